@@ -0,0 +1,325 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/pengcunfu/go-mcp-git/pkg/gitops"
+	"golang.org/x/crypto/ssh"
+)
+
+// File is the server configuration file, holding named workspace profiles:
+//
+//	{
+//	  "profiles": {
+//	    "work": {
+//	      "repositories": ["~/src/service", "~/src/infra"],
+//	      "aliases": {"backend": "~/src/service", "docs": "https://github.com/corp/docs.git"},
+//	      "workspaces": ["~/src"],
+//	      "user_name": "Jane Doe",
+//	      "user_email": "jane@corp.example",
+//	      "commit_policy": {"wrap_body": 72, "issue_pattern": "[A-Z]+-[0-9]+"},
+//	      "credentials": {"username": "jane", "password_env": "CORP_GIT_TOKEN"},
+//	      "ssh": {"key_file": "~/.ssh/id_work", "known_hosts": ["~/.ssh/known_hosts"]},
+//	      "signing_key": {"key_file": "~/.keys/jane.asc", "passphrase_env": "JANE_GPG_PASSPHRASE"},
+//	      "restrict_repositories": true,
+//	      "disable_tools": ["destructive", "git_raw_command"]
+//	    }
+//	  }
+//	}
+type File struct {
+	Profiles map[string]*Profile `json:"profiles"`
+}
+
+// Profile is a named set of repositories, identity, policies, credentials
+// and guardrails selected at startup
+type Profile struct {
+	Repositories []string `json:"repositories,omitempty"`
+	UserName     string   `json:"user_name,omitempty"`
+	UserEmail    string   `json:"user_email,omitempty"`
+
+	// Aliases name repositories, tools accepting repo: "backend" instead
+	// of a path. Targets are paths or URLs, cloned on first use.
+	Aliases map[string]string `json:"aliases,omitempty"`
+	// Workspaces are searched at startup for repositories, registered
+	// with the server
+	Workspaces []string `json:"workspaces,omitempty"`
+
+	// CommitPolicy applies to repositories without their own
+	// [mcpgit "commit"] configuration
+	CommitPolicy *gitops.CommitPolicy `json:"commit_policy,omitempty"`
+	// Credentials authenticate fetch and push over HTTPS
+	Credentials *Credentials `json:"credentials,omitempty"`
+	// SSH authenticates fetch and push over SSH
+	SSH *SSH `json:"ssh,omitempty"`
+	// SigningKey signs the commits and tags requesting a signature
+	SigningKey *SigningKey `json:"signing_key,omitempty"`
+
+	// ReadOnly only exposes tools that do not modify repositories
+	ReadOnly bool `json:"read_only,omitempty"`
+	// EnableTools only exposes these tools and tool groups ("read",
+	// "write", "remote", "destructive"), DisableTools hides them
+	EnableTools  []string `json:"enable_tools,omitempty"`
+	DisableTools []string `json:"disable_tools,omitempty"`
+	// RestrictRepositories rejects tool calls on repositories outside
+	// Repositories
+	RestrictRepositories bool `json:"restrict_repositories,omitempty"`
+	// Locked hides the tools that modify repositories until the agent
+	// calls server_unlock
+	Locked bool `json:"locked,omitempty"`
+
+	// ResultStyle sets how much prose frames tool results: minimal,
+	// normal or verbose
+	ResultStyle string `json:"result_style,omitempty"`
+	// ToolPrefix is prepended to the tool names, e.g. "work_" exposes
+	// work_git_status
+	ToolPrefix string `json:"tool_prefix,omitempty"`
+}
+
+// Credentials holds HTTP basic authentication for remotes. The password
+// (usually a token) is best read from an environment variable so it does
+// not live in the configuration file.
+type Credentials struct {
+	Username    string `json:"username"`
+	Password    string `json:"password,omitempty"`
+	PasswordEnv string `json:"password_env,omitempty"`
+	// PasswordFile is read for the password, e.g. a mounted Docker secret
+	PasswordFile string `json:"password_file,omitempty"`
+}
+
+// SigningKey locates the OpenPGP key signing commits and tags: a private
+// key file or exported keyring, optionally the ID of the key to use from
+// it, and its passphrase, best read from an environment variable or a file.
+// With Format "ssh" KeyFile is an SSH key instead, as user.signingkey with
+// gpg.format=ssh: a private key or the public key of an ssh-agent key.
+type SigningKey struct {
+	Format         string `json:"format,omitempty"`
+	KeyFile        string `json:"key_file"`
+	KeyID          string `json:"key_id,omitempty"`
+	Passphrase     string `json:"passphrase,omitempty"`
+	PassphraseEnv  string `json:"passphrase_env,omitempty"`
+	PassphraseFile string `json:"passphrase_file,omitempty"`
+}
+
+// SSH configures the authentication to SSH remotes: a private key file or
+// the public key of an ssh-agent key (all agent keys are offered without
+// one), its passphrase, and the known_hosts files verifying host keys
+type SSH struct {
+	KeyFile               string   `json:"key_file,omitempty"`
+	Passphrase            string   `json:"passphrase,omitempty"`
+	PassphraseEnv         string   `json:"passphrase_env,omitempty"`
+	PassphraseFile        string   `json:"passphrase_file,omitempty"`
+	KnownHosts            []string `json:"known_hosts,omitempty"`
+	InsecureIgnoreHostKey bool     `json:"insecure_ignore_host_key,omitempty"`
+}
+
+// DefaultPath returns the default location of the configuration file
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "go-mcp-git.json"
+	}
+	return filepath.Join(dir, "go-mcp-git", "config.json")
+}
+
+// Load reads a configuration file
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+
+	var file File
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+
+	return &file, nil
+}
+
+// Profile returns a profile by name with repository paths expanded
+func (f *File) Profile(name string) (*Profile, error) {
+	profile, ok := f.Profiles[name]
+	if !ok || profile == nil {
+		names := make([]string, 0, len(f.Profiles))
+		for n := range f.Profiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("profile '%s' not found (available: %s)", name, strings.Join(names, ", "))
+	}
+
+	for i, repo := range profile.Repositories {
+		profile.Repositories[i] = expandPath(repo)
+	}
+	for i, workspace := range profile.Workspaces {
+		profile.Workspaces[i] = expandPath(workspace)
+	}
+	for alias, target := range profile.Aliases {
+		if err := ValidateAlias(alias, target); err != nil {
+			return nil, fmt.Errorf("profile '%s': %w", name, err)
+		}
+		profile.Aliases[alias] = expandPath(target)
+	}
+
+	if profile.CommitPolicy != nil {
+		if err := profile.CommitPolicy.Validate(); err != nil {
+			return nil, fmt.Errorf("profile '%s': %w", name, err)
+		}
+	}
+
+	return profile, nil
+}
+
+// ValidateAlias checks the name and target of a repository alias
+func ValidateAlias(name, target string) error {
+	if !aliasPattern.MatchString(name) {
+		return fmt.Errorf("invalid repository alias '%s' (expected letters, digits, '.', '_' or '-')", name)
+	}
+	if strings.TrimSpace(target) == "" {
+		return fmt.Errorf("repository alias '%s' has no path or URL", name)
+	}
+	return nil
+}
+
+var aliasPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ParseAliases reads repository aliases given as name=path or name=URL,
+// later ones replacing earlier ones of the same name
+func ParseAliases(values []string) (map[string]string, error) {
+	aliases := make(map[string]string, len(values))
+	for _, value := range values {
+		name, target, ok := strings.Cut(value, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid repository alias '%s' (expected name=path or name=URL)", value)
+		}
+		name, target = strings.TrimSpace(name), strings.TrimSpace(target)
+		if err := ValidateAlias(name, target); err != nil {
+			return nil, err
+		}
+		aliases[name] = expandPath(target)
+	}
+	return aliases, nil
+}
+
+// Auth returns the transport authentication for the credentials
+func (c *Credentials) Auth() (transport.AuthMethod, error) {
+	password := c.Password
+	if c.PasswordEnv != "" {
+		password = os.Getenv(c.PasswordEnv)
+		if password == "" {
+			return nil, fmt.Errorf("environment variable %s holding the password is not set", c.PasswordEnv)
+		}
+	}
+	if c.PasswordFile != "" {
+		data, err := os.ReadFile(expandPath(c.PasswordFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read password file: %w", err)
+		}
+		password = strings.TrimSpace(string(data))
+	}
+
+	return &http.BasicAuth{Username: c.Username, Password: password}, nil
+}
+
+// Signing key formats, as git's gpg.format
+const (
+	SigningFormatOpenPGP = "openpgp"
+	SigningFormatSSH     = "ssh"
+)
+
+// IsSSH reports whether the signing key is an SSH key, failing for unknown
+// formats
+func (k *SigningKey) IsSSH() (bool, error) {
+	switch k.Format {
+	case "", SigningFormatOpenPGP:
+		return false, nil
+	case SigningFormatSSH:
+		return true, nil
+	}
+	return false, fmt.Errorf("unknown signing key format '%s' (expected %s or %s)", k.Format, SigningFormatOpenPGP, SigningFormatSSH)
+}
+
+// Entity loads and decrypts the OpenPGP signing key
+func (k *SigningKey) Entity() (*openpgp.Entity, error) {
+	passphrase, err := k.passphrase()
+	if err != nil {
+		return nil, err
+	}
+	return gitops.LoadSigningKey(expandPath(k.KeyFile), k.KeyID, passphrase)
+}
+
+// SSHSigner loads and decrypts the SSH signing key
+func (k *SigningKey) SSHSigner() (ssh.Signer, error) {
+	passphrase, err := k.passphrase()
+	if err != nil {
+		return nil, err
+	}
+	return gitops.LoadSSHKey(expandPath(k.KeyFile), passphrase)
+}
+
+func (k *SigningKey) passphrase() ([]byte, error) {
+	return readPassphrase(k.Passphrase, k.PassphraseEnv, k.PassphraseFile)
+}
+
+// Auth loads the SSH key, when given, and returns the SSH authentication
+func (s *SSH) Auth() (*gitops.SSHAuth, error) {
+	auth := &gitops.SSHAuth{InsecureIgnoreHostKey: s.InsecureIgnoreHostKey}
+	for _, file := range s.KnownHosts {
+		auth.KnownHosts = append(auth.KnownHosts, expandPath(file))
+	}
+	if s.KeyFile == "" {
+		return auth, nil
+	}
+
+	passphrase, err := readPassphrase(s.Passphrase, s.PassphraseEnv, s.PassphraseFile)
+	if err != nil {
+		return nil, err
+	}
+	key := s.KeyFile
+	// A key:: literal names an ssh-agent key, not a file
+	if !strings.HasPrefix(key, "key::") {
+		key = expandPath(key)
+		auth.KeyFile = key
+	}
+	auth.Key, err = gitops.LoadSSHKey(key, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return auth, nil
+}
+
+// readPassphrase returns a passphrase given directly, by an environment
+// variable or by a file, the latter taking precedence
+func readPassphrase(passphrase, env, file string) ([]byte, error) {
+	if env != "" {
+		passphrase = os.Getenv(env)
+	}
+	if file != "" {
+		data, err := os.ReadFile(expandPath(file))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read passphrase file: %w", err)
+		}
+		passphrase = strings.TrimRight(string(data), "\r\n")
+	}
+	return []byte(passphrase), nil
+}
+
+// expandPath expands environment variables and a leading ~ in a path
+func expandPath(path string) string {
+	path = os.ExpandEnv(path)
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+	}
+	return path
+}
@@ -0,0 +1,231 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+func TestLoadProfile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	content := `{
+  "profiles": {
+    "work": {
+      "repositories": ["$WORK_ROOT/service"],
+      "aliases": {"backend": "$WORK_ROOT/service", "docs": "https://git.example/docs.git"},
+      "workspaces": ["$WORK_ROOT"],
+      "user_name": "Jane Doe",
+      "commit_policy": {"wrap_body": 72, "issue_pattern": "[A-Z]+-[0-9]+"},
+      "credentials": {"username": "jane", "password_env": "WORK_TOKEN"},
+      "read_only": true
+    },
+    "oss": {"commit_policy": {"issue_format": "suffix"}},
+    "typo": {"aliases": {"../up": "/src/up"}}
+  }
+}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	t.Setenv("WORK_ROOT", "/src")
+	t.Setenv("WORK_TOKEN", "secret")
+
+	file, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	profile, err := file.Profile("work")
+	if err != nil {
+		t.Fatalf("Profile failed: %v", err)
+	}
+	if len(profile.Repositories) != 1 || profile.Repositories[0] != "/src/service" {
+		t.Errorf("Unexpected repositories: %v", profile.Repositories)
+	}
+	if profile.Aliases["backend"] != "/src/service" || profile.Aliases["docs"] != "https://git.example/docs.git" {
+		t.Errorf("Unexpected aliases: %v", profile.Aliases)
+	}
+	if len(profile.Workspaces) != 1 || profile.Workspaces[0] != "/src" {
+		t.Errorf("Unexpected workspaces: %v", profile.Workspaces)
+	}
+	if profile.UserName != "Jane Doe" || !profile.ReadOnly {
+		t.Errorf("Unexpected profile: %+v", profile)
+	}
+	if profile.CommitPolicy.WrapBody != 72 || profile.CommitPolicy.IssueTrailer != "Refs" {
+		t.Errorf("Expected policy defaults to be filled in, got: %+v", profile.CommitPolicy)
+	}
+
+	auth, err := profile.Credentials.Auth()
+	if err != nil {
+		t.Fatalf("Auth failed: %v", err)
+	}
+	if basic, ok := auth.(*http.BasicAuth); !ok || basic.Username != "jane" || basic.Password != "secret" {
+		t.Errorf("Unexpected auth: %+v", auth)
+	}
+
+	if _, err := file.Profile("oss"); err == nil {
+		t.Error("Expected invalid commit policy to fail")
+	}
+	if _, err := file.Profile("typo"); err == nil {
+		t.Error("Expected invalid alias name to fail")
+	}
+	if _, err := file.Profile("missing"); err == nil {
+		t.Error("Expected missing profile to fail")
+	}
+}
+
+func TestParseAliases(t *testing.T) {
+	t.Setenv("SRC", "/src")
+	aliases, err := ParseAliases([]string{"backend=$SRC/old", "web = git@git.example:web.git", "backend=$SRC/backend"})
+	if err != nil {
+		t.Fatalf("ParseAliases failed: %v", err)
+	}
+	if len(aliases) != 2 || aliases["backend"] != "/src/backend" || aliases["web"] != "git@git.example:web.git" {
+		t.Errorf("Unexpected aliases: %v", aliases)
+	}
+	for _, value := range []string{"backend", "=/src/backend", "back end=/src", "backend="} {
+		if _, err := ParseAliases([]string{value}); err == nil {
+			t.Errorf("Expected %q to be rejected", value)
+		}
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	if err := os.WriteFile(tokenFile, []byte("from-file\n"), 0600); err != nil {
+		t.Fatalf("Failed to write token: %v", err)
+	}
+
+	t.Setenv(EnvRepositories, "/repos/a"+string(filepath.ListSeparator)+"/repos/b")
+	t.Setenv(EnvUserName, "Bot")
+	t.Setenv(EnvToken, "")
+	t.Setenv(EnvTokenFile, tokenFile)
+	t.Setenv(EnvUsername, "")
+	t.Setenv(EnvSigningKey, "/keys/bot.asc")
+	t.Setenv(EnvSigningKeyID, "ABCD1234")
+	t.Setenv(EnvSigningFormat, "")
+	t.Setenv(EnvSSHKey, "")
+	t.Setenv(EnvSSHKnownHosts, "/hosts/a"+string(filepath.ListSeparator)+"/hosts/b")
+
+	env := FromEnv()
+	if len(env.Repositories) != 2 || env.Repositories[1] != "/repos/b" || env.UserName != "Bot" {
+		t.Errorf("Unexpected container config: %+v", env)
+	}
+
+	auth, err := env.Credentials.Auth()
+	if err != nil {
+		t.Fatalf("Auth failed: %v", err)
+	}
+	if basic := auth.(*http.BasicAuth); basic.Username != "git" || basic.Password != "from-file" {
+		t.Errorf("Unexpected auth: %+v", basic)
+	}
+
+	if key := env.SigningKey; key == nil || key.KeyFile != "/keys/bot.asc" || key.KeyID != "ABCD1234" || key.PassphraseEnv != EnvSigningPassphrase {
+		t.Errorf("Unexpected signing key: %+v", key)
+	}
+	if isSSH, err := env.SigningKey.IsSSH(); err != nil || isSSH {
+		t.Errorf("Expected an OpenPGP key, got ssh=%t, err=%v", isSSH, err)
+	}
+	if _, err := (&SigningKey{Format: "x509"}).IsSSH(); err == nil {
+		t.Error("Expected error for an unknown signing key format")
+	}
+
+	if ssh := env.SSH; ssh == nil || len(ssh.KnownHosts) != 2 || ssh.KnownHosts[1] != "/hosts/b" || ssh.KeyFile != "" {
+		t.Errorf("Unexpected SSH config: %+v", ssh)
+	}
+	sshAuth, err := env.SSH.Auth()
+	if err != nil || sshAuth.Key != nil || len(sshAuth.KnownHosts) != 2 {
+		t.Errorf("Expected the ssh-agent with known hosts, got %+v (%v)", sshAuth, err)
+	}
+	if _, err := (&SSH{KeyFile: filepath.Join(dir, "missing")}).Auth(); err == nil {
+		t.Error("Expected error for a missing SSH key")
+	}
+}
+
+func TestEnvCredentials(t *testing.T) {
+	t.Setenv(EnvToken, "")
+	t.Setenv(EnvTokenFile, "")
+	t.Setenv(EnvGitToken, "")
+	t.Setenv(EnvUsername, "")
+	if creds := EnvCredentials(); creds != nil {
+		t.Errorf("Expected no credentials, got %+v", creds)
+	}
+
+	t.Setenv(EnvGitToken, "generic")
+	auth, err := EnvCredentials().Auth()
+	if err != nil {
+		t.Fatalf("Auth failed: %v", err)
+	}
+	if basic := auth.(*http.BasicAuth); basic.Username != "git" || basic.Password != "generic" {
+		t.Errorf("Unexpected auth from %s: %+v", EnvGitToken, basic)
+	}
+
+	t.Setenv(EnvToken, "specific")
+	t.Setenv(EnvUsername, "bot")
+	auth, _ = EnvCredentials().Auth()
+	if basic := auth.(*http.BasicAuth); basic.Username != "bot" || basic.Password != "specific" {
+		t.Errorf("Expected %s to take precedence, got %+v", EnvToken, basic)
+	}
+}
+
+func TestAuthToken(t *testing.T) {
+	t.Setenv(EnvAuthToken, "")
+	t.Setenv(EnvAuthTokenFile, "")
+	if token, err := AuthToken("", ""); err != nil || token != "" {
+		t.Errorf("Expected no token, got %q (%v)", token, err)
+	}
+
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	if err := os.WriteFile(tokenFile, []byte("from-file\n"), 0600); err != nil {
+		t.Fatalf("Failed to write token file: %v", err)
+	}
+	t.Setenv(EnvAuthTokenFile, tokenFile)
+	if token, _ := AuthToken("", ""); token != "from-file" {
+		t.Errorf("Expected the token of %s, got %q", EnvAuthTokenFile, token)
+	}
+	t.Setenv(EnvAuthToken, "from-env")
+	if token, _ := AuthToken("", ""); token != "from-env" {
+		t.Errorf("Expected %s to take precedence, got %q", EnvAuthToken, token)
+	}
+	if token, _ := AuthToken("from-flag", ""); token != "from-flag" {
+		t.Errorf("Expected the given token to take precedence, got %q", token)
+	}
+	if token, _ := AuthToken("from-flag", tokenFile); token != "from-file" {
+		t.Errorf("Expected the given file to take precedence, got %q", token)
+	}
+
+	empty := filepath.Join(dir, "empty")
+	if err := os.WriteFile(empty, nil, 0600); err != nil {
+		t.Fatalf("Failed to write token file: %v", err)
+	}
+	if _, err := AuthToken("", empty); err == nil {
+		t.Error("Expected error for an empty token file")
+	}
+	if _, err := AuthToken("", filepath.Join(dir, "missing")); err == nil {
+		t.Error("Expected error for a missing token file")
+	}
+}
+
+func TestOTLPTracesEndpoint(t *testing.T) {
+	t.Setenv(EnvOTLPEndpoint, "")
+	t.Setenv(EnvOTLPTracesEndpoint, "")
+	if endpoint := OTLPTracesEndpoint(""); endpoint != "" {
+		t.Errorf("Expected no endpoint, got %q", endpoint)
+	}
+
+	t.Setenv(EnvOTLPEndpoint, "http://collector:4318/")
+	if endpoint := OTLPTracesEndpoint(""); endpoint != "http://collector:4318/v1/traces" {
+		t.Errorf("Expected the traces path under %s, got %q", EnvOTLPEndpoint, endpoint)
+	}
+	t.Setenv(EnvOTLPTracesEndpoint, "http://collector:4318/custom")
+	if endpoint := OTLPTracesEndpoint(""); endpoint != "http://collector:4318/custom" {
+		t.Errorf("Expected %s to take precedence, got %q", EnvOTLPTracesEndpoint, endpoint)
+	}
+	if endpoint := OTLPTracesEndpoint("http://flag:4318/v1/traces"); endpoint != "http://flag:4318/v1/traces" {
+		t.Errorf("Expected the given endpoint to take precedence, got %q", endpoint)
+	}
+}
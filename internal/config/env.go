@@ -0,0 +1,184 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Environment variables configuring the server in container mode
+const (
+	// EnvContainer enables container mode when set to a true value
+	EnvContainer = "MCP_GIT_CONTAINER"
+	// EnvRepositories lists the mounted repositories, separated like PATH
+	EnvRepositories = "MCP_GIT_REPOSITORIES"
+	EnvUserName     = "MCP_GIT_USER_NAME"
+	EnvUserEmail    = "MCP_GIT_USER_EMAIL"
+	EnvScratchDir   = "MCP_GIT_SCRATCH_DIR"
+	// EnvUsername with EnvToken or EnvTokenFile provide HTTPS credentials,
+	// also read outside container mode. EnvGitToken is the fallback used
+	// by other git tooling.
+	EnvUsername  = "MCP_GIT_USERNAME"
+	EnvToken     = "MCP_GIT_TOKEN"
+	EnvTokenFile = "MCP_GIT_TOKEN_FILE"
+	EnvGitToken  = "GIT_TOKEN"
+	// EnvSigningKey is the OpenPGP key file signing commits and tags,
+	// EnvSigningKeyID selects a key from it and EnvSigningPassphrase
+	// decrypts it. The passphrase is also read outside container mode.
+	// EnvSigningFormat "ssh" makes it an SSH key.
+	EnvSigningKey        = "MCP_GIT_SIGNING_KEY"
+	EnvSigningKeyID      = "MCP_GIT_SIGNING_KEY_ID"
+	EnvSigningPassphrase = "MCP_GIT_SIGNING_PASSPHRASE"
+	EnvSigningFormat     = "MCP_GIT_SIGNING_FORMAT"
+	// EnvSSHKey is the key authenticating to SSH remotes, decrypted with
+	// EnvSSHPassphrase (also read outside container mode), and
+	// EnvSSHKnownHosts lists the known_hosts files verifying host keys
+	EnvSSHKey        = "MCP_GIT_SSH_KEY"
+	EnvSSHPassphrase = "MCP_GIT_SSH_PASSPHRASE"
+	EnvSSHKnownHosts = "MCP_GIT_SSH_KNOWN_HOSTS"
+	// EnvAuthToken or EnvAuthTokenFile give the bearer token clients of
+	// network transports present, also read outside container mode, and
+	// EnvOAuthClientSecret authenticates the server to the OAuth2
+	// introspection endpoint
+	EnvAuthToken         = "MCP_GIT_AUTH_TOKEN"
+	EnvAuthTokenFile     = "MCP_GIT_AUTH_TOKEN_FILE"
+	EnvOAuthClientSecret = "MCP_GIT_OAUTH_CLIENT_SECRET"
+)
+
+// Standard OpenTelemetry variables configuring the export of traces, read
+// outside container mode too. The traces variables take precedence over
+// the general ones.
+const (
+	EnvOTLPEndpoint       = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	EnvOTLPTracesEndpoint = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
+	EnvOTLPHeaders        = "OTEL_EXPORTER_OTLP_HEADERS"
+	EnvOTLPTracesHeaders  = "OTEL_EXPORTER_OTLP_TRACES_HEADERS"
+)
+
+// Container is the configuration of a containerized deployment, read from
+// the environment
+type Container struct {
+	Repositories []string
+	UserName     string
+	UserEmail    string
+	ScratchDir   string
+	Credentials  *Credentials
+	SigningKey   *SigningKey
+	SSH          *SSH
+}
+
+// ContainerMode reports whether the environment requests container mode
+func ContainerMode() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv(EnvContainer))
+	return enabled
+}
+
+// FromEnv reads the container configuration from the environment
+func FromEnv() *Container {
+	c := &Container{
+		UserName:   os.Getenv(EnvUserName),
+		UserEmail:  os.Getenv(EnvUserEmail),
+		ScratchDir: os.Getenv(EnvScratchDir),
+	}
+
+	for _, repo := range filepath.SplitList(os.Getenv(EnvRepositories)) {
+		if repo != "" {
+			c.Repositories = append(c.Repositories, expandPath(repo))
+		}
+	}
+
+	c.Credentials = EnvCredentials()
+
+	if os.Getenv(EnvSigningKey) != "" {
+		c.SigningKey = &SigningKey{
+			Format:        os.Getenv(EnvSigningFormat),
+			KeyFile:       os.Getenv(EnvSigningKey),
+			KeyID:         os.Getenv(EnvSigningKeyID),
+			PassphraseEnv: EnvSigningPassphrase,
+		}
+	}
+
+	if os.Getenv(EnvSSHKey) != "" || os.Getenv(EnvSSHKnownHosts) != "" {
+		c.SSH = &SSH{KeyFile: os.Getenv(EnvSSHKey), PassphraseEnv: EnvSSHPassphrase}
+		for _, file := range filepath.SplitList(os.Getenv(EnvSSHKnownHosts)) {
+			if file != "" {
+				c.SSH.KnownHosts = append(c.SSH.KnownHosts, file)
+			}
+		}
+	}
+
+	return c
+}
+
+// EnvCredentials returns the HTTPS credentials given by EnvToken,
+// EnvTokenFile or EnvGitToken, nil when none is set
+func EnvCredentials() *Credentials {
+	c := &Credentials{Username: os.Getenv(EnvUsername)}
+	switch {
+	case os.Getenv(EnvToken) != "":
+		c.PasswordEnv = EnvToken
+	case os.Getenv(EnvTokenFile) != "":
+		c.PasswordFile = os.Getenv(EnvTokenFile)
+	case os.Getenv(EnvGitToken) != "":
+		c.PasswordEnv = EnvGitToken
+	default:
+		return nil
+	}
+
+	// Token based HTTPS authentication ignores the user name, but an empty
+	// one is rejected by some servers
+	if c.Username == "" {
+		c.Username = "git"
+	}
+	return c
+}
+
+// AuthToken returns the bearer token required from the clients of network
+// transports: the content of file, the token itself, or the token given
+// by EnvAuthToken or EnvAuthTokenFile, in that order. It is empty when
+// none is set.
+func AuthToken(token, file string) (string, error) {
+	if file == "" && token == "" {
+		token = os.Getenv(EnvAuthToken)
+		if token == "" {
+			file = os.Getenv(EnvAuthTokenFile)
+		}
+	}
+	if file != "" {
+		data, err := os.ReadFile(expandPath(file))
+		if err != nil {
+			return "", fmt.Errorf("failed to read auth token file: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+		if token == "" {
+			return "", fmt.Errorf("auth token file %s is empty", file)
+		}
+	}
+	return token, nil
+}
+
+// OTLPTracesEndpoint returns the OTLP/HTTP URL traces are exported to:
+// endpoint itself, EnvOTLPTracesEndpoint, or EnvOTLPEndpoint with the
+// traces path, in that order. It is empty when none is set.
+func OTLPTracesEndpoint(endpoint string) string {
+	if endpoint == "" {
+		endpoint = os.Getenv(EnvOTLPTracesEndpoint)
+	}
+	if endpoint == "" {
+		if base := os.Getenv(EnvOTLPEndpoint); base != "" {
+			endpoint = strings.TrimRight(base, "/") + "/v1/traces"
+		}
+	}
+	return endpoint
+}
+
+// OTLPHeaders returns the headers sent with exported traces, in the format
+// of EnvOTLPHeaders
+func OTLPHeaders() string {
+	if headers := os.Getenv(EnvOTLPTracesHeaders); headers != "" {
+		return headers
+	}
+	return os.Getenv(EnvOTLPHeaders)
+}
@@ -0,0 +1,89 @@
+// Package logging sets up the logs of the server. Stdout carries the
+// JSON-RPC messages, so logs go to stderr or to a file, never to stdout.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// LevelTrace also logs every JSON-RPC message, below slog.LevelDebug
+const LevelTrace = slog.LevelDebug - 4
+
+// Formats are the accepted values of Options.Format
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Options configure the logs
+type Options struct {
+	// Verbose is the count of -v flags: warnings and errors are logged
+	// without any, then -v adds information, -vv debugging and -vvv the
+	// JSON-RPC messages
+	Verbose int
+	// File receives the logs instead of stderr, appended to
+	File string
+	// Format is FormatText, the default, or FormatJSON
+	Format string
+}
+
+// Level maps the count of -v flags to a log level
+func Level(verbose int) slog.Level {
+	switch {
+	case verbose <= 0:
+		return slog.LevelWarn
+	case verbose == 1:
+		return slog.LevelInfo
+	case verbose == 2:
+		return slog.LevelDebug
+	default:
+		return LevelTrace
+	}
+}
+
+// New creates a logger writing to w
+func New(w io.Writer, format string, level slog.Level) (*slog.Logger, error) {
+	opts := &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.LevelKey && len(groups) == 0 {
+				if l, ok := a.Value.Any().(slog.Level); ok && l <= LevelTrace {
+					a.Value = slog.StringValue("TRACE")
+				}
+			}
+			return a
+		},
+	}
+	switch format {
+	case "", FormatText:
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case FormatJSON:
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	}
+	return nil, fmt.Errorf("invalid log format '%s' (expected %s or %s)", format, FormatText, FormatJSON)
+}
+
+// Setup makes the logger of opts the default one, which the log package
+// writes to as well. The returned function closes the log file.
+func Setup(opts Options) (func() error, error) {
+	var w io.Writer = os.Stderr
+	closeFile := func() error { return nil }
+	if opts.File != "" {
+		file, err := os.OpenFile(opts.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+		w, closeFile = file, file.Close
+	}
+
+	logger, err := New(w, opts.Format, Level(opts.Verbose))
+	if err != nil {
+		closeFile()
+		return nil, err
+	}
+	slog.SetDefault(logger)
+	return closeFile, nil
+}
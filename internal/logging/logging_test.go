@@ -0,0 +1,73 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLevel(t *testing.T) {
+	tests := map[int]slog.Level{0: slog.LevelWarn, 1: slog.LevelInfo, 2: slog.LevelDebug, 3: LevelTrace, 5: LevelTrace}
+	for verbose, level := range tests {
+		if got := Level(verbose); got != level {
+			t.Errorf("Level(%d) = %v, expected %v", verbose, got, level)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, FormatJSON, LevelTrace)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	logger.Log(context.Background(), LevelTrace, "Request", "method", "tools/list")
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Expected a JSON log line, got %q: %v", buf.String(), err)
+	}
+	if entry["level"] != "TRACE" || entry["msg"] != "Request" || entry["method"] != "tools/list" {
+		t.Errorf("Unexpected log entry: %v", entry)
+	}
+
+	buf.Reset()
+	logger, _ = New(&buf, "", slog.LevelWarn)
+	logger.Info("Hidden")
+	logger.Warn("Shown")
+	if strings.Contains(buf.String(), "Hidden") || !strings.Contains(buf.String(), "msg=Shown") {
+		t.Errorf("Unexpected text log: %q", buf.String())
+	}
+
+	if _, err := New(&buf, "xml", slog.LevelInfo); err == nil {
+		t.Error("Expected error for an unknown format")
+	}
+}
+
+func TestSetup(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+
+	path := filepath.Join(t.TempDir(), "server.log")
+	closeFile, err := Setup(Options{Verbose: 1, File: path})
+	if err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+	slog.Info("Starting", "repository", "/src/service")
+	slog.Debug("Hidden")
+	if err := closeFile(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(content), "msg=Starting repository=/src/service") || strings.Contains(string(content), "Hidden") {
+		t.Errorf("Unexpected log file: %q", content)
+	}
+}
@@ -0,0 +1,314 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// exportInterval is the longest a finished span waits for export
+	exportInterval = 5 * time.Second
+	// exportBatch is the number of spans exported at once
+	exportBatch = 512
+	// maxQueued bounds the spans waiting for export, those finishing while
+	// the collector is unreachable being dropped past it
+	maxQueued = 4096
+	// exportTimeout bounds an export request
+	exportTimeout = 10 * time.Second
+)
+
+// Options configure a tracer
+type Options struct {
+	// Endpoint is the OTLP/HTTP traces URL of the collector, e.g.
+	// http://localhost:4318/v1/traces
+	Endpoint string
+	// Headers are sent with every export, e.g. for authentication
+	Headers map[string]string
+	// ServiceName and ServiceVersion describe the server in the resource
+	// of the spans
+	ServiceName    string
+	ServiceVersion string
+}
+
+// Tracer starts traces and exports their spans in batches, as OTLP/HTTP
+// JSON
+type Tracer struct {
+	opts   Options
+	client *http.Client
+
+	mu      sync.Mutex
+	queue   []*Span
+	dropped int
+
+	flush   chan struct{}
+	stop    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+}
+
+// NewTracer creates a tracer exporting to a collector until Shutdown
+func NewTracer(opts Options) *Tracer {
+	t := &Tracer{
+		opts:    opts,
+		client:  &http.Client{Timeout: exportTimeout},
+		flush:   make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go t.run()
+	return t
+}
+
+// Start starts the root span of a trace, continuing the trace of a W3C
+// traceparent header when valid. A nil tracer records nothing.
+func (t *Tracer) Start(ctx context.Context, name string, traceParent string, attrs ...Attribute) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+	span := t.newSpan(name, KindServer, attrs)
+	if traceID, parentID, ok := parseTraceParent(traceParent); ok {
+		span.traceID, span.parentID = traceID, parentID
+	} else {
+		randomID(span.traceID[:])
+	}
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func (t *Tracer) newSpan(name string, kind int, attrs []Attribute) *Span {
+	span := &Span{tracer: t, name: name, kind: kind, start: time.Now(), attrs: attrs}
+	randomID(span.spanID[:])
+	return span
+}
+
+// enqueue queues a finished span for export
+func (t *Tracer) enqueue(span *Span) {
+	t.mu.Lock()
+	if len(t.queue) >= maxQueued {
+		t.dropped++
+		t.mu.Unlock()
+		return
+	}
+	t.queue = append(t.queue, span)
+	full := len(t.queue) >= exportBatch
+	t.mu.Unlock()
+	if full {
+		select {
+		case t.flush <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Shutdown exports the spans left and stops the tracer, waiting until ctx
+// is done at most
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+	t.once.Do(func() { close(t.stop) })
+	select {
+	case <-t.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run exports the queued spans on every interval or full batch, until the
+// tracer stops
+func (t *Tracer) run() {
+	defer close(t.stopped)
+	ticker := time.NewTicker(exportInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-t.flush:
+		case <-t.stop:
+			t.exportQueued()
+			return
+		}
+		t.exportQueued()
+	}
+}
+
+// exportQueued exports the queued spans, batch by batch. Failed exports
+// are logged and their spans dropped, a collector being down must not
+// grow the queue.
+func (t *Tracer) exportQueued() {
+	for {
+		t.mu.Lock()
+		batch := t.queue
+		if len(batch) > exportBatch {
+			batch = batch[:exportBatch]
+		}
+		t.queue = t.queue[len(batch):]
+		dropped := t.dropped
+		t.dropped = 0
+		t.mu.Unlock()
+
+		if dropped > 0 {
+			slog.Warn("Dropped spans waiting for export", "spans", dropped)
+		}
+		if len(batch) == 0 {
+			return
+		}
+		if err := t.export(batch); err != nil {
+			slog.Warn("Failed to export spans", "endpoint", t.opts.Endpoint, "spans", len(batch), "error", err)
+		}
+	}
+}
+
+// export sends spans to the collector
+func (t *Tracer) export(spans []*Span) error {
+	body, err := json.Marshal(t.encode(spans))
+	if err != nil {
+		return fmt.Errorf("failed to encode spans: %w", err)
+	}
+	request, err := http.NewRequest(http.MethodPost, t.opts.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create export request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for name, value := range t.opts.Headers {
+		request.Header.Set(name, value)
+	}
+	response, err := t.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to send spans: %w", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return fmt.Errorf("collector answered %s: %s", response.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// OTLP JSON encoding of spans: IDs are hex and 64-bit integers strings
+type (
+	otlpRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	}
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpSpan struct {
+		TraceID           string          `json:"traceId"`
+		SpanID            string          `json:"spanId"`
+		ParentSpanID      string          `json:"parentSpanId,omitempty"`
+		Name              string          `json:"name"`
+		Kind              int             `json:"kind"`
+		StartTimeUnixNano string          `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+		Attributes        []otlpAttribute `json:"attributes,omitempty"`
+		Status            *otlpStatus     `json:"status,omitempty"`
+	}
+	otlpStatus struct {
+		Code    int    `json:"code"`
+		Message string `json:"message,omitempty"`
+	}
+	otlpAttribute struct {
+		Key   string                 `json:"key"`
+		Value map[string]interface{} `json:"value"`
+	}
+)
+
+// otlpStatusError is the OTLP status code of failed spans
+const otlpStatusError = 2
+
+// scopeName names the instrumentation in the exported spans
+const scopeName = "github.com/pengcunfu/go-mcp-git"
+
+func (t *Tracer) encode(spans []*Span) otlpRequest {
+	resource := []otlpAttribute{encodeAttribute(String("service.name", t.opts.ServiceName))}
+	if t.opts.ServiceVersion != "" {
+		resource = append(resource, encodeAttribute(String("service.version", t.opts.ServiceVersion)))
+	}
+
+	encoded := make([]otlpSpan, 0, len(spans))
+	for _, span := range spans {
+		span.mu.Lock()
+		s := otlpSpan{
+			TraceID:           hex.EncodeToString(span.traceID[:]),
+			SpanID:            hex.EncodeToString(span.spanID[:]),
+			Name:              span.name,
+			Kind:              span.kind,
+			StartTimeUnixNano: strconv.FormatInt(span.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.end.UnixNano(), 10),
+		}
+		if span.parentID != ([8]byte{}) {
+			s.ParentSpanID = hex.EncodeToString(span.parentID[:])
+		}
+		for _, attr := range span.attrs {
+			s.Attributes = append(s.Attributes, encodeAttribute(attr))
+		}
+		if span.failure != "" {
+			s.Status = &otlpStatus{Code: otlpStatusError, Message: span.failure}
+		}
+		span.mu.Unlock()
+		encoded = append(encoded, s)
+	}
+
+	return otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: resource},
+		ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: scopeName}, Spans: encoded}},
+	}}}
+}
+
+func encodeAttribute(attr Attribute) otlpAttribute {
+	var value map[string]interface{}
+	switch v := attr.Value.(type) {
+	case bool:
+		value = map[string]interface{}{"boolValue": v}
+	case int64:
+		value = map[string]interface{}{"intValue": strconv.FormatInt(v, 10)}
+	case int:
+		value = map[string]interface{}{"intValue": strconv.Itoa(v)}
+	default:
+		value = map[string]interface{}{"stringValue": fmt.Sprint(v)}
+	}
+	return otlpAttribute{Key: attr.Key, Value: value}
+}
+
+// ParseHeaders reads headers in the format of OTEL_EXPORTER_OTLP_HEADERS,
+// comma-separated key=value pairs whose values may be URL-encoded
+func ParseHeaders(value string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, val, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid OTLP header '%s' (expected key=value)", strings.TrimSpace(pair))
+		}
+		if unescaped, err := url.PathUnescape(strings.TrimSpace(val)); err == nil {
+			val = unescaped
+		}
+		headers[strings.TrimSpace(key)] = strings.TrimSpace(val)
+	}
+	return headers, nil
+}
@@ -0,0 +1,160 @@
+// Package tracing records the tool calls of the server as OpenTelemetry
+// spans, the git operations they run being child spans, and exports them
+// to a collector over OTLP/HTTP. Spans travel in contexts, so that code
+// running without a tracer records nothing.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kinds of spans, as numbered by OTLP
+const (
+	KindInternal = 1
+	KindServer   = 2
+	KindClient   = 3
+)
+
+// Attribute is a key and value describing a span. Values are strings,
+// bools, ints or int64s.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// String returns a string attribute
+func String(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Int returns an integer attribute
+func Int(key string, value int) Attribute {
+	return Attribute{Key: key, Value: int64(value)}
+}
+
+// Bool returns a boolean attribute
+func Bool(key string, value bool) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Hash returns an attribute holding a digest of value instead of value,
+// for values such as paths that should not leave the host. Equal values
+// have equal digests, so that spans can still be grouped by them.
+func Hash(key, value string) Attribute {
+	sum := sha256.Sum256([]byte(value))
+	return Attribute{Key: key, Value: hex.EncodeToString(sum[:8])}
+}
+
+// Span is an operation of a trace. The methods of a nil span do nothing,
+// so that code need not check whether it is traced.
+type Span struct {
+	tracer   *Tracer
+	traceID  [16]byte
+	spanID   [8]byte
+	parentID [8]byte
+	name     string
+	kind     int
+	start    time.Time
+
+	mu      sync.Mutex
+	end     time.Time
+	attrs   []Attribute
+	failure string
+	ended   bool
+}
+
+// spanKey holds the current span in a context
+type spanKey struct{}
+
+// FromContext returns the current span of a context, nil when untraced
+func FromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// Start starts a child of the current span of ctx. Without a current span
+// it returns ctx and a nil span.
+func Start(ctx context.Context, name string, kind int, attrs ...Attribute) (context.Context, *Span) {
+	parent := FromContext(ctx)
+	if parent == nil {
+		return ctx, nil
+	}
+	span := parent.tracer.newSpan(name, kind, attrs)
+	span.traceID = parent.traceID
+	span.parentID = parent.spanID
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// SetAttributes adds attributes to the span
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attrs = append(s.attrs, attrs...)
+}
+
+// End ends the span, failed with err when not nil, and queues it for
+// export. Only the first call counts.
+func (s *Span) End(err error) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = time.Now()
+	if err != nil {
+		s.failure = err.Error()
+	}
+	s.mu.Unlock()
+	s.tracer.enqueue(s)
+}
+
+// TraceParent returns the W3C traceparent header of the span, continuing
+// its trace in other processes
+func (s *Span) TraceParent() string {
+	if s == nil {
+		return ""
+	}
+	return fmt.Sprintf("00-%s-%s-01", hex.EncodeToString(s.traceID[:]), hex.EncodeToString(s.spanID[:]))
+}
+
+// parseTraceParent reads the trace and parent span IDs of a W3C
+// traceparent header
+func parseTraceParent(header string) (traceID [16]byte, spanID [8]byte, ok bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return traceID, spanID, false
+	}
+	if _, err := hex.Decode(traceID[:], []byte(parts[1])); err != nil || traceID == ([16]byte{}) {
+		return traceID, spanID, false
+	}
+	if _, err := hex.Decode(spanID[:], []byte(parts[2])); err != nil || spanID == ([8]byte{}) {
+		return traceID, spanID, false
+	}
+	return traceID, spanID, true
+}
+
+// randomID fills an ID with random bytes
+func randomID(id []byte) {
+	if _, err := rand.Read(id); err != nil {
+		// crypto/rand does not fail on supported platforms, a clock keeps
+		// the IDs distinct otherwise
+		now := time.Now().UnixNano()
+		for i := range id {
+			id[i] = byte(now >> (8 * (i % 8)))
+		}
+	}
+}
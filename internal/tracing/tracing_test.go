@@ -0,0 +1,161 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// collector records the spans exported to it
+type collector struct {
+	mu       sync.Mutex
+	requests []otlpRequest
+	headers  []http.Header
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var request otlpRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c.mu.Lock()
+	c.requests = append(c.requests, request)
+	c.headers = append(c.headers, r.Header)
+	c.mu.Unlock()
+}
+
+func (c *collector) spans() map[string]otlpSpan {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	spans := make(map[string]otlpSpan)
+	for _, request := range c.requests {
+		for _, resourceSpans := range request.ResourceSpans {
+			for _, scopeSpans := range resourceSpans.ScopeSpans {
+				for _, span := range scopeSpans.Spans {
+					spans[span.Name] = span
+				}
+			}
+		}
+	}
+	return spans
+}
+
+func attribute(span otlpSpan, key string) interface{} {
+	for _, attr := range span.Attributes {
+		if attr.Key == key {
+			for _, value := range attr.Value {
+				return value
+			}
+		}
+	}
+	return nil
+}
+
+func TestTracer(t *testing.T) {
+	c := &collector{}
+	server := httptest.NewServer(c)
+	defer server.Close()
+
+	tracer := NewTracer(Options{Endpoint: server.URL + "/v1/traces", Headers: map[string]string{"Authorization": "Bearer secret"}, ServiceName: "go-mcp-git"})
+	ctx, root := tracer.Start(context.Background(), "tools/call git_log", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01", String("mcp.tool.name", "git_log"))
+	childCtx, child := Start(ctx, "git rev-list", KindClient, Int("git.args", 3))
+	if FromContext(childCtx) != child {
+		t.Error("Expected the child span to be current in its context")
+	}
+	time.Sleep(time.Millisecond)
+	child.End(errors.New("exit status 128"))
+	root.SetAttributes(Bool("mcp.tool.error", false))
+	root.End(nil)
+	root.End(errors.New("ended twice"))
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := tracer.Shutdown(shutdownCtx); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	spans := c.spans()
+	rootSpan, childSpan := spans["tools/call git_log"], spans["git rev-list"]
+	if rootSpan.TraceID != "0af7651916cd43dd8448eb211c80319c" || rootSpan.ParentSpanID != "b7ad6b7169203331" {
+		t.Errorf("Expected the root span to continue the traceparent, got trace %s parent %s", rootSpan.TraceID, rootSpan.ParentSpanID)
+	}
+	if childSpan.TraceID != rootSpan.TraceID || childSpan.ParentSpanID != rootSpan.SpanID {
+		t.Errorf("Expected the child span under the root span, got %+v", childSpan)
+	}
+	if rootSpan.Kind != KindServer || childSpan.Kind != KindClient {
+		t.Errorf("Unexpected kinds %d and %d", rootSpan.Kind, childSpan.Kind)
+	}
+	if rootSpan.Status != nil {
+		t.Errorf("Expected the root span to succeed, got %+v", rootSpan.Status)
+	}
+	if childSpan.Status == nil || childSpan.Status.Code != otlpStatusError || childSpan.Status.Message != "exit status 128" {
+		t.Errorf("Expected the child span to fail, got %+v", childSpan.Status)
+	}
+	if attribute(rootSpan, "mcp.tool.name") != "git_log" || attribute(rootSpan, "mcp.tool.error") != false || attribute(childSpan, "git.args") != "3" {
+		t.Errorf("Unexpected attributes %v and %v", rootSpan.Attributes, childSpan.Attributes)
+	}
+	if c.headers[0].Get("Authorization") != "Bearer secret" || c.headers[0].Get("Content-Type") != "application/json" {
+		t.Errorf("Unexpected export headers %v", c.headers[0])
+	}
+	if service := c.requests[0].ResourceSpans[0].Resource.Attributes[0]; service.Key != "service.name" || service.Value["stringValue"] != "go-mcp-git" {
+		t.Errorf("Unexpected resource %+v", service)
+	}
+}
+
+func TestTracer_NewTrace(t *testing.T) {
+	tracer := NewTracer(Options{Endpoint: "http://127.0.0.1:0/v1/traces"})
+	defer tracer.Shutdown(context.Background())
+
+	_, first := tracer.Start(context.Background(), "first", "")
+	_, second := tracer.Start(context.Background(), "second", "00-00000000000000000000000000000000-b7ad6b7169203331-01")
+	if first.traceID == second.traceID || first.traceID == ([16]byte{}) {
+		t.Error("Expected traces without a valid traceparent to get new trace IDs")
+	}
+	if second.parentID != ([8]byte{}) {
+		t.Error("Expected an invalid traceparent to be ignored")
+	}
+	if parent := first.TraceParent(); len(parent) != 55 {
+		t.Errorf("Unexpected traceparent %q", parent)
+	}
+}
+
+func TestUntraced(t *testing.T) {
+	ctx, span := Start(context.Background(), "git status", KindInternal)
+	if span != nil || FromContext(ctx) != nil {
+		t.Fatal("Expected no span without a current span")
+	}
+	// The methods of a nil span do nothing
+	span.SetAttributes(String("key", "value"))
+	span.End(nil)
+
+	var tracer *Tracer
+	if _, span := tracer.Start(context.Background(), "tools/call", ""); span != nil {
+		t.Error("Expected a nil tracer to record nothing")
+	}
+}
+
+func TestHash(t *testing.T) {
+	a, b := Hash("git.repo_path", "/srv/repo"), Hash("git.repo_path", "/srv/repo")
+	if a != b || a.Value == "/srv/repo" || len(a.Value.(string)) != 16 {
+		t.Errorf("Unexpected hashes %v and %v", a, b)
+	}
+}
+
+func TestParseHeaders(t *testing.T) {
+	headers, err := ParseHeaders("Authorization=Bearer%20secret, x-tenant = acme ,")
+	if err != nil {
+		t.Fatalf("ParseHeaders failed: %v", err)
+	}
+	if len(headers) != 2 || headers["Authorization"] != "Bearer secret" || headers["x-tenant"] != "acme" {
+		t.Errorf("Unexpected headers %v", headers)
+	}
+	if _, err := ParseHeaders("novalue"); err == nil {
+		t.Error("Expected a header without value to be rejected")
+	}
+}
@@ -1,43 +1,467 @@
-package main
-
-import (
-	"context"
-	"log"
-
-	"github.com/pengcunfu/go-mcp-git/internal/server"
-	"github.com/spf13/cobra"
-)
-
-var (
-	repository string
-	verbose    int
-	userName   string
-	userEmail  string
-)
-
-func main() {
-	var rootCmd = &cobra.Command{
-		Use:   "go-mcp-git",
-		Short: "MCP Git Server - Git functionality for MCP",
-		Long:  "A Model Context Protocol server providing Git repository interaction and automation tools.",
-		Run:   runServer,
-	}
-
-	rootCmd.Flags().StringVarP(&repository, "repository", "r", "", "Git repository path")
-	rootCmd.Flags().CountVarP(&verbose, "verbose", "v", "Verbose output")
-	rootCmd.Flags().StringVarP(&userName, "user-name", "u", "", "Git user name for commits")
-	rootCmd.Flags().StringVarP(&userEmail, "user-email", "e", "", "Git user email for commits")
-
-	if err := rootCmd.Execute(); err != nil {
-		log.Fatal(err)
-	}
-}
-
-func runServer(cmd *cobra.Command, args []string) {
-	ctx := context.Background()
-	
-	srv := server.New(repository, verbose, userName, userEmail)
-	if err := srv.Serve(ctx); err != nil {
-		log.Fatal(err)
-	}
-}
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/pengcunfu/go-mcp-git/internal/config"
+	"github.com/pengcunfu/go-mcp-git/internal/logging"
+	"github.com/pengcunfu/go-mcp-git/internal/tracing"
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+	"github.com/pengcunfu/go-mcp-git/pkg/mcpserver"
+	"github.com/spf13/cobra"
+)
+
+var (
+	repositories []string
+	repoAliases  []string
+	aliasDir     string
+	workspaces   []string
+	discoverDeep int
+	verbose      int
+	logFile      string
+	logFormat    string
+	userName     string
+	userEmail    string
+	scratchDir   string
+	scratchMaxMB int64
+	repoCache    int
+	trashDir     string
+	noTrash      bool
+	configPath   string
+	profileName  string
+	container    bool
+	safeDirs     []string
+	resultStyle  string
+	maxOutputKB  int
+	streamKB     int
+	toolTimeout  time.Duration
+	callsPerMin  int
+	concurrent   int
+	pushesPerHr  int
+	clonesPerHr  int
+	drainTimeout time.Duration
+	pageSize     int
+	framing      string
+	wsAddr       string
+	wsPath       string
+	wsOrigins    []string
+	wsMaxMB      int
+	authToken    string
+	authFile     string
+	oauthURL     string
+	oauthClient  string
+	oauthAud     string
+	tlsCert      string
+	tlsKey       string
+	allowAnon    bool
+	metrics      bool
+	pprof        bool
+	otlpEndpoint string
+	locked       bool
+	enableTools  []string
+	disableTools []string
+	ignoreRoots  bool
+	toolPrefix   string
+	instances    []string
+	signingKey   string
+	signingKeyID string
+	signingFmt   string
+	sshKey       string
+	knownHosts   []string
+	sshInsecure  bool
+)
+
+func main() {
+	var rootCmd = &cobra.Command{
+		Use:   "go-mcp-git",
+		Short: "MCP Git Server - Git functionality for MCP",
+		Long:  "A Model Context Protocol server providing Git repository interaction and automation tools.",
+		Run:   runServer,
+	}
+
+	rootCmd.Flags().StringArrayVarP(&repositories, "repository", "r", nil, "Git repository path (repeatable, the first one is the default)")
+	rootCmd.Flags().StringArrayVar(&repoAliases, "repo-alias", nil, "Repository alias tools accept as repo instead of a path, as name=path or name=URL, URLs being cloned on first use (repeatable, added to the profile's aliases)")
+	rootCmd.Flags().StringVar(&aliasDir, "repo-alias-dir", "", "Directory the URL repository aliases are cloned into (default: the user cache dir)")
+	rootCmd.Flags().StringArrayVar(&workspaces, "workspace", nil, "Directory searched at startup for repositories, registered with the server and listed as resources (repeatable, added to the profile's workspaces)")
+	rootCmd.Flags().IntVar(&discoverDeep, "discovery-depth", mcpserver.DefaultDiscoveryDepth, "Number of directory levels below a workspace searched for repositories")
+	rootCmd.Flags().CountVarP(&verbose, "verbose", "v", "Log more: -v information, -vv tool calls, -vvv JSON-RPC messages (default: warnings and errors)")
+	rootCmd.Flags().StringVar(&logFile, "log-file", "", "Append logs to this file instead of stderr, stdout being the JSON-RPC channel")
+	rootCmd.Flags().StringVar(&logFormat, "log-format", logging.FormatText, "Format of the logs: text or json")
+	rootCmd.Flags().StringVarP(&userName, "user-name", "u", "", "Git user name for commits")
+	rootCmd.Flags().StringVarP(&userEmail, "user-email", "e", "", "Git user email for commits")
+	rootCmd.Flags().StringVar(&scratchDir, "scratch-dir", "", "Directory for temporary working copies (default: system temp dir)")
+	rootCmd.Flags().Int64Var(&scratchMaxMB, "scratch-max-mb", 0, "Disk budget in MiB for the scratch directory, evicting least recently used entries (0: unlimited)")
+	rootCmd.Flags().IntVar(&repoCache, "repo-cache-size", mcpserver.DefaultRepoCacheSize, "Number of repositories kept open between tool calls, reopened when their packfiles change (0: open the repository on every call)")
+	rootCmd.Flags().StringVar(&trashDir, "trash-dir", "", "Directory keeping copies of the files discarded by hard resets and restores for 7 days (default: user cache dir)")
+	rootCmd.Flags().BoolVar(&noTrash, "no-trash", false, "Discard files on hard resets and restores without keeping a copy in the trash")
+	rootCmd.Flags().StringVar(&configPath, "config", "", "Configuration file with workspace profiles (default: "+config.DefaultPath()+")")
+	rootCmd.Flags().StringVar(&profileName, "profile", "", "Workspace profile to use from the configuration file")
+	rootCmd.Flags().StringArrayVar(&safeDirs, "safe-directory", nil, "Directory the git executable trusts even when owned by another user (repeatable, '*' trusts all)")
+	rootCmd.Flags().StringVar(&resultStyle, "result-style", "", "Framing of tool results: minimal (raw data), normal or verbose (default: normal, or the profile's result_style)")
+	rootCmd.Flags().IntVar(&maxOutputKB, "max-output-kb", mcpserver.DefaultMaxOutputBytes>>10, "Truncate tool results larger than this many KiB, with paging metadata (0: unlimited)")
+	rootCmd.Flags().IntVar(&streamKB, "stream-chunk-kb", mcpserver.DefaultStreamChunkBytes>>10, "Split tool results larger than this many KiB into several content blocks, sent in progress notifications to clients supporting partial results (0: one block)")
+	rootCmd.Flags().DurationVar(&toolTimeout, "tool-timeout", mcpserver.DefaultToolTimeout, "Stop tool calls running longer than this, e.g. hung remotes (0: unlimited)")
+	rootCmd.Flags().IntVar(&callsPerMin, "rate-limit", 0, "Tool calls each session may start per minute, calls over it failing with rate_limited (0: unlimited)")
+	rootCmd.Flags().IntVar(&concurrent, "max-concurrent-calls", 0, "Tool calls each session may run at once (0: unlimited)")
+	rootCmd.Flags().IntVar(&pushesPerHr, "max-pushes-per-hour", 0, "Pushes each session may make per hour, with git_push and git_push_tags (0: unlimited)")
+	rootCmd.Flags().IntVar(&clonesPerHr, "max-clones-per-hour", 0, "Clones each session may make per hour (0: unlimited)")
+	rootCmd.Flags().DurationVar(&drainTimeout, "drain-timeout", mcp.DefaultDrainTimeout, "On SIGINT or SIGTERM, wait this long for the tool call in flight before cancelling it")
+	rootCmd.Flags().BoolVar(&locked, "locked", false, "Hide the tools that modify repositories from each session until it calls server_unlock (or the profile's locked)")
+	rootCmd.Flags().StringSliceVar(&enableTools, "enable-tools", nil, "Only expose these tools and tool groups, comma-separated: tool names, read, write, remote or destructive (default: all tools, or the profile's enable_tools)")
+	rootCmd.Flags().StringSliceVar(&disableTools, "disable-tools", nil, "Hide these tools and tool groups, comma-separated like --enable-tools and winning over it (added to the profile's disable_tools)")
+	rootCmd.Flags().BoolVar(&ignoreRoots, "ignore-roots", false, "Do not restrict repositories to the client's workspace roots, nor default repo_path to its single root")
+	rootCmd.Flags().IntVar(&pageSize, "tools-page-size", mcp.DefaultToolsPageSize, "Number of tools per page of tools/list (0: list all tools at once)")
+	rootCmd.Flags().StringVar(&wsAddr, "websocket", "", "Serve WebSocket clients on this address, e.g. 127.0.0.1:8765, instead of stdio")
+	rootCmd.Flags().StringVar(&wsPath, "websocket-path", "/", "HTTP path accepting WebSocket connections")
+	rootCmd.Flags().IntVar(&wsMaxMB, "websocket-max-message-mb", mcp.DefaultWebSocketMaxMessageBytes>>20, "Close WebSocket connections sending messages larger than this many MiB")
+	rootCmd.Flags().StringArrayVar(&wsOrigins, "websocket-origin", nil, "Origin of browser pages allowed to connect over WebSocket, e.g. http://localhost:3000 (repeatable, '*' allows all, default: no browser pages)")
+	rootCmd.Flags().StringVar(&authToken, "auth-token", "", "Bearer token WebSocket clients must present, in the Authorization header or the access_token query parameter (prefer --auth-token-file or $"+config.EnvAuthToken+", command lines are visible to other users)")
+	rootCmd.Flags().StringVar(&authFile, "auth-token-file", "", "File holding the bearer token WebSocket clients must present (default: $"+config.EnvAuthTokenFile+")")
+	rootCmd.Flags().StringVar(&oauthURL, "oauth-introspection-url", "", "Accept the OAuth2 access tokens this introspection endpoint (RFC 7662) reports as active, the client secret being read from $"+config.EnvOAuthClientSecret)
+	rootCmd.Flags().StringVar(&oauthClient, "oauth-client-id", "", "Client ID authenticating the server to the OAuth2 introspection endpoint")
+	rootCmd.Flags().StringVar(&oauthAud, "oauth-audience", "", "Audience OAuth2 access tokens must be issued for")
+	rootCmd.Flags().StringVar(&tlsCert, "tls-cert", "", "TLS certificate file (PEM) serving wss:// instead of ws://")
+	rootCmd.Flags().StringVar(&tlsKey, "tls-key", "", "TLS private key file (PEM) of --tls-cert")
+	rootCmd.Flags().BoolVar(&allowAnon, "allow-unauthenticated", false, "Serve WebSocket clients without authentication on addresses other hosts can reach")
+	rootCmd.Flags().BoolVar(&metrics, "metrics", false, "Serve Prometheus metrics of the tool calls on "+mcpserver.MetricsPath+" of the WebSocket server, authenticated like its clients")
+	rootCmd.Flags().StringVar(&otlpEndpoint, "otlp-endpoint", "", "Export a trace of every tool call, with its git operations, to this OTLP/HTTP traces URL, e.g. http://localhost:4318/v1/traces (default: $"+config.EnvOTLPTracesEndpoint+" or $"+config.EnvOTLPEndpoint+", headers from $"+config.EnvOTLPHeaders+")")
+	rootCmd.Flags().BoolVar(&pprof, "pprof", false, "Serve the runtime profiles of net/http/pprof on "+mcpserver.PprofPath+" of the WebSocket server, authenticated like its clients")
+	rootCmd.Flags().StringVar(&framing, "framing", string(mcp.FramingAuto), "Framing of the messages on stdio: newline, content-length (LSP-style headers), or auto to follow the client")
+	rootCmd.Flags().StringVar(&toolPrefix, "tool-prefix", "", "Prefix prepended to every tool name, e.g. 'repoA_' (default: the profile's tool_prefix)")
+	rootCmd.Flags().StringArrayVar(&instances, "instance", nil, "Also serve the tools of this profile from the configuration file, prefixed with its tool_prefix or '<name>_' (repeatable)")
+	rootCmd.Flags().StringVar(&signingKey, "signing-key", "", "OpenPGP private key file or exported keyring (SSH key with --signing-format ssh) signing commits and tags on request, decrypted with $"+config.EnvSigningPassphrase)
+	rootCmd.Flags().StringVar(&signingFmt, "signing-format", config.SigningFormatOpenPGP, "Format of the signing key: openpgp, or ssh for an SSH private key or the public key of an ssh-agent key (like gpg.format)")
+	rootCmd.Flags().StringVar(&signingKeyID, "signing-key-id", "", "ID or fingerprint of the key to use from the signing key file (default: its first private key)")
+	rootCmd.Flags().StringVar(&sshKey, "ssh-key", "", "SSH private key file, or public key of an ssh-agent key, authenticating to SSH remotes, decrypted with $"+config.EnvSSHPassphrase+" (default: all ssh-agent keys)")
+	rootCmd.Flags().StringArrayVar(&knownHosts, "ssh-known-hosts", nil, "known_hosts file verifying the host keys of SSH remotes (repeatable, default: ~/.ssh/known_hosts)")
+	rootCmd.Flags().BoolVar(&sshInsecure, "ssh-insecure-ignore-host-key", false, "Accept any host key of SSH remotes, without verification")
+	rootCmd.Flags().BoolVar(&container, "container", config.ContainerMode(), "Container mode: read settings from MCP_GIT_* environment variables, trust mounted repositories and never prompt (default from "+config.EnvContainer+")")
+
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func runServer(cmd *cobra.Command, args []string) {
+	closeLog, err := logging.Setup(logging.Options{Verbose: verbose, File: logFile, Format: logFormat})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer closeLog()
+
+	messageFraming, err := mcp.ParseFraming(framing)
+	if err != nil {
+		fatal(err)
+	}
+
+	// SIGINT and SIGTERM stop the server after the request in flight, a
+	// second signal kills it right away
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	
+	aliases, err := config.ParseAliases(repoAliases)
+	if err != nil {
+		fatal(err)
+	}
+
+	cfg := mcpserver.Config{
+		Repositories:     repositories,
+		Aliases:          aliases,
+		AliasCloneDir:    aliasDir,
+		Workspaces:       workspaces,
+		DiscoveryDepth:   discoverDeep,
+		UserName:         userName,
+		UserEmail:        userEmail,
+		ScratchDir:       scratchDir,
+		ScratchMaxBytes:  scratchMaxMB << 20,
+		RepoCacheSize:    repoCache,
+		TrashDir:         trashDir,
+		NoTrash:          noTrash,
+		SafeDirectories:  safeDirs,
+		ResultStyle:      resultStyle,
+		MaxOutputBytes:   maxOutputKB << 10,
+		StreamChunkBytes: streamKB << 10,
+		ToolTimeout:      toolTimeout,
+		CallsPerMinute:   callsPerMin,
+		ConcurrentCalls:  concurrent,
+		PushesPerHour:    pushesPerHr,
+		ClonesPerHour:    clonesPerHr,
+		DrainTimeout:     drainTimeout,
+		ToolsPageSize:    pageSize,
+		Framing:          messageFraming,
+		WebSocketAddr:    wsAddr,
+		WebSocket:        mcp.WebSocketOptions{Path: wsPath, MaxMessageBytes: wsMaxMB << 20, AllowedOrigins: wsOrigins, TLSCertFile: tlsCert, TLSKeyFile: tlsKey},
+		Locked:           locked,
+		EnableTools:      enableTools,
+		DisableTools:     disableTools,
+		IgnoreRoots:      ignoreRoots,
+		ToolPrefix:       toolPrefix,
+	}
+	if signingKey != "" {
+		key := &config.SigningKey{Format: signingFmt, KeyFile: signingKey, KeyID: signingKeyID, PassphraseEnv: config.EnvSigningPassphrase}
+		if err := loadSigningKey(&cfg, key); err != nil {
+			fatal(err)
+		}
+	}
+	if sshKey != "" || len(knownHosts) > 0 || sshInsecure {
+		ssh := &config.SSH{KeyFile: sshKey, PassphraseEnv: config.EnvSSHPassphrase, KnownHosts: knownHosts, InsecureIgnoreHostKey: sshInsecure}
+		auth, err := ssh.Auth()
+		if err != nil {
+			fatal(err)
+		}
+		cfg.SSHAuth = auth
+	}
+	if profileName != "" {
+		if err := applyProfile(&cfg, profileName); err != nil {
+			fatal(err)
+		}
+	}
+	if container {
+		if err := applyContainer(&cfg); err != nil {
+			fatal(err)
+		}
+	}
+	// HTTPS tokens from the environment apply without a profile or
+	// container mode too
+	if creds := config.EnvCredentials(); cfg.Auth == nil && creds != nil {
+		auth, err := creds.Auth()
+		if err != nil {
+			fatal(err)
+		}
+		cfg.Auth = auth
+	}
+	// Tokens given by the environment are ignored on stdio
+	if wsAddr != "" {
+		if cfg.WebSocket.Authenticate, err = webSocketAuth(); err != nil {
+			fatal(err)
+		}
+	} else if authToken != "" || authFile != "" || oauthURL != "" {
+		fatal(fmt.Errorf("authentication applies to the WebSocket transport, which is not enabled"))
+	}
+	cfg.AllowUnauthenticated = allowAnon
+	cfg.Metrics, cfg.Pprof = metrics, pprof
+	if endpoint := config.OTLPTracesEndpoint(otlpEndpoint); endpoint != "" {
+		headers, err := tracing.ParseHeaders(config.OTLPHeaders())
+		if err != nil {
+			fatal(err)
+		}
+		cfg.Tracing = &mcpserver.TracingOptions{Endpoint: endpoint, Headers: headers}
+	}
+
+	srv, err := mcpserver.New(mcpserver.WithConfig(cfg))
+	if err != nil {
+		fatal(err)
+	}
+	for _, name := range instances {
+		if _, err := srv.Mount(mcpserver.WithConfig(instanceConfig(cfg, name))); err != nil {
+			fatal(fmt.Errorf("instance '%s': %w", name, err))
+		}
+	}
+	if err := srv.Serve(ctx); err != nil {
+		fatal(err)
+	}
+}
+
+// applyProfile fills the server configuration from a workspace profile.
+// Values given on the command line take precedence over the profile.
+func applyProfile(cfg *mcpserver.Config, name string) error {
+	path := configPath
+	if path == "" {
+		path = config.DefaultPath()
+	}
+
+	file, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+	profile, err := file.Profile(name)
+	if err != nil {
+		return err
+	}
+
+	if len(cfg.Repositories) == 0 {
+		cfg.Repositories = profile.Repositories
+	}
+	if cfg.Aliases == nil {
+		cfg.Aliases = make(map[string]string, len(profile.Aliases))
+	}
+	for alias, target := range profile.Aliases {
+		if _, ok := cfg.Aliases[alias]; !ok {
+			cfg.Aliases[alias] = target
+		}
+	}
+	cfg.Workspaces = append(cfg.Workspaces, profile.Workspaces...)
+	if cfg.UserName == "" {
+		cfg.UserName = profile.UserName
+	}
+	if cfg.UserEmail == "" {
+		cfg.UserEmail = profile.UserEmail
+	}
+	if cfg.ResultStyle == "" {
+		cfg.ResultStyle = profile.ResultStyle
+	}
+	if cfg.ToolPrefix == "" {
+		cfg.ToolPrefix = profile.ToolPrefix
+	}
+	cfg.CommitPolicy = profile.CommitPolicy
+	cfg.ReadOnly = profile.ReadOnly
+	if len(cfg.EnableTools) == 0 {
+		cfg.EnableTools = profile.EnableTools
+	}
+	cfg.DisableTools = append(cfg.DisableTools, profile.DisableTools...)
+	cfg.RestrictRepositories = profile.RestrictRepositories
+	cfg.Locked = cfg.Locked || profile.Locked
+
+	if profile.Credentials != nil {
+		cfg.Auth, err = profile.Credentials.Auth()
+		if err != nil {
+			return fmt.Errorf("profile '%s': %w", name, err)
+		}
+	}
+	if cfg.SSHAuth == nil && profile.SSH != nil {
+		cfg.SSHAuth, err = profile.SSH.Auth()
+		if err != nil {
+			return fmt.Errorf("profile '%s': %w", name, err)
+		}
+	}
+	if cfg.SigningKey == nil && cfg.SSHSigningKey == nil && profile.SigningKey != nil {
+		if err := loadSigningKey(cfg, profile.SigningKey); err != nil {
+			return fmt.Errorf("profile '%s': %w", name, err)
+		}
+	}
+
+	slog.Info("Using profile", "profile", name, "path", path)
+	return nil
+}
+
+// loadSigningKey loads the OpenPGP or SSH signing key of the server
+func loadSigningKey(cfg *mcpserver.Config, key *config.SigningKey) error {
+	isSSH, err := key.IsSSH()
+	if err != nil {
+		return err
+	}
+	if isSSH {
+		cfg.SSHSigningKey, err = key.SSHSigner()
+	} else {
+		cfg.SigningKey, err = key.Entity()
+	}
+	return err
+}
+
+// instanceConfig builds the configuration of an instance served next to the
+// main one from a profile. Process-wide settings are taken from the main
+// configuration, everything else from the profile.
+func instanceConfig(base mcpserver.Config, name string) mcpserver.Config {
+	cfg := mcpserver.Config{
+		AliasCloneDir:    base.AliasCloneDir,
+		DiscoveryDepth:   base.DiscoveryDepth,
+		ScratchDir:       base.ScratchDir,
+		ScratchMaxBytes:  base.ScratchMaxBytes,
+		RepoCacheSize:    base.RepoCacheSize,
+		TrashDir:         base.TrashDir,
+		NoTrash:          base.NoTrash,
+		SafeDirectories:  append([]string(nil), base.SafeDirectories...),
+		NonInteractive:   base.NonInteractive,
+		ResultStyle:      resultStyle,
+		MaxOutputBytes:   base.MaxOutputBytes,
+		StreamChunkBytes: base.StreamChunkBytes,
+		ToolTimeout:      base.ToolTimeout,
+		IgnoreRoots:      base.IgnoreRoots,
+	}
+	if err := applyProfile(&cfg, name); err != nil {
+		fatal(err)
+	}
+	if cfg.ToolPrefix == "" {
+		cfg.ToolPrefix = name + "_"
+	}
+	if container {
+		cfg.SafeDirectories = append(cfg.SafeDirectories, cfg.Repositories...)
+	}
+	return cfg
+}
+
+// applyContainer fills the server configuration from the environment of a
+// container. Bind-mounted repositories are usually owned by another user
+// than the one running the server, so they are trusted explicitly.
+func applyContainer(cfg *mcpserver.Config) error {
+	env := config.FromEnv()
+
+	if len(cfg.Repositories) == 0 {
+		cfg.Repositories = env.Repositories
+	}
+	if cfg.UserName == "" {
+		cfg.UserName = env.UserName
+	}
+	if cfg.UserEmail == "" {
+		cfg.UserEmail = env.UserEmail
+	}
+	if cfg.ScratchDir == "" {
+		cfg.ScratchDir = env.ScratchDir
+	}
+	if cfg.Auth == nil && env.Credentials != nil {
+		auth, err := env.Credentials.Auth()
+		if err != nil {
+			return err
+		}
+		cfg.Auth = auth
+	}
+	if cfg.SSHAuth == nil && env.SSH != nil {
+		auth, err := env.SSH.Auth()
+		if err != nil {
+			return err
+		}
+		cfg.SSHAuth = auth
+	}
+	if cfg.SigningKey == nil && cfg.SSHSigningKey == nil && env.SigningKey != nil {
+		if err := loadSigningKey(cfg, env.SigningKey); err != nil {
+			return err
+		}
+	}
+
+	cfg.SafeDirectories = append(cfg.SafeDirectories, cfg.Repositories...)
+	cfg.NonInteractive = true
+
+	slog.Info("Container mode", "repositories", len(cfg.Repositories))
+	return nil
+}
+
+// fatal logs an error that prevents the server from running and exits
+func fatal(err error) {
+	slog.Error(err.Error())
+	os.Exit(1)
+}
+
+// webSocketAuth returns the validator of the bearer tokens of WebSocket
+// clients, nil without authentication
+func webSocketAuth() (mcp.TokenValidator, error) {
+	token, err := config.AuthToken(authToken, authFile)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" && oauthURL != "" {
+		return nil, fmt.Errorf("use either an auth token or OAuth2 token introspection, not both")
+	}
+	switch {
+	case token != "":
+		return mcp.StaticTokens(token), nil
+	case oauthURL != "":
+		return mcp.IntrospectTokens(mcp.IntrospectionOptions{
+			URL:          oauthURL,
+			ClientID:     oauthClient,
+			ClientSecret: os.Getenv(config.EnvOAuthClientSecret),
+			Audience:     oauthAud,
+		}), nil
+	}
+	return nil, nil
+}
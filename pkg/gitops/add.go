@@ -0,0 +1,202 @@
+package gitops
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// AddOptions selects the changes staged by Add besides the given paths
+type AddOptions struct {
+	// All stages every change, including untracked files and deletions,
+	// like git add -A. With paths it is limited to them.
+	All bool
+	// Update stages modifications and deletions of tracked files only,
+	// like git add -u. With paths it is limited to them.
+	Update bool
+	// Force also stages untracked files matched by ignore rules, like
+	// git add -f
+	Force bool
+}
+
+// Add stages the changes of the files matching the given pathspecs. A
+// pathspec is a file, a directory (matching everything below it), "." or a
+// glob pattern where * and ** match any characters including slashes, as
+// in git. Untracked files matched by .gitignore, the repository excludes
+// or the global excludes are skipped unless opts.Force is set.
+func (g *Operations) Add(repoPath string, files []string, opts AddOptions) (string, error) {
+	if opts.All && opts.Update {
+		return "", fmt.Errorf("all and update are mutually exclusive")
+	}
+	if len(files) == 0 && !opts.All && !opts.Update {
+		return "", fmt.Errorf("no files specified (use all or update to stage every change)")
+	}
+
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	specs, err := compilePathspecs(files)
+	if err != nil {
+		return "", err
+	}
+
+	status, err := worktree.Status()
+	if err != nil {
+		return "", fmt.Errorf("failed to get status: %w", err)
+	}
+
+	var paths []string
+	matched := make([]bool, len(specs))
+	for path, fileStatus := range status {
+		if fileStatus.Worktree == git.Unmodified {
+			continue
+		}
+		if opts.Update && fileStatus.Worktree == git.Untracked {
+			continue
+		}
+		if matchPathspecs(specs, path, matched) {
+			paths = append(paths, path)
+		}
+	}
+
+	// Pathspecs naming only unchanged tracked files are fine
+	if idx, err := repo.Storer.Index(); err == nil {
+		for _, entry := range idx.Entries {
+			matchPathspecs(specs, entry.Name, matched)
+		}
+	}
+
+	// go-git's status leaves ignored files out. They are listed when forced,
+	// or to tell why a pathspec matches nothing else.
+	force := opts.Force && !opts.Update
+	unmatched := false
+	for i := range specs {
+		unmatched = unmatched || !matched[i]
+	}
+	if force || unmatched {
+		ignored, err := ignoredFiles(repo, worktree, false)
+		if err != nil {
+			return "", err
+		}
+		ignoredMatched := make([]bool, len(specs))
+		for _, file := range ignored {
+			if matchPathspecs(specs, file.path, ignoredMatched) && force {
+				paths = append(paths, file.path)
+			}
+		}
+		for i, spec := range specs {
+			switch {
+			case matched[i]:
+			case ignoredMatched[i] && !force:
+				return "", fmt.Errorf("pathspec '%s' only matches files ignored by .gitignore (use force to add them)", spec.pattern)
+			case !ignoredMatched[i]:
+				return "", fmt.Errorf("pathspec '%s' did not match any files", spec.pattern)
+			}
+		}
+	}
+
+	if len(paths) == 0 {
+		return "No changes to stage", nil
+	}
+
+	sort.Strings(paths)
+	for _, path := range paths {
+		if _, err := worktree.Add(path); err != nil {
+			return "", fmt.Errorf("failed to add file %s: %w", path, err)
+		}
+	}
+
+	return "Files staged successfully", nil
+}
+
+// pathspec is a compiled git add pathspec
+type pathspec struct {
+	pattern string
+	re      *regexp.Regexp
+}
+
+// compilePathspecs turns literal paths and glob patterns into regular
+// expressions. Literal paths also match everything below them.
+func compilePathspecs(files []string) ([]pathspec, error) {
+	specs := make([]pathspec, 0, len(files))
+	for _, file := range files {
+		path := strings.Trim(filepath.ToSlash(filepath.Clean(file)), "/")
+		if path == "." || path == "" {
+			specs = append(specs, pathspec{pattern: file, re: regexp.MustCompile("")})
+			continue
+		}
+		if !strings.ContainsAny(path, "*?[") {
+			specs = append(specs, pathspec{pattern: file, re: regexp.MustCompile("^" + regexp.QuoteMeta(path) + "(/|$)")})
+			continue
+		}
+
+		re, err := globRegexp(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pathspec '%s': %w", file, err)
+		}
+		specs = append(specs, pathspec{pattern: file, re: re})
+	}
+	return specs, nil
+}
+
+// globRegexp converts a glob pattern to an anchored regular expression. As
+// in git pathspecs, * and ? also match slashes.
+func globRegexp(pattern string) (*regexp.Regexp, error) {
+	var expr strings.Builder
+	expr.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			expr.WriteString(".*")
+			for i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+			}
+		case '?':
+			expr.WriteString(".")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated character class")
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expr.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	// A pattern matching a directory also matches everything below it
+	expr.WriteString("(/|$)")
+	return regexp.Compile(expr.String())
+}
+
+// matchPathspecs reports whether path matches any pathspec, an empty set
+// matching every path, and records which pathspecs matched
+func matchPathspecs(specs []pathspec, path string, matched []bool) bool {
+	if len(specs) == 0 {
+		return true
+	}
+	found := false
+	for i, spec := range specs {
+		if spec.re.MatchString(path) {
+			matched[i] = true
+			found = true
+		}
+	}
+	return found
+}
@@ -0,0 +1,129 @@
+package gitops
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOperations_AddPathspecs(t *testing.T) {
+	tempDir, _ := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	commitFile(t, ops, tempDir, "tracked.go", "package main\n", "Add tracked")
+	commitFile(t, ops, tempDir, ".gitignore", "*.log\n", "Add ignore rules")
+	for name, content := range map[string]string{
+		"tracked.go":       "package main // changed\n",
+		"main.go":          "package main\n",
+		"src/app/app.go":   "package app\n",
+		"src/app/README":   "readme\n",
+		"docs/guide.md":    "guide\n",
+		"docs/debug.log":   "log\n",
+		"other/notes.txt":  "notes\n",
+		"other/more/x.txt": "x\n",
+	} {
+		path := filepath.Join(tempDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	if err := os.Remove(filepath.Join(tempDir, "test.txt")); err != nil {
+		t.Fatalf("Failed to remove file: %v", err)
+	}
+
+	// Update stages tracked files only
+	if _, err := ops.Add(tempDir, nil, AddOptions{Update: true}); err != nil {
+		t.Fatalf("Add update failed: %v", err)
+	}
+	status, _ := ops.Status(tempDir, false)
+	expected := "Staged (2):\n  deleted: test.txt\n  modified: tracked.go\n\n" +
+		"Untracked (6):\n  docs/guide.md\n  main.go\n  other/more/x.txt\n  other/notes.txt\n  src/app/README\n  src/app/app.go"
+	if status != expected {
+		t.Errorf("Unexpected status after update: %q", status)
+	}
+
+	// Globs match at any depth, directories match everything below them
+	if _, err := ops.Add(tempDir, []string{"*.go", "other"}, AddOptions{}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	status, _ = ops.Status(tempDir, false)
+	expected = "Staged (6):\n  added: main.go\n  added: other/more/x.txt\n  added: other/notes.txt\n  added: src/app/app.go\n  deleted: test.txt\n  modified: tracked.go\n\n" +
+		"Untracked (2):\n  docs/guide.md\n  src/app/README"
+	if status != expected {
+		t.Errorf("Unexpected status after globs: %q", status)
+	}
+
+	if _, err := ops.Add(tempDir, []string{"*.rs"}, AddOptions{}); err == nil {
+		t.Error("Expected error for a pathspec matching nothing")
+	}
+	if result, err := ops.Add(tempDir, []string{"tracked.go"}, AddOptions{}); err != nil || result != "No changes to stage" {
+		t.Errorf("Expected nothing to stage, got: %s, %v", result, err)
+	}
+
+	// All stages the rest but never ignored files
+	if _, err := ops.Add(tempDir, []string{"src/**"}, AddOptions{All: true}); err != nil {
+		t.Fatalf("Add all failed: %v", err)
+	}
+	status, _ = ops.Status(tempDir, false)
+	if !contains(status, "added: src/app/README") || !contains(status, "Untracked (1):\n  docs/guide.md") {
+		t.Errorf("Unexpected status after limited all: %q", status)
+	}
+	if _, err := ops.Add(tempDir, nil, AddOptions{All: true}); err != nil {
+		t.Fatalf("Add all failed: %v", err)
+	}
+	status, _ = ops.Status(tempDir, true)
+	if contains(status, "Untracked") || !contains(status, "added: docs/guide.md") || !contains(status, "Ignored (1):\n  docs/debug.log") {
+		t.Errorf("Unexpected status after all: %q", status)
+	}
+
+	if _, err := ops.Add(tempDir, nil, AddOptions{}); err == nil {
+		t.Error("Expected error without files")
+	}
+}
+
+func TestOperations_AddIgnored(t *testing.T) {
+	tempDir, _ := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	commitFile(t, ops, tempDir, ".gitignore", "node_modules/\n*.o\n", "Add ignore rules")
+	for _, name := range []string{"main.c", "main.o", "node_modules/pkg/index.js"} {
+		path := filepath.Join(tempDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	// Adding everything skips ignored files
+	if _, err := ops.Add(tempDir, []string{"."}, AddOptions{}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	status, _ := ops.Status(tempDir, true)
+	if status != "Staged (1):\n  added: main.c\n\nIgnored (2):\n  main.o\n  node_modules/" {
+		t.Errorf("Unexpected status: %q", status)
+	}
+
+	// Naming an ignored file needs force
+	if _, err := ops.Add(tempDir, []string{"main.o"}, AddOptions{}); err == nil || !contains(err.Error(), "use force") {
+		t.Errorf("Expected error for an ignored file, got: %v", err)
+	}
+	if _, err := ops.Add(tempDir, []string{"main.o"}, AddOptions{Force: true}); err != nil {
+		t.Fatalf("Forced add failed: %v", err)
+	}
+	if _, err := ops.Add(tempDir, []string{"."}, AddOptions{All: true, Force: true}); err != nil {
+		t.Fatalf("Forced add failed: %v", err)
+	}
+	status, _ = ops.Status(tempDir, true)
+	if status != "Staged (3):\n  added: main.c\n  added: main.o\n  added: node_modules/pkg/index.js" {
+		t.Errorf("Unexpected status after forced add: %q", status)
+	}
+}
@@ -0,0 +1,246 @@
+package gitops
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// commitsBetween returns the commits reachable from include but not from
+// exclude (git log exclude..include), newest first by committer time
+func commitsBetween(repo *git.Repository, exclude, include plumbing.Hash) ([]*object.Commit, error) {
+	return rangeCommits(repo, []plumbing.Hash{include}, []plumbing.Hash{exclude})
+}
+
+// patchID computes a stable identifier of the change a commit introduces
+// relative to its first parent, ignoring whitespace and line numbers in the
+// same spirit as git patch-id --stable. Merge commits have no patch ID.
+func patchID(commit *object.Commit) (string, error) {
+	if commit.NumParents() > 1 {
+		return "", nil
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get tree: %w", err)
+	}
+
+	var parentTree *object.Tree
+	if commit.NumParents() == 1 {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return "", fmt.Errorf("failed to get parent: %w", err)
+		}
+		parentTree, err = parent.Tree()
+		if err != nil {
+			return "", fmt.Errorf("failed to get parent tree: %w", err)
+		}
+	}
+
+	changes, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		return "", fmt.Errorf("failed to diff trees: %w", err)
+	}
+
+	patch, err := changes.Patch()
+	if err != nil {
+		return "", fmt.Errorf("failed to compute patch: %w", err)
+	}
+
+	filePatches := patch.FilePatches()
+	sort.Slice(filePatches, func(i, j int) bool {
+		return patchPath(filePatches[i]) < patchPath(filePatches[j])
+	})
+
+	hasher := sha1.New()
+	for _, fp := range filePatches {
+		from, to := fp.Files()
+		if from != nil {
+			fmt.Fprintf(hasher, "a/%s\n", from.Path())
+		}
+		if to != nil {
+			fmt.Fprintf(hasher, "b/%s\n", to.Path())
+		}
+		if fp.IsBinary() {
+			if to != nil {
+				fmt.Fprintf(hasher, "binary %s\n", to.Hash())
+			}
+			continue
+		}
+		for _, chunk := range fp.Chunks() {
+			var sign string
+			switch chunk.Type() {
+			case fdiff.Add:
+				sign = "+"
+			case fdiff.Delete:
+				sign = "-"
+			default:
+				continue
+			}
+			for _, line := range strings.Split(strings.TrimSuffix(chunk.Content(), "\n"), "\n") {
+				fmt.Fprintf(hasher, "%s%s\n", sign, strings.Join(strings.Fields(line), ""))
+			}
+		}
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// FindEquivalentCommits compares the commits unique to head and to upstream
+// (since their merge base) and pairs those introducing the same patch, like
+// git cherry. Commits of head without an equivalent on upstream still need
+// to be applied (backported) there.
+func (g *Operations) FindEquivalentCommits(repoPath, upstream, head string) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	if head == "" {
+		head = "HEAD"
+	}
+
+	upstreamHash, err := resolveRevision(repo, upstream)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve upstream '%s': %w", upstream, err)
+	}
+	headHash, err := resolveRevision(repo, head)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve head '%s': %w", head, err)
+	}
+
+	headOnly, err := commitsBetween(repo, *upstreamHash, *headHash)
+	if err != nil {
+		return "", err
+	}
+	upstreamOnly, err := commitsBetween(repo, *headHash, *upstreamHash)
+	if err != nil {
+		return "", err
+	}
+
+	upstreamByID := make(map[string]*object.Commit)
+	for _, c := range upstreamOnly {
+		id, err := patchID(c)
+		if err != nil {
+			return "", err
+		}
+		if id != "" {
+			upstreamByID[id] = c
+		}
+	}
+
+	var equivalent, missing []string
+	matched := make(map[plumbing.Hash]bool)
+	for _, c := range headOnly {
+		id, err := patchID(c)
+		if err != nil {
+			return "", err
+		}
+		if other, ok := upstreamByID[id]; ok && id != "" {
+			matched[other.Hash] = true
+			equivalent = append(equivalent, fmt.Sprintf("= %s == %s %s", c.Hash.String()[:7], other.Hash.String()[:7], commitSubject(c)))
+			continue
+		}
+		missing = append(missing, fmt.Sprintf("+ %s %s", c.Hash.String()[:7], commitSubject(c)))
+	}
+
+	var upstreamMissing []string
+	for _, c := range upstreamOnly {
+		if !matched[c.Hash] {
+			upstreamMissing = append(upstreamMissing, fmt.Sprintf("+ %s %s", c.Hash.String()[:7], commitSubject(c)))
+		}
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Comparing %s (%d unique commits) with %s (%d unique commits)\n",
+		head, len(headOnly), upstream, len(upstreamOnly)))
+	writeSection := func(title string, lines []string) {
+		result.WriteString(fmt.Sprintf("\n%s (%d):\n", title, len(lines)))
+		for _, line := range lines {
+			result.WriteString("  " + line + "\n")
+		}
+	}
+	writeSection(fmt.Sprintf("Equivalent patches (%s == %s)", head, upstream), equivalent)
+	writeSection(fmt.Sprintf("Only on %s, not yet in %s", head, upstream), missing)
+	writeSection(fmt.Sprintf("Only on %s, not yet in %s", upstream, head), upstreamMissing)
+
+	return strings.TrimSpace(result.String()), nil
+}
+
+// CompareRefs counts the commits head is ahead of and behind base, like
+// git rev-list --left-right --count base...head, and reports their merge
+// base
+func (g *Operations) CompareRefs(repoPath, base, head string) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	if head == "" {
+		head = "HEAD"
+	}
+
+	baseHash, err := resolveRevision(repo, base)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve base '%s': %w", base, err)
+	}
+	headHash, err := resolveRevision(repo, head)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve head '%s': %w", head, err)
+	}
+
+	ahead, err := commitsBetween(repo, *baseHash, *headHash)
+	if err != nil {
+		return "", err
+	}
+	behind, err := commitsBetween(repo, *headHash, *baseHash)
+	if err != nil {
+		return "", err
+	}
+	bases, err := mergeBases(repo, *baseHash, *headHash)
+	if err != nil {
+		return "", err
+	}
+
+	mergeBase := "none (unrelated histories)"
+	if len(bases) > 0 {
+		mergeBase = bases[0].String()
+	}
+
+	var status string
+	switch {
+	case len(ahead) == 0 && len(behind) == 0:
+		status = "up to date"
+	case len(behind) == 0:
+		status = "ahead"
+	case len(ahead) == 0:
+		status = "behind"
+	default:
+		status = "diverged"
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Head: %s (%s)\n", head, headHash.String()[:7]))
+	result.WriteString(fmt.Sprintf("Base: %s (%s)\n", base, baseHash.String()[:7]))
+	result.WriteString(fmt.Sprintf("Ahead: %d\n", len(ahead)))
+	result.WriteString(fmt.Sprintf("Behind: %d\n", len(behind)))
+	result.WriteString(fmt.Sprintf("Merge base: %s\n", mergeBase))
+	result.WriteString(fmt.Sprintf("Status: %s", status))
+
+	return result.String(), nil
+}
+
+// commitSubject returns the first line of a commit message
+func commitSubject(c *object.Commit) string {
+	subject, _, _ := strings.Cut(strings.TrimSpace(c.Message), "\n")
+	return subject
+}
@@ -0,0 +1,110 @@
+package gitops
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// commitFile writes a file and commits it, failing the test on error
+func commitFile(t *testing.T, ops *Operations, repoPath, name, content, message string) {
+	t.Helper()
+
+	if err := os.WriteFile(filepath.Join(repoPath, name), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	if _, err := ops.Add(repoPath, []string{name}, AddOptions{}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if _, err := ops.Commit(repoPath, message, CommitOptions{}); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+}
+
+func TestOperations_FindEquivalentCommits(t *testing.T) {
+	tempDir, _ := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	// The same fix lands on both branches, plus one feature-only commit
+	if _, err := ops.Checkout(tempDir, "feature", true, ""); err != nil {
+		t.Fatalf("Checkout failed: %v", err)
+	}
+	commitFile(t, ops, tempDir, "fix.txt", "fix\n", "Fix bug")
+	commitFile(t, ops, tempDir, "feature.txt", "feature\n", "Add feature")
+
+	if _, err := ops.Checkout(tempDir, "master", false, ""); err != nil {
+		t.Fatalf("Checkout failed: %v", err)
+	}
+	commitFile(t, ops, tempDir, "fix.txt", "fix\n", "Fix bug (cherry picked)")
+
+	result, err := ops.FindEquivalentCommits(tempDir, "master", "feature")
+	if err != nil {
+		t.Fatalf("FindEquivalentCommits failed: %v", err)
+	}
+
+	for _, expected := range []string{
+		"Equivalent patches (feature == master) (1)",
+		"Only on feature, not yet in master (1)",
+		"Add feature",
+		"Only on master, not yet in feature (0)",
+	} {
+		if !contains(result, expected) {
+			t.Errorf("Expected %q in result, got: %s", expected, result)
+		}
+	}
+}
+
+func TestOperations_CompareRefs(t *testing.T) {
+	tempDir, repo := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("Failed to get HEAD: %v", err)
+	}
+	base := head.Hash().String()
+
+	if _, err := ops.Checkout(tempDir, "feature", true, ""); err != nil {
+		t.Fatalf("Checkout failed: %v", err)
+	}
+	commitFile(t, ops, tempDir, "a.txt", "a\n", "Add a")
+	commitFile(t, ops, tempDir, "b.txt", "b\n", "Add b")
+
+	result, err := ops.CompareRefs(tempDir, "master", "feature")
+	if err != nil {
+		t.Fatalf("CompareRefs failed: %v", err)
+	}
+	for _, expected := range []string{"Ahead: 2\n", "Behind: 0\n", "Merge base: " + base + "\n", "Status: ahead"} {
+		if !contains(result, expected) {
+			t.Errorf("Expected %q in result, got: %s", expected, result)
+		}
+	}
+
+	if _, err := ops.Checkout(tempDir, "master", false, ""); err != nil {
+		t.Fatalf("Checkout failed: %v", err)
+	}
+	commitFile(t, ops, tempDir, "c.txt", "c\n", "Add c")
+
+	result, err = ops.CompareRefs(tempDir, "master", "feature")
+	if err != nil {
+		t.Fatalf("CompareRefs failed: %v", err)
+	}
+	for _, expected := range []string{"Ahead: 2\n", "Behind: 1\n", "Merge base: " + base + "\n", "Status: diverged"} {
+		if !contains(result, expected) {
+			t.Errorf("Expected %q in result, got: %s", expected, result)
+		}
+	}
+
+	// head defaults to HEAD, which is master here
+	result, err = ops.CompareRefs(tempDir, "master", "")
+	if err != nil {
+		t.Fatalf("CompareRefs failed: %v", err)
+	}
+	if !contains(result, "Status: up to date") {
+		t.Errorf("Expected master to be up to date with itself, got: %s", result)
+	}
+}
@@ -0,0 +1,131 @@
+package gitops
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ApplyOptions selects how Apply applies a patch
+type ApplyOptions struct {
+	// Cached applies the patch to the index only, leaving the working tree
+	// untouched
+	Cached bool
+	// ThreeWay falls back to a 3-way merge with the blobs the patch records,
+	// leaving conflict markers when the hunks do not apply
+	ThreeWay bool
+	// Reject applies the hunks that apply and writes the others to .rej
+	// files, instead of applying nothing
+	Reject bool
+	// Check only reports whether the patch applies
+	Check bool
+}
+
+var (
+	applyChecking  = regexp.MustCompile(`^Checking patch (.+)\.\.\.$`)
+	applyClean     = regexp.MustCompile(`^Applied patch (.+) cleanly\.$`)
+	applyConflicts = regexp.MustCompile(`^Applied patch (.+) with conflicts\.$`)
+	applyRejects   = regexp.MustCompile(`^Applying patch (.+) with (\d+) rejects?\.\.\.$`)
+	applyHunk      = regexp.MustCompile(`^(?:Hunk #\d+ applied cleanly|Rejected hunk #\d+)\.$`)
+)
+
+// Apply applies a unified diff, as produced by git diff or written by hand,
+// to the working tree or the index with the git executable. Hunk line
+// counts are recomputed, so hand-written hunk headers only need the right
+// start lines.
+func (g *Operations) Apply(repoPath, patch string, opts ApplyOptions) (string, error) {
+	if strings.TrimSpace(patch) == "" {
+		return "", fmt.Errorf("patch is required")
+	}
+	if opts.ThreeWay && opts.Reject {
+		return "", fmt.Errorf("three_way and reject cannot be combined")
+	}
+	if !strings.HasSuffix(patch, "\n") {
+		patch += "\n"
+	}
+
+	args := []string{"apply", "--verbose", "--recount"}
+	if opts.Cached {
+		args = append(args, "--cached")
+	}
+	if opts.ThreeWay {
+		args = append(args, "--3way")
+	}
+	if opts.Reject {
+		args = append(args, "--reject")
+	}
+	if opts.Check {
+		args = append(args, "--check")
+	}
+
+	cmd := g.gitCommand(repoPath, args...)
+	cmd.Stdin = strings.NewReader(patch)
+	output, err := g.runCommand(cmd)
+	if _, ok := err.(*DubiousOwnershipError); ok {
+		return "", err
+	}
+
+	var files, rejected []string
+	status := make(map[string]string)
+	var current string
+	for _, line := range strings.Split(string(output), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case applyChecking.MatchString(line):
+			current = applyChecking.FindStringSubmatch(line)[1]
+			files = append(files, current)
+		case applyClean.MatchString(line):
+			status[applyClean.FindStringSubmatch(line)[1]] = "applied"
+		case applyConflicts.MatchString(line):
+			status[applyConflicts.FindStringSubmatch(line)[1]] = "applied with conflicts, resolve them in the file"
+		case applyRejects.MatchString(line):
+			m := applyRejects.FindStringSubmatch(line)
+			current = m[1]
+			status[current] = fmt.Sprintf("%s hunk(s) rejected, see %s.rej", m[2], current)
+			rejected = append(rejected, current)
+		case applyHunk.MatchString(line) && current != "":
+			status[current] += "\n    " + strings.TrimSuffix(line, ".")
+		}
+	}
+
+	var conflicts bool
+	for _, file := range files {
+		conflicts = conflicts || strings.HasPrefix(status[file], "applied with conflicts")
+	}
+	if opts.Check {
+		if err != nil {
+			return fmt.Sprintf("Patch does not apply:\n%s", strings.TrimSpace(string(output))), nil
+		}
+		return fmt.Sprintf("Patch applies cleanly to %d file(s)", len(files)), nil
+	}
+	if err != nil && len(rejected) == 0 && !conflicts {
+		return "", fmt.Errorf("patch does not apply:\n%s", strings.TrimSpace(string(output)))
+	}
+
+	target := "the working tree"
+	if opts.Cached {
+		target = "the index"
+	}
+	var result strings.Builder
+	fmt.Fprintf(&result, "Applied patch to %s:", target)
+	for _, file := range files {
+		fileStatus := status[file]
+		if fileStatus == "" {
+			fileStatus = "applied"
+		}
+		fmt.Fprintf(&result, "\n  %s: %s", file, fileStatus)
+	}
+
+	// The rejected hunks are shown, so they can be fixed and applied again
+	for _, file := range rejected {
+		content, err := os.ReadFile(filepath.Join(repoPath, file+".rej"))
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(&result, "\n\nRejected hunks of %s:\n%s", file, strings.TrimSuffix(string(content), "\n"))
+	}
+
+	return result.String(), nil
+}
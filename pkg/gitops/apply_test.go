@@ -0,0 +1,79 @@
+package gitops
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOperations_Apply(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not available")
+	}
+	tempDir, repo := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+	commitFile(t, ops, tempDir, "file.txt", "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n", "Add file")
+
+	// Hand-written hunk headers with wrong line counts still apply
+	patch := "--- a/file.txt\n+++ b/file.txt\n@@ -1,9 +1,9 @@\n a\n-b\n+B\n c\n"
+	result, err := ops.Apply(tempDir, patch, ApplyOptions{Check: true})
+	if err != nil || result != "Patch applies cleanly to 1 file(s)" {
+		t.Fatalf("Check failed: %s (%v)", result, err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(tempDir, "file.txt")); strings.Contains(string(content), "B") {
+		t.Error("Expected check not to modify the file")
+	}
+
+	result, err = ops.Apply(tempDir, patch, ApplyOptions{Cached: true})
+	if err != nil {
+		t.Fatalf("Apply to the index failed: %v", err)
+	}
+	if result != "Applied patch to the index:\n  file.txt: applied" {
+		t.Errorf("Unexpected result: %s", result)
+	}
+	entries, _ := indexEntries(repo)
+	if blob, _ := repo.BlobObject(entries["file.txt"].Hash); blob == nil || blob.Size != 20 {
+		t.Error("Expected the patched file to be staged")
+	}
+	if content, _ := os.ReadFile(filepath.Join(tempDir, "file.txt")); strings.Contains(string(content), "B") {
+		t.Error("Expected cached not to modify the working tree")
+	}
+
+	if _, err := ops.Apply(tempDir, patch, ApplyOptions{}); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(tempDir, "file.txt")); !strings.HasPrefix(string(content), "a\nB\nc\n") {
+		t.Errorf("Expected the patch in the working tree, got %q", content)
+	}
+
+	// A hunk that does not match fails the whole patch, unless rejected
+	patch = "--- a/file.txt\n+++ b/file.txt\n@@ -3,3 +3,3 @@\n c\n-d\n+D\n e\n@@ -8,3 +8,3 @@\n h\n-X\n+I\n j\n"
+	if _, err := ops.Apply(tempDir, patch, ApplyOptions{}); err == nil || !strings.Contains(err.Error(), "patch failed: file.txt:8") {
+		t.Errorf("Expected the failing hunk to be reported, got %v", err)
+	}
+	if result, _ := ops.Apply(tempDir, patch, ApplyOptions{Check: true}); !strings.HasPrefix(result, "Patch does not apply:") {
+		t.Errorf("Unexpected check result: %s", result)
+	}
+	result, err = ops.Apply(tempDir, patch, ApplyOptions{Reject: true})
+	if err != nil {
+		t.Fatalf("Apply with rejects failed: %v", err)
+	}
+	if !strings.Contains(result, "file.txt: 1 hunk(s) rejected, see file.txt.rej\n    Hunk #1 applied cleanly\n    Rejected hunk #2") ||
+		!strings.Contains(result, "Rejected hunks of file.txt:\n") || !strings.Contains(result, "+I\n j") {
+		t.Errorf("Unexpected result: %s", result)
+	}
+	if content, _ := os.ReadFile(filepath.Join(tempDir, "file.txt")); !strings.Contains(string(content), "\nD\n") {
+		t.Errorf("Expected the first hunk to be applied, got %q", content)
+	}
+
+	if _, err := ops.Apply(tempDir, " \n", ApplyOptions{}); err == nil {
+		t.Error("Expected error for an empty patch")
+	}
+	if _, err := ops.Apply(tempDir, patch, ApplyOptions{Reject: true, ThreeWay: true}); err == nil {
+		t.Error("Expected error for reject combined with three_way")
+	}
+}
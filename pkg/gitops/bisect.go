@@ -0,0 +1,186 @@
+package gitops
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// BisectOptions holds the parameters of a bisect action
+type BisectOptions struct {
+	// Action is start, good, bad, skip, reset, log or run
+	Action string
+	// Bad is the revision known to be bad when starting, HEAD by default
+	Bad string
+	// Good are the revisions known to be good when starting. run needs
+	// them unless a bisect is in progress.
+	Good []string
+	// Revisions are the commits marked by good, bad and skip, the commit
+	// being tested by default
+	Revisions []string
+	// Paths limits the bisect to commits touching these paths when starting
+	Paths []string
+	// Command is the shell command run tests each commit with: exit code 0
+	// marks it good, 125 skips it and any other code up to 127 marks it bad
+	Command string
+}
+
+var (
+	bisectFirstBad = regexp.MustCompile(`(?m)^([0-9a-f]{40}) is the first bad commit$`)
+	bisectStep     = regexp.MustCompile(`(?m)^(Bisecting: .*)\n\[([0-9a-f]{40})\] (.*)$`)
+	bisectLogMark  = regexp.MustCompile(`(?m)^# (good|bad|skip): \[([0-9a-f]{40})\] (.*)$`)
+)
+
+// Bisect runs a git bisect action with the git executable to find the
+// commit that introduced a bug. The run action bisects automatically with
+// a test command, then resets the repository to where it was and returns
+// the first bad commit.
+func (g *Operations) Bisect(repoPath string, opts BisectOptions) (string, error) {
+	revisions := append(append(append([]string{opts.Bad}, opts.Good...), opts.Revisions...), opts.Paths...)
+	for _, revision := range revisions {
+		if strings.HasPrefix(revision, "-") {
+			return "", fmt.Errorf("invalid revision or path %s", revision)
+		}
+	}
+
+	bisecting, err := isBisecting(repoPath)
+	if err != nil {
+		return "", err
+	}
+
+	switch opts.Action {
+	case "start":
+		if bisecting {
+			return "", fmt.Errorf("a bisect is already in progress, reset it first")
+		}
+		output, err := g.runBisect(repoPath, bisectStartArgs(opts)...)
+		if err != nil {
+			return "", err
+		}
+		if len(opts.Good) == 0 {
+			return "Bisect started, mark a bad and a good commit to begin testing", nil
+		}
+		return formatBisectOutput(output), nil
+	case "good", "bad", "skip":
+		if !bisecting {
+			return "", fmt.Errorf("no bisect in progress, start one first")
+		}
+		output, err := g.runBisect(repoPath, append([]string{opts.Action}, opts.Revisions...)...)
+		if err != nil {
+			return "", err
+		}
+		return formatBisectOutput(output), nil
+	case "reset":
+		if !bisecting {
+			return "No bisect in progress", nil
+		}
+		output, err := g.runBisect(repoPath, "reset")
+		if err != nil {
+			return "", err
+		}
+		return "Bisect reset\n" + strings.TrimSpace(output), nil
+	case "log":
+		if !bisecting {
+			return "No bisect in progress", nil
+		}
+		return g.runBisect(repoPath, "log")
+	case "run":
+		return g.bisectRun(repoPath, bisecting, opts)
+	default:
+		return "", fmt.Errorf("invalid action %s, expected start, good, bad, skip, reset, log or run", opts.Action)
+	}
+}
+
+// bisectRun bisects automatically with a test command
+func (g *Operations) bisectRun(repoPath string, bisecting bool, opts BisectOptions) (string, error) {
+	if strings.TrimSpace(opts.Command) == "" {
+		return "", fmt.Errorf("command is required to run a bisect")
+	}
+	if !bisecting {
+		if len(opts.Good) == 0 {
+			return "", fmt.Errorf("good revisions are required to start a bisect")
+		}
+		if _, err := g.runBisect(repoPath, bisectStartArgs(opts)...); err != nil {
+			return "", err
+		}
+	}
+
+	output, runErr := g.runBisect(repoPath, "run", "sh", "-c", opts.Command)
+	log, logErr := g.runBisect(repoPath, "log")
+	if _, err := g.runBisect(repoPath, "reset"); err != nil {
+		return "", err
+	}
+	if runErr != nil {
+		return "", runErr
+	}
+	if logErr != nil {
+		return "", logErr
+	}
+
+	var result strings.Builder
+	result.WriteString(formatBisectOutput(output))
+	result.WriteString("\n\nCommits marked:")
+	for _, m := range bisectLogMark.FindAllStringSubmatch(log, -1) {
+		fmt.Fprintf(&result, "\n  %s %s %s", m[1], m[2][:7], m[3])
+	}
+	result.WriteString("\n\nBisect reset, the repository is back where it was")
+	return result.String(), nil
+}
+
+// bisectStartArgs returns the arguments of git bisect start
+func bisectStartArgs(opts BisectOptions) []string {
+	args := []string{"start"}
+	if opts.Bad != "" || len(opts.Good) > 0 {
+		bad := opts.Bad
+		if bad == "" {
+			bad = "HEAD"
+		}
+		args = append(append(args, bad), opts.Good...)
+	}
+	if len(opts.Paths) > 0 {
+		args = append(append(args, "--"), opts.Paths...)
+	}
+	return args
+}
+
+// runBisect runs git bisect
+func (g *Operations) runBisect(repoPath string, args ...string) (string, error) {
+	output, err := g.runCommand(g.gitCommand(repoPath, append([]string{"bisect"}, args...)...))
+	if err != nil {
+		if _, ok := err.(*DubiousOwnershipError); ok {
+			return "", err
+		}
+		return "", fmt.Errorf("git bisect %s failed: %w\n%s", args[0], err, strings.TrimSpace(string(output)))
+	}
+	return string(output), nil
+}
+
+// isBisecting reports whether a bisect is in progress
+func isBisecting(repoPath string) (bool, error) {
+	gitDir, _, err := findGitDir(repoPath)
+	if err != nil {
+		return false, err
+	}
+	_, err = os.Stat(filepath.Join(gitDir, "BISECT_START"))
+	return err == nil, nil
+}
+
+// formatBisectOutput summarizes the output of a bisect step: the first bad
+// commit once found, otherwise the commit to test next
+func formatBisectOutput(output string) string {
+	if loc := bisectFirstBad.FindStringSubmatchIndex(output); loc != nil {
+		// The commit is described like git show --stat, until the messages
+		// of bisect run
+		block := output[loc[1]:]
+		if end := strings.Index(block, "\nbisect "); end >= 0 {
+			block = block[:end]
+		}
+		return fmt.Sprintf("First bad commit: %s\n%s", output[loc[2]:loc[3]], strings.Trim(block, "\n"))
+	}
+	if m := bisectStep.FindStringSubmatch(output); m != nil {
+		return fmt.Sprintf("%s\nNow at %s %s, test it and mark it good, bad or skip", m[1], m[2][:7], m[3])
+	}
+	return strings.TrimSpace(output)
+}
@@ -0,0 +1,78 @@
+package gitops
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestOperations_Bisect(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not available")
+	}
+	tempDir, repo := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+	for _, version := range []string{"1", "2", "3", "4", "5", "6"} {
+		commitFile(t, ops, tempDir, "version.txt", version+"\n", "Version "+version)
+	}
+	head, _ := repo.Head()
+
+	// Versions from 4 on are bad
+	result, err := ops.Bisect(tempDir, BisectOptions{Action: "start", Good: []string{"HEAD~5"}})
+	if err != nil {
+		t.Fatalf("Bisect start failed: %v", err)
+	}
+	if !strings.HasPrefix(result, "Bisecting: 2 revisions left to test after this") || !strings.HasSuffix(result, " Version 3, test it and mark it good, bad or skip") {
+		t.Errorf("Unexpected start result: %s", result)
+	}
+	if _, err := ops.Bisect(tempDir, BisectOptions{Action: "start"}); err == nil {
+		t.Error("Expected error for a bisect in progress")
+	}
+	result, _ = ops.Bisect(tempDir, BisectOptions{Action: "good"})
+	if !strings.HasSuffix(result, " Version 5, test it and mark it good, bad or skip") {
+		t.Errorf("Unexpected good result: %s", result)
+	}
+	result, _ = ops.Bisect(tempDir, BisectOptions{Action: "bad"})
+	if !strings.HasSuffix(result, " Version 4, test it and mark it good, bad or skip") {
+		t.Errorf("Unexpected bad result: %s", result)
+	}
+	result, _ = ops.Bisect(tempDir, BisectOptions{Action: "bad"})
+	if !strings.HasPrefix(result, "First bad commit: ") || !strings.Contains(result, "    Version 4") {
+		t.Errorf("Unexpected result: %s", result)
+	}
+	if log, _ := ops.Bisect(tempDir, BisectOptions{Action: "log"}); !strings.Contains(log, "# first bad commit: ") {
+		t.Errorf("Unexpected log: %s", log)
+	}
+	if result, err := ops.Bisect(tempDir, BisectOptions{Action: "reset"}); err != nil || !strings.HasPrefix(result, "Bisect reset\n") {
+		t.Errorf("Unexpected reset result: %s (%v)", result, err)
+	}
+	if result, _ := ops.Bisect(tempDir, BisectOptions{Action: "reset"}); result != "No bisect in progress" {
+		t.Errorf("Unexpected second reset: %s", result)
+	}
+
+	// The automated run finds the same commit and leaves HEAD where it was
+	result, err = ops.Bisect(tempDir, BisectOptions{Action: "run", Good: []string{"HEAD~5"}, Command: "test $(cat version.txt) -lt 4"})
+	if err != nil {
+		t.Fatalf("Bisect run failed: %v", err)
+	}
+	if !strings.HasPrefix(result, "First bad commit: ") || !strings.Contains(result, "    Version 4") ||
+		!strings.Contains(result, "\n\nCommits marked:\n  bad ") || !strings.HasSuffix(result, "Bisect reset, the repository is back where it was") {
+		t.Errorf("Unexpected run result:\n%s", result)
+	}
+	if current, _ := repo.Head(); current.Name() != head.Name() || current.Hash() != head.Hash() {
+		t.Errorf("Expected HEAD back at %s, got %s", head.Name(), current.Name())
+	}
+
+	if _, err := ops.Bisect(tempDir, BisectOptions{Action: "good"}); err == nil {
+		t.Error("Expected error without a bisect in progress")
+	}
+	if _, err := ops.Bisect(tempDir, BisectOptions{Action: "run", Good: []string{"HEAD~5"}}); err == nil {
+		t.Error("Expected error for run without a command")
+	}
+	if _, err := ops.Bisect(tempDir, BisectOptions{Action: "start", Good: []string{"--no-checkout"}}); err == nil {
+		t.Error("Expected error for an option as revision")
+	}
+}
@@ -0,0 +1,117 @@
+package gitops
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// Blame heat bucket sizes
+const (
+	BlameBucketMonth   = "month"
+	BlameBucketQuarter = "quarter"
+	BlameBucketYear    = "year"
+)
+
+// BlameBuckets lists the accepted bucket sizes of BlameHeat
+var BlameBuckets = []string{BlameBucketMonth, BlameBucketQuarter, BlameBucketYear}
+
+// blameHeatBarWidth is the width of the bar of a bucket holding every line
+const blameHeatBarWidth = 40
+
+// BlameHeat blames a file at a revision (HEAD by default) and reports the
+// share of lines last modified in each time bucket, from oldest to newest,
+// with the age of the lines relative to the revision
+func (g *Operations) BlameHeat(repoPath, path, revision, bucket string) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	if revision == "" {
+		revision = "HEAD"
+	}
+	if bucket == "" {
+		bucket = BlameBucketQuarter
+	}
+	label, ok := blameBucketLabel(bucket)
+	if !ok {
+		return "", fmt.Errorf("unknown bucket '%s' (expected one of %s)", bucket, strings.Join(BlameBuckets, ", "))
+	}
+
+	commit, err := resolveCommit(repo, revision)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve revision '%s': %w", revision, err)
+	}
+
+	blame, err := git.Blame(commit, path)
+	if err != nil {
+		return "", fmt.Errorf("failed to blame %s: %w", path, err)
+	}
+	if len(blame.Lines) == 0 {
+		return fmt.Sprintf("%s is empty at %s", path, commit.Hash.String()[:7]), nil
+	}
+
+	counts := make(map[string]int)
+	ages := make([]time.Duration, 0, len(blame.Lines))
+	for _, line := range blame.Lines {
+		counts[label(line.Date)]++
+		ages = append(ages, commit.Committer.When.Sub(line.Date))
+	}
+
+	buckets := make([]string, 0, len(counts))
+	for name := range counts {
+		buckets = append(buckets, name)
+	}
+	sort.Strings(buckets)
+
+	sort.Slice(ages, func(i, j int) bool { return ages[i] < ages[j] })
+	total := len(blame.Lines)
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Blame heat for %s at %s (%d lines, per %s)\n", path, commit.Hash.String()[:7], total, bucket))
+	for _, name := range buckets {
+		share := float64(counts[name]) / float64(total)
+		bar := strings.Repeat("#", int(share*blameHeatBarWidth+0.5))
+		result.WriteString(fmt.Sprintf("%-8s %6d lines %6.1f%% %s\n", name, counts[name], share*100, bar))
+	}
+	result.WriteString(fmt.Sprintf("Line age: newest %s, median %s, oldest %s",
+		formatAge(ages[0]), formatAge(ages[len(ages)/2]), formatAge(ages[len(ages)-1])))
+
+	return result.String(), nil
+}
+
+// blameBucketLabel returns the function naming the bucket of a time
+func blameBucketLabel(bucket string) (func(time.Time) string, bool) {
+	switch bucket {
+	case BlameBucketMonth:
+		return func(t time.Time) string { return t.UTC().Format("2006-01") }, true
+	case BlameBucketQuarter:
+		return func(t time.Time) string {
+			t = t.UTC()
+			return fmt.Sprintf("%d-Q%d", t.Year(), (int(t.Month())-1)/3+1)
+		}, true
+	case BlameBucketYear:
+		return func(t time.Time) string { return t.UTC().Format("2006") }, true
+	}
+	return nil, false
+}
+
+// formatAge renders a duration in days, or in years past two years
+func formatAge(age time.Duration) string {
+	days := int(age.Hours() / 24)
+	if days < 0 {
+		days = 0
+	}
+	if days >= 730 {
+		return fmt.Sprintf("%.1f years", float64(days)/365)
+	}
+	if days == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", days)
+}
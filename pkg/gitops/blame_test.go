@@ -0,0 +1,48 @@
+package gitops
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOperations_BlameHeat(t *testing.T) {
+	tempDir, repo := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	commitAt(t, repo, "notes.txt", "one\ntwo\nthree\n", time.Date(2029, 2, 1, 12, 0, 0, 0, time.UTC))
+	commitAt(t, repo, "notes.txt", "one\ntwo\nthree\nfour\n", time.Date(2030, 5, 1, 12, 0, 0, 0, time.UTC))
+
+	result, err := ops.BlameHeat(tempDir, "notes.txt", "", "")
+	if err != nil {
+		t.Fatalf("BlameHeat failed: %v", err)
+	}
+	for _, expected := range []string{
+		"(4 lines, per quarter)",
+		"2029-Q1       3 lines   75.0%",
+		"2030-Q2       1 lines   25.0%",
+		"newest 0 days, median 454 days, oldest 454 days",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected %q in result, got: %s", expected, result)
+		}
+	}
+	if strings.Index(result, "2029-Q1") > strings.Index(result, "2030-Q2") {
+		t.Errorf("Expected buckets from oldest to newest, got: %s", result)
+	}
+
+	result, err = ops.BlameHeat(tempDir, "notes.txt", "HEAD~1", BlameBucketYear)
+	if err != nil {
+		t.Fatalf("BlameHeat failed: %v", err)
+	}
+	if !strings.Contains(result, "2029          3 lines  100.0%") {
+		t.Errorf("Expected all lines in 2029 at HEAD~1, got: %s", result)
+	}
+
+	if _, err := ops.BlameHeat(tempDir, "notes.txt", "", "week"); err == nil {
+		t.Error("Expected error for unknown bucket")
+	}
+}
@@ -0,0 +1,189 @@
+package gitops
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// DeleteBranch deletes a local branch. The currently checked out branch can
+// never be deleted; a branch that is not fully merged into HEAD is only
+// deleted when force is true.
+func (g *Operations) DeleteBranch(repoPath, branchName string, force bool) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	branchRef := plumbing.NewBranchReferenceName(branchName)
+	ref, err := repo.Reference(branchRef, false)
+	if err != nil {
+		return "", fmt.Errorf("branch '%s' not found: %w", branchName, err)
+	}
+
+	head, err := repo.Head()
+	if err == nil && head.Name() == branchRef {
+		return "", fmt.Errorf("cannot delete branch '%s': it is the current branch", branchName)
+	}
+
+	if !force && head != nil {
+		merged, err := isAncestor(repo, ref.Hash(), head.Hash())
+		if err != nil {
+			return "", err
+		}
+		if !merged {
+			return "", fmt.Errorf("branch '%s' is not fully merged; set force to true to delete it anyway", branchName)
+		}
+	}
+
+	if err := repo.Storer.RemoveReference(branchRef); err != nil {
+		return "", fmt.Errorf("failed to delete branch: %w", err)
+	}
+
+	// Drop the branch tracking configuration, if any
+	cfg, err := repo.Config()
+	if err == nil {
+		if _, ok := cfg.Branches[branchName]; ok {
+			delete(cfg.Branches, branchName)
+			if err := repo.SetConfig(cfg); err != nil {
+				return "", fmt.Errorf("failed to update config: %w", err)
+			}
+		}
+	}
+
+	return fmt.Sprintf("Deleted branch '%s' (was %s)", branchName, ref.Hash().String()[:7]), nil
+}
+
+// RenameBranch renames a local branch, moving its tracking configuration and
+// updating HEAD when it is the current branch. An existing branch with the
+// new name is only overwritten when force is true.
+func (g *Operations) RenameBranch(repoPath, oldName, newName string, force bool) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	if newName == "" {
+		return "", fmt.Errorf("new branch name cannot be empty")
+	}
+
+	oldRef := plumbing.NewBranchReferenceName(oldName)
+	newRef := plumbing.NewBranchReferenceName(newName)
+	if oldRef == newRef {
+		return "", fmt.Errorf("branch is already named '%s'", newName)
+	}
+
+	ref, err := repo.Reference(oldRef, false)
+	if err != nil {
+		return "", fmt.Errorf("branch '%s' not found: %w", oldName, err)
+	}
+
+	if _, err := repo.Reference(newRef, false); err == nil && !force {
+		return "", fmt.Errorf("a branch named '%s' already exists; set force to true to overwrite it", newName)
+	}
+
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(newRef, ref.Hash())); err != nil {
+		return "", fmt.Errorf("failed to create branch '%s': %w", newName, err)
+	}
+
+	// Point HEAD at the new name if the renamed branch is checked out
+	head, err := repo.Storer.Reference(plumbing.HEAD)
+	if err == nil && head.Type() == plumbing.SymbolicReference && head.Target() == oldRef {
+		if err := repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, newRef)); err != nil {
+			return "", fmt.Errorf("failed to update HEAD: %w", err)
+		}
+	}
+
+	if err := repo.Storer.RemoveReference(oldRef); err != nil {
+		return "", fmt.Errorf("failed to remove branch '%s': %w", oldName, err)
+	}
+
+	cfg, err := repo.Config()
+	if err == nil {
+		if branch, ok := cfg.Branches[oldName]; ok {
+			delete(cfg.Branches, oldName)
+			branch.Name = newName
+			cfg.Branches[newName] = branch
+			if err := repo.SetConfig(cfg); err != nil {
+				return "", fmt.Errorf("failed to update config: %w", err)
+			}
+		}
+	}
+
+	return fmt.Sprintf("Renamed branch '%s' to '%s'", oldName, newName), nil
+}
+
+// currentBranch returns the short name of the checked out branch, which may
+// not have any commits yet. It reports false when HEAD is detached.
+func currentBranch(repo *git.Repository) (string, bool) {
+	head, err := repo.Storer.Reference(plumbing.HEAD)
+	if err != nil || head.Type() != plumbing.SymbolicReference {
+		return "", false
+	}
+	return head.Target().Short(), true
+}
+
+// CurrentBranch describes HEAD: the checked out branch (or the detached
+// commit), the commit HEAD points at, and how far the branch is ahead of
+// and behind its upstream
+func (g *Operations) CurrentBranch(repoPath string) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	var result strings.Builder
+	branch, onBranch := currentBranch(repo)
+	if onBranch {
+		result.WriteString(fmt.Sprintf("Branch: %s\n", branch))
+	} else {
+		result.WriteString("Branch: none (detached HEAD)\n")
+	}
+
+	head, err := repo.Head()
+	if err == plumbing.ErrReferenceNotFound && onBranch {
+		result.WriteString("HEAD: none (no commits yet)\n")
+	} else if err != nil {
+		return "", fmt.Errorf("failed to get HEAD: %w", err)
+	} else {
+		commit, err := repo.CommitObject(head.Hash())
+		if err != nil {
+			return "", fmt.Errorf("failed to get commit: %w", err)
+		}
+		result.WriteString(fmt.Sprintf("HEAD: %s %s\n", head.Hash(), commitSubject(commit)))
+	}
+	result.WriteString(fmt.Sprintf("Detached: %t\n", !onBranch))
+
+	if !onBranch {
+		return strings.TrimSpace(result.String()), nil
+	}
+
+	upstream, upstreamName, ok := upstreamOf(repo, branch)
+	if !ok {
+		result.WriteString("Upstream: none")
+		return result.String(), nil
+	}
+	ref, err := repo.Reference(upstream, true)
+	if err != nil {
+		result.WriteString(fmt.Sprintf("Upstream: %s (gone)", upstreamName))
+		return result.String(), nil
+	}
+	result.WriteString(fmt.Sprintf("Upstream: %s (%s)\n", upstreamName, ref.Hash().String()[:7]))
+	if head == nil {
+		return strings.TrimSpace(result.String()), nil
+	}
+
+	ahead, behind, err := aheadBehind(repo, head.Hash(), ref.Hash())
+	if err != nil {
+		return "", err
+	}
+	result.WriteString(fmt.Sprintf("Ahead: %d\n", ahead))
+	result.WriteString(fmt.Sprintf("Behind: %d", behind))
+
+	return result.String(), nil
+}
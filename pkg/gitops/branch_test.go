@@ -0,0 +1,199 @@
+package gitops
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func TestOperations_DeleteBranch(t *testing.T) {
+	tempDir, _ := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	if _, err := ops.DeleteBranch(tempDir, "master", false); err == nil {
+		t.Error("Expected error when deleting the current branch")
+	}
+
+	// A branch with an unmerged commit needs force
+	if _, err := ops.Checkout(tempDir, "unmerged", true, ""); err != nil {
+		t.Fatalf("Checkout failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "new.txt"), []byte("new content"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if _, err := ops.Add(tempDir, []string{"new.txt"}, AddOptions{}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if _, err := ops.Commit(tempDir, "Unmerged commit", CommitOptions{}); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if _, err := ops.Checkout(tempDir, "master", false, ""); err != nil {
+		t.Fatalf("Checkout failed: %v", err)
+	}
+
+	if _, err := ops.DeleteBranch(tempDir, "unmerged", false); err == nil {
+		t.Error("Expected error when deleting an unmerged branch without force")
+	}
+	if _, err := ops.DeleteBranch(tempDir, "unmerged", true); err != nil {
+		t.Fatalf("Forced DeleteBranch failed: %v", err)
+	}
+
+	// A merged branch can be deleted directly
+	if _, err := ops.CreateBranch(tempDir, "merged", ""); err != nil {
+		t.Fatalf("CreateBranch failed: %v", err)
+	}
+	result, err := ops.DeleteBranch(tempDir, "merged", false)
+	if err != nil {
+		t.Fatalf("DeleteBranch failed: %v", err)
+	}
+	if !contains(result, "Deleted branch 'merged'") {
+		t.Errorf("Unexpected result: %s", result)
+	}
+}
+
+func TestOperations_RenameBranch(t *testing.T) {
+	tempDir, repo := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	if _, err := ops.CreateBranch(tempDir, "other", ""); err != nil {
+		t.Fatalf("CreateBranch failed: %v", err)
+	}
+	if _, err := ops.RenameBranch(tempDir, "master", "other", false); err == nil {
+		t.Error("Expected error when renaming onto an existing branch")
+	}
+
+	// Renaming the current branch moves HEAD along
+	if _, err := ops.RenameBranch(tempDir, "master", "main", false); err != nil {
+		t.Fatalf("RenameBranch failed: %v", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("Failed to get HEAD: %v", err)
+	}
+	if head.Name() != plumbing.NewBranchReferenceName("main") {
+		t.Errorf("Expected HEAD to follow the rename, got %s", head.Name())
+	}
+	if _, err := repo.Reference(plumbing.NewBranchReferenceName("master"), false); err == nil {
+		t.Error("Expected old branch to be removed")
+	}
+}
+
+func TestOperations_BranchContains(t *testing.T) {
+	tempDir, repo := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	initial, err := repo.Head()
+	if err != nil {
+		t.Fatalf("Failed to get HEAD: %v", err)
+	}
+
+	if _, err := ops.CreateBranch(tempDir, "old", ""); err != nil {
+		t.Fatalf("CreateBranch failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "new.txt"), []byte("new content"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if _, err := ops.Add(tempDir, []string{"new.txt"}, AddOptions{}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if _, err := ops.Commit(tempDir, "Second commit", CommitOptions{}); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	head, _ := repo.Head()
+
+	// Both branches contain the initial commit
+	result, err := ops.Branch(tempDir, "local", initial.Hash().String(), "", "")
+	if err != nil {
+		t.Fatalf("Branch failed: %v", err)
+	}
+	if !contains(result, "old") || !contains(result, "master") {
+		t.Errorf("Expected both branches, got: %s", result)
+	}
+
+	// Only master contains the second commit
+	result, err = ops.Branch(tempDir, "local", head.Hash().String(), "", "")
+	if err != nil {
+		t.Fatalf("Branch failed: %v", err)
+	}
+	if result != "* master" {
+		t.Errorf("Expected only master, got: %s", result)
+	}
+
+	// Only old does not contain it
+	result, err = ops.Branch(tempDir, "local", "", head.Hash().String(), "")
+	if err != nil {
+		t.Fatalf("Branch failed: %v", err)
+	}
+	if result != "old" {
+		t.Errorf("Expected only old, got: %s", result)
+	}
+
+	if _, err := ops.Branch(tempDir, "local", "nonexistent", "", ""); err == nil {
+		t.Error("Expected error for unknown commit")
+	}
+}
+
+func TestOperations_CurrentBranch(t *testing.T) {
+	origin, _ := createTestRepo(t)
+	defer os.RemoveAll(origin)
+
+	clone, err := os.MkdirTemp("", "git-clone-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(clone)
+
+	repo, err := git.PlainClone(clone, false, &git.CloneOptions{URL: origin})
+	if err != nil {
+		t.Fatalf("Clone failed: %v", err)
+	}
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	// The origin has no upstream
+	result, err := ops.CurrentBranch(origin)
+	if err != nil {
+		t.Fatalf("CurrentBranch failed: %v", err)
+	}
+	if !strings.HasPrefix(result, "Branch: master\nHEAD: ") || !strings.HasSuffix(result, "Initial commit\nDetached: false\nUpstream: none") {
+		t.Errorf("Unexpected result: %s", result)
+	}
+
+	// Diverge the clone from the origin
+	commitFile(t, ops, origin, "upstream.txt", "upstream\n", "Upstream change")
+	commitFile(t, ops, clone, "local.txt", "local\n", "Local change")
+	if err := repo.Fetch(&git.FetchOptions{}); err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	result, err = ops.CurrentBranch(clone)
+	if err != nil {
+		t.Fatalf("CurrentBranch failed: %v", err)
+	}
+	if !strings.Contains(result, "Local change\nDetached: false\nUpstream: origin/master (") || !strings.HasSuffix(result, "Ahead: 1\nBehind: 1") {
+		t.Errorf("Unexpected result: %s", result)
+	}
+
+	// A detached HEAD has no branch and no upstream
+	head, _ := repo.Head()
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(plumbing.HEAD, head.Hash())); err != nil {
+		t.Fatalf("Failed to detach HEAD: %v", err)
+	}
+	result, err = ops.CurrentBranch(clone)
+	if err != nil {
+		t.Fatalf("CurrentBranch failed: %v", err)
+	}
+	expected := "Branch: none (detached HEAD)\nHEAD: " + head.Hash().String() + " Local change\nDetached: true"
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
@@ -0,0 +1,200 @@
+package gitops
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// bundleSignatures start the bundle file formats. go-git cannot read
+// bundles, so they are handled by the git executable.
+var bundleSignatures = []string{"# v2 git bundle", "# v3 git bundle"}
+
+// bundleHeader is the header of a bundle file: the references it contains
+// and the prerequisite commits it needs, as "hash name" lines
+type bundleHeader struct {
+	refs          []string
+	prerequisites []string
+}
+
+// readBundleHeader reads the header of a bundle file
+func readBundleHeader(path string) (*bundleHeader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle: %w", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	signature, _ := reader.ReadString('\n')
+	known := false
+	for _, s := range bundleSignatures {
+		known = known || strings.TrimSuffix(signature, "\n") == s
+	}
+	if !known {
+		return nil, fmt.Errorf("%s is not a bundle file", path)
+	}
+
+	header := &bundleHeader{}
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("%s is not a bundle file: truncated header", path)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			return header, nil
+		case strings.HasPrefix(line, "@"):
+			// Capabilities of v3 bundles, like the object format
+		case strings.HasPrefix(line, "-"):
+			header.prerequisites = append(header.prerequisites, shortHeaderLine(line[1:]))
+		default:
+			header.refs = append(header.refs, shortHeaderLine(line))
+		}
+	}
+}
+
+// shortHeaderLine abbreviates the hash of a bundle header line
+func shortHeaderLine(line string) string {
+	hash, name, _ := strings.Cut(line, " ")
+	if len(hash) > 7 {
+		hash = hash[:7]
+	}
+	return strings.TrimSpace(hash + " " + name)
+}
+
+// describe lists the references and prerequisites of a bundle
+func (h *bundleHeader) describe() string {
+	var result strings.Builder
+	fmt.Fprintf(&result, "References (%d):", len(h.refs))
+	for _, ref := range h.refs {
+		result.WriteString("\n  " + ref)
+	}
+	if len(h.prerequisites) == 0 {
+		result.WriteString("\nRequires nothing, the bundle has the complete history")
+		return result.String()
+	}
+	fmt.Fprintf(&result, "\nRequires (%d):", len(h.prerequisites))
+	for _, prerequisite := range h.prerequisites {
+		result.WriteString("\n  " + prerequisite)
+	}
+	return result.String()
+}
+
+// isBundle reports whether path is a bundle file
+func isBundle(path string) bool {
+	_, err := readBundleHeader(path)
+	return err == nil
+}
+
+// bundleRevisionOptions are the options accepted among the revisions of
+// BundleCreate, next to references and ranges like main or v1.0..main
+var bundleRevisionOptions = map[string]bool{"--all": true, "--branches": true, "--tags": true, "--remotes": true}
+
+// BundleCreate writes the history of revisions to a bundle file, all the
+// references by default. A revision range like v1.0..main makes an
+// incremental bundle that needs v1.0 to be unbundled.
+func (g *Operations) BundleCreate(repoPath, file string, revisions []string) (string, error) {
+	if file == "" {
+		return "", fmt.Errorf("bundle file is required")
+	}
+	if len(revisions) == 0 {
+		revisions = []string{"--all"}
+	}
+	for _, revision := range revisions {
+		if strings.HasPrefix(revision, "-") && !bundleRevisionOptions[revision] {
+			return "", fmt.Errorf("invalid revision %s", revision)
+		}
+	}
+	file, err := filepath.Abs(file)
+	if err != nil {
+		return "", fmt.Errorf("invalid bundle file: %w", err)
+	}
+
+	args := append([]string{"bundle", "create", "--quiet", file}, revisions...)
+	if output, err := g.runCommand(g.gitCommand(repoPath, args...)); err != nil {
+		if _, ok := err.(*DubiousOwnershipError); ok {
+			return "", err
+		}
+		return "", fmt.Errorf("failed to create bundle: %w\n%s", err, strings.TrimSpace(string(output)))
+	}
+
+	header, err := readBundleHeader(file)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read bundle: %w", err)
+	}
+	return fmt.Sprintf("Created bundle %s (%s)\n%s", file, formatBytes(info.Size()), header.describe()), nil
+}
+
+// BundleVerify checks that a bundle file is valid and that repoPath has
+// the commits it requires, listing its references
+func (g *Operations) BundleVerify(repoPath, file string) (string, error) {
+	file, err := filepath.Abs(file)
+	if err != nil {
+		return "", fmt.Errorf("invalid bundle file: %w", err)
+	}
+	header, err := readBundleHeader(file)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := g.runCommand(g.gitCommand(repoPath, "bundle", "verify", "--", file))
+	if err != nil {
+		if _, ok := err.(*DubiousOwnershipError); ok {
+			return "", err
+		}
+		// The missing prerequisites are listed as errors, one per line
+		var missing []string
+		for _, line := range strings.Split(string(output), "\n") {
+			if !strings.HasPrefix(line, "error: ") {
+				continue
+			}
+			line = strings.TrimSpace(strings.TrimPrefix(line, "error: "))
+			if hash, _, _ := strings.Cut(line, " "); plumbing.IsHash(hash) {
+				missing = append(missing, "  "+shortHeaderLine(line))
+			}
+		}
+		if len(missing) == 0 {
+			return "", fmt.Errorf("invalid bundle %s: %w\n%s", file, err, strings.TrimSpace(string(output)))
+		}
+		return fmt.Sprintf("Bundle %s cannot be unbundled here, the repository lacks %d prerequisite commit(s):\n%s\n%s",
+			file, len(missing), strings.Join(missing, "\n"), header.describe()), nil
+	}
+	return fmt.Sprintf("Bundle %s is valid\n%s", file, header.describe()), nil
+}
+
+// fetchBundle fetches the branches of a bundle file into the
+// remote-tracking branches of remote, and its tags with opts.Tags
+func (g *Operations) fetchBundle(repoPath, remote string, opts FetchOptions) error {
+	bundle, err := filepath.Abs(opts.Bundle)
+	if err != nil {
+		return fmt.Errorf("invalid bundle file: %w", err)
+	}
+	if _, err := readBundleHeader(bundle); err != nil {
+		return err
+	}
+	if strings.ContainsAny(remote, "*:") || strings.HasPrefix(remote, "-") {
+		return fmt.Errorf("invalid remote name %s", remote)
+	}
+
+	args := []string{"fetch", "--no-tags", "--", bundle, "+refs/heads/*:refs/remotes/" + remote + "/*"}
+	if opts.Tags {
+		args = append(args, "+refs/tags/*:refs/tags/*")
+	}
+	if output, err := g.runCommand(g.gitCommand(repoPath, args...)); err != nil {
+		if _, ok := err.(*DubiousOwnershipError); ok {
+			return err
+		}
+		return fmt.Errorf("failed to fetch %s: %w\n%s", opts.Bundle, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
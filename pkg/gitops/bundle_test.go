@@ -0,0 +1,90 @@
+package gitops
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOperations_Bundle(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not available")
+	}
+	tempDir, _ := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+	otherDir, err := os.MkdirTemp("", "git-bundle-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(otherDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+	full := filepath.Join(otherDir, "full.bundle")
+	result, err := ops.BundleCreate(tempDir, full, nil)
+	if err != nil {
+		t.Fatalf("BundleCreate failed: %v", err)
+	}
+	if !strings.HasPrefix(result, "Created bundle "+full) || !strings.Contains(result, "References (2):") ||
+		!strings.HasSuffix(result, "Requires nothing, the bundle has the complete history") {
+		t.Errorf("Unexpected result:\n%s", result)
+	}
+	if !isBundle(full) || isBundle(filepath.Join(tempDir, "test.txt")) {
+		t.Error("Expected only the bundle to be recognized")
+	}
+
+	// Cloning the bundle needs no network
+	cloneDir := filepath.Join(otherDir, "clone")
+	result, err = ops.Clone(full, cloneDir, CloneOptions{})
+	if err != nil {
+		t.Fatalf("Clone of the bundle failed: %v", err)
+	}
+	if !strings.Contains(result, "Cloned from a bundle file") {
+		t.Errorf("Unexpected clone result: %s", result)
+	}
+	if _, err := os.Stat(filepath.Join(cloneDir, "test.txt")); err != nil {
+		t.Errorf("Expected the files of the bundle to be checked out: %v", err)
+	}
+
+	// An incremental bundle needs the commits it is based on
+	commitFile(t, ops, tempDir, "second.txt", "second\n", "Second commit")
+	incremental := filepath.Join(otherDir, "incremental.bundle")
+	if _, err := ops.BundleCreate(tempDir, incremental, []string{"master~1..master"}); err != nil {
+		t.Fatalf("BundleCreate of a range failed: %v", err)
+	}
+	result, err = ops.BundleVerify(cloneDir, incremental)
+	if err != nil || !strings.HasPrefix(result, "Bundle "+incremental+" is valid") || !strings.Contains(result, "Requires (1):") {
+		t.Errorf("Unexpected verify result: %s (%v)", result, err)
+	}
+	emptyDir := filepath.Join(otherDir, "empty")
+	if output, err := exec.Command("git", "init", "-q", emptyDir).CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %s", output)
+	}
+	result, err = ops.BundleVerify(emptyDir, incremental)
+	if err != nil || !strings.Contains(result, "cannot be unbundled here, the repository lacks 1 prerequisite commit(s)") {
+		t.Errorf("Expected the missing prerequisite, got: %s (%v)", result, err)
+	}
+
+	// Fetching the incremental bundle brings the clone up to date
+	result, err = ops.Fetch(cloneDir, FetchOptions{Bundle: incremental})
+	if err != nil {
+		t.Fatalf("Fetch of the bundle failed: %v", err)
+	}
+	if !strings.HasPrefix(result, "Fetched "+incremental+", 1 reference(s) updated:") || !strings.Contains(result, "  origin/master: ") {
+		t.Errorf("Unexpected fetch result:\n%s", result)
+	}
+	if result, _ := ops.Fetch(cloneDir, FetchOptions{Bundle: incremental}); result != "Already up to date with "+incremental {
+		t.Errorf("Expected no changes, got: %s", result)
+	}
+
+	if _, err := ops.Fetch(cloneDir, FetchOptions{Bundle: filepath.Join(cloneDir, "test.txt")}); err == nil {
+		t.Error("Expected error for a file that is not a bundle")
+	}
+	if _, err := ops.Fetch(cloneDir, FetchOptions{Bundle: incremental, Prune: true}); err == nil {
+		t.Error("Expected error for bundle combined with prune")
+	}
+	if _, err := ops.BundleCreate(tempDir, full, []string{"--output=x"}); err == nil {
+		t.Error("Expected error for an option among the revisions")
+	}
+}
@@ -0,0 +1,322 @@
+package gitops
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// CommitOptions selects what Commit records besides the staged changes
+type CommitOptions struct {
+	// Files commits only the working tree content of the tracked files
+	// matching these pathspecs, like git commit -- <paths>. Other staged
+	// changes stay staged.
+	Files []string
+	// All stages the modifications and deletions of tracked files first,
+	// like git commit -a
+	All bool
+	// AllowEmpty creates the commit even when it records no change
+	AllowEmpty bool
+	// Trailers are appended to the message as "Key: value" lines, keys in
+	// name order, e.g. Co-authored-by or Reviewed-by
+	Trailers map[string][]string
+	// Signoff appends a Signed-off-by trailer for the committer, like
+	// git commit -s
+	Signoff bool
+
+	// AuthorName, AuthorEmail and AuthorDate override the author of the
+	// commit, the server identity and the current time by default. Dates
+	// take the formats of parseTimestamp.
+	AuthorName  string
+	AuthorEmail string
+	AuthorDate  string
+	// CommitterName, CommitterEmail and CommitterDate override the
+	// committer the same way, independently of the author
+	CommitterName  string
+	CommitterEmail string
+	CommitterDate  string
+
+	// Sign signs the commit with the configured OpenPGP or SSH key
+	Sign bool
+}
+
+// Commit creates a new commit with the given message
+func (g *Operations) Commit(repoPath, message string, opts CommitOptions) (string, error) {
+	if opts.All && len(opts.Files) > 0 {
+		return "", fmt.Errorf("all and files are mutually exclusive")
+	}
+
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	// Run the message through the repository's commit policy, if any
+	policy, err := loadCommitPolicy(repo)
+	if err != nil {
+		return "", err
+	}
+	if policy == nil {
+		policy = g.commitPolicy
+	}
+	if policy != nil {
+		branch, _ := currentBranch(repo)
+		message, err = policy.Apply(message, branch)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	signer, err := g.signerFor(repo, opts.Sign)
+	if err != nil {
+		return "", err
+	}
+
+	author, err := g.commitSignature(opts.AuthorName, opts.AuthorEmail, opts.AuthorDate)
+	if err != nil {
+		return "", fmt.Errorf("invalid author: %w", err)
+	}
+	committer, err := g.commitSignature(opts.CommitterName, opts.CommitterEmail, opts.CommitterDate)
+	if err != nil {
+		return "", fmt.Errorf("invalid committer: %w", err)
+	}
+
+	trailers, err := commitTrailers(opts.Trailers)
+	if err != nil {
+		return "", err
+	}
+	if opts.Signoff {
+		trailers = append(trailers, [2]string{"Signed-off-by", fmt.Sprintf("%s <%s>", committer.Name, committer.Email)})
+	}
+	if len(trailers) > 0 {
+		message = addTrailers(message, trailers)
+	}
+
+	if opts.All {
+		if _, err := g.Add(repoPath, nil, AddOptions{Update: true}); err != nil {
+			return "", err
+		}
+	}
+
+	// Committing some files records them on top of HEAD in a separate
+	// index, the real index has them staged afterwards
+	var restore *index.Index
+	if len(opts.Files) > 0 {
+		restore, err = stageCommitFiles(repo, worktree, opts.Files)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	hash, err := commitIndex(worktree, message, &git.CommitOptions{
+		Author:            author,
+		Committer:         committer,
+		AllowEmptyCommits: opts.AllowEmpty,
+		SignKey:           signer.openPGPKey(),
+	})
+	// go-git only signs with OpenPGP, SSH signatures are added after
+	if err == nil && signer.sshKey() != nil {
+		hash, err = sshSignCommit(repo, hash, signer.sshKey())
+	}
+	if restore != nil {
+		if err := repo.Storer.SetIndex(restore); err != nil {
+			return "", fmt.Errorf("failed to write index: %w", err)
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	result := fmt.Sprintf("Changes committed successfully with hash %s", hash)
+	if policy != nil || len(trailers) > 0 {
+		result += fmt.Sprintf("\nMessage:\n%s", message)
+	}
+
+	return result, nil
+}
+
+// commitIndex commits the index, refusing to record no change unless
+// AllowEmptyCommits is set (go-git only refuses an empty index)
+func commitIndex(worktree *git.Worktree, message string, opts *git.CommitOptions) (string, error) {
+	if !opts.AllowEmptyCommits {
+		status, err := worktree.Status()
+		if err != nil {
+			return "", fmt.Errorf("failed to get status: %w", err)
+		}
+		staged := false
+		for _, fileStatus := range status {
+			if fileStatus.Staging != git.Unmodified && fileStatus.Staging != git.Untracked {
+				staged = true
+				break
+			}
+		}
+		if !staged {
+			return "", fmt.Errorf("nothing to commit (stage changes first or set allow_empty)")
+		}
+	}
+
+	hash, err := worktree.Commit(message, opts)
+	if err != nil {
+		return "", fmt.Errorf("failed to commit: %w", err)
+	}
+	return hash.String(), nil
+}
+
+// stageCommitFiles stages the tracked files matching pathspecs and replaces
+// the index by HEAD plus those files. It returns the index to restore after
+// the commit: the original one with the files staged.
+func stageCommitFiles(repo *git.Repository, worktree *git.Worktree, files []string) (*index.Index, error) {
+	specs, err := compilePathspecs(files)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := headTree(repo)
+	if err != nil {
+		return nil, err
+	}
+	headFiles, err := flattenTree(tree)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+
+	// Only files known to git are committed, like git commit -- <paths>
+	matched := make([]bool, len(specs))
+	paths := make(map[string]bool)
+	for name := range headFiles {
+		if matchPathspecs(specs, name, matched) {
+			paths[name] = true
+		}
+	}
+	for _, entry := range idx.Entries {
+		if matchPathspecs(specs, entry.Name, matched) {
+			paths[entry.Name] = true
+		}
+	}
+	for i, spec := range specs {
+		if !matched[i] {
+			return nil, fmt.Errorf("pathspec '%s' did not match any file known to git", spec.pattern)
+		}
+	}
+
+	conflicts, err := indexConflicts(repo)
+	if err != nil {
+		return nil, err
+	}
+	for path := range paths {
+		if conflicts[path] != nil {
+			return nil, fmt.Errorf("%s is unmerged, resolve it first (see git_conflicts)", path)
+		}
+	}
+	for path := range paths {
+		if _, err := worktree.Add(path); err != nil {
+			return nil, fmt.Errorf("failed to add file %s: %w", path, err)
+		}
+	}
+
+	staged, err := repo.Storer.Index()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+
+	commitIdx := &index.Index{Version: staged.Version}
+	for name, file := range headFiles {
+		if !paths[name] {
+			commitIdx.Entries = append(commitIdx.Entries, &index.Entry{Name: name, Hash: file.hash, Mode: file.mode})
+		}
+	}
+	for _, entry := range staged.Entries {
+		if paths[entry.Name] {
+			commitIdx.Entries = append(commitIdx.Entries, entry)
+		}
+	}
+	if err := repo.Storer.SetIndex(commitIdx); err != nil {
+		return nil, fmt.Errorf("failed to write index: %w", err)
+	}
+	return staged, nil
+}
+
+// commitSignature builds an author or committer signature, defaulting to
+// the server identity and the current time
+func (g *Operations) commitSignature(name, email, date string) (*object.Signature, error) {
+	signature := g.getUserSignature()
+	if name != "" {
+		signature.Name = strings.TrimSpace(name)
+	}
+	if email != "" {
+		signature.Email = strings.TrimSpace(email)
+	}
+	if strings.ContainsAny(signature.Name, "<>\n") || strings.ContainsAny(signature.Email, "<>\n ") {
+		return nil, fmt.Errorf("'%s <%s>' is not a valid identity", signature.Name, signature.Email)
+	}
+	if date != "" {
+		when, err := parseTimestamp(date)
+		if err != nil {
+			return nil, err
+		}
+		signature.When = when
+	}
+	return signature, nil
+}
+
+var trailerKey = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]*$`)
+
+// commitTrailers validates trailers and orders them by key
+func commitTrailers(trailers map[string][]string) ([][2]string, error) {
+	keys := make([]string, 0, len(trailers))
+	for key := range trailers {
+		if !trailerKey.MatchString(key) {
+			return nil, fmt.Errorf("invalid trailer key '%s' (expected letters, digits and dashes)", key)
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var ordered [][2]string
+	for _, key := range keys {
+		for _, value := range trailers[key] {
+			value = strings.TrimSpace(value)
+			if value == "" || strings.Contains(value, "\n") {
+				return nil, fmt.Errorf("invalid value for trailer %s (expected a single non-empty line)", key)
+			}
+			ordered = append(ordered, [2]string{key, value})
+		}
+	}
+	return ordered, nil
+}
+
+// addTrailers appends trailers to the trailer block of a commit message,
+// skipping the ones the message already has
+func addTrailers(message string, trailers [][2]string) string {
+	message = strings.TrimSpace(message)
+	subject, body, _ := strings.Cut(message, "\n")
+	body = strings.Trim(body, "\n")
+
+	for _, trailer := range trailers {
+		line := trailer[0] + ": " + trailer[1]
+		if strings.Contains("\n"+body+"\n", "\n"+line+"\n") {
+			continue
+		}
+		body = appendTrailer(body, trailer[0], trailer[1])
+	}
+
+	if body == "" {
+		return subject
+	}
+	return subject + "\n\n" + body
+}
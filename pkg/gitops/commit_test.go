@@ -0,0 +1,206 @@
+package gitops
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestOperations_CommitFiles(t *testing.T) {
+	tempDir, repo := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	commitFile(t, ops, tempDir, "a.txt", "a\n", "Add a")
+	commitFile(t, ops, tempDir, "b.txt", "b\n", "Add b")
+	for name, content := range map[string]string{"a.txt": "a changed\n", "b.txt": "b changed\n", "c.txt": "c\n"} {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	if _, err := ops.Add(tempDir, []string{"b.txt", "c.txt"}, AddOptions{}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	// Only a.txt is committed, the staged b.txt and c.txt stay staged
+	if _, err := ops.Commit(tempDir, "Change a", CommitOptions{Files: []string{"a.txt"}}); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	head, _ := repo.Head()
+	commit, _ := repo.CommitObject(head.Hash())
+	stats, _ := commit.Stats()
+	if len(stats) != 1 || stats[0].Name != "a.txt" {
+		t.Errorf("Expected only a.txt in the commit, got: %v", stats)
+	}
+	status, _ := ops.Status(tempDir, false)
+	if status != "Staged (2):\n  modified: b.txt\n  added: c.txt" {
+		t.Errorf("Unexpected status: %q", status)
+	}
+
+	if _, err := ops.Commit(tempDir, "Unknown", CommitOptions{Files: []string{"missing.txt"}}); err == nil {
+		t.Error("Expected error for a path unknown to git")
+	}
+	if _, err := ops.Commit(tempDir, "Both", CommitOptions{Files: []string{"a.txt"}, All: true}); err == nil {
+		t.Error("Expected error for files with all")
+	}
+}
+
+func TestOperations_CommitAllAndEmpty(t *testing.T) {
+	tempDir, repo := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	if _, err := ops.Commit(tempDir, "Nothing", CommitOptions{}); err == nil {
+		t.Error("Expected error when nothing is staged")
+	}
+	if _, err := ops.Commit(tempDir, "Trigger CI", CommitOptions{AllowEmpty: true}); err != nil {
+		t.Fatalf("Empty commit failed: %v", err)
+	}
+	head, _ := repo.Head()
+	commit, _ := repo.CommitObject(head.Hash())
+	if commit.Message != "Trigger CI" || commit.NumParents() != 1 {
+		t.Errorf("Unexpected empty commit: %q with %d parents", commit.Message, commit.NumParents())
+	}
+
+	// All stages tracked modifications but not untracked files
+	if err := os.WriteFile(filepath.Join(tempDir, "test.txt"), []byte("changed"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "new.txt"), []byte("new"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if _, err := ops.Commit(tempDir, "Commit all", CommitOptions{All: true}); err != nil {
+		t.Fatalf("Commit all failed: %v", err)
+	}
+	status, _ := ops.Status(tempDir, false)
+	if status != "Untracked (1):\n  new.txt" {
+		t.Errorf("Unexpected status: %q", status)
+	}
+}
+
+func TestOperations_CommitTrailers(t *testing.T) {
+	tempDir, repo := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	if err := os.WriteFile(filepath.Join(tempDir, "test.txt"), []byte("changed"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	opts := CommitOptions{
+		All: true,
+		Trailers: map[string][]string{
+			"Reviewed-by":    {"Rev Iewer <rev@example.com>"},
+			"Co-authored-by": {"Ann Other <ann@example.com>", "Third Person <third@example.com>"},
+		},
+		Signoff: true,
+	}
+	result, err := ops.Commit(tempDir, "Change test\n\nBody text.\n", opts)
+	if err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	expected := "Change test\n\nBody text.\n\n" +
+		"Co-authored-by: Ann Other <ann@example.com>\n" +
+		"Co-authored-by: Third Person <third@example.com>\n" +
+		"Reviewed-by: Rev Iewer <rev@example.com>\n" +
+		"Signed-off-by: Test User <test@example.com>"
+	head, _ := repo.Head()
+	commit, _ := repo.CommitObject(head.Hash())
+	if commit.Message != expected {
+		t.Errorf("Unexpected message: %q", commit.Message)
+	}
+	if !contains(result, "Message:\n"+expected) {
+		t.Errorf("Expected the final message in the result, got: %s", result)
+	}
+
+	// An existing sign-off is not repeated
+	message := "Empty\n\nSigned-off-by: Test User <test@example.com>"
+	if _, err := ops.Commit(tempDir, message, CommitOptions{AllowEmpty: true, Signoff: true}); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	head, _ = repo.Head()
+	commit, _ = repo.CommitObject(head.Hash())
+	if commit.Message != message {
+		t.Errorf("Unexpected message: %q", commit.Message)
+	}
+
+	if _, err := ops.Commit(tempDir, "Bad", CommitOptions{AllowEmpty: true, Trailers: map[string][]string{"Bad key": {"x"}}}); err == nil {
+		t.Error("Expected error for an invalid trailer key")
+	}
+}
+
+func TestOperations_CommitIdentity(t *testing.T) {
+	tempDir, repo := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	opts := CommitOptions{
+		AllowEmpty:  true,
+		Signoff:     true,
+		AuthorName:  "Jane Doe",
+		AuthorEmail: "jane@example.com",
+		AuthorDate:  "2024-03-01T10:00:00+02:00",
+	}
+	if _, err := ops.Commit(tempDir, "On behalf of Jane", opts); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	head, _ := repo.Head()
+	commit, _ := repo.CommitObject(head.Hash())
+	if commit.Author.Name != "Jane Doe" || commit.Author.Email != "jane@example.com" {
+		t.Errorf("Unexpected author: %s", commit.Author)
+	}
+	if !commit.Author.When.Equal(time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected author date: %s", commit.Author.When)
+	}
+	// The server stays the committer and signs off
+	if commit.Committer.Name != "Test User" || commit.Committer.Email != "test@example.com" || commit.Committer.When.Year() == 2024 {
+		t.Errorf("Unexpected committer: %s", commit.Committer)
+	}
+	if !contains(commit.Message, "Signed-off-by: Test User <test@example.com>") {
+		t.Errorf("Expected the committer sign-off, got: %q", commit.Message)
+	}
+
+	opts = CommitOptions{AllowEmpty: true, CommitterName: "CI Bot", CommitterEmail: "ci@example.com", CommitterDate: "@1700000000"}
+	if _, err := ops.Commit(tempDir, "By the bot", opts); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	head, _ = repo.Head()
+	commit, _ = repo.CommitObject(head.Hash())
+	if commit.Committer.Name != "CI Bot" || commit.Committer.When.Unix() != 1700000000 || commit.Author.Name != "Test User" {
+		t.Errorf("Unexpected identities: author %s, committer %s", commit.Author, commit.Committer)
+	}
+
+	for _, opts := range []CommitOptions{
+		{AllowEmpty: true, AuthorEmail: "not <valid>"},
+		{AllowEmpty: true, AuthorDate: "someday"},
+	} {
+		if _, err := ops.Commit(tempDir, "Invalid", opts); err == nil {
+			t.Errorf("Expected error for %+v", opts)
+		}
+	}
+}
+
+func TestTrailers_UnmarshalJSON(t *testing.T) {
+	var trailers Trailers
+	if err := json.Unmarshal([]byte(`{"Reviewed-by": "A <a@example.com>", "Co-authored-by": ["B <b@example.com>", "C <c@example.com>"]}`), &trailers); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	expected := Trailers{
+		"Reviewed-by":    {"A <a@example.com>"},
+		"Co-authored-by": {"B <b@example.com>", "C <c@example.com>"},
+	}
+	if !reflect.DeepEqual(trailers, expected) {
+		t.Errorf("Expected %v, got %v", expected, trailers)
+	}
+
+	if err := json.Unmarshal([]byte(`{"Reviewed-by": 1}`), &trailers); err == nil {
+		t.Errorf("Expected a trailer that is not a string to be rejected")
+	}
+}
@@ -0,0 +1,133 @@
+package gitops
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	commitgraphfmt "github.com/go-git/go-git/v5/plumbing/format/commitgraph/v2"
+	"github.com/go-git/go-git/v5/plumbing/object/commitgraph"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// commitNodes returns the commits of a repository as graph nodes, read from
+// its commit-graph files when it has some (git commit-graph write, which gc
+// runs by default, or the commit-graph maintenance task). Nodes of the
+// commit-graph hold their parents and generation number without decoding
+// the commit objects, commits written since falling back to their objects.
+// Call done once finished with the nodes.
+func commitNodes(repo *git.Repository) (index commitgraph.CommitNodeIndex, done func()) {
+	if storage, ok := repo.Storer.(*filesystem.Storage); ok {
+		if graph, err := commitgraphfmt.OpenChainOrFileIndex(storage.Filesystem()); err == nil {
+			return commitgraph.NewGraphCommitNodeIndex(graph, repo.Storer), func() { graph.Close() }
+		}
+	}
+	return commitgraph.NewObjectCommitNodeIndex(repo.Storer), func() {}
+}
+
+// hasCommitGraph reports whether a repository has commit-graph files
+func hasCommitGraph(repo *git.Repository) (commits int, ok bool) {
+	storage, isFilesystem := repo.Storer.(*filesystem.Storage)
+	if !isFilesystem {
+		return 0, false
+	}
+	graph, err := commitgraphfmt.OpenChainOrFileIndex(storage.Filesystem())
+	if err != nil {
+		return 0, false
+	}
+	defer graph.Close()
+	return int(graph.MaximumNumberOfHashes()), true
+}
+
+// isAncestor reports whether commit a is reachable from commit b. The walk
+// from b skips the commits whose generation number is below the one of a,
+// which cannot reach it, so that with a commit-graph only the history
+// between the two commits is visited.
+func isAncestor(repo *git.Repository, a, b plumbing.Hash) (bool, error) {
+	if a == b {
+		return true, nil
+	}
+
+	index, done := commitNodes(repo)
+	defer done()
+	ancestor, err := index.Get(a)
+	if err != nil {
+		return false, fmt.Errorf("failed to get commit %s: %w", a.String()[:7], err)
+	}
+	descendant, err := index.Get(b)
+	if err != nil {
+		return false, fmt.Errorf("failed to get commit %s: %w", b.String()[:7], err)
+	}
+
+	generation := ancestor.Generation()
+	found := false
+	err = walkCommitNodes(descendant, func(node commitgraph.CommitNode) (bool, error) {
+		if node.ID() == a {
+			found = true
+			return false, storer.ErrStop
+		}
+		return node.Generation() >= generation, nil
+	})
+	return found, err
+}
+
+// reachableCommits returns the set of commits reachable from hash,
+// including hash itself
+func reachableCommits(repo *git.Repository, hash plumbing.Hash) (map[plumbing.Hash]bool, error) {
+	index, done := commitNodes(repo)
+	defer done()
+	start, err := index.Get(hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit %s: %w", hash.String()[:7], err)
+	}
+
+	reachable := make(map[plumbing.Hash]bool)
+	err = walkCommitNodes(start, func(node commitgraph.CommitNode) (bool, error) {
+		reachable[node.ID()] = true
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reachable, nil
+}
+
+// walkCommitNodes visits the history of a commit once per commit, going on
+// with the parents of the commits visit accepts, until it returns
+// storer.ErrStop or fails. Parents missing from the repository, such as the
+// boundary of a shallow clone, end the walk there.
+func walkCommitNodes(start commitgraph.CommitNode, visit func(commitgraph.CommitNode) (bool, error)) error {
+	seen := map[plumbing.Hash]bool{start.ID(): true}
+	stack := []commitgraph.CommitNode{start}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		descend, err := visit(node)
+		if err == storer.ErrStop {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !descend {
+			continue
+		}
+		for i, parentHash := range node.ParentHashes() {
+			if seen[parentHash] {
+				continue
+			}
+			seen[parentHash] = true
+			parent, err := node.ParentNode(i)
+			if errors.Is(err, plumbing.ErrObjectNotFound) {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to walk history: %w", err)
+			}
+			stack = append(stack, parent)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package gitops
+
+import (
+	"fmt"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// syntheticHistory writes a history of empty commits straight into the
+// object database: a main line of length commits, and a side branch of 5
+// commits forking at the first third of it, merged back at the second
+// third unless unmerged. It returns the main line, oldest first, and the
+// side branch.
+func syntheticHistory(tb testing.TB, length int, unmerged bool) (string, []plumbing.Hash, []plumbing.Hash) {
+	tb.Helper()
+	dir := tb.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		tb.Fatalf("Failed to init repo: %v", err)
+	}
+
+	treeHash, err := repo.Storer.SetEncodedObject(encodeObject(tb, repo, &object.Tree{}))
+	if err != nil {
+		tb.Fatalf("Failed to write tree: %v", err)
+	}
+	when := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	commit := func(message string, parents ...plumbing.Hash) plumbing.Hash {
+		when = when.Add(time.Minute)
+		signature := object.Signature{Name: "Test User", Email: "test@example.com", When: when}
+		c := &object.Commit{Author: signature, Committer: signature, Message: message, TreeHash: treeHash, ParentHashes: parents}
+		hash, err := repo.Storer.SetEncodedObject(encodeObject(tb, repo, c))
+		if err != nil {
+			tb.Fatalf("Failed to write commit: %v", err)
+		}
+		return hash
+	}
+
+	var main, side []plumbing.Hash
+	for i := 0; i < length; i++ {
+		var parents []plumbing.Hash
+		if i > 0 {
+			parents = append(parents, main[i-1])
+		}
+		if i == length/3 {
+			parent := main[i-1]
+			for j := 0; j < 5; j++ {
+				parent = commit(fmt.Sprintf("side %d", j), parent)
+				side = append(side, parent)
+			}
+		}
+		if i == 2*length/3 && !unmerged {
+			parents = append(parents, side[len(side)-1])
+		}
+		main = append(main, commit(fmt.Sprintf("main %d", i), parents...))
+	}
+
+	refs := map[plumbing.ReferenceName]plumbing.Hash{
+		plumbing.NewBranchReferenceName("master"): main[len(main)-1],
+		plumbing.NewBranchReferenceName("side"):   side[len(side)-1],
+	}
+	for name, hash := range refs {
+		if err := repo.Storer.SetReference(plumbing.NewHashReference(name, hash)); err != nil {
+			tb.Fatalf("Failed to set %s: %v", name, err)
+		}
+	}
+	return dir, main, side
+}
+
+func encodeObject(tb testing.TB, repo *git.Repository, o interface {
+	Encode(plumbing.EncodedObject) error
+}) plumbing.EncodedObject {
+	tb.Helper()
+	encoded := repo.Storer.NewEncodedObject()
+	if err := o.Encode(encoded); err != nil {
+		tb.Fatalf("Failed to encode object: %v", err)
+	}
+	return encoded
+}
+
+func writeCommitGraph(tb testing.TB, dir string) {
+	tb.Helper()
+	if output, err := exec.Command("git", "-C", dir, "commit-graph", "write", "--reachable").CombinedOutput(); err != nil {
+		tb.Fatalf("git commit-graph write failed: %v\n%s", err, output)
+	}
+}
+
+func TestIsAncestor_CommitGraph(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not available")
+	}
+
+	for _, unmerged := range []bool{false, true} {
+		dir, main, side := syntheticHistory(t, 60, unmerged)
+		pairs := [][2]plumbing.Hash{
+			{main[0], main[59]},
+			{main[59], main[0]},
+			{main[30], main[31]},
+			{side[2], main[59]},
+			{side[2], main[30]},
+			{main[19], side[4]},
+			{main[20], side[4]},
+			{side[4], side[0]},
+		}
+
+		check := func() {
+			repo, err := git.PlainOpen(dir)
+			if err != nil {
+				t.Fatalf("Failed to open repo: %v", err)
+			}
+			for _, pair := range pairs {
+				a, err := repo.CommitObject(pair[0])
+				if err != nil {
+					t.Fatal(err)
+				}
+				b, err := repo.CommitObject(pair[1])
+				if err != nil {
+					t.Fatal(err)
+				}
+				expected, err := a.IsAncestor(b)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got, err := isAncestor(repo, pair[0], pair[1])
+				if err != nil {
+					t.Fatalf("isAncestor failed: %v", err)
+				}
+				if got != expected {
+					t.Errorf("isAncestor(%s, %s) = %t, expected %t (unmerged: %t)", pair[0].String()[:7], pair[1].String()[:7], got, expected, unmerged)
+				}
+			}
+			reachable, err := reachableCommits(repo, main[59])
+			if err != nil {
+				t.Fatalf("reachableCommits failed: %v", err)
+			}
+			expected := 60
+			if !unmerged {
+				expected += 5
+			}
+			if len(reachable) != expected {
+				t.Errorf("Expected %d reachable commits, got %d", expected, len(reachable))
+			}
+		}
+
+		check()
+		writeCommitGraph(t, dir)
+		repo, _ := git.PlainOpen(dir)
+		if commits, ok := hasCommitGraph(repo); !ok || commits != 65 {
+			t.Fatalf("Expected a commit-graph of 65 commits, got %d (%t)", commits, ok)
+		}
+		check()
+	}
+}
+
+func TestOperations_MaintenanceCommitGraph(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not available")
+	}
+
+	dir, _, _ := syntheticHistory(t, 10, false)
+	ops := NewOperations("Test User", "test@example.com")
+	result, err := ops.Maintenance(dir, MaintenanceOptions{Task: "commit-graph"})
+	if err != nil {
+		t.Fatalf("Maintenance failed: %v", err)
+	}
+	if result != fmt.Sprintf("Wrote the commit-graph of %s: 15 commits", dir) {
+		t.Errorf("Unexpected result: %s", result)
+	}
+	if _, err := ops.Maintenance(dir, MaintenanceOptions{Task: "commit-graph", Aggressive: true}); err == nil {
+		t.Error("Expected aggressive to be rejected by the commit-graph task")
+	}
+}
+
+// BenchmarkIsAncestor asks whether the unmerged side branch is contained in
+// the main line, which walks the whole history without a commit-graph and
+// stops at the fork point with one
+func BenchmarkIsAncestor(b *testing.B) {
+	if _, err := exec.LookPath("git"); err != nil {
+		b.Skip("git executable not available")
+	}
+	dir, main, side := syntheticHistory(b, 5000, true)
+
+	run := func(b *testing.B) {
+		repo, err := git.PlainOpen(dir)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if contained, err := isAncestor(repo, side[4], main[len(main)-1]); err != nil || contained {
+				b.Fatalf("Expected the side branch not to be contained: %v", err)
+			}
+		}
+	}
+	b.Run("objects", run)
+	writeCommitGraph(b, dir)
+	b.Run("commit-graph", run)
+}
+
+// BenchmarkReachableCommits lists the history of the main line, as log
+// ranges and ahead/behind counts do
+func BenchmarkReachableCommits(b *testing.B) {
+	if _, err := exec.LookPath("git"); err != nil {
+		b.Skip("git executable not available")
+	}
+	dir, main, _ := syntheticHistory(b, 5000, false)
+
+	run := func(b *testing.B) {
+		repo, err := git.PlainOpen(dir)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := reachableCommits(repo, main[len(main)-1]); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	b.Run("objects", run)
+	writeCommitGraph(b, dir)
+	b.Run("commit-graph", run)
+}
@@ -0,0 +1,291 @@
+package gitops
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// mergedStage is the stage of the index entries of merged paths. go-git's
+// index.Merged constant is 1, clashing with index.AncestorMode, while the
+// decoder (like git) uses 0.
+const mergedStage index.Stage = 0
+
+// conflict is a path with unmerged index entries. stages holds the base
+// (1), ours (2) and theirs (3) entries, nil where a side does not have the
+// file.
+type conflict struct {
+	path   string
+	stages [4]*index.Entry
+}
+
+// code returns the two-letter status of the conflict as in git status
+// --short: DD, AU, UD, UA, DU, AA or UU
+func (c *conflict) code() string {
+	base, ours, theirs := c.stages[index.AncestorMode] != nil, c.stages[index.OurMode] != nil, c.stages[index.TheirMode] != nil
+	switch {
+	case base && !ours && !theirs:
+		return "DD"
+	case !base && ours && !theirs:
+		return "AU"
+	case base && ours && !theirs:
+		return "UD"
+	case !base && !ours && theirs:
+		return "UA"
+	case base && !ours && theirs:
+		return "DU"
+	case !base && ours && theirs:
+		return "AA"
+	default:
+		return "UU"
+	}
+}
+
+// conflictKinds describes the conflict status codes
+var conflictKinds = map[string]string{
+	"DD": "both deleted",
+	"AU": "added by us",
+	"UD": "deleted by them",
+	"UA": "added by them",
+	"DU": "deleted by us",
+	"AA": "both added",
+	"UU": "both modified",
+}
+
+// indexConflicts returns the unmerged paths of the index by path
+func indexConflicts(repo *git.Repository) (map[string]*conflict, error) {
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+
+	conflicts := make(map[string]*conflict)
+	for _, entry := range idx.Entries {
+		if entry.Stage == mergedStage {
+			continue
+		}
+		c, ok := conflicts[entry.Name]
+		if !ok {
+			c = &conflict{path: entry.Name}
+			conflicts[entry.Name] = c
+		}
+		if entry.Stage >= index.AncestorMode && entry.Stage <= index.TheirMode {
+			c.stages[entry.Stage] = entry
+		}
+	}
+	return conflicts, nil
+}
+
+// sortedConflicts returns conflicts ordered by path
+func sortedConflicts(conflicts map[string]*conflict) []*conflict {
+	sorted := make([]*conflict, 0, len(conflicts))
+	for _, c := range conflicts {
+		sorted = append(sorted, c)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].path < sorted[j].path
+	})
+	return sorted
+}
+
+// unmergedNote lists the unmerged paths left out of a diff, empty when there
+// are none
+func unmergedNote(conflicts map[string]*conflict) string {
+	if len(conflicts) == 0 {
+		return ""
+	}
+	paths := make([]string, 0, len(conflicts))
+	for _, c := range sortedConflicts(conflicts) {
+		paths = append(paths, c.path)
+	}
+	return fmt.Sprintf("Unmerged paths (resolve and stage them, see git_conflicts): %s", strings.Join(paths, ", "))
+}
+
+// withOperationContext frames the result of a read-only tool with the
+// operation the repository is in the middle of and the unmerged paths the
+// result leaves out
+func withOperationContext(repo *git.Repository, result string, conflicts map[string]*conflict) string {
+	if note := unmergedNote(conflicts); note != "" {
+		result += "\n\n" + note
+	}
+	if operation := operationInProgress(repo); operation != "" {
+		result = operation + "\n\n" + result
+	}
+	return result
+}
+
+// gitDir returns the git directory of a repository opened from disk, empty
+// for other storages
+func gitDir(repo *git.Repository) string {
+	storage, ok := repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return ""
+	}
+	return storage.Filesystem().Root()
+}
+
+// operationInProgress describes the merge, rebase, cherry-pick, revert, am
+// or bisect the repository is in the middle of, empty when there is none
+func operationInProgress(repo *git.Repository) string {
+	dir := gitDir(repo)
+	if dir == "" {
+		return ""
+	}
+
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(data))
+	}
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(dir, name))
+		return err == nil
+	}
+	short := func(hashes string) string {
+		var names []string
+		for _, line := range strings.Fields(hashes) {
+			if plumbing.IsHash(line) {
+				line = line[:7]
+			}
+			names = append(names, line)
+		}
+		return strings.Join(names, ", ")
+	}
+	branch := func(ref string) string {
+		return strings.TrimPrefix(ref, "refs/heads/")
+	}
+
+	switch {
+	case exists("rebase-merge"):
+		return fmt.Sprintf("Rebase in progress: rebasing %s onto %s%s",
+			orDetached(branch(read("rebase-merge/head-name"))), short(read("rebase-merge/onto")),
+			rebaseStep(read("rebase-merge/msgnum"), read("rebase-merge/end")))
+	case exists("rebase-apply/applying"):
+		return fmt.Sprintf("Mailbox apply (am) in progress%s", rebaseStep(read("rebase-apply/next"), read("rebase-apply/last")))
+	case exists("rebase-apply"):
+		return fmt.Sprintf("Rebase in progress: rebasing %s onto %s%s",
+			orDetached(branch(read("rebase-apply/head-name"))), short(read("rebase-apply/onto")),
+			rebaseStep(read("rebase-apply/next"), read("rebase-apply/last")))
+	case exists("MERGE_HEAD"):
+		return fmt.Sprintf("Merge in progress: merging %s", short(read("MERGE_HEAD")))
+	case exists("CHERRY_PICK_HEAD"):
+		return fmt.Sprintf("Cherry-pick in progress: picking %s", short(read("CHERRY_PICK_HEAD")))
+	case exists("REVERT_HEAD"):
+		return fmt.Sprintf("Revert in progress: reverting %s", short(read("REVERT_HEAD")))
+	case exists("BISECT_LOG"):
+		return "Bisect in progress"
+	}
+	return ""
+}
+
+func orDetached(branch string) string {
+	if branch == "" || branch == "detached HEAD" {
+		return "detached HEAD"
+	}
+	return branch
+}
+
+// rebaseStep formats the progress of a rebase, empty when unknown
+func rebaseStep(current, total string) string {
+	n, err1 := strconv.Atoi(current)
+	m, err2 := strconv.Atoi(total)
+	if err1 != nil || err2 != nil {
+		return ""
+	}
+	return fmt.Sprintf(" (step %d of %d)", n, m)
+}
+
+// OperationInProgress describes the merge, rebase, cherry-pick, revert, am
+// or bisect a repository is in the middle of, empty when there is none
+func (g *Operations) OperationInProgress(repoPath string) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+	return operationInProgress(repo), nil
+}
+
+// Conflicts lists the unmerged paths of the index with the kind of conflict
+// and whether the working tree file still holds conflict markers
+func (g *Operations) Conflicts(repoPath string) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	conflicts, err := indexConflicts(repo)
+	if err != nil {
+		return "", err
+	}
+
+	var result strings.Builder
+	if operation := operationInProgress(repo); operation != "" {
+		result.WriteString(operation + "\n")
+	}
+	if len(conflicts) == 0 {
+		result.WriteString("No conflicts")
+		return result.String(), nil
+	}
+
+	result.WriteString(fmt.Sprintf("Conflicted files (%d):\n", len(conflicts)))
+	for _, c := range sortedConflicts(conflicts) {
+		var sides []string
+		for stage, name := range []string{index.AncestorMode: "base", index.OurMode: "ours", index.TheirMode: "theirs"} {
+			if entry := c.stages[stage]; entry != nil {
+				sides = append(sides, fmt.Sprintf("%d:%s=%s", stage, name, entry.Hash.String()[:7]))
+			}
+		}
+
+		line := fmt.Sprintf("%s %s (%s; %s)", c.code(), c.path, conflictKinds[c.code()], strings.Join(sides, " "))
+		switch markers, err := conflictMarkers(filepath.Join(worktree.Filesystem.Root(), filepath.FromSlash(c.path))); {
+		case os.IsNotExist(err):
+			line += ", missing from the working tree"
+		case err != nil:
+			return "", fmt.Errorf("failed to read %s: %w", c.path, err)
+		case markers > 0:
+			line += fmt.Sprintf(", %d conflict region(s) left", markers)
+		default:
+			line += ", no conflict markers left (stage it to mark it resolved)"
+		}
+		result.WriteString(line + "\n")
+	}
+
+	return strings.TrimSpace(result.String()), nil
+}
+
+// conflictMarkers counts the conflict regions left in a file
+func conflictMarkers(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for scanner.Scan() {
+		if strings.HasPrefix(scanner.Text(), "<<<<<<< ") || scanner.Text() == "<<<<<<<" {
+			count++
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,195 @@
+package gitops
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+)
+
+// startConflictedMerge leaves the repository in the middle of a merge of
+// theirs whose changes to name conflict with HEAD, as git merge does
+func startConflictedMerge(t *testing.T, repo *git.Repository, name string, base, ours, theirs plumbing.Hash) {
+	t.Helper()
+
+	blob := func(commitHash plumbing.Hash) plumbing.Hash {
+		commit, err := repo.CommitObject(commitHash)
+		if err != nil {
+			t.Fatalf("Failed to get commit: %v", err)
+		}
+		file, err := commit.File(name)
+		if err != nil {
+			t.Fatalf("Failed to get %s: %v", name, err)
+		}
+		return file.Hash
+	}
+
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		t.Fatalf("Failed to read index: %v", err)
+	}
+	if _, err := idx.Remove(name); err != nil {
+		t.Fatalf("Failed to remove index entry: %v", err)
+	}
+	for stage, commit := range map[index.Stage]plumbing.Hash{index.AncestorMode: base, index.OurMode: ours, index.TheirMode: theirs} {
+		idx.Entries = append(idx.Entries, &index.Entry{Name: name, Hash: blob(commit), Mode: filemode.Regular, Stage: stage})
+	}
+	sort.SliceStable(idx.Entries, func(i, j int) bool {
+		if idx.Entries[i].Name != idx.Entries[j].Name {
+			return idx.Entries[i].Name < idx.Entries[j].Name
+		}
+		return idx.Entries[i].Stage < idx.Entries[j].Stage
+	})
+	if err := repo.Storer.SetIndex(idx); err != nil {
+		t.Fatalf("Failed to write index: %v", err)
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+	root := worktree.Filesystem.Root()
+	markers := "a\n<<<<<<< HEAD\nmain\n=======\nside\n>>>>>>> side\n"
+	if err := os.WriteFile(filepath.Join(root, name), []byte(markers), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".git", "MERGE_HEAD"), []byte(theirs.String()+"\n"), 0644); err != nil {
+		t.Fatalf("Failed to write MERGE_HEAD: %v", err)
+	}
+}
+
+func TestOperations_ReadOnlyToolsDuringMerge(t *testing.T) {
+	tempDir, repo := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	commitFile(t, ops, tempDir, "f.txt", "a\nb\n", "Add f")
+	commitFile(t, ops, tempDir, "other.txt", "x\n", "Add other")
+	head, _ := repo.Head()
+	base := head.Hash()
+
+	if _, err := ops.Checkout(tempDir, "side", true, ""); err != nil {
+		t.Fatalf("Checkout failed: %v", err)
+	}
+	commitFile(t, ops, tempDir, "f.txt", "a\nside\n", "Change f on side")
+	head, _ = repo.Head()
+	theirs := head.Hash()
+
+	if _, err := ops.Checkout(tempDir, "master", false, ""); err != nil {
+		t.Fatalf("Checkout failed: %v", err)
+	}
+	commitFile(t, ops, tempDir, "f.txt", "a\nmain\n", "Change f on master")
+	head, _ = repo.Head()
+	ours := head.Hash()
+
+	startConflictedMerge(t, repo, "f.txt", base, ours, theirs)
+	if err := os.WriteFile(filepath.Join(tempDir, "other.txt"), []byte("x\ny\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	operation := "Merge in progress: merging " + theirs.String()[:7]
+
+	status, err := ops.Status(tempDir, false)
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if status != operation+"\n\nConflicted (1):\n  both modified: f.txt\n\nUnstaged (1):\n  modified: other.txt" {
+		t.Errorf("Unexpected status: %q", status)
+	}
+
+	staged, err := ops.DiffStaged(tempDir, DiffOptions{ContextLines: 3})
+	if err != nil {
+		t.Fatalf("DiffStaged failed: %v", err)
+	}
+	if !strings.HasPrefix(staged, operation+"\n\nno staged changes") || !strings.HasSuffix(staged, "git_conflicts): f.txt") {
+		t.Errorf("Unexpected staged diff: %s", staged)
+	}
+
+	unstaged, err := ops.DiffUnstaged(tempDir, DiffOptions{ContextLines: 3})
+	if err != nil {
+		t.Fatalf("DiffUnstaged failed: %v", err)
+	}
+	if !strings.Contains(unstaged, "+y") || strings.Contains(unstaged, "diff --git a/f.txt") || !strings.Contains(unstaged, "Unmerged paths") {
+		t.Errorf("Unexpected unstaged diff: %s", unstaged)
+	}
+
+	diff, err := ops.Diff(tempDir, "HEAD", DiffOptions{ContextLines: 3})
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if !strings.HasPrefix(diff, operation) || !strings.Contains(diff, "+<<<<<<< HEAD") {
+		t.Errorf("Expected the conflict markers in the diff, got: %s", diff)
+	}
+
+	if _, err := ops.Log(tempDir, LogOptions{MaxCount: 5}); err != nil {
+		t.Errorf("Log failed: %v", err)
+	}
+
+	conflicts, err := ops.Conflicts(tempDir)
+	if err != nil {
+		t.Fatalf("Conflicts failed: %v", err)
+	}
+	if !strings.HasPrefix(conflicts, operation+"\nConflicted files (1):\nUU f.txt (both modified; 1:base=") || !strings.HasSuffix(conflicts, "1 conflict region(s) left") {
+		t.Errorf("Unexpected conflicts: %s", conflicts)
+	}
+
+	// Resolving the markers is reported before the file is staged
+	if err := os.WriteFile(filepath.Join(tempDir, "f.txt"), []byte("a\nmain and side\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	conflicts, err = ops.Conflicts(tempDir)
+	if err != nil {
+		t.Fatalf("Conflicts failed: %v", err)
+	}
+	if !strings.HasSuffix(conflicts, "no conflict markers left (stage it to mark it resolved)") {
+		t.Errorf("Unexpected conflicts: %s", conflicts)
+	}
+}
+
+func TestOperations_OperationInProgress(t *testing.T) {
+	tempDir, _ := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	operation, err := ops.OperationInProgress(tempDir)
+	if err != nil {
+		t.Fatalf("OperationInProgress failed: %v", err)
+	}
+	if operation != "" {
+		t.Errorf("Expected no operation, got: %s", operation)
+	}
+
+	rebase := filepath.Join(tempDir, ".git", "rebase-merge")
+	if err := os.MkdirAll(rebase, 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	onto := strings.Repeat("ab", 20)
+	for name, content := range map[string]string{"head-name": "refs/heads/feature\n", "onto": onto + "\n", "msgnum": "2\n", "end": "3\n"} {
+		if err := os.WriteFile(filepath.Join(rebase, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	operation, err = ops.OperationInProgress(tempDir)
+	if err != nil {
+		t.Fatalf("OperationInProgress failed: %v", err)
+	}
+	if operation != "Rebase in progress: rebasing feature onto abababa (step 2 of 3)" {
+		t.Errorf("Unexpected operation: %s", operation)
+	}
+
+	conflicts, err := ops.Conflicts(tempDir)
+	if err != nil {
+		t.Fatalf("Conflicts failed: %v", err)
+	}
+	if conflicts != operation+"\nNo conflicts" {
+		t.Errorf("Unexpected conflicts: %s", conflicts)
+	}
+}
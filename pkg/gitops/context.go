@@ -0,0 +1,58 @@
+package gitops
+
+import (
+	"context"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/pengcunfu/go-mcp-git/internal/tracing"
+)
+
+// WithContext returns a copy of the operations bound to ctx. Network
+// operations and the git executable stop when ctx is done, failing with its
+// error.
+func (g *Operations) WithContext(ctx context.Context) *Operations {
+	bound := *g
+	bound.ctx = ctx
+	return &bound
+}
+
+// context returns the context the operations are bound to
+func (g *Operations) context() context.Context {
+	if g.ctx == nil {
+		return context.Background()
+	}
+	return g.ctx
+}
+
+// WithIdentity returns a copy of the operations committing and tagging as
+// another user. Empty values keep the identity of g.
+func (g *Operations) WithIdentity(userName, userEmail string) *Operations {
+	bound := *g
+	if userName != "" {
+		bound.userName = userName
+	}
+	if userEmail != "" {
+		bound.userEmail = userEmail
+	}
+	return &bound
+}
+
+// trace starts a span of the trace of the operations, returning a copy of
+// them bound to it so that the spans of what they run are its children.
+// Untraced operations get a nil span.
+func (g *Operations) trace(name string, kind int, attrs ...tracing.Attribute) (*Operations, *tracing.Span) {
+	ctx, span := tracing.Start(g.context(), name, kind, attrs...)
+	if span == nil {
+		return g, nil
+	}
+	return g.WithContext(ctx), span
+}
+
+// endNetworkSpan ends the span of a network operation, which succeeded
+// when there was nothing to transfer
+func endNetworkSpan(span *tracing.Span, err error) {
+	if err == git.NoErrAlreadyUpToDate {
+		err = nil
+	}
+	span.End(err)
+}
@@ -0,0 +1,55 @@
+package gitops
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestOperations_WithContext(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not available")
+	}
+
+	upstreamDir, _ := createTestRepo(t)
+	defer os.RemoveAll(upstreamDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	bound := ops.WithContext(ctx)
+
+	cloneDir := filepath.Join(t.TempDir(), "clone")
+	if _, err := bound.Clone(upstreamDir, cloneDir, CloneOptions{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected clone to stop with the context, got: %v", err)
+	}
+	if _, err := os.Stat(cloneDir); !os.IsNotExist(err) {
+		t.Error("Expected a stopped clone to leave no directory behind")
+	}
+	if _, err := bound.runGit(upstreamDir, "status"); err == nil {
+		t.Error("Expected the git executable not to run with a cancelled context")
+	}
+
+	// The operations the context was bound from are unaffected
+	if _, err := ops.Clone(upstreamDir, cloneDir, CloneOptions{}); err != nil {
+		t.Errorf("Clone failed: %v", err)
+	}
+}
+
+func TestOperations_WithIdentity(t *testing.T) {
+	ops := NewOperations("Test User", "test@example.com")
+
+	bound := ops.WithIdentity("Other User", "")
+	signature := bound.getUserSignature()
+	if signature.Name != "Other User" || signature.Email != "test@example.com" {
+		t.Errorf("Expected Other User <test@example.com>, got %s <%s>", signature.Name, signature.Email)
+	}
+
+	// The operations the identity was bound from are unaffected
+	if signature := ops.getUserSignature(); signature.Name != "Test User" {
+		t.Errorf("Expected Test User, got %s", signature.Name)
+	}
+}
@@ -0,0 +1,187 @@
+package gitops
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// DiffDirectory compares the tree of a revision (HEAD by default) with an
+// arbitrary directory on disk. Files only present in the directory are
+// reported as added, files only present in the revision as deleted.
+func (g *Operations) DiffDirectory(repoPath, revision, dir string, opts DiffOptions) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	if revision == "" {
+		revision = "HEAD"
+	}
+
+	hash, err := resolveRevision(repo, revision)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve revision '%s': %w", revision, err)
+	}
+
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return "", fmt.Errorf("failed to get commit %s: %w", revision, err)
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get tree: %w", err)
+	}
+
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", dir)
+	}
+
+	external, err := readDirectoryFiles(dir, nil)
+	if err != nil {
+		return "", err
+	}
+
+	committed := make(map[string]*object.File)
+	err = tree.Files().ForEach(func(f *object.File) error {
+		committed[f.Name] = f
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to iterate tree: %w", err)
+	}
+
+	names := make([]string, 0, len(committed)+len(external))
+	for name := range committed {
+		names = append(names, name)
+	}
+	for name := range external {
+		if _, ok := committed[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var pairs []filePair
+	for _, name := range names {
+		ext, inDir := external[name]
+		file, inTree := committed[name]
+
+		if inDir && inTree && ext.hash == file.Hash && ext.mode == file.Mode {
+			continue
+		}
+
+		var from *patchFile
+		if inTree {
+			from, err = blobPatchFile(file)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		var to *patchFile
+		if inDir {
+			to = ext
+		}
+
+		pairs = append(pairs, filePair{from, to})
+	}
+
+	filePatches, err := buildFilePatches(pairs, opts)
+	if err != nil {
+		return "", err
+	}
+	if len(filePatches) == 0 {
+		return fmt.Sprintf("No differences between %s and %s", hash.String()[:7], dir), nil
+	}
+
+	rendered, err := renderFilePatches(filePatches, opts)
+	if err != nil {
+		return "", err
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Comparing %s (%s) with %s\n", revision, hash.String()[:7], dir))
+	if opts.Format == "" || opts.Format == DiffFormatPatch {
+		result.WriteString(summarizeFilePatches(filePatches))
+		result.WriteString("\n\n")
+	}
+	result.WriteString(rendered)
+
+	return strings.TrimSpace(result.String()), nil
+}
+
+// readDirectoryFiles reads every file below dir (skipping .git directories
+// and paths matched by ignore, if not nil) keyed by slash-separated path
+// relative to dir
+func readDirectoryFiles(dir string, ignore gitignore.Matcher) (map[string]*patchFile, error) {
+	files := make(map[string]*patchFile)
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == dir {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if info.IsDir() {
+			if info.Name() == ".git" || (ignore != nil && ignore.Match(strings.Split(rel, "/"), true)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ignore != nil && ignore.Match(strings.Split(rel, "/"), false) {
+			return nil
+		}
+
+		file, err := readFileInfo(path, rel, info)
+		if err != nil {
+			return err
+		}
+		if file != nil {
+			files[rel] = file
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+
+	return files, nil
+}
+
+// blobPatchFile loads a committed file as a patch side
+func blobPatchFile(file *object.File) (*patchFile, error) {
+	reader, err := file.Reader()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file.Name, err)
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file.Name, err)
+	}
+
+	return &patchFile{
+		path:    file.Name,
+		mode:    file.Mode,
+		hash:    file.Hash,
+		content: content,
+	}, nil
+}
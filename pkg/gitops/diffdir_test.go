@@ -0,0 +1,107 @@
+package gitops
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOperations_DiffDirectory(t *testing.T) {
+	tempDir, _ := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	external := t.TempDir()
+	if err := os.WriteFile(filepath.Join(external, "test.txt"), []byte("test content"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	result, err := ops.DiffDirectory(tempDir, "", external, DiffOptions{ContextLines: DefaultContextLines})
+	if err != nil {
+		t.Fatalf("DiffDirectory failed: %v", err)
+	}
+	if !contains(result, "No differences") {
+		t.Errorf("Expected no differences, got: %s", result)
+	}
+
+	if err := os.WriteFile(filepath.Join(external, "test.txt"), []byte("new content\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(external, "extra.txt"), []byte("extra\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	result, err = ops.DiffDirectory(tempDir, "HEAD", external, DiffOptions{ContextLines: DefaultContextLines})
+	if err != nil {
+		t.Fatalf("DiffDirectory failed: %v", err)
+	}
+	for _, expected := range []string{"A\textra.txt", "M\ttest.txt", "-test content", "+new content", "+extra"} {
+		if !contains(result, expected) {
+			t.Errorf("Expected %q in result, got: %s", expected, result)
+		}
+	}
+}
+
+func TestOperations_ImportTree(t *testing.T) {
+	tempDir, repo := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	external := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(external, "node_modules"), 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	files := map[string]string{
+		"dist/app.js":         "console.log(1)\n",
+		"README":              "readme\n",
+		"node_modules/dep.js": "ignored\n",
+		"debug.log":           "ignored\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(external, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	// Import onto a new branch under a prefix, keeping the existing files
+	result, err := ops.ImportTree(tempDir, external, "vendor", "build", "add", "Import build", []string{"node_modules/", "*.log"})
+	if err != nil {
+		t.Fatalf("ImportTree failed: %v", err)
+	}
+	if !contains(result, "Imported 2 files") {
+		t.Errorf("Unexpected result: %s", result)
+	}
+
+	// The drop must match the imported branch, ignoring the skipped files
+	ref, err := repo.Reference("refs/heads/vendor", true)
+	if err != nil {
+		t.Fatalf("Expected vendor branch: %v", err)
+	}
+	commit, _ := repo.CommitObject(ref.Hash())
+	tree, _ := commit.Tree()
+	for _, name := range []string{"test.txt", "build/README", "build/dist/app.js"} {
+		if _, err := tree.File(name); err != nil {
+			t.Errorf("Expected %s in imported tree: %v", name, err)
+		}
+	}
+	if _, err := tree.File("build/debug.log"); err == nil {
+		t.Error("Expected ignored file to be skipped")
+	}
+
+	// Replace mode on the checked out branch mirrors the directory exactly
+	if _, err := ops.ImportTree(tempDir, external, "", "", "replace", "Replace", []string{"node_modules/", "*.log"}); err != nil {
+		t.Fatalf("ImportTree failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "test.txt")); !os.IsNotExist(err) {
+		t.Error("Expected test.txt to be removed by replace import")
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "dist", "app.js")); err != nil {
+		t.Errorf("Expected worktree to be updated: %v", err)
+	}
+}
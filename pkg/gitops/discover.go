@@ -0,0 +1,62 @@
+package gitops
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultDiscoveryDepth is the default number of directory levels below a
+// workspace searched for repositories
+const DefaultDiscoveryDepth = 3
+
+// discoverySkipped are directories never holding repositories worth
+// working on, and slow to walk
+var discoverySkipped = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+}
+
+// DiscoverRepositories finds the repositories in a workspace directory and
+// the directories below it, down to maxDepth levels. Hidden directories
+// are skipped, and repositories are not searched for nested ones, keeping
+// the scan of large workspaces quick. Worktrees and submodules, whose .git
+// is a file, are found too.
+func (g *Operations) DiscoverRepositories(root string, maxDepth int) ([]string, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workspace %s: %w", root, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("workspace %s is not a directory", root)
+	}
+
+	var repositories []string
+	var walk func(dir string, depth int)
+	walk = func(dir string, depth int) {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			repositories = append(repositories, dir)
+			return
+		}
+		if depth >= maxDepth {
+			return
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			// Unreadable directories are left out of the scan
+			return
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || discoverySkipped[entry.Name()] {
+				continue
+			}
+			walk(filepath.Join(dir, entry.Name()), depth+1)
+		}
+	}
+	walk(filepath.Clean(root), 0)
+
+	sort.Strings(repositories)
+	return repositories, nil
+}
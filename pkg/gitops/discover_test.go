@@ -0,0 +1,57 @@
+package gitops
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+)
+
+func TestOperations_DiscoverRepositories(t *testing.T) {
+	root := t.TempDir()
+	initAt := func(rel string) string {
+		path := filepath.Join(root, rel)
+		if _, err := git.PlainInit(path, false); err != nil {
+			t.Fatalf("Failed to init %s: %v", rel, err)
+		}
+		return path
+	}
+	service := initAt("service")
+	infra := initAt("team/infra")
+	initAt("service/nested")
+	initAt("a/b/c/too-deep")
+	initAt(".cache/hidden")
+	initAt("web/node_modules/dependency")
+
+	// A worktree, whose .git is a file
+	worktree := filepath.Join(root, "team", "worktree")
+	if err := os.MkdirAll(worktree, 0755); err != nil {
+		t.Fatalf("Failed to create worktree: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(worktree, ".git"), []byte("gitdir: "+infra+"/.git/worktrees/w\n"), 0644); err != nil {
+		t.Fatalf("Failed to write .git file: %v", err)
+	}
+
+	ops := NewOperations("Test User", "test@example.com")
+	found, err := ops.DiscoverRepositories(root, DefaultDiscoveryDepth)
+	if err != nil {
+		t.Fatalf("DiscoverRepositories failed: %v", err)
+	}
+	if expected := []string{service, infra, worktree}; !reflect.DeepEqual(found, expected) {
+		t.Errorf("Expected %v, got %v", expected, found)
+	}
+
+	found, err = ops.DiscoverRepositories(root, 4)
+	if err != nil || len(found) != 4 {
+		t.Errorf("Expected the deeper repository at depth 4, got %v (%v)", found, err)
+	}
+
+	if found, err := ops.DiscoverRepositories(service, 0); err != nil || !reflect.DeepEqual(found, []string{service}) {
+		t.Errorf("Expected a workspace that is a repository to be found, got %v (%v)", found, err)
+	}
+	if _, err := ops.DiscoverRepositories(filepath.Join(root, "missing"), 1); err == nil {
+		t.Error("Expected a missing workspace to fail")
+	}
+}
@@ -0,0 +1,136 @@
+package gitops
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DiskUsage reports the size of the working tree, the .git directory and a
+// breakdown of pack files and loose objects for a repository.
+func (g *Operations) DiskUsage(repoPath string) (string, error) {
+	gitDir, bare, err := findGitDir(repoPath)
+	if err != nil {
+		return "", err
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Repository: %s\n", repoPath))
+
+	if !bare {
+		size, files, err := dirSize(repoPath, ".git")
+		if err != nil {
+			return "", err
+		}
+		result.WriteString(fmt.Sprintf("  Working tree: %s (%d files)\n", formatBytes(size), files))
+	}
+
+	gitSize, _, err := dirSize(gitDir, "")
+	if err != nil {
+		return "", err
+	}
+	result.WriteString(fmt.Sprintf("  .git: %s\n", formatBytes(gitSize)))
+
+	// Pack breakdown
+	packDir := filepath.Join(gitDir, "objects", "pack")
+	packs, _ := filepath.Glob(filepath.Join(packDir, "*.pack"))
+	sort.Strings(packs)
+	var packTotal int64
+	if len(packs) > 0 {
+		result.WriteString(fmt.Sprintf("  Packs (%d):\n", len(packs)))
+		for _, pack := range packs {
+			packSize := fileSize(pack)
+			idxSize := fileSize(strings.TrimSuffix(pack, ".pack") + ".idx")
+			packTotal += packSize + idxSize
+			result.WriteString(fmt.Sprintf("    %s: %s (idx %s)\n", filepath.Base(pack), formatBytes(packSize), formatBytes(idxSize)))
+		}
+	} else {
+		result.WriteString("  Packs: none\n")
+	}
+
+	// Loose objects live in two-character fan-out directories
+	var looseCount int
+	var looseSize int64
+	fanout, _ := filepath.Glob(filepath.Join(gitDir, "objects", "[0-9a-f][0-9a-f]"))
+	for _, dir := range fanout {
+		size, files, err := dirSize(dir, "")
+		if err != nil {
+			return "", err
+		}
+		looseCount += files
+		looseSize += size
+	}
+	result.WriteString(fmt.Sprintf("  Loose objects: %d (%s)\n", looseCount, formatBytes(looseSize)))
+	result.WriteString(fmt.Sprintf("  Other .git data: %s", formatBytes(gitSize-packTotal-looseSize)))
+
+	return result.String(), nil
+}
+
+// ScratchUsage reports how much of the scratch disk budget is in use
+func (g *Operations) ScratchUsage() (string, error) {
+	entries, err := g.scratch.Entries()
+	if err != nil {
+		return "", err
+	}
+
+	var total int64
+	for _, entry := range entries {
+		total += entry.Size
+	}
+
+	budget := "unlimited"
+	if g.scratch.MaxBytes() > 0 {
+		budget = formatBytes(g.scratch.MaxBytes())
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Scratch directory: %s\n", g.scratch.Dir()))
+	result.WriteString(fmt.Sprintf("  Used: %s of %s (%d entries)", formatBytes(total), budget, len(entries)))
+	for _, entry := range entries {
+		result.WriteString(fmt.Sprintf("\n    %s: %s", filepath.Base(entry.Path), formatBytes(entry.Size)))
+	}
+
+	return result.String(), nil
+}
+
+// findGitDir locates the git directory of a repository and reports whether
+// the repository is bare
+func findGitDir(repoPath string) (string, bool, error) {
+	gitDir := filepath.Join(repoPath, ".git")
+	if info, err := os.Stat(gitDir); err == nil && info.IsDir() {
+		return gitDir, false, nil
+	}
+
+	if info, err := os.Stat(filepath.Join(repoPath, "objects")); err == nil && info.IsDir() {
+		return repoPath, true, nil
+	}
+
+	return "", false, fmt.Errorf("%s is not a git repository", repoPath)
+}
+
+// fileSize returns the size of a file, or 0 if it does not exist
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// formatBytes renders a byte count using binary units
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,100 @@
+package gitops
+
+import (
+	"context"
+	"errors"
+	"os"
+	"regexp"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// Error codes classify the failures of operations, so that clients can
+// react to them without parsing messages
+const (
+	// ErrorCodeRepoNotFound means the repository path does not exist
+	ErrorCodeRepoNotFound = "repo_not_found"
+	// ErrorCodeNotARepo means the path exists but is not a git repository
+	ErrorCodeNotARepo = "not_a_repo"
+	// ErrorCodeDetachedHead means the operation needs a checked out branch
+	ErrorCodeDetachedHead = "detached_head"
+	// ErrorCodeMergeConflict means conflicts, unmerged files or an
+	// operation in progress stopped the operation
+	ErrorCodeMergeConflict = "merge_conflict"
+	// ErrorCodeAuthFailed means a remote rejected the credentials or
+	// needs some
+	ErrorCodeAuthFailed = "auth_failed"
+	// ErrorCodeNothingToCommit means there were no changes to record
+	ErrorCodeNothingToCommit = "nothing_to_commit"
+	// ErrorCodeNonFastForward means an update would lose commits of the
+	// branch it replaces
+	ErrorCodeNonFastForward = "non_fast_forward"
+	// ErrorCodeDubiousOwnership means the git executable refused a
+	// repository owned by another user
+	ErrorCodeDubiousOwnership = "dubious_ownership"
+	// ErrorCodeTimeout means the operation ran out of time
+	ErrorCodeTimeout = "timeout"
+	// ErrorCodeUnknown is every other failure
+	ErrorCodeUnknown = "unknown"
+)
+
+// errorPatterns recognize the failures reported as messages, by go-git or
+// by the git executable, in order
+var errorPatterns = []struct {
+	code    string
+	pattern *regexp.Regexp
+}{
+	{ErrorCodeNotARepo, regexp.MustCompile(`(?i)not a git repository|repository does not exist`)},
+	{ErrorCodeDetachedHead, regexp.MustCompile(`(?i)HEAD is detached|detached HEAD|not on any branch|not currently on a branch`)},
+	{ErrorCodeNothingToCommit, regexp.MustCompile(`(?i)nothing to commit|nothing added to commit|no changes added to commit`)},
+	{ErrorCodeNonFastForward, regexp.MustCompile(`(?i)non-fast-forward|\(fetch first\)|not possible to fast-forward|stale info`)},
+	{ErrorCodeAuthFailed, regexp.MustCompile(`(?i)authentication (required|failed)|authorization failed|unable to authenticate|permission denied \(publickey|could not read (username|password)|invalid username or password`)},
+	{ErrorCodeMergeConflict, regexp.MustCompile(`(?i)conflict|unmerged|could not apply|is in progress|needs merge`)},
+}
+
+// ErrorCode classifies an error returned by an operation on repoPath as
+// one of the ErrorCode constants
+func ErrorCode(err error, repoPath string) string {
+	if err == nil {
+		return ""
+	}
+
+	var dubious *DubiousOwnershipError
+	switch {
+	case errors.As(err, &dubious):
+		return ErrorCodeDubiousOwnership
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorCodeTimeout
+	case errors.Is(err, git.ErrRepositoryNotExists):
+		return repositoryErrorCode(repoPath)
+	case errors.Is(err, git.ErrEmptyCommit):
+		return ErrorCodeNothingToCommit
+	case errors.Is(err, git.ErrNonFastForwardUpdate):
+		return ErrorCodeNonFastForward
+	case errors.Is(err, transport.ErrAuthenticationRequired), errors.Is(err, transport.ErrAuthorizationFailed):
+		return ErrorCodeAuthFailed
+	}
+
+	message := err.Error()
+	for _, p := range errorPatterns {
+		if p.pattern.MatchString(message) {
+			if p.code == ErrorCodeNotARepo {
+				return repositoryErrorCode(repoPath)
+			}
+			return p.code
+		}
+	}
+	return ErrorCodeUnknown
+}
+
+// repositoryErrorCode tells a missing repository path from a path that is
+// not a repository
+func repositoryErrorCode(repoPath string) string {
+	if repoPath != "" {
+		if _, err := os.Stat(repoPath); os.IsNotExist(err) {
+			return ErrorCodeRepoNotFound
+		}
+	}
+	return ErrorCodeNotARepo
+}
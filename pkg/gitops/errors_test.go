@@ -0,0 +1,66 @@
+package gitops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+func TestErrorCode(t *testing.T) {
+	tempDir, _ := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+	commitFile(t, ops, tempDir, "a.txt", "a\n", "Add a")
+
+	missing := filepath.Join(tempDir, "missing")
+	_, err := ops.Status(missing, false)
+	if code := ErrorCode(err, missing); code != ErrorCodeRepoNotFound {
+		t.Errorf("Expected %s for a missing path, got %s (%v)", ErrorCodeRepoNotFound, code, err)
+	}
+
+	plain := t.TempDir()
+	_, err = ops.Status(plain, false)
+	if code := ErrorCode(err, plain); code != ErrorCodeNotARepo {
+		t.Errorf("Expected %s for a plain directory, got %s (%v)", ErrorCodeNotARepo, code, err)
+	}
+
+	_, err = ops.Commit(tempDir, "Nothing", CommitOptions{})
+	if code := ErrorCode(err, tempDir); code != ErrorCodeNothingToCommit {
+		t.Errorf("Expected %s, got %s (%v)", ErrorCodeNothingToCommit, code, err)
+	}
+
+	if _, err := ops.Checkout(tempDir, "HEAD~0", false, ""); err != nil {
+		t.Fatalf("Checkout failed: %v", err)
+	}
+	_, err = ops.SquashBranch(tempDir, "master", "Squash", CommitOptions{})
+	if code := ErrorCode(err, tempDir); code != ErrorCodeDetachedHead {
+		t.Errorf("Expected %s, got %s (%v)", ErrorCodeDetachedHead, code, err)
+	}
+
+	tests := []struct {
+		err  error
+		code string
+	}{
+		{nil, ""},
+		{&DubiousOwnershipError{Path: tempDir}, ErrorCodeDubiousOwnership},
+		{fmt.Errorf("failed to fetch: %w", context.DeadlineExceeded), ErrorCodeTimeout},
+		{fmt.Errorf("failed to push: %w", transport.ErrAuthenticationRequired), ErrorCodeAuthFailed},
+		{errors.New("fatal: Authentication failed for 'https://example.com/repo.git/'"), ErrorCodeAuthFailed},
+		{errors.New("failed to push: non-fast-forward update: refs/heads/master"), ErrorCodeNonFastForward},
+		{errors.New(" ! [rejected]        master -> master (fetch first)"), ErrorCodeNonFastForward},
+		{errors.New("a.txt is unmerged, resolve it first (see git_conflicts)"), ErrorCodeMergeConflict},
+		{errors.New("cannot rewrite history: a rebase is in progress"), ErrorCodeMergeConflict},
+		{errors.New("branch 'topic' is not fully merged; set force to true to delete it anyway"), ErrorCodeUnknown},
+	}
+	for _, tt := range tests {
+		if code := ErrorCode(tt.err, tempDir); code != tt.code {
+			t.Errorf("ErrorCode(%v) = %s, expected %s", tt.err, code, tt.code)
+		}
+	}
+}
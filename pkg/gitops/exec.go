@@ -0,0 +1,118 @@
+package gitops
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/pengcunfu/go-mcp-git/internal/tracing"
+)
+
+// credentialHelper answers git's credential requests from the environment
+// of the command, so secrets never appear in its arguments
+const credentialHelper = `!f() { test "$1" = get && echo "username=$MCP_GIT_AUTH_USERNAME" && echo "password=$MCP_GIT_AUTH_PASSWORD"; }; f`
+
+// SetSafeDirectories marks directories as safe for the git executable even
+// when they are owned by another user, as is common with bind mounts in
+// containers
+func (g *Operations) SetSafeDirectories(dirs []string) {
+	g.safeDirectories = dirs
+}
+
+// SetNonInteractive prevents the git executable from prompting on the
+// terminal, e.g. for credentials
+func (g *Operations) SetNonInteractive(nonInteractive bool) {
+	g.nonInteractive = nonInteractive
+}
+
+// gitCommand builds an invocation of the git executable in repoPath,
+// applying the safe directories, credentials and prompting settings
+func (g *Operations) gitCommand(repoPath string, args ...string) *exec.Cmd {
+	return g.gitCommandAuth(repoPath, g.auth, args...)
+}
+
+// gitCommandAuth builds an invocation of the git executable like
+// gitCommand, with the credentials of auth in place of the server ones
+func (g *Operations) gitCommandAuth(repoPath string, auth transport.AuthMethod, args ...string) *exec.Cmd {
+	var prefix []string
+	for _, dir := range g.safeDirectories {
+		prefix = append(prefix, "-c", "safe.directory="+dir)
+	}
+
+	env := os.Environ()
+	if basic, ok := auth.(*http.BasicAuth); ok {
+		prefix = append(prefix, "-c", "credential.helper=", "-c", "credential.helper="+credentialHelper)
+		env = append(env, "MCP_GIT_AUTH_USERNAME="+basic.Username, "MCP_GIT_AUTH_PASSWORD="+basic.Password)
+	}
+	if command := g.sshCommand(); command != "" {
+		env = append(env, "GIT_SSH_COMMAND="+command)
+	}
+	if g.nonInteractive {
+		env = append(env, "GIT_TERMINAL_PROMPT=0", "GIT_ASKPASS=", "SSH_ASKPASS=")
+	}
+
+	cmd := exec.CommandContext(g.context(), "git", append(prefix, args...)...)
+	cmd.Dir = repoPath
+	cmd.Env = env
+	return cmd
+}
+
+// dubiousOwnership matches the error git prints for repositories owned by
+// another user that are not listed in safe.directory
+var dubiousOwnership = regexp.MustCompile(`detected dubious ownership in repository at '([^']+)'`)
+
+// DubiousOwnershipError is returned when the git executable refuses to work
+// in a repository owned by another user
+type DubiousOwnershipError struct {
+	Path string
+}
+
+func (e *DubiousOwnershipError) Error() string {
+	return fmt.Sprintf("git refuses to operate in %s because it is owned by another user (dubious ownership). "+
+		"Start the server with --safe-directory %s (or --container to trust the configured repositories), "+
+		"or run: git config --global --add safe.directory %s", e.Path, e.Path, e.Path)
+}
+
+// runGit runs the git executable in repoPath and returns its combined output.
+// Dubious ownership failures are reported as *DubiousOwnershipError.
+func (g *Operations) runGit(repoPath string, args ...string) ([]byte, error) {
+	return g.runCommand(g.gitCommand(repoPath, args...))
+}
+
+// runCommand runs an invocation of the git executable like runGit, as a
+// span of the trace of the operations
+func (g *Operations) runCommand(cmd *exec.Cmd) (output []byte, err error) {
+	command := gitSubcommand(cmd.Args[1:])
+	_, span := tracing.Start(g.context(), strings.TrimSpace("git "+command), tracing.KindClient, tracing.String("git.command", command))
+	defer func() { span.End(err) }()
+
+	output, err = cmd.CombinedOutput()
+	if err != nil {
+		if m := dubiousOwnership.FindSubmatch(output); m != nil {
+			return output, &DubiousOwnershipError{Path: string(m[1])}
+		}
+		return output, err
+	}
+	return output, nil
+}
+
+// gitSubcommand returns the subcommand of the arguments of the git
+// executable, after its options. The other arguments are left out of
+// traces, they may hold URLs or messages.
+func gitSubcommand(args []string) string {
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "-c", "-C":
+			i++
+		default:
+			if !strings.HasPrefix(args[i], "-") {
+				return args[i]
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,47 @@
+package gitops
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOperations_DubiousOwnership(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not available")
+	}
+	if os.Geteuid() != 0 {
+		t.Skip("changing repository ownership requires root")
+	}
+
+	tempDir, _ := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	err := filepath.Walk(tempDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Lchown(path, 65534, 65534)
+	})
+	if err != nil {
+		t.Fatalf("Failed to change ownership: %v", err)
+	}
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	_, err = ops.RawCommand(tempDir, "git status")
+	dubious, ok := err.(*DubiousOwnershipError)
+	if !ok {
+		t.Fatalf("Expected DubiousOwnershipError, got: %v", err)
+	}
+	if !strings.Contains(dubious.Error(), "--safe-directory") {
+		t.Errorf("Expected remediation in error, got: %s", dubious.Error())
+	}
+
+	ops.SetSafeDirectories([]string{dubious.Path})
+	if _, err := ops.RawCommand(tempDir, "git status"); err != nil {
+		t.Errorf("Expected safe directory to be trusted, got: %v", err)
+	}
+}
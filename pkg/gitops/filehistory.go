@@ -0,0 +1,205 @@
+package gitops
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// DefaultFileHistoryMaxCount is the number of commits reported when
+// FileHistoryOptions.MaxCount is not set
+const DefaultFileHistoryMaxCount = 20
+
+// FileHistoryOptions holds the parameters of a file history
+type FileHistoryOptions struct {
+	// Path is the file whose history is listed, as named at the start of
+	// the history
+	Path string
+	// Range is a revision (default HEAD) or a range A..B / A...B
+	Range string
+	// Follow continues the history across renames like git log --follow
+	Follow bool
+	// Patch includes the diff of the file in every commit
+	Patch        bool
+	ContextLines int
+	// MaxCount caps the number of commits reported
+	MaxCount int
+}
+
+// FileHistory lists the commits changing a file, newest first, with the
+// status of the file in each commit. Merge commits are listed only when the
+// file differs from every parent.
+func (g *Operations) FileHistory(repoPath string, opts FileHistoryOptions) (string, error) {
+	path := strings.Trim(filepath.ToSlash(filepath.Clean(opts.Path)), "/")
+	if opts.Path == "" || path == "." {
+		return "", fmt.Errorf("path is required")
+	}
+
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	commitIter, err := logIterator(repo, LogOptions{Range: opts.Range})
+	if err != nil {
+		return "", err
+	}
+	defer commitIter.Close()
+
+	maxCount := opts.MaxCount
+	if maxCount <= 0 {
+		maxCount = DefaultFileHistoryMaxCount
+	}
+
+	var entries []string
+	err = commitIter.ForEach(func(commit *object.Commit) error {
+		patch, err := fileChange(commit, path, opts.Follow)
+		if err != nil || patch == nil {
+			return err
+		}
+
+		entry := fmt.Sprintf("%s %s %s %s\n    %s",
+			commit.Hash.String()[:7],
+			commit.Author.When.Format(time.RFC3339),
+			commit.Author.Name,
+			commitSubject(commit),
+			summarizeFilePatches([]fdiff.FilePatch{patch}))
+		if opts.Patch {
+			rendered, err := renderFilePatches([]fdiff.FilePatch{patch}, DiffOptions{ContextLines: opts.ContextLines})
+			if err != nil {
+				return err
+			}
+			entry += "\n\n" + strings.TrimRight(rendered, "\n") + "\n"
+		}
+		entries = append(entries, entry)
+
+		// Older commits know the file by its name before the rename
+		if patch.from != nil {
+			path = patch.from.path
+		}
+		// One more commit tells whether the result was truncated
+		return limitReached(len(entries), maxCount+1)
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to walk history: %w", err)
+	}
+
+	if len(entries) == 0 {
+		return fmt.Sprintf("No commits changing %s", opts.Path), nil
+	}
+
+	truncated := len(entries) > maxCount
+	if truncated {
+		entries = entries[:maxCount]
+	}
+	result := strings.Join(entries, "\n")
+	if truncated {
+		result += fmt.Sprintf("\n... (stopped after %d commits)", maxCount)
+	}
+	return result, nil
+}
+
+// fileChange returns the patch of a file in a commit against its first
+// parent, or nil if the commit does not change the file. With follow, an
+// added file is matched against the files the commit deletes to detect a
+// rename.
+func fileChange(commit *object.Commit, path string, follow bool) (*filePatch, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree of %s: %w", commit.Hash.String()[:7], err)
+	}
+	entry := fileEntry(tree, path)
+
+	var parentTree *object.Tree
+	for i := 0; i < commit.NumParents(); i++ {
+		parent, err := commit.Parent(i)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get parent of %s: %w", commit.Hash.String()[:7], err)
+		}
+		ptree, err := parent.Tree()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tree of %s: %w", parent.Hash.String()[:7], err)
+		}
+		// A merge taking the file from any parent does not change it
+		if sameEntry(fileEntry(ptree, path), entry) {
+			return nil, nil
+		}
+		if i == 0 {
+			parentTree = ptree
+		}
+	}
+	if entry == nil && parentTree == nil {
+		return nil, nil
+	}
+
+	from, err := treePatchFile(parentTree, path)
+	if err != nil {
+		return nil, err
+	}
+	to, err := treePatchFile(tree, path)
+	if err != nil {
+		return nil, err
+	}
+
+	if from == nil && follow && parentTree != nil {
+		rename, err := findRename(parentTree, tree, to)
+		if err != nil || rename != nil {
+			return rename, err
+		}
+	}
+
+	return newFilePatch(from, to, false), nil
+}
+
+// findRename looks for the file an added file was renamed from among the
+// files deleted between two trees
+func findRename(parentTree, tree *object.Tree, added *patchFile) (*filePatch, error) {
+	changes, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff trees: %w", err)
+	}
+
+	pairs := []filePair{{to: added}}
+	for _, change := range changes {
+		if change.To.Name != "" {
+			continue
+		}
+		deleted, err := treePatchFile(parentTree, change.From.Name)
+		if err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, filePair{from: deleted})
+	}
+
+	_, renames := detectRenames(pairs, DefaultRenameThreshold)
+	if len(renames) == 0 {
+		return nil, nil
+	}
+	rename := newFilePatch(renames[0].from, renames[0].to, false)
+	rename.similarity = renames[0].similarity
+	return rename, nil
+}
+
+// fileEntry returns the tree entry of a file, nil if the tree has no such
+// file
+func fileEntry(tree *object.Tree, path string) *object.TreeEntry {
+	entry, err := tree.FindEntry(path)
+	if err != nil || !entry.Mode.IsFile() {
+		return nil
+	}
+	return entry
+}
+
+// sameEntry reports whether two tree entries, either of which may be nil,
+// have the same content and mode
+func sameEntry(a, b *object.TreeEntry) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Hash == b.Hash && a.Mode == b.Mode
+}
@@ -0,0 +1,78 @@
+package gitops
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOperations_FileHistory(t *testing.T) {
+	tempDir, repo := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	content := "line one\nline two\nline three\nline four\n"
+	commitFile(t, ops, tempDir, "old.txt", content, "Add old")
+	commitFile(t, ops, tempDir, "other.txt", "unrelated\n", "Add other")
+	commitFile(t, ops, tempDir, "old.txt", content+"line five\n", "Extend old")
+
+	// Rename old.txt to new.txt with a small edit
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+	if err := os.Remove(filepath.Join(tempDir, "old.txt")); err != nil {
+		t.Fatalf("Failed to remove: %v", err)
+	}
+	if _, err := worktree.Remove("old.txt"); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	commitFile(t, ops, tempDir, "new.txt", content+"line 5\n", "Rename old to new")
+
+	result, err := ops.FileHistory(tempDir, FileHistoryOptions{Path: "new.txt", Follow: true})
+	if err != nil {
+		t.Fatalf("FileHistory failed: %v", err)
+	}
+	lines := strings.Split(result, "\n")
+	if len(lines) != 6 {
+		t.Fatalf("Expected 3 commits, got: %s", result)
+	}
+	for i, want := range []string{"Rename old to new", "", "Extend old", "    M\told.txt", "Add old", "    A\told.txt"} {
+		if want != "" && !strings.HasSuffix(lines[i], want) {
+			t.Errorf("Line %d: expected %q, got: %s", i, want, result)
+		}
+	}
+	if !strings.HasPrefix(lines[1], "    R0") || !strings.HasSuffix(lines[1], "\told.txt\tnew.txt") {
+		t.Errorf("Expected a rename, got: %s", lines[1])
+	}
+
+	// Without follow the history stops at the rename
+	result, err = ops.FileHistory(tempDir, FileHistoryOptions{Path: "new.txt"})
+	if err != nil {
+		t.Fatalf("FileHistory failed: %v", err)
+	}
+	if strings.Count(result, "\n    ") != 1 || !strings.Contains(result, "    A\tnew.txt") {
+		t.Errorf("Expected only the addition, got: %s", result)
+	}
+
+	result, err = ops.FileHistory(tempDir, FileHistoryOptions{Path: "new.txt", Follow: true, Patch: true, MaxCount: 2})
+	if err != nil {
+		t.Fatalf("FileHistory failed: %v", err)
+	}
+	if !strings.Contains(result, "+line five") || !strings.Contains(result, "+line 5") || strings.Contains(result, "unrelated") {
+		t.Errorf("Expected the patches of the file, got: %s", result)
+	}
+	if !strings.HasSuffix(result, "(stopped after 2 commits)") {
+		t.Errorf("Expected truncated result, got: %s", result)
+	}
+
+	result, err = ops.FileHistory(tempDir, FileHistoryOptions{Path: "missing.txt"})
+	if err != nil {
+		t.Fatalf("FileHistory failed: %v", err)
+	}
+	if result != "No commits changing missing.txt" {
+		t.Errorf("Unexpected result: %s", result)
+	}
+}
@@ -0,0 +1,143 @@
+package gitops
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// DefaultGraphMaxCount is the number of commits exported when
+// GraphExportOptions.MaxCount is not set
+const DefaultGraphMaxCount = 200
+
+// GraphExportOptions selects the commits of a graph export
+type GraphExportOptions struct {
+	// Range is a revision or revision range (A..B, A...B), HEAD by default
+	Range string
+	// MaxCount caps the number of exported commits
+	MaxCount int
+}
+
+// CommitGraph is the JSON document produced by ExportGraph
+type CommitGraph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+	// Boundary lists parents of exported commits that were left out
+	Boundary []string `json:"boundary"`
+	// Truncated is set when MaxCount stopped the export
+	Truncated bool `json:"truncated"`
+}
+
+// GraphNode is a commit of the exported graph
+type GraphNode struct {
+	Hash      string    `json:"hash"`
+	Short     string    `json:"short"`
+	Parents   []string  `json:"parents"`
+	Author    string    `json:"author"`
+	Email     string    `json:"email"`
+	Date      time.Time `json:"date"`
+	Committed time.Time `json:"committed"`
+	Subject   string    `json:"subject"`
+	Refs      []string  `json:"refs,omitempty"`
+}
+
+// GraphEdge links a commit to one of its parents. Index is the position of
+// the parent, 0 being the first parent.
+type GraphEdge struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Index int    `json:"index"`
+}
+
+// ExportGraph exports the commits of a revision range as JSON nodes and
+// parent edges, labelled with the references pointing at them. Commits are
+// ordered newest first so that children precede their parents.
+func (g *Operations) ExportGraph(repoPath string, opts GraphExportOptions) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	graph, err := exportGraph(repo, opts)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.MarshalIndent(graph, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode graph: %w", err)
+	}
+	return string(data), nil
+}
+
+func exportGraph(repo *git.Repository, opts GraphExportOptions) (*CommitGraph, error) {
+	maxCount := opts.MaxCount
+	if maxCount <= 0 {
+		maxCount = DefaultGraphMaxCount
+	}
+
+	decorations, err := refDecorations(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	iter, err := logIterator(repo, LogOptions{Range: opts.Range, Graph: true})
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	graph := &CommitGraph{Nodes: []GraphNode{}, Edges: []GraphEdge{}, Boundary: []string{}}
+	err = iter.ForEach(func(c *object.Commit) error {
+		if len(graph.Nodes) >= maxCount {
+			graph.Truncated = true
+			return storer.ErrStop
+		}
+
+		parents := make([]string, 0, len(c.ParentHashes))
+		for _, parent := range c.ParentHashes {
+			parents = append(parents, parent.String())
+		}
+		subject, _, _ := strings.Cut(strings.TrimSpace(c.Message), "\n")
+
+		graph.Nodes = append(graph.Nodes, GraphNode{
+			Hash:      c.Hash.String(),
+			Short:     c.Hash.String()[:7],
+			Parents:   parents,
+			Author:    c.Author.Name,
+			Email:     c.Author.Email,
+			Date:      c.Author.When,
+			Committed: c.Committer.When,
+			Subject:   subject,
+			Refs:      decorations[c.Hash],
+		})
+		return nil
+	})
+	if err != nil && err != storer.ErrStop {
+		return nil, fmt.Errorf("failed to iterate commits: %w", err)
+	}
+
+	exported := make(map[string]bool, len(graph.Nodes))
+	for _, node := range graph.Nodes {
+		exported[node.Hash] = true
+	}
+	boundary := make(map[string]bool)
+	for _, node := range graph.Nodes {
+		for i, parent := range node.Parents {
+			if exported[parent] {
+				graph.Edges = append(graph.Edges, GraphEdge{From: node.Hash, To: parent, Index: i})
+			} else if !boundary[parent] {
+				boundary[parent] = true
+				graph.Boundary = append(graph.Boundary, parent)
+			}
+		}
+	}
+
+	return graph, nil
+}
@@ -0,0 +1,71 @@
+package gitops
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestOperations_ExportGraph(t *testing.T) {
+	tempDir, repo := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("Failed to get HEAD: %v", err)
+	}
+	root := head.Hash()
+
+	when := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	a := commitAt(t, repo, "a.txt", "Add a", when.Add(time.Hour))
+	side := commitAt(t, repo, "side.txt", "Side work", when.Add(2*time.Hour), root)
+	merge := commitAt(t, repo, "merge.txt", "Merge side", when.Add(3*time.Hour), a, side)
+
+	result, err := ops.ExportGraph(tempDir, GraphExportOptions{})
+	if err != nil {
+		t.Fatalf("ExportGraph failed: %v", err)
+	}
+
+	var graph CommitGraph
+	if err := json.Unmarshal([]byte(result), &graph); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, result)
+	}
+
+	if len(graph.Nodes) != 4 || graph.Nodes[0].Hash != merge.String() {
+		t.Fatalf("Expected 4 nodes starting with the merge, got: %s", result)
+	}
+	if graph.Nodes[0].Subject != "Merge side" || len(graph.Nodes[0].Refs) == 0 || graph.Nodes[0].Refs[0] != "HEAD -> master" {
+		t.Errorf("Unexpected merge node: %+v", graph.Nodes[0])
+	}
+	if len(graph.Edges) != 4 || len(graph.Boundary) != 0 || graph.Truncated {
+		t.Errorf("Expected 4 edges and no boundary, got: %s", result)
+	}
+
+	// Limiting the range leaves the root as a boundary parent
+	result, err = ops.ExportGraph(tempDir, GraphExportOptions{Range: root.String() + "..HEAD"})
+	if err != nil {
+		t.Fatalf("ExportGraph failed: %v", err)
+	}
+	graph = CommitGraph{}
+	if err := json.Unmarshal([]byte(result), &graph); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if len(graph.Nodes) != 3 || len(graph.Boundary) != 1 || graph.Boundary[0] != root.String() {
+		t.Errorf("Expected 3 nodes with the root as boundary, got: %s", result)
+	}
+
+	result, err = ops.ExportGraph(tempDir, GraphExportOptions{MaxCount: 2})
+	if err != nil {
+		t.Fatalf("ExportGraph failed: %v", err)
+	}
+	graph = CommitGraph{}
+	if err := json.Unmarshal([]byte(result), &graph); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if len(graph.Nodes) != 2 || !graph.Truncated {
+		t.Errorf("Expected 2 nodes and truncation, got: %s", result)
+	}
+}
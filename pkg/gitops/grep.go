@@ -0,0 +1,154 @@
+package gitops
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// DefaultGrepMaxResults is the number of matches reported per repository
+// when GrepOptions.MaxResults is not set
+const DefaultGrepMaxResults = 100
+
+// GrepOptions holds the parameters of a content search
+type GrepOptions struct {
+	// Pattern is the regular expression searched for
+	Pattern string
+	// Revision whose files are searched (default HEAD)
+	Revision string
+	// Paths limits the search to these files or directories
+	Paths      []string
+	IgnoreCase bool
+	// MaxResults caps the number of matches per repository
+	MaxResults int
+}
+
+// Grep searches the files of a revision for lines matching a regular
+// expression and returns them as path:line:content
+func (g *Operations) Grep(repoPath string, opts GrepOptions) (string, error) {
+	matches, truncated, err := g.grepRepo(repoPath, opts)
+	if err != nil {
+		return "", err
+	}
+	if len(matches) == 0 {
+		return fmt.Sprintf("No matches for '%s'", opts.Pattern), nil
+	}
+
+	result := strings.Join(matches, "\n")
+	if truncated {
+		result += fmt.Sprintf("\n... (stopped after %d matches)", len(matches))
+	}
+	return result, nil
+}
+
+// MultiGrep searches several repositories concurrently and groups the
+// matches by repository
+func (g *Operations) MultiGrep(repoPaths []string, opts GrepOptions) string {
+	sections := forEachRepo(repoPaths, func(repoPath string) (string, error) {
+		matches, truncated, err := g.grepRepo(repoPath, opts)
+		if err != nil {
+			return "", err
+		}
+
+		var section strings.Builder
+		section.WriteString(fmt.Sprintf("== %s (%d matches) ==", repoPath, len(matches)))
+		for _, match := range matches {
+			section.WriteString("\n" + match)
+		}
+		if truncated {
+			section.WriteString(fmt.Sprintf("\n... (stopped after %d matches)", len(matches)))
+		}
+		return section.String(), nil
+	})
+
+	return strings.Join(sections, "\n\n")
+}
+
+// grepRepo runs a search in a single repository. It reports whether the
+// matches were truncated to opts.MaxResults.
+func (g *Operations) grepRepo(repoPath string, opts GrepOptions) ([]string, bool, error) {
+	if opts.Pattern == "" {
+		return nil, false, fmt.Errorf("pattern is required")
+	}
+
+	pattern := opts.Pattern
+	if opts.IgnoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	revision := opts.Revision
+	if revision == "" {
+		revision = "HEAD"
+	}
+	hash, err := resolveRevision(repo, revision)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to resolve revision '%s': %w", revision, err)
+	}
+
+	grepOptions := &git.GrepOptions{
+		Patterns:   []*regexp.Regexp{re},
+		CommitHash: *hash,
+	}
+	grepOptions.PathSpecs = pathSpecs(opts.Paths)
+
+	results, err := repo.Grep(grepOptions)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to search: %w", err)
+	}
+
+	maxResults := opts.MaxResults
+	if maxResults <= 0 {
+		maxResults = DefaultGrepMaxResults
+	}
+	truncated := len(results) > maxResults
+	if truncated {
+		results = results[:maxResults]
+	}
+
+	matches := make([]string, len(results))
+	for i, r := range results {
+		matches[i] = fmt.Sprintf("%s:%d:%s", r.FileName, r.LineNumber, r.Content)
+	}
+	return matches, truncated, nil
+}
+
+// pathSpecs turns file or directory paths into patterns matching them and
+// everything below them. Paths naming the repository root are dropped.
+func pathSpecs(paths []string) []*regexp.Regexp {
+	var specs []*regexp.Regexp
+	for _, path := range paths {
+		path = strings.Trim(filepath.ToSlash(filepath.Clean(path)), "/")
+		if path == "." || path == "" {
+			continue
+		}
+		specs = append(specs, regexp.MustCompile("^"+regexp.QuoteMeta(path)+"(/|$)"))
+	}
+	return specs
+}
+
+// matchesPathSpecs reports whether path matches any spec, an empty set of
+// specs matching every path
+func matchesPathSpecs(specs []*regexp.Regexp, path string) bool {
+	if len(specs) == 0 {
+		return true
+	}
+	for _, spec := range specs {
+		if spec.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
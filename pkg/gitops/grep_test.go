@@ -0,0 +1,71 @@
+package gitops
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOperations_Grep(t *testing.T) {
+	tempDir, _ := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	if err := os.MkdirAll(filepath.Join(tempDir, "docs"), 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	commitFile(t, ops, tempDir, "docs/guide.md", "Intro\nUse the Diff tool\n", "Add guide")
+	commitFile(t, ops, tempDir, "main.go", "package main\n// diff helpers\n", "Add main")
+
+	result, err := ops.Grep(tempDir, GrepOptions{Pattern: "diff", IgnoreCase: true})
+	if err != nil {
+		t.Fatalf("Grep failed: %v", err)
+	}
+	if result != "docs/guide.md:2:Use the Diff tool\nmain.go:2:// diff helpers" {
+		t.Errorf("Unexpected result: %s", result)
+	}
+
+	result, err = ops.Grep(tempDir, GrepOptions{Pattern: "diff", IgnoreCase: true, Paths: []string{"docs/"}})
+	if err != nil {
+		t.Fatalf("Grep failed: %v", err)
+	}
+	if result != "docs/guide.md:2:Use the Diff tool" {
+		t.Errorf("Unexpected result: %s", result)
+	}
+
+	// Older revisions do not have the files yet
+	result, err = ops.Grep(tempDir, GrepOptions{Pattern: "diff", Revision: "HEAD~2"})
+	if err != nil {
+		t.Fatalf("Grep failed: %v", err)
+	}
+	if !strings.HasPrefix(result, "No matches") {
+		t.Errorf("Unexpected result: %s", result)
+	}
+
+	result, err = ops.Grep(tempDir, GrepOptions{Pattern: "i", MaxResults: 1})
+	if err != nil {
+		t.Fatalf("Grep failed: %v", err)
+	}
+	if !strings.HasSuffix(result, "(stopped after 1 matches)") {
+		t.Errorf("Expected truncated result, got: %s", result)
+	}
+}
+
+func TestOperations_MultiGrep(t *testing.T) {
+	first, _ := createTestRepo(t)
+	defer os.RemoveAll(first)
+	second, _ := createTestRepo(t)
+	defer os.RemoveAll(second)
+
+	ops := NewOperations("Test User", "test@example.com")
+	commitFile(t, ops, second, "notes.txt", "test notes\n", "Add notes")
+
+	result := ops.MultiGrep([]string{first, second}, GrepOptions{Pattern: "test"})
+	expected := "== " + first + " (1 matches) ==\ntest.txt:1:test content\n\n" +
+		"== " + second + " (2 matches) ==\nnotes.txt:1:test notes\ntest.txt:1:test content"
+	if result != expected {
+		t.Errorf("Unexpected result:\n%s\nexpected:\n%s", result, expected)
+	}
+}
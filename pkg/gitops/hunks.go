@@ -0,0 +1,278 @@
+package gitops
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// lineOp is one line of a line diff
+type lineOp struct {
+	op   diffmatchpatch.Operation
+	text string
+}
+
+// hunk is a group of changed lines of a file with their context, like a
+// hunk of git add -p. start and end delimit its lines in the line diff.
+type hunk struct {
+	id         string
+	start, end int
+	header     string
+}
+
+// fileHunks holds the unstaged changes of a file split into hunks
+type fileHunks struct {
+	path     string
+	ops      []lineOp
+	hunks    []hunk
+	staged   *patchFile
+	worktree *patchFile
+}
+
+// ListHunks splits the unstaged changes of a file into hunks, each with an
+// ID that stays the same as long as the hunk itself does not change (in
+// particular when other hunks of the file are staged)
+func (g *Operations) ListHunks(repoPath, path string) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	fh, err := unstagedHunks(repo, path)
+	if err != nil {
+		return "", err
+	}
+	if len(fh.hunks) == 0 {
+		return fmt.Sprintf("No unstaged changes in %s", fh.path), nil
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("%s: %d hunk(s)\n", fh.path, len(fh.hunks)))
+	for _, h := range fh.hunks {
+		result.WriteString(fmt.Sprintf("\nHunk %s %s\n", h.id, h.header))
+		for _, line := range fh.ops[h.start:h.end] {
+			prefix := " "
+			switch line.op {
+			case diffmatchpatch.DiffInsert:
+				prefix = "+"
+			case diffmatchpatch.DiffDelete:
+				prefix = "-"
+			}
+			result.WriteString(prefix + strings.TrimSuffix(line.text, "\n") + "\n")
+			if !strings.HasSuffix(line.text, "\n") {
+				result.WriteString("\\ No newline at end of file\n")
+			}
+		}
+	}
+
+	return strings.TrimSuffix(result.String(), "\n"), nil
+}
+
+// StageHunks stages the hunks of a file selected by their IDs, as listed by
+// ListHunks, leaving the other changes of the file unstaged
+func (g *Operations) StageHunks(repoPath, path string, ids []string) (string, error) {
+	if len(ids) == 0 {
+		return "", fmt.Errorf("at least one hunk ID is required")
+	}
+
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	fh, err := unstagedHunks(repo, path)
+	if err != nil {
+		return "", err
+	}
+
+	selected := make([]bool, len(fh.ops))
+	byID := make(map[string]hunk, len(fh.hunks))
+	for _, h := range fh.hunks {
+		byID[h.id] = h
+	}
+	for _, id := range ids {
+		h, ok := byID[id]
+		if !ok {
+			return "", fmt.Errorf("unknown hunk %s in %s (the changes may have moved on, list the hunks again)", id, fh.path)
+		}
+		for i := h.start; i < h.end; i++ {
+			selected[i] = true
+		}
+	}
+
+	// Selected hunks take the working tree lines, the others keep the
+	// staged ones
+	var content strings.Builder
+	for i, line := range fh.ops {
+		switch {
+		case line.op == diffmatchpatch.DiffEqual,
+			line.op == diffmatchpatch.DiffInsert && selected[i],
+			line.op == diffmatchpatch.DiffDelete && !selected[i]:
+			content.WriteString(line.text)
+		}
+	}
+
+	hash, err := writeBlobObject(repo, []byte(content.String()))
+	if err != nil {
+		return "", err
+	}
+
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return "", fmt.Errorf("failed to read index: %w", err)
+	}
+	entry, err := idx.Entry(fh.path)
+	if err != nil {
+		entry = idx.Add(fh.path)
+		entry.Mode = fh.worktree.mode
+	}
+	entry.Hash = hash
+	entry.Size = uint32(content.Len())
+	// The staged content differs from the file on disk, so its stat data
+	// must not let the file look unmodified
+	entry.ModifiedAt = time.Time{}
+	if err := repo.Storer.SetIndex(idx); err != nil {
+		return "", fmt.Errorf("failed to write index: %w", err)
+	}
+
+	staged := make(map[string]bool)
+	for _, id := range ids {
+		staged[id] = true
+	}
+	return fmt.Sprintf("Staged %d of %d hunk(s) of %s", len(staged), len(fh.hunks), fh.path), nil
+}
+
+// unstagedHunks diffs the staged and the working tree version of a file and
+// splits the changes into hunks with DefaultContextLines lines of context
+func unstagedHunks(repo *git.Repository, path string) (*fileHunks, error) {
+	name := strings.Trim(filepath.ToSlash(filepath.Clean(path)), "/")
+	if path == "" || name == "." {
+		return nil, fmt.Errorf("path is required")
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	conflicts, err := indexConflicts(repo)
+	if err != nil {
+		return nil, err
+	}
+	if conflicts[name] != nil {
+		return nil, fmt.Errorf("%s is unmerged, resolve it first (see git_conflicts)", name)
+	}
+
+	fh := &fileHunks{path: name}
+	fh.worktree, err = readWorktreeFile(worktree.Filesystem.Root(), name)
+	if err != nil {
+		return nil, err
+	}
+	if fh.worktree == nil {
+		return nil, fmt.Errorf("%s does not exist in the working tree (use git_add to stage a deletion)", name)
+	}
+
+	entries, err := indexEntries(repo)
+	if err != nil {
+		return nil, err
+	}
+	var stagedContent []byte
+	if entry, ok := entries[name]; ok {
+		if fh.staged, err = indexPatchFile(repo, entry); err != nil {
+			return nil, err
+		}
+		stagedContent = fh.staged.content
+	}
+	if isBinary(stagedContent) || isBinary(fh.worktree.content) {
+		return nil, fmt.Errorf("%s is a binary file, stage it with git_add", name)
+	}
+
+	for _, d := range diff.Do(string(stagedContent), string(fh.worktree.content)) {
+		for _, line := range splitLines(d.Text) {
+			fh.ops = append(fh.ops, lineOp{op: d.Type, text: line})
+		}
+	}
+	fh.hunks = groupHunks(name, fh.ops, DefaultContextLines)
+	return fh, nil
+}
+
+// groupHunks groups the changed lines of a line diff into hunks. Changes
+// separated by at most 2*context unchanged lines share a hunk, as in a
+// unified diff.
+func groupHunks(path string, ops []lineOp, context int) []hunk {
+	var hunks []hunk
+	seen := make(map[string]int)
+	for i := 0; i < len(ops); {
+		if ops[i].op == diffmatchpatch.DiffEqual {
+			i++
+			continue
+		}
+
+		// Extend the hunk over changes close enough to the previous one
+		last := i
+		for j := i + 1; j < len(ops) && j <= last+2*context+1; j++ {
+			if ops[j].op != diffmatchpatch.DiffEqual {
+				last = j
+			}
+		}
+
+		h := hunk{start: max(i-context, 0), end: min(last+1+context, len(ops))}
+		h.header = hunkHeader(ops, h.start, h.end)
+
+		sum := sha1.New()
+		sum.Write([]byte(path + "\n"))
+		for _, line := range ops[h.start:h.end] {
+			sum.Write([]byte(fmt.Sprintf("%d %s", line.op, line.text)))
+		}
+		h.id = hex.EncodeToString(sum.Sum(nil))[:8]
+		// Identical hunks are told apart by their order
+		if seen[h.id]++; seen[h.id] > 1 {
+			h.id = fmt.Sprintf("%s-%d", h.id, seen[h.id])
+		}
+
+		hunks = append(hunks, h)
+		i = last + 1
+	}
+	return hunks
+}
+
+// hunkHeader formats the @@ -a,b +c,d @@ line of the diff lines start to end
+func hunkHeader(ops []lineOp, start, end int) string {
+	oldStart, newStart := 1, 1
+	for _, line := range ops[:start] {
+		if line.op != diffmatchpatch.DiffInsert {
+			oldStart++
+		}
+		if line.op != diffmatchpatch.DiffDelete {
+			newStart++
+		}
+	}
+
+	var oldCount, newCount int
+	for _, line := range ops[start:end] {
+		if line.op != diffmatchpatch.DiffInsert {
+			oldCount++
+		}
+		if line.op != diffmatchpatch.DiffDelete {
+			newCount++
+		}
+	}
+
+	// An empty side is numbered after the line it follows
+	if oldCount == 0 {
+		oldStart--
+	}
+	if newCount == 0 {
+		newStart--
+	}
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@", oldStart, oldCount, newStart, newCount)
+}
@@ -0,0 +1,124 @@
+package gitops
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestOperations_StageHunks(t *testing.T) {
+	tempDir, repo := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	var lines []string
+	for i := 1; i <= 20; i++ {
+		lines = append(lines, "line "+string(rune('a'+i-1)))
+	}
+	commitFile(t, ops, tempDir, "file.txt", strings.Join(lines, "\n")+"\n", "Add file")
+
+	// One change at the top and one at the bottom make two hunks
+	changed := append([]string{}, lines...)
+	changed[1] = "top change"
+	changed[18] = "bottom change"
+	if err := os.WriteFile(filepath.Join(tempDir, "file.txt"), []byte(strings.Join(changed, "\n")+"\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	listed, err := ops.ListHunks(tempDir, "file.txt")
+	if err != nil {
+		t.Fatalf("ListHunks failed: %v", err)
+	}
+	ids := regexp.MustCompile(`(?m)^Hunk (\S+) (@@ .* @@)$`).FindAllStringSubmatch(listed, -1)
+	if !strings.HasPrefix(listed, "file.txt: 2 hunk(s)") || len(ids) != 2 {
+		t.Fatalf("Expected two hunks, got: %s", listed)
+	}
+	if ids[0][2] != "@@ -1,5 +1,5 @@" || ids[1][2] != "@@ -16,5 +16,5 @@" {
+		t.Errorf("Unexpected hunk headers: %s", listed)
+	}
+	if !strings.Contains(listed, "-line b\n+top change\n") {
+		t.Errorf("Expected the top change in the hunks, got: %s", listed)
+	}
+
+	if _, err := ops.StageHunks(tempDir, "file.txt", []string{"deadbeef"}); err == nil {
+		t.Error("Expected error for an unknown hunk")
+	}
+
+	result, err := ops.StageHunks(tempDir, "file.txt", []string{ids[1][1]})
+	if err != nil {
+		t.Fatalf("StageHunks failed: %v", err)
+	}
+	if result != "Staged 1 of 2 hunk(s) of file.txt" {
+		t.Errorf("Unexpected result: %s", result)
+	}
+
+	// Only the bottom change is staged
+	entries, err := indexEntries(repo)
+	if err != nil {
+		t.Fatalf("Failed to read index: %v", err)
+	}
+	staged, err := indexPatchFile(repo, entries["file.txt"])
+	if err != nil {
+		t.Fatalf("Failed to read staged file: %v", err)
+	}
+	expected := append([]string{}, lines...)
+	expected[18] = "bottom change"
+	if string(staged.content) != strings.Join(expected, "\n")+"\n" {
+		t.Errorf("Unexpected staged content: %q", staged.content)
+	}
+
+	// The remaining hunk keeps its ID
+	listed, err = ops.ListHunks(tempDir, "file.txt")
+	if err != nil {
+		t.Fatalf("ListHunks failed: %v", err)
+	}
+	if !strings.HasPrefix(listed, "file.txt: 1 hunk(s)\n\nHunk "+ids[0][1]+" @@ -1,5 +1,5 @@") {
+		t.Errorf("Expected the top hunk to be left, got: %s", listed)
+	}
+	status, _ := ops.Status(tempDir, false)
+	if status != "Staged (1):\n  modified: file.txt\n\nUnstaged (1):\n  modified: file.txt" {
+		t.Errorf("Unexpected status: %q", status)
+	}
+
+	if _, err := ops.StageHunks(tempDir, "file.txt", []string{ids[0][1]}); err != nil {
+		t.Fatalf("StageHunks failed: %v", err)
+	}
+	listed, _ = ops.ListHunks(tempDir, "file.txt")
+	if listed != "No unstaged changes in file.txt" {
+		t.Errorf("Expected no hunks left, got: %s", listed)
+	}
+}
+
+func TestOperations_StageHunksNewFile(t *testing.T) {
+	tempDir, _ := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	if err := os.WriteFile(filepath.Join(tempDir, "new.txt"), []byte("first\nsecond"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	listed, err := ops.ListHunks(tempDir, "new.txt")
+	if err != nil {
+		t.Fatalf("ListHunks failed: %v", err)
+	}
+	id := regexp.MustCompile(`Hunk (\S+) @@ -0,0 \+1,2 @@\n\+first\n\+second\n\\ No newline at end of file$`).FindStringSubmatch(listed)
+	if id == nil {
+		t.Fatalf("Unexpected hunks: %q", listed)
+	}
+
+	if _, err := ops.StageHunks(tempDir, "new.txt", []string{id[1]}); err != nil {
+		t.Fatalf("StageHunks failed: %v", err)
+	}
+	status, _ := ops.Status(tempDir, false)
+	if status != "Staged (1):\n  added: new.txt" {
+		t.Errorf("Unexpected status: %q", status)
+	}
+
+	if _, err := ops.ListHunks(tempDir, "missing.txt"); err == nil {
+		t.Error("Expected error for a file missing from the working tree")
+	}
+}
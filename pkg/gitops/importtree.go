@@ -0,0 +1,166 @@
+package gitops
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// ImportTree snapshots an external directory into the repository as a new
+// commit on branch, without going through the worktree or index.
+//
+// The directory content is placed below prefix (the repository root when
+// empty). In "replace" mode everything previously below prefix is dropped so
+// the result mirrors the directory exactly; in "add" mode files are added or
+// overwritten and other files are kept. Paths matching one of the gitignore
+// style ignore patterns are skipped.
+func (g *Operations) ImportTree(repoPath, dir, branch, prefix, mode, message string, ignore []string) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	switch mode {
+	case "", "replace":
+		mode = "replace"
+	case "add":
+	default:
+		return "", fmt.Errorf("invalid import mode: %s (expected add or replace)", mode)
+	}
+
+	if message == "" {
+		return "", fmt.Errorf("commit message cannot be empty")
+	}
+
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", dir)
+	}
+
+	prefix = strings.Trim(path.Clean("/"+strings.ReplaceAll(prefix, "\\", "/")), "/")
+
+	// Determine the branch to commit to and its current tip
+	branchRef := plumbing.HEAD
+	if branch != "" {
+		branchRef = plumbing.NewBranchReferenceName(branch)
+	} else if head, err := repo.Storer.Reference(plumbing.HEAD); err == nil && head.Type() == plumbing.SymbolicReference {
+		branchRef = head.Target()
+	}
+
+	// A missing branch is created from HEAD, like git checkout -b
+	var parents []plumbing.Hash
+	var parentTree *object.Tree
+	ref, err := repo.Reference(branchRef, true)
+	if err != nil {
+		ref, err = repo.Head()
+	}
+	if err == nil {
+		parent, err := repo.CommitObject(ref.Hash())
+		if err != nil {
+			return "", fmt.Errorf("failed to get branch tip: %w", err)
+		}
+		parentTree, err = parent.Tree()
+		if err != nil {
+			return "", fmt.Errorf("failed to get tree: %w", err)
+		}
+		parents = []plumbing.Hash{parent.Hash}
+	}
+
+	// Importing onto the checked out branch requires a clean worktree, which
+	// is then moved to the new commit
+	checkedOut := false
+	if head, err := repo.Storer.Reference(plumbing.HEAD); err == nil && head.Type() == plumbing.SymbolicReference && head.Target() == branchRef {
+		checkedOut = true
+	}
+	var worktree *git.Worktree
+	if checkedOut {
+		worktree, err = repo.Worktree()
+		if err == nil {
+			status, err := worktree.Status()
+			if err != nil {
+				return "", fmt.Errorf("failed to get status: %w", err)
+			}
+			if !status.IsClean() {
+				return "", fmt.Errorf("branch '%s' is checked out and the working tree is not clean", branchRef.Short())
+			}
+		}
+	}
+
+	var patterns []gitignore.Pattern
+	for _, p := range ignore {
+		patterns = append(patterns, gitignore.ParsePattern(p, nil))
+	}
+
+	external, err := readDirectoryFiles(dir, gitignore.NewMatcher(patterns))
+	if err != nil {
+		return "", err
+	}
+
+	files, err := flattenTree(parentTree)
+	if err != nil {
+		return "", err
+	}
+
+	inPrefix := func(name string) bool {
+		return prefix == "" || name == prefix || strings.HasPrefix(name, prefix+"/")
+	}
+	if mode == "replace" {
+		for name := range files {
+			if inPrefix(name) {
+				delete(files, name)
+			}
+		}
+	}
+
+	for rel, file := range external {
+		hash, err := writeBlobObject(repo, file.content)
+		if err != nil {
+			return "", err
+		}
+		name := rel
+		if prefix != "" {
+			name = prefix + "/" + rel
+		}
+		files[name] = treeFile{hash: hash, mode: file.mode}
+	}
+
+	treeHash, err := buildTree(repo, files)
+	if err != nil {
+		return "", err
+	}
+
+	if parentTree != nil && parentTree.Hash == treeHash {
+		return fmt.Sprintf("Nothing to import: %s already matches %s", branchRef.Short(), dir), nil
+	}
+
+	signature := g.getUserSignature()
+	commitHash, err := writeCommitObject(repo, &object.Commit{
+		Author:       *signature,
+		Committer:    *signature,
+		Message:      message,
+		TreeHash:     treeHash,
+		ParentHashes: parents,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(branchRef, commitHash)); err != nil {
+		return "", fmt.Errorf("failed to update branch: %w", err)
+	}
+
+	if checkedOut && worktree != nil {
+		if err := worktree.Reset(&git.ResetOptions{Commit: commitHash, Mode: git.HardReset}); err != nil {
+			return "", fmt.Errorf("failed to update worktree: %w", err)
+		}
+	}
+
+	return fmt.Sprintf("Imported %d files from %s into %s (%s mode) as commit %s",
+		len(external), dir, branchRef.Short(), mode, commitHash.String()), nil
+}
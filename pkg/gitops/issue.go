@@ -0,0 +1,106 @@
+package gitops
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultIssuePatterns are used to find issue identifiers in branch names
+// when the repository does not configure mcpgit.commit.issuePattern: tracker
+// keys such as ABC-123, and bare numbers such as 123-fix-crash or
+// fix/gh-42 referring to GitHub/GitLab issues.
+var DefaultIssuePatterns = []string{
+	`[A-Z][A-Z0-9]+-[0-9]+`,
+	`(?i)(?:^|/)(?:gh-|issue-|#)?([0-9]+)(?:[-_/]|$)`,
+}
+
+// findIssue returns the first issue identifier matched in branch, trying the
+// patterns in order. When a pattern has a capture group, the first group is
+// the identifier; otherwise the whole match is.
+func findIssue(patterns []string, branch string) (string, error) {
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return "", fmt.Errorf("invalid issue pattern '%s': %w", pattern, err)
+		}
+
+		match := re.FindStringSubmatch(branch)
+		if match == nil {
+			continue
+		}
+		if len(match) > 1 && match[1] != "" {
+			return match[1], nil
+		}
+		return match[0], nil
+	}
+	return "", nil
+}
+
+// formatIssue returns the form of an issue identifier used in commit
+// messages; bare issue numbers are written as #123
+func formatIssue(issue string) string {
+	if issue != "" && strings.Trim(issue, "0123456789") == "" {
+		return "#" + issue
+	}
+	return issue
+}
+
+// IssueFromBranch extracts the issue identifier from a branch name (the
+// current branch when empty) and suggests how to reference it in commit
+// messages. Patterns default to the repository's configured
+// mcpgit.commit.issuePattern values, then to DefaultIssuePatterns.
+func (g *Operations) IssueFromBranch(repoPath, branch string, patterns []string) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	if branch == "" {
+		var ok bool
+		branch, ok = currentBranch(repo)
+		if !ok {
+			return "", fmt.Errorf("HEAD is detached; pass a branch name")
+		}
+	}
+
+	cfg, err := repo.Config()
+	if err != nil {
+		return "", fmt.Errorf("failed to read config: %w", err)
+	}
+	options := cfg.Raw.Section(policySection).Subsection(policySubsection).Options
+
+	if len(patterns) == 0 {
+		patterns = options.GetAll("issuePattern")
+	}
+	if len(patterns) == 0 {
+		patterns = DefaultIssuePatterns
+	}
+
+	issue, err := findIssue(patterns, branch)
+	if err != nil {
+		return "", err
+	}
+	if issue == "" {
+		return fmt.Sprintf("No issue identifier found in branch '%s' (patterns: %s)", branch, strings.Join(patterns, ", ")), nil
+	}
+
+	trailer := options.Get("issueTrailer")
+	if trailer == "" {
+		trailer = "Refs"
+	}
+	ref := formatIssue(issue)
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Branch: %s\n", branch))
+	result.WriteString(fmt.Sprintf("Issue: %s\n", ref))
+	result.WriteString("Suggested commit message prefixes:\n")
+	result.WriteString(fmt.Sprintf("  %s: <subject>\n", ref))
+	result.WriteString(fmt.Sprintf("  [%s] <subject>\n", ref))
+	result.WriteString(fmt.Sprintf("  <subject> (%s)\n", ref))
+	result.WriteString("Suggested trailer:\n")
+	result.WriteString(fmt.Sprintf("  %s: %s", trailer, ref))
+
+	return result.String(), nil
+}
@@ -0,0 +1,85 @@
+package gitops
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestFindIssue(t *testing.T) {
+	tests := []struct {
+		branch   string
+		expected string
+	}{
+		{"feature/ABC-123-retry", "ABC-123"},
+		{"PROJ2-7", "PROJ2-7"},
+		{"fix/gh-42-crash", "42"},
+		{"123-fix-typo", "123"},
+		{"issue-9", "9"},
+		{"release/v1.2", ""},
+		{"main", ""},
+	}
+
+	for _, tt := range tests {
+		issue, err := findIssue(DefaultIssuePatterns, tt.branch)
+		if err != nil {
+			t.Fatalf("findIssue(%q) failed: %v", tt.branch, err)
+		}
+		if issue != tt.expected {
+			t.Errorf("findIssue(%q) = %q, expected %q", tt.branch, issue, tt.expected)
+		}
+	}
+}
+
+func TestOperations_IssueFromBranch(t *testing.T) {
+	tempDir, repo := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	if _, err := ops.Checkout(tempDir, "feature/ABC-123-retry", true, ""); err != nil {
+		t.Fatalf("Checkout failed: %v", err)
+	}
+
+	result, err := ops.IssueFromBranch(tempDir, "", nil)
+	if err != nil {
+		t.Fatalf("IssueFromBranch failed: %v", err)
+	}
+	if !strings.Contains(result, "Issue: ABC-123") || !strings.Contains(result, "[ABC-123] <subject>") || !strings.Contains(result, "Refs: ABC-123") {
+		t.Errorf("Unexpected result: %s", result)
+	}
+
+	// Bare numbers are referenced as #N
+	result, err = ops.IssueFromBranch(tempDir, "42-fix-crash", nil)
+	if err != nil {
+		t.Fatalf("IssueFromBranch failed: %v", err)
+	}
+	if !strings.Contains(result, "Issue: #42") {
+		t.Errorf("Unexpected result: %s", result)
+	}
+
+	// Configured patterns replace the defaults
+	cfg, _ := repo.Config()
+	commit := cfg.Raw.Section("mcpgit").Subsection("commit")
+	commit.SetOption("issuePattern", `ticket([0-9]+)`)
+	commit.SetOption("issueTrailer", "Closes")
+	if err := repo.SetConfig(cfg); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	result, err = ops.IssueFromBranch(tempDir, "ticket77-ABC-1", nil)
+	if err != nil {
+		t.Fatalf("IssueFromBranch failed: %v", err)
+	}
+	if !strings.Contains(result, "Issue: #77") || !strings.Contains(result, "Closes: #77") {
+		t.Errorf("Unexpected result: %s", result)
+	}
+
+	result, err = ops.IssueFromBranch(tempDir, "main", nil)
+	if err != nil {
+		t.Fatalf("IssueFromBranch failed: %v", err)
+	}
+	if !strings.Contains(result, "No issue identifier found") {
+		t.Errorf("Unexpected result: %s", result)
+	}
+}
@@ -0,0 +1,221 @@
+package gitops
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// DefaultLargeFileThreshold is the size over which LargeFiles reports blobs
+// when LargeFileOptions.Threshold is not set
+const DefaultLargeFileThreshold = 1 << 20
+
+// DefaultLargeFileMaxCount is the number of blobs reported when
+// LargeFileOptions.MaxCount is not set
+const DefaultLargeFileMaxCount = 20
+
+// LargeFileOptions holds the parameters of a large file scan
+type LargeFileOptions struct {
+	// Threshold is the size in bytes over which blobs are reported
+	Threshold int64
+	// Range is a revision or a range A..B / A...B limiting the scanned
+	// history, all the references by default
+	Range string
+	// MaxCount caps the number of blobs reported, largest first
+	MaxCount int
+}
+
+// largeBlob is a blob over the threshold and where history introduced it
+type largeBlob struct {
+	hash   plumbing.Hash
+	size   int64
+	paths  []string
+	commit *object.Commit
+	atHead bool
+}
+
+// LargeFiles scans history for blobs over a size threshold, reporting the
+// commit that introduced each of them and its paths, to decide what to
+// move to LFS or purge from history
+func (g *Operations) LargeFiles(repoPath string, opts LargeFileOptions) (string, error) {
+	threshold := opts.Threshold
+	if threshold <= 0 {
+		threshold = DefaultLargeFileThreshold
+	}
+	maxCount := opts.MaxCount
+	if maxCount <= 0 {
+		maxCount = DefaultLargeFileMaxCount
+	}
+
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	// Sizes are read from the object headers, so only the history of
+	// repositories with large blobs needs to be walked
+	blobs := make(map[plumbing.Hash]*largeBlob)
+	blobIter, err := repo.BlobObjects()
+	if err != nil {
+		return "", fmt.Errorf("failed to list blobs: %w", err)
+	}
+	err = blobIter.ForEach(func(blob *object.Blob) error {
+		if blob.Size > threshold {
+			blobs[blob.Hash] = &largeBlob{hash: blob.Hash, size: blob.Size}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to list blobs: %w", err)
+	}
+
+	scope := "all references"
+	if opts.Range != "" {
+		scope = opts.Range
+	}
+	if len(blobs) == 0 {
+		return fmt.Sprintf("No blobs over %s in the history of %s", formatBytes(threshold), scope), nil
+	}
+
+	var commitIter object.CommitIter
+	if opts.Range == "" {
+		commitIter, err = repo.Log(&git.LogOptions{All: true})
+	} else {
+		commitIter, err = logIterator(repo, LogOptions{Range: opts.Range})
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get log: %w", err)
+	}
+	defer commitIter.Close()
+
+	err = commitIter.ForEach(func(commit *object.Commit) error {
+		return introducedBlobs(commit, blobs)
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to scan history: %w", err)
+	}
+
+	var found []*largeBlob
+	for _, blob := range blobs {
+		if blob.commit != nil {
+			found = append(found, blob)
+		}
+	}
+	if len(found) == 0 {
+		return fmt.Sprintf("No blobs over %s in the history of %s", formatBytes(threshold), scope), nil
+	}
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].size != found[j].size {
+			return found[i].size > found[j].size
+		}
+		return found[i].hash.String() < found[j].hash.String()
+	})
+	markBlobsAtHead(repo, found)
+
+	var total int64
+	for _, blob := range found {
+		total += blob.size
+	}
+	var result strings.Builder
+	fmt.Fprintf(&result, "%d blob(s) over %s in the history of %s, %s in total:", len(found), formatBytes(threshold), scope, formatBytes(total))
+	for i, blob := range found {
+		if i == maxCount {
+			fmt.Fprintf(&result, "\n... %d more", len(found)-maxCount)
+			break
+		}
+		sort.Strings(blob.paths)
+		state := "deleted from HEAD"
+		if blob.atHead {
+			state = "still in HEAD"
+		}
+		fmt.Fprintf(&result, "\n%s %s %s (%s)\n    introduced by %s %s %s",
+			formatBytes(blob.size), blob.hash.String()[:7], strings.Join(blob.paths, ", "), state,
+			blob.commit.Hash.String()[:7], blob.commit.Author.When.Format("2006-01-02"), commitSubject(blob.commit))
+	}
+	return result.String(), nil
+}
+
+// introducedBlobs records the large blobs a commit adds or modifies
+// compared to its first parent, keeping the oldest commit as the one
+// introducing each blob
+func introducedBlobs(commit *object.Commit, blobs map[plumbing.Hash]*largeBlob) error {
+	tree, err := commit.Tree()
+	if err != nil {
+		return fmt.Errorf("failed to get tree of %s: %w", commit.Hash.String()[:7], err)
+	}
+	var parentTree *object.Tree
+	if commit.NumParents() > 0 {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return fmt.Errorf("failed to get parent of %s: %w", commit.Hash.String()[:7], err)
+		}
+		parentTree, err = parent.Tree()
+		if err != nil {
+			return fmt.Errorf("failed to get tree of %s: %w", parent.Hash.String()[:7], err)
+		}
+	}
+
+	changes, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		return fmt.Errorf("failed to diff %s: %w", commit.Hash.String()[:7], err)
+	}
+	for _, change := range changes {
+		blob, ok := blobs[change.To.TreeEntry.Hash]
+		if !ok || change.To.Name == "" {
+			continue
+		}
+		known := false
+		for _, path := range blob.paths {
+			known = known || path == change.To.Name
+		}
+		if !known {
+			blob.paths = append(blob.paths, change.To.Name)
+		}
+		// A merge also brings in the blobs of its other parents, which
+		// introduced them earlier or, made by scripts, in the same second
+		switch {
+		case blob.commit == nil, commit.Committer.When.Before(blob.commit.Committer.When):
+			blob.commit = commit
+		case commit.Committer.When.Equal(blob.commit.Committer.When) && blob.commit.NumParents() > 1:
+			blob.commit = commit
+		}
+	}
+	return nil
+}
+
+// markBlobsAtHead marks the blobs still in the tree of HEAD
+func markBlobsAtHead(repo *git.Repository, blobs []*largeBlob) {
+	head, err := repo.Head()
+	if err != nil {
+		return
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return
+	}
+
+	index := make(map[plumbing.Hash]*largeBlob, len(blobs))
+	for _, blob := range blobs {
+		index[blob.hash] = blob
+	}
+	walker := object.NewTreeWalker(tree, true, nil)
+	defer walker.Close()
+	for {
+		_, entry, err := walker.Next()
+		if err != nil {
+			return
+		}
+		if blob, ok := index[entry.Hash]; ok {
+			blob.atHead = true
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package gitops
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOperations_LargeFiles(t *testing.T) {
+	tempDir, _ := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+	if result, _ := ops.LargeFiles(tempDir, LargeFileOptions{}); result != "No blobs over 1.0 MiB in the history of all references" {
+		t.Errorf("Unexpected result: %s", result)
+	}
+
+	commitFile(t, ops, tempDir, "video.bin", strings.Repeat("v", 4096), "Add video")
+	commitFile(t, ops, tempDir, "data.csv", strings.Repeat("d,", 1024), "Add data")
+	if err := os.Remove(filepath.Join(tempDir, "video.bin")); err != nil {
+		t.Fatalf("Failed to remove file: %v", err)
+	}
+	if _, err := ops.Add(tempDir, []string{"video.bin"}, AddOptions{}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if _, err := ops.Commit(tempDir, "Remove video", CommitOptions{}); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	result, err := ops.LargeFiles(tempDir, LargeFileOptions{Threshold: 1024})
+	if err != nil {
+		t.Fatalf("LargeFiles failed: %v", err)
+	}
+	lines := strings.Split(result, "\n")
+	if len(lines) != 5 || lines[0] != "2 blob(s) over 1.0 KiB in the history of all references, 6.0 KiB in total:" ||
+		!strings.HasPrefix(lines[1], "4.0 KiB ") || !strings.HasSuffix(lines[1], " video.bin (deleted from HEAD)") ||
+		!strings.HasSuffix(lines[2], " Add video") ||
+		!strings.HasSuffix(lines[3], " data.csv (still in HEAD)") || !strings.HasSuffix(lines[4], " Add data") {
+		t.Errorf("Unexpected result:\n%s", result)
+	}
+
+	if result, _ := ops.LargeFiles(tempDir, LargeFileOptions{Threshold: 1024, MaxCount: 1}); !strings.HasSuffix(result, "\n... 1 more") {
+		t.Errorf("Expected the list to be limited, got:\n%s", result)
+	}
+	result, err = ops.LargeFiles(tempDir, LargeFileOptions{Threshold: 1024, Range: "HEAD~1..HEAD"})
+	if err != nil || result != "No blobs over 1.0 KiB in the history of HEAD~1..HEAD" {
+		t.Errorf("Unexpected result for a range: %s (%v)", result, err)
+	}
+}
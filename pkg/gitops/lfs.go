@@ -0,0 +1,366 @@
+package gitops
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/gitattributes"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// lfsPointerVersion starts the pointer files Git LFS commits in place of
+// the content of the files it tracks. go-git does not run the LFS filters,
+// so these files are checked out and staged as they are.
+const lfsPointerVersion = "version https://git-lfs.github.com/spec/v1"
+
+// lfsPointerMaxSize is the size over which a file cannot be a pointer
+const lfsPointerMaxSize = 1024
+
+// lfsPointer is the content of a Git LFS pointer file
+type lfsPointer struct {
+	oid  string
+	size int64
+}
+
+// parseLFSPointer parses a Git LFS pointer file
+func parseLFSPointer(content []byte) (lfsPointer, bool) {
+	var pointer lfsPointer
+	if len(content) > lfsPointerMaxSize || !bytes.HasPrefix(content, []byte(lfsPointerVersion+"\n")) {
+		return pointer, false
+	}
+	for _, line := range strings.Split(string(content), "\n")[1:] {
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "oid":
+			oid, ok := strings.CutPrefix(value, "sha256:")
+			if !ok || len(oid) != 64 {
+				return pointer, false
+			}
+			pointer.oid = oid
+		case "size":
+			size, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || size < 0 {
+				return pointer, false
+			}
+			pointer.size = size
+		}
+	}
+	return pointer, pointer.oid != ""
+}
+
+// lfsAttributes are the .gitattributes rules of a tree assigning the lfs
+// filter
+type lfsAttributes struct {
+	matcher gitattributes.Matcher
+	// patterns are the patterns with filter=lfs, relative to the root
+	patterns []string
+}
+
+// readLFSAttributes reads the .gitattributes files of a tree
+func readLFSAttributes(tree *object.Tree) (*lfsAttributes, error) {
+	type attributesFile struct {
+		dir  []string
+		file *object.File
+	}
+	var files []attributesFile
+	walker := object.NewTreeWalker(tree, true, nil)
+	defer walker.Close()
+	for {
+		name, entry, err := walker.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read .gitattributes: %w", err)
+		}
+		if path.Base(name) != ".gitattributes" || !entry.Mode.IsFile() {
+			continue
+		}
+		file, err := tree.File(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		var domain []string
+		if dir := path.Dir(name); dir != "." {
+			domain = strings.Split(dir, "/")
+		}
+		files = append(files, attributesFile{dir: domain, file: file})
+	}
+	// Deeper files have priority, as the matcher expects them last
+	sort.SliceStable(files, func(i, j int) bool { return len(files[i].dir) < len(files[j].dir) })
+
+	attributes := &lfsAttributes{}
+	var stack []gitattributes.MatchAttribute
+	for _, f := range files {
+		content, err := f.file.Contents()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", f.file.Name, err)
+		}
+		rules, err := gitattributes.ReadAttributes(strings.NewReader(content), f.dir, len(f.dir) == 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", f.file.Name, err)
+		}
+		stack = append(stack, rules...)
+
+		for _, line := range strings.Split(content, "\n") {
+			fields := strings.Fields(line)
+			if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+				continue
+			}
+			for _, field := range fields[1:] {
+				if field == "filter=lfs" {
+					attributes.patterns = append(attributes.patterns, path.Join(append(f.dir, fields[0])...))
+				}
+			}
+		}
+	}
+	attributes.matcher = gitattributes.NewMatcher(stack)
+	return attributes, nil
+}
+
+// tracks reports whether the lfs filter applies to a path
+func (a *lfsAttributes) tracks(name string) bool {
+	// Only requesting the filter attribute makes the matcher honor the
+	// priorities of the rules
+	results, _ := a.matcher.Match(strings.Split(name, "/"), []string{"filter"})
+	filter, ok := results["filter"]
+	return ok && filter.IsValueSet() && filter.Value() == "lfs"
+}
+
+// readLFSPointer returns the pointer stored in a blob, false if the blob is
+// not a pointer
+func readLFSPointer(repo *git.Repository, entry object.TreeEntry) (lfsPointer, bool) {
+	if !entry.Mode.IsFile() || entry.Mode == filemode.Symlink {
+		return lfsPointer{}, false
+	}
+	blob, err := repo.BlobObject(entry.Hash)
+	if err != nil || blob.Size > lfsPointerMaxSize {
+		return lfsPointer{}, false
+	}
+	reader, err := blob.Reader()
+	if err != nil {
+		return lfsPointer{}, false
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return lfsPointer{}, false
+	}
+	return parseLFSPointer(content)
+}
+
+// readLFSPointerFile returns the pointer a working tree file contains
+func readLFSPointerFile(name string) (lfsPointer, bool) {
+	info, err := os.Lstat(name)
+	if err != nil || !info.Mode().IsRegular() || info.Size() > lfsPointerMaxSize {
+		return lfsPointer{}, false
+	}
+	content, err := os.ReadFile(name)
+	if err != nil {
+		return lfsPointer{}, false
+	}
+	return parseLFSPointer(content)
+}
+
+// lfsFile is a file of a tree stored in Git LFS or meant to be
+type lfsFile struct {
+	name    string
+	pointer lfsPointer
+	// isPointer is false for a file tracked by LFS but committed as is
+	isPointer bool
+	tracked   bool
+	size      int64
+}
+
+// lfsFiles lists the files of a tree tracked by LFS or committed as
+// pointers
+func lfsFiles(repo *git.Repository, tree *object.Tree, attributes *lfsAttributes) ([]lfsFile, error) {
+	var files []lfsFile
+	walker := object.NewTreeWalker(tree, true, nil)
+	defer walker.Close()
+	for {
+		name, entry, err := walker.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to walk tree: %w", err)
+		}
+		if !entry.Mode.IsFile() {
+			continue
+		}
+
+		file := lfsFile{name: name, tracked: attributes.tracks(name)}
+		file.pointer, file.isPointer = readLFSPointer(repo, entry)
+		if !file.tracked && !file.isPointer {
+			continue
+		}
+		if !file.isPointer {
+			blob, err := repo.BlobObject(entry.Hash)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", name, err)
+			}
+			file.size = blob.Size
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// LFSFiles lists the files of a revision (default HEAD) stored in Git LFS,
+// with the patterns of .gitattributes assigning the lfs filter. For HEAD
+// of a non-bare repository it also tells which files are checked out as
+// pointers instead of their content.
+func (g *Operations) LFSFiles(repoPath, revision string) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+	if revision == "" {
+		revision = "HEAD"
+	}
+	commit, err := resolveCommit(repo, revision)
+	if err != nil {
+		return "", err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get tree: %w", err)
+	}
+
+	attributes, err := readLFSAttributes(tree)
+	if err != nil {
+		return "", err
+	}
+	files, err := lfsFiles(repo, tree, attributes)
+	if err != nil {
+		return "", err
+	}
+	if len(attributes.patterns) == 0 && len(files) == 0 {
+		return fmt.Sprintf("No files tracked by Git LFS in %s", revision), nil
+	}
+
+	// The working tree and the local LFS objects only matter for HEAD
+	worktreeDir := ""
+	gitDir := ""
+	if head, err := repo.Head(); err == nil && head.Hash() == commit.Hash {
+		if dir, bare, err := findGitDir(repoPath); err == nil {
+			gitDir = dir
+			if !bare {
+				worktreeDir = repoPath
+			}
+		}
+	}
+
+	var result strings.Builder
+	fmt.Fprintf(&result, "Git LFS patterns in .gitattributes of %s (%d):", revision, len(attributes.patterns))
+	for _, pattern := range attributes.patterns {
+		result.WriteString("\n  " + pattern)
+	}
+
+	var stored, unconverted, untracked []string
+	var total int64
+	checkedOutPointers := 0
+	for _, file := range files {
+		if !file.isPointer {
+			unconverted = append(unconverted, fmt.Sprintf("  %s (%s)", file.name, formatBytes(file.size)))
+			continue
+		}
+		if !file.tracked {
+			untracked = append(untracked, "  "+file.name)
+		}
+		total += file.pointer.size
+
+		var details []string
+		details = append(details, formatBytes(file.pointer.size), "oid "+file.pointer.oid[:10])
+		if gitDir != "" {
+			object := filepath.Join(gitDir, "lfs", "objects", file.pointer.oid[:2], file.pointer.oid[2:4], file.pointer.oid)
+			if _, err := os.Stat(object); err == nil {
+				details = append(details, "downloaded")
+			} else {
+				details = append(details, "not downloaded")
+			}
+		}
+		if worktreeDir != "" {
+			if _, ok := readLFSPointerFile(filepath.Join(worktreeDir, filepath.FromSlash(file.name))); ok {
+				details = append(details, "checked out as a pointer")
+				checkedOutPointers++
+			}
+		}
+		stored = append(stored, fmt.Sprintf("  %s (%s)", file.name, strings.Join(details, ", ")))
+	}
+
+	fmt.Fprintf(&result, "\nFiles stored in Git LFS (%d, %s in total):", len(stored), formatBytes(total))
+	for _, line := range stored {
+		result.WriteString("\n" + line)
+	}
+	if len(unconverted) > 0 {
+		fmt.Fprintf(&result, "\nFiles matching the patterns but committed as regular content (%d):\n%s", len(unconverted), strings.Join(unconverted, "\n"))
+	}
+	if len(untracked) > 0 {
+		fmt.Fprintf(&result, "\nPointers outside of the patterns (%d):\n%s", len(untracked), strings.Join(untracked, "\n"))
+	}
+	if checkedOutPointers > 0 {
+		fmt.Fprintf(&result, "\n%d file(s) in the working tree are pointers, not their content: run git lfs pull with git-lfs installed to download them", checkedOutPointers)
+	}
+	return result.String(), nil
+}
+
+// lfsCheckoutNote tells which files tracked by Git LFS a checkout left as
+// pointers, go-git not running the LFS smudge filter. It is empty for
+// repositories without LFS files.
+func lfsCheckoutNote(repo *git.Repository, repoPath string) string {
+	head, err := repo.Head()
+	if err != nil {
+		return ""
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return ""
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return ""
+	}
+	attributes, err := readLFSAttributes(tree)
+	if err != nil || len(attributes.patterns) == 0 {
+		return ""
+	}
+
+	var pointers []string
+	walker := object.NewTreeWalker(tree, true, nil)
+	defer walker.Close()
+	for {
+		name, entry, err := walker.Next()
+		if err != nil {
+			break
+		}
+		if !entry.Mode.IsFile() || !attributes.tracks(name) {
+			continue
+		}
+		if _, ok := readLFSPointerFile(filepath.Join(repoPath, filepath.FromSlash(name))); ok {
+			pointers = append(pointers, name)
+		}
+	}
+	if len(pointers) == 0 {
+		return ""
+	}
+
+	listed := pointers
+	if len(listed) > 5 {
+		listed = append(listed[:5:5], fmt.Sprintf("and %d more", len(pointers)-5))
+	}
+	return fmt.Sprintf("\n%d file(s) tracked by Git LFS are checked out as pointers, not their content: %s. Run git lfs pull with git-lfs installed to download them.",
+		len(pointers), strings.Join(listed, ", "))
+}
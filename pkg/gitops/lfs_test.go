@@ -0,0 +1,86 @@
+package gitops
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOperations_LFSFiles(t *testing.T) {
+	tempDir, _ := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+	if result, _ := ops.LFSFiles(tempDir, ""); result != "No files tracked by Git LFS in HEAD" {
+		t.Errorf("Unexpected result: %s", result)
+	}
+
+	oid := strings.Repeat("ab", 32)
+	pointer := lfsPointerVersion + "\noid sha256:" + oid + "\nsize 2097152\n"
+	commitFile(t, ops, tempDir, ".gitattributes", "*.bin filter=lfs diff=lfs merge=lfs -text\n", "Track binaries with LFS")
+	commitFile(t, ops, tempDir, "model.bin", pointer, "Add model")
+	commitFile(t, ops, tempDir, "raw.bin", "not a pointer\n", "Add raw binary")
+	if err := os.MkdirAll(filepath.Join(tempDir, "assets"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	commitFile(t, ops, tempDir, "assets/.gitattributes", "*.bin -filter\n", "Untrack assets")
+	commitFile(t, ops, tempDir, "assets/logo.bin", pointer, "Add logo")
+
+	result, err := ops.LFSFiles(tempDir, "")
+	if err != nil {
+		t.Fatalf("LFSFiles failed: %v", err)
+	}
+	expected := "Git LFS patterns in .gitattributes of HEAD (1):\n  *.bin\n" +
+		"Files stored in Git LFS (2, 4.0 MiB in total):\n" +
+		"  assets/logo.bin (2.0 MiB, oid ababababab, not downloaded, checked out as a pointer)\n" +
+		"  model.bin (2.0 MiB, oid ababababab, not downloaded, checked out as a pointer)\n" +
+		"Files matching the patterns but committed as regular content (1):\n  raw.bin (14 B)\n" +
+		"Pointers outside of the patterns (1):\n  assets/logo.bin\n" +
+		"2 file(s) in the working tree are pointers, not their content: run git lfs pull with git-lfs installed to download them"
+	if result != expected {
+		t.Errorf("Unexpected result:\n%s", result)
+	}
+
+	// A downloaded object and a working tree with the real content
+	object := filepath.Join(tempDir, ".git", "lfs", "objects", oid[:2], oid[2:4], oid)
+	if err := os.MkdirAll(filepath.Dir(object), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(object, []byte("model"), 0644); err != nil {
+		t.Fatalf("Failed to write object: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "model.bin"), []byte("model"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	result, _ = ops.LFSFiles(tempDir, "")
+	if !strings.Contains(result, "  model.bin (2.0 MiB, oid ababababab, downloaded)\n") || !strings.HasSuffix(result, "1 file(s) in the working tree are pointers, not their content: run git lfs pull with git-lfs installed to download them") {
+		t.Errorf("Unexpected result:\n%s", result)
+	}
+	if result, _ := ops.LFSFiles(tempDir, "HEAD~4"); result != "Git LFS patterns in .gitattributes of HEAD~4 (1):\n  *.bin\nFiles stored in Git LFS (0, 0 B in total):" {
+		t.Errorf("Unexpected result for HEAD~4:\n%s", result)
+	}
+}
+
+func TestOperations_CheckoutLFSPointers(t *testing.T) {
+	tempDir, _ := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+	commitFile(t, ops, tempDir, ".gitattributes", "*.bin filter=lfs\n", "Track binaries with LFS")
+	if _, err := ops.Checkout(tempDir, "models", true, ""); err != nil {
+		t.Fatalf("Checkout failed: %v", err)
+	}
+	commitFile(t, ops, tempDir, "model.bin", lfsPointerVersion+"\noid sha256:"+strings.Repeat("cd", 32)+"\nsize 12\n", "Add model")
+
+	if result, _ := ops.Checkout(tempDir, "master", false, ""); result != "Switched to branch 'master'" {
+		t.Errorf("Unexpected result without pointers: %s", result)
+	}
+	result, err := ops.Checkout(tempDir, "models", false, "")
+	if err != nil {
+		t.Fatalf("Checkout failed: %v", err)
+	}
+	if result != "Switched to branch 'models'\n1 file(s) tracked by Git LFS are checked out as pointers, not their content: model.bin. Run git lfs pull with git-lfs installed to download them." {
+		t.Errorf("Unexpected result: %s", result)
+	}
+}
@@ -0,0 +1,186 @@
+package gitops
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/pengcunfu/go-mcp-git/internal/tracing"
+)
+
+// RepoLocks serializes the operations modifying a repository, so that two
+// of them never write its index or refs at once. Exclusive locks wait for
+// the other operations of the repository, shared locks, taken by the
+// operations only reading it, wait for the exclusive ones only. Waiting
+// writers go first, so that a stream of reads does not starve them.
+type RepoLocks struct {
+	mu    sync.Mutex
+	locks map[string]*repoLock
+}
+
+// repoLock is the lock of one repository, guarded by RepoLocks.mu. Waiters
+// are woken up by closing released, which is replaced on every release.
+type repoLock struct {
+	readers        int
+	writer         bool
+	waitingWriters int
+	waiting        int
+	released       chan struct{}
+}
+
+// processLocks are the repository locks of the operations, shared by the
+// server instances of the process, which may serve the same repositories
+var processLocks = NewRepoLocks()
+
+// NewRepoLocks creates a lock manager holding no locks
+func NewRepoLocks() *RepoLocks {
+	return &RepoLocks{locks: make(map[string]*repoLock)}
+}
+
+// Lock locks repositories, exclusively or shared, until unlock is called.
+// Repositories are locked in the same order by every caller, so that calls
+// locking several never deadlock. Once ctx is done waiting stops with its
+// error, no lock being held.
+func (l *RepoLocks) Lock(ctx context.Context, exclusive bool, repoPaths ...string) (unlock func(), err error) {
+	keys := lockKeys(repoPaths)
+	var held []string
+	release := func() {
+		for i := len(held) - 1; i >= 0; i-- {
+			l.release(held[i], exclusive)
+		}
+	}
+	for _, key := range keys {
+		if err := l.acquire(ctx, key, exclusive); err != nil {
+			release()
+			return nil, fmt.Errorf("failed to lock repository %s: %w", key, err)
+		}
+		held = append(held, key)
+	}
+
+	var once sync.Once
+	return func() { once.Do(release) }, nil
+}
+
+// Locked returns the number of repositories locked or waited for
+func (l *RepoLocks) Locked() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.locks)
+}
+
+func (l *RepoLocks) acquire(ctx context.Context, key string, exclusive bool) error {
+	l.mu.Lock()
+	lock, ok := l.locks[key]
+	if !ok {
+		lock = &repoLock{released: make(chan struct{})}
+		l.locks[key] = lock
+	}
+	lock.waiting++
+	if exclusive {
+		lock.waitingWriters++
+	}
+	defer func() {
+		lock.waiting--
+		if exclusive {
+			lock.waitingWriters--
+		}
+		l.forget(key, lock)
+		l.mu.Unlock()
+	}()
+
+	for {
+		if exclusive && !lock.writer && lock.readers == 0 {
+			lock.writer = true
+			return nil
+		}
+		if !exclusive && !lock.writer && lock.waitingWriters == 0 {
+			lock.readers++
+			return nil
+		}
+
+		released := lock.released
+		l.mu.Unlock()
+		select {
+		case <-released:
+			l.mu.Lock()
+		case <-ctx.Done():
+			l.mu.Lock()
+			// Writers given up on may let readers in
+			lock.wake()
+			return ctx.Err()
+		}
+	}
+}
+
+func (l *RepoLocks) release(key string, exclusive bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	lock := l.locks[key]
+	if exclusive {
+		lock.writer = false
+	} else {
+		lock.readers--
+	}
+	lock.wake()
+	l.forget(key, lock)
+}
+
+// wake lets the waiters check the lock again
+func (lock *repoLock) wake() {
+	close(lock.released)
+	lock.released = make(chan struct{})
+}
+
+// forget drops the lock of a repository nobody holds or waits for
+func (l *RepoLocks) forget(key string, lock *repoLock) {
+	if lock.readers == 0 && !lock.writer && lock.waiting == 0 {
+		delete(l.locks, key)
+	}
+}
+
+// lockKeys returns the distinct repositories of paths in lock order, the
+// spellings of a repository sharing its lock
+func lockKeys(repoPaths []string) []string {
+	seen := make(map[string]bool, len(repoPaths))
+	keys := make([]string, 0, len(repoPaths))
+	for _, path := range repoPaths {
+		key := repoKey(path)
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// LockRepositories locks repositories for an operation, exclusively when
+// it modifies them, until unlock is called. Waiting stops with the context
+// the operations are bound to.
+func (g *Operations) LockRepositories(exclusive bool, repoPaths ...string) (unlock func(), err error) {
+	_, span := tracing.Start(g.context(), "git.lock", tracing.KindInternal, tracing.Bool("git.lock.exclusive", exclusive), tracing.Int("git.lock.repositories", len(repoPaths)))
+	unlock, err = g.locks.Lock(g.context(), exclusive, repoPaths...)
+	span.End(err)
+	return unlock, err
+}
+
+// LockedRepositories returns the number of repositories locked or waited
+// for
+func (g *Operations) LockedRepositories() int {
+	return g.locks.Locked()
+}
+
+// repoKey resolves the path of a repository, so that its spellings and the
+// symlinks to it have the same key
+func repoKey(path string) string {
+	key, err := filepath.Abs(path)
+	if err != nil {
+		key = filepath.Clean(path)
+	}
+	if resolved, err := filepath.EvalSymlinks(key); err == nil {
+		key = resolved
+	}
+	return key
+}
@@ -0,0 +1,123 @@
+package gitops
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// lockedWithin reports whether locking succeeds within a short delay
+func lockedWithin(t *testing.T, locks *RepoLocks, exclusive bool, repoPaths ...string) (func(), bool) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	unlock, err := locks.Lock(ctx, exclusive, repoPaths...)
+	if err != nil {
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Expected locking to time out, got: %v", err)
+		}
+		return nil, false
+	}
+	return unlock, true
+}
+
+func TestRepoLocks(t *testing.T) {
+	locks := NewRepoLocks()
+	dir := t.TempDir()
+	repoA, repoB := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+
+	t.Run("exclusive locks wait for each other", func(t *testing.T) {
+		unlock, ok := lockedWithin(t, locks, true, repoA)
+		if !ok {
+			t.Fatal("Expected a free repository to lock")
+		}
+		if _, ok := lockedWithin(t, locks, true, repoA); ok {
+			t.Error("Expected a second exclusive lock to wait")
+		}
+		if _, ok := lockedWithin(t, locks, false, repoA); ok {
+			t.Error("Expected a shared lock to wait for the exclusive one")
+		}
+		unlockB, ok := lockedWithin(t, locks, true, repoB)
+		if !ok {
+			t.Error("Expected another repository to lock")
+		} else {
+			unlockB()
+		}
+		unlock()
+		unlock() // unlocking twice is harmless
+
+		if unlock, ok := lockedWithin(t, locks, true, repoA); !ok {
+			t.Error("Expected the released repository to lock")
+		} else {
+			unlock()
+		}
+	})
+
+	t.Run("shared locks share the repository", func(t *testing.T) {
+		first, ok := lockedWithin(t, locks, false, repoA)
+		if !ok {
+			t.Fatal("Expected a shared lock")
+		}
+		second, ok := lockedWithin(t, locks, false, repoA)
+		if !ok {
+			t.Fatal("Expected shared locks to share the repository")
+		}
+		if _, ok := lockedWithin(t, locks, true, repoA); ok {
+			t.Error("Expected an exclusive lock to wait for the shared ones")
+		}
+		first()
+		second()
+	})
+
+	t.Run("waiting writers go first", func(t *testing.T) {
+		reader, _ := lockedWithin(t, locks, false, repoA)
+		locked := make(chan func())
+		go func() {
+			unlock, err := locks.Lock(context.Background(), true, repoA)
+			if err != nil {
+				t.Errorf("Lock failed: %v", err)
+			}
+			locked <- unlock
+		}()
+		time.Sleep(20 * time.Millisecond)
+		if _, ok := lockedWithin(t, locks, false, repoA); ok {
+			t.Error("Expected a shared lock to wait behind a waiting writer")
+		}
+		reader()
+		(<-locked)()
+	})
+
+	t.Run("spellings of a repository share its lock", func(t *testing.T) {
+		if err := os.MkdirAll(repoA, 0755); err != nil {
+			t.Fatal(err)
+		}
+		unlock, _ := lockedWithin(t, locks, true, repoA)
+		if _, ok := lockedWithin(t, locks, true, filepath.Join(repoA, "..", "a")); ok {
+			t.Error("Expected another spelling of the repository to wait")
+		}
+		unlock()
+	})
+
+	t.Run("several repositories", func(t *testing.T) {
+		unlock, ok := lockedWithin(t, locks, true, repoB, repoA, repoA)
+		if !ok {
+			t.Fatal("Expected the repositories to lock")
+		}
+		if _, ok := lockedWithin(t, locks, true, repoA, filepath.Join(dir, "c")); ok {
+			t.Error("Expected locking repositories partly held to wait")
+		}
+		if free, ok := lockedWithin(t, locks, true, filepath.Join(dir, "c")); !ok {
+			t.Error("Expected a failed lock to release the repositories it got")
+		} else {
+			free()
+		}
+		unlock()
+	})
+
+	if n := locks.Locked(); n != 0 {
+		t.Errorf("Expected no repository left locked, got %d", n)
+	}
+}
@@ -0,0 +1,198 @@
+package gitops
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// refDecorations maps commit hashes to the names of the references pointing
+// at them, formatted like git log --decorate: "HEAD -> main", "tag: v1.0",
+// "origin/main"
+func refDecorations(repo *git.Repository) (map[plumbing.Hash][]string, error) {
+	refs, err := repo.References()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list references: %w", err)
+	}
+	defer refs.Close()
+
+	headTarget := plumbing.ReferenceName("")
+	if head, err := repo.Storer.Reference(plumbing.HEAD); err == nil && head.Type() == plumbing.SymbolicReference {
+		headTarget = head.Target()
+	}
+
+	// Names are ordered HEAD first, then tags, local branches and remote
+	// branches, alphabetically within each group
+	type decoration struct {
+		group int
+		name  string
+	}
+	byHash := make(map[plumbing.Hash][]decoration)
+
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() != plumbing.HashReference {
+			return nil
+		}
+
+		hash := ref.Hash()
+		name := ref.Name()
+		switch {
+		case name == plumbing.HEAD:
+			byHash[hash] = append(byHash[hash], decoration{0, "HEAD"})
+		case name.IsTag():
+			if tag, err := repo.TagObject(hash); err == nil {
+				if commit, err := tag.Commit(); err == nil {
+					hash = commit.Hash
+				}
+			}
+			byHash[hash] = append(byHash[hash], decoration{1, "tag: " + name.Short()})
+		case name.IsBranch():
+			if name == headTarget {
+				byHash[hash] = append(byHash[hash], decoration{0, "HEAD -> " + name.Short()})
+			} else {
+				byHash[hash] = append(byHash[hash], decoration{2, name.Short()})
+			}
+		case name.IsRemote():
+			byHash[hash] = append(byHash[hash], decoration{3, name.Short()})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list references: %w", err)
+	}
+
+	decorations := make(map[plumbing.Hash][]string, len(byHash))
+	for hash, list := range byHash {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].group != list[j].group {
+				return list[i].group < list[j].group
+			}
+			return list[i].name < list[j].name
+		})
+		names := make([]string, len(list))
+		for i, d := range list {
+			names[i] = d.name
+		}
+		decorations[hash] = names
+	}
+
+	return decorations, nil
+}
+
+// logGraph renders the ASCII commit graph of git log --graph. Commits must
+// be fed children first; every column tracks the commit expected next on
+// that line of history.
+type logGraph struct {
+	columns []plumbing.Hash
+}
+
+// lane is a line of history moving from one column to another between two
+// commit rows
+type lane struct {
+	pos, target int
+}
+
+// next returns the graph prefix of the row showing commit c and the
+// connector rows drawn between it and the next commit
+func (g *logGraph) next(c *object.Commit) (string, []string) {
+	idx := indexOfHash(g.columns, c.Hash)
+	if idx < 0 {
+		g.columns = append(g.columns, c.Hash)
+		idx = len(g.columns) - 1
+	}
+
+	row := bytes.Repeat([]byte(" "), 2*len(g.columns))
+	for i := range g.columns {
+		row[2*i] = '|'
+	}
+	row[2*idx] = '*'
+
+	// Replace the commit by its parents; other lines of history waiting for
+	// the same commit end here
+	var next []plumbing.Hash
+	for i, hash := range g.columns {
+		if i == idx {
+			for _, parent := range c.ParentHashes {
+				if indexOfHash(next, parent) < 0 && indexOfHash(g.columns[i+1:], parent) < 0 {
+					next = append(next, parent)
+				}
+			}
+			continue
+		}
+		if hash != c.Hash && indexOfHash(next, hash) < 0 {
+			next = append(next, hash)
+		}
+	}
+
+	var lanes []lane
+	for i, hash := range g.columns {
+		switch {
+		case i == idx:
+			for _, parent := range c.ParentHashes {
+				lanes = append(lanes, lane{i, indexOfHash(next, parent)})
+			}
+		case hash == c.Hash:
+			if len(c.ParentHashes) > 0 {
+				lanes = append(lanes, lane{i, indexOfHash(next, c.ParentHashes[0])})
+			}
+		default:
+			lanes = append(lanes, lane{i, indexOfHash(next, hash)})
+		}
+	}
+	g.columns = next
+
+	return strings.TrimRight(string(row), " "), connectorRows(lanes)
+}
+
+// connectorRows draws lanes converging on or diverging to their target
+// column, moving each lane by at most one column per row
+func connectorRows(lanes []lane) []string {
+	width := 0
+	for _, l := range lanes {
+		if l.pos > width {
+			width = l.pos
+		}
+		if l.target > width {
+			width = l.target
+		}
+	}
+
+	var rows []string
+	for {
+		row := bytes.Repeat([]byte(" "), 2*width+2)
+		moved := false
+		for i := range lanes {
+			l := &lanes[i]
+			switch {
+			case l.target > l.pos:
+				row[2*l.pos+1] = '\\'
+				l.pos++
+				moved = true
+			case l.target < l.pos:
+				row[2*l.pos-1] = '/'
+				l.pos--
+				moved = true
+			default:
+				row[2*l.pos] = '|'
+			}
+		}
+		if !moved {
+			return rows
+		}
+		rows = append(rows, strings.TrimRight(string(row), " "))
+	}
+}
+
+func indexOfHash(hashes []plumbing.Hash, hash plumbing.Hash) int {
+	for i, h := range hashes {
+		if h == hash {
+			return i
+		}
+	}
+	return -1
+}
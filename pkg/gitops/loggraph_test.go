@@ -0,0 +1,70 @@
+package gitops
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func TestOperations_LogGraph(t *testing.T) {
+	tempDir, repo := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+	head, _ := repo.Head()
+	initial := head.Hash()
+
+	// Commits get increasing timestamps so the order is deterministic
+	when := time.Now().Add(time.Hour)
+	commit := func(name, message string, parents ...plumbing.Hash) plumbing.Hash {
+		when = when.Add(time.Minute)
+		return commitAt(t, repo, name, message, when, parents...)
+	}
+
+	a := commit("a.txt", "Master change")
+	if _, err := repo.CreateTag("v1", a, nil); err != nil {
+		t.Fatalf("CreateTag failed: %v", err)
+	}
+	if _, err := ops.Checkout(tempDir, "feature", true, initial.String()); err != nil {
+		t.Fatalf("Checkout failed: %v", err)
+	}
+	f := commit("f.txt", "Feature change")
+	if _, err := ops.Checkout(tempDir, "master", false, ""); err != nil {
+		t.Fatalf("Checkout failed: %v", err)
+	}
+	m := commit("f.txt", "Merge feature", a, f)
+
+	commits, err := ops.Log(tempDir, LogOptions{MaxCount: 10, Graph: true, Decorate: true})
+	if err != nil {
+		t.Fatalf("Log failed: %v", err)
+	}
+
+	short := func(h plumbing.Hash) string { return h.String()[:7] }
+	expected := []string{
+		"* " + short(m) + " (HEAD -> master) Merge feature",
+		"|\\",
+		"| * " + short(f) + " (feature) Feature change",
+		"* | " + short(a) + " (tag: v1) Master change",
+		"|/",
+		"* " + short(initial) + " Initial commit",
+	}
+	if got := strings.Join(commits, "\n"); got != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected graph:\n%s\nexpected:\n%s", got, strings.Join(expected, "\n"))
+	}
+
+	// Block output lists parents and refs
+	commits, err = ops.Log(tempDir, LogOptions{MaxCount: 1, Parents: true, Decorate: true})
+	if err != nil {
+		t.Fatalf("Log failed: %v", err)
+	}
+	if !contains(commits[0], "Parents: "+a.String()+" "+f.String()) || !contains(commits[0], "Refs: HEAD -> master") {
+		t.Errorf("Unexpected commit: %s", commits[0])
+	}
+
+	if _, err := ops.Log(tempDir, LogOptions{MaxCount: 10, Graph: true, Grep: "x"}); err == nil {
+		t.Error("Expected graph with grep filter to fail")
+	}
+}
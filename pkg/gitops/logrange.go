@@ -0,0 +1,195 @@
+package gitops
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// parseRevisionRange splits a revision range of the form A..B (reachable
+// from B but not A) or A...B (reachable from either but not both). An empty
+// side means HEAD. A plain revision is returned as to with isRange false.
+func parseRevisionRange(spec string) (from, to string, symmetric, isRange bool) {
+	if i := strings.Index(spec, "..."); i >= 0 {
+		return orHead(spec[:i]), orHead(spec[i+3:]), true, true
+	}
+	if i := strings.Index(spec, ".."); i >= 0 {
+		return orHead(spec[:i]), orHead(spec[i+2:]), false, true
+	}
+	return "", spec, false, false
+}
+
+func orHead(revision string) string {
+	if revision == "" {
+		return "HEAD"
+	}
+	return revision
+}
+
+// logIterator returns the commits selected by the revision options of a log
+// request: a range given as opts.Range or opts.From/opts.To, a single
+// revision, or HEAD
+func logIterator(repo *git.Repository, opts LogOptions) (object.CommitIter, error) {
+	from, to, symmetric, isRange := parseRevisionRange(opts.Range)
+	if opts.From != "" || opts.To != "" {
+		if opts.Range != "" {
+			return nil, fmt.Errorf("range cannot be combined with from/to")
+		}
+		from, to, isRange = opts.From, orHead(opts.To), opts.From != ""
+	}
+
+	resolve := func(revision string) (plumbing.Hash, error) {
+		hash, err := resolveRevision(repo, revision)
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to resolve revision '%s': %w", revision, err)
+		}
+		return *hash, nil
+	}
+
+	if !isRange {
+		logOptions := &git.LogOptions{}
+		if opts.Graph {
+			// Children must be drawn before their parents
+			logOptions.Order = git.LogOrderCommitterTime
+		}
+		if to != "" {
+			hash, err := resolve(to)
+			if err != nil {
+				return nil, err
+			}
+			logOptions.From = hash
+		}
+
+		iter, err := repo.Log(logOptions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get log: %w", err)
+		}
+		return iter, nil
+	}
+
+	fromHash, err := resolve(from)
+	if err != nil {
+		return nil, err
+	}
+	toHash, err := resolve(to)
+	if err != nil {
+		return nil, err
+	}
+
+	include := []plumbing.Hash{toHash}
+	exclude := []plumbing.Hash{fromHash}
+	if symmetric {
+		include = append(include, fromHash)
+		exclude, err = mergeBases(repo, fromHash, toHash)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	commits, err := rangeCommits(repo, include, exclude)
+	if err != nil {
+		return nil, err
+	}
+	return &commitSliceIter{commits: commits}, nil
+}
+
+// mergeBases returns the best common ancestors of two commits
+func mergeBases(repo *git.Repository, a, b plumbing.Hash) ([]plumbing.Hash, error) {
+	first, err := repo.CommitObject(a)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit %s: %w", a.String()[:7], err)
+	}
+	second, err := repo.CommitObject(b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit %s: %w", b.String()[:7], err)
+	}
+
+	bases, err := first.MergeBase(second)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find merge base: %w", err)
+	}
+
+	hashes := make([]plumbing.Hash, 0, len(bases))
+	for _, base := range bases {
+		hashes = append(hashes, base.Hash)
+	}
+	return hashes, nil
+}
+
+// rangeCommits returns the commits reachable from any of include but from
+// none of exclude, newest first by committer time
+func rangeCommits(repo *git.Repository, include, exclude []plumbing.Hash) ([]*object.Commit, error) {
+	excluded := make(map[plumbing.Hash]bool)
+	for _, hash := range exclude {
+		reachable, err := reachableCommits(repo, hash)
+		if err != nil {
+			return nil, err
+		}
+		for h := range reachable {
+			excluded[h] = true
+		}
+	}
+
+	var commits []*object.Commit
+	for _, hash := range include {
+		start, err := repo.CommitObject(hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get commit %s: %w", hash.String()[:7], err)
+		}
+
+		err = object.NewCommitPreorderIter(start, excluded, nil).ForEach(func(c *object.Commit) error {
+			commits = append(commits, c)
+			excluded[c.Hash] = true
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to walk history: %w", err)
+		}
+	}
+
+	sort.SliceStable(commits, func(i, j int) bool {
+		return commits[i].Committer.When.After(commits[j].Committer.When)
+	})
+
+	return commits, nil
+}
+
+// commitSliceIter iterates over an in-memory list of commits
+type commitSliceIter struct {
+	commits []*object.Commit
+	pos     int
+}
+
+func (it *commitSliceIter) Next() (*object.Commit, error) {
+	if it.pos >= len(it.commits) {
+		return nil, io.EOF
+	}
+	c := it.commits[it.pos]
+	it.pos++
+	return c, nil
+}
+
+func (it *commitSliceIter) ForEach(cb func(*object.Commit) error) error {
+	for {
+		c, err := it.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err := cb(c); err != nil {
+			if err == storer.ErrStop {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+func (it *commitSliceIter) Close() {
+	it.pos = len(it.commits)
+}
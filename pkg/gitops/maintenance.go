@@ -0,0 +1,188 @@
+package gitops
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MaintenanceOptions selects the maintenance Maintenance runs
+type MaintenanceOptions struct {
+	// Task is gc (the default), auto for a gc only when git finds too many
+	// loose objects or packs, repack to pack all the objects into a single
+	// pack, prune to delete the unreachable loose objects, or commit-graph
+	// to write the commit-graph speeding up history and ancestry queries
+	Task string
+	// PruneExpire only prunes unreachable objects older than this date, like
+	// 2.weeks.ago (the git default), now or never. Used by gc and prune.
+	PruneExpire string
+	// Aggressive makes gc spend more time for smaller packs
+	Aggressive bool
+}
+
+// objectStats are the object statistics of git count-objects -v
+type objectStats struct {
+	loose, looseSize  int64
+	packed, packs     int64
+	packSize          int64
+	prunable, garbage int64
+	garbageSize       int64
+}
+
+// Maintenance compacts the object database of a repository with the git
+// executable, reporting the object counts and sizes before and after
+func (g *Operations) Maintenance(repoPath string, opts MaintenanceOptions) (string, error) {
+	if _, _, err := findGitDir(repoPath); err != nil {
+		return "", err
+	}
+	if strings.HasPrefix(opts.PruneExpire, "-") {
+		return "", fmt.Errorf("invalid prune_expire %s", opts.PruneExpire)
+	}
+
+	var args []string
+	switch opts.Task {
+	case "", "gc":
+		args = []string{"gc", "--quiet"}
+		if opts.Aggressive {
+			args = append(args, "--aggressive")
+		}
+		if opts.PruneExpire != "" {
+			args = append(args, "--prune="+opts.PruneExpire)
+		}
+	case "auto", "repack":
+		if opts.Aggressive || opts.PruneExpire != "" {
+			return "", fmt.Errorf("aggressive and prune_expire are not supported by task %s", opts.Task)
+		}
+		if opts.Task == "auto" {
+			args = []string{"gc", "--auto", "--quiet"}
+		} else {
+			args = []string{"repack", "-a", "-d", "-q"}
+		}
+	case "commit-graph":
+		if opts.Aggressive || opts.PruneExpire != "" {
+			return "", fmt.Errorf("aggressive and prune_expire are not supported by task %s", opts.Task)
+		}
+		return g.writeCommitGraph(repoPath)
+	case "prune":
+		if opts.Aggressive {
+			return "", fmt.Errorf("aggressive is not supported by task prune")
+		}
+		args = []string{"prune"}
+		if opts.PruneExpire != "" {
+			args = append(args, "--expire="+opts.PruneExpire)
+		}
+	default:
+		return "", fmt.Errorf("invalid task %s, expected gc, auto, repack, prune or commit-graph", opts.Task)
+	}
+
+	before, err := g.objectStats(repoPath)
+	if err != nil {
+		return "", err
+	}
+	if output, err := g.runCommand(g.gitCommand(repoPath, args...)); err != nil {
+		if _, ok := err.(*DubiousOwnershipError); ok {
+			return "", err
+		}
+		return "", fmt.Errorf("failed to run git %s: %w\n%s", args[0], err, strings.TrimSpace(string(output)))
+	}
+	after, err := g.objectStats(repoPath)
+	if err != nil {
+		return "", err
+	}
+
+	task := opts.Task
+	if task == "" {
+		task = "gc"
+	}
+	if after == before {
+		if task == "auto" {
+			return fmt.Sprintf("No maintenance needed in %s\n%s", repoPath, after.describe()), nil
+		}
+		return fmt.Sprintf("Ran %s in %s, nothing changed\n%s", task, repoPath, after.describe()), nil
+	}
+
+	var result strings.Builder
+	fmt.Fprintf(&result, "Ran %s in %s", task, repoPath)
+	fmt.Fprintf(&result, "\n  Loose objects: %d (%s) -> %d (%s)", before.loose, formatBytes(before.looseSize), after.loose, formatBytes(after.looseSize))
+	fmt.Fprintf(&result, "\n  Packed objects: %d in %d pack(s) (%s) -> %d in %d pack(s) (%s)",
+		before.packed, before.packs, formatBytes(before.packSize), after.packed, after.packs, formatBytes(after.packSize))
+	if before.garbage > 0 || after.garbage > 0 {
+		fmt.Fprintf(&result, "\n  Garbage files: %d (%s) -> %d (%s)", before.garbage, formatBytes(before.garbageSize), after.garbage, formatBytes(after.garbageSize))
+	}
+	total, afterTotal := before.size(), after.size()
+	fmt.Fprintf(&result, "\n  Total: %s -> %s", formatBytes(total), formatBytes(afterTotal))
+	if afterTotal < total {
+		fmt.Fprintf(&result, " (%s saved)", formatBytes(total-afterTotal))
+	}
+	return result.String(), nil
+}
+
+// writeCommitGraph writes the commit-graph of the commits reachable from
+// the refs of a repository, replacing the previous one
+func (g *Operations) writeCommitGraph(repoPath string) (string, error) {
+	args := []string{"commit-graph", "write", "--reachable"}
+	if output, err := g.runCommand(g.gitCommand(repoPath, args...)); err != nil {
+		if _, ok := err.(*DubiousOwnershipError); ok {
+			return "", err
+		}
+		return "", fmt.Errorf("failed to run git %s: %w\n%s", args[0], err, strings.TrimSpace(string(output)))
+	}
+
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+	commits, ok := hasCommitGraph(repo)
+	if !ok {
+		return fmt.Sprintf("Ran commit-graph in %s, no commit-graph was written (no commits reachable from the refs)", repoPath), nil
+	}
+	return fmt.Sprintf("Wrote the commit-graph of %s: %d commits", repoPath, commits), nil
+}
+
+// objectStats counts the objects of a repository
+func (g *Operations) objectStats(repoPath string) (objectStats, error) {
+	var stats objectStats
+	output, err := g.runCommand(g.gitCommand(repoPath, "count-objects", "-v"))
+	if err != nil {
+		if _, ok := err.(*DubiousOwnershipError); ok {
+			return stats, err
+		}
+		return stats, fmt.Errorf("failed to count objects: %w\n%s", err, strings.TrimSpace(string(output)))
+	}
+
+	// Sizes are reported in KiB
+	fields := map[string]*int64{
+		"count": &stats.loose, "size": &stats.looseSize,
+		"in-pack": &stats.packed, "packs": &stats.packs, "size-pack": &stats.packSize,
+		"prune-packable": &stats.prunable, "garbage": &stats.garbage, "size-garbage": &stats.garbageSize,
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		name, value, ok := strings.Cut(line, ": ")
+		if field, known := fields[name]; ok && known {
+			*field, _ = strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		}
+	}
+	stats.looseSize *= 1024
+	stats.packSize *= 1024
+	stats.garbageSize *= 1024
+	return stats, nil
+}
+
+// size is the size of the object database
+func (s objectStats) size() int64 {
+	return s.looseSize + s.packSize + s.garbageSize
+}
+
+// describe summarizes the object statistics
+func (s objectStats) describe() string {
+	result := fmt.Sprintf("  Loose objects: %d (%s)\n  Packed objects: %d in %d pack(s) (%s)",
+		s.loose, formatBytes(s.looseSize), s.packed, s.packs, formatBytes(s.packSize))
+	if s.prunable > 0 {
+		result += fmt.Sprintf("\n  Loose objects also in packs: %d", s.prunable)
+	}
+	if s.garbage > 0 {
+		result += fmt.Sprintf("\n  Garbage files: %d (%s)", s.garbage, formatBytes(s.garbageSize))
+	}
+	return result
+}
@@ -0,0 +1,60 @@
+package gitops
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestOperations_Maintenance(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not available")
+	}
+	tempDir, repo := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+	commitFile(t, ops, tempDir, "second.txt", "second\n", "Second commit")
+
+	result, err := ops.Maintenance(tempDir, MaintenanceOptions{Task: "auto"})
+	if err != nil {
+		t.Fatalf("Maintenance auto failed: %v", err)
+	}
+	if !strings.HasPrefix(result, "No maintenance needed in ") {
+		t.Errorf("Unexpected auto result:\n%s", result)
+	}
+
+	result, err = ops.Maintenance(tempDir, MaintenanceOptions{})
+	if err != nil {
+		t.Fatalf("Maintenance gc failed: %v", err)
+	}
+	if !strings.HasPrefix(result, "Ran gc in ") || !strings.Contains(result, "-> 0 (0 B)") || !strings.Contains(result, "-> 6 in 1 pack(s)") {
+		t.Errorf("Unexpected gc result:\n%s", result)
+	}
+
+	// An unreachable object is only pruned once expired
+	if _, err := writeBlobObject(repo, []byte("unreachable\n")); err != nil {
+		t.Fatalf("Failed to write blob: %v", err)
+	}
+	if result, _ := ops.Maintenance(tempDir, MaintenanceOptions{Task: "prune", PruneExpire: "1.hour.ago"}); !strings.Contains(result, "nothing changed") {
+		t.Errorf("Expected the recent object to be kept:\n%s", result)
+	}
+	result, err = ops.Maintenance(tempDir, MaintenanceOptions{Task: "prune", PruneExpire: "now"})
+	if err != nil {
+		t.Fatalf("Maintenance prune failed: %v", err)
+	}
+	if !strings.Contains(result, "Loose objects: 1 (") || !strings.Contains(result, "-> 0 (0 B)") {
+		t.Errorf("Unexpected prune result:\n%s", result)
+	}
+
+	if _, err := ops.Maintenance(tempDir, MaintenanceOptions{Task: "optimize"}); err == nil {
+		t.Error("Expected error for an unknown task")
+	}
+	if _, err := ops.Maintenance(tempDir, MaintenanceOptions{Task: "repack", PruneExpire: "now"}); err == nil {
+		t.Error("Expected error for prune_expire with repack")
+	}
+	if _, err := ops.Maintenance(tempDir, MaintenanceOptions{PruneExpire: "--all"}); err == nil {
+		t.Error("Expected error for an option as prune_expire")
+	}
+}
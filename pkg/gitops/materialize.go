@@ -0,0 +1,63 @@
+package gitops
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// MaterializeRevision checks out the tree of a revision into a new scratch
+// directory without touching the repository's worktree, index or HEAD. It
+// returns the path of the directory and the resolved commit hash.
+func (g *Operations) MaterializeRevision(repoPath, revision string) (string, string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	if revision == "" {
+		revision = "HEAD"
+	}
+
+	hash, err := resolveRevision(repo, revision)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve revision '%s': %w", revision, err)
+	}
+
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get commit %s: %w", revision, err)
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get tree: %w", err)
+	}
+
+	dir, err := g.scratch.Create(fmt.Sprintf("%s-%s", filepath.Base(repoPath), hash.String()[:7]))
+	if err != nil {
+		return "", "", err
+	}
+
+	err = tree.Files().ForEach(func(f *object.File) error {
+		return writeBlob(repo, filepath.Join(dir, filepath.FromSlash(f.Name)), f.Hash, f.Mode)
+	})
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", "", fmt.Errorf("failed to materialize %s: %w", revision, err)
+	}
+
+	return dir, hash.String(), nil
+}
+
+// RemoveMaterialized deletes a directory created by MaterializeRevision
+func (g *Operations) RemoveMaterialized(path string) (string, error) {
+	if err := g.scratch.Remove(filepath.Clean(path)); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Removed %s", path), nil
+}
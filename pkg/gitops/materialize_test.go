@@ -0,0 +1,48 @@
+package gitops
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOperations_MaterializeRevision(t *testing.T) {
+	tempDir, _ := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+	ops.SetScratch(NewScratch(t.TempDir(), 0))
+
+	// Modify the worktree so we can check it is left alone
+	testFile := filepath.Join(tempDir, "test.txt")
+	if err := os.WriteFile(testFile, []byte("modified"), 0644); err != nil {
+		t.Fatalf("Failed to modify file: %v", err)
+	}
+
+	dir, hash, err := ops.MaterializeRevision(tempDir, "HEAD")
+	if err != nil {
+		t.Fatalf("MaterializeRevision failed: %v", err)
+	}
+	if len(hash) != 40 {
+		t.Errorf("Expected full commit hash, got: %s", hash)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, "test.txt"))
+	if err != nil || string(content) != "test content" {
+		t.Errorf("Expected committed content in materialized tree, got: %s (%v)", content, err)
+	}
+	content, _ = os.ReadFile(testFile)
+	if string(content) != "modified" {
+		t.Errorf("Expected worktree to be untouched, got: %s", content)
+	}
+
+	if _, err := ops.RemoveMaterialized(tempDir); err == nil {
+		t.Error("Expected error when removing a path outside the scratch directory")
+	}
+	if _, err := ops.RemoveMaterialized(dir); err != nil {
+		t.Fatalf("RemoveMaterialized failed: %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Expected materialized directory to be removed")
+	}
+}
@@ -0,0 +1,257 @@
+package gitops
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/pengcunfu/go-mcp-git/internal/tracing"
+)
+
+// multiRepoConcurrency bounds how many repositories are processed at once by
+// the multi-repository operations
+const multiRepoConcurrency = 8
+
+// forEachRepo runs fn for every repository concurrently and returns the
+// results in the order of repoPaths. A failure is reported as the result of
+// that repository instead of aborting the others.
+func forEachRepo(repoPaths []string, fn func(repoPath string) (string, error)) []string {
+	results := make([]string, len(repoPaths))
+	sem := make(chan struct{}, multiRepoConcurrency)
+
+	var wg sync.WaitGroup
+	for i, repoPath := range repoPaths {
+		wg.Add(1)
+		go func(i int, repoPath string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			result, err := fn(repoPath)
+			if err != nil {
+				result = fmt.Sprintf("%s: error: %v", repoPath, err)
+			}
+			results[i] = result
+		}(i, repoPath)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// upstreamOf returns the remote-tracking reference configured as the
+// upstream of a local branch, and its short name (e.g. origin/main)
+func upstreamOf(repo *git.Repository, branch string) (plumbing.ReferenceName, string, bool) {
+	cfg, err := repo.Config()
+	if err != nil {
+		return "", "", false
+	}
+
+	b, ok := cfg.Branches[branch]
+	if !ok || b.Remote == "" || !b.Merge.IsBranch() {
+		return "", "", false
+	}
+
+	// A branch tracking another local branch uses the "." remote
+	if b.Remote == "." {
+		return b.Merge, b.Merge.Short(), true
+	}
+
+	name := plumbing.NewRemoteReferenceName(b.Remote, b.Merge.Short())
+	return name, b.Remote + "/" + b.Merge.Short(), true
+}
+
+// aheadBehind counts the commits local has that upstream does not (ahead)
+// and the commits upstream has that local does not (behind)
+func aheadBehind(repo *git.Repository, local, upstream plumbing.Hash) (int, int, error) {
+	ahead, err := rangeCommits(repo, []plumbing.Hash{local}, []plumbing.Hash{upstream})
+	if err != nil {
+		return 0, 0, err
+	}
+	behind, err := rangeCommits(repo, []plumbing.Hash{upstream}, []plumbing.Hash{local})
+	if err != nil {
+		return 0, 0, err
+	}
+	return len(ahead), len(behind), nil
+}
+
+// MultiStatus returns a one-line summary of every repository: current
+// branch, number of modified and untracked files, and how far the branch is
+// ahead of or behind its upstream. Repositories are inspected concurrently.
+func (g *Operations) MultiStatus(repoPaths []string) string {
+	return strings.Join(forEachRepo(repoPaths, g.statusSummary), "\n")
+}
+
+// statusSummary summarizes the state of a single repository
+func (g *Operations) statusSummary(repoPath string) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	var parts []string
+
+	branch, onBranch := currentBranch(repo)
+	head, headErr := repo.Head()
+	switch {
+	case onBranch && headErr != nil:
+		parts = append(parts, fmt.Sprintf("[%s, no commits]", branch))
+	case onBranch:
+		parts = append(parts, fmt.Sprintf("[%s]", branch))
+	case headErr == nil:
+		parts = append(parts, fmt.Sprintf("[detached at %s]", head.Hash().String()[:7]))
+	default:
+		return "", fmt.Errorf("failed to get HEAD: %w", headErr)
+	}
+
+	worktree, err := repo.Worktree()
+	if err == nil {
+		status, err := worktree.Status()
+		if err != nil {
+			return "", fmt.Errorf("failed to get status: %w", err)
+		}
+
+		var modified, untracked int
+		for _, fileStatus := range status {
+			switch {
+			case fileStatus.Worktree == git.Untracked:
+				untracked++
+			case fileStatus.Staging != git.Unmodified || fileStatus.Worktree != git.Unmodified:
+				modified++
+			}
+		}
+		if modified == 0 && untracked == 0 {
+			parts = append(parts, "clean")
+		} else {
+			parts = append(parts, fmt.Sprintf("%d modified, %d untracked", modified, untracked))
+		}
+	} else if err != git.ErrIsBareRepository {
+		return "", fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	if onBranch && headErr == nil {
+		upstream, upstreamName, ok := upstreamOf(repo, branch)
+		if !ok {
+			parts = append(parts, "no upstream")
+		} else if ref, err := repo.Reference(upstream, true); err != nil {
+			parts = append(parts, fmt.Sprintf("upstream %s gone", upstreamName))
+		} else {
+			ahead, behind, err := aheadBehind(repo, head.Hash(), ref.Hash())
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, fmt.Sprintf("ahead %d, behind %d (%s)", ahead, behind, upstreamName))
+		}
+	}
+
+	return fmt.Sprintf("%s %s %s", repoPath, parts[0], strings.Join(parts[1:], "; ")), nil
+}
+
+// MultiSync fetches every remote of every repository concurrently. When
+// pull is true, the current branch is then fast-forwarded to its upstream
+// provided the working tree has no local modifications.
+func (g *Operations) MultiSync(repoPaths []string, pull bool) string {
+	return strings.Join(forEachRepo(repoPaths, func(repoPath string) (string, error) {
+		return g.syncRepo(repoPath, pull)
+	}), "\n")
+}
+
+// syncRepo fetches and optionally fast-forwards a single repository
+func (g *Operations) syncRepo(repoPath string, pull bool) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	remotes, err := repo.Remotes()
+	if err != nil {
+		return "", fmt.Errorf("failed to list remotes: %w", err)
+	}
+	if len(remotes) == 0 {
+		return fmt.Sprintf("%s: no remotes", repoPath), nil
+	}
+
+	var parts []string
+	for _, remote := range remotes {
+		name := remote.Config().Name
+		auth, err := g.remoteAuth(remote, nil)
+		if err == nil {
+			traced, span := g.trace("git.fetch", tracing.KindClient, tracing.String("git.remote", name))
+			err = remote.FetchContext(traced.context(), &git.FetchOptions{Auth: auth})
+			endNetworkSpan(span, err)
+		}
+		switch {
+		case err == git.NoErrAlreadyUpToDate:
+			parts = append(parts, fmt.Sprintf("%s up to date", name))
+		case err != nil:
+			parts = append(parts, fmt.Sprintf("%s fetch failed: %v", name, err))
+		default:
+			parts = append(parts, fmt.Sprintf("fetched %s", name))
+		}
+	}
+
+	if pull {
+		parts = append(parts, fastForward(repo))
+	}
+
+	return fmt.Sprintf("%s: %s", repoPath, strings.Join(parts, "; ")), nil
+}
+
+// fastForward moves the current branch to its upstream when that is a fast
+// forward and the working tree has no local modifications. It returns a
+// description of what happened.
+func fastForward(repo *git.Repository) string {
+	branch, ok := currentBranch(repo)
+	if !ok {
+		return "not on a branch, skipped pull"
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return fmt.Sprintf("%s has no commits, skipped pull", branch)
+	}
+
+	upstream, upstreamName, ok := upstreamOf(repo, branch)
+	if !ok {
+		return fmt.Sprintf("%s has no upstream, skipped pull", branch)
+	}
+	ref, err := repo.Reference(upstream, true)
+	if err != nil {
+		return fmt.Sprintf("upstream %s not found, skipped pull", upstreamName)
+	}
+
+	if ref.Hash() == head.Hash() {
+		return fmt.Sprintf("%s already up to date with %s", branch, upstreamName)
+	}
+
+	canFastForward, err := isAncestor(repo, head.Hash(), ref.Hash())
+	if err != nil {
+		return fmt.Sprintf("pull failed: %v", err)
+	}
+	if !canFastForward {
+		return fmt.Sprintf("%s has diverged from %s, skipped pull", branch, upstreamName)
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return fmt.Sprintf("pull failed: %v", err)
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return fmt.Sprintf("pull failed: %v", err)
+	}
+	for _, fileStatus := range status {
+		if fileStatus.Worktree != git.Untracked && (fileStatus.Staging != git.Unmodified || fileStatus.Worktree != git.Unmodified) {
+			return fmt.Sprintf("%s has local modifications, skipped pull", branch)
+		}
+	}
+
+	if err := worktree.Reset(&git.ResetOptions{Commit: ref.Hash(), Mode: git.HardReset}); err != nil {
+		return fmt.Sprintf("pull failed: %v", err)
+	}
+
+	return fmt.Sprintf("fast-forwarded %s %s..%s", branch, head.Hash().String()[:7], ref.Hash().String()[:7])
+}
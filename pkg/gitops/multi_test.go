@@ -0,0 +1,99 @@
+package gitops
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+)
+
+func TestOperations_MultiStatus(t *testing.T) {
+	origin, _ := createTestRepo(t)
+	defer os.RemoveAll(origin)
+
+	clone, err := os.MkdirTemp("", "git-clone-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(clone)
+
+	if _, err := git.PlainClone(clone, false, &git.CloneOptions{URL: origin}); err != nil {
+		t.Fatalf("Clone failed: %v", err)
+	}
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	// The clone is one commit ahead, the origin has an untracked file
+	commitFile(t, ops, clone, "local.txt", "local\n", "Local change")
+	if err := os.WriteFile(filepath.Join(origin, "untracked.txt"), []byte("new"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	missing := filepath.Join(origin, "missing")
+	lines := strings.Split(ops.MultiStatus([]string{origin, clone, missing}), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got: %v", lines)
+	}
+
+	if lines[0] != origin+" [master] 0 modified, 1 untracked; no upstream" {
+		t.Errorf("Unexpected origin summary: %s", lines[0])
+	}
+	if lines[1] != clone+" [master] clean; ahead 1, behind 0 (origin/master)" {
+		t.Errorf("Unexpected clone summary: %s", lines[1])
+	}
+	if !strings.HasPrefix(lines[2], missing+": error:") {
+		t.Errorf("Expected error for missing repository, got: %s", lines[2])
+	}
+}
+
+func TestOperations_MultiSync(t *testing.T) {
+	origin, _ := createTestRepo(t)
+	defer os.RemoveAll(origin)
+
+	clone, err := os.MkdirTemp("", "git-clone-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(clone)
+
+	if _, err := git.PlainClone(clone, false, &git.CloneOptions{URL: origin}); err != nil {
+		t.Fatalf("Clone failed: %v", err)
+	}
+
+	ops := NewOperations("Test User", "test@example.com")
+	commitFile(t, ops, origin, "upstream.txt", "upstream\n", "Upstream change")
+
+	// Fetch only leaves the working tree alone
+	lines := strings.Split(ops.MultiSync([]string{clone, origin}, false), "\n")
+	if lines[0] != clone+": fetched origin" {
+		t.Errorf("Unexpected clone result: %s", lines[0])
+	}
+	if lines[1] != origin+": no remotes" {
+		t.Errorf("Unexpected origin result: %s", lines[1])
+	}
+	if _, err := os.Stat(filepath.Join(clone, "upstream.txt")); !os.IsNotExist(err) {
+		t.Error("Expected fetch not to update the working tree")
+	}
+
+	// Local modifications block the fast-forward
+	if err := os.WriteFile(filepath.Join(clone, "test.txt"), []byte("local edit"), 0644); err != nil {
+		t.Fatalf("Failed to modify file: %v", err)
+	}
+	result := ops.MultiSync([]string{clone}, true)
+	if !strings.Contains(result, "origin up to date; master has local modifications, skipped pull") {
+		t.Errorf("Unexpected result: %s", result)
+	}
+
+	if _, err := ops.Restore(clone, []string{"test.txt"}, "", false, true); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	result = ops.MultiSync([]string{clone}, true)
+	if !strings.Contains(result, "fast-forwarded master") {
+		t.Errorf("Unexpected result: %s", result)
+	}
+	if _, err := os.Stat(filepath.Join(clone, "upstream.txt")); err != nil {
+		t.Errorf("Expected upstream.txt after fast-forward: %v", err)
+	}
+}
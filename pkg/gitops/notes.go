@@ -0,0 +1,318 @@
+package gitops
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// defaultNotesRef is the notes reference git notes uses by default
+const defaultNotesRef = "refs/notes/commits"
+
+// NoteOptions selects how AddNote treats an existing note
+type NoteOptions struct {
+	// Ref is the notes reference, refs/notes/commits by default. A short
+	// name like review stands for refs/notes/review.
+	Ref string
+	// Force replaces an existing note
+	Force bool
+	// Append adds the message to an existing note after a blank line
+	Append bool
+}
+
+// notes is the content of a notes reference: the note blobs keyed by their
+// path, which is the hash of the annotated object, possibly split into
+// fanout directories like ab/cdef...
+type notes struct {
+	ref    plumbing.ReferenceName
+	files  map[string]treeFile
+	parent *object.Commit
+}
+
+// notesRefName returns the full name of a notes reference
+func notesRefName(ref string) plumbing.ReferenceName {
+	switch {
+	case ref == "":
+		return defaultNotesRef
+	case strings.HasPrefix(ref, "refs/"):
+		return plumbing.ReferenceName(ref)
+	default:
+		return plumbing.ReferenceName("refs/notes/" + ref)
+	}
+}
+
+// readNotes reads a notes reference, which need not exist yet
+func readNotes(repo *git.Repository, ref string) (*notes, error) {
+	n := &notes{ref: notesRefName(ref), files: make(map[string]treeFile)}
+
+	tip, err := repo.Reference(n.ref, true)
+	if err == plumbing.ErrReferenceNotFound {
+		return n, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", n.ref, err)
+	}
+	n.parent, err = repo.CommitObject(tip.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", n.ref, err)
+	}
+	tree, err := n.parent.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", n.ref, err)
+	}
+	n.files, err = flattenTree(tree)
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// path returns the path of the note of an object
+func (n *notes) path(hash plumbing.Hash) (string, bool) {
+	name := hash.String()
+	if _, ok := n.files[name]; ok {
+		return name, true
+	}
+	for path := range n.files {
+		if strings.ReplaceAll(path, "/", "") == name {
+			return path, true
+		}
+	}
+	return name, false
+}
+
+// content returns the note of an object, false without one
+func (n *notes) content(repo *git.Repository, hash plumbing.Hash) (string, bool, error) {
+	path, ok := n.path(hash)
+	if !ok {
+		return "", false, nil
+	}
+	content, err := readBlob(repo, n.files[path].hash)
+	if err != nil {
+		return "", false, err
+	}
+	return content, true, nil
+}
+
+// readBlob returns the content of a blob
+func readBlob(repo *git.Repository, hash plumbing.Hash) (string, error) {
+	blob, err := repo.BlobObject(hash)
+	if err != nil {
+		return "", fmt.Errorf("failed to read blob %s: %w", hash, err)
+	}
+	reader, err := blob.Reader()
+	if err != nil {
+		return "", fmt.Errorf("failed to read blob %s: %w", hash, err)
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to read blob %s: %w", hash, err)
+	}
+	return string(content), nil
+}
+
+// AddNote attaches a note to a commit, like git notes add. An existing note
+// is kept unless replaced with Force or extended with Append.
+func (g *Operations) AddNote(repoPath, revision, message string, opts NoteOptions) (string, error) {
+	if strings.TrimSpace(message) == "" {
+		return "", fmt.Errorf("note message cannot be empty")
+	}
+	if opts.Force && opts.Append {
+		return "", fmt.Errorf("force and append cannot be combined")
+	}
+
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+	if revision == "" {
+		revision = "HEAD"
+	}
+	commit, err := resolveCommit(repo, revision)
+	if err != nil {
+		return "", err
+	}
+
+	n, err := readNotes(repo, opts.Ref)
+	if err != nil {
+		return "", err
+	}
+	existing, exists, err := n.content(repo, commit.Hash)
+	if err != nil {
+		return "", err
+	}
+
+	content := strings.TrimRight(message, "\n") + "\n"
+	command := "add"
+	switch {
+	case exists && opts.Append:
+		content = strings.TrimRight(existing, "\n") + "\n\n" + content
+		command = "append"
+	case exists && !opts.Force:
+		return "", fmt.Errorf("%s already has a note in %s, use force to replace it or append to extend it", commit.Hash.String()[:7], n.ref.Short())
+	}
+
+	hash, err := writeBlobObject(repo, []byte(content))
+	if err != nil {
+		return "", err
+	}
+	path, _ := n.path(commit.Hash)
+	n.files[path] = treeFile{hash: hash, mode: filemode.Regular}
+	if _, err := n.commit(g, repo, fmt.Sprintf("Notes added by 'git notes %s'", command)); err != nil {
+		return "", err
+	}
+
+	verb := "Added note to"
+	switch {
+	case command == "append":
+		verb = "Appended to the note of"
+	case exists:
+		verb = "Replaced the note of"
+	}
+	return fmt.Sprintf("%s %s %s (%s)", verb, commit.Hash.String()[:7], commitSubject(commit), n.ref.Short()), nil
+}
+
+// commit records the notes as a new commit of their reference
+func (n *notes) commit(g *Operations, repo *git.Repository, message string) (plumbing.Hash, error) {
+	treeHash, err := buildTree(repo, n.files)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	signature := g.getUserSignature()
+	commit := &object.Commit{
+		Author:    *signature,
+		Committer: *signature,
+		Message:   message + "\n",
+		TreeHash:  treeHash,
+	}
+	if n.parent != nil {
+		commit.ParentHashes = []plumbing.Hash{n.parent.Hash}
+	}
+	hash, err := writeCommitObject(repo, commit)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(n.ref, hash)); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to update %s: %w", n.ref, err)
+	}
+	return hash, nil
+}
+
+// ShowNote returns the note attached to a commit
+func (g *Operations) ShowNote(repoPath, revision, ref string) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+	if revision == "" {
+		revision = "HEAD"
+	}
+	commit, err := resolveCommit(repo, revision)
+	if err != nil {
+		return "", err
+	}
+
+	n, err := readNotes(repo, ref)
+	if err != nil {
+		return "", err
+	}
+	content, ok, err := n.content(repo, commit.Hash)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return fmt.Sprintf("No note on %s in %s", commit.Hash.String()[:7], n.ref.Short()), nil
+	}
+	return strings.TrimRight(content, "\n"), nil
+}
+
+// ListNotes lists the objects with a note, most recent commits first, with
+// the first line of their note. maxCount limits the list when positive.
+func (g *Operations) ListNotes(repoPath, ref string, maxCount int) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	n, err := readNotes(repo, ref)
+	if err != nil {
+		return "", err
+	}
+	if len(n.files) == 0 {
+		return fmt.Sprintf("No notes in %s", n.ref.Short()), nil
+	}
+
+	type entry struct {
+		hash   plumbing.Hash
+		commit *object.Commit
+		path   string
+	}
+	var entries []entry
+	for path := range n.files {
+		name := strings.ReplaceAll(path, "/", "")
+		if !plumbing.IsHash(name) {
+			continue
+		}
+		e := entry{hash: plumbing.NewHash(name), path: path}
+		e.commit, _ = repo.CommitObject(e.hash)
+		entries = append(entries, e)
+	}
+	// Commits come first, most recent first, then other annotated objects
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i].commit, entries[j].commit
+		switch {
+		case a != nil && b != nil && !a.Committer.When.Equal(b.Committer.When):
+			return a.Committer.When.After(b.Committer.When)
+		case a != nil && b != nil:
+			// Commits of the same second, as made by scripts, are ordered
+			// by ancestry
+			if isAncestor, _ := b.IsAncestor(a); isAncestor {
+				return true
+			}
+			if isAncestor, _ := a.IsAncestor(b); isAncestor {
+				return false
+			}
+		case (a == nil) != (b == nil):
+			return a != nil
+		}
+		return entries[i].hash.String() < entries[j].hash.String()
+	})
+
+	total := len(entries)
+	if maxCount > 0 && len(entries) > maxCount {
+		entries = entries[:maxCount]
+	}
+
+	var result strings.Builder
+	fmt.Fprintf(&result, "%d note(s) in %s:", total, n.ref.Short())
+	for _, e := range entries {
+		content, err := readBlob(repo, n.files[e.path].hash)
+		if err != nil {
+			return "", err
+		}
+		firstLine, _, _ := strings.Cut(strings.TrimSpace(content), "\n")
+
+		subject := "(not a commit)"
+		if e.commit != nil {
+			subject = commitSubject(e.commit)
+		}
+		fmt.Fprintf(&result, "\n%s %s\n    %s", e.hash.String()[:7], subject, firstLine)
+	}
+	if len(entries) < total {
+		fmt.Fprintf(&result, "\n... %d more", total-len(entries))
+	}
+	return result.String(), nil
+}
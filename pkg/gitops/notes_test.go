@@ -0,0 +1,81 @@
+package gitops
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestOperations_Notes(t *testing.T) {
+	tempDir, repo := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	if result, _ := ops.ListNotes(tempDir, "", 0); result != "No notes in notes/commits" {
+		t.Errorf("Unexpected empty list: %s", result)
+	}
+	if result, _ := ops.ShowNote(tempDir, "", ""); !strings.HasPrefix(result, "No note on ") {
+		t.Errorf("Unexpected missing note: %s", result)
+	}
+
+	result, err := ops.AddNote(tempDir, "HEAD", "Reviewed-by: Jane", NoteOptions{})
+	if err != nil {
+		t.Fatalf("AddNote failed: %v", err)
+	}
+	if !strings.HasPrefix(result, "Added note to ") || !strings.HasSuffix(result, "(notes/commits)") {
+		t.Errorf("Unexpected result: %s", result)
+	}
+	if _, err := ops.AddNote(tempDir, "HEAD", "Again", NoteOptions{}); err == nil {
+		t.Error("Expected error for an existing note")
+	}
+	if _, err := ops.AddNote(tempDir, "HEAD", "Build: passed", NoteOptions{Append: true}); err != nil {
+		t.Fatalf("Appending failed: %v", err)
+	}
+	if note, _ := ops.ShowNote(tempDir, "HEAD", ""); note != "Reviewed-by: Jane\n\nBuild: passed" {
+		t.Errorf("Unexpected note: %q", note)
+	}
+
+	// The notes are readable by git itself
+	if _, err := exec.LookPath("git"); err == nil {
+		output, err := exec.Command("git", "-C", tempDir, "notes", "show", "HEAD").CombinedOutput()
+		if err != nil || string(output) != "Reviewed-by: Jane\n\nBuild: passed\n" {
+			t.Errorf("git notes show failed: %q (%v)", output, err)
+		}
+	}
+
+	// A second commit, and a separate notes reference
+	commitFile(t, ops, tempDir, "second.txt", "second\n", "Second commit")
+	if _, err := ops.AddNote(tempDir, "", "Needs work", NoteOptions{}); err != nil {
+		t.Fatalf("AddNote failed: %v", err)
+	}
+	if _, err := ops.AddNote(tempDir, "HEAD", "Replaced", NoteOptions{Force: true}); err != nil {
+		t.Fatalf("Replacing failed: %v", err)
+	}
+	if _, err := ops.AddNote(tempDir, "HEAD~1", "ci: green", NoteOptions{Ref: "ci"}); err != nil {
+		t.Fatalf("AddNote to refs/notes/ci failed: %v", err)
+	}
+	if _, err := repo.Reference("refs/notes/ci", true); err != nil {
+		t.Errorf("Expected refs/notes/ci: %v", err)
+	}
+
+	result, err = ops.ListNotes(tempDir, "", 0)
+	if err != nil {
+		t.Fatalf("ListNotes failed: %v", err)
+	}
+	lines := strings.Split(result, "\n")
+	if len(lines) != 5 || lines[0] != "2 note(s) in notes/commits:" || !strings.HasSuffix(lines[1], " Second commit") || lines[2] != "    Replaced" || lines[4] != "    Reviewed-by: Jane" {
+		t.Errorf("Unexpected list:\n%s", result)
+	}
+	if result, _ := ops.ListNotes(tempDir, "", 1); !strings.HasSuffix(result, "... 1 more") {
+		t.Errorf("Expected the list to be limited, got:\n%s", result)
+	}
+	if result, _ := ops.ListNotes(tempDir, "refs/notes/ci", 0); !strings.Contains(result, "    ci: green") {
+		t.Errorf("Unexpected ci notes:\n%s", result)
+	}
+
+	if _, err := ops.AddNote(tempDir, "HEAD", " ", NoteOptions{}); err == nil {
+		t.Error("Expected error for an empty note")
+	}
+}
@@ -0,0 +1,129 @@
+package gitops
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// treeFile is a file entry of a tree being built from a flat path listing
+type treeFile struct {
+	hash plumbing.Hash
+	mode filemode.FileMode
+}
+
+// writeBlobObject stores content as a blob object and returns its hash
+func writeBlobObject(repo *git.Repository, content []byte) (plumbing.Hash, error) {
+	obj := repo.Storer.NewEncodedObject()
+	obj.SetType(plumbing.BlobObject)
+	obj.SetSize(int64(len(content)))
+
+	writer, err := obj.Writer()
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to create blob: %w", err)
+	}
+	if _, err := writer.Write(content); err != nil {
+		writer.Close()
+		return plumbing.ZeroHash, fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to write blob: %w", err)
+	}
+
+	hash, err := repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to store blob: %w", err)
+	}
+	return hash, nil
+}
+
+// flattenTree lists every file of a tree keyed by its full path
+func flattenTree(tree *object.Tree) (map[string]treeFile, error) {
+	files := make(map[string]treeFile)
+	if tree == nil {
+		return files, nil
+	}
+
+	err := tree.Files().ForEach(func(f *object.File) error {
+		files[f.Name] = treeFile{hash: f.Hash, mode: f.Mode}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to iterate tree: %w", err)
+	}
+
+	return files, nil
+}
+
+// buildTree writes the tree objects for a flat path listing and returns the
+// hash of the root tree
+func buildTree(repo *git.Repository, files map[string]treeFile) (plumbing.Hash, error) {
+	return buildSubtree(repo, "", files)
+}
+
+func buildSubtree(repo *git.Repository, prefix string, files map[string]treeFile) (plumbing.Hash, error) {
+	var entries []object.TreeEntry
+	subdirs := make(map[string]bool)
+
+	for path, file := range files {
+		if !strings.HasPrefix(path, prefix) {
+			continue
+		}
+		rest := strings.TrimPrefix(path, prefix)
+		if i := strings.Index(rest, "/"); i >= 0 {
+			subdirs[rest[:i]] = true
+			continue
+		}
+		entries = append(entries, object.TreeEntry{Name: rest, Mode: file.mode, Hash: file.hash})
+	}
+
+	for dir := range subdirs {
+		hash, err := buildSubtree(repo, prefix+dir+"/", files)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		entries = append(entries, object.TreeEntry{Name: dir, Mode: filemode.Dir, Hash: hash})
+	}
+
+	// Git orders tree entries by name, comparing directories as if their
+	// name ended with a slash
+	sortKey := func(e object.TreeEntry) string {
+		if e.Mode == filemode.Dir {
+			return e.Name + "/"
+		}
+		return e.Name
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return sortKey(entries[i]) < sortKey(entries[j])
+	})
+
+	obj := repo.Storer.NewEncodedObject()
+	if err := (&object.Tree{Entries: entries}).Encode(obj); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to encode tree: %w", err)
+	}
+
+	hash, err := repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to store tree: %w", err)
+	}
+	return hash, nil
+}
+
+// writeCommitObject stores a commit object and returns its hash
+func writeCommitObject(repo *git.Repository, commit *object.Commit) (plumbing.Hash, error) {
+	obj := repo.Storer.NewEncodedObject()
+	if err := commit.Encode(obj); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to encode commit: %w", err)
+	}
+
+	hash, err := repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to store commit: %w", err)
+	}
+	return hash, nil
+}
@@ -0,0 +1,1538 @@
+package gitops
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"golang.org/x/crypto/ssh"
+)
+
+// Operations provides Git operations
+type Operations struct{
+	userName  string
+	userEmail string
+	scratch   *Scratch
+	// trash keeps the files discarded by destructive operations (nil
+	// disables it)
+	trash *Trash
+
+	// commitPolicy applies to repositories without their own policy
+	commitPolicy *CommitPolicy
+	// auth authenticates fetch and push (nil uses go-git's defaults)
+	auth transport.AuthMethod
+	// signingKey signs the commits and tags requesting it
+	signingKey *openpgp.Entity
+	// sshSigningKey signs them in repositories set up for SSH signing, or
+	// without an OpenPGP key
+	sshSigningKey ssh.Signer
+	// sshAuth authenticates to SSH remotes, go-git's defaults apply when
+	// nil
+	sshAuth *SSHAuth
+
+	// safeDirectories and nonInteractive configure the git executable
+	safeDirectories []string
+	nonInteractive  bool
+
+	// locks serialize the operations modifying a repository, shared by
+	// every Operations of the process
+	locks *RepoLocks
+	// repos keeps the handles of recently used repositories open
+	repos *RepoCache
+
+	// ctx cancels network operations and the git executable, see
+	// WithContext
+	ctx context.Context
+}
+
+// NewOperations creates a new Git operations instance
+func NewOperations(userName, userEmail string) *Operations {
+	return &Operations{
+		userName:  userName,
+		userEmail: userEmail,
+		scratch:   NewScratch("", 0),
+		locks:     processLocks,
+		repos:     NewRepoCache(DefaultRepoCacheSize),
+	}
+}
+
+// SetScratch replaces the scratch directory manager used for temporary
+// working copies
+func (g *Operations) SetScratch(scratch *Scratch) {
+	g.scratch = scratch
+}
+
+// SetTrash sets the trash keeping copies of the files discarded by hard
+// resets and restores. A nil trash discards them for good.
+func (g *Operations) SetTrash(trash *Trash) {
+	g.trash = trash
+}
+
+// SetCommitPolicy sets the commit policy used for repositories that do not
+// configure one themselves. The policy must have been validated.
+func (g *Operations) SetCommitPolicy(policy *CommitPolicy) {
+	g.commitPolicy = policy
+}
+
+// SetAuth sets the authentication used when talking to remotes
+func (g *Operations) SetAuth(auth transport.AuthMethod) {
+	g.auth = auth
+}
+
+// getUserSignature returns the user signature for commits and tags
+func (g *Operations) getUserSignature() *object.Signature {
+	name := g.userName
+	email := g.userEmail
+	
+	// Use default values if not configured
+	if name == "" {
+		name = "MCP Git Server"
+	}
+	if email == "" {
+		email = "mcp-git@example.com"
+	}
+	
+	return &object.Signature{
+		Name:  name,
+		Email: email,
+		When:  time.Now(),
+	}
+}
+
+// DiffUnstaged returns the changes of the working tree not yet staged
+func (g *Operations) DiffUnstaged(repoPath string, opts DiffOptions) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	status, err := worktree.Status()
+	if err != nil {
+		return "", fmt.Errorf("failed to get status: %w", err)
+	}
+
+	entries, err := indexEntries(repo)
+	if err != nil {
+		return "", err
+	}
+	conflicts, err := indexConflicts(repo)
+	if err != nil {
+		return "", err
+	}
+
+	// Compare the index with the working tree, ignoring untracked and
+	// unmerged files
+	var pairs []filePair
+	for file, fileStatus := range status {
+		if fileStatus.Worktree == git.Unmodified || fileStatus.Worktree == git.Untracked || conflicts[file] != nil {
+			continue
+		}
+
+		var from *patchFile
+		if entry, ok := entries[file]; ok {
+			from, err = indexPatchFile(repo, entry)
+			if err != nil {
+				return "", err
+			}
+		}
+		to, err := readWorktreeFile(worktree.Filesystem.Root(), file)
+		if err != nil {
+			return "", err
+		}
+
+		if !sameContent(from, to) {
+			pairs = append(pairs, filePair{from, to})
+		}
+	}
+
+	filePatches, err := buildFilePatches(pairs, opts)
+	if err != nil {
+		return "", err
+	}
+	if len(filePatches) == 0 {
+		return withOperationContext(repo, "no unstaged changes", conflicts), nil
+	}
+
+	rendered, err := renderFilePatches(filePatches, opts)
+	if err != nil {
+		return "", err
+	}
+	return withOperationContext(repo, rendered, conflicts), nil
+}
+
+// DiffStaged returns the changes staged for the next commit
+func (g *Operations) DiffStaged(repoPath string, opts DiffOptions) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	// A nil tree on an unborn branch shows every staged file as added
+	tree, err := headTree(repo)
+	if err != nil {
+		return "", err
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	status, err := worktree.Status()
+	if err != nil {
+		return "", fmt.Errorf("failed to get status: %w", err)
+	}
+
+	entries, err := indexEntries(repo)
+	if err != nil {
+		return "", err
+	}
+	conflicts, err := indexConflicts(repo)
+	if err != nil {
+		return "", err
+	}
+
+	// Compare HEAD with the index, leaving out unmerged files
+	var pairs []filePair
+	for file, fileStatus := range status {
+		if fileStatus.Staging == git.Unmodified || fileStatus.Staging == git.Untracked || conflicts[file] != nil {
+			continue
+		}
+
+		from, err := treePatchFile(tree, file)
+		if err != nil {
+			return "", err
+		}
+		var to *patchFile
+		if entry, ok := entries[file]; ok {
+			to, err = indexPatchFile(repo, entry)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		if !sameContent(from, to) {
+			pairs = append(pairs, filePair{from, to})
+		}
+	}
+
+	filePatches, err := buildFilePatches(pairs, opts)
+	if err != nil {
+		return "", err
+	}
+	if len(filePatches) == 0 {
+		return withOperationContext(repo, "no staged changes", conflicts), nil
+	}
+
+	rendered, err := renderFilePatches(filePatches, opts)
+	if err != nil {
+		return "", err
+	}
+	return withOperationContext(repo, rendered, conflicts), nil
+}
+
+// Diff returns the differences between a revision and the working tree,
+// covering the files tracked in either of them
+func (g *Operations) Diff(repoPath, target string, opts DiffOptions) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	// Resolve target revision
+	targetCommit, err := resolveCommit(repo, target)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve target '%s': %w", target, err)
+	}
+
+	tree, err := targetCommit.Tree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get tree: %w", err)
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	entries, err := indexEntries(repo)
+	if err != nil {
+		return "", err
+	}
+	conflicts, err := indexConflicts(repo)
+	if err != nil {
+		return "", err
+	}
+	// Unmerged files are tracked too, their conflict markers included
+	for name, c := range conflicts {
+		for _, entry := range c.stages {
+			if entry != nil {
+				entries[name] = entry
+			}
+		}
+	}
+
+	committed := make(map[string]*object.File)
+	err = tree.Files().ForEach(func(f *object.File) error {
+		committed[f.Name] = f
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to iterate tree: %w", err)
+	}
+
+	names := make([]string, 0, len(committed)+len(entries))
+	for name := range committed {
+		names = append(names, name)
+	}
+	for name := range entries {
+		if _, ok := committed[name]; !ok {
+			names = append(names, name)
+		}
+	}
+
+	var pairs []filePair
+	for _, name := range names {
+		// Files not in the index are not part of the working tree for git
+		var to *patchFile
+		if _, tracked := entries[name]; tracked {
+			to, err = readWorktreeFile(worktree.Filesystem.Root(), name)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		var from *patchFile
+		if file, ok := committed[name]; ok {
+			if to != nil && file.Hash == to.hash && file.Mode == to.mode {
+				continue
+			}
+			from, err = blobPatchFile(file)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		if !sameContent(from, to) {
+			pairs = append(pairs, filePair{from, to})
+		}
+	}
+
+	filePatches, err := buildFilePatches(pairs, opts)
+	if err != nil {
+		return "", err
+	}
+	if len(filePatches) == 0 {
+		return withOperationContext(repo, fmt.Sprintf("No differences between %s (%s) and the working tree", target, targetCommit.Hash.String()[:7]), nil), nil
+	}
+
+	rendered, err := renderFilePatches(filePatches, opts)
+	if err != nil {
+		return "", err
+	}
+	return withOperationContext(repo, rendered, nil), nil
+}
+
+// Reset resets the current HEAD, the index or individual index entries.
+//
+// mode is one of "soft", "mixed" (default) or "hard". target is an optional
+// revision to reset to (defaults to HEAD). When files is not empty only the
+// index entries of those paths are reset to the target, leaving HEAD and the
+// working tree untouched. A hard reset discards working tree changes and is
+// refused unless confirm is true.
+func (g *Operations) Reset(repoPath, mode, target string, files []string, confirm bool) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	var resetMode git.ResetMode
+	switch mode {
+	case "", "mixed":
+		mode = "mixed"
+		resetMode = git.MixedReset
+	case "soft":
+		resetMode = git.SoftReset
+	case "hard":
+		resetMode = git.HardReset
+	default:
+		return "", fmt.Errorf("invalid reset mode: %s (expected soft, mixed or hard)", mode)
+	}
+
+	// Resolve the target commit, defaulting to HEAD
+	var targetHash plumbing.Hash
+	if target != "" {
+		hash, err := resolveRevision(repo, target)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve target '%s': %w", target, err)
+		}
+		targetHash = *hash
+	} else {
+		head, err := repo.Head()
+		if err != nil {
+			return "", fmt.Errorf("failed to get HEAD: %w", err)
+		}
+		targetHash = head.Hash()
+	}
+
+	if len(files) > 0 {
+		if mode != "mixed" {
+			return "", fmt.Errorf("cannot do a %s reset with paths", mode)
+		}
+
+		unstaged, err := resetIndexPaths(repo, targetHash, files)
+		if err != nil {
+			return "", err
+		}
+		if len(unstaged) == 0 {
+			return "No matching staged changes to reset", nil
+		}
+		return fmt.Sprintf("Unstaged changes for: %s", strings.Join(unstaged, ", ")), nil
+	}
+
+	if resetMode == git.HardReset && !confirm {
+		return "", fmt.Errorf("hard reset discards all uncommitted changes and was not confirmed; git_reset returns a confirmation_token to repeat the call with")
+	}
+
+	var trashed string
+	if resetMode == git.HardReset {
+		trashed, err = g.saveToTrash(repo, "hard reset", pathMatcher([]string{"."}))
+		if err != nil {
+			return "", err
+		}
+	}
+
+	err = worktree.Reset(&git.ResetOptions{
+		Commit: targetHash,
+		Mode:   resetMode,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to reset: %w", err)
+	}
+
+	if target == "" && resetMode == git.MixedReset {
+		return "All staged changes reset", nil
+	}
+
+	result := fmt.Sprintf("Reset (%s) HEAD to %s", mode, targetHash.String()[:7])
+	if trashed != "" {
+		result += "\n" + trashed
+	}
+	return result, nil
+}
+
+// resetIndexPaths resets the index entries matching the given paths to their
+// state in the given commit. A path matches an entry when it is equal to it or
+// is one of its parent directories. It returns the paths that were reset.
+func resetIndexPaths(repo *git.Repository, commitHash plumbing.Hash, paths []string) ([]string, error) {
+	commit, err := repo.CommitObject(commitHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit: %w", err)
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree: %w", err)
+	}
+
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+
+	matches := pathMatcher(paths)
+
+	// Collect candidates from both the index and the target tree so that
+	// newly added files are dropped and deleted files are restored.
+	candidates := make(map[string]bool)
+	for _, entry := range idx.Entries {
+		if matches(entry.Name) {
+			candidates[entry.Name] = true
+		}
+	}
+	err = tree.Files().ForEach(func(f *object.File) error {
+		if matches(f.Name) {
+			candidates[f.Name] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to iterate tree: %w", err)
+	}
+
+	var reset []string
+	for name := range candidates {
+		file, err := tree.File(name)
+		if err == object.ErrFileNotFound {
+			if _, err := idx.Remove(name); err == nil {
+				reset = append(reset, name)
+			}
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from tree: %w", name, err)
+		}
+
+		entry, err := idx.Entry(name)
+		if err != nil {
+			entry = idx.Add(name)
+		} else if entry.Hash == file.Hash && entry.Mode == file.Mode {
+			continue
+		}
+
+		entry.Hash = file.Hash
+		entry.Mode = file.Mode
+		entry.Size = uint32(file.Size)
+		reset = append(reset, name)
+	}
+
+	if err := repo.Storer.SetIndex(idx); err != nil {
+		return nil, fmt.Errorf("failed to write index: %w", err)
+	}
+
+	sort.Strings(reset)
+	return reset, nil
+}
+
+// LogOptions holds the filters applied by Log
+type LogOptions struct {
+	// Range is a revision (default HEAD) or a range A..B / A...B
+	Range string
+	// From and To select the commits reachable from To (default HEAD) but
+	// not from From, as an alternative to Range
+	From string
+	To   string
+
+	// MaxCount limits the number of commits returned (0 means no limit)
+	MaxCount int
+	// StartTimestamp and EndTimestamp bound the committer date, inclusive
+	StartTimestamp string
+	EndTimestamp   string
+	// Path limits the log to commits touching a file or directory
+	Path string
+	// Author is a regular expression matched against "Name <email>"
+	Author string
+	// Grep is a regular expression matched against the commit message
+	Grep string
+
+	// Parents includes the parent hashes of every commit
+	Parents bool
+	// Decorate includes the branches and tags pointing at every commit
+	Decorate bool
+	// Graph renders one line per commit with an ASCII graph of the history
+	Graph bool
+}
+
+// Log returns commit history
+func (g *Operations) Log(repoPath string, opts LogOptions) ([]string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	// The graph needs every commit of the drawn history to connect the lines
+	if opts.Graph && (opts.Path != "" || opts.Author != "" || opts.Grep != "" || opts.StartTimestamp != "" || opts.EndTimestamp != "") {
+		return nil, fmt.Errorf("graph cannot be combined with path, author, grep or timestamp filters")
+	}
+
+	var decorations map[plumbing.Hash][]string
+	if opts.Decorate {
+		decorations, err = refDecorations(repo)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var graph *logGraph
+	if opts.Graph {
+		graph = &logGraph{}
+	}
+
+	// Get commit iterator
+	commitIter, err := logIterator(repo, opts)
+	if err != nil {
+		return nil, err
+	}
+	if path := strings.Trim(filepath.ToSlash(filepath.Clean(opts.Path)), "/"); opts.Path != "" && path != "." {
+		commitIter = object.NewCommitPathIterFromIter(func(name string) bool {
+			return name == path || strings.HasPrefix(name, path+"/")
+		}, commitIter, false)
+	}
+	defer commitIter.Close()
+
+	var authorRe, grepRe *regexp.Regexp
+	if opts.Author != "" {
+		authorRe, err = regexp.Compile(opts.Author)
+		if err != nil {
+			return nil, fmt.Errorf("invalid author pattern: %w", err)
+		}
+	}
+	if opts.Grep != "" {
+		grepRe, err = regexp.Compile(opts.Grep)
+		if err != nil {
+			return nil, fmt.Errorf("invalid grep pattern: %w", err)
+		}
+	}
+
+	var commits []string
+
+	// Parse timestamps if provided
+	var startTime, endTime *time.Time
+	if opts.StartTimestamp != "" {
+		t, err := parseTimestamp(opts.StartTimestamp)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start timestamp: %w", err)
+		}
+		startTime = &t
+	}
+	if opts.EndTimestamp != "" {
+		t, err := parseTimestamp(opts.EndTimestamp)
+		if err != nil {
+			return nil, fmt.Errorf("invalid end timestamp: %w", err)
+		}
+		// A date without a time covers the whole day
+		if isDateOnly(opts.EndTimestamp) {
+			t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+		endTime = &t
+	}
+	if startTime != nil && endTime != nil && startTime.After(*endTime) {
+		return nil, fmt.Errorf("start timestamp %s is after end timestamp %s", opts.StartTimestamp, opts.EndTimestamp)
+	}
+
+	err = commitIter.ForEach(func(commit *object.Commit) error {
+		// Filter by committer date like git log --since/--until; both
+		// bounds are inclusive
+		if startTime != nil && commit.Committer.When.Before(*startTime) {
+			return nil
+		}
+		if endTime != nil && commit.Committer.When.After(*endTime) {
+			return nil
+		}
+
+		// Filter by author and message if provided
+		if authorRe != nil && !authorRe.MatchString(fmt.Sprintf("%s <%s>", commit.Author.Name, commit.Author.Email)) {
+			return nil
+		}
+		if grepRe != nil && !grepRe.MatchString(commit.Message) {
+			return nil
+		}
+
+		var parents []string
+		for _, parent := range commit.ParentHashes {
+			parents = append(parents, parent.String())
+		}
+		refs := decorations[commit.Hash]
+
+		if graph != nil {
+			line := commit.Hash.String()[:7]
+			if opts.Parents {
+				for _, parent := range parents {
+					line += " " + parent[:7]
+				}
+			}
+			if len(refs) > 0 {
+				line += fmt.Sprintf(" (%s)", strings.Join(refs, ", "))
+			}
+
+			prefix, connectors := graph.next(commit)
+			commits = append(commits, strings.Join(append([]string{prefix + " " + line + " " + commitSubject(commit)}, connectors...), "\n"))
+			return limitReached(len(commits), opts.MaxCount)
+		}
+
+		commitStr := fmt.Sprintf("Commit: %s\n", commit.Hash.String())
+		if opts.Parents && len(parents) > 0 {
+			commitStr += fmt.Sprintf("Parents: %s\n", strings.Join(parents, " "))
+		} else if opts.Parents {
+			commitStr += "Parents: none\n"
+		}
+		if len(refs) > 0 {
+			commitStr += fmt.Sprintf("Refs: %s\n", strings.Join(refs, ", "))
+		}
+		commitStr += fmt.Sprintf("Author: %s\nDate: %s\nMessage: %s\n",
+			commit.Author.Name,
+			commit.Author.When.Format(time.RFC3339),
+			strings.TrimSpace(commit.Message))
+
+		commits = append(commits, commitStr)
+		return limitReached(len(commits), opts.MaxCount)
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to iterate commits: %w", err)
+	}
+
+	return commits, nil
+}
+
+// limitReached stops a commit iteration once count commits have been
+// collected. A maxCount of zero or less means no limit.
+func limitReached(count, maxCount int) error {
+	if maxCount > 0 && count >= maxCount {
+		return storer.ErrStop
+	}
+	return nil
+}
+
+// CreateBranch creates a new branch
+func (g *Operations) CreateBranch(repoPath, branchName, baseBranch string) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	var baseRef *plumbing.Reference
+	if baseBranch != "" {
+		baseRef, err = repo.Reference(plumbing.ReferenceName("refs/heads/"+baseBranch), true)
+		if err != nil {
+			return "", fmt.Errorf("failed to find base branch %s: %w", baseBranch, err)
+		}
+	} else {
+		baseRef, err = repo.Head()
+		if err != nil {
+			return "", fmt.Errorf("failed to get HEAD: %w", err)
+		}
+	}
+
+	// Create new branch
+	branchRef := plumbing.NewHashReference(plumbing.ReferenceName("refs/heads/"+branchName), baseRef.Hash())
+	err = repo.Storer.SetReference(branchRef)
+	if err != nil {
+		return "", fmt.Errorf("failed to create branch: %w", err)
+	}
+
+	baseName := "HEAD"
+	if baseBranch != "" {
+		baseName = baseBranch
+	}
+
+	return fmt.Sprintf("Created branch '%s' from '%s'", branchName, baseName), nil
+}
+
+// Checkout switches to a branch, or detaches HEAD at a commit or tag.
+//
+// When create is true a new branch named target is created at startPoint
+// (HEAD by default) and checked out, like git checkout -b. Checking out a
+// remote-tracking branch such as "origin/feature-x", or a branch name that
+// only exists on a single remote, creates a local branch tracking it.
+//
+// Files tracked by Git LFS are checked out as their pointers, which the
+// result lists.
+func (g *Operations) Checkout(repoPath, target string, create bool, startPoint string) (string, error) {
+	result, err := g.checkout(repoPath, target, create, startPoint)
+	if err != nil {
+		return "", err
+	}
+
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+	return result + lfsCheckoutNote(repo, repoPath), nil
+}
+
+// checkout runs Checkout
+func (g *Operations) checkout(repoPath, target string, create bool, startPoint string) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	if target == "" {
+		return "", fmt.Errorf("branch name cannot be empty")
+	}
+
+	if create {
+		branchRef := plumbing.NewBranchReferenceName(target)
+		if _, err := repo.Reference(branchRef, false); err == nil {
+			return "", fmt.Errorf("a branch named '%s' already exists", target)
+		}
+
+		base := startPoint
+		if base == "" {
+			base = "HEAD"
+		}
+		hash, err := resolveRevision(repo, base)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve start point '%s': %w", base, err)
+		}
+
+		err = worktree.Checkout(&git.CheckoutOptions{
+			Hash:   *hash,
+			Branch: branchRef,
+			Create: true,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to checkout new branch: %w", err)
+		}
+
+		result := fmt.Sprintf("Switched to a new branch '%s'", target)
+		if remote, remoteBranch, ok := splitRemoteBranch(repo, startPoint); ok {
+			if err := setUpstream(repo, target, remote, remoteBranch); err != nil {
+				return "", err
+			}
+			result += fmt.Sprintf("\nBranch '%s' set up to track '%s/%s'.", target, remote, remoteBranch)
+		}
+		return result, nil
+	}
+
+	// Existing local branch
+	branchRef := plumbing.NewBranchReferenceName(target)
+	if _, err := repo.Reference(branchRef, false); err == nil {
+		err = worktree.Checkout(&git.CheckoutOptions{
+			Branch: branchRef,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to checkout branch: %w", err)
+		}
+		return fmt.Sprintf("Switched to branch '%s'", target), nil
+	}
+
+	// Remote-tracking branch, either given explicitly or guessed from a
+	// unique remote branch with the same name
+	remote, remoteBranch, ok := splitRemoteBranch(repo, target)
+	if !ok {
+		remote, ok = uniqueRemoteFor(repo, target)
+		remoteBranch = target
+	}
+	if ok {
+		localRef := plumbing.NewBranchReferenceName(remoteBranch)
+		if _, err := repo.Reference(localRef, false); err == nil {
+			err = worktree.Checkout(&git.CheckoutOptions{
+				Branch: localRef,
+			})
+			if err != nil {
+				return "", fmt.Errorf("failed to checkout branch: %w", err)
+			}
+			return fmt.Sprintf("Switched to branch '%s'", remoteBranch), nil
+		}
+
+		remoteRef, err := repo.Reference(plumbing.NewRemoteReferenceName(remote, remoteBranch), true)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve remote branch '%s/%s': %w", remote, remoteBranch, err)
+		}
+
+		err = worktree.Checkout(&git.CheckoutOptions{
+			Hash:   remoteRef.Hash(),
+			Branch: localRef,
+			Create: true,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to checkout branch: %w", err)
+		}
+		if err := setUpstream(repo, remoteBranch, remote, remoteBranch); err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("Switched to a new branch '%s'\nBranch '%s' set up to track '%s/%s'.",
+			remoteBranch, remoteBranch, remote, remoteBranch), nil
+	}
+
+	// Anything else (commit hash, tag, relative revision) detaches HEAD
+	hash, err := resolveRevision(repo, target)
+	if err != nil {
+		return "", fmt.Errorf("'%s' did not match any branch, tag or commit: %w", target, err)
+	}
+
+	err = worktree.Checkout(&git.CheckoutOptions{
+		Hash: *hash,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to checkout %s: %w", target, err)
+	}
+
+	return fmt.Sprintf("HEAD is now at %s (detached from '%s')", hash.String()[:7], target), nil
+}
+
+// splitRemoteBranch splits a name like "origin/feature-x" into remote and
+// branch when it refers to an existing remote-tracking branch
+func splitRemoteBranch(repo *git.Repository, name string) (string, string, bool) {
+	remotes, err := repo.Remotes()
+	if err != nil {
+		return "", "", false
+	}
+
+	for _, remote := range remotes {
+		remoteName := remote.Config().Name
+		if !strings.HasPrefix(name, remoteName+"/") {
+			continue
+		}
+		branch := strings.TrimPrefix(name, remoteName+"/")
+		if _, err := repo.Reference(plumbing.NewRemoteReferenceName(remoteName, branch), false); err == nil {
+			return remoteName, branch, true
+		}
+	}
+
+	return "", "", false
+}
+
+// uniqueRemoteFor returns the remote that has a branch with the given name,
+// provided exactly one remote has it
+func uniqueRemoteFor(repo *git.Repository, branch string) (string, bool) {
+	remotes, err := repo.Remotes()
+	if err != nil {
+		return "", false
+	}
+
+	var found []string
+	for _, remote := range remotes {
+		remoteName := remote.Config().Name
+		if _, err := repo.Reference(plumbing.NewRemoteReferenceName(remoteName, branch), false); err == nil {
+			found = append(found, remoteName)
+		}
+	}
+
+	if len(found) != 1 {
+		return "", false
+	}
+	return found[0], true
+}
+
+// setUpstream configures a local branch to track a remote branch
+func setUpstream(repo *git.Repository, branch, remote, remoteBranch string) error {
+	cfg, err := repo.Config()
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	cfg.Branches[branch] = &config.Branch{
+		Name:   branch,
+		Remote: remote,
+		Merge:  plumbing.NewBranchReferenceName(remoteBranch),
+	}
+
+	if err := repo.SetConfig(cfg); err != nil {
+		return fmt.Errorf("failed to set upstream for '%s': %w", branch, err)
+	}
+	return nil
+}
+
+// ShowOptions holds the parameters of Show
+type ShowOptions struct {
+	Revision string
+	// Paths limits the patch to these files or directories
+	Paths []string
+	DiffOptions
+}
+
+// Show displays a commit and its patch against the first parent. Root
+// commits are diffed against the empty tree.
+func (g *Operations) Show(repoPath string, opts ShowOptions) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	// Resolve revision
+	commit, err := resolveCommit(repo, opts.Revision)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve revision '%s': %w", opts.Revision, err)
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Commit: %s\n", commit.Hash.String()))
+	result.WriteString(fmt.Sprintf("Author: %s\n", commit.Author.Name))
+	result.WriteString(fmt.Sprintf("Date: %s\n", commit.Author.When.Format(time.RFC3339)))
+	result.WriteString(fmt.Sprintf("Message: %s\n\n", strings.TrimSpace(commit.Message)))
+
+	commitTree, err := commit.Tree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get tree: %w", err)
+	}
+
+	// A nil tree stands for the empty tree of a root commit
+	var parentTree *object.Tree
+	switch commit.NumParents() {
+	case 0:
+		result.WriteString("(root commit)\n")
+	case 1:
+	default:
+		result.WriteString(fmt.Sprintf("(merge commit, diff against first parent %s)\n", commit.ParentHashes[0].String()[:7]))
+	}
+	if commit.NumParents() > 0 {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return "", fmt.Errorf("failed to get parent: %w", err)
+		}
+		parentTree, err = parent.Tree()
+		if err != nil {
+			return "", fmt.Errorf("failed to get parent tree: %w", err)
+		}
+	}
+
+	changes, err := object.DiffTree(parentTree, commitTree)
+	if err != nil {
+		return "", fmt.Errorf("failed to diff trees: %w", err)
+	}
+
+	specs := pathSpecs(opts.Paths)
+	var pairs []filePair
+	for _, change := range changes {
+		if !matchesPathSpecs(specs, change.From.Name) && !matchesPathSpecs(specs, change.To.Name) {
+			continue
+		}
+
+		fromFile, toFile, err := change.Files()
+		if err != nil {
+			return "", fmt.Errorf("failed to get changed files: %w", err)
+		}
+		// The files of a change are named after their tree entry alone
+		var pair filePair
+		if fromFile != nil {
+			if pair.from, err = blobPatchFile(fromFile); err != nil {
+				return "", err
+			}
+			pair.from.path = change.From.Name
+		}
+		if toFile != nil {
+			if pair.to, err = blobPatchFile(toFile); err != nil {
+				return "", err
+			}
+			pair.to.path = change.To.Name
+		}
+		pairs = append(pairs, pair)
+	}
+
+	filePatches, err := buildFilePatches(pairs, opts.DiffOptions)
+	if err != nil {
+		return "", err
+	}
+	if len(filePatches) == 0 {
+		if len(specs) > 0 {
+			result.WriteString("No changes in the given paths\n")
+		}
+		return strings.TrimSpace(result.String()), nil
+	}
+
+	rendered, err := renderFilePatches(filePatches, opts.DiffOptions)
+	if err != nil {
+		return "", err
+	}
+	result.WriteString(rendered)
+	return result.String(), nil
+}
+
+// Branch lists branches
+func (g *Operations) Branch(repoPath, branchType, contains, notContains, sortBy string) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	var refs []*plumbing.Reference
+	var result strings.Builder
+
+	switch branchType {
+	case "local":
+		branchRefs, err := repo.Branches()
+		if err != nil {
+			return "", fmt.Errorf("failed to get local branches: %w", err)
+		}
+		err = branchRefs.ForEach(func(ref *plumbing.Reference) error {
+			refs = append(refs, ref)
+			return nil
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to iterate branches: %w", err)
+		}
+
+	case "remote":
+		remoteRefs, err := repo.References()
+		if err != nil {
+			return "", fmt.Errorf("failed to get references: %w", err)
+		}
+		err = remoteRefs.ForEach(func(ref *plumbing.Reference) error {
+			if ref.Name().IsRemote() {
+				refs = append(refs, ref)
+			}
+			return nil
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to iterate remote references: %w", err)
+		}
+
+	case "all":
+		allRefs, err := repo.References()
+		if err != nil {
+			return "", fmt.Errorf("failed to get references: %w", err)
+		}
+		err = allRefs.ForEach(func(ref *plumbing.Reference) error {
+			if ref.Name().IsBranch() || ref.Name().IsRemote() {
+				refs = append(refs, ref)
+			}
+			return nil
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to iterate references: %w", err)
+		}
+
+	default:
+		return "", fmt.Errorf("invalid branch type: %s", branchType)
+	}
+
+	// Filter by commit ancestry, like git branch --contains/--no-contains
+	if contains != "" || notContains != "" {
+		refs, err = filterBranchesByCommit(repo, refs, contains, notContains)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if err := sortRefs(repo, refs, sortBy); err != nil {
+		return "", err
+	}
+
+	// Get current branch
+	head, err := repo.Head()
+	var currentBranch string
+	if err == nil {
+		currentBranch = head.Name().Short()
+	}
+
+	for _, ref := range refs {
+		branchName := ref.Name().Short()
+		if ref.Name().IsRemote() {
+			branchName = strings.TrimPrefix(string(ref.Name()), "refs/remotes/")
+		}
+
+		// Mark current branch
+		prefix := "  "
+		if branchName == currentBranch {
+			prefix = "* "
+		}
+
+		result.WriteString(fmt.Sprintf("%s%s\n", prefix, branchName))
+	}
+
+	return strings.TrimSpace(result.String()), nil
+}
+
+// filterBranchesByCommit keeps the branches that contain the commit
+// contains (when set) and do not contain notContains (when set)
+func filterBranchesByCommit(repo *git.Repository, refs []*plumbing.Reference, contains, notContains string) ([]*plumbing.Reference, error) {
+	resolve := func(rev string) (plumbing.Hash, error) {
+		if rev == "" {
+			return plumbing.ZeroHash, nil
+		}
+		hash, err := resolveRevision(repo, rev)
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to resolve commit '%s': %w", rev, err)
+		}
+		return *hash, nil
+	}
+
+	containsHash, err := resolve(contains)
+	if err != nil {
+		return nil, err
+	}
+	notContainsHash, err := resolve(notContains)
+	if err != nil {
+		return nil, err
+	}
+
+	var filtered []*plumbing.Reference
+	for _, ref := range refs {
+		// Symbolic references such as origin/HEAD point at another branch
+		resolved, err := repo.Reference(ref.Name(), true)
+		if err != nil {
+			continue
+		}
+
+		if !containsHash.IsZero() {
+			ok, err := isAncestor(repo, containsHash, resolved.Hash())
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+		}
+		if !notContainsHash.IsZero() {
+			ok, err := isAncestor(repo, notContainsHash, resolved.Hash())
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				continue
+			}
+		}
+
+		filtered = append(filtered, ref)
+	}
+
+	return filtered, nil
+}
+
+// pathMatcher returns a function reporting whether a repository-relative
+// file name equals one of paths or lies below one of them
+func pathMatcher(paths []string) func(string) bool {
+	cleaned := make([]string, 0, len(paths))
+	for _, p := range paths {
+		cleaned = append(cleaned, strings.TrimSuffix(filepath.ToSlash(filepath.Clean(p)), "/"))
+	}
+
+	return func(name string) bool {
+		for _, p := range cleaned {
+			if p == "." || name == p || strings.HasPrefix(name, p+"/") {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// parseTimestamp parses various timestamp formats
+func parseTimestamp(timestamp string) (time.Time, error) {
+	return parseTimestampAt(timestamp, time.Now())
+}
+
+// relativeUnits maps the units accepted in relative dates to a function
+// moving a time back by n units
+var relativeUnits = map[string]func(t time.Time, n int) time.Time{
+	"second": func(t time.Time, n int) time.Time { return t.Add(-time.Duration(n) * time.Second) },
+	"minute": func(t time.Time, n int) time.Time { return t.Add(-time.Duration(n) * time.Minute) },
+	"hour":   func(t time.Time, n int) time.Time { return t.Add(-time.Duration(n) * time.Hour) },
+	"day":    func(t time.Time, n int) time.Time { return t.AddDate(0, 0, -n) },
+	"week":   func(t time.Time, n int) time.Time { return t.AddDate(0, 0, -7*n) },
+	"month":  func(t time.Time, n int) time.Time { return t.AddDate(0, -n, 0) },
+	"year":   func(t time.Time, n int) time.Time { return t.AddDate(-n, 0, 0) },
+}
+
+// parseTimestampAt parses a timestamp relative to now. Besides absolute
+// formats it accepts Unix epoch seconds ("1700000000" or "@1700000000") and
+// relative dates in the spirit of git's approxidate: "now", "today",
+// "yesterday", "N units ago" (also written "N.units.ago") and "last unit".
+func parseTimestampAt(timestamp string, now time.Time) (time.Time, error) {
+	timestamp = strings.TrimSpace(timestamp)
+
+	// Try different formats
+	formats := []string{
+		time.RFC3339,
+		"2006-01-02T15:04:05",
+		"2006-01-02",
+		"Jan 2 2006",
+	}
+
+	for _, format := range formats {
+		if t, err := time.Parse(format, timestamp); err == nil {
+			return t, nil
+		}
+	}
+
+	// Unix epoch seconds
+	if epoch := strings.TrimPrefix(timestamp, "@"); epoch != "" && strings.Trim(epoch, "0123456789") == "" {
+		if seconds, err := strconv.ParseInt(epoch, 10, 64); err == nil {
+			return time.Unix(seconds, 0), nil
+		}
+	}
+
+	words := strings.Fields(strings.ToLower(strings.NewReplacer(".", " ", "_", " ").Replace(timestamp)))
+	switch {
+	case len(words) == 1 && (words[0] == "now" || words[0] == "today"):
+		return now, nil
+	case len(words) == 1 && words[0] == "yesterday":
+		return now.AddDate(0, 0, -1), nil
+	case len(words) == 2 && words[0] == "last":
+		if back, ok := relativeUnits[strings.TrimSuffix(words[1], "s")]; ok {
+			return back(now, 1), nil
+		}
+	case len(words) == 3 && words[2] == "ago":
+		back, ok := relativeUnits[strings.TrimSuffix(words[1], "s")]
+		if !ok {
+			break
+		}
+		var n int
+		switch words[0] {
+		case "a", "an", "one":
+			n = 1
+		default:
+			var err error
+			if n, err = strconv.Atoi(words[0]); err != nil || n < 0 {
+				return time.Time{}, fmt.Errorf("unable to parse timestamp: %s", timestamp)
+			}
+		}
+		return back(now, n), nil
+	}
+
+	return time.Time{}, fmt.Errorf("unable to parse timestamp: %s", timestamp)
+}
+
+// isDateOnly reports whether a timestamp is a calendar date without a time
+// of day
+func isDateOnly(timestamp string) bool {
+	for _, format := range []string{"2006-01-02", "Jan 2 2006"} {
+		if _, err := time.Parse(format, timestamp); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// RawCommand executes a raw Git command directly
+func (g *Operations) RawCommand(repoPath, command string) (string, error) {
+	// Parse the command to extract git subcommand and arguments
+	parts := strings.Fields(command)
+	if len(parts) == 0 {
+		return "", fmt.Errorf("empty command")
+	}
+
+	// Ensure the first part is "git"
+	if parts[0] != "git" {
+		return "", fmt.Errorf("command must start with 'git'")
+	}
+
+	// Remove "git" from the beginning
+	args := parts[1:]
+	
+	// Execute the command and capture output
+	output, err := g.runGit(repoPath, args...)
+	if err != nil {
+		if _, ok := err.(*DubiousOwnershipError); ok {
+			return "", err
+		}
+		return "", fmt.Errorf("git command failed: %s\nOutput: %s", err.Error(), string(output))
+	}
+	
+	return string(output), nil
+}
+
+// Init initializes a new Git repository
+func (g *Operations) Init(repoPath string, bare bool) (string, error) {
+	if repoPath == "" {
+		return "", fmt.Errorf("repository path cannot be empty")
+	}
+
+	// Create directory if it doesn't exist
+	if err := os.MkdirAll(repoPath, 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	var repo *git.Repository
+	var err error
+
+	if bare {
+		repo, err = git.PlainInitWithOptions(repoPath, &git.PlainInitOptions{
+			Bare: true,
+		})
+	} else {
+		repo, err = git.PlainInit(repoPath, false)
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("failed to initialize repository: %w", err)
+	}
+
+	repoType := "regular"
+	if bare {
+		repoType = "bare"
+	}
+
+	_ = repo // avoid unused variable warning
+	return fmt.Sprintf("Initialized empty Git repository (%s) in %s", repoType, repoPath), nil
+}
+
+// ListRepositories lists Git repositories in a directory
+func (g *Operations) ListRepositories(searchPath string, recursive bool) ([]string, error) {
+	if searchPath == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get current directory: %w", err)
+		}
+		searchPath = cwd
+	}
+
+	var repositories []string
+
+	if recursive {
+		err := filepath.Walk(searchPath, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return nil // Continue walking even if there's an error
+			}
+
+			if info.IsDir() && info.Name() == ".git" {
+				repoPath := filepath.Dir(path)
+				repositories = append(repositories, repoPath)
+				return filepath.SkipDir // Don't walk into .git directory
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to walk directory: %w", err)
+		}
+	} else {
+		// Check if the current directory is a Git repository
+		gitDir := filepath.Join(searchPath, ".git")
+		if _, err := os.Stat(gitDir); err == nil {
+			repositories = append(repositories, searchPath)
+		}
+	}
+
+	sort.Strings(repositories)
+	return repositories, nil
+}
+
+// CreateTag creates a new Git tag. A signed tag is always annotated.
+func (g *Operations) CreateTag(repoPath, tagName, message string, annotated, sign bool) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	signer, err := g.signerFor(repo, sign)
+	if err != nil {
+		return "", err
+	}
+	annotated = annotated || sign
+
+	// Get HEAD commit
+	head, err := repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	if annotated {
+		// Create annotated tag
+		var ref *plumbing.Reference
+		ref, err = repo.CreateTag(tagName, head.Hash(), &git.CreateTagOptions{
+			Tagger:  g.getUserSignature(),
+			Message: message,
+			SignKey: signer.openPGPKey(),
+		})
+		// go-git only signs with OpenPGP, SSH signatures are added after
+		if err == nil && signer.sshKey() != nil {
+			err = sshSignTag(repo, ref, signer.sshKey())
+		}
+	} else {
+		// Create lightweight tag
+		tagRef := plumbing.NewHashReference(plumbing.ReferenceName("refs/tags/"+tagName), head.Hash())
+		err = repo.Storer.SetReference(tagRef)
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("failed to create tag: %w", err)
+	}
+
+	tagType := "lightweight"
+	if sign {
+		tagType = "signed"
+	} else if annotated {
+		tagType = "annotated"
+	}
+
+	result := fmt.Sprintf("Created %s tag '%s' at %s", tagType, tagName, head.Hash().String()[:7])
+	if message != "" {
+		result += fmt.Sprintf(" with message: %s", message)
+	}
+
+	return result, nil
+}
+
+// DeleteTag deletes a Git tag
+func (g *Operations) DeleteTag(repoPath, tagName string) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	// Check if tag exists
+	tagRef := plumbing.ReferenceName("refs/tags/" + tagName)
+	_, err = repo.Reference(tagRef, true)
+	if err != nil {
+		return "", fmt.Errorf("tag '%s' not found: %w", tagName, err)
+	}
+
+	// Delete the tag
+	err = repo.Storer.RemoveReference(tagRef)
+	if err != nil {
+		return "", fmt.Errorf("failed to delete tag: %w", err)
+	}
+
+	return fmt.Sprintf("Deleted tag '%s'", tagName), nil
+}
+
+// ListTags lists all Git tags
+func (g *Operations) ListTags(repoPath string, pattern, sortBy string) ([]string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	tagRefs, err := repo.Tags()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+
+	var refs []*plumbing.Reference
+	err = tagRefs.ForEach(func(ref *plumbing.Reference) error {
+		tagName := strings.TrimPrefix(string(ref.Name()), "refs/tags/")
+		
+		// Apply pattern filter if provided
+		if pattern != "" {
+			matched, err := filepath.Match(pattern, tagName)
+			if err != nil {
+				return err
+			}
+			if !matched {
+				return nil
+			}
+		}
+		
+		refs = append(refs, ref)
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to iterate tags: %w", err)
+	}
+
+	if err := sortRefs(repo, refs, sortBy); err != nil {
+		return nil, err
+	}
+
+	tags := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		tags = append(tags, ref.Name().Short())
+	}
+	return tags, nil
+}
@@ -0,0 +1,704 @@
+package gitops
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func createTestRepo(t *testing.T) (string, *git.Repository) {
+	// Create temporary directory
+	tempDir, err := os.MkdirTemp("", "git-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+
+	// Initialize repository
+	repo, err := git.PlainInit(tempDir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repo: %v", err)
+	}
+
+	// Create a test file
+	testFile := filepath.Join(tempDir, "test.txt")
+	err = os.WriteFile(testFile, []byte("test content"), 0644)
+	if err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	// Add and commit the file
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+
+	_, err = worktree.Add("test.txt")
+	if err != nil {
+		t.Fatalf("Failed to add file: %v", err)
+	}
+
+	_, err = worktree.Commit("Initial commit", &git.CommitOptions{
+		Author: &object.Signature{
+			Name:  "Test User",
+			Email: "test@example.com",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	return tempDir, repo
+}
+
+func TestOperations_Status(t *testing.T) {
+	tempDir, _ := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	// Test clean status
+	status, err := ops.Status(tempDir, false)
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+
+	if status != "working tree clean" {
+		t.Errorf("Expected clean status, got: %s", status)
+	}
+
+	// Create a new file to test dirty status
+	newFile := filepath.Join(tempDir, "new.txt")
+	err = os.WriteFile(newFile, []byte("new content"), 0644)
+	if err != nil {
+		t.Fatalf("Failed to create new file: %v", err)
+	}
+
+	status, err = ops.Status(tempDir, false)
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+
+	if status == "working tree clean" {
+		t.Errorf("Expected dirty status, got clean")
+	}
+}
+
+func TestOperations_Add(t *testing.T) {
+	tempDir, _ := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	// Create a new file
+	newFile := filepath.Join(tempDir, "new.txt")
+	err := os.WriteFile(newFile, []byte("new content"), 0644)
+	if err != nil {
+		t.Fatalf("Failed to create new file: %v", err)
+	}
+
+	// Add the file
+	result, err := ops.Add(tempDir, []string{"new.txt"}, AddOptions{})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	if result != "Files staged successfully" {
+		t.Errorf("Expected success message, got: %s", result)
+	}
+}
+
+func TestOperations_Commit(t *testing.T) {
+	tempDir, _ := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	// Create and add a new file
+	newFile := filepath.Join(tempDir, "new.txt")
+	err := os.WriteFile(newFile, []byte("new content"), 0644)
+	if err != nil {
+		t.Fatalf("Failed to create new file: %v", err)
+	}
+
+	_, err = ops.Add(tempDir, []string{"new.txt"}, AddOptions{})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	// Commit the changes
+	result, err := ops.Commit(tempDir, "Test commit", CommitOptions{})
+	if err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	if !contains(result, "Changes committed successfully with hash") {
+		t.Errorf("Expected commit success message, got: %s", result)
+	}
+}
+
+func TestOperations_CreateBranch(t *testing.T) {
+	tempDir, _ := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	// Create a new branch
+	result, err := ops.CreateBranch(tempDir, "test-branch", "")
+	if err != nil {
+		t.Fatalf("CreateBranch failed: %v", err)
+	}
+
+	expected := "Created branch 'test-branch' from 'HEAD'"
+	if result != expected {
+		t.Errorf("Expected '%s', got: %s", expected, result)
+	}
+}
+
+func TestOperations_Checkout(t *testing.T) {
+	tempDir, _ := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	// Create a new branch first
+	_, err := ops.CreateBranch(tempDir, "test-branch", "")
+	if err != nil {
+		t.Fatalf("CreateBranch failed: %v", err)
+	}
+
+	// Checkout the branch
+	result, err := ops.Checkout(tempDir, "test-branch", false, "")
+	if err != nil {
+		t.Fatalf("Checkout failed: %v", err)
+	}
+
+	expected := "Switched to branch 'test-branch'"
+	if result != expected {
+		t.Errorf("Expected '%s', got: %s", expected, result)
+	}
+}
+
+func TestOperations_CheckoutModes(t *testing.T) {
+	tempDir, repo := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("Failed to get HEAD: %v", err)
+	}
+
+	// Create and switch in one step
+	result, err := ops.Checkout(tempDir, "feature", true, "")
+	if err != nil {
+		t.Fatalf("Checkout with create failed: %v", err)
+	}
+	if result != "Switched to a new branch 'feature'" {
+		t.Errorf("Unexpected result: %s", result)
+	}
+	if _, err := ops.Checkout(tempDir, "feature", true, ""); err == nil {
+		t.Error("Expected error when creating an existing branch")
+	}
+
+	// Detached HEAD at a commit
+	result, err = ops.Checkout(tempDir, head.Hash().String(), false, "")
+	if err != nil {
+		t.Fatalf("Detached checkout failed: %v", err)
+	}
+	if !contains(result, "detached") {
+		t.Errorf("Expected detached HEAD message, got: %s", result)
+	}
+	newHead, _ := repo.Head()
+	if newHead.Name() != plumbing.HEAD {
+		t.Errorf("Expected detached HEAD, got %s", newHead.Name())
+	}
+
+	// Remote-tracking branch creates a local tracking branch
+	if _, err := repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{"https://example.com/repo.git"}}); err != nil {
+		t.Fatalf("Failed to create remote: %v", err)
+	}
+	remoteRef := plumbing.NewHashReference(plumbing.NewRemoteReferenceName("origin", "feature-x"), head.Hash())
+	if err := repo.Storer.SetReference(remoteRef); err != nil {
+		t.Fatalf("Failed to create remote ref: %v", err)
+	}
+
+	result, err = ops.Checkout(tempDir, "origin/feature-x", false, "")
+	if err != nil {
+		t.Fatalf("Remote branch checkout failed: %v", err)
+	}
+	if !contains(result, "set up to track 'origin/feature-x'") {
+		t.Errorf("Expected tracking message, got: %s", result)
+	}
+	cfg, _ := repo.Config()
+	if branch, ok := cfg.Branches["feature-x"]; !ok || branch.Remote != "origin" {
+		t.Errorf("Expected feature-x to track origin, got: %+v", cfg.Branches["feature-x"])
+	}
+}
+
+func TestOperations_Log(t *testing.T) {
+	tempDir, _ := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	// Get log
+	commits, err := ops.Log(tempDir, LogOptions{MaxCount: 10})
+	if err != nil {
+		t.Fatalf("Log failed: %v", err)
+	}
+
+	if len(commits) == 0 {
+		t.Error("Expected at least one commit")
+	}
+
+	// Check if the commit contains expected fields
+	firstCommit := commits[0]
+	if !contains(firstCommit, "Commit:") || !contains(firstCommit, "Author:") || !contains(firstCommit, "Date:") || !contains(firstCommit, "Message:") {
+		t.Errorf("Commit format incorrect: %s", firstCommit)
+	}
+}
+
+func TestOperations_LogFilters(t *testing.T) {
+	tempDir, _ := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+	alice := NewOperations("Alice", "alice@example.com")
+
+	if err := os.MkdirAll(filepath.Join(tempDir, "docs"), 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	commitFile(t, alice, tempDir, "docs/guide.md", "guide\n", "Document diff usage")
+	commitFile(t, ops, tempDir, "docs/faq.md", "faq\n", "Add FAQ")
+	commitFile(t, alice, tempDir, "main.go", "package main\n", "Fix diff output")
+
+	tests := []struct {
+		name     string
+		opts     LogOptions
+		expected []string
+	}{
+		{"path", LogOptions{Path: "docs"}, []string{"Add FAQ", "Document diff usage"}},
+		{"file", LogOptions{Path: "./main.go"}, []string{"Fix diff output"}},
+		{"author", LogOptions{Author: "alice@"}, []string{"Fix diff output", "Document diff usage"}},
+		{"grep", LogOptions{Grep: "diff"}, []string{"Fix diff output", "Document diff usage"}},
+		{"combined", LogOptions{Path: "docs/", Author: "^Alice", Grep: "diff"}, []string{"Document diff usage"}},
+	}
+
+	for _, tt := range tests {
+		tt.opts.MaxCount = 10
+		commits, err := ops.Log(tempDir, tt.opts)
+		if err != nil {
+			t.Fatalf("%s: Log failed: %v", tt.name, err)
+		}
+		if len(commits) != len(tt.expected) {
+			t.Fatalf("%s: expected %d commits, got %d: %v", tt.name, len(tt.expected), len(commits), commits)
+		}
+		for i, message := range tt.expected {
+			if !contains(commits[i], "Message: "+message) {
+				t.Errorf("%s: expected commit %d to be %q, got: %s", tt.name, i, message, commits[i])
+			}
+		}
+	}
+
+	if _, err := ops.Log(tempDir, LogOptions{MaxCount: 10, Grep: "("}); err == nil {
+		t.Error("Expected invalid grep pattern to fail")
+	}
+}
+
+func TestOperations_LogRange(t *testing.T) {
+	tempDir, _ := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	if _, err := ops.Checkout(tempDir, "feature", true, ""); err != nil {
+		t.Fatalf("Checkout failed: %v", err)
+	}
+	commitFile(t, ops, tempDir, "f1.txt", "f1\n", "Feature one")
+	commitFile(t, ops, tempDir, "f2.txt", "f2\n", "Feature two")
+	if _, err := ops.Checkout(tempDir, "master", false, ""); err != nil {
+		t.Fatalf("Checkout failed: %v", err)
+	}
+	commitFile(t, ops, tempDir, "m1.txt", "m1\n", "Master one")
+
+	messages := func(opts LogOptions) []string {
+		t.Helper()
+		opts.MaxCount = 10
+		commits, err := ops.Log(tempDir, opts)
+		if err != nil {
+			t.Fatalf("Log %+v failed: %v", opts, err)
+		}
+		var result []string
+		for _, commit := range commits {
+			for _, line := range strings.Split(commit, "\n") {
+				if strings.HasPrefix(line, "Message: ") {
+					result = append(result, strings.TrimPrefix(line, "Message: "))
+				}
+			}
+		}
+		sort.Strings(result)
+		return result
+	}
+
+	tests := []struct {
+		name     string
+		opts     LogOptions
+		expected []string
+	}{
+		{"two-dot", LogOptions{Range: "master..feature"}, []string{"Feature one", "Feature two"}},
+		{"from-to", LogOptions{From: "feature", To: "master"}, []string{"Master one"}},
+		{"from-head", LogOptions{From: "feature"}, []string{"Master one"}},
+		{"three-dot", LogOptions{Range: "master...feature"}, []string{"Feature one", "Feature two", "Master one"}},
+		{"three-dot-head", LogOptions{Range: "feature..."}, []string{"Feature one", "Feature two", "Master one"}},
+		{"revision", LogOptions{Range: "feature"}, []string{"Feature one", "Feature two", "Initial commit"}},
+		{"range-path", LogOptions{Range: "master..feature", Path: "f1.txt"}, []string{"Feature one"}},
+	}
+
+	for _, tt := range tests {
+		got := messages(tt.opts)
+		if strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+
+	if _, err := ops.Log(tempDir, LogOptions{MaxCount: 10, Range: "a..b", From: "c"}); err == nil {
+		t.Error("Expected range combined with from to fail")
+	}
+}
+
+// commitAt writes and commits a file with the given author and committer
+// date, returning the commit hash
+func commitAt(t *testing.T, repo *git.Repository, name, content string, when time.Time, parents ...plumbing.Hash) plumbing.Hash {
+	t.Helper()
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(worktree.Filesystem.Root(), name), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	if _, err := worktree.Add(name); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	signature := &object.Signature{Name: "Test User", Email: "test@example.com", When: when}
+	hash, err := worktree.Commit(content, &git.CommitOptions{Author: signature, Committer: signature, Parents: parents})
+	if err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	return hash
+}
+
+func TestOperations_LogLimitsAndTimestamps(t *testing.T) {
+	tempDir, repo := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	day := time.Date(2030, 5, 10, 0, 0, 0, 0, time.UTC)
+	commitAt(t, repo, "a.txt", "Morning", day.Add(9*time.Hour))
+	commitAt(t, repo, "b.txt", "Noon", day.Add(12*time.Hour))
+	commitAt(t, repo, "c.txt", "Next day", day.Add(36*time.Hour))
+
+	messages := func(opts LogOptions) []string {
+		t.Helper()
+		commits, err := ops.Log(tempDir, opts)
+		if err != nil {
+			t.Fatalf("Log %+v failed: %v", opts, err)
+		}
+		var result []string
+		for _, commit := range commits {
+			for _, line := range strings.Split(commit, "\n") {
+				if strings.HasPrefix(line, "Message: ") {
+					result = append(result, strings.TrimPrefix(line, "Message: "))
+				}
+			}
+		}
+		return result
+	}
+
+	tests := []struct {
+		name     string
+		opts     LogOptions
+		expected []string
+	}{
+		{"exact limit", LogOptions{MaxCount: 2}, []string{"Next day", "Noon"}},
+		{"no limit", LogOptions{}, []string{"Next day", "Noon", "Morning", "Initial commit"}},
+		// Filtered commits do not count towards the limit
+		{"limit after filter", LogOptions{MaxCount: 1, EndTimestamp: "2030-05-10T10:00:00Z"}, []string{"Morning"}},
+		{"inclusive bounds", LogOptions{StartTimestamp: "2030-05-10T09:00:00Z", EndTimestamp: "2030-05-10T12:00:00Z"}, []string{"Noon", "Morning"}},
+		{"whole end day", LogOptions{StartTimestamp: "2030-05-10", EndTimestamp: "2030-05-10"}, []string{"Noon", "Morning"}},
+		{"empty window", LogOptions{StartTimestamp: "2030-05-10T10:00:00Z", EndTimestamp: "2030-05-10T11:00:00Z"}, nil},
+	}
+
+	for _, tt := range tests {
+		if got := messages(tt.opts); strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+
+	if _, err := ops.Log(tempDir, LogOptions{StartTimestamp: "2030-05-11", EndTimestamp: "2030-05-10"}); err == nil {
+		t.Error("Expected start after end to fail")
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	now := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		input    string
+		expected time.Time
+	}{
+		{"2024-01-02T03:04:05Z", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{"2024-01-02", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"1700000000", time.Unix(1700000000, 0)},
+		{"@1700000000", time.Unix(1700000000, 0)},
+		{"now", now},
+		{"Today", now},
+		{"yesterday", now.AddDate(0, 0, -1)},
+		{"2 weeks ago", now.AddDate(0, 0, -14)},
+		{"2.weeks.ago", now.AddDate(0, 0, -14)},
+		{"1 day ago", now.AddDate(0, 0, -1)},
+		{"an hour ago", now.Add(-time.Hour)},
+		{"30 minutes ago", now.Add(-30 * time.Minute)},
+		{"3 months ago", now.AddDate(0, -3, 0)},
+		{"last year", now.AddDate(-1, 0, 0)},
+	}
+
+	for _, tt := range tests {
+		got, err := parseTimestampAt(tt.input, now)
+		if err != nil {
+			t.Errorf("parseTimestampAt(%q) failed: %v", tt.input, err)
+			continue
+		}
+		if !got.Equal(tt.expected) {
+			t.Errorf("parseTimestampAt(%q) = %v, expected %v", tt.input, got, tt.expected)
+		}
+	}
+
+	for _, input := range []string{"", "soon", "two weeks ago", "-1 days ago", "2 fortnights ago", "@"} {
+		if _, err := parseTimestampAt(input, now); err == nil {
+			t.Errorf("Expected parseTimestampAt(%q) to fail", input)
+		}
+	}
+}
+
+func TestOperations_Show(t *testing.T) {
+	tempDir, repo := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	// The initial commit has no parent and is diffed against the empty tree
+	result, err := ops.Show(tempDir, ShowOptions{Revision: "HEAD"})
+	if err != nil {
+		t.Fatalf("Show failed: %v", err)
+	}
+	for _, expected := range []string{"(root commit)", "new file mode 100644", "+test content"} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected %q in root commit, got: %s", expected, result)
+		}
+	}
+
+	when := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	if err := os.MkdirAll(filepath.Join(tempDir, "docs"), 0755); err != nil {
+		t.Fatalf("Failed to create docs: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "test.txt"), []byte("changed content\n"), 0644); err != nil {
+		t.Fatalf("Failed to write test.txt: %v", err)
+	}
+	if _, err := ops.Add(tempDir, []string{"test.txt"}, AddOptions{}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	commitAt(t, repo, "docs/guide.md", "Add guide", when)
+
+	result, err = ops.Show(tempDir, ShowOptions{Revision: "HEAD", DiffOptions: DiffOptions{ContextLines: DefaultContextLines}})
+	if err != nil {
+		t.Fatalf("Show failed: %v", err)
+	}
+	for _, expected := range []string{"Message: Add guide", "diff --git a/docs/guide.md b/docs/guide.md", "+Add guide", "-test content", "+changed content"} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected %q in result, got: %s", expected, result)
+		}
+	}
+
+	result, err = ops.Show(tempDir, ShowOptions{Revision: "HEAD", Paths: []string{"docs"}})
+	if err != nil {
+		t.Fatalf("Show failed: %v", err)
+	}
+	if !strings.Contains(result, "docs/guide.md") || strings.Contains(result, "test.txt") {
+		t.Errorf("Expected only docs/guide.md in patch, got: %s", result)
+	}
+
+	result, err = ops.Show(tempDir, ShowOptions{Revision: "HEAD", DiffOptions: DiffOptions{Format: DiffFormatStat}})
+	if err != nil {
+		t.Fatalf("Show failed: %v", err)
+	}
+	if !strings.Contains(result, "2 files changed, 2 insertions(+), 1 deletions(-)") || strings.Contains(result, "@@") {
+		t.Errorf("Expected stat summary without patch, got: %s", result)
+	}
+}
+
+func TestOperations_Branch(t *testing.T) {
+	tempDir, _ := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	// Create a test branch
+	_, err := ops.CreateBranch(tempDir, "test-branch", "")
+	if err != nil {
+		t.Fatalf("CreateBranch failed: %v", err)
+	}
+
+	// List local branches
+	result, err := ops.Branch(tempDir, "local", "", "", "")
+	if err != nil {
+		t.Fatalf("Branch failed: %v", err)
+	}
+
+	if !contains(result, "test-branch") {
+		t.Errorf("Expected test-branch in result, got: %s", result)
+	}
+}
+
+func TestOperations_Reset(t *testing.T) {
+	tempDir, _ := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	// Create and add a new file
+	newFile := filepath.Join(tempDir, "new.txt")
+	err := os.WriteFile(newFile, []byte("new content"), 0644)
+	if err != nil {
+		t.Fatalf("Failed to create new file: %v", err)
+	}
+
+	_, err = ops.Add(tempDir, []string{"new.txt"}, AddOptions{})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	// Reset staged changes
+	result, err := ops.Reset(tempDir, "", "", nil, false)
+	if err != nil {
+		t.Fatalf("Reset failed: %v", err)
+	}
+
+	expected := "All staged changes reset"
+	if result != expected {
+		t.Errorf("Expected '%s', got: %s", expected, result)
+	}
+}
+
+func TestOperations_ResetModes(t *testing.T) {
+	tempDir, repo := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("Failed to get HEAD: %v", err)
+	}
+
+	// Create a second commit
+	testFile := filepath.Join(tempDir, "test.txt")
+	if err := os.WriteFile(testFile, []byte("changed content"), 0644); err != nil {
+		t.Fatalf("Failed to modify file: %v", err)
+	}
+	if _, err := ops.Add(tempDir, []string{"test.txt"}, AddOptions{}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if _, err := ops.Commit(tempDir, "Second commit", CommitOptions{}); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	// Invalid mode
+	if _, err := ops.Reset(tempDir, "bogus", "", nil, false); err == nil {
+		t.Error("Expected error for invalid mode")
+	}
+
+	// Soft reset to the first commit keeps the change staged
+	if _, err := ops.Reset(tempDir, "soft", "HEAD~1", nil, false); err != nil {
+		t.Fatalf("Soft reset failed: %v", err)
+	}
+	newHead, _ := repo.Head()
+	if newHead.Hash() != head.Hash() {
+		t.Errorf("Expected HEAD %s after soft reset, got %s", head.Hash(), newHead.Hash())
+	}
+	status, _ := ops.Status(tempDir, false)
+	if !contains(status, "Staged (1):\n  modified: test.txt") {
+		t.Errorf("Expected staged modification after soft reset, got: %s", status)
+	}
+
+	// Per-file unstage keeps the working tree change
+	result, err := ops.Reset(tempDir, "", "", []string{"test.txt"}, false)
+	if err != nil {
+		t.Fatalf("File reset failed: %v", err)
+	}
+	if !contains(result, "test.txt") {
+		t.Errorf("Expected unstaged file in result, got: %s", result)
+	}
+	status, _ = ops.Status(tempDir, false)
+	if contains(status, "Staged") || !contains(status, "Unstaged (1):\n  modified: test.txt") {
+		t.Errorf("Expected unstaged modification after file reset, got: %s", status)
+	}
+
+	// Hard reset must be confirmed
+	if _, err := ops.Reset(tempDir, "hard", "", nil, false); err == nil {
+		t.Error("Expected error for unconfirmed hard reset")
+	}
+	if _, err := ops.Reset(tempDir, "hard", "", nil, true); err != nil {
+		t.Fatalf("Hard reset failed: %v", err)
+	}
+	content, _ := os.ReadFile(testFile)
+	if string(content) != "test content" {
+		t.Errorf("Expected working tree restored by hard reset, got: %s", content)
+	}
+}
+
+func TestOperations_DiskUsage(t *testing.T) {
+	tempDir, _ := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	result, err := ops.DiskUsage(tempDir)
+	if err != nil {
+		t.Fatalf("DiskUsage failed: %v", err)
+	}
+
+	if !contains(result, "Working tree: 12 B (1 files)") || !contains(result, "Loose objects: 3") {
+		t.Errorf("Unexpected disk usage report: %s", result)
+	}
+
+	if _, err := ops.DiskUsage(t.TempDir()); err == nil {
+		t.Error("Expected error for non-repository directory")
+	}
+}
+
+// Helper function to check if a string contains a substring
+func contains(s, substr string) bool {
+	return len(s) >= len(substr) && (s == substr || len(s) > len(substr) && (s[:len(substr)] == substr || s[len(s)-len(substr):] == substr || containsAt(s, substr)))
+}
+
+func containsAt(s, substr string) bool {
+	for i := 0; i <= len(s)-len(substr); i++ {
+		if s[i:i+len(substr)] == substr {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+// Package gitops implements the Git operations of the MCP Git server on top
+// of go-git, falling back to the git executable where go-git lacks
+// support. Operations can be used on their own by other programs:
+//
+//	ops := gitops.New(gitops.WithIdentity("Bot", "bot@example.com"))
+//	status, err := ops.Status("/path/to/repo", false)
+package gitops
+
+import (
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"golang.org/x/crypto/ssh"
+)
+
+// Option configures the Operations created by New
+type Option func(*Operations)
+
+// New creates a Git operations instance configured by opts. Without options
+// it behaves like NewOperations("", ""): commits use the identity of the
+// repository configuration and temporary working copies go to the system
+// temporary directory.
+func New(opts ...Option) *Operations {
+	g := NewOperations("", "")
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// WithIdentity sets the author and committer of the commits, the
+// repository configuration applies to empty values
+func WithIdentity(userName, userEmail string) Option {
+	return func(g *Operations) {
+		g.userName, g.userEmail = userName, userEmail
+	}
+}
+
+// WithScratch sets the scratch directory manager, see SetScratch
+func WithScratch(scratch *Scratch) Option {
+	return func(g *Operations) { g.SetScratch(scratch) }
+}
+
+// WithTrash sets the trash of the discarded files, see SetTrash
+func WithTrash(trash *Trash) Option {
+	return func(g *Operations) { g.SetTrash(trash) }
+}
+
+// WithRepoCache sets the cache of repository handles, see SetRepoCache
+func WithRepoCache(cache *RepoCache) Option {
+	return func(g *Operations) { g.SetRepoCache(cache) }
+}
+
+// WithCommitPolicy sets the default commit policy, see SetCommitPolicy
+func WithCommitPolicy(policy *CommitPolicy) Option {
+	return func(g *Operations) { g.SetCommitPolicy(policy) }
+}
+
+// WithAuth authenticates fetch and push, see SetAuth
+func WithAuth(auth transport.AuthMethod) Option {
+	return func(g *Operations) { g.SetAuth(auth) }
+}
+
+// WithSSHAuth authenticates to SSH remotes, see SetSSHAuth
+func WithSSHAuth(auth *SSHAuth) Option {
+	return func(g *Operations) { g.SetSSHAuth(auth) }
+}
+
+// WithSigningKey signs commits and tags with OpenPGP, see SetSigningKey
+func WithSigningKey(key *openpgp.Entity) Option {
+	return func(g *Operations) { g.SetSigningKey(key) }
+}
+
+// WithSSHSigningKey signs commits and tags with SSH, see SetSSHSigningKey
+func WithSSHSigningKey(key ssh.Signer) Option {
+	return func(g *Operations) { g.SetSSHSigningKey(key) }
+}
+
+// WithSafeDirectories trusts directories owned by other users, see
+// SetSafeDirectories
+func WithSafeDirectories(dirs []string) Option {
+	return func(g *Operations) { g.SetSafeDirectories(dirs) }
+}
+
+// WithNonInteractive prevents the git executable from prompting, see
+// SetNonInteractive
+func WithNonInteractive(nonInteractive bool) Option {
+	return func(g *Operations) { g.SetNonInteractive(nonInteractive) }
+}
@@ -0,0 +1,47 @@
+package gitops
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNew_Options(t *testing.T) {
+	tempDir, repo := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	cache := NewRepoCache(0)
+	ops := New(
+		WithIdentity("Embedded User", "embedded@example.com"),
+		WithRepoCache(cache),
+		WithNonInteractive(true),
+	)
+	if ops.repos != cache || !ops.nonInteractive {
+		t.Fatalf("Options were not applied")
+	}
+	if ops.scratch == nil || ops.locks == nil {
+		t.Fatalf("Expected the defaults of NewOperations to be kept")
+	}
+
+	if err := os.WriteFile(filepath.Join(tempDir, "new.txt"), []byte("new content"), 0644); err != nil {
+		t.Fatalf("Failed to create new file: %v", err)
+	}
+	if _, err := ops.Add(tempDir, []string{"new.txt"}, AddOptions{}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if _, err := ops.Commit(tempDir, "Embedded commit", CommitOptions{}); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("Failed to get HEAD: %v", err)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		t.Fatalf("Failed to get commit: %v", err)
+	}
+	if commit.Author.Name != "Embedded User" || commit.Author.Email != "embedded@example.com" {
+		t.Errorf("Expected the identity given by WithIdentity, got %s <%s>", commit.Author.Name, commit.Author.Email)
+	}
+}
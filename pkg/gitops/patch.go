@@ -0,0 +1,282 @@
+package gitops
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// patchFile is one side of a file patch built from in-memory content
+type patchFile struct {
+	path    string
+	mode    filemode.FileMode
+	hash    plumbing.Hash
+	content []byte
+}
+
+func (f *patchFile) Hash() plumbing.Hash     { return f.hash }
+func (f *patchFile) Mode() filemode.FileMode { return f.mode }
+func (f *patchFile) Path() string            { return f.path }
+
+// textChunk is a run of lines with the same diff operation
+type textChunk struct {
+	content string
+	op      fdiff.Operation
+}
+
+func (c *textChunk) Content() string       { return c.content }
+func (c *textChunk) Type() fdiff.Operation { return c.op }
+
+// filePatch implements diff.FilePatch for content that is not necessarily
+// stored in the object database (working tree files, external directories)
+type filePatch struct {
+	from, to *patchFile
+	chunks   []fdiff.Chunk
+	binary   bool
+	// similarity is the rename score of a patch whose sides have different paths
+	similarity int
+}
+
+func (p *filePatch) IsBinary() bool { return p.binary }
+
+func (p *filePatch) Files() (fdiff.File, fdiff.File) {
+	var from, to fdiff.File
+	if p.from != nil {
+		from = p.from
+	}
+	if p.to != nil {
+		to = p.to
+	}
+	return from, to
+}
+
+func (p *filePatch) Chunks() []fdiff.Chunk { return p.chunks }
+
+// multiPatch is a collection of file patches
+type multiPatch struct {
+	filePatches []fdiff.FilePatch
+	message     string
+}
+
+func (p *multiPatch) FilePatches() []fdiff.FilePatch { return p.filePatches }
+func (p *multiPatch) Message() string                { return p.message }
+
+// newFilePatch computes the line diff between two file versions. Either side
+// may be nil to represent an added or deleted file. With ignoreWhitespace,
+// lines differing only in whitespace are treated as unchanged.
+func newFilePatch(from, to *patchFile, ignoreWhitespace bool) *filePatch {
+	patch := &filePatch{from: from, to: to}
+
+	var fromContent, toContent []byte
+	if from != nil {
+		fromContent = from.content
+	}
+	if to != nil {
+		toContent = to.content
+	}
+
+	if isBinary(fromContent) || isBinary(toContent) {
+		patch.binary = true
+		return patch
+	}
+
+	if ignoreWhitespace {
+		patch.chunks = whitespaceInsensitiveChunks(string(fromContent), string(toContent))
+		return patch
+	}
+
+	for _, d := range diff.Do(string(fromContent), string(toContent)) {
+		patch.chunks = append(patch.chunks, &textChunk{content: d.Text, op: chunkOperation(d.Type)})
+	}
+
+	return patch
+}
+
+// hasChanges reports whether a patch changes content, mode or path
+func (p *filePatch) hasChanges() bool {
+	if p.from == nil || p.to == nil || p.binary || p.from.path != p.to.path || p.from.mode != p.to.mode {
+		return true
+	}
+	for _, chunk := range p.chunks {
+		if chunk.Type() != fdiff.Equal {
+			return true
+		}
+	}
+	return false
+}
+
+func chunkOperation(op diffmatchpatch.Operation) fdiff.Operation {
+	switch op {
+	case diffmatchpatch.DiffInsert:
+		return fdiff.Add
+	case diffmatchpatch.DiffDelete:
+		return fdiff.Delete
+	default:
+		return fdiff.Equal
+	}
+}
+
+// whitespaceInsensitiveChunks diffs two texts line by line, comparing lines
+// with all whitespace removed like git diff -w. Unchanged lines are shown in
+// their new form.
+func whitespaceInsensitiveChunks(from, to string) []fdiff.Chunk {
+	fromLines, toLines := splitLines(from), splitLines(to)
+
+	// Each distinct normalized line is encoded as one rune
+	ids := make(map[string]rune)
+	encode := func(lines []string) []rune {
+		runes := make([]rune, len(lines))
+		for i, line := range lines {
+			key := strings.Join(strings.Fields(line), "")
+			id, ok := ids[key]
+			if !ok {
+				id = rune(len(ids) + 1)
+				ids[key] = id
+			}
+			runes[i] = id
+		}
+		return runes
+	}
+
+	dmp := diffmatchpatch.New()
+	diffs := dmp.DiffMainRunes(encode(fromLines), encode(toLines), false)
+
+	var chunks []fdiff.Chunk
+	i, j := 0, 0
+	for _, d := range diffs {
+		n := utf8.RuneCountInString(d.Text)
+		var lines []string
+		switch d.Type {
+		case diffmatchpatch.DiffEqual:
+			lines = toLines[j : j+n]
+			i, j = i+n, j+n
+		case diffmatchpatch.DiffDelete:
+			lines = fromLines[i : i+n]
+			i += n
+		case diffmatchpatch.DiffInsert:
+			lines = toLines[j : j+n]
+			j += n
+		}
+		chunks = append(chunks, &textChunk{content: strings.Join(lines, ""), op: chunkOperation(d.Type)})
+	}
+	return chunks
+}
+
+// splitLines splits text after each newline, keeping the terminators
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// newPatchFile builds a patch side from raw content, computing its blob hash
+func newPatchFile(path string, mode filemode.FileMode, content []byte) *patchFile {
+	return &patchFile{
+		path:    path,
+		mode:    mode,
+		hash:    plumbing.ComputeHash(plumbing.BlobObject, content),
+		content: content,
+	}
+}
+
+// encodePatch renders file patches in unified diff format
+func encodePatch(filePatches []fdiff.FilePatch, contextLines int) (string, error) {
+	var buf bytes.Buffer
+	if err := writePatch(&buf, &multiPatch{filePatches: filePatches}, contextLines); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// writePatch writes a patch in unified diff format
+func writePatch(w io.Writer, patch fdiff.Patch, contextLines int) error {
+	if contextLines < 0 {
+		contextLines = DefaultContextLines
+	}
+	if err := fdiff.NewUnifiedEncoder(w, contextLines).Encode(patch); err != nil {
+		return fmt.Errorf("failed to encode patch: %w", err)
+	}
+	return nil
+}
+
+// isBinary reports whether content looks binary, using the same heuristic
+// as git: a NUL byte within the first 8000 bytes
+func isBinary(content []byte) bool {
+	if len(content) > 8000 {
+		content = content[:8000]
+	}
+	return bytes.IndexByte(content, 0) >= 0
+}
+
+// patchPath returns the path a file patch applies to
+func patchPath(fp fdiff.FilePatch) string {
+	from, to := fp.Files()
+	if to != nil {
+		return to.Path()
+	}
+	if from != nil {
+		return from.Path()
+	}
+	return ""
+}
+
+// changeLetter returns the name-status letter of a file patch, followed by
+// the similarity score for renames
+func changeLetter(fp fdiff.FilePatch) string {
+	from, to := fp.Files()
+	switch {
+	case from == nil:
+		return "A"
+	case to == nil:
+		return "D"
+	case from.Path() != to.Path():
+		if p, ok := fp.(*filePatch); ok {
+			return fmt.Sprintf("R%03d", p.similarity)
+		}
+		return "R"
+	default:
+		return "M"
+	}
+}
+
+// summarizeFilePatches lists file patches as name-status lines, renames
+// listing both paths
+func summarizeFilePatches(filePatches []fdiff.FilePatch) string {
+	var lines []string
+	for _, fp := range filePatches {
+		line := fmt.Sprintf("%s\t%s", changeLetter(fp), patchPath(fp))
+		if from, to := fp.Files(); from != nil && to != nil && from.Path() != to.Path() {
+			line = fmt.Sprintf("%s\t%s\t%s", changeLetter(fp), from.Path(), to.Path())
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatStats renders file stats like git diff --stat, including the
+// summary line
+func formatStats(stats object.FileStats) string {
+	var added, deleted int
+	for _, stat := range stats {
+		added += stat.Addition
+		deleted += stat.Deletion
+	}
+
+	files := "files"
+	if len(stats) == 1 {
+		files = "file"
+	}
+	return fmt.Sprintf("%s %d %s changed, %d insertions(+), %d deletions(-)",
+		stats.String(), len(stats), files, added, deleted)
+}
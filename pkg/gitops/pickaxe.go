@@ -0,0 +1,232 @@
+package gitops
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// DefaultLogSearchMaxCount is the number of commits reported when
+// LogSearchOptions.MaxCount is not set
+const DefaultLogSearchMaxCount = 20
+
+// LogSearchOptions holds the parameters of a pickaxe search
+type LogSearchOptions struct {
+	// Pattern is the string (or, with Regex, the regular expression)
+	// searched for in the changes of each commit
+	Pattern string
+	// Regex treats Pattern as a regular expression matched against added or
+	// removed lines like git log -G. Otherwise commits are reported whose
+	// changes alter the number of occurrences of Pattern like git log -S.
+	Regex      bool
+	IgnoreCase bool
+	// Range is a revision (default HEAD) or a range A..B / A...B
+	Range string
+	// Path limits the search to a file or directory
+	Path string
+	// MaxCount caps the number of commits reported
+	MaxCount int
+}
+
+// pickaxeMatch is a file whose change in a commit matches the search
+type pickaxeMatch struct {
+	path           string
+	added, removed int
+}
+
+// LogSearch finds the commits whose changes introduce or remove a string,
+// newest first. Merge commits are skipped like git log does without -m.
+func (g *Operations) LogSearch(repoPath string, opts LogSearchOptions) (string, error) {
+	if opts.Pattern == "" {
+		return "", fmt.Errorf("pattern is required")
+	}
+
+	pattern := opts.Pattern
+	if !opts.Regex {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if opts.IgnoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	commitIter, err := logIterator(repo, LogOptions{Range: opts.Range})
+	if err != nil {
+		return "", err
+	}
+	defer commitIter.Close()
+
+	maxCount := opts.MaxCount
+	if maxCount <= 0 {
+		maxCount = DefaultLogSearchMaxCount
+	}
+	specs := pathSpecs([]string{opts.Path})
+
+	var entries []string
+	err = commitIter.ForEach(func(commit *object.Commit) error {
+		if commit.NumParents() > 1 {
+			return nil
+		}
+
+		matches, err := pickaxeCommit(commit, re, opts.Regex, specs)
+		if err != nil {
+			return err
+		}
+		if len(matches) == 0 {
+			return nil
+		}
+
+		entry := fmt.Sprintf("%s %s %s %s",
+			commit.Hash.String()[:7],
+			commit.Author.When.Format(time.RFC3339),
+			commit.Author.Name,
+			commitSubject(commit))
+		for _, match := range matches {
+			entry += fmt.Sprintf("\n    %s (+%d -%d)", match.path, match.added, match.removed)
+		}
+		entries = append(entries, entry)
+		// One more commit tells whether the result was truncated
+		return limitReached(len(entries), maxCount+1)
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to search commits: %w", err)
+	}
+
+	if len(entries) == 0 {
+		return fmt.Sprintf("No commits changing '%s'", opts.Pattern), nil
+	}
+
+	truncated := len(entries) > maxCount
+	if truncated {
+		entries = entries[:maxCount]
+	}
+	result := strings.Join(entries, "\n")
+	if truncated {
+		result += fmt.Sprintf("\n... (stopped after %d commits)", maxCount)
+	}
+	return result, nil
+}
+
+// pickaxeCommit returns the files of a commit whose change matches the
+// search. added and removed count occurrences of the pattern for a string
+// search and matching lines for a regex search.
+func pickaxeCommit(commit *object.Commit, re *regexp.Regexp, lines bool, specs []*regexp.Regexp) ([]pickaxeMatch, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree of %s: %w", commit.Hash.String()[:7], err)
+	}
+
+	var parentTree *object.Tree
+	if commit.NumParents() == 1 {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get parent of %s: %w", commit.Hash.String()[:7], err)
+		}
+		parentTree, err = parent.Tree()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tree of %s: %w", parent.Hash.String()[:7], err)
+		}
+	}
+
+	changes, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff %s: %w", commit.Hash.String()[:7], err)
+	}
+
+	var matches []pickaxeMatch
+	for _, change := range changes {
+		path := change.To.Name
+		if path == "" {
+			path = change.From.Name
+		}
+		if !matchesPathSpecs(specs, path) {
+			continue
+		}
+
+		from, to, err := change.Files()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		fromContent, err := pickaxeContent(from)
+		if err != nil {
+			return nil, err
+		}
+		toContent, err := pickaxeContent(to)
+		if err != nil {
+			return nil, err
+		}
+		if isBinary([]byte(fromContent)) || isBinary([]byte(toContent)) {
+			continue
+		}
+
+		var match pickaxeMatch
+		if lines {
+			match = grepChangedLines(fromContent, toContent, re)
+		} else {
+			before := len(re.FindAllStringIndex(fromContent, -1))
+			after := len(re.FindAllStringIndex(toContent, -1))
+			if before == after {
+				continue
+			}
+			if after > before {
+				match.added = after - before
+			} else {
+				match.removed = before - after
+			}
+		}
+		if match.added == 0 && match.removed == 0 {
+			continue
+		}
+		match.path = path
+		matches = append(matches, match)
+	}
+	return matches, nil
+}
+
+// grepChangedLines counts the added and removed lines matching re
+func grepChangedLines(from, to string, re *regexp.Regexp) pickaxeMatch {
+	var match pickaxeMatch
+	for _, d := range diff.Do(from, to) {
+		if d.Type == diffmatchpatch.DiffEqual {
+			continue
+		}
+		for _, line := range splitLines(d.Text) {
+			if !re.MatchString(line) {
+				continue
+			}
+			if d.Type == diffmatchpatch.DiffInsert {
+				match.added++
+			} else {
+				match.removed++
+			}
+		}
+	}
+	return match
+}
+
+// pickaxeContent returns the content of one side of a change, empty for
+// the missing side of an addition or deletion
+func pickaxeContent(file *object.File) (string, error) {
+	if file == nil {
+		return "", nil
+	}
+	content, err := file.Contents()
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", file.Name, err)
+	}
+	return content, nil
+}
@@ -0,0 +1,69 @@
+package gitops
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestOperations_LogSearch(t *testing.T) {
+	tempDir, _ := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	commitFile(t, ops, tempDir, "util.go", "package util\n\nfunc parseConfig() {}\n", "Add parseConfig")
+	commitFile(t, ops, tempDir, "util.go", "package util\n\n// parse the config\nfunc parseConfig() {}\n", "Document parseConfig")
+	commitFile(t, ops, tempDir, "notes.md", "parseConfig is gone\n", "Add notes")
+	commitFile(t, ops, tempDir, "util.go", "package util\n", "Remove parseConfig")
+
+	// The comment and the notes contain the string, but only the
+	// introduction and removal of the function in util.go change the count
+	result, err := ops.LogSearch(tempDir, LogSearchOptions{Pattern: "func parseConfig"})
+	if err != nil {
+		t.Fatalf("LogSearch failed: %v", err)
+	}
+	lines := strings.Split(result, "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected 2 commits, got: %s", result)
+	}
+	if !strings.HasSuffix(lines[0], "Remove parseConfig") || lines[1] != "    util.go (+0 -1)" {
+		t.Errorf("Unexpected removal: %s", result)
+	}
+	if !strings.HasSuffix(lines[2], "Add parseConfig") || lines[3] != "    util.go (+1 -0)" {
+		t.Errorf("Unexpected introduction: %s", result)
+	}
+
+	// A regex search reports every commit adding or removing matching lines
+	result, err = ops.LogSearch(tempDir, LogSearchOptions{Pattern: "parse.*config", Regex: true, IgnoreCase: true})
+	if err != nil {
+		t.Fatalf("LogSearch failed: %v", err)
+	}
+	if strings.Count(result, "\n    ") != 4 {
+		t.Errorf("Expected 4 matching commits, got: %s", result)
+	}
+
+	result, err = ops.LogSearch(tempDir, LogSearchOptions{Pattern: "parseConfig", Path: "notes.md"})
+	if err != nil {
+		t.Fatalf("LogSearch failed: %v", err)
+	}
+	if !strings.HasSuffix(strings.Split(result, "\n")[0], "Add notes") || strings.Contains(result, "util.go") {
+		t.Errorf("Expected only the notes commit, got: %s", result)
+	}
+
+	result, err = ops.LogSearch(tempDir, LogSearchOptions{Pattern: "parseConfig", MaxCount: 1})
+	if err != nil {
+		t.Fatalf("LogSearch failed: %v", err)
+	}
+	if !strings.HasSuffix(result, "(stopped after 1 commits)") {
+		t.Errorf("Expected truncated result, got: %s", result)
+	}
+
+	result, err = ops.LogSearch(tempDir, LogSearchOptions{Pattern: "missing"})
+	if err != nil {
+		t.Fatalf("LogSearch failed: %v", err)
+	}
+	if result != "No commits changing 'missing'" {
+		t.Errorf("Unexpected result: %s", result)
+	}
+}
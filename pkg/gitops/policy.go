@@ -0,0 +1,308 @@
+package gitops
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// Configuration section holding the per-repository commit policy, e.g.
+//
+//	[mcpgit "commit"]
+//		wrapBody = 72
+//		imperativeSubject = true
+//		issuePattern = [A-Z]+-[0-9]+
+//		issueFormat = trailer
+//		issueTrailer = Refs
+const (
+	policySection    = "mcpgit"
+	policySubsection = "commit"
+)
+
+// CommitPolicy describes how commit messages are rewritten before committing
+type CommitPolicy struct {
+	// WrapBody wraps body paragraphs at this many columns (0 disables)
+	WrapBody int `json:"wrap_body,omitempty"`
+	// ImperativeSubject rewrites the first word of the subject to the
+	// imperative mood ("Added" -> "Add") and drops a trailing period
+	ImperativeSubject bool `json:"imperative_subject,omitempty"`
+	// IssuePattern extracts an issue ID from the branch name (empty disables)
+	IssuePattern string `json:"issue_pattern,omitempty"`
+	// IssueFormat is "trailer" (append IssueTrailer: ID) or "prefix"
+	// (prepend "ID: " to the subject)
+	IssueFormat string `json:"issue_format,omitempty"`
+	// IssueTrailer is the trailer key used with the trailer format
+	IssueTrailer string `json:"issue_trailer,omitempty"`
+}
+
+// loadCommitPolicy reads the commit policy from the repository configuration.
+// It returns nil when no policy is configured.
+func loadCommitPolicy(repo *git.Repository) (*CommitPolicy, error) {
+	cfg, err := repo.Config()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	if !cfg.Raw.HasSection(policySection) || !cfg.Raw.Section(policySection).HasSubsection(policySubsection) {
+		return nil, nil
+	}
+	options := cfg.Raw.Section(policySection).Subsection(policySubsection).Options
+
+	policy := &CommitPolicy{
+		IssueFormat:  "trailer",
+		IssueTrailer: "Refs",
+	}
+
+	if v := options.Get("wrapBody"); v != "" {
+		policy.WrapBody, err = strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s.%s.wrapBody: %s", policySection, policySubsection, v)
+		}
+	}
+	if v := options.Get("imperativeSubject"); v != "" {
+		policy.ImperativeSubject, err = strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s.%s.imperativeSubject: %s", policySection, policySubsection, v)
+		}
+	}
+	if v := options.Get("issuePattern"); v != "" {
+		if _, err := regexp.Compile(v); err != nil {
+			return nil, fmt.Errorf("invalid %s.%s.issuePattern: %w", policySection, policySubsection, err)
+		}
+		policy.IssuePattern = v
+	}
+	if v := options.Get("issueFormat"); v != "" {
+		if v != "trailer" && v != "prefix" {
+			return nil, fmt.Errorf("invalid %s.%s.issueFormat: %s (expected trailer or prefix)", policySection, policySubsection, v)
+		}
+		policy.IssueFormat = v
+	}
+	if v := options.Get("issueTrailer"); v != "" {
+		policy.IssueTrailer = v
+	}
+
+	return policy, nil
+}
+
+// Validate checks a policy that did not come from git config and fills in
+// the defaults of unset fields
+func (p *CommitPolicy) Validate() error {
+	if p.WrapBody < 0 {
+		return fmt.Errorf("invalid commit policy wrap_body: %d", p.WrapBody)
+	}
+	if p.IssuePattern != "" {
+		if _, err := regexp.Compile(p.IssuePattern); err != nil {
+			return fmt.Errorf("invalid commit policy issue_pattern: %w", err)
+		}
+	}
+	switch p.IssueFormat {
+	case "":
+		p.IssueFormat = "trailer"
+	case "trailer", "prefix":
+	default:
+		return fmt.Errorf("invalid commit policy issue_format: %s (expected trailer or prefix)", p.IssueFormat)
+	}
+	if p.IssueTrailer == "" {
+		p.IssueTrailer = "Refs"
+	}
+	return nil
+}
+
+// Apply runs the message through the policy transforms. branch is the name
+// of the branch being committed to, used to derive the issue ID.
+func (p *CommitPolicy) Apply(message, branch string) (string, error) {
+	message = strings.TrimSpace(message)
+	subject, body, _ := strings.Cut(message, "\n")
+	subject = strings.TrimSpace(subject)
+	body = strings.Trim(body, "\n")
+
+	if p.ImperativeSubject {
+		subject = imperativeSubject(subject)
+	}
+
+	if p.WrapBody > 0 && body != "" {
+		body = wrapBody(body, p.WrapBody)
+	}
+
+	if p.IssuePattern != "" {
+		issue, err := findIssue([]string{p.IssuePattern}, branch)
+		if err != nil {
+			return "", err
+		}
+		if issue = formatIssue(issue); issue != "" && !strings.Contains(message, issue) {
+			if p.IssueFormat == "prefix" {
+				subject = issue + ": " + subject
+			} else {
+				body = appendTrailer(body, p.IssueTrailer, issue)
+			}
+		}
+	}
+
+	if body == "" {
+		return subject, nil
+	}
+	return subject + "\n\n" + body, nil
+}
+
+// imperativeVerbs are verbs recognised when rewriting a subject to the
+// imperative mood
+var imperativeVerbs = []string{
+	"add", "allow", "avoid", "bump", "change", "clean", "convert", "correct",
+	"create", "delete", "deprecate", "disable", "document", "drop", "enable",
+	"ensure", "extend", "fix", "handle", "implement", "improve", "introduce",
+	"make", "merge", "move", "optimize", "prevent", "refactor", "release",
+	"remove", "rename", "replace", "restore", "revert", "simplify", "support",
+	"test", "update", "upgrade", "use",
+}
+
+// imperativeSubject rewrites the first word of a subject, after an optional
+// conventional-commit "type(scope): " prefix, to the imperative mood
+func imperativeSubject(subject string) string {
+	subject = strings.TrimRight(subject, ".")
+
+	prefix := ""
+	if i := strings.Index(subject, ": "); i > 0 && !strings.Contains(subject[:i], " ") {
+		prefix, subject = subject[:i+2], subject[i+2:]
+	}
+
+	word, rest, _ := strings.Cut(subject, " ")
+	if verb, ok := imperativeForm(word); ok {
+		if r := []rune(word); len(r) > 0 && unicode.IsUpper(r[0]) {
+			verb = capitalize(verb)
+		}
+		word = verb
+	}
+
+	// Plain subjects start with a capital letter; conventional-commit
+	// descriptions keep the casing chosen by the author
+	if prefix == "" {
+		word = capitalize(word)
+	}
+
+	if rest == "" {
+		return prefix + word
+	}
+	return prefix + word + " " + rest
+}
+
+// imperativeForm maps inflected forms of known verbs ("Added", "fixes",
+// "dropping") to their imperative form
+func imperativeForm(word string) (string, bool) {
+	lower := strings.ToLower(word)
+	for _, verb := range imperativeVerbs {
+		stem := strings.TrimSuffix(verb, "e")
+		doubled := verb + verb[len(verb)-1:]
+		forms := []string{
+			verb, verb + "s", verb + "es", verb + "d", verb + "ed",
+			stem + "ing", verb + "ing", doubled + "ed", doubled + "ing",
+		}
+		for _, form := range forms {
+			if lower == form {
+				return verb, true
+			}
+		}
+	}
+	return "", false
+}
+
+// capitalize upper-cases the first letter of a word
+func capitalize(word string) string {
+	r := []rune(word)
+	if len(r) == 0 {
+		return word
+	}
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+// wrapBody wraps plain paragraphs and bullet items of a commit body at width
+// columns. Indented lines (code) and trailers are left untouched.
+func wrapBody(body string, width int) string {
+	var out []string
+	for _, paragraph := range strings.Split(body, "\n\n") {
+		lines := strings.Split(paragraph, "\n")
+		if isTrailerBlock(lines) || strings.HasPrefix(lines[0], " ") || strings.HasPrefix(lines[0], "\t") {
+			out = append(out, paragraph)
+			continue
+		}
+
+		var wrapped []string
+		var item []string
+		flush := func() {
+			if len(item) == 0 {
+				return
+			}
+			text := strings.Join(item, " ")
+			indent := ""
+			if strings.HasPrefix(text, "- ") || strings.HasPrefix(text, "* ") {
+				indent = "  "
+			}
+			wrapped = append(wrapped, wrapText(text, width, indent)...)
+			item = nil
+		}
+		for _, line := range lines {
+			trimmed := strings.TrimSpace(line)
+			if strings.HasPrefix(trimmed, "- ") || strings.HasPrefix(trimmed, "* ") {
+				flush()
+			}
+			item = append(item, trimmed)
+		}
+		flush()
+
+		out = append(out, strings.Join(wrapped, "\n"))
+	}
+	return strings.Join(out, "\n\n")
+}
+
+// wrapText greedily wraps text at width columns, indenting continuation
+// lines with indent. Words longer than width are not split.
+func wrapText(text string, width int, indent string) []string {
+	var lines []string
+	current := ""
+	for _, word := range strings.Fields(text) {
+		switch {
+		case current == "":
+			current = word
+		case len(current)+1+len(word) > width:
+			lines = append(lines, current)
+			current = indent + word
+		default:
+			current += " " + word
+		}
+	}
+	if current != "" {
+		lines = append(lines, current)
+	}
+	return lines
+}
+
+var trailerLine = regexp.MustCompile(`^[A-Za-z0-9-]+: \S`)
+
+// isTrailerBlock reports whether every line of a paragraph is a trailer
+func isTrailerBlock(lines []string) bool {
+	for _, line := range lines {
+		if !trailerLine.MatchString(line) {
+			return false
+		}
+	}
+	return len(lines) > 0
+}
+
+// appendTrailer adds a "key: value" trailer to a commit body, joining an
+// existing trailer block when there is one
+func appendTrailer(body, key, value string) string {
+	trailer := fmt.Sprintf("%s: %s", key, value)
+	if body == "" {
+		return trailer
+	}
+
+	paragraphs := strings.Split(body, "\n\n")
+	if last := paragraphs[len(paragraphs)-1]; isTrailerBlock(strings.Split(last, "\n")) {
+		return body + "\n" + trailer
+	}
+	return body + "\n\n" + trailer
+}
@@ -0,0 +1,77 @@
+package gitops
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCommitPolicy_Apply(t *testing.T) {
+	policy := &CommitPolicy{
+		WrapBody:          30,
+		ImperativeSubject: true,
+		IssuePattern:      `[A-Z]+-[0-9]+`,
+		IssueFormat:       "trailer",
+		IssueTrailer:      "Refs",
+	}
+
+	message := "Added retry logic.\n\nThis change makes the client retry failed requests a few times before giving up.\n\n    code stays as is"
+	got, err := policy.Apply(message, "feature/ABC-123-retry")
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	expected := "Add retry logic\n\n" +
+		"This change makes the client\nretry failed requests a few\ntimes before giving up.\n\n" +
+		"    code stays as is\n\n" +
+		"Refs: ABC-123"
+	if got != expected {
+		t.Errorf("Unexpected message:\n%s\nexpected:\n%s", got, expected)
+	}
+
+	// The issue is not duplicated and conventional prefixes are kept
+	policy.IssueFormat = "prefix"
+	if got, _ := policy.Apply("fix(api): fixes ABC-123 crash", "ABC-123"); got != "fix(api): fix ABC-123 crash" {
+		t.Errorf("Unexpected message: %s", got)
+	}
+	if got, _ := policy.Apply("Fixing crash", "ABC-7-crash"); got != "ABC-7: Fix crash" {
+		t.Errorf("Unexpected message: %s", got)
+	}
+}
+
+func TestOperations_CommitWithPolicy(t *testing.T) {
+	tempDir, repo := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	cfg, err := repo.Config()
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	cfg.Raw.Section("mcpgit").Subsection("commit").SetOption("imperativeSubject", "true")
+	if err := repo.SetConfig(cfg); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(tempDir, "new.txt"), []byte("new content"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if _, err := ops.Add(tempDir, []string{"new.txt"}, AddOptions{}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	result, err := ops.Commit(tempDir, "Updated docs.", CommitOptions{})
+	if err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if !strings.HasSuffix(result, "Message:\nUpdate docs") {
+		t.Errorf("Expected final message to be echoed, got: %s", result)
+	}
+
+	head, _ := repo.Head()
+	commit, _ := repo.CommitObject(head.Hash())
+	if commit.Message != "Update docs" {
+		t.Errorf("Expected formatted commit message, got: %q", commit.Message)
+	}
+}
@@ -0,0 +1,66 @@
+package gitops
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// BranchNames lists the short names of the local branches of a repository,
+// followed by its remote-tracking branches when remotes is set
+func (g *Operations) BranchNames(repoPath string, remotes bool) ([]string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	var local, remote []string
+	refs, err := repo.References()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get references: %w", err)
+	}
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		switch {
+		case ref.Name().IsBranch():
+			local = append(local, ref.Name().Short())
+		case remotes && ref.Name().IsRemote() && ref.Type() == plumbing.HashReference:
+			// origin/HEAD is a symbolic reference to one of the others
+			remote = append(remote, ref.Name().Short())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to iterate references: %w", err)
+	}
+
+	sort.Strings(local)
+	sort.Strings(remote)
+	return append(local, remote...), nil
+}
+
+// TagNames lists the names of the tags of a repository, sorted by name
+func (g *Operations) TagNames(repoPath string) ([]string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	tagRefs, err := repo.Tags()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+	var tags []string
+	err = tagRefs.ForEach(func(ref *plumbing.Reference) error {
+		tags = append(tags, ref.Name().Short())
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to iterate tags: %w", err)
+	}
+
+	sort.Strings(tags)
+	return tags, nil
+}
@@ -0,0 +1,57 @@
+package gitops
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func TestRefNames(t *testing.T) {
+	tempDir, repo := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("Failed to get HEAD: %v", err)
+	}
+	for _, name := range []string{"refs/heads/feature", "refs/remotes/origin/main", "refs/tags/v1.0", "refs/tags/v0.9"} {
+		if err := repo.Storer.SetReference(plumbing.NewHashReference(plumbing.ReferenceName(name), head.Hash())); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+	originHead := plumbing.NewSymbolicReference("refs/remotes/origin/HEAD", "refs/remotes/origin/main")
+	if err := repo.Storer.SetReference(originHead); err != nil {
+		t.Fatalf("Failed to create origin/HEAD: %v", err)
+	}
+
+	branches, err := ops.BranchNames(tempDir, false)
+	if err != nil {
+		t.Fatalf("BranchNames failed: %v", err)
+	}
+	if expected := []string{"feature", "master"}; !reflect.DeepEqual(branches, expected) {
+		t.Errorf("Expected branches %v, got %v", expected, branches)
+	}
+
+	branches, err = ops.BranchNames(tempDir, true)
+	if err != nil {
+		t.Fatalf("BranchNames failed: %v", err)
+	}
+	if expected := []string{"feature", "master", "origin/main"}; !reflect.DeepEqual(branches, expected) {
+		t.Errorf("Expected branches with remotes %v, got %v", expected, branches)
+	}
+
+	tags, err := ops.TagNames(tempDir)
+	if err != nil {
+		t.Fatalf("TagNames failed: %v", err)
+	}
+	if expected := []string{"v0.9", "v1.0"}; !reflect.DeepEqual(tags, expected) {
+		t.Errorf("Expected tags %v, got %v", expected, tags)
+	}
+
+	if _, err := ops.TagNames(t.TempDir()); err == nil {
+		t.Error("Expected an error outside of a repository")
+	}
+}
@@ -0,0 +1,896 @@
+package gitops
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/protocol/packp/capability"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/client"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/pengcunfu/go-mcp-git/internal/tracing"
+)
+
+// Credentials authenticate a single remote operation over HTTPS in place of
+// the server credentials. Password is a password or an access token.
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// PushOptions selects what Push sends
+type PushOptions struct {
+	// Remote is pushed to, origin by default
+	Remote string
+	// Refspec is pushed instead of the remote's configured refspecs
+	Refspec string
+	// Tags also pushes all tags
+	Tags bool
+	// Force updates remote branches the pushed ones do not descend from
+	Force bool
+	// ForceWithLease only forces the update of a remote branch still at
+	// the commit of its remote-tracking branch
+	ForceWithLease bool
+	// SetUpstream makes the remote branches the upstream of the pushed
+	// branches. Without Refspec, only the current branch is pushed.
+	SetUpstream bool
+	// Delete is a remote branch to delete instead of pushing
+	Delete string
+	// Mirror makes the remote an exact copy: all references, including
+	// tags and notes, are forced and those missing locally are deleted
+	Mirror bool
+	// DryRun reports the updates without pushing
+	DryRun bool
+	// Options are sent to the server for its hooks, like git push -o, e.g.
+	// ci.skip or merge_request.create
+	Options []string
+	// Credentials override the server credentials
+	Credentials *Credentials
+}
+
+// FetchOptions selects what Fetch downloads
+type FetchOptions struct {
+	// Remote is fetched from, origin by default
+	Remote string
+	// Tags also fetches all tags, not only those of fetched commits
+	Tags bool
+	// Prune deletes the remote-tracking branches of branches deleted from
+	// the remote
+	Prune bool
+	// Depth limits the history fetched to this many commits from the tips
+	// of the branches, 0 fetches all of it
+	Depth int
+	// Filter omits objects from the download as a partial clone filter,
+	// e.g. blob:none, which needs the git executable and a partial clone
+	Filter string
+	// Bundle is a bundle file fetched instead of the remote, into the
+	// remote-tracking branches of Remote, which need not exist
+	Bundle string
+	// Credentials override the server credentials
+	Credentials *Credentials
+}
+
+// CloneOptions selects what Clone copies
+type CloneOptions struct {
+	// Branch is checked out instead of the remote HEAD
+	Branch string
+	// Bare clones without a working tree
+	Bare bool
+	// Depth makes a shallow clone of this many commits, 0 clones all the
+	// history
+	Depth int
+	// SingleBranch only clones the history of Branch, or of the remote HEAD
+	SingleBranch bool
+	// Mirror makes a bare copy of all the references of the remote, which
+	// later fetches overwrite
+	Mirror bool
+	// Filter makes a partial clone omitting objects until they are needed,
+	// e.g. blob:none or blob:limit=1m. It needs the git executable, which
+	// downloads the missing objects on demand.
+	Filter string
+	// Credentials override the server credentials
+	Credentials *Credentials
+}
+
+// authFor returns the authentication for talking to url: the credentials of
+// the call, or else the server ones. HTTP credentials are only sent to
+// HTTP(S) remotes, SSH remotes use the SSH authentication.
+func (g *Operations) authFor(url string, creds *Credentials) (transport.AuthMethod, error) {
+	endpoint, err := transport.NewEndpoint(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote URL %s: %w", url, err)
+	}
+	isHTTP := endpoint.Protocol == "http" || endpoint.Protocol == "https"
+
+	if creds != nil {
+		if !isHTTP {
+			return nil, fmt.Errorf("username and token only apply to HTTPS remotes, not %s", url)
+		}
+		username := creds.Username
+		// Token based authentication ignores the user name, but an empty
+		// one is rejected by some servers
+		if username == "" {
+			username = "git"
+		}
+		return &http.BasicAuth{Username: username, Password: creds.Password}, nil
+	}
+
+	if endpoint.Protocol == "ssh" && g.sshAuth != nil {
+		return g.sshAuthFor(endpoint.User)
+	}
+	if _, ok := g.auth.(*http.BasicAuth); ok && !isHTTP {
+		return nil, nil
+	}
+	return g.auth, nil
+}
+
+// remoteAuth returns the authentication for talking to a remote, see
+// authFor
+func (g *Operations) remoteAuth(remote *git.Remote, creds *Credentials) (transport.AuthMethod, error) {
+	urls := remote.Config().URLs
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("remote '%s' has no URL", remote.Config().Name)
+	}
+	return g.authFor(urls[0], creds)
+}
+
+// Push pushes changes to remote repository
+func (g *Operations) Push(repoPath string, opts PushOptions) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+	if opts.Force && opts.ForceWithLease {
+		return "", fmt.Errorf("force and force_with_lease cannot be combined")
+	}
+	if opts.Mirror && (opts.Refspec != "" || opts.Tags || opts.Delete != "" || opts.SetUpstream || opts.ForceWithLease) {
+		return "", fmt.Errorf("mirror cannot be combined with refspec, tags, delete, set_upstream or force_with_lease")
+	}
+
+	// Get remote
+	remote := opts.Remote
+	if remote == "" {
+		remote = "origin"
+	}
+
+	remoteObj, err := repo.Remote(remote)
+	if err != nil {
+		return "", fmt.Errorf("failed to get remote '%s': %w", remote, err)
+	}
+	auth, err := g.remoteAuth(remoteObj, opts.Credentials)
+	if err != nil {
+		return "", err
+	}
+
+	refSpecs, err := pushRefSpecs(repo, opts)
+	if err != nil {
+		return "", err
+	}
+	if opts.Mirror {
+		deletions, err := mirrorDeletions(repo, remoteObj, auth)
+		if err != nil {
+			return "", err
+		}
+		refSpecs = append(refSpecs, deletions...)
+	}
+	options, err := pushOptionValues(remoteObj, auth, opts.Options)
+	if err != nil {
+		return "", err
+	}
+	if opts.DryRun {
+		return pushPlan(repo, remoteObj, auth, refSpecs, opts)
+	}
+
+	// Prepare push options
+	pushOptions := &git.PushOptions{RemoteName: remote, RefSpecs: refSpecs, Force: opts.Force, Options: options, Auth: auth}
+	if opts.ForceWithLease {
+		// Without a reference or hash, each remote branch is expected at
+		// its remote-tracking branch
+		pushOptions.ForceWithLease = &git.ForceWithLease{}
+	}
+
+	traced, span := g.trace("git.push", tracing.KindClient, tracing.String("git.remote", remote))
+	err = remoteObj.PushContext(traced.context(), pushOptions)
+	endNetworkSpan(span, err)
+	var result string
+	switch {
+	case err == git.NoErrAlreadyUpToDate && opts.Delete != "":
+		return "", fmt.Errorf("remote branch '%s' does not exist on %s", opts.Delete, remote)
+	case err == git.NoErrAlreadyUpToDate:
+		result = "Everything up-to-date"
+	case err != nil:
+		return "", fmt.Errorf("failed to push: %w", err)
+	case opts.Delete != "":
+		result = fmt.Sprintf("Deleted branch '%s' from %s", opts.Delete, remote)
+	case opts.Mirror:
+		result = fmt.Sprintf("Mirrored all references to %s", remote)
+	default:
+		result = fmt.Sprintf("Successfully pushed to %s", remote)
+		if opts.Tags {
+			result += " (including tags)"
+		}
+		if opts.Refspec != "" {
+			result += fmt.Sprintf(" with refspec: %s", opts.Refspec)
+		}
+		if opts.Force || opts.ForceWithLease {
+			result += " (forced)"
+		}
+		if len(opts.Options) > 0 {
+			result += fmt.Sprintf(" with push options: %s", strings.Join(opts.Options, ", "))
+		}
+	}
+
+	if opts.SetUpstream {
+		locals, remotes := pushedBranches(refSpecs)
+		for i, local := range locals {
+			if err := setUpstream(repo, local.Short(), remote, remotes[i].Short()); err != nil {
+				return "", err
+			}
+			result += fmt.Sprintf("\nBranch '%s' set up to track '%s/%s'", local.Short(), remote, remotes[i].Short())
+		}
+	}
+
+	return result, nil
+}
+
+// pushRefSpecs returns the refspecs pushed with opts
+func pushRefSpecs(repo *git.Repository, opts PushOptions) ([]config.RefSpec, error) {
+	var refSpecs []config.RefSpec
+	switch {
+	case opts.Mirror:
+		refSpecs = append(refSpecs, config.RefSpec("+refs/*:refs/*"))
+	case opts.Delete != "":
+		if opts.Refspec != "" || opts.SetUpstream {
+			return nil, fmt.Errorf("delete cannot be combined with refspec or set_upstream")
+		}
+		name := opts.Delete
+		if !strings.HasPrefix(name, "refs/") {
+			name = plumbing.NewBranchReferenceName(name).String()
+		}
+		refSpecs = append(refSpecs, config.RefSpec(":"+name))
+	case opts.Refspec != "":
+		refSpecs = append(refSpecs, config.RefSpec(opts.Refspec))
+	case opts.SetUpstream:
+		head, err := repo.Head()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get HEAD: %w", err)
+		}
+		if !head.Name().IsBranch() {
+			return nil, fmt.Errorf("HEAD is detached, give a refspec to set an upstream")
+		}
+		refSpecs = append(refSpecs, config.RefSpec(head.Name()+":"+head.Name()))
+	default:
+		refSpecs = append(refSpecs, config.RefSpec(config.DefaultPushRefSpec))
+	}
+
+	// If tags flag is set, push tags
+	if opts.Tags {
+		refSpecs = append(refSpecs, config.RefSpec("refs/tags/*:refs/tags/*"))
+	}
+
+	for _, refSpec := range refSpecs {
+		if err := refSpec.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid refspec %s: %w", refSpec, err)
+		}
+	}
+	if locals, _ := pushedBranches(refSpecs); opts.SetUpstream && len(locals) == 0 {
+		return nil, fmt.Errorf("set_upstream needs a refspec pushing a branch, like refs/heads/main:refs/heads/main")
+	}
+	return refSpecs, nil
+}
+
+// mirrorDeletions returns the refspecs deleting the remote references that
+// do not exist locally. go-git's pruning cannot be used: it deletes every
+// reference of forced refspecs.
+func mirrorDeletions(repo *git.Repository, remote *git.Remote, auth transport.AuthMethod) ([]config.RefSpec, error) {
+	advertised, err := remote.List(&git.ListOptions{Auth: auth})
+	if err == transport.ErrEmptyRemoteRepository {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remote references: %w", err)
+	}
+
+	var deletions []config.RefSpec
+	for _, ref := range advertised {
+		if ref.Type() != plumbing.HashReference || ref.Name() == plumbing.HEAD {
+			continue
+		}
+		if _, err := repo.Reference(ref.Name(), false); err == plumbing.ErrReferenceNotFound {
+			deletions = append(deletions, config.RefSpec(":"+ref.Name().String()))
+		}
+	}
+	return deletions, nil
+}
+
+// pushOptionValues splits push options into the keys and values sent by
+// go-git, which only sends them to servers advertising the push-options
+// capability. Failing the push instead of silently dropping them matters:
+// an option like ci.skip must not be assumed applied.
+func pushOptionValues(remote *git.Remote, auth transport.AuthMethod, options []string) (map[string]string, error) {
+	if len(options) == 0 {
+		return nil, nil
+	}
+
+	values := make(map[string]string, len(options))
+	for _, option := range options {
+		if option == "" || strings.ContainsAny(option, "\n\x00") {
+			return nil, fmt.Errorf("invalid push option %q", option)
+		}
+		// Options without a value are sent as "key=", which servers read
+		// like "key"
+		key, value, _ := strings.Cut(option, "=")
+		if _, ok := values[key]; ok {
+			return nil, fmt.Errorf("push option %s is given more than once", key)
+		}
+		values[key] = value
+	}
+
+	url := remote.Config().URLs[0]
+	endpoint, err := transport.NewEndpoint(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote URL %s: %w", url, err)
+	}
+	cli, err := client.NewClient(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	session, err := cli.NewReceivePackSession(endpoint, auth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", remote.Config().Name, err)
+	}
+	defer session.Close()
+	advertised, err := session.AdvertisedReferences()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", remote.Config().Name, err)
+	}
+	if !advertised.Capabilities.Supports(capability.PushOptions) {
+		return nil, fmt.Errorf("remote %s does not support push options", remote.Config().Name)
+	}
+	return values, nil
+}
+
+// pushedBranches returns the local branches pushed to a single remote
+// branch by refSpecs, and those remote branches
+func pushedBranches(refSpecs []config.RefSpec) ([]plumbing.ReferenceName, []plumbing.ReferenceName) {
+	var locals, remotes []plumbing.ReferenceName
+	for _, refSpec := range refSpecs {
+		if refSpec.IsWildcard() || refSpec.IsDelete() {
+			continue
+		}
+		src := plumbing.ReferenceName(refSpec.Src())
+		if dst := refSpec.Dst(src); src.IsBranch() && dst.IsBranch() {
+			locals = append(locals, src)
+			remotes = append(remotes, dst)
+		}
+	}
+	return locals, remotes
+}
+
+// pushPlan reports the remote references a push would update and whether
+// the remote would accept them, without pushing
+func pushPlan(repo *git.Repository, remote *git.Remote, auth transport.AuthMethod, refSpecs []config.RefSpec, opts PushOptions) (string, error) {
+	remoteName := remote.Config().Name
+	advertised, err := remote.List(&git.ListOptions{Auth: auth})
+	if err != nil && err != transport.ErrEmptyRemoteRepository {
+		return "", fmt.Errorf("failed to list remote references: %w", err)
+	}
+	remoteRefs := make(map[plumbing.ReferenceName]plumbing.Hash)
+	for _, ref := range advertised {
+		if ref.Type() == plumbing.HashReference {
+			remoteRefs[ref.Name()] = ref.Hash()
+		}
+	}
+
+	refs, err := repo.References()
+	if err != nil {
+		return "", fmt.Errorf("failed to list references: %w", err)
+	}
+	var localRefs []*plumbing.Reference
+	refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() == plumbing.HashReference {
+			localRefs = append(localRefs, ref)
+		}
+		return nil
+	})
+	refs.Close()
+
+	var lines []string
+	for _, refSpec := range refSpecs {
+		if refSpec.IsDelete() {
+			name := plumbing.ReferenceName(strings.TrimPrefix(refSpec.String(), ":"))
+			old, ok := remoteRefs[name]
+			if !ok {
+				return "", fmt.Errorf("remote branch '%s' does not exist on %s", opts.Delete, remoteName)
+			}
+			lines = append(lines, fmt.Sprintf("  %s: deleted (was %s)", name.Short(), old.String()[:7]))
+			continue
+		}
+
+		for _, local := range localRefs {
+			if !refSpec.Match(local.Name()) {
+				continue
+			}
+			dst := refSpec.Dst(local.Name())
+			old, new := remoteRefs[dst], local.Hash()
+			if old == new {
+				continue
+			}
+
+			var status string
+			switch {
+			case old.IsZero():
+				status = fmt.Sprintf("new (%s)", new.String()[:7])
+			case opts.ForceWithLease:
+				tracking := plumbing.NewRemoteReferenceName(remoteName, local.Name().Short())
+				ref, err := repo.Reference(tracking, true)
+				if err != nil || ref.Hash() != old {
+					status = fmt.Sprintf("rejected (stale info, remote at %s)", old.String()[:7])
+				} else {
+					status = fmt.Sprintf("%s...%s (forced)", old.String()[:7], new.String()[:7])
+				}
+			case opts.Force || refSpec.IsForceUpdate():
+				status = fmt.Sprintf("%s...%s (forced)", old.String()[:7], new.String()[:7])
+			case isFastForward(repo, old, new):
+				status = fmt.Sprintf("%s..%s", old.String()[:7], new.String()[:7])
+			default:
+				status = fmt.Sprintf("rejected (non-fast-forward, remote at %s)", old.String()[:7])
+			}
+			lines = append(lines, fmt.Sprintf("  %s -> %s: %s", local.Name().Short(), dst.Short(), status))
+		}
+	}
+	sort.Strings(lines)
+
+	if len(lines) == 0 {
+		return fmt.Sprintf("Dry run: everything up-to-date with %s", remoteName), nil
+	}
+	result := fmt.Sprintf("Dry run, would push to %s:\n%s", remoteName, strings.Join(lines, "\n"))
+	if len(opts.Options) > 0 {
+		result += fmt.Sprintf("\nPush options: %s", strings.Join(opts.Options, ", "))
+	}
+	if opts.SetUpstream {
+		locals, remotes := pushedBranches(refSpecs)
+		for i, local := range locals {
+			result += fmt.Sprintf("\nWould set branch '%s' up to track '%s/%s'", local.Short(), remoteName, remotes[i].Short())
+		}
+	}
+	return result, nil
+}
+
+// isFastForward reports whether the commit new descends from old, false
+// when old is not known locally
+func isFastForward(repo *git.Repository, old, new plumbing.Hash) bool {
+	oldCommit, err := repo.CommitObject(old)
+	if err != nil {
+		return false
+	}
+	newCommit, err := repo.CommitObject(new)
+	if err != nil {
+		return false
+	}
+	isAncestor, err := oldCommit.IsAncestor(newCommit)
+	return err == nil && isAncestor
+}
+
+// PushTags pushes tags to remote repository
+func (g *Operations) PushTags(repoPath, remote string, tagName string, creds *Credentials) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	if remote == "" {
+		remote = "origin"
+	}
+
+	remoteObj, err := repo.Remote(remote)
+	if err != nil {
+		return "", fmt.Errorf("failed to get remote '%s': %w", remote, err)
+	}
+	auth, err := g.remoteAuth(remoteObj, creds)
+	if err != nil {
+		return "", err
+	}
+
+	var refSpecs []config.RefSpec
+	var message string
+
+	if tagName != "" {
+		// Push specific tag
+		refSpecs = []config.RefSpec{config.RefSpec("refs/tags/" + tagName + ":refs/tags/" + tagName)}
+		message = fmt.Sprintf("Pushed tag '%s' to %s", tagName, remote)
+	} else {
+		// Push all tags
+		refSpecs = []config.RefSpec{config.RefSpec("refs/tags/*:refs/tags/*")}
+		message = fmt.Sprintf("Pushed all tags to %s", remote)
+	}
+
+	traced, span := g.trace("git.push", tracing.KindClient, tracing.String("git.remote", remote))
+	err = remoteObj.PushContext(traced.context(), &git.PushOptions{
+		RemoteName: remote,
+		RefSpecs:   refSpecs,
+		Auth:       auth,
+	})
+	endNetworkSpan(span, err)
+
+	if err != nil {
+		if err == git.NoErrAlreadyUpToDate {
+			return "Everything up-to-date", nil
+		}
+		return "", fmt.Errorf("failed to push tags: %w", err)
+	}
+
+	return message, nil
+}
+
+// Fetch downloads the branches and tags of a remote, reporting the
+// references it created or moved
+func (g *Operations) Fetch(repoPath string, opts FetchOptions) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	if opts.Depth < 0 {
+		return "", fmt.Errorf("depth cannot be negative")
+	}
+
+	remote := opts.Remote
+	if remote == "" {
+		remote = "origin"
+	}
+
+	before, err := referenceHashes(repo)
+	if err != nil {
+		return "", err
+	}
+
+	source := remote
+	var pruned []plumbing.ReferenceName
+	if opts.Bundle != "" {
+		if opts.Prune || opts.Filter != "" || opts.Depth > 0 {
+			return "", fmt.Errorf("bundle cannot be combined with prune, filter or depth")
+		}
+		if err := g.fetchBundle(repoPath, remote, opts); err != nil {
+			return "", err
+		}
+		source = opts.Bundle
+	} else {
+		remoteObj, err := repo.Remote(remote)
+		if err != nil {
+			return "", fmt.Errorf("failed to get remote '%s': %w", remote, err)
+		}
+		auth, err := g.remoteAuth(remoteObj, opts.Credentials)
+		if err != nil {
+			return "", err
+		}
+
+		if opts.Filter != "" {
+			if err := g.fetchFiltered(repoPath, remote, auth, opts); err != nil {
+				return "", err
+			}
+		} else {
+			fetchOptions := &git.FetchOptions{RemoteName: remote, Depth: opts.Depth, Auth: auth}
+			if opts.Tags {
+				fetchOptions.Tags = git.AllTags
+			}
+			traced, span := g.trace("git.fetch", tracing.KindClient, tracing.String("git.remote", remote))
+			err = remoteObj.FetchContext(traced.context(), fetchOptions)
+			endNetworkSpan(span, err)
+			if err != nil && err != git.NoErrAlreadyUpToDate {
+				return "", fmt.Errorf("failed to fetch: %w", err)
+			}
+		}
+
+		if opts.Prune {
+			pruned, err = pruneRemote(repo, remoteObj, auth, false)
+			if err != nil {
+				return "", err
+			}
+		}
+	}
+
+	after, err := referenceHashes(repo)
+	if err != nil {
+		return "", err
+	}
+
+	var changes []string
+	for name, hash := range after {
+		switch old, ok := before[name]; {
+		case !ok:
+			changes = append(changes, fmt.Sprintf("  %s: new (%s)", name.Short(), hash.String()[:7]))
+		case old != hash:
+			changes = append(changes, fmt.Sprintf("  %s: %s..%s", name.Short(), old.String()[:7], hash.String()[:7]))
+		}
+	}
+	for _, name := range pruned {
+		changes = append(changes, fmt.Sprintf("  %s: deleted (was %s)", name.Short(), before[name].String()[:7]))
+	}
+	sort.Strings(changes)
+
+	if len(changes) == 0 {
+		return fmt.Sprintf("Already up to date with %s", source), nil
+	}
+	return fmt.Sprintf("Fetched %s, %d reference(s) updated:\n%s", source, len(changes), strings.Join(changes, "\n")), nil
+}
+
+// fetchFiltered fetches with a partial clone filter through the git
+// executable, go-git not supporting filters
+func (g *Operations) fetchFiltered(repoPath, remote string, auth transport.AuthMethod, opts FetchOptions) error {
+	if err := validateFilter(opts.Filter); err != nil {
+		return err
+	}
+	if strings.HasPrefix(remote, "-") {
+		return fmt.Errorf("invalid remote name %s", remote)
+	}
+
+	args := []string{"fetch", "--filter=" + opts.Filter}
+	if opts.Depth > 0 {
+		args = append(args, fmt.Sprintf("--depth=%d", opts.Depth))
+	}
+	if opts.Tags {
+		args = append(args, "--tags")
+	}
+	args = append(args, remote)
+
+	if output, err := g.runCommand(g.gitCommandAuth(repoPath, auth, args...)); err != nil {
+		if _, ok := err.(*DubiousOwnershipError); ok {
+			return err
+		}
+		return fmt.Errorf("failed to fetch: %w\n%s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// validateFilter checks a partial clone filter, e.g. blob:none,
+// blob:limit=1m or tree:0
+func validateFilter(filter string) error {
+	kind, _, _ := strings.Cut(filter, ":")
+	switch kind {
+	case "blob", "tree", "sparse", "object", "combine":
+	default:
+		return fmt.Errorf("invalid filter %s, expected e.g. blob:none, blob:limit=1m or tree:0", filter)
+	}
+	if strings.ContainsAny(filter, " \t\n") {
+		return fmt.Errorf("invalid filter %s", filter)
+	}
+	return nil
+}
+
+// RemotePrune deletes the remote-tracking branches of a remote whose
+// branches were deleted from it, or only lists them with dryRun
+func (g *Operations) RemotePrune(repoPath, remote string, dryRun bool, creds *Credentials) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	if remote == "" {
+		remote = "origin"
+	}
+	remoteObj, err := repo.Remote(remote)
+	if err != nil {
+		return "", fmt.Errorf("failed to get remote '%s': %w", remote, err)
+	}
+	auth, err := g.remoteAuth(remoteObj, creds)
+	if err != nil {
+		return "", err
+	}
+
+	before, err := referenceHashes(repo)
+	if err != nil {
+		return "", err
+	}
+	pruned, err := pruneRemote(repo, remoteObj, auth, dryRun)
+	if err != nil {
+		return "", err
+	}
+	if len(pruned) == 0 {
+		return fmt.Sprintf("No stale remote-tracking branches of %s", remote), nil
+	}
+
+	verb := "Pruned"
+	if dryRun {
+		verb = "Would prune"
+	}
+	var result strings.Builder
+	fmt.Fprintf(&result, "%s %d stale remote-tracking branch(es) of %s:", verb, len(pruned), remote)
+	for _, name := range pruned {
+		fmt.Fprintf(&result, "\n  %s (was %s)", name.Short(), before[name].String()[:7])
+	}
+	return result.String(), nil
+}
+
+// pruneRemote deletes the remote-tracking branches a fetch refspec of the
+// remote maps from a branch the remote no longer has, or only returns them
+// with dryRun
+func pruneRemote(repo *git.Repository, remote *git.Remote, auth transport.AuthMethod, dryRun bool) ([]plumbing.ReferenceName, error) {
+	advertised, err := remote.List(&git.ListOptions{Auth: auth})
+	if err != nil && err != transport.ErrEmptyRemoteRepository {
+		return nil, fmt.Errorf("failed to list remote references: %w", err)
+	}
+	remoteRefs := make(map[plumbing.ReferenceName]bool, len(advertised))
+	for _, ref := range advertised {
+		remoteRefs[ref.Name()] = true
+	}
+
+	// Reversed refspecs map remote-tracking branches back to the remote
+	// branches they were fetched from
+	var reversed []config.RefSpec
+	for _, refSpec := range remote.Config().Fetch {
+		reversed = append(reversed, config.RefSpec(strings.TrimPrefix(refSpec.String(), "+")).Reverse())
+	}
+
+	refs, err := repo.References()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list references: %w", err)
+	}
+	defer refs.Close()
+
+	var stale []plumbing.ReferenceName
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() != plumbing.HashReference || !ref.Name().IsRemote() {
+			return nil
+		}
+		matched := false
+		for _, refSpec := range reversed {
+			if refSpec.Match(ref.Name()) {
+				matched = true
+				if remoteRefs[refSpec.Dst(ref.Name())] {
+					return nil
+				}
+			}
+		}
+		if matched {
+			stale = append(stale, ref.Name())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list references: %w", err)
+	}
+	sort.Slice(stale, func(i, j int) bool { return stale[i] < stale[j] })
+
+	if !dryRun {
+		for _, name := range stale {
+			if err := repo.Storer.RemoveReference(name); err != nil {
+				return nil, fmt.Errorf("failed to delete %s: %w", name.Short(), err)
+			}
+		}
+	}
+	return stale, nil
+}
+
+// referenceHashes returns the hashes of the remote-tracking branches and
+// tags of a repository
+func referenceHashes(repo *git.Repository) (map[plumbing.ReferenceName]plumbing.Hash, error) {
+	refs, err := repo.References()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list references: %w", err)
+	}
+	defer refs.Close()
+
+	hashes := make(map[plumbing.ReferenceName]plumbing.Hash)
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() == plumbing.HashReference && (ref.Name().IsRemote() || ref.Name().IsTag()) {
+			hashes[ref.Name()] = ref.Hash()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list references: %w", err)
+	}
+	return hashes, nil
+}
+
+// Clone clones a repository into a new directory
+func (g *Operations) Clone(url, repoPath string, opts CloneOptions) (string, error) {
+	if url == "" {
+		return "", fmt.Errorf("URL is required")
+	}
+	if repoPath == "" {
+		return "", fmt.Errorf("repository path cannot be empty")
+	}
+	if entries, err := os.ReadDir(repoPath); err == nil && len(entries) > 0 {
+		return "", fmt.Errorf("destination %s already exists and is not empty", repoPath)
+	}
+
+	if opts.Depth < 0 {
+		return "", fmt.Errorf("depth cannot be negative")
+	}
+	if opts.Mirror && (opts.Branch != "" || opts.SingleBranch) {
+		return "", fmt.Errorf("mirror cannot be combined with branch or single_branch")
+	}
+
+	auth, err := g.authFor(url, opts.Credentials)
+	if err != nil {
+		return "", err
+	}
+
+	bundle := isBundle(url)
+	var repo *git.Repository
+	if opts.Filter != "" || bundle {
+		repo, err = g.cloneWithGit(url, repoPath, auth, opts)
+	} else {
+		cloneOptions := &git.CloneOptions{URL: url, Auth: auth, Depth: opts.Depth, SingleBranch: opts.SingleBranch, Mirror: opts.Mirror}
+		if opts.Branch != "" {
+			cloneOptions.ReferenceName = plumbing.NewBranchReferenceName(opts.Branch)
+		}
+		traced, span := g.trace("git.clone", tracing.KindClient)
+		repo, err = git.PlainCloneContext(traced.context(), repoPath, opts.Bare, cloneOptions)
+		span.End(err)
+	}
+	if err != nil {
+		// Do not leave a partial clone behind
+		os.RemoveAll(repoPath)
+		return "", fmt.Errorf("failed to clone %s: %w", url, err)
+	}
+
+	result := fmt.Sprintf("Cloned %s into %s", url, repoPath)
+	if head, err := repo.Head(); err == nil {
+		result += fmt.Sprintf(" (%s at %s)", head.Name().Short(), head.Hash().String()[:7])
+	} else {
+		result += " (empty repository)"
+	}
+	if bundle {
+		result += "\nCloned from a bundle file, which stays the URL of origin"
+	}
+	if opts.Depth > 0 {
+		result += fmt.Sprintf("\nShallow clone with the last %d commit(s)", opts.Depth)
+	}
+	if opts.SingleBranch {
+		result += "\nSingle branch clone"
+	}
+	if opts.Mirror {
+		result += "\nMirror clone of all references, overwritten by later fetches"
+	}
+	if opts.Filter != "" {
+		result += fmt.Sprintf("\nPartial clone with filter %s, missing objects are downloaded by the git executable when needed", opts.Filter)
+	}
+	return result, nil
+}
+
+// cloneWithGit makes partial clones and clones of bundle files through the
+// git executable, go-git supporting neither
+func (g *Operations) cloneWithGit(url, repoPath string, auth transport.AuthMethod, opts CloneOptions) (*git.Repository, error) {
+	args := []string{"clone"}
+	if opts.Filter != "" {
+		if err := validateFilter(opts.Filter); err != nil {
+			return nil, err
+		}
+		args = append(args, "--filter="+opts.Filter)
+	}
+	if opts.Depth > 0 {
+		args = append(args, fmt.Sprintf("--depth=%d", opts.Depth))
+	}
+	if opts.SingleBranch {
+		args = append(args, "--single-branch")
+	}
+	if opts.Branch != "" {
+		args = append(args, "--branch="+opts.Branch)
+	}
+	if opts.Mirror {
+		args = append(args, "--mirror")
+	} else if opts.Bare {
+		args = append(args, "--bare")
+	}
+	args = append(args, "--", url, repoPath)
+
+	if output, err := g.runCommand(g.gitCommandAuth("", auth, args...)); err != nil {
+		if _, ok := err.(*DubiousOwnershipError); ok {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w\n%s", err, strings.TrimSpace(string(output)))
+	}
+	return git.PlainOpen(repoPath)
+}
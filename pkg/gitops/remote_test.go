@@ -0,0 +1,489 @@
+package gitops
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+)
+
+func TestOperations_CloneFetchPush(t *testing.T) {
+	upstreamDir, upstream := createTestRepo(t)
+	defer os.RemoveAll(upstreamDir)
+	workDir := t.TempDir()
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	cloneDir := filepath.Join(workDir, "clone")
+	result, err := ops.Clone(upstreamDir, cloneDir, CloneOptions{})
+	if err != nil {
+		t.Fatalf("Clone failed: %v", err)
+	}
+	if !strings.HasPrefix(result, "Cloned "+upstreamDir+" into "+cloneDir+" (master at ") {
+		t.Errorf("Unexpected clone result: %s", result)
+	}
+	if _, err := ops.Clone(upstreamDir, cloneDir, CloneOptions{}); err == nil {
+		t.Error("Expected error when cloning into a non-empty directory")
+	}
+	if _, err := ops.Clone(filepath.Join(workDir, "missing"), filepath.Join(workDir, "failed"), CloneOptions{}); err == nil {
+		t.Error("Expected error when cloning a missing repository")
+	}
+	if _, err := os.Stat(filepath.Join(workDir, "failed")); !os.IsNotExist(err) {
+		t.Error("Expected a failed clone to leave no directory behind")
+	}
+
+	result, err = ops.Fetch(cloneDir, FetchOptions{})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if result != "Already up to date with origin" {
+		t.Errorf("Unexpected fetch result: %s", result)
+	}
+
+	if _, err := ops.Commit(upstreamDir, "Upstream change", CommitOptions{AllowEmpty: true}); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if _, err := ops.CreateTag(upstreamDir, "v1.0", "", false, false); err != nil {
+		t.Fatalf("CreateTag failed: %v", err)
+	}
+	result, err = ops.Fetch(cloneDir, FetchOptions{Tags: true})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if !strings.Contains(result, "2 reference(s) updated") || !strings.Contains(result, "origin/master: ") || !strings.Contains(result, "v1.0: new") {
+		t.Errorf("Unexpected fetch result: %s", result)
+	}
+
+	// Pushing to a bare clone
+	bareDir := filepath.Join(workDir, "bare.git")
+	if _, err := ops.Clone(upstreamDir, bareDir, CloneOptions{Bare: true}); err != nil {
+		t.Fatalf("Bare clone failed: %v", err)
+	}
+	if _, err := upstream.CreateRemote(&config.RemoteConfig{Name: "backup", URLs: []string{bareDir}}); err != nil {
+		t.Fatalf("Failed to add remote: %v", err)
+	}
+	if _, err := ops.Commit(upstreamDir, "Pushed change", CommitOptions{AllowEmpty: true}); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if _, err := ops.Push(upstreamDir, PushOptions{Remote: "backup"}); err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+	bare, _ := git.PlainOpen(bareDir)
+	head, _ := upstream.Head()
+	if ref, err := bare.Reference("refs/heads/master", true); err != nil || ref.Hash() != head.Hash() {
+		t.Errorf("Expected master to be pushed, got %v (%v)", ref, err)
+	}
+}
+
+func TestOperations_AuthFor(t *testing.T) {
+	ops := NewOperations("Test User", "test@example.com")
+	ops.SetAuth(&http.BasicAuth{Username: "server", Password: "secret"})
+
+	auth, err := ops.authFor("https://example.com/repo.git", nil)
+	if basic, ok := auth.(*http.BasicAuth); err != nil || !ok || basic.Username != "server" {
+		t.Errorf("Expected the server credentials for HTTPS, got %v (%v)", auth, err)
+	}
+	auth, err = ops.authFor("https://example.com/repo.git", &Credentials{Password: "token"})
+	if basic, ok := auth.(*http.BasicAuth); err != nil || !ok || basic.Username != "git" || basic.Password != "token" {
+		t.Errorf("Expected the call credentials, got %v (%v)", auth, err)
+	}
+
+	auth, err = ops.authFor("git@example.com:repo.git", nil)
+	if err != nil || auth != nil {
+		t.Errorf("Expected no HTTP credentials for SSH, got %v (%v)", auth, err)
+	}
+	if _, err := ops.authFor("git@example.com:repo.git", &Credentials{Password: "token"}); err == nil {
+		t.Error("Expected error for a token on an SSH remote")
+	}
+
+	// SSH remotes use the SSH authentication, with the URL's user
+	keyFile, _ := writeSSHSigningKey(t, t.TempDir(), nil)
+	key, err := LoadSSHKey(keyFile, nil)
+	if err != nil {
+		t.Fatalf("LoadSSHKey failed: %v", err)
+	}
+	ops.SetSSHAuth(&SSHAuth{Key: key, KeyFile: keyFile, InsecureIgnoreHostKey: true})
+	auth, err = ops.authFor("deploy@example.com:repo.git", nil)
+	if keys, ok := auth.(*gitssh.PublicKeys); err != nil || !ok || keys.User != "deploy" || keys.HostKeyCallback == nil {
+		t.Errorf("Expected the SSH key, got %v (%v)", auth, err)
+	}
+	auth, _ = ops.authFor("ssh://example.com/repo.git", nil)
+	if keys, ok := auth.(*gitssh.PublicKeys); !ok || keys.User != "git" {
+		t.Errorf("Expected the git user by default, got %v", auth)
+	}
+	if command := ops.sshCommand(); !strings.Contains(command, "-i '"+keyFile+"'") || !strings.Contains(command, "StrictHostKeyChecking=no") {
+		t.Errorf("Unexpected GIT_SSH_COMMAND: %s", command)
+	}
+
+	ops.SetSSHAuth(&SSHAuth{Key: key, KnownHosts: []string{filepath.Join(t.TempDir(), "missing")}})
+	if _, err := ops.authFor("git@example.com:repo.git", nil); err == nil {
+		t.Error("Expected error for a missing known_hosts file")
+	}
+}
+
+func TestOperations_PushRewrittenBranch(t *testing.T) {
+	upstreamDir, _ := createTestRepo(t)
+	defer os.RemoveAll(upstreamDir)
+	workDir := t.TempDir()
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	bareDir := filepath.Join(workDir, "bare.git")
+	if _, err := ops.Clone(upstreamDir, bareDir, CloneOptions{Bare: true}); err != nil {
+		t.Fatalf("Bare clone failed: %v", err)
+	}
+	cloneDir := filepath.Join(workDir, "clone")
+	if _, err := ops.Clone(bareDir, cloneDir, CloneOptions{}); err != nil {
+		t.Fatalf("Clone failed: %v", err)
+	}
+	clone, _ := git.PlainOpen(cloneDir)
+	bare, _ := git.PlainOpen(bareDir)
+
+	// Upstream tracking of a new branch
+	if _, err := ops.Checkout(cloneDir, "feature", true, ""); err != nil {
+		t.Fatalf("Checkout failed: %v", err)
+	}
+	if _, err := ops.Commit(cloneDir, "Feature work", CommitOptions{AllowEmpty: true}); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	result, err := ops.Push(cloneDir, PushOptions{SetUpstream: true, DryRun: true})
+	if err != nil {
+		t.Fatalf("Dry run failed: %v", err)
+	}
+	if !strings.Contains(result, "feature -> feature: new") || !strings.Contains(result, "Would set branch 'feature'") {
+		t.Errorf("Unexpected dry run: %s", result)
+	}
+	if _, err := bare.Reference("refs/heads/feature", true); err == nil {
+		t.Error("Expected a dry run not to push")
+	}
+	result, err = ops.Push(cloneDir, PushOptions{SetUpstream: true})
+	if err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+	if !strings.Contains(result, "Branch 'feature' set up to track 'origin/feature'") {
+		t.Errorf("Unexpected push result: %s", result)
+	}
+	cfg, _ := clone.Config()
+	if branch := cfg.Branches["feature"]; branch == nil || branch.Remote != "origin" || branch.Merge != "refs/heads/feature" {
+		t.Errorf("Expected feature to track origin/feature, got %+v", branch)
+	}
+
+	// Rewriting the pushed commit needs a forced push
+	if _, err := ops.Reset(cloneDir, "soft", "HEAD~1", nil, false); err != nil {
+		t.Fatalf("Reset failed: %v", err)
+	}
+	if _, err := ops.Commit(cloneDir, "Feature work, rewritten", CommitOptions{AllowEmpty: true}); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	refspec := "refs/heads/feature:refs/heads/feature"
+	result, _ = ops.Push(cloneDir, PushOptions{Refspec: refspec, DryRun: true})
+	if !strings.Contains(result, "rejected (non-fast-forward") {
+		t.Errorf("Expected a rejected update, got: %s", result)
+	}
+	if _, err := ops.Push(cloneDir, PushOptions{Refspec: refspec}); err == nil {
+		t.Error("Expected a non-fast-forward push to fail")
+	}
+	if _, err := ops.Push(cloneDir, PushOptions{Refspec: refspec, Force: true, ForceWithLease: true}); err == nil {
+		t.Error("Expected error for force combined with force_with_lease")
+	}
+	if _, err := ops.Push(cloneDir, PushOptions{Refspec: refspec, ForceWithLease: true}); err != nil {
+		t.Fatalf("Push with lease failed: %v", err)
+	}
+	head, _ := clone.Head()
+	if ref, err := bare.Reference("refs/heads/feature", true); err != nil || ref.Hash() != head.Hash() {
+		t.Errorf("Expected feature to be forced, got %v (%v)", ref, err)
+	}
+
+	// Deleting the remote branch
+	result, err = ops.Push(cloneDir, PushOptions{Delete: "feature"})
+	if err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if result != "Deleted branch 'feature' from origin" {
+		t.Errorf("Unexpected delete result: %s", result)
+	}
+	if _, err := bare.Reference("refs/heads/feature", true); err == nil {
+		t.Error("Expected feature to be deleted from the remote")
+	}
+	if _, err := ops.Push(cloneDir, PushOptions{Delete: "feature"}); err == nil {
+		t.Error("Expected error when deleting a missing remote branch")
+	}
+}
+
+func TestOperations_PushOptions(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	upstreamDir, upstream := createTestRepo(t)
+	defer os.RemoveAll(upstreamDir)
+	workDir := t.TempDir()
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	bareDir := filepath.Join(workDir, "bare.git")
+	if _, err := ops.Clone(upstreamDir, bareDir, CloneOptions{Bare: true}); err != nil {
+		t.Fatalf("Bare clone failed: %v", err)
+	}
+	if _, err := upstream.CreateRemote(&config.RemoteConfig{Name: "backup", URLs: []string{bareDir}}); err != nil {
+		t.Fatalf("Failed to add remote: %v", err)
+	}
+	if _, err := ops.Commit(upstreamDir, "Skip CI", CommitOptions{AllowEmpty: true}); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	options := []string{"ci.skip", "merge_request.target=main"}
+	if _, err := ops.Push(upstreamDir, PushOptions{Remote: "backup", Options: options}); err == nil {
+		t.Error("Expected error for a remote without push options")
+	}
+
+	// A hook recording the options received
+	received := filepath.Join(workDir, "options")
+	hook := "#!/bin/sh\nfor i in $(seq 0 $(($GIT_PUSH_OPTION_COUNT - 1))); do eval echo \\$GIT_PUSH_OPTION_$i; done > " + received + "\n"
+	os.MkdirAll(filepath.Join(bareDir, "hooks"), 0755)
+	if err := os.WriteFile(filepath.Join(bareDir, "hooks", "pre-receive"), []byte(hook), 0755); err != nil {
+		t.Fatalf("Failed to write hook: %v", err)
+	}
+	bare, _ := git.PlainOpen(bareDir)
+	cfg, _ := bare.Config()
+	cfg.Raw.Section("receive").SetOption("advertisePushOptions", "true")
+	if err := bare.SetConfig(cfg); err != nil {
+		t.Fatalf("Failed to set config: %v", err)
+	}
+
+	if _, err := ops.Push(upstreamDir, PushOptions{Remote: "backup", Options: []string{"ci.skip", "ci.skip"}}); err == nil {
+		t.Error("Expected error for a repeated push option")
+	}
+	result, err := ops.Push(upstreamDir, PushOptions{Remote: "backup", Options: options})
+	if err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+	if !strings.HasSuffix(result, "with push options: ci.skip, merge_request.target=main") {
+		t.Errorf("Unexpected push result: %s", result)
+	}
+	data, err := os.ReadFile(received)
+	if err != nil {
+		t.Fatalf("Expected the hook to run: %v", err)
+	}
+	got := strings.Fields(string(data))
+	sort.Strings(got)
+	if len(got) != 2 || got[0] != "ci.skip=" || got[1] != "merge_request.target=main" {
+		t.Errorf("Unexpected push options received: %q", data)
+	}
+}
+
+func TestOperations_Prune(t *testing.T) {
+	upstreamDir, _ := createTestRepo(t)
+	defer os.RemoveAll(upstreamDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	if _, err := ops.CreateBranch(upstreamDir, "stale", ""); err != nil {
+		t.Fatalf("CreateBranch failed: %v", err)
+	}
+	cloneDir := filepath.Join(t.TempDir(), "clone")
+	if _, err := ops.Clone(upstreamDir, cloneDir, CloneOptions{}); err != nil {
+		t.Fatalf("Clone failed: %v", err)
+	}
+	clone, _ := git.PlainOpen(cloneDir)
+	if _, err := clone.Reference("refs/remotes/origin/stale", true); err != nil {
+		t.Fatalf("Expected origin/stale after the clone: %v", err)
+	}
+
+	result, err := ops.RemotePrune(cloneDir, "", false, nil)
+	if err != nil {
+		t.Fatalf("RemotePrune failed: %v", err)
+	}
+	if result != "No stale remote-tracking branches of origin" {
+		t.Errorf("Unexpected prune result: %s", result)
+	}
+
+	if _, err := ops.DeleteBranch(upstreamDir, "stale", true); err != nil {
+		t.Fatalf("DeleteBranch failed: %v", err)
+	}
+	result, err = ops.RemotePrune(cloneDir, "origin", true, nil)
+	if err != nil {
+		t.Fatalf("RemotePrune failed: %v", err)
+	}
+	if !strings.HasPrefix(result, "Would prune 1 stale remote-tracking branch(es) of origin:\n  origin/stale (was ") {
+		t.Errorf("Unexpected dry run: %s", result)
+	}
+	if _, err := clone.Reference("refs/remotes/origin/stale", true); err != nil {
+		t.Error("Expected a dry run to keep origin/stale")
+	}
+
+	result, err = ops.Fetch(cloneDir, FetchOptions{Prune: true})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if !strings.Contains(result, "1 reference(s) updated") || !strings.Contains(result, "origin/stale: deleted") {
+		t.Errorf("Unexpected fetch result: %s", result)
+	}
+	if _, err := clone.Reference("refs/remotes/origin/stale", true); err == nil {
+		t.Error("Expected origin/stale to be pruned")
+	}
+	if _, err := clone.Reference("refs/remotes/origin/master", true); err != nil {
+		t.Errorf("Expected origin/master to be kept: %v", err)
+	}
+}
+
+func TestOperations_ShallowAndPartialClone(t *testing.T) {
+	upstreamDir, upstream := createTestRepo(t)
+	defer os.RemoveAll(upstreamDir)
+	workDir := t.TempDir()
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	if _, err := ops.CreateBranch(upstreamDir, "other", ""); err != nil {
+		t.Fatalf("CreateBranch failed: %v", err)
+	}
+	for _, message := range []string{"Second", "Third"} {
+		if _, err := ops.Commit(upstreamDir, message, CommitOptions{AllowEmpty: true}); err != nil {
+			t.Fatalf("Commit failed: %v", err)
+		}
+	}
+	// Local clones only honor depth and filters over file:// URLs
+	url := "file://" + filepath.ToSlash(upstreamDir)
+
+	shallowDir := filepath.Join(workDir, "shallow")
+	result, err := ops.Clone(url, shallowDir, CloneOptions{Depth: 1, SingleBranch: true})
+	if err != nil {
+		t.Fatalf("Shallow clone failed: %v", err)
+	}
+	if !strings.Contains(result, "Shallow clone with the last 1 commit(s)") || !strings.Contains(result, "Single branch clone") {
+		t.Errorf("Unexpected clone result: %s", result)
+	}
+	shallow, _ := git.PlainOpen(shallowDir)
+	if hashes, err := shallow.Storer.Shallow(); err != nil || len(hashes) != 1 {
+		t.Errorf("Expected one shallow commit, got %v (%v)", hashes, err)
+	}
+	if _, err := shallow.Reference("refs/remotes/origin/other", true); err == nil {
+		t.Error("Expected a single branch clone not to fetch other branches")
+	}
+	if _, err := ops.Clone(url, filepath.Join(workDir, "negative"), CloneOptions{Depth: -1}); err == nil {
+		t.Error("Expected error for a negative depth")
+	}
+	if _, err := ops.Clone(url, filepath.Join(workDir, "invalid"), CloneOptions{Filter: "--upload-pack=evil"}); err == nil {
+		t.Error("Expected error for an invalid filter")
+	}
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	cfg, _ := upstream.Config()
+	cfg.Raw.Section("uploadpack").SetOption("allowFilter", "true")
+	if err := upstream.SetConfig(cfg); err != nil {
+		t.Fatalf("Failed to set config: %v", err)
+	}
+
+	partialDir := filepath.Join(workDir, "partial")
+	result, err = ops.Clone(url, partialDir, CloneOptions{Filter: "blob:none"})
+	if err != nil {
+		t.Fatalf("Partial clone failed: %v", err)
+	}
+	if !strings.Contains(result, "(master at ") || !strings.Contains(result, "Partial clone with filter blob:none") {
+		t.Errorf("Unexpected clone result: %s", result)
+	}
+	partial, _ := git.PlainOpen(partialDir)
+	partialCfg, _ := partial.Config()
+	if promisor := partialCfg.Raw.Section("remote").Subsection("origin").Option("promisor"); promisor != "true" {
+		t.Errorf("Expected origin to be a promisor remote, got %q", promisor)
+	}
+
+	if _, err := ops.Commit(upstreamDir, "Fourth", CommitOptions{AllowEmpty: true}); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	result, err = ops.Fetch(partialDir, FetchOptions{Filter: "blob:none"})
+	if err != nil {
+		t.Fatalf("Filtered fetch failed: %v", err)
+	}
+	if !strings.Contains(result, "origin/master: ") {
+		t.Errorf("Unexpected fetch result: %s", result)
+	}
+	result, _ = ops.Fetch(partialDir, FetchOptions{Filter: "blob:none"})
+	if result != "Already up to date with origin" {
+		t.Errorf("Unexpected fetch result: %s", result)
+	}
+}
+
+func TestOperations_Mirror(t *testing.T) {
+	upstreamDir, upstream := createTestRepo(t)
+	defer os.RemoveAll(upstreamDir)
+	workDir := t.TempDir()
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	if _, err := ops.CreateBranch(upstreamDir, "other", ""); err != nil {
+		t.Fatalf("CreateBranch failed: %v", err)
+	}
+	if _, err := ops.CreateTag(upstreamDir, "v1.0", "Release 1.0", true, false); err != nil {
+		t.Fatalf("CreateTag failed: %v", err)
+	}
+	head, _ := upstream.Head()
+	notes := plumbing.NewHashReference("refs/notes/commits", head.Hash())
+	if err := upstream.Storer.SetReference(notes); err != nil {
+		t.Fatalf("Failed to create notes: %v", err)
+	}
+	names := []plumbing.ReferenceName{"refs/heads/master", "refs/heads/other", "refs/tags/v1.0", "refs/notes/commits"}
+
+	mirrorDir := filepath.Join(workDir, "mirror.git")
+	if _, err := ops.Clone(upstreamDir, mirrorDir, CloneOptions{Mirror: true, Branch: "other"}); err == nil {
+		t.Error("Expected error for a mirror of a single branch")
+	}
+	result, err := ops.Clone(upstreamDir, mirrorDir, CloneOptions{Mirror: true})
+	if err != nil {
+		t.Fatalf("Mirror clone failed: %v", err)
+	}
+	if !strings.Contains(result, "Mirror clone of all references") {
+		t.Errorf("Unexpected clone result: %s", result)
+	}
+	mirror, _ := git.PlainOpen(mirrorDir)
+	for _, name := range names {
+		if _, err := mirror.Reference(name, false); err != nil {
+			t.Errorf("Expected %s in the mirror: %v", name, err)
+		}
+	}
+
+	// Replicating to another host
+	targetDir := filepath.Join(workDir, "target.git")
+	target, err := git.PlainInit(targetDir, true)
+	if err != nil {
+		t.Fatalf("Failed to init target: %v", err)
+	}
+	if _, err := mirror.CreateRemote(&config.RemoteConfig{Name: "target", URLs: []string{targetDir}}); err != nil {
+		t.Fatalf("Failed to add remote: %v", err)
+	}
+	if _, err := ops.Push(mirrorDir, PushOptions{Remote: "target", Mirror: true, Tags: true}); err == nil {
+		t.Error("Expected error for mirror combined with tags")
+	}
+	if result, err := ops.Push(mirrorDir, PushOptions{Remote: "target", Mirror: true}); err != nil || result != "Mirrored all references to target" {
+		t.Fatalf("Mirror push failed: %s (%v)", result, err)
+	}
+	for _, name := range names {
+		if _, err := target.Reference(name, false); err != nil {
+			t.Errorf("Expected %s in the target: %v", name, err)
+		}
+	}
+
+	// References deleted from the mirror are deleted from the target
+	if err := mirror.Storer.RemoveReference("refs/heads/other"); err != nil {
+		t.Fatalf("Failed to delete branch: %v", err)
+	}
+	result, _ = ops.Push(mirrorDir, PushOptions{Remote: "target", Mirror: true, DryRun: true})
+	if !strings.Contains(result, "other: deleted") {
+		t.Errorf("Expected the deletion in the dry run, got: %s", result)
+	}
+	if _, err := ops.Push(mirrorDir, PushOptions{Remote: "target", Mirror: true}); err != nil {
+		t.Fatalf("Mirror push failed: %v", err)
+	}
+	if _, err := target.Reference("refs/heads/other", false); err == nil {
+		t.Error("Expected other to be deleted from the target")
+	}
+}
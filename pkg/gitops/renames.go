@@ -0,0 +1,97 @@
+package gitops
+
+import (
+	"sort"
+
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// maxRenameCandidates bounds the number of deleted and of added files
+// compared by content when detecting renames. Larger changes only detect
+// exact renames.
+const maxRenameCandidates = 200
+
+// detectRenames pairs deleted and added files whose content is at least
+// threshold percent similar. It returns the remaining pairs and the renames,
+// whose similarity field holds the score.
+func detectRenames(pairs []filePair, threshold int) ([]filePair, []*filePatch) {
+	var deleted, added []int
+	for i, pair := range pairs {
+		switch {
+		case pair.from != nil && pair.to == nil:
+			deleted = append(deleted, i)
+		case pair.from == nil && pair.to != nil:
+			added = append(added, i)
+		}
+	}
+	if len(deleted) == 0 || len(added) == 0 {
+		return pairs, nil
+	}
+
+	type candidate struct {
+		deleted, added, score int
+	}
+	var candidates []candidate
+	inexact := len(deleted) <= maxRenameCandidates && len(added) <= maxRenameCandidates
+	for _, d := range deleted {
+		for _, a := range added {
+			from, to := pairs[d].from, pairs[a].to
+			score := 0
+			switch {
+			case from.hash == to.hash:
+				score = 100
+			case inexact:
+				score = similarity(from.content, to.content)
+			}
+			if score >= threshold {
+				candidates = append(candidates, candidate{d, a, score})
+			}
+		}
+	}
+
+	// The best matches are paired first, each file taking part in one rename
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+	used := make(map[int]bool)
+	var renames []*filePatch
+	for _, c := range candidates {
+		if used[c.deleted] || used[c.added] {
+			continue
+		}
+		used[c.deleted], used[c.added] = true, true
+		renames = append(renames, &filePatch{from: pairs[c.deleted].from, to: pairs[c.added].to, similarity: c.score})
+	}
+
+	remaining := make([]filePair, 0, len(pairs)-2*len(renames))
+	for i, pair := range pairs {
+		if !used[i] {
+			remaining = append(remaining, pair)
+		}
+	}
+	return remaining, renames
+}
+
+// similarity scores how much of the larger of two contents is unchanged in
+// a line diff, from 0 to 100. Binary contents are only similar when equal.
+func similarity(from, to []byte) int {
+	size := len(from)
+	if len(to) > size {
+		size = len(to)
+	}
+	if size == 0 {
+		return 100
+	}
+	if isBinary(from) || isBinary(to) {
+		return 0
+	}
+
+	common := 0
+	for _, d := range diff.Do(string(from), string(to)) {
+		if d.Type == diffmatchpatch.DiffEqual {
+			common += len(d.Text)
+		}
+	}
+	return common * 100 / size
+}
@@ -0,0 +1,189 @@
+package gitops
+
+import (
+	"container/list"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/pengcunfu/go-mcp-git/internal/tracing"
+)
+
+// DefaultRepoCacheSize is the number of repositories whose handles are kept
+// open by default
+const DefaultRepoCacheSize = 16
+
+// RepoCache keeps the handles of recently used repositories open, so that
+// bursts of calls on a repository do not open it every time. Handles are
+// not safe for concurrent use, so an operation checks a handle out and puts
+// it back once done, another operation meanwhile opening its own. Handles
+// are reopened once their packfiles or alternates changed, e.g. after a gc
+// by the git executable: go-git indexes the packfiles once, while refs,
+// config and the index are read anew on every use.
+type RepoCache struct {
+	mu   sync.Mutex
+	size int
+	// idle holds the handles not in use, the most recently used first, at
+	// most one per repository
+	idle   *list.List
+	byKey  map[string]*list.Element
+	inUse  map[*git.Repository]*cachedRepo
+	hits   int64
+	misses int64
+}
+
+// cachedRepo is a handle with what it saw of its repository when opened
+type cachedRepo struct {
+	key         string
+	repo        *git.Repository
+	fingerprint repoFingerprint
+}
+
+// repoFingerprint holds the state of the files go-git reads once per
+// handle
+type repoFingerprint struct {
+	gitDir     string
+	packs      time.Time
+	alternates time.Time
+}
+
+// RepoCacheStats reports the use of a repository cache
+type RepoCacheStats struct {
+	// Size is the maximum number of idle handles, Idle the current one
+	Size int
+	Idle int
+	// Hits and Misses count the opens served from the cache or not
+	Hits   int64
+	Misses int64
+}
+
+// NewRepoCache creates a cache keeping up to size handles. A size of zero
+// disables it, every operation opening its repository.
+func NewRepoCache(size int) *RepoCache {
+	return &RepoCache{
+		size:  size,
+		idle:  list.New(),
+		byKey: make(map[string]*list.Element),
+		inUse: make(map[*git.Repository]*cachedRepo),
+	}
+}
+
+// Open returns a handle of the repository at repoPath, cached if an
+// unchanged one is idle. Put it back with Release.
+func (c *RepoCache) Open(repoPath string) (*git.Repository, error) {
+	if c == nil || c.size == 0 {
+		return git.PlainOpen(repoPath)
+	}
+
+	key := repoKey(repoPath)
+	c.mu.Lock()
+	if element, ok := c.byKey[key]; ok {
+		cached := c.idle.Remove(element).(*cachedRepo)
+		delete(c.byKey, key)
+		c.mu.Unlock()
+		if fingerprint(cached.fingerprint.gitDir) == cached.fingerprint {
+			c.mu.Lock()
+			c.inUse[cached.repo] = cached
+			c.hits++
+			c.mu.Unlock()
+			return cached.repo, nil
+		}
+	} else {
+		c.mu.Unlock()
+	}
+
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	cached := &cachedRepo{key: key, repo: repo}
+	if storage, ok := repo.Storer.(*filesystem.Storage); ok {
+		cached.fingerprint = fingerprint(storage.Filesystem().Root())
+	}
+	c.mu.Lock()
+	c.inUse[repo] = cached
+	c.misses++
+	c.mu.Unlock()
+	return repo, nil
+}
+
+// Release puts back a handle returned by Open, evicting the least recently
+// used handle when the cache is full. The handle must not be used anymore.
+func (c *RepoCache) Release(repo *git.Repository) {
+	if c == nil || c.size == 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.inUse[repo]
+	if !ok {
+		return
+	}
+	delete(c.inUse, repo)
+	if cached.fingerprint.gitDir == "" {
+		// Not on the filesystem, nothing tells when it changed
+		return
+	}
+	if _, ok := c.byKey[cached.key]; ok {
+		// Another operation put back a handle of the repository first
+		return
+	}
+	c.byKey[cached.key] = c.idle.PushFront(cached)
+	for c.idle.Len() > c.size {
+		oldest := c.idle.Remove(c.idle.Back()).(*cachedRepo)
+		delete(c.byKey, oldest.key)
+	}
+}
+
+// Stats reports the use of the cache
+func (c *RepoCache) Stats() RepoCacheStats {
+	if c == nil {
+		return RepoCacheStats{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return RepoCacheStats{Size: c.size, Idle: c.idle.Len(), Hits: c.hits, Misses: c.misses}
+}
+
+// fingerprint reads the state of the packfiles and alternates of a git
+// directory. Missing files leave zero times, so that their creation
+// changes the fingerprint too.
+func fingerprint(gitDir string) repoFingerprint {
+	fp := repoFingerprint{gitDir: gitDir}
+	if info, err := os.Stat(filepath.Join(gitDir, "objects", "pack")); err == nil {
+		fp.packs = info.ModTime()
+	}
+	if info, err := os.Stat(filepath.Join(gitDir, "objects", "info", "alternates")); err == nil {
+		fp.alternates = info.ModTime()
+	}
+	return fp
+}
+
+// SetRepoCache sets the cache of repository handles, nil opening the
+// repository on every operation
+func (g *Operations) SetRepoCache(cache *RepoCache) {
+	g.repos = cache
+}
+
+// RepoCacheStats reports the use of the cache of repository handles
+func (g *Operations) RepoCacheStats() RepoCacheStats {
+	return g.repos.Stats()
+}
+
+// openRepository opens a repository for an operation, which puts it back
+// with releaseRepository once done
+func (g *Operations) openRepository(repoPath string) (*git.Repository, error) {
+	_, span := tracing.Start(g.context(), "git.open", tracing.KindInternal)
+	repo, err := g.repos.Open(repoPath)
+	span.End(err)
+	return repo, err
+}
+
+// releaseRepository puts back a repository opened by openRepository
+func (g *Operations) releaseRepository(repo *git.Repository) {
+	g.repos.Release(repo)
+}
@@ -0,0 +1,114 @@
+package gitops
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+)
+
+func TestRepoCache(t *testing.T) {
+	repoDir, _ := createTestRepo(t)
+	defer os.RemoveAll(repoDir)
+	otherDir, _ := createTestRepo(t)
+	defer os.RemoveAll(otherDir)
+
+	cache := NewRepoCache(1)
+
+	first, err := cache.Open(repoDir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	// A handle in use is never shared
+	second, err := cache.Open(repoDir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if first == second {
+		t.Error("Expected a handle in use not to be handed out again")
+	}
+	cache.Release(first)
+	cache.Release(second)
+
+	reused, err := cache.Open(repoDir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if reused != first {
+		t.Error("Expected the released handle to be reused")
+	}
+	cache.Release(reused)
+
+	// The cache holds one handle, the other repository evicts it
+	other, err := cache.Open(otherDir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	cache.Release(other)
+	if reopened, _ := cache.Open(repoDir); reopened == first {
+		t.Error("Expected the least recently used handle to be evicted")
+	} else {
+		cache.Release(reopened)
+	}
+
+	stats := cache.Stats()
+	if stats.Hits != 1 || stats.Misses != 4 || stats.Idle != 1 {
+		t.Errorf("Expected 1 hit, 4 misses and 1 idle handle, got %+v", stats)
+	}
+
+	if _, err := cache.Open(t.TempDir()); err == nil {
+		t.Error("Expected opening a directory that is not a repository to fail")
+	}
+}
+
+func TestRepoCache_Invalidation(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not available")
+	}
+
+	repoDir, _ := createTestRepo(t)
+	defer os.RemoveAll(repoDir)
+
+	cache := NewRepoCache(DefaultRepoCacheSize)
+	repo, err := cache.Open(repoDir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	cache.Release(repo)
+
+	// Repacking replaces the loose objects with a packfile the handle has
+	// not indexed
+	if output, err := exec.Command("git", "-C", repoDir, "gc", "--quiet").CombinedOutput(); err != nil {
+		t.Fatalf("git gc failed: %v\n%s", err, output)
+	}
+
+	reopened, err := cache.Open(repoDir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer cache.Release(reopened)
+	if reopened == repo {
+		t.Error("Expected a handle of a repacked repository to be reopened")
+	}
+	head, err := reopened.Head()
+	if err != nil {
+		t.Fatalf("Head failed: %v", err)
+	}
+	if _, err := reopened.CommitObject(head.Hash()); err != nil {
+		t.Errorf("Expected the repacked commit to be found: %v", err)
+	}
+}
+
+func TestRepoCache_Disabled(t *testing.T) {
+	repoDir, _ := createTestRepo(t)
+	defer os.RemoveAll(repoDir)
+
+	cache := NewRepoCache(0)
+	repo, err := cache.Open(repoDir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	cache.Release(repo)
+	if again, _ := cache.Open(repoDir); again == repo {
+		t.Error("Expected a disabled cache to open the repository every time")
+	}
+}
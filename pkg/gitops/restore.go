@@ -0,0 +1,231 @@
+package gitops
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Restore discards changes to the given paths, equivalent to git restore.
+//
+// Without a source the working tree files are restored from the index. With a
+// source revision the files are restored from that commit instead. When staged
+// is true the index entries are restored from the source (HEAD by default);
+// worktree controls whether the working tree files are restored.
+func (g *Operations) Restore(repoPath string, files []string, source string, staged, worktree bool) (string, error) {
+	if len(files) == 0 {
+		return "", fmt.Errorf("at least one file must be specified")
+	}
+	if !staged && !worktree {
+		return "", fmt.Errorf("nothing to restore: enable staged and/or worktree")
+	}
+
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	var sourceHash plumbing.Hash
+	if source != "" || staged {
+		rev := source
+		if rev == "" {
+			rev = "HEAD"
+		}
+		hash, err := resolveRevision(repo, rev)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve source '%s': %w", rev, err)
+		}
+		sourceHash = *hash
+	}
+
+	var restored []string
+	if staged {
+		restored, err = resetIndexPaths(repo, sourceHash, files)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	var trashed string
+	if worktree {
+		trashed, err = g.saveToTrash(repo, "restore", pathMatcher(files))
+		if err != nil {
+			return "", err
+		}
+
+		var worktreeRestored []string
+		if source == "" && !staged {
+			worktreeRestored, err = restoreFromIndex(repo, wt.Filesystem.Root(), files)
+		} else {
+			worktreeRestored, err = restoreFromCommit(repo, wt.Filesystem.Root(), sourceHash, files)
+		}
+		if err != nil {
+			return "", err
+		}
+		restored = mergeSorted(restored, worktreeRestored)
+	}
+
+	if len(restored) == 0 {
+		return "Nothing to restore", nil
+	}
+
+	result := fmt.Sprintf("Restored: %s", strings.Join(restored, ", "))
+	if trashed != "" {
+		result += "\n" + trashed
+	}
+	return result, nil
+}
+
+// restoreFromIndex overwrites working tree files with their staged content
+func restoreFromIndex(repo *git.Repository, root string, files []string) ([]string, error) {
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+
+	matches := pathMatcher(files)
+	var restored []string
+	for _, entry := range idx.Entries {
+		if !matches(entry.Name) {
+			continue
+		}
+		if err := writeBlob(repo, filepath.Join(root, filepath.FromSlash(entry.Name)), entry.Hash, entry.Mode); err != nil {
+			return nil, err
+		}
+		restored = append(restored, entry.Name)
+	}
+
+	if len(restored) == 0 {
+		return nil, fmt.Errorf("pathspec '%s' did not match any file(s) known to git", strings.Join(files, " "))
+	}
+
+	sort.Strings(restored)
+	return restored, nil
+}
+
+// restoreFromCommit overwrites working tree files with their content in the
+// given commit. Tracked files below the given paths which do not exist in the
+// commit are removed.
+func restoreFromCommit(repo *git.Repository, root string, commitHash plumbing.Hash, files []string) ([]string, error) {
+	commit, err := repo.CommitObject(commitHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit: %w", err)
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree: %w", err)
+	}
+
+	matches := pathMatcher(files)
+	inTree := make(map[string]bool)
+	var restored []string
+	err = tree.Files().ForEach(func(f *object.File) error {
+		if !matches(f.Name) {
+			return nil
+		}
+		inTree[f.Name] = true
+		restored = append(restored, f.Name)
+		return writeBlob(repo, filepath.Join(root, filepath.FromSlash(f.Name)), f.Hash, f.Mode)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+	for _, entry := range idx.Entries {
+		if matches(entry.Name) && !inTree[entry.Name] {
+			if err := os.Remove(filepath.Join(root, filepath.FromSlash(entry.Name))); err != nil && !os.IsNotExist(err) {
+				return nil, fmt.Errorf("failed to remove %s: %w", entry.Name, err)
+			}
+			restored = append(restored, entry.Name)
+		}
+	}
+
+	if len(restored) == 0 {
+		return nil, fmt.Errorf("pathspec '%s' did not match any file(s) in %s", strings.Join(files, " "), commitHash.String()[:7])
+	}
+
+	sort.Strings(restored)
+	return restored, nil
+}
+
+// writeBlob writes the content of a blob to dest, creating parent
+// directories and honouring executable and symlink modes
+func writeBlob(repo *git.Repository, dest string, hash plumbing.Hash, mode filemode.FileMode) error {
+	blob, err := repo.BlobObject(hash)
+	if err != nil {
+		return fmt.Errorf("failed to read blob %s: %w", hash.String()[:7], err)
+	}
+
+	reader, err := blob.Reader()
+	if err != nil {
+		return fmt.Errorf("failed to read blob %s: %w", hash.String()[:7], err)
+	}
+	defer reader.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", dest, err)
+	}
+
+	// Remove whatever is there first so that symlinks are not followed
+	if err := os.Remove(dest); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to replace %s: %w", dest, err)
+	}
+
+	if mode == filemode.Symlink {
+		target, err := io.ReadAll(reader)
+		if err != nil {
+			return fmt.Errorf("failed to read symlink target: %w", err)
+		}
+		return os.Symlink(string(target), dest)
+	}
+
+	perm := os.FileMode(0644)
+	if mode == filemode.Executable {
+		perm = 0755
+	}
+
+	file, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", dest, err)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, reader); err != nil {
+		return fmt.Errorf("failed to write %s: %w", dest, err)
+	}
+
+	return nil
+}
+
+// mergeSorted merges two sorted string slices, dropping duplicates
+func mergeSorted(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	merged := make([]string, 0, len(a)+len(b))
+	for _, s := range append(append([]string{}, a...), b...) {
+		if !seen[s] {
+			seen[s] = true
+			merged = append(merged, s)
+		}
+	}
+	sort.Strings(merged)
+	return merged
+}
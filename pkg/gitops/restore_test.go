@@ -0,0 +1,51 @@
+package gitops
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOperations_Restore(t *testing.T) {
+	tempDir, _ := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+	testFile := filepath.Join(tempDir, "test.txt")
+
+	// Restore a modified file from the index
+	if err := os.WriteFile(testFile, []byte("modified"), 0644); err != nil {
+		t.Fatalf("Failed to modify file: %v", err)
+	}
+	result, err := ops.Restore(tempDir, []string{"test.txt"}, "", false, true)
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if result != "Restored: test.txt" {
+		t.Errorf("Unexpected result: %s", result)
+	}
+	content, _ := os.ReadFile(testFile)
+	if string(content) != "test content" {
+		t.Errorf("Expected restored content, got: %s", content)
+	}
+
+	// Restore a staged change from HEAD into both index and worktree
+	if err := os.WriteFile(testFile, []byte("staged"), 0644); err != nil {
+		t.Fatalf("Failed to modify file: %v", err)
+	}
+	if _, err := ops.Add(tempDir, []string{"test.txt"}, AddOptions{}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if _, err := ops.Restore(tempDir, []string{"."}, "HEAD", true, true); err != nil {
+		t.Fatalf("Restore from HEAD failed: %v", err)
+	}
+	status, _ := ops.Status(tempDir, false)
+	if status != "working tree clean" {
+		t.Errorf("Expected clean tree after restore, got: %s", status)
+	}
+
+	// Unknown paths are rejected
+	if _, err := ops.Restore(tempDir, []string{"missing.txt"}, "", false, true); err == nil {
+		t.Error("Expected error for unknown path")
+	}
+}
@@ -0,0 +1,148 @@
+package gitops
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// resolveRevision resolves a revision to a commit hash like git rev-parse:
+// full or abbreviated hashes, branch, remote-tracking and tag names (tags
+// are peeled to the commit they point at), HEAD and its @ alias, followed
+// by any number of ancestry suffixes: ~N, ^N, ^{}, ^{commit} and ^{/regex}.
+func resolveRevision(repo *git.Repository, rev string) (*plumbing.Hash, error) {
+	commit, err := resolveCommit(repo, rev)
+	if err != nil {
+		return nil, err
+	}
+	return &commit.Hash, nil
+}
+
+// resolveCommit resolves a revision to a commit, see resolveRevision
+func resolveCommit(repo *git.Repository, rev string) (*object.Commit, error) {
+	rev = strings.TrimSpace(rev)
+	if rev == "" {
+		return nil, fmt.Errorf("empty revision")
+	}
+
+	base, suffix := rev, ""
+	if i := strings.IndexAny(rev, "~^"); i >= 0 {
+		base, suffix = rev[:i], rev[i:]
+	}
+	if base == "@" {
+		base = "HEAD"
+	}
+	if base == "" {
+		return nil, fmt.Errorf("revision '%s' has no base", rev)
+	}
+	if strings.Contains(base, "@{") {
+		return nil, fmt.Errorf("reflog revisions such as '%s' are not supported", rev)
+	}
+
+	hash, err := repo.ResolveRevision(plumbing.Revision(base))
+	if err != nil {
+		return nil, fmt.Errorf("unknown revision '%s'", base)
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit %s: %w", hash.String()[:7], err)
+	}
+
+	for suffix != "" {
+		op := suffix[0]
+		suffix = suffix[1:]
+
+		// ^{...} peels or searches instead of selecting a parent
+		if op == '^' && strings.HasPrefix(suffix, "{") {
+			end := strings.Index(suffix, "}")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated ^{ in revision '%s'", rev)
+			}
+			inner := suffix[1:end]
+			suffix = suffix[end+1:]
+
+			switch {
+			case inner == "" || inner == "commit":
+				// Already peeled to a commit
+			case strings.HasPrefix(inner, "/"):
+				commit, err = findCommitByMessage(commit, inner[1:])
+				if err != nil {
+					return nil, err
+				}
+			default:
+				return nil, fmt.Errorf("revision '%s' does not name a commit", rev)
+			}
+			continue
+		}
+
+		digits := len(suffix) - len(strings.TrimLeft(suffix, "0123456789"))
+		n := 1
+		if digits > 0 {
+			n, err = strconv.Atoi(suffix[:digits])
+			if err != nil {
+				return nil, fmt.Errorf("invalid revision '%s'", rev)
+			}
+			suffix = suffix[digits:]
+		}
+
+		switch op {
+		case '~':
+			for i := 0; i < n; i++ {
+				if commit.NumParents() == 0 {
+					return nil, fmt.Errorf("revision '%s' goes past the root commit %s", rev, commit.Hash.String()[:7])
+				}
+				commit, err = commit.Parent(0)
+				if err != nil {
+					return nil, fmt.Errorf("failed to get parent: %w", err)
+				}
+			}
+		case '^':
+			if n == 0 {
+				continue
+			}
+			if n > commit.NumParents() {
+				return nil, fmt.Errorf("revision '%s': commit %s has %d parent(s)", rev, commit.Hash.String()[:7], commit.NumParents())
+			}
+			commit, err = commit.Parent(n - 1)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get parent: %w", err)
+			}
+		}
+	}
+
+	return commit, nil
+}
+
+// findCommitByMessage returns the youngest commit reachable from start whose
+// message matches a regular expression, a leading ! negating the match
+func findCommitByMessage(start *object.Commit, pattern string) (*object.Commit, error) {
+	negate := strings.HasPrefix(pattern, "!") && !strings.HasPrefix(pattern, "!!")
+	pattern = strings.TrimPrefix(pattern, "!")
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid message pattern: %w", err)
+	}
+
+	var found *object.Commit
+	err = object.NewCommitIterCTime(start, nil, nil).ForEach(func(c *object.Commit) error {
+		if re.MatchString(c.Message) != negate {
+			found = c
+			return storer.ErrStop
+		}
+		return nil
+	})
+	if err != nil && err != storer.ErrStop {
+		return nil, fmt.Errorf("failed to walk history: %w", err)
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no commit message matches '%s'", pattern)
+	}
+	return found, nil
+}
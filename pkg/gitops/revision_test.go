@@ -0,0 +1,113 @@
+package gitops
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestResolveRevision(t *testing.T) {
+	tempDir, repo := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("Failed to get HEAD: %v", err)
+	}
+	root := head.Hash()
+
+	when := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	a := commitAt(t, repo, "a.txt", "Add a", when.Add(time.Hour))
+	b := commitAt(t, repo, "b.txt", "Add b", when.Add(2*time.Hour))
+	side := commitAt(t, repo, "side.txt", "Side work", when.Add(3*time.Hour), a)
+	merge := commitAt(t, repo, "merge.txt", "Merge side", when.Add(4*time.Hour), b, side, root)
+
+	signature := &object.Signature{Name: "Test User", Email: "test@example.com", When: when}
+	if _, err := repo.CreateTag("v1.0", b, &git.CreateTagOptions{Tagger: signature, Message: "Release 1.0"}); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+
+	tests := []struct {
+		revision string
+		expected plumbing.Hash
+	}{
+		{"HEAD", merge},
+		{"@", merge},
+		{" master ", merge},
+		{merge.String()[:7], merge},
+		{"v1.0", b},
+		{"v1.0^{}", b},
+		{"v1.0^{commit}", b},
+		{"HEAD~1", b},
+		{"HEAD~", b},
+		{"HEAD^", b},
+		{"@~2", a},
+		{"HEAD^2", side},
+		{"HEAD^3", root},
+		{"HEAD^2~1", a},
+		{"HEAD^0", merge},
+		{"HEAD~3", root},
+		{"HEAD^{/^Side}", side},
+		{"master^{/Add a}", a},
+	}
+
+	for _, tt := range tests {
+		hash, err := resolveRevision(repo, tt.revision)
+		if err != nil {
+			t.Errorf("resolveRevision(%q) failed: %v", tt.revision, err)
+			continue
+		}
+		if *hash != tt.expected {
+			t.Errorf("resolveRevision(%q) = %s, want %s", tt.revision, hash, tt.expected)
+		}
+	}
+
+	for revision, expected := range map[string]string{
+		"":             "empty revision",
+		"missing":      "unknown revision 'missing'",
+		"HEAD~10":      "goes past the root commit",
+		"HEAD^4":       "has 3 parent(s)",
+		"HEAD^{tree}":  "does not name a commit",
+		"HEAD^{/nope}": "no commit message matches",
+		"HEAD@{1}":     "reflog revisions",
+		"HEAD^{commit": "unterminated",
+	} {
+		_, err := resolveRevision(repo, revision)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("resolveRevision(%q) error = %v, want %q", revision, err, expected)
+		}
+	}
+}
+
+func TestOperations_ShowSymbolicRevision(t *testing.T) {
+	tempDir, repo := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+	commitAt(t, repo, "a.txt", "Add a", time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC))
+
+	result, err := ops.Show(tempDir, ShowOptions{Revision: "HEAD"})
+	if err != nil {
+		t.Fatalf("Show failed: %v", err)
+	}
+	if !strings.Contains(result, "Message: Add a") {
+		t.Errorf("Expected HEAD commit in result, got: %s", result)
+	}
+
+	result, err = ops.Diff(tempDir, "HEAD~1", DiffOptions{Format: DiffFormatNameStatus})
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if result != "A\ta.txt" {
+		t.Errorf("Expected a.txt added since HEAD~1, got: %s", result)
+	}
+
+	if _, err := ops.Show(tempDir, ShowOptions{Revision: "HEAD~5"}); err == nil {
+		t.Error("Expected error for revision past the root commit")
+	}
+}
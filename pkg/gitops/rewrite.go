@@ -0,0 +1,228 @@
+package gitops
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// RewriteStep is one line of a history rewriting plan
+type RewriteStep struct {
+	// Commit is the commit the step applies to
+	Commit string `json:"commit" description:"Commit hash or revision" required:"true"`
+	// Action is pick, reword, squash, fixup or drop, like in the todo list
+	// of git rebase -i
+	Action string `json:"action" description:"pick keeps the commit, reword changes its message, squash and fixup combine it with the commit before with or without its message, drop removes it" enum:"pick,reword,squash,fixup,drop" required:"true"`
+	// Message replaces the message of the commit with reword, or of the
+	// combined commit with squash, which otherwise joins the messages
+	Message string `json:"message,omitempty" description:"New message of a reword, or of the combined commit of a squash (default: both messages)"`
+}
+
+// rewriteActions are the actions of a rewriting plan
+var rewriteActions = map[string]bool{"pick": true, "reword": true, "squash": true, "fixup": true, "drop": true}
+
+// RewriteHistory rewrites the last commits of a branch like git rebase -i,
+// executing a plan listing each of them once, oldest first, with the action
+// to apply to it. Steps may be reordered. A plan that does not apply
+// cleanly is aborted, leaving the branch as it was.
+func (g *Operations) RewriteHistory(repoPath, branch string, steps []RewriteStep) (string, error) {
+	if len(steps) == 0 {
+		return "", fmt.Errorf("plan cannot be empty")
+	}
+
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+	if operation := operationInProgress(repo); operation != "" {
+		return "", fmt.Errorf("cannot rewrite history: %s", operation)
+	}
+
+	branchRef, err := rewriteBranch(repo, branch)
+	if err != nil {
+		return "", err
+	}
+	tip, err := repo.CommitObject(branchRef.Hash())
+	if err != nil {
+		return "", fmt.Errorf("failed to get commit: %w", err)
+	}
+
+	// The plan covers the last len(steps) commits of the branch
+	commits := make(map[plumbing.Hash]*object.Commit)
+	commit := tip
+	var base *object.Commit
+	for i := 0; i < len(steps); i++ {
+		if commit.NumParents() > 1 {
+			return "", fmt.Errorf("cannot rewrite merge commit %s %s", commit.Hash.String()[:7], commitSubject(commit))
+		}
+		commits[commit.Hash] = commit
+		if commit.NumParents() == 0 {
+			if i != len(steps)-1 {
+				return "", fmt.Errorf("%s has only %d commit(s)", branchRef.Name().Short(), i+1)
+			}
+			break
+		}
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return "", fmt.Errorf("failed to get parent of %s: %w", commit.Hash.String()[:7], err)
+		}
+		if i == len(steps)-1 {
+			base = parent
+		}
+		commit = parent
+	}
+
+	todo, messages, err := rewriteTodo(repo, steps, commits)
+	if err != nil {
+		return "", err
+	}
+
+	tempDir, err := os.MkdirTemp("", "mcp-git-rewrite-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+	for i, message := range messages {
+		name := filepath.Join(tempDir, fmt.Sprintf("message-%d", i))
+		if err := os.WriteFile(name, []byte(message), 0600); err != nil {
+			return "", fmt.Errorf("failed to write message: %w", err)
+		}
+		todo = strings.ReplaceAll(todo, fmt.Sprintf("{message-%d}", i), shellQuote(name))
+	}
+	todoFile := filepath.Join(tempDir, "todo")
+	if err := os.WriteFile(todoFile, []byte(todo), 0600); err != nil {
+		return "", fmt.Errorf("failed to write plan: %w", err)
+	}
+
+	args := []string{"rebase", "--interactive", "--no-autosquash", "--empty=drop"}
+	if base == nil {
+		args = append(args, "--root")
+	} else {
+		args = append(args, base.Hash.String())
+	}
+	args = append(args, branchRef.Name().Short())
+
+	// The plan replaces the todo list, and the joined messages of squashes
+	// are kept as they are
+	signature := g.getUserSignature()
+	cmd := g.gitCommand(repoPath, args...)
+	cmd.Env = append(cmd.Env,
+		"GIT_SEQUENCE_EDITOR=cp "+shellQuote(todoFile),
+		"GIT_EDITOR=true",
+		"GIT_COMMITTER_NAME="+signature.Name,
+		"GIT_COMMITTER_EMAIL="+signature.Email)
+	if output, err := g.runCommand(cmd); err != nil {
+		if _, ok := err.(*DubiousOwnershipError); ok {
+			return "", err
+		}
+		// Leave the branch as it was rather than in the middle of a
+		// rebase, also when the rebase was stopped by the context
+		if operationInProgress(repo) != "" {
+			g.runCommand(g.WithContext(context.Background()).gitCommand(repoPath, "rebase", "--abort"))
+		}
+		return "", fmt.Errorf("failed to rewrite history, %s is unchanged: %w\n%s", branchRef.Name().Short(), err, strings.TrimSpace(string(output)))
+	}
+
+	newTip, err := repo.Reference(branchRef.Name(), true)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", branchRef.Name().Short(), err)
+	}
+
+	var result strings.Builder
+	if base == nil {
+		fmt.Fprintf(&result, "Rewrote %d commit(s) of %s from the root", len(steps), branchRef.Name().Short())
+	} else {
+		fmt.Fprintf(&result, "Rewrote %d commit(s) of %s on %s", len(steps), branchRef.Name().Short(), base.Hash.String()[:7])
+	}
+	var rewritten []string
+	commit, err = repo.CommitObject(newTip.Hash())
+	for err == nil && (base == nil || commit.Hash != base.Hash) {
+		rewritten = append([]string{fmt.Sprintf("  %s %s", commit.Hash.String()[:7], commitSubject(commit))}, rewritten...)
+		if commit.NumParents() == 0 {
+			break
+		}
+		commit, err = commit.Parent(0)
+	}
+	fmt.Fprintf(&result, ", now %d commit(s):\n%s", len(rewritten), strings.Join(rewritten, "\n"))
+	fmt.Fprintf(&result, "\nThe previous tip was %s, restore it with git_reset to %s if needed", tip.Hash.String()[:7], tip.Hash.String())
+	return result.String(), nil
+}
+
+// rewriteBranch returns the branch to rewrite, the current one by default
+func rewriteBranch(repo *git.Repository, branch string) (*plumbing.Reference, error) {
+	if branch == "" {
+		head, err := repo.Head()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get HEAD: %w", err)
+		}
+		if !head.Name().IsBranch() {
+			return nil, fmt.Errorf("HEAD is detached, check out the branch to rewrite or name it")
+		}
+		return head, nil
+	}
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(branch), true)
+	if err != nil {
+		return nil, fmt.Errorf("branch '%s' not found: %w", branch, err)
+	}
+	return ref, nil
+}
+
+// rewriteTodo validates a plan and renders it as the todo list of git
+// rebase -i. Replacement messages are returned apart, their files being
+// referenced as {message-N} in the todo list.
+func rewriteTodo(repo *git.Repository, steps []RewriteStep, commits map[plumbing.Hash]*object.Commit) (string, []string, error) {
+	var todo strings.Builder
+	var messages []string
+	seen := make(map[plumbing.Hash]bool)
+	picked := false
+	for i, step := range steps {
+		if !rewriteActions[step.Action] {
+			return "", nil, fmt.Errorf("invalid action %q in step %d, expected pick, reword, squash, fixup or drop", step.Action, i+1)
+		}
+		commit, err := resolveCommit(repo, step.Commit)
+		if err != nil {
+			return "", nil, fmt.Errorf("step %d: %w", i+1, err)
+		}
+		if commits[commit.Hash] == nil {
+			return "", nil, fmt.Errorf("step %d: %s is not one of the last %d commit(s) of the branch", i+1, step.Commit, len(steps))
+		}
+		if seen[commit.Hash] {
+			return "", nil, fmt.Errorf("step %d: %s is listed twice", i+1, step.Commit)
+		}
+		seen[commit.Hash] = true
+
+		message := strings.TrimSpace(step.Message)
+		switch step.Action {
+		case "reword":
+			if message == "" {
+				return "", nil, fmt.Errorf("step %d: reword needs a message", i+1)
+			}
+		case "squash", "fixup":
+			if !picked {
+				return "", nil, fmt.Errorf("step %d: %s needs an earlier commit to be combined with", i+1, step.Action)
+			}
+		}
+		if message != "" && step.Action != "reword" && step.Action != "squash" {
+			return "", nil, fmt.Errorf("step %d: only reword and squash take a message", i+1)
+		}
+
+		action := step.Action
+		if action == "reword" {
+			action = "pick"
+		}
+		fmt.Fprintf(&todo, "%s %s %s\n", action, commit.Hash, commitSubject(commit))
+		if message != "" {
+			fmt.Fprintf(&todo, "exec git commit --amend --allow-empty --quiet --file={message-%d}\n", len(messages))
+			messages = append(messages, message+"\n")
+		}
+		picked = picked || step.Action != "drop"
+	}
+	return todo.String(), messages, nil
+}
@@ -0,0 +1,102 @@
+package gitops
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOperations_RewriteHistory(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not available")
+	}
+	tempDir, repo := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+	commitFile(t, ops, tempDir, "a.txt", "a\n", "Add a")
+	commitFile(t, ops, tempDir, "a.txt", "a fixed\n", "Fix typo in a")
+	commitFile(t, ops, tempDir, "debug.txt", "debug\n", "WIP debugging")
+	commitFile(t, ops, tempDir, "b.txt", "b\n", "add b")
+	head, _ := repo.Head()
+
+	hash := func(revision string) string {
+		h, err := resolveRevision(repo, revision)
+		if err != nil {
+			t.Fatalf("Failed to resolve %s: %v", revision, err)
+		}
+		return h.String()
+	}
+	plan := []RewriteStep{
+		{Commit: hash("HEAD"), Action: "reword", Message: "Add b"},
+		{Commit: hash("HEAD~3"), Action: "pick"},
+		{Commit: hash("HEAD~2"), Action: "fixup"},
+		{Commit: hash("HEAD~1"), Action: "drop"},
+	}
+	result, err := ops.RewriteHistory(tempDir, "", plan)
+	if err != nil {
+		t.Fatalf("RewriteHistory failed: %v", err)
+	}
+	lines := strings.Split(result, "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], "Rewrote 4 commit(s) of master on ") || !strings.HasSuffix(lines[0], ", now 2 commit(s):") ||
+		!strings.HasSuffix(lines[1], " Add b") || !strings.HasSuffix(lines[2], " Add a") ||
+		lines[3] != "The previous tip was "+head.Hash().String()[:7]+", restore it with git_reset to "+head.Hash().String()+" if needed" {
+		t.Errorf("Unexpected result:\n%s", result)
+	}
+	if content, _ := os.ReadFile(filepath.Join(tempDir, "a.txt")); string(content) != "a fixed\n" {
+		t.Errorf("Expected the fixup in a.txt, got %q", content)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "debug.txt")); !os.IsNotExist(err) {
+		t.Error("Expected the dropped commit's file to be gone")
+	}
+	commit, _ := resolveCommit(repo, "HEAD~1")
+	if commit.Message != "Add b\n" || commit.Author.Name != "Test User" {
+		t.Errorf("Unexpected commit: %q by %s", commit.Message, commit.Author.Name)
+	}
+
+	// Squashing keeps both messages unless given one
+	result, err = ops.RewriteHistory(tempDir, "master", []RewriteStep{
+		{Commit: hash("HEAD~1"), Action: "pick"},
+		{Commit: hash("HEAD"), Action: "squash"},
+	})
+	if err != nil {
+		t.Fatalf("Squash failed: %v", err)
+	}
+	commit, _ = resolveCommit(repo, "HEAD")
+	if !strings.Contains(commit.Message, "Add a") || !strings.Contains(commit.Message, "Add b") {
+		t.Errorf("Expected the joined messages, got %q", commit.Message)
+	}
+
+	// A plan that does not apply is aborted
+	commitFile(t, ops, tempDir, "c.txt", "c\n", "Add c")
+	commitFile(t, ops, tempDir, "c.txt", "c changed\n", "Change c")
+	before, _ := repo.Head()
+	_, err = ops.RewriteHistory(tempDir, "", []RewriteStep{
+		{Commit: hash("HEAD"), Action: "pick"},
+		{Commit: hash("HEAD~1"), Action: "pick"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "master is unchanged") {
+		t.Errorf("Expected the conflicting plan to fail, got %v", err)
+	}
+	if after, _ := repo.Head(); after.Hash() != before.Hash() || after.Name() != before.Name() {
+		t.Error("Expected the branch to be left as it was")
+	}
+	if operationInProgress(repo) != "" {
+		t.Error("Expected the rebase to be aborted")
+	}
+
+	invalid := [][]RewriteStep{
+		{{Commit: hash("HEAD"), Action: "squash"}, {Commit: hash("HEAD~1"), Action: "pick"}},
+		{{Commit: hash("HEAD"), Action: "pick"}, {Commit: hash("HEAD"), Action: "pick"}},
+		{{Commit: hash("HEAD~2"), Action: "pick"}},
+		{{Commit: hash("HEAD"), Action: "reword"}},
+		{{Commit: hash("HEAD"), Action: "edit"}},
+	}
+	for _, plan := range invalid {
+		if _, err := ops.RewriteHistory(tempDir, "", plan); err == nil {
+			t.Errorf("Expected error for plan %v", plan)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package gitops
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Scratch manages a directory of disposable working copies (materialized
+// revisions, temporary clones, ...) kept within a disk budget. When the budget
+// is exceeded the least recently used entries are evicted.
+type Scratch struct {
+	dir      string
+	maxBytes int64
+	mu       sync.Mutex
+}
+
+// ScratchEntry describes a single entry in the scratch directory
+type ScratchEntry struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+}
+
+// NewScratch creates a scratch manager rooted at dir. A maxBytes of zero or
+// less disables the budget.
+func NewScratch(dir string, maxBytes int64) *Scratch {
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "go-mcp-git")
+	}
+	return &Scratch{
+		dir:      dir,
+		maxBytes: maxBytes,
+	}
+}
+
+// Dir returns the scratch root directory
+func (s *Scratch) Dir() string {
+	return s.dir
+}
+
+// MaxBytes returns the configured disk budget (0 means unlimited)
+func (s *Scratch) MaxBytes() int64 {
+	return s.maxBytes
+}
+
+// Create evicts old entries if needed and creates a new empty directory
+// inside the scratch root whose name starts with prefix.
+func (s *Scratch) Create(prefix string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create scratch directory: %w", err)
+	}
+
+	if _, err := s.evictLocked(); err != nil {
+		return "", err
+	}
+
+	path, err := os.MkdirTemp(s.dir, prefix+"-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create scratch entry: %w", err)
+	}
+
+	return path, nil
+}
+
+// Touch marks an entry as recently used so it is evicted last
+func (s *Scratch) Touch(path string) {
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+}
+
+// Contains reports whether path is an entry managed by this scratch root
+func (s *Scratch) Contains(path string) bool {
+	rel, err := filepath.Rel(s.dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != "." && filepath.Dir(rel) == "." && rel != ".."
+}
+
+// Remove deletes a scratch entry
+func (s *Scratch) Remove(path string) error {
+	if !s.Contains(path) {
+		return fmt.Errorf("%s is not a scratch entry", path)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.RemoveAll(path); err != nil {
+		return fmt.Errorf("failed to remove scratch entry: %w", err)
+	}
+	return nil
+}
+
+// Entries lists the scratch entries, most recently used first
+func (s *Scratch) Entries() ([]ScratchEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.entriesLocked()
+}
+
+// Evict removes least recently used entries until the scratch directory fits
+// within its budget. It returns the removed paths.
+func (s *Scratch) Evict() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.evictLocked()
+}
+
+func (s *Scratch) entriesLocked() ([]ScratchEntry, error) {
+	dirEntries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read scratch directory: %w", err)
+	}
+
+	entries := make([]ScratchEntry, 0, len(dirEntries))
+	for _, dirEntry := range dirEntries {
+		info, err := dirEntry.Info()
+		if err != nil {
+			continue
+		}
+
+		path := filepath.Join(s.dir, dirEntry.Name())
+		size, _, err := dirSize(path, "")
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, ScratchEntry{
+			Path:    path,
+			Size:    size,
+			ModTime: info.ModTime(),
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ModTime.After(entries[j].ModTime)
+	})
+
+	return entries, nil
+}
+
+func (s *Scratch) evictLocked() ([]string, error) {
+	if s.maxBytes <= 0 {
+		return nil, nil
+	}
+
+	entries, err := s.entriesLocked()
+	if err != nil {
+		return nil, err
+	}
+
+	var total int64
+	for _, entry := range entries {
+		total += entry.Size
+	}
+
+	var evicted []string
+	for i := len(entries) - 1; i >= 0 && total > s.maxBytes; i-- {
+		if err := os.RemoveAll(entries[i].Path); err != nil {
+			return evicted, fmt.Errorf("failed to evict %s: %w", entries[i].Path, err)
+		}
+		total -= entries[i].Size
+		evicted = append(evicted, entries[i].Path)
+	}
+
+	return evicted, nil
+}
+
+// dirSize returns the total size and number of regular files below root,
+// skipping any directory named skip.
+func dirSize(root, skip string) (int64, int, error) {
+	var size int64
+	var files int
+
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil // Continue walking even if there's an error
+		}
+		if info.IsDir() {
+			if skip != "" && info.Name() == skip && path != root {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+			files++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to walk %s: %w", root, err)
+	}
+
+	return size, files, nil
+}
@@ -0,0 +1,55 @@
+package gitops
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestScratch_Evict(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "scratch-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	scratch := NewScratch(tempDir, 150)
+
+	// Create two entries of 100 bytes each, the first one being older
+	old, err := scratch.Create("old")
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(old, "data"), make([]byte, 100), 0644); err != nil {
+		t.Fatalf("Failed to write data: %v", err)
+	}
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(old, past, past); err != nil {
+		t.Fatalf("Failed to set times: %v", err)
+	}
+
+	recent, err := scratch.Create("recent")
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(recent, "data"), make([]byte, 100), 0644); err != nil {
+		t.Fatalf("Failed to write data: %v", err)
+	}
+	scratch.Touch(recent)
+
+	evicted, err := scratch.Evict()
+	if err != nil {
+		t.Fatalf("Evict failed: %v", err)
+	}
+
+	if len(evicted) != 1 || evicted[0] != old {
+		t.Errorf("Expected only %s to be evicted, got: %v", old, evicted)
+	}
+	if _, err := os.Stat(recent); err != nil {
+		t.Errorf("Expected recent entry to survive eviction: %v", err)
+	}
+	if !scratch.Contains(recent) || scratch.Contains(tempDir) {
+		t.Error("Contains reported wrong scratch membership")
+	}
+}
@@ -0,0 +1,168 @@
+package gitops
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/go-git/go-git/v5"
+	"golang.org/x/crypto/ssh"
+)
+
+// LoadSigningKey reads the OpenPGP key signing commits and tags from an
+// armored or binary private key file, which may be an exported keyring
+// holding several keys. keyID selects a key by its key ID or fingerprint
+// (or a suffix of them, in hex); the first private key is used when it is
+// empty. passphrase decrypts protected keys.
+func LoadSigningKey(path, keyID string, passphrase []byte) (*openpgp.Entity, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+
+	keyring, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+	if err != nil {
+		keyring, err = openpgp.ReadKeyRing(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key %s: %w", path, err)
+	}
+
+	keyID = strings.ToUpper(strings.TrimPrefix(strings.ReplaceAll(keyID, " ", ""), "0x"))
+	var entity *openpgp.Entity
+	for _, candidate := range keyring {
+		if candidate.PrivateKey == nil {
+			continue
+		}
+		fingerprint := strings.ToUpper(fmt.Sprintf("%X", candidate.PrimaryKey.Fingerprint))
+		if keyID == "" || strings.HasSuffix(fingerprint, keyID) {
+			entity = candidate
+			break
+		}
+	}
+	if entity == nil {
+		if keyID != "" {
+			return nil, fmt.Errorf("no private key %s in %s", keyID, path)
+		}
+		return nil, fmt.Errorf("no private key in %s", path)
+	}
+
+	if entity.PrivateKey.Encrypted && len(passphrase) == 0 {
+		return nil, fmt.Errorf("signing key %s is protected by a passphrase, none was given", entity.PrimaryKey.KeyIdString())
+	}
+	if err := entity.DecryptPrivateKeys(passphrase); err != nil {
+		return nil, fmt.Errorf("failed to decrypt signing key %s: %w", entity.PrimaryKey.KeyIdString(), err)
+	}
+	return entity, nil
+}
+
+// SetSigningKey sets the OpenPGP key used by commits and tags requesting a
+// signature (nil disables signing)
+func (g *Operations) SetSigningKey(key *openpgp.Entity) {
+	g.signingKey = key
+}
+
+// SetSSHSigningKey sets the SSH key used by commits and tags requesting a
+// signature in repositories without an OpenPGP key, or with gpg.format=ssh
+// and no user.signingkey of their own
+func (g *Operations) SetSSHSigningKey(key ssh.Signer) {
+	g.sshSigningKey = key
+}
+
+// SigningKeyID returns the key ID of the OpenPGP signing key, or else the
+// fingerprint of the SSH one, empty without either
+func (g *Operations) SigningKeyID() string {
+	switch {
+	case g.signingKey != nil:
+		return g.signingKey.PrimaryKey.KeyIdString()
+	case g.sshSigningKey != nil:
+		return ssh.FingerprintSHA256(g.sshSigningKey.PublicKey())
+	}
+	return ""
+}
+
+// commitSigner is the OpenPGP or SSH key signing a commit or tag
+type commitSigner struct {
+	openpgp *openpgp.Entity
+	ssh     ssh.Signer
+}
+
+// openPGPKey returns the OpenPGP key for go-git to sign with, nil when not
+// signing or signing with SSH
+func (s *commitSigner) openPGPKey() *openpgp.Entity {
+	if s == nil {
+		return nil
+	}
+	return s.openpgp
+}
+
+// sshKey returns the SSH key to sign with, nil when not signing or signing
+// with OpenPGP
+func (s *commitSigner) sshKey() ssh.Signer {
+	if s == nil {
+		return nil
+	}
+	return s.ssh
+}
+
+// signerFor returns the key to sign with in a repository when sign is set,
+// failing when none is configured. As in git, gpg.format=ssh in the
+// repository config selects SSH signing, with its user.signingkey or else
+// the server SSH key. Otherwise the server OpenPGP key signs, or the SSH
+// one when the server has no OpenPGP key.
+func (g *Operations) signerFor(repo *git.Repository, sign bool) (*commitSigner, error) {
+	if !sign {
+		return nil, nil
+	}
+
+	cfg, err := repo.Config()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read repository config: %w", err)
+	}
+
+	switch format := strings.ToLower(cfg.Raw.Section("gpg").Option("format")); format {
+	case "ssh":
+		if key := cfg.Raw.Section("user").Option("signingkey"); key != "" {
+			signer, err := LoadSSHKey(repoKeyPath(repo, key), nil)
+			if err != nil {
+				return nil, fmt.Errorf("user.signingkey: %w", err)
+			}
+			return &commitSigner{ssh: signer}, nil
+		}
+		if g.sshSigningKey == nil {
+			return nil, fmt.Errorf("gpg.format is ssh but neither user.signingkey nor a server SSH signing key is configured (see --signing-format)")
+		}
+		return &commitSigner{ssh: g.sshSigningKey}, nil
+	case "", "openpgp":
+		switch {
+		case g.signingKey != nil:
+			return &commitSigner{openpgp: g.signingKey}, nil
+		case g.sshSigningKey != nil:
+			return &commitSigner{ssh: g.sshSigningKey}, nil
+		}
+		return nil, fmt.Errorf("signing requested but no signing key is configured (see --signing-key)")
+	default:
+		return nil, fmt.Errorf("unsupported gpg.format %s (expected openpgp or ssh)", format)
+	}
+}
+
+// repoKeyPath resolves a user.signingkey path like git run at the top of
+// the working tree, expanding a leading ~. Key literals are kept as is.
+func repoKeyPath(repo *git.Repository, key string) string {
+	switch {
+	case strings.HasPrefix(key, "key::"):
+		return key
+	case strings.HasPrefix(key, "~/"):
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, key[2:])
+		}
+	case !filepath.IsAbs(key):
+		if worktree, err := repo.Worktree(); err == nil {
+			return filepath.Join(worktree.Filesystem.Root(), key)
+		}
+	}
+	return key
+}
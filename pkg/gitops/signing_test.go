@@ -0,0 +1,108 @@
+package gitops
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// writeSigningKey generates a passphrase protected key and writes it to an
+// armored private key file, returning the file and the armored public key
+func writeSigningKey(t *testing.T, dir string, passphrase []byte) (string, string) {
+	entity, err := openpgp.NewEntity("Test User", "", "test@example.com", nil)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	var public bytes.Buffer
+	w, _ := armor.Encode(&public, openpgp.PublicKeyType, nil)
+	if err := entity.Serialize(w); err != nil {
+		t.Fatalf("Failed to serialize public key: %v", err)
+	}
+	w.Close()
+
+	if err := entity.EncryptPrivateKeys(passphrase, nil); err != nil {
+		t.Fatalf("Failed to encrypt key: %v", err)
+	}
+	var private bytes.Buffer
+	w, _ = armor.Encode(&private, openpgp.PrivateKeyType, nil)
+	if err := entity.SerializePrivateWithoutSigning(w, nil); err != nil {
+		t.Fatalf("Failed to serialize private key: %v", err)
+	}
+	w.Close()
+
+	path := filepath.Join(dir, "key.asc")
+	if err := os.WriteFile(path, private.Bytes(), 0600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+	return path, public.String()
+}
+
+func TestOperations_SignedCommitAndTag(t *testing.T) {
+	tempDir, repo := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+	keyDir, err := os.MkdirTemp("", "key-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(keyDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	if _, err := ops.Commit(tempDir, "Unsigned", CommitOptions{AllowEmpty: true, Sign: true}); err == nil {
+		t.Error("Expected error when signing without a key")
+	}
+
+	keyFile, publicKey := writeSigningKey(t, keyDir, []byte("secret"))
+	if _, err := LoadSigningKey(keyFile, "", nil); err == nil {
+		t.Error("Expected error for a protected key without passphrase")
+	}
+	if _, err := LoadSigningKey(keyFile, "", []byte("wrong")); err == nil {
+		t.Error("Expected error for a wrong passphrase")
+	}
+	if _, err := LoadSigningKey(keyFile, "0123456789ABCDEF", []byte("secret")); err == nil {
+		t.Error("Expected error for an unknown key ID")
+	}
+	key, err := LoadSigningKey(keyFile, "", []byte("secret"))
+	if err != nil {
+		t.Fatalf("LoadSigningKey failed: %v", err)
+	}
+	if _, err := LoadSigningKey(keyFile, strings.ToLower(key.PrimaryKey.KeyIdString()), []byte("secret")); err != nil {
+		t.Errorf("Expected the key to be found by its ID: %v", err)
+	}
+	ops.SetSigningKey(key)
+
+	if _, err := ops.Commit(tempDir, "Signed", CommitOptions{AllowEmpty: true, Sign: true}); err != nil {
+		t.Fatalf("Signed commit failed: %v", err)
+	}
+	head, _ := repo.Head()
+	commit, _ := repo.CommitObject(head.Hash())
+	if commit.PGPSignature == "" {
+		t.Fatal("Expected a signature on the commit")
+	}
+	if _, err := commit.Verify(publicKey); err != nil {
+		t.Errorf("Commit signature does not verify: %v", err)
+	}
+
+	result, err := ops.CreateTag(tempDir, "v1.0", "Release 1.0", false, true)
+	if err != nil {
+		t.Fatalf("Signed tag failed: %v", err)
+	}
+	if !strings.HasPrefix(result, "Created signed tag 'v1.0'") {
+		t.Errorf("Unexpected result: %s", result)
+	}
+	ref, _ := repo.Reference(plumbing.NewTagReferenceName("v1.0"), true)
+	tag, err := repo.TagObject(ref.Hash())
+	if err != nil {
+		t.Fatalf("Expected an annotated tag: %v", err)
+	}
+	if _, err := tag.Verify(publicKey); err != nil {
+		t.Errorf("Tag signature does not verify: %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package gitops
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// Sort keys of branch and tag listings. A leading "-" reverses the order.
+const (
+	// SortName orders by reference name
+	SortName = "name"
+	// SortDate orders by committer date of the commit the reference points at
+	SortDate = "date"
+	// SortVersion orders names containing version numbers numerically, like
+	// git's v:refname (v1.10 after v1.9)
+	SortVersion = "version"
+)
+
+// SortKeys lists the accepted sort keys of branch and tag listings
+var SortKeys = []string{SortName, "-" + SortName, SortDate, "-" + SortDate, SortVersion, "-" + SortVersion}
+
+// sortRefs orders references by key (SortName when empty). Ties keep the
+// name order so that listings are deterministic.
+func sortRefs(repo *git.Repository, refs []*plumbing.Reference, key string) error {
+	reverse := strings.HasPrefix(key, "-")
+	key = strings.TrimPrefix(key, "-")
+
+	name := func(ref *plumbing.Reference) string { return ref.Name().Short() }
+	sort.SliceStable(refs, func(i, j int) bool { return name(refs[i]) < name(refs[j]) })
+
+	var less func(i, j int) bool
+	switch key {
+	case "", SortName:
+		less = func(i, j int) bool { return name(refs[i]) < name(refs[j]) }
+	case SortVersion:
+		less = func(i, j int) bool { return compareVersions(name(refs[i]), name(refs[j])) < 0 }
+	case SortDate:
+		dates := make(map[plumbing.ReferenceName]time.Time, len(refs))
+		for _, ref := range refs {
+			dates[ref.Name()] = refDate(repo, ref)
+		}
+		less = func(i, j int) bool { return dates[refs[i].Name()].Before(dates[refs[j].Name()]) }
+	default:
+		return fmt.Errorf("unknown sort key '%s' (expected one of %s)", key, strings.Join(SortKeys, ", "))
+	}
+
+	if reverse {
+		forward := less
+		less = func(i, j int) bool { return forward(j, i) }
+	}
+	sort.SliceStable(refs, less)
+	return nil
+}
+
+// refDate returns the committer date of the commit a reference points at,
+// peeling annotated tags. It is the zero time for references to other
+// objects.
+func refDate(repo *git.Repository, ref *plumbing.Reference) time.Time {
+	hash := ref.Hash()
+	if tag, err := repo.TagObject(hash); err == nil {
+		if commit, err := tag.Commit(); err == nil {
+			return commit.Committer.When
+		}
+		return tag.Tagger.When
+	}
+	if commit, err := repo.CommitObject(hash); err == nil {
+		return commit.Committer.When
+	}
+	return time.Time{}
+}
+
+// compareVersions compares two names piecewise, runs of digits comparing
+// numerically and everything else lexically
+func compareVersions(a, b string) int {
+	for a != "" && b != "" {
+		pa, ra := leadingPiece(a)
+		pb, rb := leadingPiece(b)
+
+		na, errA := strconv.ParseUint(pa, 10, 64)
+		nb, errB := strconv.ParseUint(pb, 10, 64)
+		switch {
+		case errA == nil && errB == nil && na != nb:
+			if na < nb {
+				return -1
+			}
+			return 1
+		case errA != nil || errB != nil:
+			if c := strings.Compare(pa, pb); c != 0 {
+				return c
+			}
+		}
+		a, b = ra, rb
+	}
+	return strings.Compare(a, b)
+}
+
+// leadingPiece splits a name after its leading run of digits or non-digits
+func leadingPiece(s string) (string, string) {
+	digit := func(c byte) bool { return c >= '0' && c <= '9' }
+	first := digit(s[0])
+	i := 1
+	for i < len(s) && digit(s[i]) == first {
+		i++
+	}
+	return s[:i], s[i:]
+}
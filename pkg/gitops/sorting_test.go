@@ -0,0 +1,94 @@
+package gitops
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func TestOperations_StatusIsSorted(t *testing.T) {
+	tempDir, _ := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	for _, name := range []string{"zeta.txt", "alpha.txt", "mid.txt", "beta.txt"} {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(name), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	first, err := ops.Status(tempDir, false)
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if !strings.HasPrefix(first, "Untracked (4):\n  alpha.txt\n  beta.txt\n  mid.txt\n  zeta.txt") {
+		t.Errorf("Expected files in name order, got: %s", first)
+	}
+	for i := 0; i < 5; i++ {
+		if again, _ := ops.Status(tempDir, false); again != first {
+			t.Fatalf("Status output changed between calls: %q vs %q", first, again)
+		}
+	}
+}
+
+func TestOperations_ListTagsSorted(t *testing.T) {
+	tempDir, repo := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	when := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i, tag := range []string{"v1.10.0", "v1.9.0", "v1.2.0"} {
+		hash := commitAt(t, repo, "file.txt", tag, when.Add(time.Duration(i)*time.Hour))
+		if err := repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewTagReferenceName(tag), hash)); err != nil {
+			t.Fatalf("Failed to create tag: %v", err)
+		}
+	}
+
+	tests := map[string][]string{
+		"":         {"v1.10.0", "v1.2.0", "v1.9.0"},
+		"-name":    {"v1.9.0", "v1.2.0", "v1.10.0"},
+		"version":  {"v1.2.0", "v1.9.0", "v1.10.0"},
+		"-version": {"v1.10.0", "v1.9.0", "v1.2.0"},
+		"date":     {"v1.10.0", "v1.9.0", "v1.2.0"},
+		"-date":    {"v1.2.0", "v1.9.0", "v1.10.0"},
+	}
+	for sortBy, expected := range tests {
+		tags, err := ops.ListTags(tempDir, "", sortBy)
+		if err != nil {
+			t.Fatalf("ListTags(%q) failed: %v", sortBy, err)
+		}
+		if !reflect.DeepEqual(tags, expected) {
+			t.Errorf("ListTags(%q) = %v, want %v", sortBy, tags, expected)
+		}
+	}
+
+	if _, err := ops.ListTags(tempDir, "", "size"); err == nil {
+		t.Error("Expected error for unknown sort key")
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"v1.9", "v1.10", -1},
+		{"v2.0", "v1.10", 1},
+		{"v1.0", "v1.0", 0},
+		{"v1.0", "v1.0-rc1", -1},
+		{"release-2", "release-10", -1},
+		{"alpha", "beta", -1},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.expected {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package gitops
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// SquashBranch squashes the commits of the current branch since its merge
+// base with target into a single commit with the given message, like
+// git reset --soft $(git merge-base target HEAD) followed by a commit. The
+// commit goes through the commit policy and signing like Commit.
+func (g *Operations) SquashBranch(repoPath, target, message string, opts CommitOptions) (string, error) {
+	if target == "" {
+		return "", fmt.Errorf("target is required")
+	}
+	if strings.TrimSpace(message) == "" {
+		return "", fmt.Errorf("message is required")
+	}
+	if opts.All || len(opts.Files) > 0 {
+		return "", fmt.Errorf("all and files are not supported when squashing")
+	}
+
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+	if operation := operationInProgress(repo); operation != "" {
+		return "", fmt.Errorf("cannot squash: %s", operation)
+	}
+	branch, ok := currentBranch(repo)
+	if !ok {
+		return "", fmt.Errorf("HEAD is detached, check out the branch to squash")
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	targetHash, err := resolveRevision(repo, target)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve target '%s': %w", target, err)
+	}
+
+	bases, err := mergeBases(repo, *targetHash, head.Hash())
+	if err != nil {
+		return "", err
+	}
+	if len(bases) == 0 {
+		return "", fmt.Errorf("%s and %s have no common history", branch, target)
+	}
+	base := bases[0]
+	commits, err := commitsBetween(repo, base, head.Hash())
+	if err != nil {
+		return "", err
+	}
+	if len(commits) == 0 {
+		return "", fmt.Errorf("%s has no commits since its merge base %s with %s", branch, base.String()[:7], target)
+	}
+
+	// The squashed commit records the tree of HEAD, staged changes would
+	// end up in it too
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get worktree: %w", err)
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return "", fmt.Errorf("failed to get status: %w", err)
+	}
+	for name, fileStatus := range status {
+		if fileStatus.Staging != git.Unmodified && fileStatus.Staging != git.Untracked {
+			return "", fmt.Errorf("cannot squash with staged changes (%s), commit or unstage them first", name)
+		}
+	}
+
+	if err := worktree.Reset(&git.ResetOptions{Commit: base, Mode: git.SoftReset}); err != nil {
+		return "", fmt.Errorf("failed to reset to merge base: %w", err)
+	}
+	committed, err := g.Commit(repoPath, message, opts)
+	if err != nil {
+		// Put the branch back where it was
+		if resetErr := worktree.Reset(&git.ResetOptions{Commit: head.Hash(), Mode: git.SoftReset}); resetErr != nil {
+			return "", fmt.Errorf("failed to squash: %w (and failed to restore %s to %s: %v)", err, branch, head.Hash(), resetErr)
+		}
+		return "", fmt.Errorf("failed to squash, %s is unchanged: %w", branch, err)
+	}
+
+	var result strings.Builder
+	fmt.Fprintf(&result, "Squashed %d commit(s) of %s since its merge base %s with %s:", len(commits), branch, base.String()[:7], target)
+	for _, commit := range commits {
+		fmt.Fprintf(&result, "\n  %s %s", commit.Hash.String()[:7], commitSubject(commit))
+	}
+	fmt.Fprintf(&result, "\n%s", committed)
+	fmt.Fprintf(&result, "\nThe previous tip was %s, restore it with git_reset to %s if needed", head.Hash().String()[:7], head.Hash())
+	return result.String(), nil
+}
@@ -0,0 +1,73 @@
+package gitops
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestOperations_SquashBranch(t *testing.T) {
+	tempDir, repo := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+	commitFile(t, ops, tempDir, "base.txt", "base\n", "Base")
+	if _, err := ops.Checkout(tempDir, "feature", true, ""); err != nil {
+		t.Fatalf("Checkout failed: %v", err)
+	}
+	commitFile(t, ops, tempDir, "a.txt", "a\n", "Add a")
+	commitFile(t, ops, tempDir, "a.txt", "a fixed\n", "Fix a")
+	commitFile(t, ops, tempDir, "b.txt", "b\n", "Add b")
+	base, _ := resolveCommit(repo, "master")
+	before, _ := repo.Head()
+
+	if _, err := ops.SquashBranch(tempDir, "master", "", CommitOptions{}); err == nil {
+		t.Error("Expected error without a message")
+	}
+
+	// Staged changes are refused
+	if err := os.WriteFile(tempDir+"/c.txt", []byte("c\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if _, err := ops.Add(tempDir, []string{"c.txt"}, AddOptions{}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if _, err := ops.SquashBranch(tempDir, "master", "Add a and b", CommitOptions{}); err == nil || !strings.Contains(err.Error(), "staged changes") {
+		t.Errorf("Expected staged changes to be refused, got %v", err)
+	}
+	if _, err := ops.Reset(tempDir, "mixed", "", []string{"c.txt"}, false); err != nil {
+		t.Fatalf("Reset failed: %v", err)
+	}
+
+	result, err := ops.SquashBranch(tempDir, "master", "Add a and b", CommitOptions{})
+	if err != nil {
+		t.Fatalf("SquashBranch failed: %v", err)
+	}
+	if !strings.HasPrefix(result, "Squashed 3 commit(s) of feature since its merge base "+base.Hash.String()[:7]+" with master:") ||
+		!strings.Contains(result, " Fix a\n") || !strings.HasSuffix(result, "restore it with git_reset to "+before.Hash().String()+" if needed") {
+		t.Errorf("Unexpected result:\n%s", result)
+	}
+
+	head, _ := resolveCommit(repo, "HEAD")
+	if head.Message != "Add a and b" || head.NumParents() != 1 || head.ParentHashes[0] != base.Hash {
+		t.Errorf("Expected a single commit on master, got %q with parents %v", head.Message, head.ParentHashes)
+	}
+	oldTip, _ := resolveCommit(repo, before.Hash().String())
+	if head.TreeHash != oldTip.TreeHash {
+		t.Error("Expected the squashed commit to keep the tree of the branch")
+	}
+	if ref, _ := repo.Head(); ref.Name().Short() != "feature" {
+		t.Errorf("Expected feature to stay checked out, got %s", ref.Name().Short())
+	}
+	if _, err := os.Stat(tempDir + "/c.txt"); err != nil {
+		t.Error("Expected the untracked file to be kept")
+	}
+
+	// Nothing left to squash on the target itself
+	if _, err := ops.Checkout(tempDir, "master", false, ""); err != nil {
+		t.Fatalf("Checkout failed: %v", err)
+	}
+	if _, err := ops.SquashBranch(tempDir, "feature", "Nothing", CommitOptions{}); err == nil {
+		t.Error("Expected error without commits to squash")
+	}
+}
@@ -0,0 +1,89 @@
+package gitops
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"golang.org/x/crypto/ssh"
+)
+
+// SSHAuth configures the authentication to SSH remotes such as
+// git@github.com:owner/repo.git
+type SSHAuth struct {
+	// Key authenticates the connections, nil to offer the keys of the
+	// ssh-agent at $SSH_AUTH_SOCK
+	Key ssh.Signer
+	// KeyFile is the file Key was read from, passed on to the git
+	// executable
+	KeyFile string
+	// KnownHosts are the known_hosts files verifying host keys, by default
+	// $SSH_KNOWN_HOSTS or ~/.ssh/known_hosts and /etc/ssh/ssh_known_hosts
+	KnownHosts []string
+	// InsecureIgnoreHostKey accepts any host key, exposing connections to
+	// man-in-the-middle attacks
+	InsecureIgnoreHostKey bool
+}
+
+// SetSSHAuth sets the authentication to SSH remotes. When nil, go-git's
+// defaults apply: the ssh-agent and the default known_hosts files.
+func (g *Operations) SetSSHAuth(auth *SSHAuth) {
+	g.sshAuth = auth
+}
+
+// sshAuthFor returns the authentication of user at an SSH remote
+func (g *Operations) sshAuthFor(user string) (transport.AuthMethod, error) {
+	if user == "" {
+		user = "git"
+	}
+
+	callback := ssh.InsecureIgnoreHostKey()
+	if !g.sshAuth.InsecureIgnoreHostKey {
+		var err error
+		callback, err = gitssh.NewKnownHostsCallback(g.sshAuth.KnownHosts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read known_hosts: %w", err)
+		}
+	}
+
+	if g.sshAuth.Key != nil {
+		auth := &gitssh.PublicKeys{User: user, Signer: g.sshAuth.Key}
+		auth.HostKeyCallback = callback
+		return auth, nil
+	}
+	auth, err := gitssh.NewSSHAgentAuth(user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to use ssh-agent: %w", err)
+	}
+	auth.HostKeyCallback = callback
+	return auth, nil
+}
+
+// sshCommand returns the GIT_SSH_COMMAND applying the SSH authentication to
+// the git executable, empty when it has nothing to apply
+func (g *Operations) sshCommand() string {
+	if g.sshAuth == nil {
+		return ""
+	}
+
+	var args []string
+	if g.sshAuth.KeyFile != "" {
+		args = append(args, "-i", shellQuote(g.sshAuth.KeyFile), "-o", "IdentitiesOnly=yes")
+	}
+	switch {
+	case g.sshAuth.InsecureIgnoreHostKey:
+		args = append(args, "-o", "StrictHostKeyChecking=no", "-o", "UserKnownHostsFile=/dev/null")
+	case len(g.sshAuth.KnownHosts) > 0:
+		args = append(args, "-o", shellQuote("UserKnownHostsFile="+strings.Join(g.sshAuth.KnownHosts, " ")))
+	}
+	if len(args) == 0 {
+		return ""
+	}
+	return "ssh " + strings.Join(args, " ")
+}
+
+// shellQuote quotes a word for sh
+func shellQuote(word string) string {
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
@@ -0,0 +1,275 @@
+package gitops
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// sshSignatureNamespace is the namespace git signs commits and tags in, as
+// ssh-keygen -Y sign -n git
+const sshSignatureNamespace = "git"
+
+// LoadSSHKey reads an SSH key signing commits and tags or authenticating
+// to remotes, given like user.signingkey with gpg.format=ssh: a private key
+// file, decrypted with passphrase when protected, or the public key file or
+// "key::" literal of a key held by the ssh-agent at $SSH_AUTH_SOCK
+func LoadSSHKey(key string, passphrase []byte) (ssh.Signer, error) {
+	if literal, ok := strings.CutPrefix(key, "key::"); ok {
+		return agentSigner([]byte(literal))
+	}
+
+	data, err := os.ReadFile(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SSH key: %w", err)
+	}
+	if _, _, _, _, err := ssh.ParseAuthorizedKey(data); err == nil {
+		return agentSigner(data)
+	}
+
+	signer, err := ssh.ParsePrivateKey(data)
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		if len(passphrase) == 0 {
+			return nil, fmt.Errorf("SSH key %s is protected by a passphrase, none was given (or add it to ssh-agent and use its public key)", key)
+		}
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(data, passphrase)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SSH key %s: %w", key, err)
+	}
+	return signer, nil
+}
+
+// agentKey signs with a key held by the ssh-agent, connecting to it for
+// each signature so that no connection is held between calls
+type agentKey struct {
+	socket string
+	public ssh.PublicKey
+}
+
+// agentSigner returns a signer for the ssh-agent key with an authorized
+// keys formatted public key, checking that the agent holds it
+func agentSigner(publicKey []byte) (ssh.Signer, error) {
+	public, _, _, _, err := ssh.ParseAuthorizedKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SSH public key: %w", err)
+	}
+
+	key := &agentKey{socket: os.Getenv("SSH_AUTH_SOCK"), public: public}
+	if key.socket == "" {
+		return nil, fmt.Errorf("SSH key %s is a public key but no ssh-agent is running (SSH_AUTH_SOCK is not set)", ssh.FingerprintSHA256(public))
+	}
+
+	var held bool
+	err = key.withAgent(func(client agent.ExtendedAgent) error {
+		keys, err := client.List()
+		for _, k := range keys {
+			held = held || bytes.Equal(k.Marshal(), public.Marshal())
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !held {
+		return nil, fmt.Errorf("ssh-agent does not hold the SSH key %s", ssh.FingerprintSHA256(public))
+	}
+	return key, nil
+}
+
+func (k *agentKey) PublicKey() ssh.PublicKey {
+	return k.public
+}
+
+// Sign signs data with the agent key, RSA keys with SHA-512 as git
+// signatures require
+func (k *agentKey) Sign(_ io.Reader, data []byte) (*ssh.Signature, error) {
+	var flags agent.SignatureFlags
+	if k.public.Type() == ssh.KeyAlgoRSA {
+		flags = agent.SignatureFlagRsaSha512
+	}
+
+	var signature *ssh.Signature
+	err := k.withAgent(func(client agent.ExtendedAgent) error {
+		var err error
+		signature, err = client.SignWithFlags(k.public, data, flags)
+		return err
+	})
+	return signature, err
+}
+
+func (k *agentKey) withAgent(fn func(agent.ExtendedAgent) error) error {
+	conn, err := net.Dial("unix", k.socket)
+	if err != nil {
+		return fmt.Errorf("failed to connect to ssh-agent: %w", err)
+	}
+	defer conn.Close()
+
+	if err := fn(agent.NewClient(conn)); err != nil {
+		return fmt.Errorf("ssh-agent: %w", err)
+	}
+	return nil
+}
+
+// sshSignature signs message in the SSHSIG format of ssh-keygen -Y sign,
+// returning the armored signature git stores in commits and tags
+func sshSignature(signer ssh.Signer, message []byte) (string, error) {
+	digest := sha512.Sum512(message)
+	signed := append([]byte("SSHSIG"), ssh.Marshal(struct {
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Hash          []byte
+	}{sshSignatureNamespace, "", "sha512", digest[:]})...)
+
+	var signature *ssh.Signature
+	var err error
+	if algorithmSigner, ok := signer.(ssh.AlgorithmSigner); ok && signer.PublicKey().Type() == ssh.KeyAlgoRSA {
+		signature, err = algorithmSigner.SignWithAlgorithm(rand.Reader, signed, ssh.KeyAlgoRSASHA512)
+	} else {
+		signature, err = signer.Sign(rand.Reader, signed)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to sign with SSH key: %w", err)
+	}
+
+	blob := append([]byte("SSHSIG"), ssh.Marshal(struct {
+		Version       uint32
+		PublicKey     []byte
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Signature     []byte
+	}{1, signer.PublicKey().Marshal(), sshSignatureNamespace, "", "sha512", ssh.Marshal(signature)})...)
+
+	encoded := base64.StdEncoding.EncodeToString(blob)
+	var armored strings.Builder
+	armored.WriteString("-----BEGIN SSH SIGNATURE-----\n")
+	for len(encoded) > 70 {
+		armored.WriteString(encoded[:70] + "\n")
+		encoded = encoded[70:]
+	}
+	armored.WriteString(encoded + "\n")
+	armored.WriteString("-----END SSH SIGNATURE-----\n")
+	return armored.String(), nil
+}
+
+// signedObject is a commit or a tag
+type signedObject interface {
+	Encode(plumbing.EncodedObject) error
+	EncodeWithoutSignature(plumbing.EncodedObject) error
+}
+
+// sshSignCommit replaces the commit HEAD points to by a copy signed with an
+// SSH key, go-git only signing with OpenPGP keys. When signing fails, HEAD
+// is moved back to the parent so that no unsigned commit is left behind.
+func sshSignCommit(repo *git.Repository, hash string, signer ssh.Signer) (string, error) {
+	commit, err := repo.CommitObject(plumbing.NewHash(hash))
+	if err != nil {
+		return "", fmt.Errorf("failed to get commit: %w", err)
+	}
+	target, err := headTarget(repo)
+	if err != nil {
+		return "", err
+	}
+
+	commit.PGPSignature, err = sshSignPayload(commit, signer)
+	if err != nil {
+		if len(commit.ParentHashes) > 0 {
+			_ = repo.Storer.SetReference(plumbing.NewHashReference(target, commit.ParentHashes[0]))
+		} else {
+			_ = repo.Storer.RemoveReference(target)
+		}
+		return "", err
+	}
+
+	signed, err := storeObject(repo, commit)
+	if err != nil {
+		return "", err
+	}
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(target, signed)); err != nil {
+		return "", fmt.Errorf("failed to update %s: %w", target.Short(), err)
+	}
+	return signed.String(), nil
+}
+
+// sshSignTag replaces an annotated tag by a copy signed with an SSH key,
+// deleting the tag when signing fails
+func sshSignTag(repo *git.Repository, ref *plumbing.Reference, signer ssh.Signer) error {
+	tag, err := repo.TagObject(ref.Hash())
+	if err != nil {
+		return fmt.Errorf("failed to get tag: %w", err)
+	}
+
+	tag.PGPSignature, err = sshSignPayload(tag, signer)
+	if err != nil {
+		_ = repo.Storer.RemoveReference(ref.Name())
+		return err
+	}
+
+	signed, err := storeObject(repo, tag)
+	if err != nil {
+		return err
+	}
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(ref.Name(), signed)); err != nil {
+		return fmt.Errorf("failed to update %s: %w", ref.Name().Short(), err)
+	}
+	return nil
+}
+
+// sshSignPayload signs a commit or tag as encoded without its signature
+func sshSignPayload(obj signedObject, signer ssh.Signer) (string, error) {
+	payload := &plumbing.MemoryObject{}
+	if err := obj.EncodeWithoutSignature(payload); err != nil {
+		return "", fmt.Errorf("failed to encode object: %w", err)
+	}
+	reader, err := payload.Reader()
+	if err != nil {
+		return "", fmt.Errorf("failed to encode object: %w", err)
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode object: %w", err)
+	}
+	return sshSignature(signer, data)
+}
+
+// storeObject writes a commit or tag to the object database
+func storeObject(repo *git.Repository, obj signedObject) (plumbing.Hash, error) {
+	encoded := repo.Storer.NewEncodedObject()
+	if err := obj.Encode(encoded); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to encode object: %w", err)
+	}
+	hash, err := repo.Storer.SetEncodedObject(encoded)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to store object: %w", err)
+	}
+	return hash, nil
+}
+
+// headTarget returns the reference HEAD points to, HEAD itself when it is
+// detached
+func headTarget(repo *git.Repository) (plumbing.ReferenceName, error) {
+	head, err := repo.Storer.Reference(plumbing.HEAD)
+	if err != nil {
+		return "", fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	if head.Type() == plumbing.SymbolicReference {
+		return head.Target(), nil
+	}
+	return plumbing.HEAD, nil
+}
@@ -0,0 +1,135 @@
+package gitops
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// writeSSHSigningKey generates an SSH key and writes it to a private key
+// file, protected when passphrase is set, returning the file and the
+// authorized keys line of the public key
+func writeSSHSigningKey(t *testing.T, dir string, passphrase []byte) (string, string) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	var block *pem.Block
+	if len(passphrase) > 0 {
+		block, err = ssh.MarshalPrivateKeyWithPassphrase(private, "test", passphrase)
+	} else {
+		block, err = ssh.MarshalPrivateKey(private, "test")
+	}
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	path := filepath.Join(dir, "id_ed25519")
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+	public, _ := ssh.NewPublicKey(private.Public())
+	return path, strings.TrimSpace(string(ssh.MarshalAuthorizedKey(public)))
+}
+
+// verifySSHSignature checks a commit or tag signature with git itself,
+// skipping the check when git or ssh-keygen is not available
+func verifySSHSignature(t *testing.T, repoPath, publicKey, command, name string) {
+	for _, tool := range []string{"git", "ssh-keygen"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Logf("%s not available, signature not verified", tool)
+			return
+		}
+	}
+
+	allowed := filepath.Join(t.TempDir(), "allowed_signers")
+	if err := os.WriteFile(allowed, []byte("test@example.com "+publicKey+"\n"), 0600); err != nil {
+		t.Fatalf("Failed to write allowed signers: %v", err)
+	}
+	cmd := exec.Command("git", "-c", "gpg.ssh.allowedSignersFile="+allowed, command, name)
+	cmd.Dir = repoPath
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("git %s %s failed: %v\n%s", command, name, err, output)
+	}
+}
+
+func TestOperations_SSHSignedCommitAndTag(t *testing.T) {
+	tempDir, repo := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+	keyDir := t.TempDir()
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	// Server SSH key, used without an OpenPGP key
+	keyFile, publicKey := writeSSHSigningKey(t, keyDir, []byte("secret"))
+	if _, err := LoadSSHKey(keyFile, nil); err == nil {
+		t.Error("Expected error for a protected key without passphrase")
+	}
+	if _, err := LoadSSHKey(keyFile, []byte("wrong")); err == nil {
+		t.Error("Expected error for a wrong passphrase")
+	}
+	key, err := LoadSSHKey(keyFile, []byte("secret"))
+	if err != nil {
+		t.Fatalf("LoadSSHKey failed: %v", err)
+	}
+	ops.SetSSHSigningKey(key)
+	if id := ops.SigningKeyID(); !strings.HasPrefix(id, "SHA256:") {
+		t.Errorf("Expected the SSH key fingerprint, got %s", id)
+	}
+
+	result, err := ops.Commit(tempDir, "Signed with SSH", CommitOptions{AllowEmpty: true, Sign: true})
+	if err != nil {
+		t.Fatalf("Signed commit failed: %v", err)
+	}
+	head, _ := repo.Head()
+	if !strings.HasSuffix(result, head.Hash().String()) {
+		t.Errorf("Expected the signed commit to be reported, got %s (HEAD %s)", result, head.Hash())
+	}
+	commit, _ := repo.CommitObject(head.Hash())
+	if !strings.HasPrefix(commit.PGPSignature, "-----BEGIN SSH SIGNATURE-----") {
+		t.Fatalf("Expected an SSH signature, got %q", commit.PGPSignature)
+	}
+	if commit.Message != "Signed with SSH" || len(commit.ParentHashes) != 1 {
+		t.Errorf("Unexpected signed commit: %q with %d parent(s)", commit.Message, len(commit.ParentHashes))
+	}
+	verifySSHSignature(t, tempDir, publicKey, "verify-commit", "HEAD")
+
+	if _, err := ops.CreateTag(tempDir, "v1.0", "Release 1.0", true, true); err != nil {
+		t.Fatalf("Signed tag failed: %v", err)
+	}
+	verifySSHSignature(t, tempDir, publicKey, "verify-tag", "v1.0")
+
+	// Repository key selected by gpg.format=ssh and user.signingkey
+	repoKey, repoPublicKey := writeSSHSigningKey(t, t.TempDir(), nil)
+	cfg, _ := repo.Config()
+	cfg.Raw.Section("gpg").SetOption("format", "ssh")
+	cfg.Raw.Section("user").SetOption("signingkey", repoKey)
+	if err := repo.SetConfig(cfg); err != nil {
+		t.Fatalf("Failed to set config: %v", err)
+	}
+
+	if _, err := ops.Commit(tempDir, "Signed with the repository key", CommitOptions{AllowEmpty: true, Sign: true}); err != nil {
+		t.Fatalf("Signed commit failed: %v", err)
+	}
+	verifySSHSignature(t, tempDir, repoPublicKey, "verify-commit", "HEAD")
+
+	cfg.Raw.Section("user").SetOption("signingkey", filepath.Join(keyDir, "missing"))
+	if err := repo.SetConfig(cfg); err != nil {
+		t.Fatalf("Failed to set config: %v", err)
+	}
+	before, _ := repo.Head()
+	if _, err := ops.Commit(tempDir, "Unsigned", CommitOptions{AllowEmpty: true, Sign: true}); err == nil {
+		t.Error("Expected error for a missing user.signingkey")
+	}
+	if after, _ := repo.Head(); after.Hash() != before.Hash() {
+		t.Error("Expected no commit when the signing key cannot be loaded")
+	}
+}
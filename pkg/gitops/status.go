@@ -0,0 +1,249 @@
+package gitops
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// statusKinds names the change types of the status codes
+var statusKinds = map[git.StatusCode]string{
+	git.Modified:           "modified",
+	git.Added:              "added",
+	git.Deleted:            "deleted",
+	git.Renamed:            "renamed",
+	git.Copied:             "copied",
+	git.UpdatedButUnmerged: "unmerged",
+}
+
+// statusLine is one file of a status section
+type statusLine struct {
+	path string
+	text string
+}
+
+// Status returns the working tree status grouped into conflicted, staged,
+// unstaged and untracked files (and ignored ones with includeIgnored), each
+// file with its change type. Staged deletions and additions of similar
+// content are reported as renames.
+func (g *Operations) Status(repoPath string, includeIgnored bool) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	status, err := worktree.Status()
+	if err != nil {
+		return "", fmt.Errorf("failed to get status: %w", err)
+	}
+
+	conflicts, err := indexConflicts(repo)
+	if err != nil {
+		return "", err
+	}
+
+	var conflicted, staged, unstaged, untracked []statusLine
+	for _, c := range conflicts {
+		conflicted = append(conflicted, statusLine{c.path, conflictKinds[c.code()] + ": " + c.path})
+	}
+
+	var stagedPairs []filePair
+	var tree *object.Tree
+	var entries map[string]*index.Entry
+	for file, fileStatus := range status {
+		if conflicts[file] != nil {
+			continue
+		}
+		if fileStatus.Worktree == git.Untracked {
+			untracked = append(untracked, statusLine{file, file})
+			continue
+		}
+
+		switch fileStatus.Staging {
+		case git.Unmodified:
+		case git.Added, git.Deleted:
+			// Renames are detected among staged additions and deletions
+			if entries == nil {
+				if tree, err = headTree(repo); err != nil {
+					return "", err
+				}
+				if entries, err = indexEntries(repo); err != nil {
+					return "", err
+				}
+			}
+			pair, err := stagedPair(repo, tree, entries, file, fileStatus.Staging)
+			if err != nil {
+				return "", err
+			}
+			stagedPairs = append(stagedPairs, pair)
+		default:
+			staged = append(staged, statusLine{file, statusKind(fileStatus.Staging) + ": " + file})
+		}
+
+		if fileStatus.Worktree != git.Unmodified {
+			unstaged = append(unstaged, statusLine{file, statusKind(fileStatus.Worktree) + ": " + file})
+		}
+	}
+
+	remaining, renames := detectRenames(stagedPairs, DefaultRenameThreshold)
+	for _, rename := range renames {
+		staged = append(staged, statusLine{rename.to.path, fmt.Sprintf("renamed: %s -> %s", rename.from.path, rename.to.path)})
+	}
+	for _, pair := range remaining {
+		if pair.to != nil {
+			staged = append(staged, statusLine{pair.to.path, "added: " + pair.to.path})
+		} else {
+			staged = append(staged, statusLine{pair.from.path, "deleted: " + pair.from.path})
+		}
+	}
+
+	var ignored []statusLine
+	if includeIgnored {
+		ignored, err = ignoredFiles(repo, worktree, true)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	var sections []string
+	if operation := operationInProgress(repo); operation != "" {
+		sections = append(sections, operation)
+	}
+	if len(conflicted)+len(staged)+len(unstaged)+len(untracked) == 0 {
+		sections = append(sections, "working tree clean")
+	}
+	for _, section := range []struct {
+		title string
+		lines []statusLine
+	}{
+		{"Conflicted", conflicted},
+		{"Staged", staged},
+		{"Unstaged", unstaged},
+		{"Untracked", untracked},
+		{"Ignored", ignored},
+	} {
+		if len(section.lines) > 0 {
+			sections = append(sections, formatStatusSection(section.title, section.lines))
+		}
+	}
+
+	return strings.Join(sections, "\n\n"), nil
+}
+
+// stagedPair builds the sides of a staged addition or deletion
+func stagedPair(repo *git.Repository, tree *object.Tree, entries map[string]*index.Entry, file string, code git.StatusCode) (filePair, error) {
+	if code == git.Deleted {
+		from, err := treePatchFile(tree, file)
+		if err != nil {
+			return filePair{}, err
+		}
+		if from == nil {
+			from = &patchFile{path: file}
+		}
+		return filePair{from: from}, nil
+	}
+
+	to := &patchFile{path: file}
+	if entry, ok := entries[file]; ok {
+		var err error
+		if to, err = indexPatchFile(repo, entry); err != nil {
+			return filePair{}, err
+		}
+	}
+	return filePair{to: to}, nil
+}
+
+func statusKind(code git.StatusCode) string {
+	if kind, ok := statusKinds[code]; ok {
+		return kind
+	}
+	return string(code)
+}
+
+// formatStatusSection renders a titled list of files ordered by path
+func formatStatusSection(title string, lines []statusLine) string {
+	sort.Slice(lines, func(i, j int) bool {
+		return lines[i].path < lines[j].path
+	})
+
+	var section strings.Builder
+	section.WriteString(fmt.Sprintf("%s (%d):", title, len(lines)))
+	for _, line := range lines {
+		section.WriteString("\n  " + line.text)
+	}
+	return section.String()
+}
+
+// ignoredFiles lists the untracked files and directories matched by the
+// ignore rules of the working tree. With collapse, wholly ignored
+// directories are listed once with a trailing slash, like git status
+// --ignored.
+func ignoredFiles(repo *git.Repository, worktree *git.Worktree, collapse bool) ([]statusLine, error) {
+	patterns, err := gitignore.ReadPatterns(worktree.Filesystem, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ignore rules: %w", err)
+	}
+	matcher := gitignore.NewMatcher(append(patterns, worktree.Excludes...))
+
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+	tracked := make(map[string]bool, len(idx.Entries))
+	for _, entry := range idx.Entries {
+		tracked[entry.Name] = true
+		// Directories holding tracked files are never wholly ignored
+		for dir := filepath.ToSlash(filepath.Dir(entry.Name)); dir != "."; dir = filepath.ToSlash(filepath.Dir(dir)) {
+			tracked[dir+"/"] = true
+		}
+	}
+
+	root := worktree.Filesystem.Root()
+	var ignored []statusLine
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == root {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			if collapse && matcher.Match(strings.Split(rel, "/"), true) && !tracked[rel+"/"] {
+				ignored = append(ignored, statusLine{rel + "/", rel + "/"})
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !tracked[rel] && matcher.Match(strings.Split(rel, "/"), false) {
+			ignored = append(ignored, statusLine{rel, rel})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk working tree: %w", err)
+	}
+
+	return ignored, nil
+}
@@ -0,0 +1,95 @@
+package gitops
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOperations_StatusSections(t *testing.T) {
+	tempDir, _ := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	content := strings.Repeat("a line of the file to rename\n", 10)
+	commitFile(t, ops, tempDir, "old.txt", content, "Add old")
+	commitFile(t, ops, tempDir, "gone.txt", "gone\n", "Add gone")
+	commitFile(t, ops, tempDir, ".gitignore", "*.log\nbuild/\n", "Add ignore rules")
+
+	if err := os.Rename(filepath.Join(tempDir, "old.txt"), filepath.Join(tempDir, "new.txt")); err != nil {
+		t.Fatalf("Failed to rename file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "added.txt"), []byte("added\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if _, err := ops.Add(tempDir, []string{"old.txt", "new.txt", "added.txt"}, AddOptions{}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := os.Remove(filepath.Join(tempDir, "gone.txt")); err != nil {
+		t.Fatalf("Failed to remove file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "test.txt"), []byte("changed"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "notes.txt"), []byte("notes\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "debug.log"), []byte("log\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(tempDir, "build"), 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "build", "out.bin"), []byte("out"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	expected := "Staged (2):\n  added: added.txt\n  renamed: old.txt -> new.txt\n\n" +
+		"Unstaged (2):\n  deleted: gone.txt\n  modified: test.txt\n\n" +
+		"Untracked (1):\n  notes.txt"
+	status, err := ops.Status(tempDir, false)
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if status != expected {
+		t.Errorf("Unexpected status: %q", status)
+	}
+
+	status, err = ops.Status(tempDir, true)
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if status != expected+"\n\nIgnored (2):\n  build/\n  debug.log" {
+		t.Errorf("Unexpected status with ignored files: %q", status)
+	}
+}
+
+func TestOperations_StatusCleanWithIgnored(t *testing.T) {
+	tempDir, _ := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	commitFile(t, ops, tempDir, ".gitignore", "*.log\n", "Add ignore rules")
+	if err := os.WriteFile(filepath.Join(tempDir, "debug.log"), []byte("log\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	status, err := ops.Status(tempDir, false)
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if status != "working tree clean" {
+		t.Errorf("Expected clean status, got: %q", status)
+	}
+
+	status, err = ops.Status(tempDir, true)
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if status != "working tree clean\n\nIgnored (1):\n  debug.log" {
+		t.Errorf("Unexpected status with ignored files: %q", status)
+	}
+}
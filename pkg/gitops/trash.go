@@ -0,0 +1,435 @@
+package gitops
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// DefaultTrashRetention is how long trash entries are kept before they are
+// purged
+const DefaultTrashRetention = 7 * 24 * time.Hour
+
+// trashManifest is the name of the file describing a trash entry
+const trashManifest = "manifest.json"
+
+// Trash keeps copies of working tree files that destructive operations are
+// about to discard, so they can be restored within the retention period.
+// Every operation makes one entry holding the files and a manifest.
+type Trash struct {
+	dir       string
+	retention time.Duration
+	mu        sync.Mutex
+}
+
+// TrashEntry describes the files saved before one destructive operation
+type TrashEntry struct {
+	ID         string    `json:"id"`
+	Repository string    `json:"repository"`
+	Operation  string    `json:"operation"`
+	Created    time.Time `json:"created"`
+	Files      []string  `json:"files"`
+}
+
+// NewTrash creates a trash rooted at dir, keeping entries for retention. An
+// empty dir uses DefaultTrashDir, a retention of zero or less
+// DefaultTrashRetention. The trash directory is only accessible to its
+// owner, the copies keeping the permissions of the discarded files.
+func NewTrash(dir string, retention time.Duration) *Trash {
+	if dir == "" {
+		dir = DefaultTrashDir()
+	}
+	if retention <= 0 {
+		retention = DefaultTrashRetention
+	}
+	return &Trash{
+		dir:       dir,
+		retention: retention,
+	}
+}
+
+// DefaultTrashDir returns the default trash directory, in the cache
+// directory of the user, or a directory of the user in the system temp dir
+// when it has none
+func DefaultTrashDir() string {
+	if cache, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(cache, "go-mcp-git", "trash")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("go-mcp-git-trash-%d", os.Getuid()))
+}
+
+// Dir returns the trash root directory
+func (t *Trash) Dir() string {
+	return t.dir
+}
+
+// Retention returns how long entries are kept
+func (t *Trash) Retention() time.Duration {
+	return t.retention
+}
+
+// Save copies working tree files, given relative to the repository root,
+// into a new entry. Expired entries are purged first. It returns nil without
+// creating an entry when files is empty.
+func (t *Trash) Save(repoPath, operation string, files []string) (*TrashEntry, error) {
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := os.MkdirAll(t.dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create trash directory: %w", err)
+	}
+	// A directory created by someone else, or made readable by others,
+	// would expose the discarded files
+	if err := os.Chmod(t.dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to restrict trash directory: %w", err)
+	}
+	if err := t.purgeLocked(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	path, err := os.MkdirTemp(t.dir, now.Format("20060102-150405")+"-"+strings.ReplaceAll(operation, " ", "-")+"-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trash entry: %w", err)
+	}
+
+	entry := &TrashEntry{
+		ID:         filepath.Base(path),
+		Repository: repoPath,
+		Operation:  operation,
+		Created:    now,
+		Files:      append([]string(nil), files...),
+	}
+	sort.Strings(entry.Files)
+
+	for _, name := range entry.Files {
+		src := filepath.Join(repoPath, filepath.FromSlash(name))
+		dest := filepath.Join(path, "files", filepath.FromSlash(name))
+		if err := copyFile(src, dest); err != nil {
+			os.RemoveAll(path)
+			return nil, fmt.Errorf("failed to save %s to trash: %w", name, err)
+		}
+	}
+
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		os.RemoveAll(path)
+		return nil, fmt.Errorf("failed to encode trash manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(path, trashManifest), data, 0600); err != nil {
+		os.RemoveAll(path)
+		return nil, fmt.Errorf("failed to write trash manifest: %w", err)
+	}
+
+	return entry, nil
+}
+
+// Entries returns the trash entries, newest first
+func (t *Trash) Entries() ([]*TrashEntry, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.entriesLocked()
+}
+
+func (t *Trash) entriesLocked() ([]*TrashEntry, error) {
+	dirEntries, err := os.ReadDir(t.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read trash directory: %w", err)
+	}
+
+	var entries []*TrashEntry
+	for _, de := range dirEntries {
+		if !de.IsDir() {
+			continue
+		}
+		entry, err := readTrashEntry(filepath.Join(t.dir, de.Name()))
+		if err != nil {
+			// Entries being written or damaged by hand are skipped
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].Created.Equal(entries[j].Created) {
+			return entries[i].Created.After(entries[j].Created)
+		}
+		return entries[i].ID > entries[j].ID
+	})
+	return entries, nil
+}
+
+// Restore copies the files of an entry (all of them when files is empty)
+// back into its repository, which must be repoPath unless repoPath is
+// empty. Files whose current content differs from the saved copy are only
+// replaced when overwrite is true. It returns the restored paths.
+func (t *Trash) Restore(repoPath, id string, files []string, overwrite bool) (*TrashEntry, []string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if id == "" || id != filepath.Base(id) || strings.HasPrefix(id, ".") {
+		return nil, nil, fmt.Errorf("invalid trash entry id '%s'", id)
+	}
+	path := filepath.Join(t.dir, id)
+	entry, err := readTrashEntry(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("trash entry '%s' not found", id)
+	}
+	if repoPath != "" && !sameDir(entry.Repository, repoPath) {
+		return nil, nil, fmt.Errorf("trash entry '%s' does not belong to %s", id, repoPath)
+	}
+
+	selected := entry.Files
+	if len(files) > 0 {
+		matches := pathMatcher(files)
+		selected = nil
+		for _, name := range entry.Files {
+			if matches(name) {
+				selected = append(selected, name)
+			}
+		}
+		if len(selected) == 0 {
+			return nil, nil, fmt.Errorf("trash entry '%s' holds none of: %s", id, strings.Join(files, ", "))
+		}
+	}
+
+	var conflicts []string
+	for _, name := range selected {
+		saved := filepath.Join(path, "files", filepath.FromSlash(name))
+		current := filepath.Join(entry.Repository, filepath.FromSlash(name))
+		if !overwrite && !sameFileContent(saved, current) {
+			conflicts = append(conflicts, name)
+		}
+	}
+	if len(conflicts) > 0 {
+		return nil, nil, fmt.Errorf("files were changed since they were trashed: %s; set overwrite to true to replace them", strings.Join(conflicts, ", "))
+	}
+
+	for _, name := range selected {
+		saved := filepath.Join(path, "files", filepath.FromSlash(name))
+		current := filepath.Join(entry.Repository, filepath.FromSlash(name))
+		if err := copyFile(saved, current); err != nil {
+			return nil, nil, fmt.Errorf("failed to restore %s: %w", name, err)
+		}
+	}
+
+	return entry, selected, nil
+}
+
+// purgeLocked removes the entries older than the retention period
+func (t *Trash) purgeLocked() error {
+	entries, err := t.entriesLocked()
+	if err != nil {
+		return err
+	}
+	cutoff := time.Now().Add(-t.retention)
+	for _, entry := range entries {
+		if entry.Created.Before(cutoff) {
+			if err := os.RemoveAll(filepath.Join(t.dir, entry.ID)); err != nil {
+				return fmt.Errorf("failed to purge trash entry %s: %w", entry.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// readTrashEntry reads the manifest of an entry. Manifests naming a
+// relative repository or files outside of it are rejected, so that a
+// crafted manifest cannot make Restore write elsewhere.
+func readTrashEntry(path string) (*TrashEntry, error) {
+	data, err := os.ReadFile(filepath.Join(path, trashManifest))
+	if err != nil {
+		return nil, err
+	}
+	var entry TrashEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+	if !filepath.IsAbs(entry.Repository) {
+		return nil, fmt.Errorf("invalid repository '%s' in trash manifest", entry.Repository)
+	}
+	for _, name := range entry.Files {
+		if !filepath.IsLocal(filepath.FromSlash(name)) {
+			return nil, fmt.Errorf("invalid file name '%s' in trash manifest", name)
+		}
+	}
+	entry.ID = filepath.Base(path)
+	return &entry, nil
+}
+
+// copyFile copies a regular file or symlink, replacing dest
+func copyFile(src, dest string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	if err := os.Remove(dest); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(target, dest)
+	}
+
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dest, data, info.Mode().Perm())
+}
+
+// sameFileContent reports whether two files (or symlinks) have the same
+// content. A missing b counts as the same, as restoring it loses nothing.
+func sameFileContent(a, b string) bool {
+	bInfo, err := os.Lstat(b)
+	if os.IsNotExist(err) {
+		return true
+	}
+	aInfo, aErr := os.Lstat(a)
+	if err != nil || aErr != nil || aInfo.Mode().Type() != bInfo.Mode().Type() {
+		return false
+	}
+
+	if aInfo.Mode()&os.ModeSymlink != 0 {
+		aTarget, aErr := os.Readlink(a)
+		bTarget, bErr := os.Readlink(b)
+		return aErr == nil && bErr == nil && aTarget == bTarget
+	}
+
+	aData, aErr := os.ReadFile(a)
+	bData, bErr := os.ReadFile(b)
+	return aErr == nil && bErr == nil && bytes.Equal(aData, bData)
+}
+
+// changedFiles returns the files of the working tree matching matches whose
+// content differs from the index or HEAD, i.e. the files a reset or restore
+// would discard. Untracked files are left out as neither touches them.
+func changedFiles(repo *git.Repository, matches func(string) bool) ([]string, error) {
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree: %w", err)
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status: %w", err)
+	}
+
+	root := worktree.Filesystem.Root()
+	var files []string
+	for name, fileStatus := range status {
+		if fileStatus.Worktree == git.Untracked || !matches(name) {
+			continue
+		}
+		if fileStatus.Worktree == git.Unmodified && fileStatus.Staging == git.Unmodified {
+			continue
+		}
+		// Deleted files have nothing left to save
+		info, err := os.Lstat(filepath.Join(root, filepath.FromSlash(name)))
+		if err != nil || !(info.Mode().IsRegular() || info.Mode()&os.ModeSymlink != 0) {
+			continue
+		}
+		files = append(files, name)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// saveToTrash copies the files a destructive operation is about to discard
+// into the trash, if one is configured. It returns a note for the result of
+// the operation, empty when nothing was saved.
+func (g *Operations) saveToTrash(repo *git.Repository, operation string, matches func(string) bool) (string, error) {
+	if g.trash == nil {
+		return "", nil
+	}
+
+	files, err := changedFiles(repo, matches)
+	if err != nil {
+		return "", err
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get worktree: %w", err)
+	}
+	entry, err := g.trash.Save(worktree.Filesystem.Root(), operation, files)
+	if err != nil || entry == nil {
+		return "", err
+	}
+	return fmt.Sprintf("Saved %d file(s) to trash entry %s", len(entry.Files), entry.ID), nil
+}
+
+// TrashList describes the trash entries, optionally only those of one
+// repository
+func (g *Operations) TrashList(repoPath string) (string, error) {
+	if g.trash == nil {
+		return "", fmt.Errorf("the trash is disabled")
+	}
+
+	entries, err := g.trash.Entries()
+	if err != nil {
+		return "", err
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Trash directory: %s (entries kept %s)\n", g.trash.Dir(), formatAge(g.trash.Retention())))
+	count := 0
+	for _, entry := range entries {
+		if repoPath != "" && !sameDir(entry.Repository, repoPath) {
+			continue
+		}
+		count++
+		result.WriteString(fmt.Sprintf("\n%s: %s of %s at %s (%d files)\n",
+			entry.ID, entry.Operation, entry.Repository, entry.Created.Format(time.RFC3339), len(entry.Files)))
+		for _, name := range entry.Files {
+			result.WriteString("    " + name + "\n")
+		}
+	}
+	if count == 0 {
+		result.WriteString("No trash entries\n")
+	}
+
+	return strings.TrimSpace(result.String()), nil
+}
+
+// TrashRestore copies files of a trash entry back into their repository,
+// which must be repoPath unless repoPath is empty
+func (g *Operations) TrashRestore(repoPath, id string, files []string, overwrite bool) (string, error) {
+	if g.trash == nil {
+		return "", fmt.Errorf("the trash is disabled")
+	}
+
+	entry, restored, err := g.trash.Restore(repoPath, id, files, overwrite)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Restored to %s: %s", entry.Repository, strings.Join(restored, ", ")), nil
+}
+
+// sameDir reports whether two paths name the same directory
+func sameDir(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && filepath.Clean(absA) == filepath.Clean(absB)
+}
@@ -0,0 +1,111 @@
+package gitops
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOperations_TrashHardReset(t *testing.T) {
+	tempDir, _ := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+	trashDir, err := os.MkdirTemp("", "trash-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(trashDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+	ops.SetTrash(NewTrash(trashDir, 0))
+
+	commitFile(t, ops, tempDir, "kept.txt", "committed\n", "Add kept")
+	if err := os.WriteFile(filepath.Join(tempDir, "kept.txt"), []byte("local edit\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "untracked.txt"), []byte("untracked\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	result, err := ops.Reset(tempDir, "hard", "", nil, true)
+	if err != nil {
+		t.Fatalf("Reset failed: %v", err)
+	}
+	if !strings.Contains(result, "Saved 1 file(s) to trash entry") {
+		t.Errorf("Expected the edit to be trashed, got: %s", result)
+	}
+
+	entries, err := ops.trash.Entries()
+	if err != nil {
+		t.Fatalf("Entries failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Operation != "hard reset" || strings.Join(entries[0].Files, ",") != "kept.txt" {
+		t.Fatalf("Unexpected entries: %+v", entries)
+	}
+	id := entries[0].ID
+
+	list, err := ops.TrashList(tempDir)
+	if err != nil {
+		t.Fatalf("TrashList failed: %v", err)
+	}
+	if !strings.Contains(list, id+": hard reset of ") || !strings.Contains(list, "    kept.txt") {
+		t.Errorf("Unexpected list: %s", list)
+	}
+
+	// A file changed since it was trashed is only replaced with overwrite
+	if err := os.WriteFile(filepath.Join(tempDir, "kept.txt"), []byte("newer edit\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if _, err := ops.TrashRestore(tempDir, id, nil, false); err == nil || !strings.Contains(err.Error(), "kept.txt") {
+		t.Errorf("Expected a conflict, got: %v", err)
+	}
+	if _, err := ops.TrashRestore(tempDir, id, []string{"kept.txt"}, true); err != nil {
+		t.Fatalf("TrashRestore failed: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(tempDir, "kept.txt"))
+	if err != nil || string(content) != "local edit\n" {
+		t.Errorf("Expected the trashed content, got: %q (%v)", content, err)
+	}
+
+	if _, err := ops.TrashRestore(tempDir, "../escape", nil, false); err == nil {
+		t.Error("Expected an invalid id to be rejected")
+	}
+	if _, err := ops.TrashRestore(t.TempDir(), id, nil, true); err == nil || !strings.Contains(err.Error(), "does not belong") {
+		t.Errorf("Expected an entry of another repository to be rejected, got: %v", err)
+	}
+
+	info, err := os.Stat(trashDir)
+	if err != nil || info.Mode().Perm() != 0700 {
+		t.Errorf("Expected the trash directory to be private, got %v (%v)", info.Mode().Perm(), err)
+	}
+}
+
+func TestTrash_CraftedManifest(t *testing.T) {
+	tempDir, _ := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+	trashDir := t.TempDir()
+	trash := NewTrash(trashDir, 0)
+
+	outside := t.TempDir()
+	for _, manifest := range []string{
+		`{"repository": "` + tempDir + `", "files": ["../` + filepath.Base(outside) + `/planted.txt"]}`,
+		`{"repository": "` + tempDir + `", "files": ["/etc/planted.txt"]}`,
+		`{"repository": "relative/repo", "files": ["planted.txt"]}`,
+	} {
+		entry := filepath.Join(trashDir, "crafted")
+		if err := os.MkdirAll(filepath.Join(entry, "files"), 0700); err != nil {
+			t.Fatalf("Failed to create entry: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(entry, trashManifest), []byte(manifest), 0600); err != nil {
+			t.Fatalf("Failed to write manifest: %v", err)
+		}
+
+		if _, _, err := trash.Restore("", "crafted", nil, true); err == nil {
+			t.Errorf("Expected the manifest to be rejected: %s", manifest)
+		}
+		os.RemoveAll(entry)
+	}
+	if _, err := os.Stat(filepath.Join(outside, "planted.txt")); err == nil {
+		t.Error("Expected nothing to be written outside the repository")
+	}
+}
@@ -0,0 +1,623 @@
+package gitops
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// GitStatus represents the parameters for git status
+type GitStatus struct {
+	RepoPath       string `json:"repo_path"`
+	IncludeIgnored bool   `json:"include_ignored,omitempty" description:"Also list the untracked files matched by ignore rules" default:"false"`
+}
+
+// GitDiffUnstaged represents the parameters for git diff (unstaged)
+type GitDiffUnstaged struct {
+	RepoPath string `json:"repo_path"`
+	DiffParams
+}
+
+// GitDiffStaged represents the parameters for git diff --cached
+type GitDiffStaged struct {
+	RepoPath string `json:"repo_path"`
+	DiffParams
+}
+
+// GitDiff represents the parameters for git diff with target
+type GitDiff struct {
+	RepoPath string `json:"repo_path"`
+	Target   string `json:"target" description:"Target revision to compare with (branch, tag, commit hash, HEAD~N, ...)" required:"true"`
+	DiffParams
+}
+
+// GitCommit represents the parameters for git commit
+type GitCommit struct {
+	RepoPath   string   `json:"repo_path"`
+	Message    string   `json:"message" description:"Commit message" required:"true"`
+	Files      []string `json:"files,omitempty" description:"Commit only the working tree content of these tracked paths, directories or glob patterns; other staged changes stay staged"`
+	All        bool     `json:"all,omitempty" description:"Stage modifications and deletions of tracked files before committing, like git commit -a" default:"false"`
+	AllowEmpty bool     `json:"allow_empty,omitempty" description:"Create the commit even when it records no change" default:"false"`
+	Trailers   Trailers `json:"trailers,omitempty" description:"Trailers appended to the message, e.g. {\"Co-authored-by\": [\"Name <email>\"], \"Reviewed-by\": \"Name <email>\"}"`
+	Signoff    bool     `json:"signoff,omitempty" description:"Append a Signed-off-by trailer for the committer (DCO sign-off), like git commit -s" default:"false"`
+	Sign       bool     `json:"sign,omitempty" description:"Sign the commit with the server's OpenPGP key, or an SSH key when the repository sets gpg.format=ssh" default:"false"`
+
+	AuthorName     string `json:"author_name,omitempty" description:"Name of the author (default: the server identity)"`
+	AuthorEmail    string `json:"author_email,omitempty" description:"Email of the author (default: the server identity)"`
+	AuthorDate     string `json:"author_date,omitempty" description:"Author date: RFC 3339, YYYY-MM-DD, Unix epoch (@seconds) or relative like \"2 hours ago\" (default: now)"`
+	CommitterName  string `json:"committer_name,omitempty" description:"Name of the committer (default: the server identity)"`
+	CommitterEmail string `json:"committer_email,omitempty" description:"Email of the committer (default: the server identity)"`
+	CommitterDate  string `json:"committer_date,omitempty" description:"Committer date: RFC 3339, YYYY-MM-DD, Unix epoch (@seconds) or relative like \"2 hours ago\" (default: now)"`
+}
+
+// Trailers represents the trailers of a commit by key, each given as a
+// string or a list of strings
+type Trailers map[string][]string
+
+// UnmarshalJSON accepts a string or a list of strings for every key
+func (t *Trailers) UnmarshalJSON(data []byte) error {
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	trailers := make(Trailers, len(values))
+	for key, raw := range values {
+		var value string
+		if err := json.Unmarshal(raw, &value); err == nil {
+			trailers[key] = []string{value}
+			continue
+		}
+		var list []string
+		if err := json.Unmarshal(raw, &list); err != nil {
+			return fmt.Errorf("trailer %s: expected a string or an array of strings", key)
+		}
+		trailers[key] = list
+	}
+	*t = trailers
+	return nil
+}
+
+// JSONSchema describes trailers as an object of strings or lists of strings
+func (Trailers) JSONSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"additionalProperties": map[string]interface{}{
+			"oneOf": []interface{}{
+				map[string]interface{}{"type": "string"},
+				map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+			},
+		},
+	}
+}
+
+// GitAdd represents the parameters for git add
+type GitAdd struct {
+	RepoPath string   `json:"repo_path"`
+	Files    []string `json:"files,omitempty" description:"Array of file paths, directories or glob patterns to stage"`
+	All      bool     `json:"all,omitempty" description:"Stage all changes including untracked files and deletions, like git add -A (limited to files when given)" default:"false"`
+	Update   bool     `json:"update,omitempty" description:"Stage modifications and deletions of tracked files only, like git add -u (limited to files when given)" default:"false"`
+	Force    bool     `json:"force,omitempty" description:"Also stage files ignored by .gitignore, like git add -f" default:"false"`
+}
+
+// GitReset represents the parameters for git reset
+type GitReset struct {
+	RepoPath string   `json:"repo_path"`
+	Mode     string   `json:"mode,omitempty" description:"Reset mode: 'soft' keeps index and working tree, 'mixed' resets the index, 'hard' also discards working tree changes" enum:"soft,mixed,hard" default:"mixed"`
+	Target   string   `json:"target,omitempty" description:"Revision to reset to (defaults to HEAD)"`
+	Files    []string `json:"files,omitempty" description:"Only unstage these paths, leaving HEAD and the working tree untouched"`
+
+	ConfirmationToken string `json:"confirmation_token,omitempty"`
+}
+
+// GitRestore represents the parameters for git restore
+type GitRestore struct {
+	RepoPath string   `json:"repo_path"`
+	Files    []string `json:"files" description:"Files or directories to restore" required:"true"`
+	Source   string   `json:"source,omitempty" description:"Revision to restore from (defaults to the index, or HEAD when staged is set)"`
+	Staged   bool     `json:"staged,omitempty" description:"Restore the index entries (unstage)" default:"false"`
+	Worktree *bool    `json:"worktree,omitempty" description:"Restore the working tree files (defaults to true unless staged is set)"`
+}
+
+// GitLog represents the parameters for git log
+type GitLog struct {
+	RepoPath       string `json:"repo_path"`
+	Range          string `json:"range,omitempty" description:"Revision to start from (default: HEAD) or a range: 'A..B' lists commits reachable from B but not A, 'A...B' commits reachable from either but not both"`
+	From           string `json:"from,omitempty" description:"Exclude commits reachable from this ref (same as range 'from..to')"`
+	To             string `json:"to,omitempty" description:"Show commits reachable from this ref (default: HEAD)"`
+	MaxCount       *int   `json:"max_count,omitempty" description:"Maximum number of commits to show (0 for no limit)" default:"10"`
+	StartTimestamp string `json:"start_timestamp,omitempty" description:"Only show commits committed at or after this time: RFC3339, YYYY-MM-DD, Unix epoch seconds or relative ('2 weeks ago', 'yesterday')"`
+	EndTimestamp   string `json:"end_timestamp,omitempty" description:"Only show commits committed at or before this time, in the same formats as start_timestamp (a date alone includes the whole day)"`
+	Path           string `json:"path,omitempty" description:"Only show commits touching this file or directory"`
+	Author         string `json:"author,omitempty" description:"Regular expression matched against the commit author as 'Name <email>'"`
+	Grep           string `json:"grep,omitempty" description:"Regular expression matched against the commit message"`
+	Parents        bool   `json:"parents,omitempty" description:"Include the parent hashes of every commit" default:"false"`
+	Decorate       bool   `json:"decorate,omitempty" description:"Include the branches and tags pointing at every commit" default:"false"`
+	Graph          bool   `json:"graph,omitempty" description:"Show one line per commit with an ASCII graph of merges and branches (cannot be combined with path, author, grep or timestamp filters)" default:"false"`
+}
+
+// GitCreateBranch represents the parameters for creating a branch
+type GitCreateBranch struct {
+	RepoPath   string `json:"repo_path"`
+	BranchName string `json:"branch_name" description:"Name of the new branch" required:"true"`
+	BaseBranch string `json:"base_branch,omitempty" description:"Base branch to create from (defaults to current branch)"`
+}
+
+// GitCheckout represents the parameters for git checkout
+type GitCheckout struct {
+	RepoPath   string `json:"repo_path"`
+	BranchName string `json:"branch_name" description:"Branch to checkout; a remote branch like 'origin/feature' creates a tracking branch, a commit or tag detaches HEAD" required:"true"`
+	Create     bool   `json:"create,omitempty" description:"Create the branch before switching to it (like git checkout -b)" default:"false"`
+	StartPoint string `json:"start_point,omitempty" description:"Revision the new branch starts at when create is set (defaults to HEAD)"`
+}
+
+// GitDeleteBranch represents the parameters for deleting a branch
+type GitDeleteBranch struct {
+	RepoPath   string `json:"repo_path"`
+	BranchName string `json:"branch_name" description:"Name of the branch to delete" required:"true"`
+	Force      bool   `json:"force,omitempty" description:"Delete the branch even if it is not fully merged into HEAD" default:"false"`
+
+	ConfirmationToken string `json:"confirmation_token,omitempty"`
+}
+
+// GitCurrentBranch represents the parameters for describing HEAD
+type GitCurrentBranch struct {
+	RepoPath string `json:"repo_path"`
+}
+
+// GitRenameBranch represents the parameters for renaming a branch
+type GitRenameBranch struct {
+	RepoPath string `json:"repo_path"`
+	OldName  string `json:"old_name" description:"Current name of the branch" required:"true"`
+	NewName  string `json:"new_name" description:"New name of the branch" required:"true"`
+	Force    bool   `json:"force,omitempty" description:"Overwrite an existing branch with the new name" default:"false"`
+}
+
+// GitIssueFromBranch represents the parameters for extracting an issue ID
+// from a branch name
+type GitIssueFromBranch struct {
+	RepoPath string   `json:"repo_path"`
+	Branch   string   `json:"branch,omitempty" description:"Branch name to inspect (default: current branch)"`
+	Patterns []string `json:"patterns,omitempty" description:"Regular expressions tried in order; the first capture group, or the whole match, is the issue ID (default: mcpgit.commit.issuePattern from the repository config, then built-in tracker key and issue number patterns)"`
+}
+
+// GitShow represents the parameters for git show
+type GitShow struct {
+	RepoPath string   `json:"repo_path"`
+	Revision string   `json:"revision" description:"The revision to show (commit hash, branch, tag, HEAD~N, HEAD^2, ...)" required:"true"`
+	Paths    []string `json:"paths,omitempty" description:"Limit the patch to these files or directories"`
+	DiffParams
+}
+
+// GitBranch represents the parameters for git branch
+type GitBranch struct {
+	RepoPath    string `json:"repo_path"`
+	BranchType  string `json:"branch_type" description:"Whether to list local branches ('local'), remote branches ('remote') or all branches('all')" enum:"local,remote,all" default:"local"`
+	Contains    string `json:"contains,omitempty" description:"The commit sha that branch should contain"`
+	NotContains string `json:"not_contains,omitempty" description:"The commit sha that branch should NOT contain"`
+	Sort        string `json:"sort,omitempty" description:"Sort order: name, committer date of the tip, or version numbers compared numerically; a leading '-' reverses it" enum:"name,-name,date,-date,version,-version" default:"name"`
+}
+
+// GitRawCommand represents the parameters for running a raw git command
+type GitRawCommand struct {
+	RepoPath string `json:"repo_path"`
+	Command  string `json:"command" description:"Raw Git command to execute (e.g., 'git tag -a v0.0.1 -m \"Release v0.0.1\"')" required:"true"`
+
+	ConfirmationToken string `json:"confirmation_token,omitempty"`
+}
+
+// GitInit represents the parameters for git init
+type GitInit struct {
+	RepoPath string `json:"repo_path" description:"Path where to initialize the repository" required:"true"`
+	Bare     bool   `json:"bare,omitempty" description:"Initialize as bare repository" default:"false"`
+}
+
+// GitPush represents the parameters for git push
+type GitPush struct {
+	RepoPath       string   `json:"repo_path"`
+	Remote         string   `json:"remote,omitempty" description:"Remote name (default: origin)" default:"origin"`
+	Refspec        string   `json:"refspec,omitempty" description:"Refspec to push (e.g., 'refs/heads/main:refs/heads/main')"`
+	Tags           bool     `json:"tags,omitempty" description:"Push tags along with commits" default:"false"`
+	Force          bool     `json:"force,omitempty" description:"Overwrite remote branches the pushed ones do not descend from, e.g. after a rebase" default:"false"`
+	ForceWithLease bool     `json:"force_with_lease,omitempty" description:"Force the push only while each remote branch is still at its remote-tracking branch, refusing to overwrite commits pushed by others" default:"false"`
+	SetUpstream    bool     `json:"set_upstream,omitempty" description:"Make the remote branch the upstream of the pushed branch (without refspec, pushes the current branch)" default:"false"`
+	Delete         string   `json:"delete,omitempty" description:"Remote branch to delete instead of pushing"`
+	Mirror         bool     `json:"mirror,omitempty" description:"Make the remote an exact copy: force all references, including tags and notes, and delete those missing locally" default:"false"`
+	PushOptions    []string `json:"push_options,omitempty" description:"Options for the server's hooks, like git push -o (e.g., 'ci.skip', 'merge_request.create', 'merge_request.target=main')"`
+	DryRun         bool     `json:"dry_run,omitempty" description:"Report the remote branches that would be updated, and whether the remote would accept them, without pushing" default:"false"`
+	CredentialParams
+
+	ConfirmationToken string `json:"confirmation_token,omitempty"`
+}
+
+// GitListRepositories represents the parameters for listing repositories
+type GitListRepositories struct {
+	SearchPath string `json:"search_path,omitempty" description:"Path to search for repositories (default: the repositories registered and discovered in the workspaces at startup, or the current directory without any)"`
+	Recursive  bool   `json:"recursive,omitempty" description:"Search recursively in subdirectories" default:"false"`
+}
+
+// GitCreateTag represents the parameters for creating a tag
+type GitCreateTag struct {
+	RepoPath  string `json:"repo_path"`
+	TagName   string `json:"tag_name" description:"Name of the tag to create" required:"true"`
+	Message   string `json:"message,omitempty" description:"Tag message (for annotated tags)"`
+	Annotated *bool  `json:"annotated,omitempty" description:"Create annotated tag (default: true)" default:"true"`
+	Sign      bool   `json:"sign,omitempty" description:"Sign the tag with the server's OpenPGP key, or an SSH key when the repository sets gpg.format=ssh (implies annotated)" default:"false"`
+}
+
+// GitDeleteTag represents the parameters for deleting a tag
+type GitDeleteTag struct {
+	RepoPath string `json:"repo_path"`
+	TagName  string `json:"tag_name" description:"Name of the tag to delete" required:"true"`
+}
+
+// GitListTags represents the parameters for listing tags
+type GitListTags struct {
+	RepoPath string `json:"repo_path"`
+	Pattern  string `json:"pattern,omitempty" description:"Pattern to filter tags (glob pattern)"`
+	Sort     string `json:"sort,omitempty" description:"Sort order: name, committer date of the tip, or version numbers compared numerically; a leading '-' reverses it" enum:"name,-name,date,-date,version,-version" default:"name"`
+}
+
+// GitPushTags represents the parameters for pushing tags
+type GitPushTags struct {
+	RepoPath string `json:"repo_path"`
+	Remote   string `json:"remote,omitempty" description:"Remote name (default: origin)" default:"origin"`
+	TagName  string `json:"tag_name,omitempty" description:"Specific tag name to push (leave empty to push all tags)"`
+	CredentialParams
+}
+
+// GitDiskUsage represents the parameters for reporting disk usage
+type GitDiskUsage struct {
+	RepoPath      string `json:"repo_path"`
+	AllRegistered bool   `json:"all_registered,omitempty" description:"Report on every repository registered with the server instead of a single one" default:"false"`
+}
+
+// GitMaintenance represents the parameters for compacting the object
+// database of a repository
+type GitMaintenance struct {
+	RepoPath    string `json:"repo_path"`
+	Task        string `json:"task,omitempty" description:"gc to pack the objects and prune unreachable ones, auto to gc only when git finds it needed, repack to pack all the objects into one pack, prune to delete unreachable loose objects, or commit-graph to write the commit-graph speeding up log ranges and contains queries" enum:"gc,auto,repack,prune,commit-graph" default:"gc"`
+	PruneExpire string `json:"prune_expire,omitempty" description:"With gc and prune, only delete unreachable objects older than this date, e.g. 2.weeks.ago (the git default), now or never"`
+	Aggressive  bool   `json:"aggressive,omitempty" description:"With gc, spend more time to produce smaller packs" default:"false"`
+}
+
+// GitLargeFiles represents the parameters for scanning history for large
+// blobs
+type GitLargeFiles struct {
+	RepoPath    string `json:"repo_path"`
+	ThresholdKB int    `json:"threshold_kb,omitempty" description:"Report blobs larger than this many KiB" default:"1024" minimum:"1"`
+	Range       string `json:"range,omitempty" description:"Revision or range (A..B, A...B) limiting the scanned history (default: all references)"`
+	MaxCount    int    `json:"max_count,omitempty" description:"Maximum number of blobs reported, largest first" default:"20" minimum:"1"`
+}
+
+// GitLFSFiles represents the parameters for listing the files stored in
+// Git LFS
+type GitLFSFiles struct {
+	RepoPath string `json:"repo_path"`
+	Revision string `json:"revision,omitempty" description:"Revision whose files are listed (default: HEAD, which also reports the working tree)"`
+}
+
+// GitMaterializeRevision represents the parameters for checking out a
+// revision into a temporary directory
+type GitMaterializeRevision struct {
+	RepoPath string `json:"repo_path"`
+	Revision string `json:"revision,omitempty" description:"The revision (commit hash, branch name, tag) to check out (default: HEAD)"`
+}
+
+// GitRemoveMaterialized represents the parameters for removing a
+// materialized revision
+type GitRemoveMaterialized struct {
+	Path string `json:"path" description:"Path returned by git_materialize_revision" required:"true"`
+}
+
+// GitDiffDirectory represents the parameters for diffing a revision against
+// an external directory
+type GitDiffDirectory struct {
+	RepoPath  string `json:"repo_path"`
+	Directory string `json:"directory" description:"External directory to compare against" required:"true"`
+	Revision  string `json:"revision,omitempty" description:"Revision to compare (default: HEAD)"`
+	DiffParams
+}
+
+// GitImportTree represents the parameters for importing an external
+// directory as a commit
+type GitImportTree struct {
+	RepoPath  string   `json:"repo_path"`
+	Directory string   `json:"directory" description:"External directory to import" required:"true"`
+	Branch    string   `json:"branch,omitempty" description:"Branch to commit to, created from HEAD if missing (defaults to the current branch)"`
+	Prefix    string   `json:"prefix,omitempty" description:"Subdirectory of the repository to import into (defaults to the repository root)"`
+	Mode      string   `json:"mode,omitempty" description:"'replace' mirrors the directory below prefix, 'add' only adds and overwrites files" enum:"replace,add" default:"replace"`
+	Message   string   `json:"message" description:"Commit message" required:"true"`
+	Ignore    []string `json:"ignore,omitempty" description:"Gitignore-style patterns of paths to skip"`
+}
+
+// GitCherry represents the parameters for finding equivalent commits
+// across branches
+type GitCherry struct {
+	RepoPath string `json:"repo_path"`
+	Upstream string `json:"upstream" description:"Branch the commits should be backported to (e.g. 'release-1.x')" required:"true"`
+	Head     string `json:"head,omitempty" description:"Branch holding the commits to check (default: HEAD)"`
+}
+
+// GitCompareRefs represents the parameters for counting the commits a ref is
+// ahead of and behind another
+type GitCompareRefs struct {
+	RepoPath string `json:"repo_path"`
+	Base     string `json:"base" description:"Ref to compare against (e.g. 'main' or 'origin/main')" required:"true"`
+	Head     string `json:"head,omitempty" description:"Ref whose position is reported (default: HEAD)"`
+}
+
+// GitMultiStatus represents the parameters for summarizing the status of
+// several repositories
+type GitMultiStatus struct {
+	RepoPaths     []string `json:"repo_paths,omitempty" description:"Paths to Git repositories"`
+	AllRegistered bool     `json:"all_registered,omitempty" description:"Use every repository registered with the server instead of repo_paths" default:"false"`
+}
+
+// GitMultiSync represents the parameters for fetching several repositories
+type GitMultiSync struct {
+	RepoPaths     []string `json:"repo_paths,omitempty" description:"Paths to Git repositories"`
+	AllRegistered bool     `json:"all_registered,omitempty" description:"Use every repository registered with the server instead of repo_paths" default:"false"`
+	Pull          bool     `json:"pull,omitempty" description:"Fast-forward the current branch to its upstream after fetching (skipped when the branch has diverged or has local modifications)" default:"false"`
+}
+
+// GitGrep represents the parameters for searching repository contents
+type GitGrep struct {
+	RepoPath      string   `json:"repo_path"`
+	Pattern       string   `json:"pattern" description:"Regular expression to search for" required:"true"`
+	Revision      string   `json:"revision,omitempty" description:"Revision whose files are searched (default: HEAD)"`
+	Paths         []string `json:"paths,omitempty" description:"Only search these files or directories"`
+	IgnoreCase    bool     `json:"ignore_case,omitempty" description:"Match case-insensitively" default:"false"`
+	MaxResults    int      `json:"max_results,omitempty" description:"Maximum number of matches per repository" default:"100"`
+	RepoPaths     []string `json:"repo_paths,omitempty" description:"Search these repositories instead of repo_path"`
+	AllRegistered bool     `json:"all_registered,omitempty" description:"Search every repository registered with the server" default:"false"`
+}
+
+// GitLogSearch represents the parameters for finding the commits that
+// introduce or remove a string
+type GitLogSearch struct {
+	RepoPath   string `json:"repo_path"`
+	Pattern    string `json:"pattern" description:"String whose number of occurrences the commits change, or a regular expression with regex" required:"true"`
+	Regex      bool   `json:"regex,omitempty" description:"Treat pattern as a regular expression matched against added and removed lines" default:"false"`
+	IgnoreCase bool   `json:"ignore_case,omitempty" description:"Match case-insensitively" default:"false"`
+	Range      string `json:"range,omitempty" description:"Revision to search from (default: HEAD) or a range A..B or A...B"`
+	Path       string `json:"path,omitempty" description:"Only search changes to this file or directory"`
+	MaxCount   int    `json:"max_count,omitempty" description:"Maximum number of commits to return" default:"20"`
+}
+
+// GitFileHistory represents the parameters for listing the commits
+// changing a file
+type GitFileHistory struct {
+	RepoPath     string `json:"repo_path"`
+	Path         string `json:"path" description:"File whose history is listed, as named in the starting revision" required:"true"`
+	Range        string `json:"range,omitempty" description:"Revision to start from (default: HEAD) or a range A..B or A...B"`
+	Follow       *bool  `json:"follow,omitempty" description:"Continue the history across renames" default:"true"`
+	Patch        bool   `json:"patch,omitempty" description:"Include the diff of the file in each commit" default:"false"`
+	ContextLines *int   `json:"context_lines,omitempty" description:"Number of context lines in patches" default:"3"`
+	MaxCount     int    `json:"max_count,omitempty" description:"Maximum number of commits to return" default:"20"`
+}
+
+// GitConflicts represents the parameters for listing the conflicted files
+// of an in-progress operation
+type GitConflicts struct {
+	RepoPath string `json:"repo_path"`
+}
+
+// GitListHunks represents the parameters for listing the unstaged hunks of
+// a file
+type GitListHunks struct {
+	RepoPath string `json:"repo_path"`
+	Path     string `json:"path" description:"File whose hunks are listed" required:"true"`
+}
+
+// GitStageHunks represents the parameters for staging selected hunks of a
+// file
+type GitStageHunks struct {
+	RepoPath string   `json:"repo_path"`
+	Path     string   `json:"path" description:"File whose hunks are staged" required:"true"`
+	HunkIDs  []string `json:"hunk_ids" description:"IDs of the hunks to stage, as listed by git_list_hunks" required:"true"`
+}
+
+// GitApply represents the parameters for applying a patch
+type GitApply struct {
+	RepoPath string `json:"repo_path"`
+	Patch    string `json:"patch" description:"Unified diff with paths relative to the repository root, as produced by git_diff; hunk line counts are recomputed" required:"true"`
+	Cached   bool   `json:"cached,omitempty" description:"Apply to the index only, leaving the working tree untouched (like git apply --cached)" default:"false"`
+	ThreeWay bool   `json:"three_way,omitempty" description:"Fall back to a 3-way merge when hunks do not apply, leaving conflict markers (like git apply --3way)" default:"false"`
+	Reject   bool   `json:"reject,omitempty" description:"Apply the hunks that apply and report the others, written to .rej files, instead of applying nothing" default:"false"`
+	Check    bool   `json:"check,omitempty" description:"Only report whether the patch applies" default:"false"`
+}
+
+// GitAddNote represents the parameters for attaching a note to a commit
+type GitAddNote struct {
+	RepoPath string `json:"repo_path"`
+	Revision string `json:"revision,omitempty" description:"Commit to annotate (default: HEAD)"`
+	Message  string `json:"message" description:"Content of the note" required:"true"`
+	Ref      string `json:"ref,omitempty" description:"Notes reference, e.g. 'review' for refs/notes/review (default: refs/notes/commits)"`
+	Force    bool   `json:"force,omitempty" description:"Replace an existing note" default:"false"`
+	Append   bool   `json:"append,omitempty" description:"Add the message to an existing note after a blank line" default:"false"`
+}
+
+// GitShowNote represents the parameters for showing the note of a commit
+type GitShowNote struct {
+	RepoPath string `json:"repo_path"`
+	Revision string `json:"revision,omitempty" description:"Annotated commit (default: HEAD)"`
+	Ref      string `json:"ref,omitempty" description:"Notes reference, e.g. 'review' for refs/notes/review (default: refs/notes/commits)"`
+}
+
+// GitListNotes represents the parameters for listing notes
+type GitListNotes struct {
+	RepoPath string `json:"repo_path"`
+	Ref      string `json:"ref,omitempty" description:"Notes reference, e.g. 'review' for refs/notes/review (default: refs/notes/commits)"`
+	MaxCount int    `json:"max_count,omitempty" description:"Maximum number of notes to list (default: all)" minimum:"0"`
+}
+
+// GitBundleCreate represents the parameters for writing history to a
+// bundle file
+type GitBundleCreate struct {
+	RepoPath  string   `json:"repo_path"`
+	File      string   `json:"file" description:"Bundle file to write" required:"true"`
+	Revisions []string `json:"revisions,omitempty" description:"References and ranges to bundle, e.g. main or v1.0..main for an incremental bundle, or --all, --branches and --tags (default: --all)"`
+}
+
+// GitBundleVerify represents the parameters for checking a bundle file
+type GitBundleVerify struct {
+	RepoPath string `json:"repo_path"`
+	File     string `json:"file" description:"Bundle file to check" required:"true"`
+}
+
+// GitBisect represents the parameters for a bisect action
+type GitBisect struct {
+	RepoPath  string   `json:"repo_path"`
+	Action    string   `json:"action" description:"start a bisect, mark the tested commit good, bad or skip, reset to end it, show its log, or run it to the end with command" enum:"start,good,bad,skip,reset,log,run" required:"true"`
+	Bad       string   `json:"bad,omitempty" description:"With start and run, a revision with the bug (default: HEAD)"`
+	Good      []string `json:"good,omitempty" description:"With start and run, revisions without the bug"`
+	Revisions []string `json:"revisions,omitempty" description:"With good, bad and skip, the commits to mark (default: the commit being tested)"`
+	Paths     []string `json:"paths,omitempty" description:"With start and run, only test commits touching these paths"`
+	Command   string   `json:"command,omitempty" description:"With run, shell command testing each commit in the repository: exit code 0 means good, 125 skips the commit, other codes up to 127 mean bad. The repository is reset afterwards."`
+}
+
+// GitRewriteHistory represents the parameters for rewriting the last
+// commits of a branch
+type GitRewriteHistory struct {
+	RepoPath string        `json:"repo_path"`
+	Branch   string        `json:"branch,omitempty" description:"Branch to rewrite, which is checked out (default: the current branch)"`
+	Plan     []RewriteStep `json:"plan" description:"One step for each of the last N commits of the branch, oldest first and in the new order" required:"true"`
+
+	ConfirmationToken string `json:"confirmation_token,omitempty"`
+}
+
+// GitSquashBranch represents the parameters for squashing the commits of
+// the current branch since its merge base with a target
+type GitSquashBranch struct {
+	RepoPath string `json:"repo_path"`
+	Target   string `json:"target" description:"Branch the current branch will be merged into, e.g. main" required:"true"`
+	Message  string `json:"message" description:"Message of the squashed commit" required:"true"`
+	Signoff  bool   `json:"signoff,omitempty" description:"Append a Signed-off-by trailer for the committer" default:"false"`
+	Sign     bool   `json:"sign,omitempty" description:"Sign the squashed commit with the configured key" default:"false"`
+
+	ConfirmationToken string `json:"confirmation_token,omitempty"`
+}
+
+// GitSuggestCommitMessage represents the parameters for drafting the
+// message of the staged changes
+type GitSuggestCommitMessage struct {
+	RepoPath string `json:"repo_path"`
+	Hint     string `json:"hint,omitempty" description:"What the change is about or why it was made, in the user's words, guiding the message"`
+}
+
+// GitFetch represents the parameters for fetching from a remote
+type GitFetch struct {
+	RepoPath string `json:"repo_path"`
+	Remote   string `json:"remote,omitempty" description:"Remote name (default: origin)" default:"origin"`
+	Tags     bool   `json:"tags,omitempty" description:"Fetch all tags, not only those pointing at fetched commits" default:"false"`
+	Prune    bool   `json:"prune,omitempty" description:"Delete the remote-tracking branches of branches deleted from the remote" default:"false"`
+	Depth    int    `json:"depth,omitempty" description:"Limit the history fetched to this many commits from the branch tips, deepening or shortening a shallow clone (default: all of it)" minimum:"0"`
+	Filter   string `json:"filter,omitempty" description:"Partial clone filter omitting objects until needed, e.g. 'blob:none' or 'blob:limit=1m'. Uses the git executable, tools reading the contents of omitted files may fail."`
+	Bundle   string `json:"bundle,omitempty" description:"Bundle file, as written by git_bundle_create, to fetch instead of the remote, into the remote-tracking branches of remote"`
+	CredentialParams
+}
+
+// GitRemotePrune represents the parameters for pruning stale
+// remote-tracking branches
+type GitRemotePrune struct {
+	RepoPath string `json:"repo_path"`
+	Remote   string `json:"remote,omitempty" description:"Remote name (default: origin)" default:"origin"`
+	DryRun   bool   `json:"dry_run,omitempty" description:"Only list the stale remote-tracking branches" default:"false"`
+	CredentialParams
+}
+
+// GitClone represents the parameters for cloning a repository
+type GitClone struct {
+	URL          string `json:"url" description:"URL of the repository to clone, or path of a bundle file written by git_bundle_create" required:"true"`
+	RepoPath     string `json:"repo_path" description:"Directory to clone into, which must not exist or be empty" required:"true"`
+	Branch       string `json:"branch,omitempty" description:"Branch to check out instead of the remote HEAD"`
+	Bare         bool   `json:"bare,omitempty" description:"Clone without a working tree" default:"false"`
+	Depth        int    `json:"depth,omitempty" description:"Make a shallow clone of this many commits (default: all the history)" minimum:"0"`
+	SingleBranch bool   `json:"single_branch,omitempty" description:"Only clone the history of branch, or of the remote HEAD" default:"false"`
+	Mirror       bool   `json:"mirror,omitempty" description:"Make a bare copy of all references, including tags and notes, to replicate the repository with a mirror push" default:"false"`
+	Filter       string `json:"filter,omitempty" description:"Partial clone filter omitting objects until needed, e.g. 'blob:none' or 'blob:limit=1m'. Uses the git executable, tools reading the contents of omitted files may fail."`
+	CredentialParams
+}
+
+// GitVerify represents the parameters for verifying the signature of a
+// commit or tag
+type GitVerify struct {
+	RepoPath       string `json:"repo_path"`
+	Revision       string `json:"revision,omitempty" description:"Tag name to verify the tag object, or a revision to verify the commit it resolves to" default:"HEAD"`
+	PublicKeys     string `json:"public_keys,omitempty" description:"Armored OpenPGP public keys to trust, besides the server's signing key"`
+	AllowedSigners string `json:"allowed_signers,omitempty" description:"SSH allowed signers file listing the trusted SSH keys (default: gpg.ssh.allowedSignersFile)"`
+}
+
+// GitTrashList represents the parameters for listing trash entries
+type GitTrashList struct {
+	RepoPath string `json:"repo_path,omitempty"`
+}
+
+// GitTrashRestore represents the parameters for restoring the files of a
+// trash entry
+type GitTrashRestore struct {
+	RepoPath  string   `json:"repo_path,omitempty"`
+	ID        string   `json:"id" description:"ID of the trash entry, as listed by git_trash_list" required:"true"`
+	Files     []string `json:"files,omitempty" description:"Only restore these files or directories (default: every file of the entry)"`
+	Overwrite bool     `json:"overwrite,omitempty" description:"Replace files that were changed since they were trashed" default:"false"`
+}
+
+// GitGraphExport represents the parameters for exporting the commit graph
+type GitGraphExport struct {
+	RepoPath string `json:"repo_path"`
+	Range    string `json:"range,omitempty" description:"Revision or revision range to export, e.g. 'main', 'main..feature' or 'main...feature' (default: HEAD)"`
+	MaxCount int    `json:"max_count,omitempty" description:"Maximum number of commits to export" default:"200"`
+}
+
+// GitBlameHeat represents the parameters for summarizing the age of the
+// lines of a file
+type GitBlameHeat struct {
+	RepoPath string `json:"repo_path"`
+	Path     string `json:"path" description:"File to blame, relative to the repository root" required:"true"`
+	Revision string `json:"revision,omitempty" description:"Revision to blame the file at (default: HEAD)"`
+	Bucket   string `json:"bucket,omitempty" description:"Size of the time buckets" enum:"month,quarter,year" default:"quarter"`
+}
+
+// CredentialParams represents the HTTPS credentials of a call to a remote,
+// overriding those of the server
+type CredentialParams struct {
+	Username string `json:"username,omitempty" description:"HTTPS user name for this call, used with token (default: git)"`
+	Token    string `json:"token,omitempty" description:"HTTPS password or access token for this call, overriding the server credentials"`
+}
+
+// Credentials returns the credentials of the parameters, nil without a
+// token
+func (p CredentialParams) Credentials() *Credentials {
+	if p.Token == "" {
+		return nil
+	}
+	return &Credentials{Username: p.Username, Password: p.Token}
+}
+
+// DiffParams represents the comparison and format parameters shared by the
+// diff tools
+type DiffParams struct {
+	ContextLines     *int   `json:"context_lines,omitempty" description:"Number of context lines to show" default:"3"`
+	Format           string `json:"format,omitempty" description:"Output format: full patch, diffstat, changed paths, or changed paths with status letters" enum:"patch,stat,name-only,name-status" default:"patch"`
+	IgnoreWhitespace bool   `json:"ignore_whitespace,omitempty" description:"Ignore whitespace when comparing lines, like git diff -w" default:"false"`
+	DetectRenames    bool   `json:"detect_renames,omitempty" description:"Report deleted and added files with similar content as renames" default:"false"`
+	RenameThreshold  int    `json:"rename_threshold,omitempty" description:"Minimum similarity percentage of a rename" minimum:"0" maximum:"100" default:"50"`
+}
+
+// Options returns the DiffOptions of the parameters, showing
+// DefaultContextLines lines of context when context_lines is missing
+func (p DiffParams) Options() DiffOptions {
+	contextLines := DefaultContextLines
+	if p.ContextLines != nil {
+		contextLines = *p.ContextLines
+	}
+	return DiffOptions{
+		ContextLines:     contextLines,
+		Format:           p.Format,
+		IgnoreWhitespace: p.IgnoreWhitespace,
+		DetectRenames:    p.DetectRenames,
+		RenameThreshold:  p.RenameThreshold,
+	}
+}
+
+// Default number of context lines for diff operations
+const DefaultContextLines = 3
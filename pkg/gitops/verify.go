@@ -0,0 +1,385 @@
+package gitops
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	pgperrors "github.com/ProtonMail/go-crypto/openpgp/errors"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"golang.org/x/crypto/ssh"
+)
+
+// VerifyOptions selects the keys Verify trusts besides the server signing
+// keys
+type VerifyOptions struct {
+	// PublicKeys are armored OpenPGP public keys
+	PublicKeys string
+	// AllowedSigners is an SSH allowed signers file as read by ssh-keygen
+	// -Y verify, by default the gpg.ssh.allowedSignersFile of the git
+	// configuration
+	AllowedSigners string
+}
+
+// signatureCheck is the outcome of checking a signature
+type signatureCheck struct {
+	format string
+	key    string
+	signer string
+	status string
+	valid  bool
+}
+
+// Verify checks the OpenPGP or SSH signature of a tag or commit, like git
+// verify-tag and git verify-commit. A tag name verifies the tag object,
+// other revisions the commit they resolve to. An unsigned, badly signed or
+// untrusted object is reported as not valid rather than failing.
+func (g *Operations) Verify(repoPath, revision string, opts VerifyOptions) (string, error) {
+	repo, err := g.openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer g.releaseRepository(repo)
+	if revision == "" {
+		revision = "HEAD"
+	}
+
+	var result strings.Builder
+	var hash plumbing.Hash
+	var isTag bool
+	if ref, err := repo.Reference(plumbing.NewTagReferenceName(revision), true); err == nil {
+		if tag, err := repo.TagObject(ref.Hash()); err == nil {
+			hash, isTag = tag.Hash, true
+			result.WriteString(fmt.Sprintf("Object: tag %s (%s) -> %s %s\n", tag.Name, tag.Hash.String()[:7], tag.TargetType, tag.Target.String()[:7]))
+			result.WriteString(fmt.Sprintf("Tagger: %s <%s>\n", tag.Tagger.Name, tag.Tagger.Email))
+		}
+	}
+	if !isTag {
+		commit, err := resolveCommit(repo, revision)
+		if err != nil {
+			return "", err
+		}
+		hash = commit.Hash
+		result.WriteString(fmt.Sprintf("Object: commit %s %s\n", commit.Hash.String()[:7], commitSubject(commit)))
+		result.WriteString(fmt.Sprintf("Committer: %s <%s>\n", commit.Committer.Name, commit.Committer.Email))
+	}
+
+	obj, err := repo.Storer.EncodedObject(plumbing.AnyObject, hash)
+	if err != nil {
+		return "", fmt.Errorf("failed to read object %s: %w", hash.String()[:7], err)
+	}
+	reader, err := obj.Reader()
+	if err != nil {
+		return "", fmt.Errorf("failed to read object %s: %w", hash.String()[:7], err)
+	}
+	defer reader.Close()
+	raw, err := io.ReadAll(reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to read object %s: %w", hash.String()[:7], err)
+	}
+
+	var payload []byte
+	var signature string
+	if isTag {
+		payload, signature = splitTagSignature(raw)
+	} else {
+		payload, signature = splitCommitSignature(raw)
+	}
+	if signature == "" {
+		result.WriteString("Signature: none\nValid: false")
+		return result.String(), nil
+	}
+
+	var check *signatureCheck
+	switch {
+	case strings.HasPrefix(signature, "-----BEGIN SSH SIGNATURE-----"):
+		check, err = g.checkSSHSignature(repo, payload, signature, opts.AllowedSigners)
+	case strings.HasPrefix(signature, "-----BEGIN PGP "):
+		check, err = g.checkOpenPGPSignature(payload, signature, opts.PublicKeys)
+	default:
+		check = &signatureCheck{format: "X.509", status: "X.509 signatures are not supported"}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	result.WriteString(fmt.Sprintf("Signature: %s\n", check.format))
+	if check.key != "" {
+		result.WriteString(fmt.Sprintf("Key: %s\n", check.key))
+	}
+	if check.signer != "" {
+		result.WriteString(fmt.Sprintf("Signer: %s\n", check.signer))
+	}
+	result.WriteString(fmt.Sprintf("Status: %s\n", check.status))
+	result.WriteString(fmt.Sprintf("Valid: %t", check.valid))
+	return result.String(), nil
+}
+
+// splitCommitSignature splits a raw commit into the signed payload, the
+// commit without its gpgsig header, and the signature
+func splitCommitSignature(raw []byte) ([]byte, string) {
+	headers, message, _ := bytes.Cut(raw, []byte("\n\n"))
+
+	var payload bytes.Buffer
+	var signature strings.Builder
+	inSignature := false
+	for _, line := range strings.Split(string(headers), "\n") {
+		switch {
+		case inSignature && strings.HasPrefix(line, " "):
+			signature.WriteString(line[1:] + "\n")
+			continue
+		case strings.HasPrefix(line, "gpgsig "):
+			inSignature = true
+			signature.WriteString(strings.TrimPrefix(line, "gpgsig ") + "\n")
+			continue
+		}
+		inSignature = false
+		payload.WriteString(line + "\n")
+	}
+	payload.WriteString("\n")
+	payload.Write(message)
+	return payload.Bytes(), signature.String()
+}
+
+// splitTagSignature splits a raw tag into the signed payload and the
+// signature git appends to its message
+func splitTagSignature(raw []byte) ([]byte, string) {
+	start := -1
+	for offset := 0; offset < len(raw); {
+		line := raw[offset:]
+		for _, marker := range []string{"-----BEGIN PGP SIGNATURE-----", "-----BEGIN SSH SIGNATURE-----", "-----BEGIN SIGNED MESSAGE-----"} {
+			if bytes.HasPrefix(line, []byte(marker)) {
+				start = offset
+			}
+		}
+		end := bytes.IndexByte(line, '\n')
+		if end < 0 {
+			break
+		}
+		offset += end + 1
+	}
+	if start < 0 {
+		return raw, ""
+	}
+	return raw[:start], string(raw[start:])
+}
+
+// checkOpenPGPSignature checks an OpenPGP signature against the server
+// signing key and publicKeys
+func (g *Operations) checkOpenPGPSignature(payload []byte, signature, publicKeys string) (*signatureCheck, error) {
+	check := &signatureCheck{format: "OpenPGP"}
+
+	var keyring openpgp.EntityList
+	if g.signingKey != nil {
+		keyring = append(keyring, g.signingKey)
+	}
+	if strings.TrimSpace(publicKeys) != "" {
+		keys, err := openpgp.ReadArmoredKeyRing(strings.NewReader(publicKeys))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse public keys: %w", err)
+		}
+		keyring = append(keyring, keys...)
+	}
+
+	if block, err := armor.Decode(strings.NewReader(signature)); err == nil {
+		if p, err := packet.NewReader(block.Body).Next(); err == nil {
+			if sig, ok := p.(*packet.Signature); ok {
+				switch {
+				case sig.IssuerFingerprint != nil:
+					check.key = fmt.Sprintf("%X", sig.IssuerFingerprint)
+				case sig.IssuerKeyId != nil:
+					check.key = fmt.Sprintf("%016X", *sig.IssuerKeyId)
+				}
+			}
+		}
+	}
+
+	entity, err := openpgp.CheckArmoredDetachedSignature(keyring, bytes.NewReader(payload), strings.NewReader(signature), nil)
+	switch {
+	case errors.Is(err, pgperrors.ErrUnknownIssuer):
+		check.status = "signed by an unknown key (pass its public key in public_keys)"
+	case err != nil:
+		check.status = fmt.Sprintf("bad signature: %v", err)
+	default:
+		check.key = fmt.Sprintf("%X", entity.PrimaryKey.Fingerprint)
+		if identity := entity.PrimaryIdentity(); identity != nil {
+			check.signer = identity.Name
+		}
+		check.status = "good signature from a trusted key"
+		check.valid = true
+	}
+	return check, nil
+}
+
+// checkSSHSignature checks an SSH signature of the git namespace, trusting
+// the server SSH signing key and the keys of the allowed signers file
+func (g *Operations) checkSSHSignature(repo *git.Repository, payload []byte, signature, allowedSigners string) (*signatureCheck, error) {
+	check := &signatureCheck{format: "SSH"}
+
+	public, err := verifySSHSIG(payload, signature)
+	if public != nil {
+		check.key = fmt.Sprintf("%s (%s)", ssh.FingerprintSHA256(public), public.Type())
+	}
+	if err != nil {
+		check.status = fmt.Sprintf("bad signature: %v", err)
+		return check, nil
+	}
+
+	if allowedSigners == "" {
+		if cfg, err := repo.ConfigScoped(gitconfig.GlobalScope); err == nil {
+			if file := cfg.Raw.Section("gpg").Subsection("ssh").Option("allowedSignersFile"); file != "" {
+				allowedSigners = repoKeyPath(repo, file)
+			}
+		}
+	}
+	var principals []string
+	if allowedSigners != "" {
+		principals, err = allowedPrincipals(allowedSigners, public)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(principals) == 0 && g.sshSigningKey != nil && bytes.Equal(g.sshSigningKey.PublicKey().Marshal(), public.Marshal()) {
+		principals = []string{g.userEmail}
+	}
+
+	if len(principals) == 0 {
+		check.status = "good signature from an unknown key (add it to the allowed signers)"
+		return check, nil
+	}
+	check.signer = strings.Join(principals, ", ")
+	check.status = "good signature from a trusted key"
+	check.valid = true
+	return check, nil
+}
+
+// verifySSHSIG checks an armored SSHSIG signature of message in the
+// git namespace, returning the public key it was made with
+func verifySSHSIG(message []byte, armored string) (ssh.PublicKey, error) {
+	body := strings.TrimSpace(armored)
+	body = strings.TrimPrefix(body, "-----BEGIN SSH SIGNATURE-----")
+	body = strings.TrimSuffix(body, "-----END SSH SIGNATURE-----")
+	blob, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(body), ""))
+	if err != nil || !bytes.HasPrefix(blob, []byte("SSHSIG")) {
+		return nil, fmt.Errorf("malformed SSH signature")
+	}
+
+	var sig struct {
+		Version       uint32
+		PublicKey     []byte
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Signature     []byte
+	}
+	if err := ssh.Unmarshal(blob[len("SSHSIG"):], &sig); err != nil {
+		return nil, fmt.Errorf("malformed SSH signature: %w", err)
+	}
+	public, err := ssh.ParsePublicKey(sig.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("malformed SSH signature key: %w", err)
+	}
+	if sig.Version != 1 {
+		return public, fmt.Errorf("unsupported SSH signature version %d", sig.Version)
+	}
+	if sig.Namespace != sshSignatureNamespace {
+		return public, fmt.Errorf("signature namespace is '%s', not '%s'", sig.Namespace, sshSignatureNamespace)
+	}
+
+	var digest []byte
+	switch sig.HashAlgorithm {
+	case "sha512":
+		sum := sha512.Sum512(message)
+		digest = sum[:]
+	case "sha256":
+		sum := sha256.Sum256(message)
+		digest = sum[:]
+	default:
+		return public, fmt.Errorf("unsupported hash algorithm %s", sig.HashAlgorithm)
+	}
+
+	var signature ssh.Signature
+	if err := ssh.Unmarshal(sig.Signature, &signature); err != nil {
+		return public, fmt.Errorf("malformed SSH signature: %w", err)
+	}
+	signed := append([]byte("SSHSIG"), ssh.Marshal(struct {
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Hash          []byte
+	}{sig.Namespace, sig.Reserved, sig.HashAlgorithm, digest})...)
+	if err := public.Verify(signed, &signature); err != nil {
+		return public, fmt.Errorf("signature does not match the object")
+	}
+	return public, nil
+}
+
+// allowedPrincipals returns the principals an SSH allowed signers file lets
+// sign git objects with key. Lines hold comma separated principals, options
+// such as namespaces="git" and the public key, as in authorized_keys.
+func allowedPrincipals(path string, key ssh.PublicKey) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read allowed signers: %w", err)
+	}
+
+	var principals []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var names, rest string
+		if strings.HasPrefix(line, `"`) {
+			end := strings.Index(line[1:], `"`)
+			if end < 0 {
+				continue
+			}
+			names, rest = line[1:end+1], line[end+2:]
+		} else {
+			names, rest, _ = strings.Cut(line, " ")
+		}
+
+		public, _, options, _, err := ssh.ParseAuthorizedKey([]byte(strings.TrimSpace(rest)))
+		if err != nil || !bytes.Equal(public.Marshal(), key.Marshal()) {
+			continue
+		}
+		if !allowsGitNamespace(options) {
+			continue
+		}
+		principals = append(principals, strings.Split(names, ",")...)
+	}
+	return principals, scanner.Err()
+}
+
+// allowsGitNamespace reports whether allowed signers options permit
+// signatures of the git namespace
+func allowsGitNamespace(options []string) bool {
+	for _, option := range options {
+		name, value, ok := strings.Cut(option, "=")
+		if !ok || !strings.EqualFold(name, "namespaces") {
+			continue
+		}
+		for _, namespace := range strings.Split(strings.Trim(value, `"`), ",") {
+			if namespace == sshSignatureNamespace || namespace == "*" {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
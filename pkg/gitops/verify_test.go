@@ -0,0 +1,121 @@
+package gitops
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func TestOperations_Verify(t *testing.T) {
+	tempDir, repo := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+	keyDir := t.TempDir()
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	result, err := ops.Verify(tempDir, "", VerifyOptions{})
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if !strings.Contains(result, "Signature: none") || !strings.HasSuffix(result, "Valid: false") {
+		t.Errorf("Expected an unsigned commit, got:\n%s", result)
+	}
+
+	// OpenPGP, trusted as the server key or through public_keys
+	keyFile, publicKey := writeSigningKey(t, keyDir, []byte("secret"))
+	key, err := LoadSigningKey(keyFile, "", []byte("secret"))
+	if err != nil {
+		t.Fatalf("LoadSigningKey failed: %v", err)
+	}
+	ops.SetSigningKey(key)
+	if _, err := ops.Commit(tempDir, "Signed", CommitOptions{AllowEmpty: true, Sign: true}); err != nil {
+		t.Fatalf("Signed commit failed: %v", err)
+	}
+	if _, err := ops.CreateTag(tempDir, "v1.0", "Release 1.0", true, true); err != nil {
+		t.Fatalf("Signed tag failed: %v", err)
+	}
+	for _, revision := range []string{"HEAD", "v1.0"} {
+		result, err = ops.Verify(tempDir, revision, VerifyOptions{})
+		if err != nil {
+			t.Fatalf("Verify %s failed: %v", revision, err)
+		}
+		if !strings.Contains(result, "Signature: OpenPGP") || !strings.Contains(result, "Signer: Test User <test@example.com>") || !strings.HasSuffix(result, "Valid: true") {
+			t.Errorf("Expected a valid signature on %s, got:\n%s", revision, result)
+		}
+	}
+	if !strings.HasPrefix(result, "Object: tag v1.0") {
+		t.Errorf("Expected the tag object to be verified, got:\n%s", result)
+	}
+
+	verifier := NewOperations("Release Bot", "bot@example.com")
+	result, _ = verifier.Verify(tempDir, "HEAD", VerifyOptions{})
+	if !strings.Contains(result, "unknown key") || !strings.Contains(result, "Key: ") || !strings.HasSuffix(result, "Valid: false") {
+		t.Errorf("Expected an unknown key without public keys, got:\n%s", result)
+	}
+	result, _ = verifier.Verify(tempDir, "HEAD", VerifyOptions{PublicKeys: publicKey})
+	if !strings.HasSuffix(result, "Valid: true") {
+		t.Errorf("Expected a valid signature with the public key, got:\n%s", result)
+	}
+
+	// A signature copied onto another commit does not match it
+	head, _ := repo.Head()
+	commit, _ := repo.CommitObject(head.Hash())
+	commit.Message = "Tampered"
+	forged, err := storeObject(repo, commit)
+	if err != nil {
+		t.Fatalf("Failed to store commit: %v", err)
+	}
+	result, _ = ops.Verify(tempDir, forged.String(), VerifyOptions{})
+	if !strings.Contains(result, "bad signature") || !strings.HasSuffix(result, "Valid: false") {
+		t.Errorf("Expected a bad signature, got:\n%s", result)
+	}
+
+	// SSH, trusted through the allowed signers file
+	sshKeyFile, sshPublicKey := writeSSHSigningKey(t, keyDir, nil)
+	sshKey, err := LoadSSHKey(sshKeyFile, nil)
+	if err != nil {
+		t.Fatalf("LoadSSHKey failed: %v", err)
+	}
+	ops.SetSigningKey(nil)
+	ops.SetSSHSigningKey(sshKey)
+	if _, err := ops.Commit(tempDir, "Signed with SSH", CommitOptions{AllowEmpty: true, Sign: true}); err != nil {
+		t.Fatalf("Signed commit failed: %v", err)
+	}
+
+	result, _ = verifier.Verify(tempDir, "HEAD", VerifyOptions{})
+	if !strings.Contains(result, "Signature: SSH") || !strings.Contains(result, "Key: SHA256:") || !strings.HasSuffix(result, "Valid: false") {
+		t.Errorf("Expected an untrusted SSH signature, got:\n%s", result)
+	}
+
+	allowed := filepath.Join(keyDir, "allowed_signers")
+	content := "# release signers\nother@example.com " + strings.Replace(sshPublicKey, "AAAA", "AAAB", 1) + "\n" +
+		`test@example.com,release@example.com namespaces="git" ` + sshPublicKey + "\n"
+	if err := os.WriteFile(allowed, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write allowed signers: %v", err)
+	}
+	result, err = verifier.Verify(tempDir, "HEAD", VerifyOptions{AllowedSigners: allowed})
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if !strings.Contains(result, "Signer: test@example.com") || !strings.HasSuffix(result, "Valid: true") {
+		t.Errorf("Expected a trusted SSH signature, got:\n%s", result)
+	}
+
+	// The allowed signers file of the repository configuration
+	cfg, _ := repo.Config()
+	cfg.Raw.Section("gpg").Subsection("ssh").SetOption("allowedSignersFile", allowed)
+	if err := repo.SetConfig(cfg); err != nil {
+		t.Fatalf("Failed to set config: %v", err)
+	}
+	result, _ = verifier.Verify(tempDir, "HEAD", VerifyOptions{})
+	if !strings.HasSuffix(result, "Valid: true") {
+		t.Errorf("Expected gpg.ssh.allowedSignersFile to be used, got:\n%s", result)
+	}
+
+	if _, err := verifier.Verify(tempDir, plumbing.ZeroHash.String(), VerifyOptions{}); err == nil {
+		t.Error("Expected error for an unknown revision")
+	}
+}
@@ -0,0 +1,258 @@
+package gitops
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Diff output formats
+const (
+	// DiffFormatPatch renders unified diffs
+	DiffFormatPatch = "patch"
+	// DiffFormatStat renders a diffstat like git diff --stat
+	DiffFormatStat = "stat"
+	// DiffFormatNameOnly lists the changed paths
+	DiffFormatNameOnly = "name-only"
+	// DiffFormatNameStatus lists the changed paths with their status letter
+	DiffFormatNameStatus = "name-status"
+)
+
+// DiffFormats lists the accepted values of DiffOptions.Format
+var DiffFormats = []string{DiffFormatPatch, DiffFormatStat, DiffFormatNameOnly, DiffFormatNameStatus}
+
+// DefaultRenameThreshold is the similarity percentage above which a deleted
+// and an added file are reported as a rename, as in git
+const DefaultRenameThreshold = 50
+
+// DiffOptions controls how the diff tools compare and render changes
+type DiffOptions struct {
+	ContextLines int
+	// Format is one of the DiffFormat constants, patch when empty
+	Format string
+	// IgnoreWhitespace ignores whitespace when comparing lines, dropping
+	// files whose changes are whitespace only
+	IgnoreWhitespace bool
+	// DetectRenames pairs deleted and added files of similar content
+	DetectRenames bool
+	// RenameThreshold is the minimum similarity percentage of a rename,
+	// DefaultRenameThreshold when 0
+	RenameThreshold int
+}
+
+// filePair holds the two sides of a changed file, either of which may be nil
+type filePair struct {
+	from, to *patchFile
+}
+
+// buildFilePatches computes the patches of changed files according to opts,
+// ordered by path
+func buildFilePatches(pairs []filePair, opts DiffOptions) ([]fdiff.FilePatch, error) {
+	if opts.RenameThreshold < 0 || opts.RenameThreshold > 100 {
+		return nil, fmt.Errorf("invalid rename threshold %d (expected 0-100)", opts.RenameThreshold)
+	}
+
+	var renames []*filePatch
+	if opts.DetectRenames {
+		threshold := opts.RenameThreshold
+		if threshold == 0 {
+			threshold = DefaultRenameThreshold
+		}
+		pairs, renames = detectRenames(pairs, threshold)
+	}
+
+	filePatches := make([]fdiff.FilePatch, 0, len(pairs)+len(renames))
+	for _, rename := range renames {
+		patch := newFilePatch(rename.from, rename.to, opts.IgnoreWhitespace)
+		patch.similarity = rename.similarity
+		filePatches = append(filePatches, patch)
+	}
+	for _, pair := range pairs {
+		if patch := newFilePatch(pair.from, pair.to, opts.IgnoreWhitespace); patch.hasChanges() {
+			filePatches = append(filePatches, patch)
+		}
+	}
+
+	sortFilePatches(filePatches)
+	return filePatches, nil
+}
+
+// renderFilePatches renders file patches in the format selected by opts
+func renderFilePatches(filePatches []fdiff.FilePatch, opts DiffOptions) (string, error) {
+	switch opts.Format {
+	case "", DiffFormatPatch:
+		return encodePatch(filePatches, opts.ContextLines)
+	case DiffFormatStat:
+		return formatStats(fileStats(filePatches)), nil
+	case DiffFormatNameOnly:
+		paths := make([]string, 0, len(filePatches))
+		for _, fp := range filePatches {
+			paths = append(paths, patchPath(fp))
+		}
+		return strings.Join(paths, "\n"), nil
+	case DiffFormatNameStatus:
+		return summarizeFilePatches(filePatches), nil
+	default:
+		return "", fmt.Errorf("unknown diff format '%s' (expected one of %s)", opts.Format, strings.Join(DiffFormats, ", "))
+	}
+}
+
+// fileStats counts the added and deleted lines of file patches. Binary
+// files are listed with no line counts.
+func fileStats(filePatches []fdiff.FilePatch) object.FileStats {
+	stats := make(object.FileStats, 0, len(filePatches))
+	for _, fp := range filePatches {
+		stat := object.FileStat{Name: patchPath(fp)}
+		if from, to := fp.Files(); from != nil && to != nil && from.Path() != to.Path() {
+			stat.Name = from.Path() + " => " + to.Path()
+		}
+		for _, chunk := range fp.Chunks() {
+			content := chunk.Content()
+			lines := strings.Count(content, "\n")
+			if content != "" && !strings.HasSuffix(content, "\n") {
+				lines++
+			}
+			switch chunk.Type() {
+			case fdiff.Add:
+				stat.Addition += lines
+			case fdiff.Delete:
+				stat.Deletion += lines
+			}
+		}
+		stats = append(stats, stat)
+	}
+	return stats
+}
+
+// sortFilePatches orders file patches by path, as git does
+func sortFilePatches(filePatches []fdiff.FilePatch) {
+	sort.Slice(filePatches, func(i, j int) bool {
+		return patchPath(filePatches[i]) < patchPath(filePatches[j])
+	})
+}
+
+// sameContent reports whether two patch sides hold the same content and mode.
+// Two missing sides are the same.
+func sameContent(from, to *patchFile) bool {
+	if from == nil || to == nil {
+		return from == nil && to == nil
+	}
+	return from.hash == to.hash && from.mode == to.mode
+}
+
+// readFileInfo loads a file on disk as a patch side named rel. It returns
+// nil for anything but regular files and symlinks.
+func readFileInfo(path, rel string, info os.FileInfo) (*patchFile, error) {
+	var content []byte
+	mode := filemode.Regular
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(path)
+		if err != nil {
+			return nil, err
+		}
+		content = []byte(target)
+		mode = filemode.Symlink
+	case info.Mode().IsRegular():
+		var err error
+		content, err = os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if info.Mode()&0111 != 0 {
+			mode = filemode.Executable
+		}
+	default:
+		return nil, nil
+	}
+	return newPatchFile(rel, mode, content), nil
+}
+
+// readWorktreeFile loads a working tree file as a patch side, returning nil
+// when it does not exist
+func readWorktreeFile(root, name string) (*patchFile, error) {
+	path := filepath.Join(root, filepath.FromSlash(name))
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	file, err := readFileInfo(path, name, info)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return file, nil
+}
+
+// indexEntries returns the merged entries of the repository index by path.
+// Unmerged paths are left out, see indexConflicts.
+func indexEntries(repo *git.Repository) (map[string]*index.Entry, error) {
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+	entries := make(map[string]*index.Entry, len(idx.Entries))
+	for _, entry := range idx.Entries {
+		if entry.Stage == mergedStage {
+			entries[entry.Name] = entry
+		}
+	}
+	return entries, nil
+}
+
+// indexPatchFile loads a staged file as a patch side
+func indexPatchFile(repo *git.Repository, entry *index.Entry) (*patchFile, error) {
+	blob, err := repo.BlobObject(entry.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read staged %s: %w", entry.Name, err)
+	}
+	file := object.NewFile(entry.Name, entry.Mode, blob)
+	return blobPatchFile(file)
+}
+
+// treePatchFile loads a file of a tree as a patch side, returning nil when
+// the tree is nil (an unborn branch) or does not contain the file
+func treePatchFile(tree *object.Tree, name string) (*patchFile, error) {
+	if tree == nil {
+		return nil, nil
+	}
+	file, err := tree.File(name)
+	if err == object.ErrFileNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return blobPatchFile(file)
+}
+
+// headTree returns the tree of HEAD, or nil on an unborn branch
+func headTree(repo *git.Repository) (*object.Tree, error) {
+	head, err := repo.Head()
+	if err != nil {
+		if err == plumbing.ErrReferenceNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit: %w", err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD tree: %w", err)
+	}
+	return tree, nil
+}
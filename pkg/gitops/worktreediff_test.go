@@ -0,0 +1,159 @@
+package gitops
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOperations_DiffFormats(t *testing.T) {
+	tempDir, _ := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	if result, err := ops.DiffUnstaged(tempDir, DiffOptions{}); err != nil || result != "no unstaged changes" {
+		t.Fatalf("Expected no unstaged changes, got: %q, %v", result, err)
+	}
+
+	// Stage a new file, then modify the committed one without staging it
+	if err := os.WriteFile(filepath.Join(tempDir, "new.txt"), []byte("one\ntwo\n"), 0644); err != nil {
+		t.Fatalf("Failed to write new.txt: %v", err)
+	}
+	if _, err := ops.Add(tempDir, []string{"new.txt"}, AddOptions{}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "test.txt"), []byte("changed\n"), 0644); err != nil {
+		t.Fatalf("Failed to write test.txt: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "untracked.txt"), []byte("ignored\n"), 0644); err != nil {
+		t.Fatalf("Failed to write untracked.txt: %v", err)
+	}
+
+	result, err := ops.DiffUnstaged(tempDir, DiffOptions{ContextLines: DefaultContextLines})
+	if err != nil {
+		t.Fatalf("DiffUnstaged failed: %v", err)
+	}
+	for _, expected := range []string{"diff --git a/test.txt b/test.txt", "-test content", "+changed"} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected %q in unstaged diff, got: %s", expected, result)
+		}
+	}
+	if strings.Contains(result, "new.txt") || strings.Contains(result, "untracked.txt") {
+		t.Errorf("Expected only unstaged tracked changes, got: %s", result)
+	}
+
+	result, err = ops.DiffStaged(tempDir, DiffOptions{Format: DiffFormatNameStatus})
+	if err != nil {
+		t.Fatalf("DiffStaged failed: %v", err)
+	}
+	if result != "A\tnew.txt" {
+		t.Errorf("Expected new.txt staged, got: %s", result)
+	}
+
+	result, err = ops.Diff(tempDir, "HEAD", DiffOptions{Format: DiffFormatNameOnly})
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if result != "new.txt\ntest.txt" {
+		t.Errorf("Expected both tracked changes, got: %s", result)
+	}
+
+	result, err = ops.Diff(tempDir, "HEAD", DiffOptions{Format: DiffFormatStat})
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if !strings.Contains(result, "new.txt | 2 ++") || !strings.Contains(result, "2 files changed, 3 insertions(+), 1 deletions(-)") {
+		t.Errorf("Unexpected stat output: %s", result)
+	}
+
+	if _, err := ops.Diff(tempDir, "HEAD", DiffOptions{Format: "bogus"}); err == nil {
+		t.Error("Expected error for unknown format")
+	}
+}
+
+func TestOperations_DiffWhitespaceAndRenames(t *testing.T) {
+	tempDir, _ := createTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	ops := NewOperations("Test User", "test@example.com")
+
+	original := "func main() {\n\tfmt.Println(\"one\")\n\tfmt.Println(\"two\")\n\tfmt.Println(\"three\")\n}\n"
+	if err := os.WriteFile(filepath.Join(tempDir, "main.go"), []byte(original), 0644); err != nil {
+		t.Fatalf("Failed to write main.go: %v", err)
+	}
+	if _, err := ops.Add(tempDir, []string{"main.go"}, AddOptions{}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if _, err := ops.Commit(tempDir, "Add main", CommitOptions{}); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	// Reindent main.go with spaces instead of tabs
+	reindented := "func main() {\n    fmt.Println(\"one\")\n    fmt.Println(\"two\")\n    fmt.Println(\"three\")\n}\n"
+	if err := os.WriteFile(filepath.Join(tempDir, "main.go"), []byte(reindented), 0644); err != nil {
+		t.Fatalf("Failed to write main.go: %v", err)
+	}
+
+	result, err := ops.DiffUnstaged(tempDir, DiffOptions{IgnoreWhitespace: true})
+	if err != nil {
+		t.Fatalf("DiffUnstaged failed: %v", err)
+	}
+	if result != "no unstaged changes" {
+		t.Errorf("Expected whitespace-only change to be ignored, got: %s", result)
+	}
+
+	result, err = ops.DiffUnstaged(tempDir, DiffOptions{Format: DiffFormatStat})
+	if err != nil {
+		t.Fatalf("DiffUnstaged failed: %v", err)
+	}
+	if !strings.Contains(result, "3 insertions(+), 3 deletions(-)") {
+		t.Errorf("Expected reindented lines without -w, got: %s", result)
+	}
+
+	moved := strings.Replace(original, "three", "THREE", 1)
+	if err := os.Remove(filepath.Join(tempDir, "main.go")); err != nil {
+		t.Fatalf("Failed to remove main.go: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "cmd.go"), []byte(moved), 0644); err != nil {
+		t.Fatalf("Failed to write cmd.go: %v", err)
+	}
+	if _, err := ops.Add(tempDir, []string{"main.go", "cmd.go"}, AddOptions{}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	result, err = ops.DiffStaged(tempDir, DiffOptions{Format: DiffFormatNameStatus})
+	if err != nil {
+		t.Fatalf("DiffStaged failed: %v", err)
+	}
+	if result != "A\tcmd.go\nD\tmain.go" {
+		t.Errorf("Expected add and delete without rename detection, got: %s", result)
+	}
+
+	result, err = ops.DiffStaged(tempDir, DiffOptions{Format: DiffFormatNameStatus, DetectRenames: true})
+	if err != nil {
+		t.Fatalf("DiffStaged failed: %v", err)
+	}
+	if !strings.HasPrefix(result, "R0") || !strings.HasSuffix(result, "\tmain.go\tcmd.go") {
+		t.Errorf("Expected rename of main.go to cmd.go, got: %s", result)
+	}
+
+	result, err = ops.DiffStaged(tempDir, DiffOptions{DetectRenames: true, ContextLines: DefaultContextLines})
+	if err != nil {
+		t.Fatalf("DiffStaged failed: %v", err)
+	}
+	for _, expected := range []string{"rename from main.go", "rename to cmd.go", "+\tfmt.Println(\"THREE\")"} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected %q in rename patch, got: %s", expected, result)
+		}
+	}
+
+	result, err = ops.DiffStaged(tempDir, DiffOptions{Format: DiffFormatNameStatus, DetectRenames: true, RenameThreshold: 100})
+	if err != nil {
+		t.Fatalf("DiffStaged failed: %v", err)
+	}
+	if strings.HasPrefix(result, "R") {
+		t.Errorf("Expected no rename at a 100%% threshold, got: %s", result)
+	}
+}
@@ -0,0 +1,249 @@
+package mcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidToken is returned by token validators rejecting a token
+var ErrInvalidToken = errors.New("invalid token")
+
+// TokenValidator checks the bearer token of a connection, returning
+// ErrInvalidToken for tokens to reject and other errors when the check
+// itself failed
+type TokenValidator func(ctx context.Context, token string) error
+
+// StaticTokens accepts the given tokens, comparing them in constant time
+func StaticTokens(tokens ...string) TokenValidator {
+	digests := make([][sha256.Size]byte, len(tokens))
+	for i, token := range tokens {
+		digests[i] = sha256.Sum256([]byte(token))
+	}
+	return func(ctx context.Context, token string) error {
+		digest := sha256.Sum256([]byte(token))
+		accepted := 0
+		for _, d := range digests {
+			accepted |= subtle.ConstantTimeCompare(digest[:], d[:])
+		}
+		if accepted == 0 {
+			return ErrInvalidToken
+		}
+		return nil
+	}
+}
+
+// IntrospectionOptions configures the validation of OAuth2 access tokens
+// by an introspection endpoint (RFC 7662)
+type IntrospectionOptions struct {
+	// URL is the introspection endpoint of the authorization server
+	URL string
+	// ClientID and ClientSecret authenticate the server to the endpoint
+	ClientID     string
+	ClientSecret string
+	// Audience, when set, must be one of the audiences of the token
+	Audience string
+	// CacheTTL is how long an active token is trusted before being
+	// introspected again, one minute when zero
+	CacheTTL time.Duration
+	// Client sends the requests, a client with a 10 second timeout when
+	// nil
+	Client *http.Client
+}
+
+// maxIntrospectedTokens caps the active tokens kept in cache
+const maxIntrospectedTokens = 1024
+
+// IntrospectTokens accepts the OAuth2 access tokens the introspection
+// endpoint reports as active. Active tokens are cached until they expire
+// or for the cache TTL, whichever comes first.
+func IntrospectTokens(opts IntrospectionOptions) TokenValidator {
+	if opts.CacheTTL == 0 {
+		opts.CacheTTL = time.Minute
+	}
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	var mu sync.Mutex
+	cache := make(map[[sha256.Size]byte]time.Time)
+	return func(ctx context.Context, token string) error {
+		key := sha256.Sum256([]byte(token))
+		now := time.Now()
+		mu.Lock()
+		expires, ok := cache[key]
+		mu.Unlock()
+		if ok && now.Before(expires) {
+			return nil
+		}
+
+		expires, err := introspect(ctx, client, opts, token)
+		if err != nil {
+			return err
+		}
+		if limit := now.Add(opts.CacheTTL); expires.IsZero() || expires.After(limit) {
+			expires = limit
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if len(cache) >= maxIntrospectedTokens {
+			for k, e := range cache {
+				if !now.Before(e) {
+					delete(cache, k)
+				}
+			}
+			if len(cache) >= maxIntrospectedTokens {
+				cache = make(map[[sha256.Size]byte]time.Time)
+			}
+		}
+		cache[key] = expires
+		return nil
+	}
+}
+
+// introspectionResponse holds the fields of an introspection response the
+// server checks. The audience is a string or a list of strings.
+type introspectionResponse struct {
+	Active    bool            `json:"active"`
+	ExpiresAt int64           `json:"exp,omitempty"`
+	Audience  json.RawMessage `json:"aud,omitempty"`
+}
+
+// introspect asks the endpoint about a token, returning its expiry, zero
+// when the endpoint does not tell
+func introspect(ctx context.Context, client *http.Client, opts IntrospectionOptions, token string) (time.Time, error) {
+	form := url.Values{"token": {token}, "token_type_hint": {"access_token"}}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, opts.URL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to create introspection request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+	if opts.ClientID != "" {
+		request.SetBasicAuth(url.QueryEscape(opts.ClientID), url.QueryEscape(opts.ClientSecret))
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to introspect token: %w", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return time.Time{}, fmt.Errorf("failed to introspect token: %s", response.Status)
+	}
+	var result introspectionResponse
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return time.Time{}, fmt.Errorf("failed to decode introspection response: %w", err)
+	}
+
+	if !result.Active {
+		return time.Time{}, ErrInvalidToken
+	}
+	if opts.Audience != "" && !hasAudience(result.Audience, opts.Audience) {
+		return time.Time{}, ErrInvalidToken
+	}
+	if result.ExpiresAt > 0 {
+		return time.Unix(result.ExpiresAt, 0), nil
+	}
+	return time.Time{}, nil
+}
+
+// hasAudience reports whether the aud claim, a string or a list of
+// strings, holds an audience
+func hasAudience(claim json.RawMessage, audience string) bool {
+	var single string
+	if err := json.Unmarshal(claim, &single); err == nil {
+		return single == audience
+	}
+	var list []string
+	if err := json.Unmarshal(claim, &list); err == nil {
+		for _, a := range list {
+			if a == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// bearerToken returns the token of the Authorization header of a request,
+// or of its access_token query parameter (RFC 6750), which browsers need
+// as they cannot set headers on WebSocket connections
+func bearerToken(r *http.Request) string {
+	if scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " "); ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return r.URL.Query().Get("access_token")
+}
+
+// DefaultTokenRevalidation is how often the bearer token of a WebSocket
+// connection is validated again by default
+const DefaultTokenRevalidation = time.Minute
+
+// watchToken validates the token of a connection again every interval
+// until stop is called, closing the connection once the token is rejected,
+// e.g. revoked or expired. Failures of the validator itself leave the
+// connection open, the next check deciding.
+func watchToken(conn io.Closer, token string, validate TokenValidator, interval time.Duration, remote string) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			err := validate(ctx, token)
+			switch {
+			case err == nil || ctx.Err() != nil:
+			case errors.Is(err, ErrInvalidToken):
+				slog.Warn("Closing connection whose bearer token is no longer valid", "remote", remote)
+				conn.Close()
+				return
+			default:
+				slog.Error("Failed to validate bearer token again", "remote", remote, "error", err)
+			}
+		}
+	}()
+	return cancel
+}
+
+// authenticate checks the bearer token of a request, answering 401
+// Unauthorized for missing and rejected tokens and 503 when the validator
+// failed
+func authenticate(w http.ResponseWriter, r *http.Request, validate TokenValidator) bool {
+	token := bearerToken(r)
+	if token == "" {
+		slog.Warn("Rejected connection without bearer token", "remote", r.RemoteAddr)
+		w.Header().Set("WWW-Authenticate", `Bearer realm="mcp"`)
+		http.Error(w, "bearer token required", http.StatusUnauthorized)
+		return false
+	}
+	err := validate(r.Context(), token)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, ErrInvalidToken):
+		slog.Warn("Rejected connection with an invalid bearer token", "remote", r.RemoteAddr)
+		w.Header().Set("WWW-Authenticate", `Bearer realm="mcp", error="invalid_token"`)
+		http.Error(w, "invalid bearer token", http.StatusUnauthorized)
+	default:
+		slog.Error("Failed to validate bearer token", "remote", r.RemoteAddr, "error", err)
+		http.Error(w, "failed to validate bearer token", http.StatusServiceUnavailable)
+	}
+	return false
+}
@@ -0,0 +1,235 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStaticTokens(t *testing.T) {
+	validate := StaticTokens("first", "second")
+	for _, token := range []string{"first", "second"} {
+		if err := validate(context.Background(), token); err != nil {
+			t.Errorf("Expected %q to be accepted, got: %v", token, err)
+		}
+	}
+	for _, token := range []string{"", "firs", "first ", "third"} {
+		if err := validate(context.Background(), token); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Expected %q to be rejected, got: %v", token, err)
+		}
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	tests := []struct {
+		name          string
+		url           string
+		authorization string
+		token         string
+	}{
+		{"header", "/", "Bearer secret", "secret"},
+		{"case insensitive scheme", "/", "bearer  secret ", "secret"},
+		{"query parameter", "/?access_token=secret", "", "secret"},
+		{"header over query parameter", "/?access_token=other", "Bearer secret", "secret"},
+		{"other scheme", "/", "Basic c2VjcmV0", ""},
+		{"none", "/", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+			if got := bearerToken(r); got != tt.token {
+				t.Errorf("Expected token %q, got %q", tt.token, got)
+			}
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	failing := func(ctx context.Context, token string) error {
+		return errors.New("endpoint unreachable")
+	}
+	tests := []struct {
+		name     string
+		token    string
+		validate TokenValidator
+		ok       bool
+		status   int
+	}{
+		{"valid", "secret", StaticTokens("secret"), true, http.StatusOK},
+		{"missing", "", StaticTokens("secret"), false, http.StatusUnauthorized},
+		{"invalid", "wrong", StaticTokens("secret"), false, http.StatusUnauthorized},
+		{"validator failure", "secret", failing, false, http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.token != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			if ok := authenticate(w, r, tt.validate); ok != tt.ok {
+				t.Fatalf("Expected %v, got %v", tt.ok, ok)
+			}
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, w.Code)
+			}
+			if tt.status == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Errorf("Expected a WWW-Authenticate challenge")
+			}
+		})
+	}
+}
+
+func TestHasAudience(t *testing.T) {
+	tests := []struct {
+		claim string
+		in    bool
+	}{
+		{`"mcp-git"`, true},
+		{`"other"`, false},
+		{`["other", "mcp-git"]`, true},
+		{`["other"]`, false},
+		{`[]`, false},
+		{`42`, false},
+		{``, false},
+	}
+	for _, tt := range tests {
+		if got := hasAudience(json.RawMessage(tt.claim), "mcp-git"); got != tt.in {
+			t.Errorf("hasAudience(%s) = %v, expected %v", tt.claim, got, tt.in)
+		}
+	}
+}
+
+// introspectionServer answers introspection requests from a table of
+// tokens, counting the requests
+type introspectionServer struct {
+	*httptest.Server
+	requests atomic.Int32
+}
+
+func newIntrospectionServer(t *testing.T, tokens map[string]string) *introspectionServer {
+	s := &introspectionServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests.Add(1)
+		if user, password, ok := r.BasicAuth(); !ok || user != "client" || password != "secret" {
+			http.Error(w, "unauthorized client", http.StatusUnauthorized)
+			return
+		}
+		response, ok := tokens[r.FormValue("token")]
+		if !ok {
+			response = `{"active": false}`
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(response))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestIntrospectTokens(t *testing.T) {
+	server := newIntrospectionServer(t, map[string]string{
+		"active":       `{"active": true}`,
+		"audience":     `{"active": true, "aud": ["other", "mcp-git"]}`,
+		"wrong-aud":    `{"active": true, "aud": "other"}`,
+		"expired":      `{"active": true, "exp": 1}`,
+		"not-json":     `active`,
+		"inactive":     `{"active": false}`,
+		"no-audience":  `{"active": true, "scope": "git"}`,
+		"expires-soon": `{"active": true, "exp": 4102444800}`,
+	})
+	opts := IntrospectionOptions{URL: server.URL, ClientID: "client", ClientSecret: "secret", CacheTTL: time.Hour}
+	validate := IntrospectTokens(opts)
+	ctx := context.Background()
+
+	for _, token := range []string{"active", "audience", "no-audience", "expires-soon"} {
+		if err := validate(ctx, token); err != nil {
+			t.Errorf("Expected %q to be accepted, got: %v", token, err)
+		}
+	}
+	for _, token := range []string{"inactive", "unknown"} {
+		if err := validate(ctx, token); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Expected %q to be rejected, got: %v", token, err)
+		}
+	}
+	if err := validate(ctx, "not-json"); err == nil || errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected a malformed response to fail the check, got: %v", err)
+	}
+
+	// Active tokens are cached, unless they expired
+	requests := server.requests.Load()
+	if err := validate(ctx, "active"); err != nil {
+		t.Fatalf("Expected the cached token to be accepted, got: %v", err)
+	}
+	if server.requests.Load() != requests {
+		t.Errorf("Expected the active token to be cached")
+	}
+	validate(ctx, "expired")
+	validate(ctx, "expired")
+	if server.requests.Load() != requests+2 {
+		t.Errorf("Expected expired tokens not to be cached")
+	}
+
+	// The audience must match when configured
+	opts.Audience = "mcp-git"
+	validate = IntrospectTokens(opts)
+	if err := validate(ctx, "audience"); err != nil {
+		t.Errorf("Expected the audience to match, got: %v", err)
+	}
+	for _, token := range []string{"wrong-aud", "no-audience"} {
+		if err := validate(ctx, token); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Expected %q to be rejected for its audience, got: %v", token, err)
+		}
+	}
+
+	// Errors of the endpoint are failures, not rejections
+	opts.ClientSecret = "wrong"
+	if err := IntrospectTokens(opts)(ctx, "active"); err == nil || errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected an endpoint error to fail the check, got: %v", err)
+	}
+}
+
+// closeRecorder records that a connection was closed
+type closeRecorder struct {
+	once   sync.Once
+	closed chan struct{}
+}
+
+func (c *closeRecorder) Close() error {
+	c.once.Do(func() { close(c.closed) })
+	return nil
+}
+
+func TestWatchToken(t *testing.T) {
+	var revoked atomic.Bool
+	validate := func(ctx context.Context, token string) error {
+		if revoked.Load() {
+			return ErrInvalidToken
+		}
+		return nil
+	}
+	conn := &closeRecorder{closed: make(chan struct{})}
+	stop := watchToken(conn, "secret", validate, 5*time.Millisecond, "test")
+	defer stop()
+
+	select {
+	case <-conn.closed:
+		t.Fatalf("Expected a valid token to keep the connection open")
+	case <-time.After(30 * time.Millisecond):
+	}
+
+	revoked.Store(true)
+	select {
+	case <-conn.closed:
+	case <-time.After(time.Second):
+		t.Fatalf("Expected the connection to be closed once the token is revoked")
+	}
+}
@@ -0,0 +1,109 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+)
+
+// isBatch reports whether a message is a JSON-RPC batch, an array of
+// requests, notifications or responses
+func isBatch(line []byte) bool {
+	trimmed := bytes.TrimSpace(line)
+	return len(trimmed) > 0 && trimmed[0] == '['
+}
+
+// isNotification reports whether a message has no id member, which
+// JSON-RPC notifications leave out
+func isNotification(line []byte) bool {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(line, &members); err != nil {
+		return false
+	}
+	_, hasID := members["id"]
+	return !hasID
+}
+
+// takeResponses passes the responses of the client to the requests waiting
+// for them, returning the rest of the message to handle, nil when nothing
+// is left. Responses may come alone or within a batch.
+func (session *Session) takeResponses(line []byte) []byte {
+	if !isBatch(line) {
+		if session.takeResponse(line) {
+			return nil
+		}
+		return line
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(line, &items); err != nil || len(items) == 0 {
+		// Left to handleMessage to answer
+		return line
+	}
+	rest := items[:0]
+	for _, item := range items {
+		if !session.takeResponse(item) {
+			rest = append(rest, item)
+		}
+	}
+	if len(rest) == 0 {
+		return nil
+	}
+	if len(rest) == len(items) {
+		return line
+	}
+	remaining, err := json.Marshal(rest)
+	if err != nil {
+		return line
+	}
+	return remaining
+}
+
+// handleMessage handles a request, a notification or a batch of them.
+// The requests of a batch are handled in order, one at a time.
+func (s *Server) handleMessage(ctx context.Context, line []byte) handledRequest {
+	if !isBatch(line) {
+		return handledRequest{responses: s.handle(ctx, line)}
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(line, &items); err != nil {
+		return handledRequest{responses: []*JSONRPCResponse{{
+			JSONRPC: JSONRPCVersion,
+			Error: &RPCError{
+				Code:    -32700,
+				Message: "Parse error",
+			},
+		}}}
+	}
+	if len(items) == 0 {
+		return handledRequest{responses: []*JSONRPCResponse{{
+			JSONRPC: JSONRPCVersion,
+			Error: &RPCError{
+				Code:    -32600,
+				Message: "Invalid Request: empty batch",
+			},
+		}}}
+	}
+
+	h := handledRequest{batch: true}
+	for _, item := range items {
+		h.responses = append(h.responses, s.handle(ctx, item)...)
+	}
+	return h
+}
+
+// handle handles a single request or notification, returning its response
+// if it has one
+func (s *Server) handle(ctx context.Context, request []byte) []*JSONRPCResponse {
+	response, err := s.handleRequest(ctx, request)
+	if err != nil {
+		slog.Error("Failed to handle request", "error", err)
+		return nil
+	}
+	if response == nil {
+		return nil
+	}
+	return []*JSONRPCResponse{response}
+}
@@ -0,0 +1,133 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const testInitialize = `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`
+
+// serveLines serves newline framed messages until their end, returning the
+// messages written back
+func serveLines(t *testing.T, server *Server, messages ...string) []string {
+	t.Helper()
+	var out bytes.Buffer
+	in := strings.NewReader(strings.Join(messages, "\n") + "\n")
+	if err := server.ServeTransport(context.Background(), NewStdioTransport(in, &out, FramingNewline)); err != nil {
+		t.Fatalf("ServeTransport failed: %v", err)
+	}
+	return strings.Split(strings.TrimSpace(out.String()), "\n")
+}
+
+// decodeBatch decodes a batch of responses
+func decodeBatch(t *testing.T, line string) []JSONRPCResponse {
+	t.Helper()
+	var responses []JSONRPCResponse
+	if err := json.Unmarshal([]byte(line), &responses); err != nil {
+		t.Fatalf("Expected a batch of responses, got %s: %v", line, err)
+	}
+	return responses
+}
+
+// decodeResponse decodes a single response
+func decodeResponse(t *testing.T, line string) JSONRPCResponse {
+	t.Helper()
+	var response JSONRPCResponse
+	if err := json.Unmarshal([]byte(line), &response); err != nil {
+		t.Fatalf("Expected a response, got %s: %v", line, err)
+	}
+	return response
+}
+
+func TestBatch_Mixed(t *testing.T) {
+	// A response of the client comes along with requests and a
+	// notification
+	lines := serveLines(t, NewServer("test", "1"),
+		testInitialize,
+		`[{"jsonrpc":"2.0","id":2,"method":"ping"},{"jsonrpc":"2.0","method":"notifications/initialized"},{"jsonrpc":"2.0","id":99,"result":{}},{"jsonrpc":"2.0","id":3,"method":"ping"}]`,
+	)
+	if len(lines) != 2 {
+		t.Fatalf("Expected the initialize response and one batch, got: %q", lines)
+	}
+	responses := decodeBatch(t, lines[1])
+	if len(responses) != 2 || responses[0].ID != float64(2) || responses[1].ID != float64(3) {
+		t.Errorf("Expected the responses to both requests in order, got: %s", lines[1])
+	}
+}
+
+func TestBatch_OnlyNotifications(t *testing.T) {
+	lines := serveLines(t, NewServer("test", "1"),
+		testInitialize,
+		`[{"jsonrpc":"2.0","method":"notifications/initialized"},{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":9}}]`,
+		`{"jsonrpc":"2.0","id":4,"method":"ping"}`,
+	)
+	if len(lines) != 2 {
+		t.Fatalf("Expected no reply to the notifications, got: %q", lines)
+	}
+	if response := decodeResponse(t, lines[1]); response.ID != float64(4) {
+		t.Errorf("Expected the response to the ping, got: %s", lines[1])
+	}
+}
+
+func TestBatch_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		code    int
+	}{
+		{"empty batch", `[]`, -32600},
+		{"malformed batch", `[{"jsonrpc":"2.0","id":2,`, -32700},
+		{"malformed message", `{"jsonrpc":"2.0","id":2,`, -32700},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := serveLines(t, NewServer("test", "1"), testInitialize, tt.message)
+			if len(lines) != 2 {
+				t.Fatalf("Expected one error response, got: %q", lines)
+			}
+			response := decodeResponse(t, lines[1])
+			if response.Error == nil || response.Error.Code != tt.code {
+				t.Errorf("Expected error %d, got: %s", tt.code, lines[1])
+			}
+		})
+	}
+}
+
+func TestTakeResponses(t *testing.T) {
+	session := testSession(NewServer("test", "1"))
+	waiting := make(chan clientResponse, 1)
+	session.pending = map[string]chan clientResponse{"7": waiting}
+
+	// Responses are passed on, requests are left to handle
+	rest := session.takeResponses([]byte(`[{"jsonrpc":"2.0","id":7,"result":{}},{"jsonrpc":"2.0","id":2,"method":"ping"}]`))
+	var items []map[string]interface{}
+	if err := json.Unmarshal(rest, &items); err != nil || len(items) != 1 || items[0]["method"] != "ping" {
+		t.Errorf("Expected the ping to be left, got: %s", rest)
+	}
+	select {
+	case response := <-waiting:
+		if string(response.ID) != "7" {
+			t.Errorf("Unexpected response: %+v", response)
+		}
+	default:
+		t.Errorf("Expected the response to reach the waiting request")
+	}
+
+	// Batches of responses leave nothing, even those nobody waits for
+	if rest := session.takeResponses([]byte(`[{"jsonrpc":"2.0","id":8,"result":{}},{"jsonrpc":"2.0","id":9,"error":{"code":-1,"message":"no"}}]`)); rest != nil {
+		t.Errorf("Expected nothing left, got: %s", rest)
+	}
+	if rest := session.takeResponses([]byte(`{"jsonrpc":"2.0","id":8,"result":{}}`)); rest != nil {
+		t.Errorf("Expected a single response to be taken, got: %s", rest)
+	}
+
+	// Requests and malformed batches are left untouched
+	for _, line := range []string{`{"jsonrpc":"2.0","id":2,"method":"ping"}`, `[{"jsonrpc":"2.0","id":2,"method":"ping"}]`, `[]`, `[{`} {
+		if rest := session.takeResponses([]byte(line)); string(rest) != line {
+			t.Errorf("Expected %s to be left untouched, got: %s", line, rest)
+		}
+	}
+}
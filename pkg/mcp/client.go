@@ -0,0 +1,99 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ErrNotServing is returned by requests to the client while the server is
+// not serving
+var ErrNotServing = errors.New("server is not serving")
+
+// clientResponse is the response of the client to a request of the server
+type clientResponse struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Result json.RawMessage `json:"result"`
+	Error  *RPCError       `json:"error"`
+}
+
+// Request sends a request to the client of the session ctx belongs to and
+// decodes the result of its response into result, which may be nil. Tool
+// handlers use it to query the client, e.g. its roots.
+func (s *Server) Request(ctx context.Context, method string, params interface{}, result interface{}) error {
+	session := SessionFromContext(ctx)
+	if session == nil {
+		return ErrNotServing
+	}
+	return session.request(ctx, method, params, result)
+}
+
+// request sends a request to the client of the session
+func (session *Session) request(ctx context.Context, method string, params interface{}, result interface{}) error {
+	var encodedParams json.RawMessage
+	if params != nil {
+		var err error
+		if encodedParams, err = json.Marshal(params); err != nil {
+			return fmt.Errorf("failed to encode %s params: %w", method, err)
+		}
+	}
+
+	session.mu.Lock()
+	session.nextID++
+	id := session.nextID
+	responses := make(chan clientResponse, 1)
+	session.pending[strconv.FormatInt(id, 10)] = responses
+	session.mu.Unlock()
+	defer func() {
+		session.mu.Lock()
+		delete(session.pending, strconv.FormatInt(id, 10))
+		session.mu.Unlock()
+	}()
+
+	encoded, err := json.Marshal(JSONRPCRequest{JSONRPC: JSONRPCVersion, ID: id, Method: method, Params: encodedParams})
+	if err != nil {
+		return fmt.Errorf("failed to encode %s request: %w", method, err)
+	}
+	if err := session.write(encoded); err != nil {
+		return err
+	}
+
+	select {
+	case response := <-responses:
+		if response.Error != nil {
+			return fmt.Errorf("client failed %s: %s (%d)", method, response.Error.Message, response.Error.Code)
+		}
+		if result != nil {
+			if err := json.Unmarshal(response.Result, result); err != nil {
+				return fmt.Errorf("invalid %s result: %w", method, err)
+			}
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("no response to %s: %w", method, ctx.Err())
+	}
+}
+
+// takeResponse passes a message to the request it answers, reporting
+// whether it was the response to a request of the server
+func (session *Session) takeResponse(line []byte) bool {
+	var response clientResponse
+	if err := json.Unmarshal(line, &response); err != nil {
+		return false
+	}
+	if response.Method != "" || len(response.ID) == 0 || (response.Result == nil && response.Error == nil) {
+		return false
+	}
+
+	session.mu.Lock()
+	responses, ok := session.pending[string(response.ID)]
+	session.mu.Unlock()
+	if ok {
+		responses <- response
+	}
+	// Responses nobody waits for any longer are dropped
+	return true
+}
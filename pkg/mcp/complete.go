@@ -0,0 +1,85 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// MaxCompletionValues caps the values of a completion/complete response
+const MaxCompletionValues = 100
+
+// RefTypeTool references a tool by name in completion/complete requests,
+// completing the arguments of its calls
+const RefTypeTool = "ref/tool"
+
+// CompletionHandler completes an argument. Handlers not knowing the
+// reference or the argument return handled false, leaving the request to
+// the next handler.
+type CompletionHandler func(ctx context.Context, request CompleteRequest) (values []string, handled bool, err error)
+
+// HandleCompletions adds a handler of completion/complete requests and
+// declares the completions capability. Handlers are tried in the order
+// they were added.
+func (s *Server) HandleCompletions(handler CompletionHandler) {
+	s.completers = append(s.completers, handler)
+	s.capabilities.Completions = &CompletionsCapability{}
+}
+
+// complete asks the handlers for the values of an argument, no handler
+// knowing it leaving no values
+func (s *Server) complete(ctx context.Context, request CompleteRequest) (Completion, error) {
+	completion := Completion{Values: []string{}}
+	for _, handler := range s.completers {
+		values, handled, err := handler(ctx, request)
+		if err != nil {
+			return completion, err
+		}
+		if !handled {
+			continue
+		}
+		completion.Total = len(values)
+		if len(values) > MaxCompletionValues {
+			values = values[:MaxCompletionValues]
+			completion.HasMore = true
+		}
+		if values != nil {
+			completion.Values = values
+		}
+		break
+	}
+	return completion, nil
+}
+
+// handleComplete handles the completion/complete request
+func (s *Server) handleComplete(ctx context.Context, request JSONRPCRequest) (*JSONRPCResponse, error) {
+	var completeReq CompleteRequest
+	if err := json.Unmarshal(request.Params, &completeReq); err != nil || completeReq.Argument.Name == "" {
+		return &JSONRPCResponse{
+			JSONRPC: JSONRPCVersion,
+			ID:      request.ID,
+			Error: &RPCError{
+				Code:    -32602,
+				Message: "Invalid params: argument name is required",
+			},
+		}, nil
+	}
+
+	completion, err := s.complete(ctx, completeReq)
+	if err != nil {
+		return &JSONRPCResponse{
+			JSONRPC: JSONRPCVersion,
+			ID:      request.ID,
+			Error: &RPCError{
+				Code:    -32603,
+				Message: fmt.Sprintf("Failed to complete %s: %v", completeReq.Argument.Name, err),
+			},
+		}, nil
+	}
+
+	return &JSONRPCResponse{
+		JSONRPC: JSONRPCVersion,
+		ID:      request.ID,
+		Result:  CompleteResult{Completion: completion},
+	}, nil
+}
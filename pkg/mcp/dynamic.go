@@ -0,0 +1,127 @@
+package mcp
+
+// Tools can change while serving: they are registered, unregistered,
+// enabled or disabled at any time, e.g. by a tool call, and clients are
+// notified with notifications/tools/list_changed. Disabled tools are
+// neither listed nor callable until enabled again. Tool filters hide
+// tools from some sessions only.
+
+// UnregisterTools removes tools from the server, reporting whether any of
+// them was registered
+func (s *Server) UnregisterTools(names ...string) bool {
+	s.toolsMu.Lock()
+	removed := false
+	for _, name := range names {
+		if _, ok := s.toolHandlers[name]; !ok {
+			continue
+		}
+		for i := range s.tools {
+			if s.tools[i].Name == name {
+				s.tools = append(s.tools[:i], s.tools[i+1:]...)
+				break
+			}
+		}
+		delete(s.toolHandlers, name)
+		delete(s.toolSchemas, name)
+		delete(s.disabled, name)
+		removed = true
+	}
+	s.toolsMu.Unlock()
+
+	if removed {
+		s.toolsChanged()
+	}
+	return removed
+}
+
+// EnableTools makes disabled tools available again
+func (s *Server) EnableTools(names ...string) {
+	s.setToolsEnabled(names, true)
+}
+
+// DisableTools hides tools from tools/list and rejects their calls, until
+// they are enabled again
+func (s *Server) DisableTools(names ...string) {
+	s.setToolsEnabled(names, false)
+}
+
+// ToolEnabled reports whether a tool is registered and enabled
+func (s *Server) ToolEnabled(name string) bool {
+	s.toolsMu.RLock()
+	defer s.toolsMu.RUnlock()
+	_, ok := s.toolHandlers[name]
+	return ok && !s.disabled[name]
+}
+
+// ToolFilter reports whether a session sees a tool, e.g. from a value the
+// session keeps with SetValue. The session is nil outside sessions.
+type ToolFilter func(session *Session, name string) bool
+
+// FilterTools adds a filter hiding the tools it rejects from tools/list and
+// tools/call, for the sessions it rejects them for. Once the answer of a
+// filter changes for a session, Session.ToolsChanged notifies its client.
+func (s *Server) FilterTools(filter ToolFilter) {
+	s.toolsMu.Lock()
+	s.filters = append(s.filters, filter)
+	s.toolsMu.Unlock()
+}
+
+// toolVisible reports whether a registered tool is enabled and seen by a
+// session. It is called with toolsMu held.
+func (s *Server) toolVisible(session *Session, name string) bool {
+	if s.disabled[name] {
+		return false
+	}
+	for _, filter := range s.filters {
+		if !filter(session, name) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Server) setToolsEnabled(names []string, enabled bool) {
+	s.toolsMu.Lock()
+	changed := false
+	for _, name := range names {
+		if _, ok := s.toolHandlers[name]; ok && s.disabled[name] == enabled {
+			if enabled {
+				delete(s.disabled, name)
+			} else {
+				s.disabled[name] = true
+			}
+			changed = true
+		}
+	}
+	s.toolsMu.Unlock()
+
+	if changed {
+		s.toolsChanged()
+	}
+}
+
+// lookupTool returns the handler and input schema of a tool the session
+// sees
+func (s *Server) lookupTool(session *Session, name string) (ToolHandler, interface{}, bool) {
+	s.toolsMu.RLock()
+	defer s.toolsMu.RUnlock()
+	handler, ok := s.toolHandlers[name]
+	if !ok || !s.toolVisible(session, name) {
+		return nil, nil, false
+	}
+	return handler, s.toolSchemas[name], true
+}
+
+// toolsChanged notifies the clients that the list of tools changed.
+// Sessions not initialized yet need no notification.
+func (s *Server) toolsChanged() {
+	s.broadcast(NotificationToolsListChanged, nil)
+}
+
+// ToolsChanged notifies the client of the session that its list of tools
+// changed, after a tool filter changed its answer for the session
+func (session *Session) ToolsChanged() {
+	if session.Info().Initialized {
+		session.notify(NotificationToolsListChanged, nil)
+	}
+}
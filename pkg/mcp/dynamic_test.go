@@ -0,0 +1,62 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFilterTools(t *testing.T) {
+	server := NewServer("test", "1")
+	handler := func(ctx context.Context, arguments map[string]interface{}) ([]TextContent, error) {
+		return nil, nil
+	}
+	server.RegisterTool(Tool{Name: "open"}, handler)
+	server.RegisterTool(Tool{Name: "guarded"}, handler)
+	type unlockedKey struct{}
+	server.FilterTools(func(session *Session, name string) bool {
+		return name != "guarded" || (session != nil && session.Value(unlockedKey{}) != nil)
+	})
+
+	unlocked, locked := testSession(server), testSession(server)
+	unlocked.SetValue(unlockedKey{}, true)
+
+	tests := []struct {
+		name    string
+		session *Session
+		tool    string
+		visible bool
+	}{
+		{"unfiltered tool", locked, "open", true},
+		{"filtered out", locked, "guarded", false},
+		{"filtered in", unlocked, "guarded", true},
+		{"outside sessions", nil, "guarded", false},
+		{"unknown tool", unlocked, "unknown", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, ok := server.lookupTool(tt.session, tt.tool); ok != tt.visible {
+				t.Errorf("Expected callable %v, got %v", tt.visible, ok)
+			}
+			response, err := server.listTools(tt.session, "")
+			if err != nil {
+				t.Fatalf("listTools failed: %v", err)
+			}
+			listed := false
+			for _, tool := range response.Tools {
+				listed = listed || tool.Name == tt.tool
+			}
+			if listed != tt.visible {
+				t.Errorf("Expected listed %v, got %v", tt.visible, listed)
+			}
+		})
+	}
+
+	// Disabled tools stay hidden whatever the filters
+	server.DisableTools("guarded")
+	if _, _, ok := server.lookupTool(unlocked, "guarded"); ok {
+		t.Errorf("Expected a disabled tool to be hidden")
+	}
+	if !server.ToolEnabled("open") || server.ToolEnabled("guarded") {
+		t.Errorf("Expected ToolEnabled to ignore the filters")
+	}
+}
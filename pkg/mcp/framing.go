@@ -0,0 +1,176 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Framing separates the messages of the stdio transport
+type Framing string
+
+const (
+	// FramingAuto detects the framing from the first message of the
+	// client and answers the same way
+	FramingAuto Framing = "auto"
+	// FramingNewline sends one message per line, the MCP stdio transport
+	FramingNewline Framing = "newline"
+	// FramingHeaders prefixes each message with a Content-Length header,
+	// like the Language Server Protocol
+	FramingHeaders Framing = "content-length"
+)
+
+// Framings lists the accepted framings
+var Framings = []Framing{FramingAuto, FramingNewline, FramingHeaders}
+
+// ParseFraming returns the framing of a name, auto when empty
+func ParseFraming(name string) (Framing, error) {
+	if name == "" {
+		return FramingAuto, nil
+	}
+	for _, framing := range Framings {
+		if string(framing) == name {
+			return framing, nil
+		}
+	}
+	return "", fmt.Errorf("invalid framing '%s' (expected auto, newline or content-length)", name)
+}
+
+// SetFraming sets the framing of the messages, auto by default
+func (s *Server) SetFraming(framing Framing) {
+	s.framing = framing
+}
+
+// frameReaderBufferSize is the buffer size of frameReader, also the
+// maximum length of a message header line
+const frameReaderBufferSize = 64 << 10
+
+// frameReader reads the messages of the client. Messages have no size
+// limit, lines and bodies being read whole whatever their length, but
+// header lines are limited to frameReaderBufferSize bytes. Bodies are read
+// as they arrive rather than allocated from their Content-Length up front.
+type frameReader struct {
+	r       *bufio.Reader
+	framing Framing
+}
+
+func newFrameReader(r io.Reader, framing Framing) *frameReader {
+	if framing == "" {
+		framing = FramingAuto
+	}
+	return &frameReader{r: bufio.NewReaderSize(r, frameReaderBufferSize), framing: framing}
+}
+
+// read returns the next message and the framing it came with. Blank lines
+// between messages are skipped.
+func (f *frameReader) read() ([]byte, Framing, error) {
+	if f.framing == FramingAuto {
+		framing, err := f.detect()
+		if err != nil {
+			return nil, "", err
+		}
+		f.framing = framing
+	}
+
+	if f.framing == FramingHeaders {
+		message, err := f.readFramed()
+		return message, f.framing, err
+	}
+	for {
+		line, err := f.r.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			// A last message without newline is still a message
+			return line, f.framing, nil
+		}
+		if err != nil {
+			return nil, f.framing, err
+		}
+	}
+}
+
+// detect skips the whitespace before the first message and tells JSON,
+// which starts with { or [, from headers
+func (f *frameReader) detect() (Framing, error) {
+	for {
+		b, err := f.r.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		case '{', '[':
+			return FramingNewline, f.r.UnreadByte()
+		}
+		return FramingHeaders, f.r.UnreadByte()
+	}
+}
+
+// readFramed reads the headers of a message, up to a blank line, and then
+// the number of bytes given by Content-Length. Other headers, such as
+// Content-Type, are ignored.
+func (f *frameReader) readFramed() ([]byte, error) {
+	length := -1
+	sawHeader := false
+	for {
+		slice, err := f.r.ReadSlice('\n')
+		line := string(slice)
+		if err != nil {
+			if err == bufio.ErrBufferFull {
+				return nil, fmt.Errorf("message header longer than %d bytes", frameReaderBufferSize)
+			}
+			if err == io.EOF && (sawHeader || strings.TrimSpace(line) != "") {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			if !sawHeader {
+				// Blank lines between messages
+				continue
+			}
+			break
+		}
+		sawHeader = true
+
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid message header %q", line)
+		}
+		if strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid Content-Length %q", strings.TrimSpace(value))
+			}
+			length = n
+		}
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("message without Content-Length header")
+	}
+
+	var message bytes.Buffer
+	if _, err := io.CopyN(&message, f.r, int64(length)); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, fmt.Errorf("failed to read message body: %w", err)
+	}
+	return message.Bytes(), nil
+}
+
+// frame encodes a message for the client in one buffer, so that it is
+// written at once
+func frame(message []byte, framing Framing) []byte {
+	if framing == FramingHeaders {
+		header := fmt.Sprintf("Content-Length: %d\r\n\r\n", len(message))
+		framed := make([]byte, 0, len(header)+len(message))
+		return append(append(framed, header...), message...)
+	}
+	framed := make([]byte, 0, len(message)+1)
+	return append(append(framed, message...), '\n')
+}
@@ -0,0 +1,133 @@
+package mcp
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestFrameReader_Detect(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		framing  Framing
+		messages []string
+	}{
+		{
+			name:     "newline",
+			input:    "\n  {\"id\":1}\n\n[{\"id\":2}]\n{\"id\":3}",
+			framing:  FramingNewline,
+			messages: []string{`{"id":1}`, `[{"id":2}]`, `{"id":3}`},
+		},
+		{
+			name:     "content-length",
+			input:    "Content-Length: 8\r\n\r\n{\"id\":1}\r\nContent-Type: application/json\r\ncontent-length: 10\r\n\r\n[{\"id\":2}]",
+			framing:  FramingHeaders,
+			messages: []string{`{"id":1}`, `[{"id":2}]`},
+		},
+		{
+			name:     "content-length with newlines in the body",
+			input:    "\r\nContent-Length: 12\r\n\r\n{\n\"id\":1\n}\n\n",
+			framing:  FramingHeaders,
+			messages: []string{"{\n\"id\":1\n}\n\n"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := newFrameReader(strings.NewReader(tt.input), FramingAuto)
+			for _, expected := range tt.messages {
+				message, framing, err := reader.read()
+				if err != nil {
+					t.Fatalf("read failed: %v", err)
+				}
+				if framing != tt.framing {
+					t.Errorf("Expected framing %s, got %s", tt.framing, framing)
+				}
+				got := string(message)
+				if framing == FramingNewline {
+					// Lines keep their newline
+					got = strings.TrimSpace(got)
+				}
+				if got != expected {
+					t.Errorf("Expected message %q, got %q", expected, message)
+				}
+			}
+			if _, _, err := reader.read(); err != io.EOF {
+				t.Errorf("Expected io.EOF after the last message, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestFrameReader_InvalidHeaders(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		error string
+	}{
+		{"header without colon", "Content-Length 8\r\n\r\n{\"id\":1}", "invalid message header"},
+		{"non-numeric length", "Content-Length: eight\r\n\r\n{\"id\":1}", "invalid Content-Length"},
+		{"negative length", "Content-Length: -1\r\n\r\n", "invalid Content-Length"},
+		{"overflowing length", "Content-Length: 99999999999999999999999\r\n\r\n", "invalid Content-Length"},
+		{"missing length", "Content-Type: application/json\r\n\r\n{}", "without Content-Length"},
+		{"oversized header", "X-Padding: " + strings.Repeat("a", frameReaderBufferSize) + "\r\n\r\n", "longer than"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := newFrameReader(strings.NewReader(tt.input), FramingHeaders).read()
+			if err == nil || !strings.Contains(err.Error(), tt.error) {
+				t.Errorf("Expected an error containing %q, got: %v", tt.error, err)
+			}
+		})
+	}
+}
+
+func TestFrameReader_UnexpectedEOF(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"in the headers", "Content-Length: 8\r\n"},
+		{"in a header line", "Content-Len"},
+		{"in the body", "Content-Length: 8\r\n\r\n{\"id\""},
+		// A large announced length is not allocated before the body
+		// arrives
+		{"in a large body", "Content-Length: 1099511627776\r\n\r\n{}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := newFrameReader(strings.NewReader(tt.input), FramingHeaders).read()
+			if !errors.Is(err, io.ErrUnexpectedEOF) {
+				t.Errorf("Expected io.ErrUnexpectedEOF, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestStdioTransport_AnswersInClientFraming(t *testing.T) {
+	var out bytes.Buffer
+	transport := NewStdioTransport(strings.NewReader("Content-Length: 8\r\n\r\n{\"id\":1}"), &out, FramingAuto)
+	if _, err := transport.Read(); err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if err := transport.Write([]byte(`{"id":1}`)); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if out.String() != "Content-Length: 8\r\n\r\n{\"id\":1}" {
+		t.Errorf("Expected a Content-Length framed answer, got %q", out.String())
+	}
+
+	out.Reset()
+	transport = NewStdioTransport(strings.NewReader("{\"id\":1}\n"), &out, FramingAuto)
+	if _, err := transport.Read(); err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if err := transport.Write([]byte(`{"id":1}`)); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if out.String() != "{\"id\":1}\n" {
+		t.Errorf("Expected a newline framed answer, got %q", out.String())
+	}
+}
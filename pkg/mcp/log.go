@@ -0,0 +1,70 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// LogLevels are the levels of log messages, from the least to the most
+// severe, as in syslog
+var LogLevels = []string{"debug", "info", "notice", "warning", "error", "critical", "alert", "emergency"}
+
+// DefaultLogLevel is the least severe level sent to clients that did not
+// set one with logging/setLevel
+const DefaultLogLevel = "info"
+
+// logSeverity ranks a log level, -1 when it is unknown
+func logSeverity(level string) int {
+	for i, l := range LogLevels {
+		if l == level {
+			return i
+		}
+	}
+	return -1
+}
+
+// Log sends a log message notification to the client of the session ctx
+// belongs to, when level is at least the level the client set. Data is any
+// JSON-serializable value, usually an object. Messages are dropped outside
+// sessions.
+func (s *Server) Log(ctx context.Context, level, logger string, data interface{}) {
+	session := SessionFromContext(ctx)
+	if session == nil {
+		return
+	}
+	session.mu.Lock()
+	minimum := session.logLevel
+	session.mu.Unlock()
+	if logSeverity(level) < logSeverity(minimum) {
+		return
+	}
+	session.notify(NotificationMessage, LogMessageNotification{Level: level, Logger: logger, Data: data})
+}
+
+// handleSetLevel handles the logging/setLevel request
+func (s *Server) handleSetLevel(ctx context.Context, request JSONRPCRequest) (*JSONRPCResponse, error) {
+	var setLevel SetLevelRequest
+	if err := json.Unmarshal(request.Params, &setLevel); err != nil || logSeverity(setLevel.Level) < 0 {
+		return &JSONRPCResponse{
+			JSONRPC: JSONRPCVersion,
+			ID:      request.ID,
+			Error: &RPCError{
+				Code:    -32602,
+				Message: fmt.Sprintf("Invalid params: level must be one of %s", strings.Join(LogLevels, ", ")),
+			},
+		}, nil
+	}
+
+	session := SessionFromContext(ctx)
+	session.mu.Lock()
+	session.logLevel = setLevel.Level
+	session.mu.Unlock()
+
+	return &JSONRPCResponse{
+		JSONRPC: JSONRPCVersion,
+		ID:      request.ID,
+		Result:  struct{}{},
+	}, nil
+}
@@ -0,0 +1,70 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+)
+
+// Middleware wraps the handler of a tool call, to layer concerns shared by
+// all tools such as logging, timing, authorization or rate limiting around
+// the handlers without modifying them
+type Middleware func(next ToolHandler) ToolHandler
+
+// Use adds middleware to the server. The middleware wraps every tool call,
+// including calls to tools registered later, the first middleware added
+// being the outermost one. Calls reach the middleware once their arguments
+// were validated against the schema of the tool.
+func (s *Server) Use(middleware ...Middleware) {
+	s.middleware = append(s.middleware, middleware...)
+}
+
+// Chain combines middleware into one, the first being the outermost
+func Chain(middleware ...Middleware) Middleware {
+	return func(next ToolHandler) ToolHandler {
+		for i := len(middleware) - 1; i >= 0; i-- {
+			next = middleware[i](next)
+		}
+		return next
+	}
+}
+
+// toolNameKey holds the name of the called tool in the context of a call
+type toolNameKey struct{}
+
+// ToolName returns the name of the tool a call is for, letting middleware
+// tell the tools apart
+func ToolName(ctx context.Context) string {
+	name, _ := ctx.Value(toolNameKey{}).(string)
+	return name
+}
+
+// traceParentKey holds the W3C traceparent the client gave for a call in
+// its context
+type traceParentKey struct{}
+
+// TraceParent returns the W3C traceparent the client gave in the metadata
+// of a call, empty when it gave none
+func TraceParent(ctx context.Context) string {
+	traceParent, _ := ctx.Value(traceParentKey{}).(string)
+	return traceParent
+}
+
+// ErrorCodeInternal is the ToolError code of calls whose handler panicked
+const ErrorCodeInternal = "internal_error"
+
+// RecoverPanics turns a panic of a handler, or of the middleware around it,
+// into a tool error so that the server keeps serving. The server applies it
+// to every call, handlers running calls on other goroutines apply it there.
+func RecoverPanics(next ToolHandler) ToolHandler {
+	return func(ctx context.Context, arguments map[string]interface{}) (content []TextContent, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				slog.Error("Tool panicked", "tool", ToolName(ctx), "panic", r, "stack", string(debug.Stack()))
+				content, err = nil, &ToolError{Code: ErrorCodeInternal, Err: fmt.Errorf("internal error: %v", r)}
+			}
+		}()
+		return next(ctx, arguments)
+	}
+}
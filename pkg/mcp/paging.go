@@ -0,0 +1,47 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+)
+
+// DefaultToolsPageSize is the number of tools listed per page of tools/list
+const DefaultToolsPageSize = 100
+
+// SetToolsPageSize sets the number of tools listed per page of tools/list,
+// 0 listing them all at once
+func (s *Server) SetToolsPageSize(size int) {
+	s.pageSize = size
+}
+
+// listTools returns the page of the tools the session sees following
+// cursor. Tools are listed by name, and cursors hold the last name of their
+// page, so that they stay valid when tools are registered between pages.
+func (s *Server) listTools(session *Session, cursor string) (ListToolsResponse, error) {
+	s.toolsMu.RLock()
+	tools := make([]Tool, 0, len(s.tools))
+	for _, tool := range s.tools {
+		if s.toolVisible(session, tool.Name) {
+			tools = append(tools, tool)
+		}
+	}
+	s.toolsMu.RUnlock()
+	sort.Slice(tools, func(i, j int) bool { return tools[i].Name < tools[j].Name })
+
+	start := 0
+	if cursor != "" {
+		after, err := base64.RawURLEncoding.DecodeString(cursor)
+		if err != nil || len(after) == 0 {
+			return ListToolsResponse{}, fmt.Errorf("invalid cursor %q", cursor)
+		}
+		start = sort.Search(len(tools), func(i int) bool { return tools[i].Name > string(after) })
+	}
+
+	response := ListToolsResponse{Tools: tools[start:]}
+	if s.pageSize > 0 && len(response.Tools) > s.pageSize {
+		response.Tools = response.Tools[:s.pageSize]
+		response.NextCursor = base64.RawURLEncoding.EncodeToString([]byte(response.Tools[s.pageSize-1].Name))
+	}
+	return response, nil
+}
@@ -0,0 +1,63 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// PartialResultsCapability is the experimental client capability of
+// clients accepting parts of tool results in progress notifications. Their
+// calls giving a progress token may get the result in several notifications
+// before a response holding its last part.
+const PartialResultsCapability = "partialResults"
+
+// progressTokenKey holds the progress token of a tool call in its context
+type progressTokenKey struct{}
+
+// ProgressToken returns the progress token the client gave for the tool
+// call ctx belongs to, nil when it gave none
+func ProgressToken(ctx context.Context) interface{} {
+	return ctx.Value(progressTokenKey{})
+}
+
+// ReportProgress notifies the client of the progress of the tool call ctx
+// belongs to, if it asked for it with a progress token. Total is zero when
+// unknown.
+func ReportProgress(ctx context.Context, progress, total float64, message string) {
+	token := ProgressToken(ctx)
+	session := SessionFromContext(ctx)
+	if token == nil || session == nil {
+		return
+	}
+	session.notify(NotificationProgress, ProgressNotification{ProgressToken: token, Progress: progress, Total: total, Message: message})
+}
+
+// SendPartialResult sends a part of the result of the tool call ctx belongs
+// to along a progress notification, for clients that gave a progress token
+// and support partial results. It reports whether the part was sent, the
+// caller returning it with the result otherwise.
+func SendPartialResult(ctx context.Context, content []TextContent, progress, total float64, message string) bool {
+	token := ProgressToken(ctx)
+	session := SessionFromContext(ctx)
+	if token == nil || session == nil || !session.supportsPartialResults() {
+		return false
+	}
+	encoded, err := json.Marshal(JSONRPCNotification{
+		JSONRPC: JSONRPCVersion,
+		Method:  NotificationProgress,
+		Params:  ProgressNotification{ProgressToken: token, Progress: progress, Total: total, Message: message, Content: content},
+	})
+	if err != nil {
+		return false
+	}
+	return session.write(encoded) == nil
+}
+
+// supportsPartialResults reports whether the client declared the partial
+// results capability
+func (session *Session) supportsPartialResults() bool {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	_, ok := session.client.Capabilities.Experimental[PartialResultsCapability]
+	return ok
+}
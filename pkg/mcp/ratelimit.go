@@ -0,0 +1,243 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrorCodeRateLimited is the ToolError code of calls refused by the rate
+// limits of their session
+const ErrorCodeRateLimited = "rate_limited"
+
+// RateLimits bound the tool calls of every session, so that a client stuck
+// in a loop cannot take over a shared machine. Calls over a limit fail
+// right away with ErrorCodeRateLimited, telling when to retry, instead of
+// waiting.
+type RateLimits struct {
+	// CallsPerMinute bounds the calls started in any minute (0: unlimited)
+	CallsPerMinute int
+	// ConcurrentCalls bounds the calls in flight at once (0: unlimited)
+	ConcurrentCalls int
+	// Quotas bound the calls of some tools in any hour
+	Quotas []Quota
+}
+
+// Quota bounds the calls it matches in any hour, e.g. pushes. Name
+// describes the calls counted in errors. Match sees the arguments of the
+// calls too, so that calls only previewing an action, like dry runs, are
+// left out.
+type Quota struct {
+	Name         string
+	CallsPerHour int
+	Match        func(tool string, arguments map[string]interface{}) bool
+}
+
+// SetRateLimits enforces rate limits on the tool calls of every session.
+// Calls over them still go through the middleware added before.
+func (s *Server) SetRateLimits(limits RateLimits) {
+	limiter := &rateLimiter{limits: limits}
+	s.Use(limiter.middleware)
+}
+
+// rateLimiter enforces RateLimits, keeping the calls of each session in
+// the session
+type rateLimiter struct {
+	limits RateLimits
+	// mu guards the state of all sessions, checks being quick
+	mu sync.Mutex
+}
+
+// rateLimiterKey holds the sessionCalls of a session
+type rateLimiterKey struct{ limiter *rateLimiter }
+
+// sessionCalls are the recent calls of a session
+type sessionCalls struct {
+	started  []time.Time
+	inFlight int
+	// quotas holds the start times of the calls counted by each quota
+	quotas [][]time.Time
+}
+
+func (l *rateLimiter) middleware(next ToolHandler) ToolHandler {
+	return func(ctx context.Context, arguments map[string]interface{}) ([]TextContent, error) {
+		session := SessionFromContext(ctx)
+		if session == nil {
+			return next(ctx, arguments)
+		}
+		release, err := l.acquire(session, ToolName(ctx), arguments, time.Now())
+		if err != nil {
+			return nil, &ToolError{Code: ErrorCodeRateLimited, Err: err}
+		}
+		hold := &inFlightHold{count: 1, release: release}
+		hold.parent, _ = ctx.Value(inFlightKey{}).(*inFlightHold)
+		defer hold.done()
+		ctx = context.WithValue(ctx, quotaCounterKey{}, quotaCounter{l, session})
+		return next(context.WithValue(ctx, inFlightKey{}, hold), arguments)
+	}
+}
+
+// inFlightKey holds the inFlightHold of a call
+type inFlightKey struct{}
+
+// inFlightHold releases the slot of a call in flight once its handler and
+// the work it left running in the background are done
+type inFlightHold struct {
+	mu      sync.Mutex
+	count   int
+	release func()
+	// parent is the hold of an outer rate limiter, if any
+	parent *inFlightHold
+}
+
+func (h *inFlightHold) add() {
+	h.mu.Lock()
+	h.count++
+	h.mu.Unlock()
+}
+
+func (h *inFlightHold) done() {
+	h.mu.Lock()
+	h.count--
+	last := h.count == 0
+	h.mu.Unlock()
+	if last {
+		h.release()
+	}
+}
+
+// KeepInFlight keeps a tool call counted as in flight by the rate limits
+// after its handler returned, until the returned function is called. It is
+// meant for handlers giving up on work that keeps running, e.g. past a
+// timeout, so that the session cannot start more calls than allowed.
+func KeepInFlight(ctx context.Context) (release func()) {
+	var holds []*inFlightHold
+	for hold, _ := ctx.Value(inFlightKey{}).(*inFlightHold); hold != nil; hold = hold.parent {
+		hold.add()
+		holds = append(holds, hold)
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			for _, hold := range holds {
+				hold.done()
+			}
+		})
+	}
+}
+
+// acquire counts a call of a session starting at now, failing when it is
+// over a limit. The call is in flight until release is called.
+func (l *rateLimiter) acquire(session *Session, tool string, arguments map[string]interface{}, now time.Time) (release func(), err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	key := rateLimiterKey{l}
+	calls, _ := session.Value(key).(*sessionCalls)
+	if calls == nil {
+		calls = &sessionCalls{quotas: make([][]time.Time, len(l.limits.Quotas))}
+		session.SetValue(key, calls)
+	}
+
+	if max := l.limits.ConcurrentCalls; max > 0 && calls.inFlight >= max {
+		return nil, fmt.Errorf("rate limit exceeded: %d tool calls at once; wait for the calls in flight to finish", max)
+	}
+	if max := l.limits.CallsPerMinute; max > 0 {
+		calls.started = since(calls.started, now.Add(-time.Minute))
+		if len(calls.started) >= max {
+			return nil, fmt.Errorf("rate limit exceeded: %d tool calls per minute; retry in %s", max, retryIn(calls.started[0].Add(time.Minute), now))
+		}
+	}
+	var counted []int
+	for i, quota := range l.limits.Quotas {
+		if quota.CallsPerHour <= 0 || quota.Match == nil || !quota.Match(tool, arguments) {
+			continue
+		}
+		calls.quotas[i] = since(calls.quotas[i], now.Add(-time.Hour))
+		if len(calls.quotas[i]) >= quota.CallsPerHour {
+			return nil, fmt.Errorf("rate limit exceeded: quota of %s reached, %d per hour; retry in %s", quota.Name, quota.CallsPerHour, retryIn(calls.quotas[i][0].Add(time.Hour), now))
+		}
+		counted = append(counted, i)
+	}
+
+	// The call is only counted once it passed every limit
+	if l.limits.CallsPerMinute > 0 {
+		calls.started = append(calls.started, now)
+	}
+	for _, i := range counted {
+		calls.quotas[i] = append(calls.quotas[i], now)
+	}
+	calls.inFlight++
+	return func() {
+		l.mu.Lock()
+		calls.inFlight--
+		l.mu.Unlock()
+	}, nil
+}
+
+// quotaCounterKey holds the quotaCounter of a call
+type quotaCounterKey struct{}
+
+// quotaCounter counts the actions of a call against the quotas of its
+// session
+type quotaCounter struct {
+	limiter *rateLimiter
+	session *Session
+}
+
+// CountQuota counts an action a call performs on top of the one its tool
+// and arguments are matched for, e.g. a clone made on the way, against the
+// quota named name of the session. It fails with ErrorCodeRateLimited when
+// the quota is reached, and does nothing without such a quota.
+func CountQuota(ctx context.Context, name string) error {
+	counter, ok := ctx.Value(quotaCounterKey{}).(quotaCounter)
+	if !ok {
+		return nil
+	}
+	if err := counter.limiter.count(counter.session, name, time.Now()); err != nil {
+		return &ToolError{Code: ErrorCodeRateLimited, Err: err}
+	}
+	return nil
+}
+
+// count counts an action of a session starting at now against the quota
+// named name, failing when it is reached
+func (l *rateLimiter) count(session *Session, name string, now time.Time) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	calls, _ := session.Value(rateLimiterKey{l}).(*sessionCalls)
+	if calls == nil {
+		return nil
+	}
+	for i, quota := range l.limits.Quotas {
+		if quota.Name != name || quota.CallsPerHour <= 0 {
+			continue
+		}
+		calls.quotas[i] = since(calls.quotas[i], now.Add(-time.Hour))
+		if len(calls.quotas[i]) >= quota.CallsPerHour {
+			return fmt.Errorf("rate limit exceeded: quota of %s reached, %d per hour; retry in %s", quota.Name, quota.CallsPerHour, retryIn(calls.quotas[i][0].Add(time.Hour), now))
+		}
+		calls.quotas[i] = append(calls.quotas[i], now)
+	}
+	return nil
+}
+
+// since drops the times before start, times being in order
+func since(times []time.Time, start time.Time) []time.Time {
+	i := 0
+	for i < len(times) && !times[i].After(start) {
+		i++
+	}
+	return times[i:]
+}
+
+// retryIn renders the wait until a call is allowed again, in whole seconds
+func retryIn(allowed, now time.Time) time.Duration {
+	wait := allowed.Sub(now).Round(time.Second)
+	if wait < time.Second {
+		wait = time.Second
+	}
+	return wait
+}
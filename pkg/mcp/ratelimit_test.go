@@ -0,0 +1,164 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// testSession returns a session with no transport, for handlers that only
+// use its values
+func testSession(s *Server) *Session {
+	return s.newSession(nil)
+}
+
+func TestKeepInFlight(t *testing.T) {
+	server := NewServer("test", "1")
+	limiter := &rateLimiter{limits: RateLimits{ConcurrentCalls: 1}}
+	session := testSession(server)
+	ctx := context.WithValue(context.Background(), sessionKey{}, session)
+
+	var release func()
+	handler := limiter.middleware(func(ctx context.Context, arguments map[string]interface{}) ([]TextContent, error) {
+		release = KeepInFlight(ctx)
+		return nil, nil
+	})
+	if _, err := handler(ctx, nil); err != nil {
+		t.Fatalf("First call failed: %v", err)
+	}
+
+	// The first call is still in flight after returning
+	if _, err := handler(ctx, nil); err == nil {
+		t.Fatalf("Expected a call to be refused while another one is kept in flight")
+	}
+
+	release()
+	release()
+	if calls := session.Value(rateLimiterKey{limiter}).(*sessionCalls); calls.inFlight != 0 {
+		t.Errorf("Expected no call in flight once released, got %d", calls.inFlight)
+	}
+	if _, err := handler(ctx, nil); err != nil {
+		t.Errorf("Expected the slot to be free once released, got: %v", err)
+	}
+}
+
+func TestKeepInFlight_WithoutLimits(t *testing.T) {
+	// Outside rate limited calls there is nothing to keep
+	KeepInFlight(context.Background())()
+}
+
+func TestRateLimiter_Acquire(t *testing.T) {
+	pushes := Quota{Name: "pushes", CallsPerHour: 2, Match: func(tool string, arguments map[string]interface{}) bool {
+		dryRun, _ := arguments["dry_run"].(bool)
+		return tool == "git_push" && !dryRun
+	}}
+	start := time.Now()
+
+	type call struct {
+		session int
+		tool    string
+		dryRun  bool
+		at      time.Duration
+		// release ends the call right away, keeping it in flight
+		// otherwise
+		release bool
+		allowed bool
+	}
+	tests := []struct {
+		name   string
+		limits RateLimits
+		calls  []call
+	}{
+		{
+			name:   "calls per minute",
+			limits: RateLimits{CallsPerMinute: 2},
+			calls: []call{
+				{0, "git_status", false, 0, true, true},
+				{0, "git_status", false, time.Second, true, true},
+				{0, "git_status", false, 2 * time.Second, true, false},
+				// Refused calls are not counted, the window slides
+				{0, "git_status", false, time.Minute + time.Millisecond, true, true},
+				{0, "git_status", false, time.Minute + 2*time.Millisecond, true, false},
+			},
+		},
+		{
+			name:   "concurrent calls",
+			limits: RateLimits{ConcurrentCalls: 1},
+			calls: []call{
+				{0, "git_status", false, 0, false, true},
+				{0, "git_status", false, time.Second, true, false},
+				{1, "git_status", false, time.Second, true, true},
+			},
+		},
+		{
+			name:   "quota",
+			limits: RateLimits{Quotas: []Quota{pushes}},
+			calls: []call{
+				{0, "git_push", false, 0, true, true},
+				{0, "git_push", true, time.Minute, true, true},
+				{0, "git_status", false, time.Minute, true, true},
+				{0, "git_push", false, 2 * time.Minute, true, true},
+				{0, "git_push", false, 3 * time.Minute, true, false},
+				{0, "git_push", true, 3 * time.Minute, true, true},
+				{1, "git_push", false, 3 * time.Minute, true, true},
+				{0, "git_push", false, time.Hour + time.Second, true, true},
+			},
+		},
+		{
+			name:   "per session",
+			limits: RateLimits{CallsPerMinute: 1},
+			calls: []call{
+				{0, "git_status", false, 0, true, true},
+				{1, "git_status", false, 0, true, true},
+				{0, "git_status", false, time.Second, true, false},
+				{1, "git_status", false, time.Second, true, false},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewServer("test", "1")
+			limiter := &rateLimiter{limits: tt.limits}
+			sessions := []*Session{testSession(server), testSession(server)}
+			for i, c := range tt.calls {
+				release, err := limiter.acquire(sessions[c.session], c.tool, map[string]interface{}{"dry_run": c.dryRun}, start.Add(c.at))
+				if (err == nil) != c.allowed {
+					t.Fatalf("Call %d: expected allowed %v, got error %v", i, c.allowed, err)
+				}
+				if err == nil && c.release {
+					release()
+				}
+			}
+		})
+	}
+}
+
+func TestCountQuota(t *testing.T) {
+	server := NewServer("test", "1")
+	clones := Quota{Name: "clones", CallsPerHour: 2, Match: func(tool string, arguments map[string]interface{}) bool {
+		return tool == "git_clone"
+	}}
+	limiter := &rateLimiter{limits: RateLimits{Quotas: []Quota{clones}}}
+	ctx := context.WithValue(context.Background(), sessionKey{}, testSession(server))
+
+	// A call of another tool cloning on the way uses up the quota, which
+	// then refuses the clones it matches
+	handler := limiter.middleware(func(ctx context.Context, arguments map[string]interface{}) ([]TextContent, error) {
+		return nil, CountQuota(ctx, "clones")
+	})
+	for i := 0; i < 2; i++ {
+		if _, err := handler(ctx, nil); err != nil {
+			t.Fatalf("Clone %d failed: %v", i+1, err)
+		}
+	}
+	if _, err := handler(ctx, nil); err == nil {
+		t.Errorf("Expected a clone over the quota to be refused")
+	}
+	if _, err := limiter.acquire(SessionFromContext(ctx), "git_clone", nil, time.Now()); err == nil {
+		t.Errorf("Expected git_clone to be refused once the quota is used up")
+	}
+
+	if err := CountQuota(context.Background(), "clones"); err != nil {
+		t.Errorf("Expected no quota outside rate limited calls, got: %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// ResourceLister lists the resources of a handler
+type ResourceLister func(ctx context.Context) ([]Resource, error)
+
+// ResourceReader reads a resource. Readers not knowing the URI return
+// handled false, leaving the request to the next reader.
+type ResourceReader func(ctx context.Context, uri string) (contents []ResourceContents, handled bool, err error)
+
+// HandleResources adds a lister and a reader of resources and declares the
+// resources capability. Resources are listed in the order their handlers
+// were added, and readers tried in that order.
+func (s *Server) HandleResources(list ResourceLister, read ResourceReader) {
+	s.listers = append(s.listers, list)
+	s.readers = append(s.readers, read)
+	s.capabilities.Resources = &ResourcesCapability{ListChanged: true}
+}
+
+// NotifyResourcesChanged tells the clients that the list of resources
+// changed
+func (s *Server) NotifyResourcesChanged() {
+	s.broadcast(NotificationResourcesListChanged, nil)
+}
+
+// handleListResources handles the resources/list request
+func (s *Server) handleListResources(ctx context.Context, request JSONRPCRequest) (*JSONRPCResponse, error) {
+	if !SessionFromContext(ctx).Info().Initialized {
+		return &JSONRPCResponse{
+			JSONRPC: JSONRPCVersion,
+			ID:      request.ID,
+			Error: &RPCError{
+				Code:    -32002,
+				Message: "Server not initialized",
+			},
+		}, nil
+	}
+
+	resources := []Resource{}
+	for _, list := range s.listers {
+		listed, err := list(ctx)
+		if err != nil {
+			return &JSONRPCResponse{
+				JSONRPC: JSONRPCVersion,
+				ID:      request.ID,
+				Error: &RPCError{
+					Code:    -32603,
+					Message: fmt.Sprintf("Failed to list resources: %v", err),
+				},
+			}, nil
+		}
+		resources = append(resources, listed...)
+	}
+
+	return &JSONRPCResponse{
+		JSONRPC: JSONRPCVersion,
+		ID:      request.ID,
+		Result:  ListResourcesResult{Resources: resources},
+	}, nil
+}
+
+// handleReadResource handles the resources/read request
+func (s *Server) handleReadResource(ctx context.Context, request JSONRPCRequest) (*JSONRPCResponse, error) {
+	var readReq ReadResourceRequest
+	if err := json.Unmarshal(request.Params, &readReq); err != nil || readReq.URI == "" {
+		return &JSONRPCResponse{
+			JSONRPC: JSONRPCVersion,
+			ID:      request.ID,
+			Error: &RPCError{
+				Code:    -32602,
+				Message: "Invalid params: uri is required",
+			},
+		}, nil
+	}
+
+	for _, read := range s.readers {
+		contents, handled, err := read(ctx, readReq.URI)
+		if !handled {
+			continue
+		}
+		if err != nil {
+			return &JSONRPCResponse{
+				JSONRPC: JSONRPCVersion,
+				ID:      request.ID,
+				Error: &RPCError{
+					Code:    -32603,
+					Message: fmt.Sprintf("Failed to read %s: %v", readReq.URI, err),
+				},
+			}, nil
+		}
+		return &JSONRPCResponse{
+			JSONRPC: JSONRPCVersion,
+			ID:      request.ID,
+			Result:  ReadResourceResult{Contents: contents},
+		}, nil
+	}
+
+	// The MCP specification answers unknown resources with -32002
+	return &JSONRPCResponse{
+		JSONRPC: JSONRPCVersion,
+		ID:      request.ID,
+		Error: &RPCError{
+			Code:    -32002,
+			Message: "Resource not found",
+			Data:    map[string]string{"uri": readReq.URI},
+		},
+	}, nil
+}
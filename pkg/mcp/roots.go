@@ -0,0 +1,54 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrRootsNotSupported is returned by Roots for clients without the roots
+// capability
+var ErrRootsNotSupported = errors.New("client does not support roots")
+
+// rootsTimeout bounds the wait for the client to list its roots
+const rootsTimeout = 10 * time.Second
+
+// Roots returns the roots of the client of the session ctx belongs to, the
+// directories its workspace is made of. They are listed once and cached
+// until the client notifies that they changed.
+func (s *Server) Roots(ctx context.Context) ([]Root, error) {
+	session := SessionFromContext(ctx)
+	if session == nil {
+		return nil, ErrNotServing
+	}
+	session.mu.Lock()
+	supported := session.client.Capabilities.Roots != nil
+	roots, cached := session.roots, session.rootsSet
+	session.mu.Unlock()
+	if !supported {
+		return nil, ErrRootsNotSupported
+	}
+	if cached {
+		return roots, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, rootsTimeout)
+	defer cancel()
+	var response ListRootsResponse
+	if err := session.request(ctx, MethodListRoots, nil, &response); err != nil {
+		return nil, err
+	}
+
+	session.mu.Lock()
+	session.roots, session.rootsSet = response.Roots, true
+	session.mu.Unlock()
+	return response.Roots, nil
+}
+
+// handleRootsChanged forgets the cached roots when the client notifies that
+// they changed
+func (session *Session) handleRootsChanged() {
+	session.mu.Lock()
+	session.roots, session.rootsSet = nil, false
+	session.mu.Unlock()
+}
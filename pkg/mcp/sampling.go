@@ -0,0 +1,35 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrSamplingNotSupported is returned by CreateMessage for clients without
+// the sampling capability
+var ErrSamplingNotSupported = errors.New("client does not support sampling")
+
+// CreateMessage asks the client of the session ctx belongs to to sample its model, which usually lets
+// the user review the prompt and the completion first
+func (s *Server) CreateMessage(ctx context.Context, request CreateMessageRequest) (*CreateMessageResult, error) {
+	session := SessionFromContext(ctx)
+	if session == nil {
+		return nil, ErrNotServing
+	}
+	session.mu.Lock()
+	supported := session.client.Capabilities.Sampling != nil
+	session.mu.Unlock()
+	if !supported {
+		return nil, ErrSamplingNotSupported
+	}
+
+	var result CreateMessageResult
+	if err := session.request(ctx, MethodSampling, request, &result); err != nil {
+		return nil, err
+	}
+	if result.Content.Type != "text" {
+		return nil, fmt.Errorf("expected text from the client's model, got %s content", result.Content.Type)
+	}
+	return &result, nil
+}
@@ -0,0 +1,557 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/pengcunfu/go-mcp-git/internal/logging"
+)
+
+// Server represents an MCP server
+type Server struct {
+	name         string
+	title        string
+	version      string
+	capabilities ServerCapabilities
+	middleware   []Middleware
+	drainTimeout time.Duration
+	pageSize     int
+	framing      Framing
+	completers   []CompletionHandler
+	listers      []ResourceLister
+	readers      []ResourceReader
+
+	// sessionsMu guards the sessions, one per transport connection
+	sessionsMu  sync.Mutex
+	sessions    map[string]*Session
+	lastSession int64
+
+	// toolsMu guards the tools, which may change while serving
+	toolsMu      sync.RWMutex
+	tools        []Tool
+	toolHandlers map[string]ToolHandler
+	toolSchemas  map[string]interface{}
+	disabled     map[string]bool
+	filters      []ToolFilter
+}
+
+// ToolHandler is a function that handles tool calls
+type ToolHandler func(ctx context.Context, arguments map[string]interface{}) ([]TextContent, error)
+
+// NewServer creates a new MCP server
+func NewServer(name, version string) *Server {
+	return &Server{
+		name:         name,
+		version:      version,
+		capabilities: ServerCapabilities{
+			Tools: &ToolsCapability{
+				ListChanged: true,
+			},
+			Logging: &LoggingCapability{},
+			Experimental: map[string]interface{}{
+				PartialResultsCapability: map[string]interface{}{},
+			},
+		},
+		tools:        make([]Tool, 0),
+		toolHandlers: make(map[string]ToolHandler),
+		toolSchemas:  make(map[string]interface{}),
+		disabled:     make(map[string]bool),
+		drainTimeout: DefaultDrainTimeout,
+		pageSize:     DefaultToolsPageSize,
+		framing:      FramingAuto,
+		sessions:     make(map[string]*Session),
+	}
+}
+
+// RegisterTool registers a tool with the server, replacing any tool of the
+// same name. Clients are notified of tools registered while serving.
+func (s *Server) RegisterTool(tool Tool, handler ToolHandler) {
+	s.toolsMu.Lock()
+	replaced := false
+	for i := range s.tools {
+		if s.tools[i].Name == tool.Name {
+			s.tools[i], replaced = tool, true
+		}
+	}
+	if !replaced {
+		s.tools = append(s.tools, tool)
+	}
+	s.toolHandlers[tool.Name] = handler
+	s.toolSchemas[tool.Name] = tool.InputSchema
+	s.toolsMu.Unlock()
+
+	s.toolsChanged()
+}
+
+// DefaultDrainTimeout is how long Serve waits for the request in flight
+// when it is asked to stop
+const DefaultDrainTimeout = 30 * time.Second
+
+// SetDrainTimeout sets how long Serve waits for the request in flight when
+// its context is done, before cancelling it
+func (s *Server) SetDrainTimeout(timeout time.Duration) {
+	s.drainTimeout = timeout
+}
+
+// Serve starts the MCP server using stdio. When ctx is done it stops reading
+// requests, lets the request in flight finish within the drain timeout,
+// writes its response and returns nil. Past the drain timeout the context
+// of the request is cancelled.
+func (s *Server) Serve(ctx context.Context) error {
+	return s.ServeTransport(ctx, NewStdioTransport(os.Stdin, os.Stdout, s.framing))
+}
+
+// ServeTransport serves one client over a transport, like Serve, until the
+// client goes away or ctx is done. Each transport gets a session of its
+// own, which the client has to initialize, so that several transports may
+// be served at once.
+func (s *Server) ServeTransport(ctx context.Context, transport Transport) error {
+	session := s.newSession(transport)
+	defer s.endSession(session)
+
+	// Messages are read on their own goroutine so that a shutdown does
+	// not wait for the next line, and so that the responses to requests
+	// sent to the client arrive while a request is in flight
+	reads := make(chan message)
+	stopped := make(chan struct{})
+	defer close(stopped)
+	go func() {
+		for {
+			line, err := transport.Read()
+			select {
+			case reads <- message{line, err}:
+			case <-stopped:
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	// Requests outlive the serving context, they are only cancelled once
+	// the drain timeout expires
+	requestCtx, cancelRequests := context.WithCancel(context.WithValue(context.WithoutCancel(ctx), sessionKey{}, session))
+	defer cancelRequests()
+
+	// Requests are handled one at a time, those arriving meanwhile wait
+	// in queue
+	var queue [][]byte
+	var readErr error
+	receive := func(m message) {
+		if m.err != nil {
+			readErr = m.err
+			return
+		}
+		slog.Log(ctx, logging.LevelTrace, "Received message", "session", session.id, "message", string(bytes.TrimSpace(m.line)))
+		if rest := session.takeResponses(m.line); rest != nil {
+			queue = append(queue, rest)
+		}
+	}
+
+	for {
+		if len(queue) == 0 {
+			if readErr != nil {
+				if readErr == io.EOF {
+					return nil
+				}
+				return fmt.Errorf("failed to read request: %w", readErr)
+			}
+			select {
+			case <-ctx.Done():
+				return nil
+			case m := <-reads:
+				receive(m)
+			}
+			continue
+		}
+		line := queue[0]
+		queue = queue[1:]
+
+		// Process request
+		done := make(chan handledRequest, 1)
+		go func() {
+			done <- s.handleMessage(requestCtx, line)
+		}()
+
+		var h handledRequest
+		stopping := false
+	wait:
+		for {
+			// The input is no longer read once it ended
+			input := reads
+			if readErr != nil {
+				input = nil
+			}
+			select {
+			case h = <-done:
+				break wait
+			case m := <-input:
+				receive(m)
+			case <-ctx.Done():
+				stopping = true
+				h = s.drain(done, input, receive, cancelRequests)
+				break wait
+			}
+		}
+		session.writeResponses(h)
+		if stopping {
+			return nil
+		}
+	}
+}
+
+// message is a message read from the client
+type message struct {
+	line []byte
+	err  error
+}
+
+// handledRequest is the outcome of handling a message: the responses to
+// its requests, none for notifications, and whether it was a batch
+type handledRequest struct {
+	responses []*JSONRPCResponse
+	batch     bool
+}
+
+// drain waits for the request in flight during a shutdown, cancelling it
+// once the drain timeout expires. Messages still arrive meanwhile, so that
+// the request gets the responses of the client.
+func (s *Server) drain(done <-chan handledRequest, input <-chan message, receive func(message), cancel context.CancelFunc) handledRequest {
+	timer := time.NewTimer(s.drainTimeout)
+	defer timer.Stop()
+	for {
+		select {
+		case h := <-done:
+			return h
+		case m := <-input:
+			receive(m)
+			if m.err != nil {
+				input = nil
+			}
+		case <-timer.C:
+			slog.Warn("Request still running after the drain timeout, cancelling it", "drain_timeout", s.drainTimeout)
+			cancel()
+			return <-done
+		}
+	}
+}
+
+// writeResponses writes the responses to a message, if any. Batches are
+// answered with an array, unless they only held notifications.
+func (session *Session) writeResponses(h handledRequest) {
+	if len(h.responses) == 0 {
+		return
+	}
+
+	var response interface{} = h.responses[0]
+	if h.batch {
+		response = h.responses
+	}
+	responseBytes, err := json.Marshal(response)
+	if err != nil {
+		slog.Error("Failed to marshal response", "error", err)
+		return
+	}
+	session.write(responseBytes)
+}
+
+// handleRequest processes a single JSON-RPC request, answering with an
+// internal error instead of stopping the server when handling it panics
+func (s *Server) handleRequest(ctx context.Context, requestBytes []byte) (response *JSONRPCResponse, err error) {
+	var request JSONRPCRequest
+	notification := isNotification(requestBytes)
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("Request panicked", "method", request.Method, "panic", r, "stack", string(debug.Stack()))
+			if notification {
+				response, err = nil, nil
+				return
+			}
+			response, err = &JSONRPCResponse{
+				JSONRPC: JSONRPCVersion,
+				ID:      request.ID,
+				Error: &RPCError{
+					Code:    -32603,
+					Message: fmt.Sprintf("Internal error: %v", r),
+				},
+			}, nil
+		}
+	}()
+
+	if err := json.Unmarshal(requestBytes, &request); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			// Valid JSON that is not a request object
+			return &JSONRPCResponse{
+				JSONRPC: JSONRPCVersion,
+				Error: &RPCError{
+					Code:    -32600,
+					Message: "Invalid Request",
+				},
+			}, nil
+		}
+		return &JSONRPCResponse{
+			JSONRPC: JSONRPCVersion,
+			Error: &RPCError{
+				Code:    -32700,
+				Message: "Parse error",
+			},
+		}, nil
+	}
+
+	// Notifications are never answered, whatever their method
+	if notification {
+		s.handleNotification(ctx, request)
+		return nil, nil
+	}
+
+	switch request.Method {
+	case MethodInitialize:
+		return s.handleInitialize(ctx, request)
+	case MethodListTools:
+		return s.handleListTools(ctx, request)
+	case MethodCallTool:
+		return s.handleCallTool(ctx, request)
+	case MethodSetLevel:
+		return s.handleSetLevel(ctx, request)
+	case MethodComplete:
+		return s.handleComplete(ctx, request)
+	case MethodListResources:
+		return s.handleListResources(ctx, request)
+	case MethodReadResource:
+		return s.handleReadResource(ctx, request)
+	case MethodPing:
+		// Pings check liveness, before initialization too
+		return &JSONRPCResponse{
+			JSONRPC: JSONRPCVersion,
+			ID:      request.ID,
+			Result:  struct{}{},
+		}, nil
+	default:
+		return &JSONRPCResponse{
+			JSONRPC: JSONRPCVersion,
+			ID:      request.ID,
+			Error: &RPCError{
+				Code:    -32601,
+				Message: "Method not found",
+			},
+		}, nil
+	}
+}
+
+// handleInitialize handles the initialize request
+func (s *Server) handleInitialize(ctx context.Context, request JSONRPCRequest) (*JSONRPCResponse, error) {
+	var initReq InitializeRequest
+	if err := json.Unmarshal(request.Params, &initReq); err != nil {
+		return &JSONRPCResponse{
+			JSONRPC: JSONRPCVersion,
+			ID:      request.ID,
+			Error: &RPCError{
+				Code:    -32602,
+				Message: "Invalid params",
+			},
+		}, nil
+	}
+
+	version, ok := negotiateVersion(initReq.ProtocolVersion)
+	if !ok {
+		return &JSONRPCResponse{
+			JSONRPC: JSONRPCVersion,
+			ID:      request.ID,
+			Error: &RPCError{
+				Code:    -32602,
+				Message: "Unsupported protocol version",
+				Data: UnsupportedVersionData{
+					Supported: SupportedProtocolVersions,
+					Requested: initReq.ProtocolVersion,
+				},
+			},
+		}, nil
+	}
+
+	session := SessionFromContext(ctx)
+	session.mu.Lock()
+	session.initialized = true
+	session.client = initReq
+	session.protocolVersion = version
+	session.roots, session.rootsSet = nil, false
+	session.mu.Unlock()
+	slog.Debug("Initialized session", "session", session.id, "protocol_version", version, "requested", initReq.ProtocolVersion, "client", initReq.ClientInfo.Name)
+
+	response := InitializeResponse{
+		ProtocolVersion: version,
+		Capabilities:    s.capabilitiesFor(version),
+		ServerInfo:      s.serverInfoFor(version),
+	}
+
+	return &JSONRPCResponse{
+		JSONRPC: JSONRPCVersion,
+		ID:      request.ID,
+		Result:  response,
+	}, nil
+}
+
+// handleListTools handles the list_tools request
+func (s *Server) handleListTools(ctx context.Context, request JSONRPCRequest) (*JSONRPCResponse, error) {
+	if !SessionFromContext(ctx).Info().Initialized {
+		return &JSONRPCResponse{
+			JSONRPC: JSONRPCVersion,
+			ID:      request.ID,
+			Error: &RPCError{
+				Code:    -32002,
+				Message: "Server not initialized",
+			},
+		}, nil
+	}
+
+	var listReq ListToolsRequest
+	if len(request.Params) > 0 {
+		if err := json.Unmarshal(request.Params, &listReq); err != nil {
+			return &JSONRPCResponse{
+				JSONRPC: JSONRPCVersion,
+				ID:      request.ID,
+				Error: &RPCError{
+					Code:    -32602,
+					Message: "Invalid params",
+				},
+			}, nil
+		}
+	}
+
+	response, err := s.listTools(SessionFromContext(ctx), listReq.Cursor)
+	if err != nil {
+		return &JSONRPCResponse{
+			JSONRPC: JSONRPCVersion,
+			ID:      request.ID,
+			Error: &RPCError{
+				Code:    -32602,
+				Message: fmt.Sprintf("Invalid params: %v", err),
+			},
+		}, nil
+	}
+
+	return &JSONRPCResponse{
+		JSONRPC: JSONRPCVersion,
+		ID:      request.ID,
+		Result:  response,
+	}, nil
+}
+
+// handleCallTool handles the call_tool request
+func (s *Server) handleCallTool(ctx context.Context, request JSONRPCRequest) (*JSONRPCResponse, error) {
+	if !SessionFromContext(ctx).Info().Initialized {
+		return &JSONRPCResponse{
+			JSONRPC: JSONRPCVersion,
+			ID:      request.ID,
+			Error: &RPCError{
+				Code:    -32002,
+				Message: "Server not initialized",
+			},
+		}, nil
+	}
+
+	var callReq CallToolRequest
+	if err := json.Unmarshal(request.Params, &callReq); err != nil {
+		return &JSONRPCResponse{
+			JSONRPC: JSONRPCVersion,
+			ID:      request.ID,
+			Error: &RPCError{
+				Code:    -32602,
+				Message: "Invalid params",
+			},
+		}, nil
+	}
+
+	handler, schema, exists := s.lookupTool(SessionFromContext(ctx), callReq.Name)
+	if !exists {
+		return &JSONRPCResponse{
+			JSONRPC: JSONRPCVersion,
+			ID:      request.ID,
+			Error: &RPCError{
+				Code:    -32601,
+				Message: fmt.Sprintf("Unknown tool: %s", callReq.Name),
+			},
+		}, nil
+	}
+
+	// Arguments not matching the schema never reach the handler, which
+	// would see missing arguments as empty values
+	if err := ValidateArguments(schema, callReq.Arguments); err != nil {
+		rpcErr := &RPCError{
+			Code:    -32602,
+			Message: fmt.Sprintf("Invalid params: %v", err),
+		}
+		var argErr *ArgumentError
+		if errors.As(err, &argErr) {
+			rpcErr.Data = map[string]interface{}{"field": argErr.Field, "tool": callReq.Name}
+		}
+		return &JSONRPCResponse{
+			JSONRPC: JSONRPCVersion,
+			ID:      request.ID,
+			Error:   rpcErr,
+		}, nil
+	}
+
+	ctx = context.WithValue(ctx, toolNameKey{}, callReq.Name)
+	if callReq.Meta != nil && callReq.Meta.ProgressToken != nil {
+		ctx = context.WithValue(ctx, progressTokenKey{}, callReq.Meta.ProgressToken)
+	}
+	if callReq.Meta != nil && callReq.Meta.TraceParent != "" {
+		ctx = context.WithValue(ctx, traceParentKey{}, callReq.Meta.TraceParent)
+	}
+	content, err := Chain(append([]Middleware{RecoverPanics}, s.middleware...)...)(handler)(ctx, callReq.Arguments)
+	if err != nil {
+		// Tool failures are results the model can see and react to, only
+		// protocol failures are JSON-RPC errors. Handlers classify their
+		// failures with a ToolError.
+		data := ToolErrorData{Code: "unknown", Tool: callReq.Name}
+		var toolErr *ToolError
+		if errors.As(err, &toolErr) && toolErr.Code != "" {
+			data.Code = toolErr.Code
+		}
+		return &JSONRPCResponse{
+			JSONRPC: JSONRPCVersion,
+			ID:      request.ID,
+			Result: CallToolResponse{
+				Content: []TextContent{{
+					Type: "text",
+					Text: fmt.Sprintf("Error [%s]: %v", data.Code, err),
+				}},
+				IsError: true,
+				Meta:    map[string]interface{}{"error": data},
+			},
+		}, nil
+	}
+
+	response := CallToolResponse{
+		Content: content,
+	}
+
+	return &JSONRPCResponse{
+		JSONRPC: JSONRPCVersion,
+		ID:      request.ID,
+		Result:  response,
+	}, nil
+}
+
+// handleNotification handles a notification of the client, ignoring
+// those it does not know
+func (s *Server) handleNotification(ctx context.Context, notification JSONRPCRequest) {
+	switch notification.Method {
+	case NotificationInitialized:
+	case NotificationRootsListChanged:
+		SessionFromContext(ctx).handleRootsChanged()
+	default:
+		slog.Debug("Ignoring notification", "method", notification.Method)
+	}
+}
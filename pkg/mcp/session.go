@@ -0,0 +1,174 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pengcunfu/go-mcp-git/internal/logging"
+)
+
+// Session is the connection of one client: its transport, the state it
+// initialized and the requests the server sent it. Every transport
+// connection gets its own session, so that several clients share a server.
+type Session struct {
+	id        string
+	transport Transport
+	started   time.Time
+
+	// writeMu serializes the messages written: responses, notifications
+	// and requests come from different goroutines
+	writeMu sync.Mutex
+
+	// mu guards the state of the session
+	mu              sync.Mutex
+	initialized     bool
+	client          InitializeRequest
+	protocolVersion string
+	logLevel        string
+	nextID          int64
+	pending         map[string]chan clientResponse
+	roots           []Root
+	rootsSet        bool
+	values          map[interface{}]interface{}
+}
+
+// SessionInfo describes a session
+type SessionInfo struct {
+	ID              string
+	Client          ClientInfo
+	ProtocolVersion string
+	Initialized     bool
+	Started         time.Time
+}
+
+// sessionKey holds the session of a request in its context
+type sessionKey struct{}
+
+// SessionFromContext returns the session of the request a context belongs
+// to, nil outside requests
+func SessionFromContext(ctx context.Context) *Session {
+	session, _ := ctx.Value(sessionKey{}).(*Session)
+	return session
+}
+
+// newSession starts the session of a transport connection
+func (s *Server) newSession(transport Transport) *Session {
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+	s.lastSession++
+	session := &Session{
+		id:        strconv.FormatInt(s.lastSession, 10),
+		transport: transport,
+		started:   time.Now(),
+		logLevel:  DefaultLogLevel,
+		pending:   make(map[string]chan clientResponse),
+		values:    make(map[interface{}]interface{}),
+	}
+	s.sessions[session.id] = session
+	return session
+}
+
+// endSession forgets a session whose connection ended
+func (s *Server) endSession(session *Session) {
+	s.sessionsMu.Lock()
+	delete(s.sessions, session.id)
+	s.sessionsMu.Unlock()
+}
+
+// Sessions describes the sessions being served, oldest first
+func (s *Server) Sessions() []SessionInfo {
+	s.sessionsMu.Lock()
+	sessions := make([]*Session, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		sessions = append(sessions, session)
+	}
+	s.sessionsMu.Unlock()
+
+	infos := make([]SessionInfo, 0, len(sessions))
+	for _, session := range sessions {
+		infos = append(infos, session.Info())
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Started.Before(infos[j].Started) })
+	return infos
+}
+
+// broadcast sends a notification to every initialized session
+func (s *Server) broadcast(method string, params interface{}) {
+	s.sessionsMu.Lock()
+	sessions := make([]*Session, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		sessions = append(sessions, session)
+	}
+	s.sessionsMu.Unlock()
+
+	for _, session := range sessions {
+		if session.Info().Initialized {
+			session.notify(method, params)
+		}
+	}
+}
+
+// ID identifies the session among the sessions of the server
+func (session *Session) ID() string {
+	return session.id
+}
+
+// Info describes the session
+func (session *Session) Info() SessionInfo {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	return SessionInfo{
+		ID:              session.id,
+		Client:          session.client.ClientInfo,
+		ProtocolVersion: session.protocolVersion,
+		Initialized:     session.initialized,
+		Started:         session.started,
+	}
+}
+
+// SetValue keeps a value for the rest of the session, e.g. defaults the
+// client chose. Keys are compared like context keys.
+func (session *Session) SetValue(key, value interface{}) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	if value == nil {
+		delete(session.values, key)
+		return
+	}
+	session.values[key] = value
+}
+
+// Value returns a value kept with SetValue, nil when there is none
+func (session *Session) Value(key interface{}) interface{} {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	return session.values[key]
+}
+
+// write sends a message to the client of the session
+func (session *Session) write(message []byte) error {
+	session.writeMu.Lock()
+	defer session.writeMu.Unlock()
+	slog.Log(context.Background(), logging.LevelTrace, "Sent message", "session", session.id, "message", string(message))
+	if err := session.transport.Write(message); err != nil {
+		slog.Error("Failed to write message", "session", session.id, "error", err)
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	return nil
+}
+
+// notify sends a notification to the client of the session
+func (session *Session) notify(method string, params interface{}) {
+	encoded, err := json.Marshal(JSONRPCNotification{JSONRPC: JSONRPCVersion, Method: method, Params: params})
+	if err != nil {
+		slog.Error("Failed to marshal notification", "method", method, "error", err)
+		return
+	}
+	session.write(encoded)
+}
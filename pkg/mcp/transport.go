@@ -0,0 +1,65 @@
+package mcp
+
+import (
+	"io"
+	"sync"
+)
+
+// Transport carries the JSON-RPC messages of one client. Read is called
+// from a single goroutine, Write may be called while a Read is waiting.
+type Transport interface {
+	// Read returns the next message of the client, io.EOF once the client
+	// went away
+	Read() ([]byte, error)
+	// Write sends a message to the client
+	Write(message []byte) error
+	// Close ends the connection
+	Close() error
+}
+
+// stdioTransport carries messages over a reader and a writer, usually
+// stdin and stdout, framed by newlines or headers
+type stdioTransport struct {
+	reader *frameReader
+	out    io.Writer
+
+	// mu guards the framing of the messages sent, following the client
+	// with FramingAuto
+	mu      sync.Mutex
+	framing Framing
+}
+
+// NewStdioTransport returns a transport reading messages from in and
+// writing them to out
+func NewStdioTransport(in io.Reader, out io.Writer, framing Framing) Transport {
+	outFraming := framing
+	if outFraming == FramingAuto || outFraming == "" {
+		outFraming = FramingNewline
+	}
+	return &stdioTransport{reader: newFrameReader(in, framing), out: out, framing: outFraming}
+}
+
+func (t *stdioTransport) Read() ([]byte, error) {
+	message, framing, err := t.reader.read()
+	if err != nil {
+		return nil, err
+	}
+	// Answers use the framing of the client
+	t.mu.Lock()
+	t.framing = framing
+	t.mu.Unlock()
+	return message, nil
+}
+
+func (t *stdioTransport) Write(message []byte) error {
+	t.mu.Lock()
+	framing := t.framing
+	t.mu.Unlock()
+	_, err := t.out.Write(frame(message, framing))
+	return err
+}
+
+// Close leaves stdin and stdout open, they belong to the process
+func (t *stdioTransport) Close() error {
+	return nil
+}
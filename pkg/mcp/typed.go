@@ -0,0 +1,320 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Typed tools declare their arguments as a struct instead of a hand-written
+// schema. Fields are named by their json tag and described by these tags:
+//
+//	description:"..."  documents the argument
+//	required:"true"    rejects calls without the argument, or with its zero value
+//	enum:"a,b,c"       lists the accepted values of a string or of the items of a list
+//	default:"..."      documents the value used when the argument is missing
+//	minimum:"1"        is the smallest accepted number
+//	maximum:"100"      is the largest accepted number
+//
+// Fields without a json tag or tagged "-" are not arguments, except
+// embedded structs whose fields are arguments of the outer struct, so that
+// tools share options. Types with a JSONSchema method describe themselves,
+// for arguments no Go type maps to, such as a string or a list of strings.
+
+// TypedToolHandler handles tool calls whose arguments are decoded into T
+type TypedToolHandler[T any] func(ctx context.Context, params T) ([]TextContent, error)
+
+// ErrorCodeInvalidArguments is the ToolError code of calls whose arguments
+// do not match the schema of a typed tool
+const ErrorCodeInvalidArguments = "invalid_arguments"
+
+// RegisterTool registers a typed tool, generating its input schema from T
+func RegisterTool[T any](s *Server, name, description string, handler TypedToolHandler[T]) {
+	s.RegisterTool(Tool{
+		Name:        name,
+		Description: description,
+		InputSchema: SchemaFor[T](),
+	}, Typed(handler))
+}
+
+// Typed adapts a typed handler to a ToolHandler. The arguments are decoded
+// into T and validated against its tags before the handler runs.
+func Typed[T any](handler TypedToolHandler[T]) ToolHandler {
+	return func(ctx context.Context, arguments map[string]interface{}) ([]TextContent, error) {
+		params, err := DecodeArguments[T](arguments)
+		if err != nil {
+			return nil, &ToolError{Code: ErrorCodeInvalidArguments, Err: err}
+		}
+		return handler(ctx, params)
+	}
+}
+
+// DecodeArguments decodes and validates the arguments of a tool call
+func DecodeArguments[T any](arguments map[string]interface{}) (T, error) {
+	var params T
+	encoded, err := json.Marshal(arguments)
+	if err != nil {
+		return params, fmt.Errorf("invalid arguments: %w", err)
+	}
+	if err := json.Unmarshal(encoded, &params); err != nil {
+		if typeErr, ok := err.(*json.UnmarshalTypeError); ok && typeErr.Field != "" {
+			return params, fmt.Errorf("invalid argument %s: expected %s, got %s", typeErr.Field, schemaType(typeErr.Type), typeErr.Value)
+		}
+		return params, fmt.Errorf("invalid arguments: %w", err)
+	}
+	if err := validate(reflect.ValueOf(params), ""); err != nil {
+		return params, err
+	}
+	return params, nil
+}
+
+// SchemaFor generates the JSON schema of the arguments of T, titled after
+// the name of T
+func SchemaFor[T any]() map[string]interface{} {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	schema := typeSchema(t)
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["title"] = t.Name()
+	return schema
+}
+
+// SchemaDescriber is implemented by argument types generating their own
+// schema
+type SchemaDescriber interface {
+	JSONSchema() map[string]interface{}
+}
+
+var schemaDescriberType = reflect.TypeOf((*SchemaDescriber)(nil)).Elem()
+
+// argumentField is a struct field holding an argument
+type argumentField struct {
+	name  string
+	index []int
+	field reflect.StructField
+}
+
+// argumentFields lists the fields of a struct holding arguments, those of
+// its embedded structs included
+func argumentFields(t reflect.Type) []argumentField {
+	var fields []argumentField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			for _, embedded := range argumentFields(field.Type) {
+				embedded.index = append([]int{i}, embedded.index...)
+				fields = append(fields, embedded)
+			}
+			continue
+		}
+		if !field.IsExported() || name == "" || name == "-" {
+			continue
+		}
+		fields = append(fields, argumentField{name: name, index: []int{i}, field: field})
+	}
+	return fields
+}
+
+// typeSchema generates the schema of a type
+func typeSchema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Implements(schemaDescriberType) {
+		return reflect.Zero(t).Interface().(SchemaDescriber).JSONSchema()
+	}
+
+	schema := map[string]interface{}{"type": schemaType(t)}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		schema["items"] = typeSchema(t.Elem())
+	case reflect.Map:
+		schema["additionalProperties"] = typeSchema(t.Elem())
+	case reflect.Struct:
+		properties := map[string]interface{}{}
+		var required []string
+		for _, f := range argumentFields(t) {
+			property := typeSchema(f.field.Type)
+			if description := f.field.Tag.Get("description"); description != "" {
+				property["description"] = description
+			}
+			if enum := f.field.Tag.Get("enum"); enum != "" {
+				values := strings.Split(enum, ",")
+				if items, ok := property["items"].(map[string]interface{}); ok {
+					items["enum"] = values
+				} else {
+					property["enum"] = values
+				}
+			}
+			if value, ok := f.field.Tag.Lookup("default"); ok {
+				property["default"] = parseTagValue(f.field.Type, value)
+			}
+			for _, bound := range []string{"minimum", "maximum"} {
+				if value, ok := f.field.Tag.Lookup(bound); ok {
+					property[bound] = parseTagValue(f.field.Type, value)
+				}
+			}
+			if f.field.Tag.Get("required") == "true" {
+				required = append(required, f.name)
+			}
+			properties[f.name] = property
+		}
+		schema["properties"] = properties
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+	}
+	return schema
+}
+
+// schemaType names the JSON schema type of a Go type
+func schemaType(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	default:
+		return "string"
+	}
+}
+
+// parseTagValue converts the value of a default or bound tag to the type
+// of its field, keeping it as a string when it does not parse
+func parseTagValue(t reflect.Type, value string) interface{} {
+	switch schemaType(t) {
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	case "integer":
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	}
+	return value
+}
+
+// validate checks the required, enum and bound tags of decoded arguments
+func validate(v reflect.Value, path string) error {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := validate(v.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		for _, f := range argumentFields(v.Type()) {
+			name := f.name
+			if path != "" {
+				name = path + "." + f.name
+			}
+			value := v.FieldByIndex(f.index)
+			if f.field.Tag.Get("required") == "true" && value.IsZero() {
+				return fmt.Errorf("missing required argument %s", name)
+			}
+			if enum := f.field.Tag.Get("enum"); enum != "" {
+				if err := validateEnum(value, name, strings.Split(enum, ",")); err != nil {
+					return err
+				}
+			}
+			if err := validateBounds(value, name, f.field.Tag); err != nil {
+				return err
+			}
+			if err := validate(value, name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateEnum checks that a string, or the strings of a list, are among
+// the accepted values. Empty strings are left to the defaults.
+func validateEnum(v reflect.Value, name string, values []string) error {
+	var check []string
+	switch {
+	case v.Kind() == reflect.String:
+		check = []string{v.String()}
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		for i := 0; i < v.Len(); i++ {
+			check = append(check, v.Index(i).String())
+		}
+	}
+	for _, value := range check {
+		if value == "" {
+			continue
+		}
+		accepted := false
+		for _, allowed := range values {
+			accepted = accepted || value == allowed
+		}
+		if !accepted {
+			return fmt.Errorf("invalid argument %s: %q is not one of %s", name, value, strings.Join(values, ", "))
+		}
+	}
+	return nil
+}
+
+// validateBounds checks the minimum and maximum of a number. Missing
+// numbers are left to the defaults: nil pointers, or zero values of other
+// fields.
+func validateBounds(v reflect.Value, name string, tag reflect.StructTag) error {
+	pointer := v.Kind() == reflect.Pointer
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !pointer && v.IsZero() {
+		return nil
+	}
+	var value float64
+	switch {
+	case v.CanInt():
+		value = float64(v.Int())
+	case v.CanUint():
+		value = float64(v.Uint())
+	case v.CanFloat():
+		value = v.Float()
+	default:
+		return nil
+	}
+	if minimum, ok := tag.Lookup("minimum"); ok {
+		if bound, err := strconv.ParseFloat(minimum, 64); err == nil && value < bound {
+			return fmt.Errorf("invalid argument %s: %v is less than %s", name, value, minimum)
+		}
+	}
+	if maximum, ok := tag.Lookup("maximum"); ok {
+		if bound, err := strconv.ParseFloat(maximum, 64); err == nil && value > bound {
+			return fmt.Errorf("invalid argument %s: %v is greater than %s", name, value, maximum)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package mcp
+
+import (
+	"reflect"
+	"testing"
+)
+
+type testPaging struct {
+	Limit *int `json:"limit,omitempty" description:"Maximum number of results" minimum:"1" maximum:"100" default:"20"`
+}
+
+type testLabels []string
+
+func (testLabels) JSONSchema() map[string]interface{} {
+	return map[string]interface{}{"type": "string"}
+}
+
+type testArguments struct {
+	Name   string     `json:"name" description:"Name to look up" required:"true"`
+	Kind   string     `json:"kind,omitempty" enum:"a,b"`
+	Labels testLabels `json:"labels,omitempty" description:"Labels to match"`
+	hidden string
+	testPaging
+}
+
+func TestSchemaFor(t *testing.T) {
+	schema := SchemaFor[testArguments]()
+	if schema["title"] != "testArguments" {
+		t.Errorf("Expected the schema titled after the type, got %v", schema["title"])
+	}
+	if !reflect.DeepEqual(schema["required"], []string{"name"}) {
+		t.Errorf("Expected name to be required, got %v", schema["required"])
+	}
+
+	properties := schema["properties"].(map[string]interface{})
+	if len(properties) != 4 {
+		t.Errorf("Expected name, kind, labels and the embedded limit, got %v", properties)
+	}
+	limit, ok := properties["limit"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected the fields of embedded structs to be arguments, got %v", properties)
+	}
+	if limit["type"] != "integer" || limit["minimum"] != int64(1) || limit["maximum"] != int64(100) || limit["default"] != int64(20) {
+		t.Errorf("Unexpected limit property: %v", limit)
+	}
+
+	// Types describing themselves keep their schema, with the tags applied
+	labels := properties["labels"].(map[string]interface{})
+	if labels["type"] != "string" || labels["description"] != "Labels to match" {
+		t.Errorf("Unexpected labels property: %v", labels)
+	}
+}
+
+func TestDecodeArguments(t *testing.T) {
+	tests := []struct {
+		name      string
+		arguments map[string]interface{}
+		valid     bool
+	}{
+		{"valid", map[string]interface{}{"name": "x", "kind": "a", "limit": 100}, true},
+		{"optional arguments missing", map[string]interface{}{"name": "x"}, true},
+		{"required argument missing", map[string]interface{}{"kind": "a"}, false},
+		{"not in enum", map[string]interface{}{"name": "x", "kind": "c"}, false},
+		{"wrong type", map[string]interface{}{"name": 1}, false},
+		{"below minimum", map[string]interface{}{"name": "x", "limit": 0}, false},
+		{"above maximum", map[string]interface{}{"name": "x", "limit": 101}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := DecodeArguments[testArguments](tt.arguments)
+			if (err == nil) != tt.valid {
+				t.Fatalf("Expected valid %v, got error: %v", tt.valid, err)
+			}
+			if tt.valid && params.Name != "x" {
+				t.Errorf("Expected name to be decoded, got %q", params.Name)
+			}
+		})
+	}
+
+	params, err := DecodeArguments[testArguments](map[string]interface{}{"name": "x", "limit": 5})
+	if err != nil {
+		t.Fatalf("DecodeArguments failed: %v", err)
+	}
+	if params.Limit == nil || *params.Limit != 5 {
+		t.Errorf("Expected the embedded limit to be decoded, got %v", params.Limit)
+	}
+}
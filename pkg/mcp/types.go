@@ -0,0 +1,345 @@
+package mcp
+
+import "encoding/json"
+
+// JSONRPCRequest represents a JSON-RPC 2.0 request
+type JSONRPCRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      interface{}     `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// JSONRPCResponse represents a JSON-RPC 2.0 response. The ID is null when
+// the request could not be read.
+type JSONRPCResponse struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      interface{} `json:"id"`
+	Result  interface{} `json:"result,omitempty"`
+	Error   *RPCError   `json:"error,omitempty"`
+}
+
+// JSONRPCNotification represents a JSON-RPC 2.0 notification, a request
+// without an ID that is not answered
+type JSONRPCNotification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// RPCError represents a JSON-RPC error
+type RPCError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// ToolError is a tool failure carrying a machine-readable code, which is
+// reported along with the error result of the call
+type ToolError struct {
+	Code string
+	Err  error
+}
+
+func (e *ToolError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ToolError) Unwrap() error {
+	return e.Err
+}
+
+// ToolErrorData represents the data of the error of a failed tool call
+type ToolErrorData struct {
+	Code string `json:"code"`
+	Tool string `json:"tool"`
+}
+
+// Tool represents an MCP tool
+type Tool struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	InputSchema interface{} `json:"inputSchema"`
+}
+
+// TextContent represents text content in MCP
+type TextContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// InitializeRequest represents the initialize request
+type InitializeRequest struct {
+	ProtocolVersion string            `json:"protocolVersion"`
+	Capabilities    ClientCapabilities `json:"capabilities"`
+	ClientInfo      ClientInfo        `json:"clientInfo"`
+}
+
+// InitializeResponse represents the initialize response
+type InitializeResponse struct {
+	ProtocolVersion string             `json:"protocolVersion"`
+	Capabilities    ServerCapabilities `json:"capabilities"`
+	ServerInfo      ServerInfo         `json:"serverInfo"`
+}
+
+// ClientCapabilities represents client capabilities
+type ClientCapabilities struct {
+	Roots    *RootsCapability    `json:"roots,omitempty"`
+	Sampling *SamplingCapability `json:"sampling,omitempty"`
+	// Experimental holds the capabilities outside the specification, such
+	// as PartialResultsCapability
+	Experimental map[string]interface{} `json:"experimental,omitempty"`
+}
+
+// RootsCapability represents roots capability
+type RootsCapability struct {
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+// SamplingCapability represents sampling capability
+type SamplingCapability struct{}
+
+// ServerCapabilities represents server capabilities
+type ServerCapabilities struct {
+	Tools       *ToolsCapability       `json:"tools,omitempty"`
+	Logging     *LoggingCapability     `json:"logging,omitempty"`
+	Completions *CompletionsCapability `json:"completions,omitempty"`
+	Resources   *ResourcesCapability   `json:"resources,omitempty"`
+	// Experimental holds the capabilities outside the specification, such
+	// as PartialResultsCapability
+	Experimental map[string]interface{} `json:"experimental,omitempty"`
+}
+
+// ToolsCapability represents tools capability
+type ToolsCapability struct {
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+// LoggingCapability represents logging capability
+type LoggingCapability struct{}
+
+// CompletionsCapability represents completions capability
+type CompletionsCapability struct{}
+
+// ResourcesCapability represents resources capability
+type ResourcesCapability struct {
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+// SetLevelRequest represents the logging/setLevel request
+type SetLevelRequest struct {
+	Level string `json:"level"`
+}
+
+// LogMessageNotification represents the params of a log message
+// notification
+type LogMessageNotification struct {
+	Level  string      `json:"level"`
+	Logger string      `json:"logger,omitempty"`
+	Data   interface{} `json:"data"`
+}
+
+// ClientInfo represents client information
+type ClientInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// ServerInfo represents server information. Title is a display name,
+// from protocol version 2025-06-18.
+type ServerInfo struct {
+	Name    string `json:"name"`
+	Title   string `json:"title,omitempty"`
+	Version string `json:"version"`
+}
+
+// ListToolsRequest represents the list_tools request, Cursor being the
+// NextCursor of the previous page
+type ListToolsRequest struct {
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// ListToolsResponse represents the response to list_tools. NextCursor is set
+// when more tools follow.
+type ListToolsResponse struct {
+	Tools      []Tool `json:"tools"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// CallToolRequest represents a tool call request
+type CallToolRequest struct {
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments,omitempty"`
+	Meta      *RequestMeta           `json:"_meta,omitempty"`
+}
+
+// RequestMeta holds the metadata of a request. Clients give a progress
+// token to be notified of the progress of the request, and a W3C
+// traceparent for the request to join their trace.
+type RequestMeta struct {
+	ProgressToken interface{} `json:"progressToken,omitempty"`
+	TraceParent   string      `json:"traceparent,omitempty"`
+}
+
+// ProgressNotification reports the progress of a request whose client gave
+// a progress token. Total is omitted when unknown. Content holds a part of
+// the result of a tool call, for clients with the partial results
+// capability.
+type ProgressNotification struct {
+	ProgressToken interface{}   `json:"progressToken"`
+	Progress      float64       `json:"progress"`
+	Total         float64       `json:"total,omitempty"`
+	Message       string        `json:"message,omitempty"`
+	Content       []TextContent `json:"content,omitempty"`
+}
+
+// CallToolResponse represents a tool call response. Failed tool calls set
+// IsError, their content describing the failure and Meta holding its
+// ToolErrorData under "error".
+type CallToolResponse struct {
+	Content []TextContent          `json:"content"`
+	IsError bool                   `json:"isError,omitempty"`
+	Meta    map[string]interface{} `json:"_meta,omitempty"`
+}
+
+// ListRootsResponse represents the response of the client to roots/list
+type ListRootsResponse struct {
+	Roots []Root `json:"roots"`
+}
+
+// Root represents a root directory
+type Root struct {
+	URI  string `json:"uri"`
+	Name string `json:"name,omitempty"`
+}
+
+// CreateMessageRequest represents a sampling/createMessage request, asking
+// the client to run a prompt through its model
+type CreateMessageRequest struct {
+	Messages         []SamplingMessage `json:"messages"`
+	SystemPrompt     string            `json:"systemPrompt,omitempty"`
+	MaxTokens        int               `json:"maxTokens"`
+	Temperature      *float64          `json:"temperature,omitempty"`
+	StopSequences    []string          `json:"stopSequences,omitempty"`
+	ModelPreferences *ModelPreferences `json:"modelPreferences,omitempty"`
+}
+
+// SamplingMessage represents a message of a sampling conversation
+type SamplingMessage struct {
+	Role    string      `json:"role"`
+	Content TextContent `json:"content"`
+}
+
+// ModelPreferences represents the model preferences of a sampling request,
+// priorities ranging from 0 to 1
+type ModelPreferences struct {
+	CostPriority         *float64 `json:"costPriority,omitempty"`
+	SpeedPriority        *float64 `json:"speedPriority,omitempty"`
+	IntelligencePriority *float64 `json:"intelligencePriority,omitempty"`
+}
+
+// CreateMessageResult represents the response to a sampling/createMessage
+// request
+type CreateMessageResult struct {
+	Role       string      `json:"role"`
+	Content    TextContent `json:"content"`
+	Model      string      `json:"model"`
+	StopReason string      `json:"stopReason,omitempty"`
+}
+
+// CompleteRequest represents a completion/complete request, asking for the
+// values of an argument starting with Argument.Value
+type CompleteRequest struct {
+	Ref      CompleteReference `json:"ref"`
+	Argument CompleteArgument  `json:"argument"`
+	Context  *CompleteContext  `json:"context,omitempty"`
+}
+
+// CompleteReference represents what the completed argument belongs to. Type
+// is ref/prompt, ref/resource or ref/tool, names identifying prompts and
+// tools and URIs resources.
+type CompleteReference struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+	URI  string `json:"uri,omitempty"`
+}
+
+// CompleteArgument represents the argument being completed
+type CompleteArgument struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// CompleteContext represents the arguments already given
+type CompleteContext struct {
+	Arguments map[string]string `json:"arguments,omitempty"`
+}
+
+// CompleteResult represents the response to completion/complete
+type CompleteResult struct {
+	Completion Completion `json:"completion"`
+}
+
+// Completion represents the values completing an argument. Total counts all
+// the values when only some are listed, HasMore being set then.
+type Completion struct {
+	Values  []string `json:"values"`
+	Total   int      `json:"total,omitempty"`
+	HasMore bool     `json:"hasMore,omitempty"`
+}
+
+// Resource represents a resource the server offers to read
+type Resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// ListResourcesResult represents the response to resources/list
+type ListResourcesResult struct {
+	Resources []Resource `json:"resources"`
+}
+
+// ReadResourceRequest represents a resources/read request
+type ReadResourceRequest struct {
+	URI string `json:"uri"`
+}
+
+// ResourceContents represents the text of a resource
+type ResourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text"`
+}
+
+// ReadResourceResult represents the response to resources/read
+type ReadResourceResult struct {
+	Contents []ResourceContents `json:"contents"`
+}
+
+// Constants for JSON-RPC
+const (
+	JSONRPCVersion = "2.0"
+)
+
+// MCP method names
+const (
+	MethodInitialize    = "initialize"
+	MethodListTools     = "tools/list"
+	MethodCallTool      = "tools/call"
+	MethodListRoots     = "roots/list"
+	MethodSetLevel      = "logging/setLevel"
+	MethodPing          = "ping"
+	MethodSampling      = "sampling/createMessage"
+	MethodComplete      = "completion/complete"
+	MethodListResources = "resources/list"
+	MethodReadResource  = "resources/read"
+
+	NotificationInitialized          = "notifications/initialized"
+	NotificationMessage              = "notifications/message"
+	NotificationToolsListChanged     = "notifications/tools/list_changed"
+	NotificationRootsListChanged     = "notifications/roots/list_changed"
+	NotificationResourcesListChanged = "notifications/resources/list_changed"
+	NotificationProgress             = "notifications/progress"
+)
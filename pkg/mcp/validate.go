@@ -0,0 +1,236 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// ArgumentError is an argument of a tool call that does not match the
+// input schema of the tool
+type ArgumentError struct {
+	// Field is the path of the argument, e.g. plan[0].action
+	Field string
+	// Message completes "argument <Field>", e.g. "is required"
+	Message string
+}
+
+func (e *ArgumentError) Error() string {
+	return fmt.Sprintf("argument %s %s", e.Field, e.Message)
+}
+
+// ValidateArguments checks the arguments of a tool call against its input
+// schema. It supports the keywords of the schemas of this server: type,
+// properties, required, items, additionalProperties, enum, minimum, maximum,
+// oneOf and anyOf. Arguments the schema does not declare are accepted.
+func ValidateArguments(schema interface{}, arguments map[string]interface{}) error {
+	s, ok := asSchema(schema)
+	if !ok {
+		return nil
+	}
+	if arguments == nil {
+		arguments = map[string]interface{}{}
+	}
+	return validateValue(s, arguments, "")
+}
+
+// asSchema returns a schema as a map, converting schemas declared as
+// other types through JSON
+func asSchema(schema interface{}) (map[string]interface{}, bool) {
+	switch s := schema.(type) {
+	case nil:
+		return nil, false
+	case map[string]interface{}:
+		return s, true
+	}
+	encoded, err := json.Marshal(schema)
+	if err != nil {
+		return nil, false
+	}
+	var s map[string]interface{}
+	if err := json.Unmarshal(encoded, &s); err != nil {
+		return nil, false
+	}
+	return s, true
+}
+
+// validateValue checks a value against a schema
+func validateValue(schema map[string]interface{}, value interface{}, field string) error {
+	for _, keyword := range []string{"oneOf", "anyOf"} {
+		if alternatives := schemaList(schema[keyword]); len(alternatives) > 0 {
+			matched := false
+			for _, alternative := range alternatives {
+				matched = matched || validateValue(alternative, value, field) == nil
+			}
+			if !matched {
+				return &ArgumentError{Field: fieldName(field), Message: "does not match any of the accepted forms"}
+			}
+		}
+	}
+
+	if expected, ok := schema["type"].(string); ok && !hasType(value, expected) {
+		return &ArgumentError{Field: fieldName(field), Message: fmt.Sprintf("must be of type %s, got %s", expected, jsonType(value))}
+	}
+	if enum := stringList(schema["enum"]); len(enum) > 0 {
+		// Empty strings select the default like missing arguments
+		if s, ok := value.(string); ok && s != "" && !contains(enum, s) {
+			return &ArgumentError{Field: fieldName(field), Message: fmt.Sprintf("must be one of %s, got %q", strings.Join(enum, ", "), s)}
+		}
+	}
+	if n, ok := value.(float64); ok {
+		if minimum, ok := number(schema["minimum"]); ok && n < minimum {
+			return &ArgumentError{Field: fieldName(field), Message: fmt.Sprintf("must be at least %v, got %v", minimum, n)}
+		}
+		if maximum, ok := number(schema["maximum"]); ok && n > maximum {
+			return &ArgumentError{Field: fieldName(field), Message: fmt.Sprintf("must be at most %v, got %v", maximum, n)}
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, name := range stringList(schema["required"]) {
+			if isMissing(v[name]) {
+				return &ArgumentError{Field: fieldName(join(field, name)), Message: "is required"}
+			}
+		}
+		properties, _ := asSchema(schema["properties"])
+		additional, _ := asSchema(schema["additionalProperties"])
+		for name, item := range v {
+			// Null stands for a missing optional argument
+			if item == nil {
+				continue
+			}
+			property, declared := asSchema(properties[name])
+			if !declared {
+				property, declared = additional, additional != nil
+			}
+			if !declared {
+				continue
+			}
+			if err := validateValue(property, item, join(field, name)); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		if items, ok := asSchema(schema["items"]); ok {
+			for i, item := range v {
+				if err := validateValue(items, item, fmt.Sprintf("%s[%d]", field, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// hasType reports whether a decoded JSON value has a JSON schema type
+func hasType(value interface{}, expected string) bool {
+	switch expected {
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	}
+	return jsonType(value) == expected
+}
+
+// jsonType names the JSON type of a decoded value
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64, json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return reflect.TypeOf(value).String()
+}
+
+// isMissing reports whether a required argument is absent. Empty strings
+// count as absent, handlers treating them as not given.
+func isMissing(value interface{}) bool {
+	s, isString := value.(string)
+	return value == nil || (isString && s == "")
+}
+
+// schemaList returns the schemas of oneOf and anyOf
+func schemaList(value interface{}) []map[string]interface{} {
+	var schemas []map[string]interface{}
+	if items, ok := value.([]interface{}); ok {
+		for _, item := range items {
+			if s, ok := asSchema(item); ok {
+				schemas = append(schemas, s)
+			}
+		}
+	}
+	return schemas
+}
+
+// stringList returns the strings of required and enum, declared as
+// []string or decoded as []interface{}
+func stringList(value interface{}) []string {
+	switch v := value.(type) {
+	case []string:
+		return v
+	case []interface{}:
+		var list []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
+
+// number returns a numeric keyword of a schema
+func number(value interface{}) (float64, bool) {
+	v := reflect.ValueOf(value)
+	switch {
+	case !v.IsValid():
+		return 0, false
+	case v.CanInt():
+		return float64(v.Int()), true
+	case v.CanUint():
+		return float64(v.Uint()), true
+	case v.CanFloat():
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func join(field, name string) string {
+	if field == "" {
+		return name
+	}
+	return field + "." + name
+}
+
+// fieldName names the arguments object itself when field is empty
+func fieldName(field string) string {
+	if field == "" {
+		return "arguments"
+	}
+	return field
+}
@@ -0,0 +1,92 @@
+package mcp
+
+// Protocol versions of MCP supported by the server
+const (
+	ProtocolVersion20241105 = "2024-11-05"
+	ProtocolVersion20250326 = "2025-03-26"
+	ProtocolVersion20250618 = "2025-06-18"
+
+	// LatestProtocolVersion is the version offered to clients asking for a
+	// newer one
+	LatestProtocolVersion = ProtocolVersion20250618
+)
+
+// SupportedProtocolVersions lists the supported versions, oldest first
+var SupportedProtocolVersions = []string{
+	ProtocolVersion20241105,
+	ProtocolVersion20250326,
+	ProtocolVersion20250618,
+}
+
+// UnsupportedVersionData is the data of the error answering an initialize
+// request with an unsupported protocol version
+type UnsupportedVersionData struct {
+	Supported []string `json:"supported"`
+	Requested string   `json:"requested"`
+}
+
+// negotiateVersion picks the protocol version of a session. Supported
+// versions are accepted as requested, newer ones get the latest version,
+// which the client may accept or disconnect. Clients not naming a version
+// predate the negotiation and get the oldest one. Versions are dates, so
+// they compare as strings.
+func negotiateVersion(requested string) (string, bool) {
+	if requested == "" {
+		return ProtocolVersion20241105, true
+	}
+	for _, version := range SupportedProtocolVersions {
+		if version == requested {
+			return version, true
+		}
+	}
+	if isProtocolVersion(requested) && requested > LatestProtocolVersion {
+		return LatestProtocolVersion, true
+	}
+	return "", false
+}
+
+// isProtocolVersion reports whether a version has the YYYY-MM-DD form of
+// MCP versions
+func isProtocolVersion(version string) bool {
+	if len(version) != len("2006-01-02") {
+		return false
+	}
+	for i, c := range version {
+		if i == 4 || i == 7 {
+			if c != '-' {
+				return false
+			}
+		} else if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// capabilitiesFor returns the capabilities declared to a session of a
+// protocol version, leaving out those the version does not define
+func (s *Server) capabilitiesFor(version string) ServerCapabilities {
+	capabilities := s.capabilities
+	if version < ProtocolVersion20250326 {
+		// Completions were declared from 2025-03-26, older clients ask
+		// for them without it
+		capabilities.Completions = nil
+	}
+	return capabilities
+}
+
+// serverInfoFor returns the server information sent to a session of a
+// protocol version
+func (s *Server) serverInfoFor(version string) ServerInfo {
+	info := ServerInfo{Name: s.name, Version: s.version}
+	if version >= ProtocolVersion20250618 {
+		info.Title = s.title
+	}
+	return info
+}
+
+// SetTitle sets the human-readable name of the server, sent to clients of
+// protocol version 2025-06-18 and later
+func (s *Server) SetTitle(title string) {
+	s.title = title
+}
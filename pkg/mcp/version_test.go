@@ -0,0 +1,66 @@
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNegotiateVersion(t *testing.T) {
+	tests := []struct {
+		name      string
+		requested string
+		version   string
+		ok        bool
+	}{
+		{"oldest supported", ProtocolVersion20241105, ProtocolVersion20241105, true},
+		{"supported", ProtocolVersion20250326, ProtocolVersion20250326, true},
+		{"latest", ProtocolVersion20250618, ProtocolVersion20250618, true},
+		{"newer", "2099-01-01", LatestProtocolVersion, true},
+		{"missing", "", ProtocolVersion20241105, true},
+		{"unknown older", "2024-01-01", "", false},
+		{"malformed", "latest", "", false},
+		{"malformed date", "2099-1-01x", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, ok := negotiateVersion(tt.requested)
+			if version != tt.version || ok != tt.ok {
+				t.Errorf("negotiateVersion(%q) = %q, %v, expected %q, %v", tt.requested, version, ok, tt.version, tt.ok)
+			}
+		})
+	}
+}
+
+func TestInitialize_ProtocolVersion(t *testing.T) {
+	tests := []struct {
+		name      string
+		requested string
+		version   string
+	}{
+		{"supported", ProtocolVersion20250326, ProtocolVersion20250326},
+		{"newer", "2099-01-01", LatestProtocolVersion},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := serveLines(t, NewServer("test", "1"),
+				`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"`+tt.requested+`","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`)
+			var response struct {
+				Result InitializeResponse `json:"result"`
+			}
+			if err := json.Unmarshal([]byte(lines[0]), &response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if response.Result.ProtocolVersion != tt.version {
+				t.Errorf("Expected version %s, got: %s", tt.version, lines[0])
+			}
+		})
+	}
+
+	// Unsupported versions are refused with the supported ones
+	lines := serveLines(t, NewServer("test", "1"),
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-01-01","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`)
+	response := decodeResponse(t, lines[0])
+	if response.Error == nil {
+		t.Fatalf("Expected an unsupported version to be refused, got: %s", lines[0])
+	}
+}
@@ -0,0 +1,194 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// DefaultWebSocketMaxMessageBytes bounds the messages received over
+// WebSocket by default, high enough for the diffs clients send while
+// keeping the memory a connection can take small
+const DefaultWebSocketMaxMessageBytes = 8 << 20
+
+// WebSocketOptions configures the WebSocket transport
+type WebSocketOptions struct {
+	// Path is the HTTP path accepting connections, / when empty
+	Path string
+	// MaxMessageBytes bounds the messages of the clients, larger ones
+	// closing the connection, DefaultWebSocketMaxMessageBytes when zero
+	MaxMessageBytes int
+	// AllowedOrigins are the origins of the browser pages allowed to
+	// connect, "*" allowing all. Clients without Origin header, which are
+	// not browsers, are always accepted.
+	AllowedOrigins []string
+	// Authenticate, when set, checks the bearer token of every connection,
+	// when it connects and then every TokenRevalidation
+	// (DefaultTokenRevalidation when zero), closing the connections whose
+	// token was revoked or expired
+	Authenticate      TokenValidator
+	TokenRevalidation time.Duration
+	// TLSCertFile and TLSKeyFile, when set, serve wss:// instead of ws://
+	TLSCertFile string
+	TLSKeyFile  string
+	// Handlers serve other HTTP paths next to the WebSocket endpoint, such
+	// as metrics, authenticated like the connections
+	Handlers map[string]http.Handler
+}
+
+// websocketTransport carries one message per WebSocket text frame
+type websocketTransport struct {
+	conn *websocket.Conn
+}
+
+func (t *websocketTransport) Read() ([]byte, error) {
+	var message []byte
+	if err := websocket.Message.Receive(t.conn, &message); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+func (t *websocketTransport) Write(message []byte) error {
+	return websocket.Message.Send(t.conn, string(message))
+}
+
+func (t *websocketTransport) Close() error {
+	return t.conn.Close()
+}
+
+// ServeWebSocket serves clients connecting over WebSocket on addr until ctx
+// is done. Each connection is a session of its own, served alongside the
+// others.
+func (s *Server) ServeWebSocket(ctx context.Context, addr string, opts WebSocketOptions) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	return s.ServeWebSocketListener(ctx, listener, opts)
+}
+
+// ServeWebSocketListener serves WebSocket clients on a listener, like
+// ServeWebSocket
+func (s *Server) ServeWebSocketListener(ctx context.Context, listener net.Listener, opts WebSocketOptions) error {
+	path := opts.Path
+	if path == "" {
+		path = "/"
+	}
+	maxMessageBytes := opts.MaxMessageBytes
+	if maxMessageBytes <= 0 {
+		maxMessageBytes = DefaultWebSocketMaxMessageBytes
+	}
+
+	var connections sync.WaitGroup
+	ws := websocket.Server{
+		Handshake: func(config *websocket.Config, r *http.Request) error {
+			return checkOrigin(r, opts.AllowedOrigins)
+		},
+		Handler: func(conn *websocket.Conn) {
+			conn.MaxPayloadBytes = maxMessageBytes
+			transport := &websocketTransport{conn: conn}
+			defer transport.Close()
+
+			remote := conn.Request().RemoteAddr
+			if opts.Authenticate != nil {
+				interval := opts.TokenRevalidation
+				if interval <= 0 {
+					interval = DefaultTokenRevalidation
+				}
+				stop := watchToken(transport, bearerToken(conn.Request()), opts.Authenticate, interval, remote)
+				defer stop()
+			}
+			slog.Info("WebSocket client connected", "remote", remote)
+			if err := s.ServeTransport(ctx, transport); err != nil && !errors.Is(err, net.ErrClosed) {
+				slog.Warn("WebSocket client failed", "remote", remote, "error", err)
+				return
+			}
+			slog.Info("WebSocket client disconnected", "remote", remote)
+		},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		if opts.Authenticate != nil && !authenticate(w, r, opts.Authenticate) {
+			return
+		}
+		// ServeHTTP returns once the connection ended
+		connections.Add(1)
+		defer connections.Done()
+		ws.ServeHTTP(w, r)
+	})
+	for pattern, handler := range opts.Handlers {
+		if pattern == path {
+			listener.Close()
+			return fmt.Errorf("HTTP path %s is already used by the WebSocket endpoint", path)
+		}
+		handler := handler
+		mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+			if opts.Authenticate != nil && !authenticate(w, r, opts.Authenticate) {
+				return
+			}
+			handler.ServeHTTP(w, r)
+		})
+	}
+
+	httpServer := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	stopped := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			// Connections are hijacked, Shutdown only stops accepting
+			// them and ServeTransport drains the requests in flight
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), s.drainTimeout)
+			defer cancel()
+			httpServer.Shutdown(shutdownCtx)
+		case <-stopped:
+		}
+	}()
+	defer close(stopped)
+
+	tls := opts.TLSCertFile != "" || opts.TLSKeyFile != ""
+	slog.Info("Serving WebSocket clients", "addr", listener.Addr().String(), "path", path, "tls", tls, "authenticated", opts.Authenticate != nil)
+	var err error
+	if tls {
+		err = httpServer.ServeTLS(listener, opts.TLSCertFile, opts.TLSKeyFile)
+	} else {
+		err = httpServer.Serve(listener)
+	}
+	if !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve WebSocket clients: %w", err)
+	}
+	// Serve returns right away on shutdown, the clients connected still
+	// draining
+	connections.Wait()
+	return nil
+}
+
+// checkOrigin accepts connections without Origin header and those from the
+// allowed origins, comparing scheme, host and port
+func checkOrigin(r *http.Request, allowed []string) error {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return fmt.Errorf("invalid origin %q", origin)
+	}
+	for _, a := range allowed {
+		if a == "*" || strings.EqualFold(strings.TrimSuffix(a, "/"), u.Scheme+"://"+u.Host) {
+			return nil
+		}
+	}
+	slog.Warn("Rejected WebSocket connection from a disallowed origin", "origin", origin, "remote", r.RemoteAddr)
+	return fmt.Errorf("origin %q is not allowed", origin)
+}
@@ -0,0 +1,134 @@
+package mcp
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+func TestCheckOrigin(t *testing.T) {
+	tests := []struct {
+		name    string
+		origin  string
+		allowed []string
+		ok      bool
+	}{
+		{"no origin", "", nil, true},
+		{"no allowed origins", "http://localhost:3000", nil, false},
+		{"allowed", "http://localhost:3000", []string{"http://localhost:3000"}, true},
+		{"allowed with slash", "http://localhost:3000", []string{"http://localhost:3000/"}, true},
+		{"case insensitive", "http://LOCALHOST:3000", []string{"http://localhost:3000"}, true},
+		{"other port", "http://localhost:3001", []string{"http://localhost:3000"}, false},
+		{"other scheme", "https://localhost:3000", []string{"http://localhost:3000"}, false},
+		{"wildcard", "https://example.com", []string{"*"}, true},
+		{"malformed", "http://[::1", []string{"*"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+			if err := checkOrigin(r, tt.allowed); (err == nil) != tt.ok {
+				t.Errorf("Expected accepted %v, got: %v", tt.ok, err)
+			}
+		})
+	}
+}
+
+// serveWebSocket serves a test server over WebSocket on a random port,
+// returning its URL. The server stops at the end of the test.
+func serveWebSocket(t *testing.T, opts WebSocketOptions) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	server := NewServer("test", "1")
+	server.SetDrainTimeout(time.Second)
+	done := make(chan error, 1)
+	go func() {
+		done <- server.ServeWebSocketListener(ctx, listener, opts)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("ServeWebSocketListener failed: %v", err)
+		}
+	})
+	return "ws://" + listener.Addr().String() + "/"
+}
+
+// dialWebSocket connects to a test server from origin with a bearer token,
+// if any
+func dialWebSocket(url, origin, token string) (*websocket.Conn, error) {
+	config, err := websocket.NewConfig(url, origin)
+	if err != nil {
+		return nil, err
+	}
+	config.Header = http.Header{}
+	if token != "" {
+		config.Header.Set("Authorization", "Bearer "+token)
+	}
+	return websocket.DialConfig(config)
+}
+
+func TestWebSocket_RoundTrip(t *testing.T) {
+	url := serveWebSocket(t, WebSocketOptions{
+		AllowedOrigins: []string{"http://localhost"},
+		Authenticate:   StaticTokens("secret"),
+	})
+
+	if _, err := dialWebSocket(url, "http://localhost", "wrong"); err == nil {
+		t.Fatalf("Expected a connection with a wrong token to be refused")
+	}
+	if _, err := dialWebSocket(url, "http://evil.example", "secret"); err == nil {
+		t.Fatalf("Expected a connection from a disallowed origin to be refused")
+	}
+
+	conn, err := dialWebSocket(url, "http://localhost", "secret")
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	for id, request := range []string{testInitialize, `{"jsonrpc":"2.0","id":2,"method":"ping"}`} {
+		if err := websocket.Message.Send(conn, request); err != nil {
+			t.Fatalf("Failed to send: %v", err)
+		}
+		var response string
+		if err := websocket.Message.Receive(conn, &response); err != nil {
+			t.Fatalf("Failed to receive: %v", err)
+		}
+		if decoded := decodeResponse(t, response); decoded.ID != float64(id+1) || decoded.Error != nil {
+			t.Errorf("Unexpected response: %s", response)
+		}
+	}
+}
+
+func TestWebSocket_MaxMessageBytes(t *testing.T) {
+	url := serveWebSocket(t, WebSocketOptions{AllowedOrigins: []string{"*"}, MaxMessageBytes: 1024})
+	conn, err := dialWebSocket(url, "http://localhost", "")
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	large := `{"jsonrpc":"2.0","id":1,"method":"ping","params":{"padding":"` + strings.Repeat("a", 2048) + `"}}`
+	if err := websocket.Message.Send(conn, large); err != nil {
+		t.Fatalf("Failed to send: %v", err)
+	}
+	var response string
+	if err := websocket.Message.Receive(conn, &response); err == nil {
+		t.Errorf("Expected the connection to be closed after an oversized message, got: %s", response)
+	}
+}
@@ -0,0 +1,280 @@
+package mcpserver
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pengcunfu/go-mcp-git/pkg/gitops"
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+// repoAliases names the repositories tools accept as repo instead of a
+// repo_path. Targets are local paths, or URLs cloned on first use below
+// cloneDir.
+type repoAliases struct {
+	targets  map[string]string
+	cloneDir string
+}
+
+// aliasClones serializes the clones of URL aliases, mounted instances
+// sharing the clone directory
+var aliasClones sync.Mutex
+
+func newRepoAliases(targets map[string]string, cloneDir string) *repoAliases {
+	if cloneDir == "" {
+		cloneDir = defaultAliasCloneDir()
+	}
+	return &repoAliases{targets: targets, cloneDir: cloneDir}
+}
+
+// defaultAliasCloneDir is where URL aliases are cloned without
+// Config.AliasCloneDir, kept between runs
+func defaultAliasCloneDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "go-mcp-git", "repositories")
+}
+
+// names returns the aliases in order
+func (a *repoAliases) names() []string {
+	names := make([]string, 0, len(a.targets))
+	for name := range a.targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// path returns the local path of an alias: its target, or the directory
+// its URL is cloned into
+func (a *repoAliases) path(name string) (string, bool) {
+	target, ok := a.targets[name]
+	if !ok {
+		return "", false
+	}
+	if !isRepositoryURL(target) {
+		return target, true
+	}
+	// The digest keeps aliases of the same name to other URLs, e.g. in
+	// other profiles, from sharing a clone
+	sum := sha256.Sum256([]byte(target))
+	return filepath.Join(a.cloneDir, name+"-"+hex.EncodeToString(sum[:4])), true
+}
+
+// paths returns the local paths of all aliases, in the order of names
+func (a *repoAliases) paths() []string {
+	paths := make([]string, 0, len(a.targets))
+	for _, name := range a.names() {
+		path, _ := a.path(name)
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// scpURLPattern matches the scp-like syntax of SSH remotes, user@host:path
+var scpURLPattern = regexp.MustCompile(`^[A-Za-z0-9._-]+@[A-Za-z0-9.-]+:`)
+
+// isRepositoryURL reports whether an alias target is a remote URL rather
+// than a path
+func isRepositoryURL(target string) bool {
+	return strings.Contains(target, "://") || scpURLPattern.MatchString(target)
+}
+
+// aliasPath returns the path of the repository an alias names. URL aliases
+// are only cloned there by cloneAliases, once the call passed the roots and
+// the registered repositories.
+func (s *Server) aliasPath(name string) (string, error) {
+	path, ok := s.aliases.path(name)
+	if !ok {
+		names := s.aliases.names()
+		if len(names) == 0 {
+			return "", fmt.Errorf("unknown repository alias '%s' (no aliases configured)", name)
+		}
+		return "", fmt.Errorf("unknown repository alias '%s' (available: %s)", name, strings.Join(names, ", "))
+	}
+	return path, nil
+}
+
+// urlAlias returns the URL alias whose clone directory is path
+func (s *Server) urlAlias(path string) (name, url string, ok bool) {
+	for _, name := range s.aliases.names() {
+		target := s.aliases.targets[name]
+		if !isRepositoryURL(target) {
+			continue
+		}
+		if aliasPath, _ := s.aliases.path(name); s.getRepoPath(aliasPath) == path {
+			return name, target, true
+		}
+	}
+	return "", "", false
+}
+
+// cloneAliases wraps the handler of a tool taking repositories so that the
+// URL aliases among them are cloned on first use. It runs inside the roots
+// and the registered repositories, so that calls they refuse clone nothing.
+func (s *Server) cloneAliases(tool mcp.Tool, handler mcp.ToolHandler) mcp.ToolHandler {
+	properties := schemaProperties(tool)
+	_, hasRepoPath := properties["repo_path"]
+	_, hasRepoPaths := properties["repo_paths"]
+	if len(s.aliases.targets) == 0 || (!hasRepoPath && !hasRepoPaths) {
+		return handler
+	}
+
+	return func(ctx context.Context, arguments map[string]interface{}) ([]mcp.TextContent, error) {
+		paths := append([]string{getString(arguments, "repo_path")}, getStringSlice(arguments, "repo_paths")...)
+		for _, path := range paths {
+			if path == "" {
+				continue
+			}
+			if err := s.cloneAlias(ctx, s.getRepoPath(path)); err != nil {
+				return nil, err
+			}
+		}
+		return handler(ctx, arguments)
+	}
+}
+
+// cloneAlias clones the URL alias whose clone directory is path, unless it
+// is cloned already. The clone counts against the clone quota of the
+// session, and is made in a temporary directory moved into place once
+// complete, so that a failed clone leaves nothing to be taken for one.
+func (s *Server) cloneAlias(ctx context.Context, path string) error {
+	name, url, ok := s.urlAlias(path)
+	if !ok {
+		return nil
+	}
+
+	aliasClones.Lock()
+	defer aliasClones.Unlock()
+	if isCloned(path) {
+		return nil
+	}
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("clone directory %s of repository alias '%s' holds no clone; remove it to clone again", path, name)
+	}
+	if err := mcp.CountQuota(ctx, cloneQuota); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create clone directory: %w", err)
+	}
+	tmp, err := os.MkdirTemp(filepath.Dir(path), "."+filepath.Base(path)+"-")
+	if err != nil {
+		return fmt.Errorf("failed to create clone directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+	if _, err := s.git(ctx).Clone(url, tmp, gitops.CloneOptions{}); err != nil {
+		return fmt.Errorf("failed to clone repository alias '%s': %w", name, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to move the clone of repository alias '%s' into place: %w", name, err)
+	}
+	return nil
+}
+
+// applyRepoAliases lets the tools taking a repo_path take the alias of a
+// repository as repo instead, and those taking repo_paths aliases as repos.
+// Aliases are resolved before the session defaults, roots and registered
+// repositories apply to the paths they name, and URL aliases cloned after. A required repo_path, as of
+// git_init and git_clone, is the directory a repository is created in
+// rather than a repository, and takes no alias.
+func (s *Server) applyRepoAliases(tool mcp.Tool, handler mcp.ToolHandler) mcp.ToolHandler {
+	names := s.aliases.names()
+	properties := schemaProperties(tool)
+	_, hasRepoPath := properties["repo_path"]
+	hasRepoPath = hasRepoPath && !schemaRequires(tool, "repo_path")
+	_, hasRepoPaths := properties["repo_paths"]
+	if len(names) == 0 || (!hasRepoPath && !hasRepoPaths) {
+		return handler
+	}
+	if hasRepoPath {
+		properties["repo"] = map[string]interface{}{
+			"type":        "string",
+			"description": "Alias of a configured repository, instead of repo_path",
+			"enum":        names,
+		}
+	}
+	if hasRepoPaths {
+		properties["repos"] = map[string]interface{}{
+			"type":        "array",
+			"description": "Aliases of configured repositories, added to repo_paths",
+			"items":       map[string]interface{}{"type": "string", "enum": names},
+		}
+	}
+
+	return func(ctx context.Context, arguments map[string]interface{}) ([]mcp.TextContent, error) {
+		alias := getString(arguments, "repo")
+		aliases := getStringSlice(arguments, "repos")
+		if alias == "" && len(aliases) == 0 {
+			return handler(ctx, arguments)
+		}
+
+		resolved := make(map[string]interface{}, len(arguments))
+		for key, value := range arguments {
+			if key != "repo" && key != "repos" {
+				resolved[key] = value
+			}
+		}
+		if alias != "" {
+			if getString(arguments, "repo_path") != "" {
+				return nil, &mcp.ToolError{Code: mcp.ErrorCodeInvalidArguments, Err: fmt.Errorf("repo and repo_path cannot be combined")}
+			}
+			path, err := s.aliasPath(alias)
+			if err != nil {
+				return nil, err
+			}
+			resolved["repo_path"] = path
+		}
+		if len(aliases) > 0 {
+			var paths []interface{}
+			for _, path := range getStringSlice(arguments, "repo_paths") {
+				paths = append(paths, path)
+			}
+			for _, alias := range aliases {
+				path, err := s.aliasPath(alias)
+				if err != nil {
+					return nil, err
+				}
+				paths = append(paths, path)
+			}
+			resolved["repo_paths"] = paths
+		}
+		return handler(ctx, resolved)
+	}
+}
+
+// describeAliases lists the aliases with the paths they name, for
+// git_list_repositories
+func (s *Server) describeAliases() []string {
+	var lines []string
+	for _, name := range s.aliases.names() {
+		path, _ := s.aliases.path(name)
+		target := s.aliases.targets[name]
+		switch {
+		case !isRepositoryURL(target):
+			lines = append(lines, fmt.Sprintf("%s: %s", name, path))
+		case isCloned(path):
+			lines = append(lines, fmt.Sprintf("%s: %s (cloned into %s)", name, target, path))
+		default:
+			lines = append(lines, fmt.Sprintf("%s: %s (cloned on first use)", name, target))
+		}
+	}
+	return lines
+}
+
+// isCloned reports whether the clone directory of a URL alias holds a clone
+func isCloned(path string) bool {
+	_, err := os.Stat(filepath.Join(path, ".git"))
+	return err == nil
+}
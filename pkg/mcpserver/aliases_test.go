@@ -0,0 +1,129 @@
+package mcpserver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/pengcunfu/go-mcp-git/pkg/gitops"
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+func TestApplyRepoAliases(t *testing.T) {
+	s := &Server{aliases: newRepoAliases(map[string]string{"app": "/src/app", "lib": "/src/lib"}, "")}
+	var received map[string]interface{}
+	handler := func(ctx context.Context, arguments map[string]interface{}) ([]mcp.TextContent, error) {
+		received = arguments
+		return nil, nil
+	}
+	ctx := context.Background()
+
+	status := mcp.Tool{Name: "git_status", InputSchema: mcp.SchemaFor[gitops.GitStatus]()}
+	aliased := s.applyRepoAliases(status, handler)
+	if _, ok := schemaProperties(status)["repo"]; !ok {
+		t.Errorf("Expected git_status to take repo")
+	}
+
+	if _, err := aliased(ctx, map[string]interface{}{"repo": "app", "include_ignored": true}); err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+	expected := map[string]interface{}{"repo_path": "/src/app", "include_ignored": true}
+	if !reflect.DeepEqual(received, expected) {
+		t.Errorf("Expected the alias resolved to repo_path, got %v", received)
+	}
+
+	if _, err := aliased(ctx, map[string]interface{}{"repo_path": "/other"}); err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+	if received["repo_path"] != "/other" {
+		t.Errorf("Expected repo_path to pass through without repo, got %v", received)
+	}
+
+	received = nil
+	if _, err := aliased(ctx, map[string]interface{}{"repo": "app", "repo_path": "/other"}); err == nil {
+		t.Errorf("Expected repo and repo_path to be rejected together")
+	}
+	if _, err := aliased(ctx, map[string]interface{}{"repo": "unknown"}); err == nil {
+		t.Errorf("Expected an unknown alias to be rejected")
+	}
+	if received != nil {
+		t.Errorf("Expected rejected calls not to run the handler")
+	}
+
+	multi := mcp.Tool{Name: "git_multi_status", InputSchema: mcp.SchemaFor[gitops.GitMultiStatus]()}
+	aliased = s.applyRepoAliases(multi, handler)
+	if _, err := aliased(ctx, map[string]interface{}{"repo_paths": []interface{}{"/other"}, "repos": []interface{}{"lib"}}); err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+	if paths := received["repo_paths"]; !reflect.DeepEqual(paths, []interface{}{"/other", "/src/lib"}) {
+		t.Errorf("Expected the aliases added to repo_paths, got %v", paths)
+	}
+
+	// The repo_path of git_init and git_clone is the directory to create
+	for _, tool := range []mcp.Tool{
+		{Name: "git_init", InputSchema: mcp.SchemaFor[gitops.GitInit]()},
+		{Name: "git_clone", InputSchema: mcp.SchemaFor[gitops.GitClone]()},
+	} {
+		s.applyRepoAliases(tool, handler)
+		if _, ok := schemaProperties(tool)["repo"]; ok {
+			t.Errorf("Expected %s not to take repo", tool.Name)
+		}
+	}
+}
+
+func TestCloneAliases(t *testing.T) {
+	ops := gitops.NewOperations("Test User", "test@example.com")
+	upstream := t.TempDir()
+	if _, err := ops.Init(upstream, false); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	if _, err := ops.Commit(upstream, "Initial", gitops.CommitOptions{AllowEmpty: true}); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	cloneDir := t.TempDir()
+	s := &Server{
+		gitOps: ops,
+		aliases: newRepoAliases(map[string]string{
+			"up":      "file://" + filepath.ToSlash(upstream),
+			"missing": "file://" + filepath.ToSlash(filepath.Join(upstream, "missing")),
+		}, cloneDir),
+	}
+	calls := 0
+	status := mcp.Tool{Name: "git_status", InputSchema: mcp.SchemaFor[gitops.GitStatus]()}
+	cloning := s.cloneAliases(status, func(ctx context.Context, arguments map[string]interface{}) ([]mcp.TextContent, error) {
+		calls++
+		return nil, nil
+	})
+	ctx := context.Background()
+
+	path, _ := s.aliases.path("up")
+	for i := 0; i < 2; i++ {
+		if _, err := cloning(ctx, map[string]interface{}{"repo_path": path}); err != nil {
+			t.Fatalf("Call failed: %v", err)
+		}
+	}
+	if !isCloned(path) || calls != 2 {
+		t.Errorf("Expected the alias cloned on first use, cloned %v after %d calls", isCloned(path), calls)
+	}
+
+	// A failed clone leaves neither the clone directory nor its temporary one
+	missing, _ := s.aliases.path("missing")
+	if _, err := cloning(ctx, map[string]interface{}{"repo_path": missing}); err == nil {
+		t.Errorf("Expected the clone of a missing repository to fail")
+	}
+	if entries, _ := os.ReadDir(cloneDir); len(entries) != 1 {
+		t.Errorf("Expected only the first clone in the clone directory, got %v", entries)
+	}
+
+	if err := os.Mkdir(missing, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if _, err := cloning(ctx, map[string]interface{}{"repo_path": missing}); err == nil {
+		t.Errorf("Expected a clone directory holding no clone to be rejected")
+	}
+	if calls != 2 {
+		t.Errorf("Expected failed clones not to run the handler")
+	}
+}
@@ -0,0 +1,34 @@
+package mcpserver
+
+import (
+	"context"
+
+	"github.com/pengcunfu/go-mcp-git/pkg/gitops"
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+// registerApplyTools registers tools for editing files with patches
+func (s *Server) registerApplyTools() {
+	registerTypedTool(s, "git_apply",
+		"Applies a unified diff to the working tree or the index, reporting the hunks that do not apply",
+		s.handleGitApply)
+}
+
+func (s *Server) handleGitApply(ctx context.Context, params gitops.GitApply) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	result, err := s.git(ctx).Apply(repoPath, params.Patch, gitops.ApplyOptions{
+		Cached:   params.Cached,
+		ThreeWay: params.ThreeWay,
+		Reject:   params.Reject,
+		Check:    params.Check,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
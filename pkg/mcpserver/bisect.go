@@ -0,0 +1,37 @@
+package mcpserver
+
+import (
+	"context"
+
+	"github.com/pengcunfu/go-mcp-git/pkg/gitops"
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+// registerBisectTools registers tools finding the commit that introduced a
+// bug
+func (s *Server) registerBisectTools() {
+	registerTypedTool(s, "git_bisect",
+		"Binary searches history for the commit that introduced a bug, step by step or automatically with a test command",
+		s.handleGitBisect)
+}
+
+func (s *Server) handleGitBisect(ctx context.Context, params gitops.GitBisect) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	result, err := s.git(ctx).Bisect(repoPath, gitops.BisectOptions{
+		Action:    params.Action,
+		Bad:       params.Bad,
+		Good:      params.Good,
+		Revisions: params.Revisions,
+		Paths:     params.Paths,
+		Command:   params.Command,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
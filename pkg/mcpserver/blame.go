@@ -0,0 +1,29 @@
+package mcpserver
+
+import (
+	"context"
+
+	"github.com/pengcunfu/go-mcp-git/pkg/gitops"
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+// registerBlameTools registers tools summarizing line authorship
+func (s *Server) registerBlameTools() {
+	registerTypedTool(s, "git_blame_heat",
+		"Summarizes how stale or actively edited a file is: the share of its lines last modified in each month, quarter or year, and the newest, median and oldest line age",
+		s.handleGitBlameHeat)
+}
+
+func (s *Server) handleGitBlameHeat(ctx context.Context, params gitops.GitBlameHeat) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	result, err := s.git(ctx).BlameHeat(repoPath, params.Path, params.Revision, params.Bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
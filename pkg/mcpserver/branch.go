@@ -0,0 +1,83 @@
+package mcpserver
+
+import (
+	"context"
+
+	"github.com/pengcunfu/go-mcp-git/pkg/gitops"
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+// registerBranchTools registers tools for branch maintenance
+func (s *Server) registerBranchTools() {
+	registerTypedTool(s, "git_delete_branch",
+		"Deletes a local branch (refuses the current branch and unmerged branches unless forced)",
+		s.handleGitDeleteBranch)
+
+	registerTypedTool(s, "git_current_branch",
+		"Shows the checked out branch, the HEAD commit, whether HEAD is detached, and the upstream branch with ahead/behind counts",
+		s.handleGitCurrentBranch)
+
+	registerTypedTool(s, "git_rename_branch",
+		"Renames a local branch, keeping its upstream configuration",
+		s.handleGitRenameBranch)
+
+	registerTypedTool(s, "git_issue_from_branch",
+		"Extracts the issue identifier from a branch name and suggests commit message prefixes and trailers referencing it",
+		s.handleGitIssueFromBranch)
+}
+
+func (s *Server) handleGitDeleteBranch(ctx context.Context, params gitops.GitDeleteBranch) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	result, err := s.git(ctx).DeleteBranch(repoPath, params.BranchName, params.Force)
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
+
+func (s *Server) handleGitCurrentBranch(ctx context.Context, params gitops.GitCurrentBranch) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	result, err := s.git(ctx).CurrentBranch(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
+
+func (s *Server) handleGitRenameBranch(ctx context.Context, params gitops.GitRenameBranch) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	result, err := s.git(ctx).RenameBranch(repoPath, params.OldName, params.NewName, params.Force)
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
+
+func (s *Server) handleGitIssueFromBranch(ctx context.Context, params gitops.GitIssueFromBranch) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	result, err := s.git(ctx).IssueFromBranch(repoPath, params.Branch, params.Patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
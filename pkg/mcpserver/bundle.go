@@ -0,0 +1,48 @@
+package mcpserver
+
+import (
+	"context"
+
+	"github.com/pengcunfu/go-mcp-git/pkg/gitops"
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+// registerBundleTools registers tools transferring history as bundle files,
+// for offline transfers and backups
+func (s *Server) registerBundleTools() {
+	registerTypedTool(s, "git_bundle_create",
+		"Writes history to a bundle file, which git_clone and git_fetch (with bundle) read back without network access",
+		s.handleGitBundleCreate)
+
+	registerTypedTool(s, "git_bundle_verify",
+		"Checks a bundle file and whether the repository has the commits it requires, listing its references",
+		s.handleGitBundleVerify)
+}
+
+func (s *Server) handleGitBundleCreate(ctx context.Context, params gitops.GitBundleCreate) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	result, err := s.git(ctx).BundleCreate(repoPath, params.File, params.Revisions)
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
+
+func (s *Server) handleGitBundleVerify(ctx context.Context, params gitops.GitBundleVerify) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	result, err := s.git(ctx).BundleVerify(repoPath, params.File)
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
@@ -0,0 +1,84 @@
+package mcpserver
+
+import (
+	"context"
+
+	"github.com/pengcunfu/go-mcp-git/pkg/gitops"
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+// registerCompareTools registers tools comparing repository content with
+// other trees, branches or directories, and importing external directories
+func (s *Server) registerCompareTools() {
+	registerTypedTool(s, "git_diff_directory",
+		"Diffs a revision against an external directory, reporting added, removed and modified files with patches",
+		s.handleGitDiffDirectory)
+
+	registerTypedTool(s, "git_import_tree",
+		"Snapshots an external directory into the repository as a commit on a branch",
+		s.handleGitImportTree)
+
+	registerTypedTool(s, "git_cherry",
+		"Finds equivalent patches (e.g. cherry-picks) between two branches by patch ID and lists the commits still missing on either side",
+		s.handleGitCherry)
+
+	registerTypedTool(s, "git_compare_refs",
+		"Counts how many commits one ref is ahead of and behind another and reports their merge base, e.g. to check whether a branch is up to date with main",
+		s.handleGitCompareRefs)
+}
+
+func (s *Server) handleGitDiffDirectory(ctx context.Context, params gitops.GitDiffDirectory) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	result, err := s.git(ctx).DiffDirectory(repoPath, params.Revision, params.Directory, params.Options())
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
+
+func (s *Server) handleGitImportTree(ctx context.Context, params gitops.GitImportTree) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	result, err := s.git(ctx).ImportTree(repoPath, params.Directory, params.Branch, params.Prefix, params.Mode, params.Message, params.Ignore)
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
+
+func (s *Server) handleGitCherry(ctx context.Context, params gitops.GitCherry) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	result, err := s.git(ctx).FindEquivalentCommits(repoPath, params.Upstream, params.Head)
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
+
+func (s *Server) handleGitCompareRefs(ctx context.Context, params gitops.GitCompareRefs) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	result, err := s.git(ctx).CompareRefs(repoPath, params.Base, params.Head)
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
@@ -0,0 +1,159 @@
+package mcpserver
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+// Kinds of completed arguments
+const (
+	completeRepositories = "repositories"
+	completeAliases      = "aliases"
+	completeBranches     = "branches"
+	completeTags         = "tags"
+	completeRevisions    = "revisions"
+)
+
+// completedArguments maps the arguments with completions to the kind of
+// their values. Revisions complete with local and remote-tracking branches
+// and tags.
+var completedArguments = map[string]string{
+	"repo_path":   completeRepositories,
+	"repository":  completeRepositories,
+	"repo":        completeAliases,
+	"repos":       completeAliases,
+	"branch_name": completeBranches,
+	"branch":      completeBranches,
+	"base_branch": completeBranches,
+	"old_name":    completeBranches,
+	"tag_name":    completeTags,
+	"revision":    completeRevisions,
+	"target":      completeRevisions,
+	"ref":         completeRevisions,
+	"from":        completeRevisions,
+	"to":          completeRevisions,
+	"upstream":    completeRevisions,
+}
+
+// complete handles completion/complete for the tools of this instance.
+// References to tools of other instances are left to them, references to
+// prompts or resources complete like tool arguments of the same name.
+// Completion is best effort: values that cannot be listed, e.g. the
+// branches of a path that is not a repository, complete with nothing.
+func (s *Server) complete(ctx context.Context, request mcp.CompleteRequest) ([]string, bool, error) {
+	if request.Ref.Type == mcp.RefTypeTool {
+		tool, ok := s.tools[request.Ref.Name]
+		if !ok {
+			return nil, false, nil
+		}
+		if _, declared := schemaProperties(tool)[request.Argument.Name]; !declared {
+			return nil, true, nil
+		}
+	}
+	given := map[string]string{}
+	if request.Context != nil && request.Context.Arguments != nil {
+		given = request.Context.Arguments
+	}
+	if given["repo_path"] == "" {
+		given["repo_path"] = defaultsOf(ctx).repoPath
+	}
+
+	var values []string
+	var err error
+	switch completedArguments[request.Argument.Name] {
+	case completeRepositories:
+		values = s.repositoryCandidates(ctx)
+	case completeAliases:
+		values = s.aliases.names()
+	case completeBranches:
+		values, err = s.git(ctx).BranchNames(s.getRepoPath(given["repo_path"]), false)
+	case completeTags:
+		values, err = s.git(ctx).TagNames(s.getRepoPath(given["repo_path"]))
+	case completeRevisions:
+		repoPath := s.getRepoPath(given["repo_path"])
+		values, err = s.git(ctx).BranchNames(repoPath, true)
+		if err == nil {
+			var tags []string
+			tags, err = s.git(ctx).TagNames(repoPath)
+			values = append(values, tags...)
+		}
+	}
+	if err != nil {
+		slog.Debug("Completion failed", "argument", request.Argument.Name, "error", err)
+		return nil, true, nil
+	}
+
+	matches := make([]string, 0, len(values))
+	for _, value := range values {
+		if strings.HasPrefix(value, request.Argument.Value) {
+			matches = append(matches, value)
+		}
+	}
+	return matches, true, nil
+}
+
+// repositoryCandidates lists the repositories repo_path may name: the
+// registered ones, and the repositories in or directly below the workspace
+// roots of the client, or the current directory without roots. Restricted
+// instances only offer the registered repositories.
+func (s *Server) repositoryCandidates(ctx context.Context) []string {
+	candidates := s.registeredRepositories()
+	if s.repository != "" {
+		candidates = append(candidates, s.getRepoPath(s.repository))
+	}
+
+	var roots []string
+	if !s.ignoreRoots {
+		var err error
+		if roots, err = s.rootPaths(ctx); err != nil {
+			slog.Debug("Completion without roots", "error", err)
+		}
+	}
+	if !s.restrictRepositories {
+		dirs := roots
+		if len(dirs) == 0 {
+			if cwd, err := os.Getwd(); err == nil {
+				dirs = []string{cwd}
+			}
+		}
+		for _, dir := range dirs {
+			candidates = append(candidates, s.discoverRepositories(dir)...)
+		}
+	}
+
+	seen := make(map[string]bool, len(candidates))
+	repos := make([]string, 0, len(candidates))
+	for _, repo := range candidates {
+		if seen[repo] || (len(roots) > 0 && !pathWithin(repo, roots)) {
+			continue
+		}
+		seen[repo] = true
+		repos = append(repos, repo)
+	}
+	sort.Strings(repos)
+	return repos
+}
+
+// discoverRepositories lists dir when it is a repository, and the
+// repositories directly below it
+func (s *Server) discoverRepositories(dir string) []string {
+	repos, _ := s.gitOps.ListRepositories(dir, false)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return repos
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		found, _ := s.gitOps.ListRepositories(filepath.Join(dir, entry.Name()), false)
+		repos = append(repos, found...)
+	}
+	return repos
+}
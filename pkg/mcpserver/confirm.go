@@ -0,0 +1,341 @@
+package mcpserver
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pengcunfu/go-mcp-git/pkg/gitops"
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+// confirmationTTL is how long a confirmation token stays valid
+const confirmationTTL = 5 * time.Minute
+
+// maxPendingConfirmations caps the tokens waiting to be echoed back, the
+// oldest being dropped first
+const maxPendingConfirmations = 64
+
+// destructiveTool describes a tool whose calls may lose work. Such calls
+// happen in two phases: the first one only returns a preview and a
+// confirmation token, and the action runs when the same call is repeated
+// with the token.
+type destructiveTool struct {
+	// applies reports whether a call is destructive, all calls when nil. s
+	// is nil when calls are matched against the quotas, outside the
+	// instances.
+	applies func(s *Server, arguments map[string]interface{}) bool
+	// describe summarizes what the call will do
+	describe func(s *Server, arguments map[string]interface{}) string
+	// preview shows what will be lost, handler being the tool itself
+	preview func(ctx context.Context, s *Server, handler mcp.ToolHandler, arguments map[string]interface{}) (string, error)
+}
+
+// destructiveTools are the tools requiring a confirmation token
+var destructiveTools = map[string]destructiveTool{
+	"git_reset": {
+		applies: func(s *Server, arguments map[string]interface{}) bool {
+			return getString(arguments, "mode") == "hard" && len(getStringSlice(arguments, "files")) == 0
+		},
+		describe: func(s *Server, arguments map[string]interface{}) string {
+			return fmt.Sprintf("hard reset of %s to %s, discarding all uncommitted changes", s.getRepoPath(getString(arguments, "repo_path")), revisionOrHead(getString(arguments, "target")))
+		},
+		preview: func(ctx context.Context, s *Server, handler mcp.ToolHandler, arguments map[string]interface{}) (string, error) {
+			return s.git(ctx).Status(s.getRepoPath(getString(arguments, "repo_path")), false)
+		},
+	},
+	"git_push": {
+		applies: func(s *Server, arguments map[string]interface{}) bool {
+			if getBool(arguments, "dry_run", false) {
+				return false
+			}
+			return getBool(arguments, "force", false) || getBool(arguments, "force_with_lease", false) ||
+				getBool(arguments, "mirror", false) || getString(arguments, "delete") != ""
+		},
+		describe: func(s *Server, arguments map[string]interface{}) string {
+			remote := getString(arguments, "remote")
+			if remote == "" {
+				remote = "origin"
+			}
+			switch {
+			case getString(arguments, "delete") != "":
+				return fmt.Sprintf("deletion of branch %s on %s", getString(arguments, "delete"), remote)
+			case getBool(arguments, "mirror", false):
+				return fmt.Sprintf("mirror push to %s, overwriting and deleting its references to match the local ones", remote)
+			}
+			return fmt.Sprintf("force push to %s, overwriting remote commits the pushed branches do not contain", remote)
+		},
+		preview: func(ctx context.Context, s *Server, handler mcp.ToolHandler, arguments map[string]interface{}) (string, error) {
+			// The dry run reports what the push would update
+			dryRun := make(map[string]interface{}, len(arguments)+1)
+			for key, value := range arguments {
+				dryRun[key] = value
+			}
+			dryRun["dry_run"] = true
+			content, err := handler(ctx, dryRun)
+			if err != nil {
+				return "", err
+			}
+			return joinContent(content), nil
+		},
+	},
+	"git_delete_branch": {
+		describe: func(s *Server, arguments map[string]interface{}) string {
+			return fmt.Sprintf("deletion of branch %s", getString(arguments, "branch_name"))
+		},
+		preview: func(ctx context.Context, s *Server, handler mcp.ToolHandler, arguments map[string]interface{}) (string, error) {
+			return s.git(ctx).CompareRefs(s.getRepoPath(getString(arguments, "repo_path")), "HEAD", getString(arguments, "branch_name"))
+		},
+	},
+	"git_rewrite_history": {
+		describe: func(s *Server, arguments map[string]interface{}) string {
+			return fmt.Sprintf("rewrite of the last %d commit(s) of %s", rewritePlanLength(arguments), branchOrCurrent(getString(arguments, "branch")))
+		},
+		preview: func(ctx context.Context, s *Server, handler mcp.ToolHandler, arguments map[string]interface{}) (string, error) {
+			return previewLog(ctx, s, arguments, gitops.LogOptions{
+				Range:    getString(arguments, "branch"),
+				MaxCount: rewritePlanLength(arguments),
+			})
+		},
+	},
+	"git_squash_branch": {
+		describe: func(s *Server, arguments map[string]interface{}) string {
+			return fmt.Sprintf("squash of the current branch since its merge base with %s into a single commit", getString(arguments, "target"))
+		},
+		preview: func(ctx context.Context, s *Server, handler mcp.ToolHandler, arguments map[string]interface{}) (string, error) {
+			return previewLog(ctx, s, arguments, gitops.LogOptions{From: getString(arguments, "target")})
+		},
+	},
+	"git_raw_command": {
+		applies: func(s *Server, arguments map[string]interface{}) bool {
+			dir := getString(arguments, "repo_path")
+			if s != nil {
+				dir = s.getRepoPath(dir)
+			}
+			return isDestructiveRawCommand(dir, getString(arguments, "command"))
+		},
+		describe: func(s *Server, arguments map[string]interface{}) string {
+			return fmt.Sprintf("raw command '%s' in %s, which may discard work", getString(arguments, "command"), s.getRepoPath(getString(arguments, "repo_path")))
+		},
+		preview: func(ctx context.Context, s *Server, handler mcp.ToolHandler, arguments map[string]interface{}) (string, error) {
+			return s.git(ctx).Status(s.getRepoPath(getString(arguments, "repo_path")), false)
+		},
+	},
+}
+
+// rewritePlanLength counts the steps of the plan of git_rewrite_history
+func rewritePlanLength(arguments map[string]interface{}) int {
+	plan, _ := arguments["plan"].([]interface{})
+	return len(plan)
+}
+
+// previewLog lists the commits a call rewrites, one line each
+func previewLog(ctx context.Context, s *Server, arguments map[string]interface{}, opts gitops.LogOptions) (string, error) {
+	opts.Graph = true
+	commits, err := s.git(ctx).Log(s.getRepoPath(getString(arguments, "repo_path")), opts)
+	if err != nil {
+		return "", err
+	}
+	if len(commits) == 0 {
+		return "No commits affected", nil
+	}
+	return fmt.Sprintf("Commits rewritten (%d):\n%s", len(commits), strings.Join(commits, "\n")), nil
+}
+
+// revisionOrHead names a revision argument, HEAD when empty
+func revisionOrHead(revision string) string {
+	if revision == "" {
+		return "HEAD"
+	}
+	return revision
+}
+
+// branchOrCurrent names a branch argument, the current branch when empty
+func branchOrCurrent(branch string) string {
+	if branch == "" {
+		return "the current branch"
+	}
+	return branch
+}
+
+// joinContent joins the text of tool results
+func joinContent(content []mcp.TextContent) string {
+	texts := make([]string, 0, len(content))
+	for _, c := range content {
+		texts = append(texts, c.Text)
+	}
+	return strings.Join(texts, "\n")
+}
+
+// pendingConfirmation is a destructive call waiting for its token
+type pendingConfirmation struct {
+	session string
+	tool    string
+	digest  string
+	expires time.Time
+}
+
+// confirmations holds the tokens issued for destructive calls. A token
+// only confirms the call it was issued for, once, in the session it was
+// issued to.
+type confirmations struct {
+	mu      sync.Mutex
+	pending map[string]pendingConfirmation
+	order   []string
+}
+
+func newConfirmations() *confirmations {
+	return &confirmations{pending: make(map[string]pendingConfirmation)}
+}
+
+// issue returns a new token for a call of a session
+func (c *confirmations) issue(session, tool, digest string) (string, error) {
+	raw := make([]byte, 12)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate confirmation token: %w", err)
+	}
+	token := hex.EncodeToString(raw)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.prune(time.Now())
+	for len(c.order) >= maxPendingConfirmations {
+		delete(c.pending, c.order[0])
+		c.order = c.order[1:]
+	}
+	c.pending[token] = pendingConfirmation{session: session, tool: tool, digest: digest, expires: time.Now().Add(confirmationTTL)}
+	c.order = append(c.order, token)
+	return token, nil
+}
+
+// consume checks a token against a call of a session, invalidating it when
+// it matches. The tokens of other sessions are unknown to the session.
+func (c *confirmations) consume(token, session, tool, digest string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.prune(time.Now())
+
+	pending, ok := c.pending[token]
+	if !ok || pending.session != session {
+		return fmt.Errorf("unknown or expired confirmation token, call %s without confirmation_token for a new one", tool)
+	}
+	if pending.tool != tool || pending.digest != digest {
+		return fmt.Errorf("confirmation token was issued for a different call, repeat the previewed call with exactly the same arguments or request a new token")
+	}
+	delete(c.pending, token)
+	for i, t := range c.order {
+		if t == token {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// prune drops the expired tokens
+func (c *confirmations) prune(now time.Time) {
+	kept := c.order[:0]
+	for _, token := range c.order {
+		if now.After(c.pending[token].expires) {
+			delete(c.pending, token)
+			continue
+		}
+		kept = append(kept, token)
+	}
+	c.order = kept
+}
+
+// sessionID returns the ID of the session of a call, empty outside sessions
+func sessionID(ctx context.Context) string {
+	if session := mcp.SessionFromContext(ctx); session != nil {
+		return session.ID()
+	}
+	return ""
+}
+
+// callDigest identifies the arguments of a call, the confirmation token
+// aside
+func callDigest(arguments map[string]interface{}) (string, error) {
+	rest := make(map[string]interface{}, len(arguments))
+	for key, value := range arguments {
+		if key != "confirmation_token" {
+			rest[key] = value
+		}
+	}
+	// Maps marshal with sorted keys
+	encoded, err := json.Marshal(rest)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode arguments: %w", err)
+	}
+	return string(encoded), nil
+}
+
+// confirmedKey marks the context of a confirmed destructive call
+type confirmedKey struct{}
+
+// isConfirmed reports whether a call was confirmed with its token
+func isConfirmed(ctx context.Context) bool {
+	confirmed, _ := ctx.Value(confirmedKey{}).(bool)
+	return confirmed
+}
+
+// requireConfirmation wraps the handler of a destructive tool so that its
+// destructive calls only run when repeated with the token of their preview
+func (s *Server) requireConfirmation(tool mcp.Tool, destructive destructiveTool, handler mcp.ToolHandler) mcp.ToolHandler {
+	name := s.toolPrefix + tool.Name
+	return func(ctx context.Context, arguments map[string]interface{}) ([]mcp.TextContent, error) {
+		if destructive.applies != nil && !destructive.applies(s, arguments) {
+			return handler(ctx, arguments)
+		}
+		digest, err := callDigest(arguments)
+		if err != nil {
+			return nil, err
+		}
+
+		session := sessionID(ctx)
+		if token := getString(arguments, "confirmation_token"); token != "" {
+			if err := s.confirmations.consume(token, session, name, digest); err != nil {
+				return nil, err
+			}
+			return handler(context.WithValue(ctx, confirmedKey{}, true), arguments)
+		}
+
+		preview, err := destructive.preview(ctx, s, handler, arguments)
+		if err != nil {
+			return nil, fmt.Errorf("failed to preview %s: %w", name, err)
+		}
+		token, err := s.confirmations.issue(session, name, digest)
+		if err != nil {
+			return nil, err
+		}
+
+		return []mcp.TextContent{{
+			Type: "text",
+			Text: fmt.Sprintf("Confirmation required for the %s. Nothing was changed yet.\n\n%s\n\nTo proceed, call %s again with the same arguments and confirmation_token %q within %d minutes.",
+				destructive.describe(s, arguments), strings.TrimSpace(preview), name, token, int(confirmationTTL.Minutes())),
+		}}, nil
+	}
+}
+
+// addConfirmationProperty declares the confirmation_token argument in the
+// schema of a destructive tool
+func addConfirmationProperty(tool *mcp.Tool) {
+	tool.Description += ". Destructive calls first return a preview and a confirmation token, and only run when repeated with it"
+	schema, ok := tool.InputSchema.(map[string]interface{})
+	if !ok {
+		return
+	}
+	properties, ok := schema["properties"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	properties["confirmation_token"] = map[string]interface{}{
+		"type":        "string",
+		"description": "Token returned by the preview of this exact call, confirming it",
+	}
+}
@@ -0,0 +1,214 @@
+package mcpserver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+func TestConfirmations_SingleUse(t *testing.T) {
+	c := newConfirmations()
+	token, err := c.issue("session", "git_reset", "digest")
+	if err != nil {
+		t.Fatalf("issue failed: %v", err)
+	}
+
+	if err := c.consume(token, "session", "git_reset", "digest"); err != nil {
+		t.Fatalf("Expected the token to confirm its call, got: %v", err)
+	}
+	if err := c.consume(token, "session", "git_reset", "digest"); err == nil {
+		t.Errorf("Expected a consumed token to be rejected")
+	}
+}
+
+func TestConfirmations_DigestMismatch(t *testing.T) {
+	c := newConfirmations()
+	token, err := c.issue("session", "git_reset", "digest")
+	if err != nil {
+		t.Fatalf("issue failed: %v", err)
+	}
+
+	if err := c.consume(token, "session", "git_reset", "other"); err == nil {
+		t.Errorf("Expected a token to be rejected for other arguments")
+	}
+	if err := c.consume(token, "session", "git_push", "digest"); err == nil {
+		t.Errorf("Expected a token to be rejected for another tool")
+	}
+	if err := c.consume(token, "other session", "git_reset", "digest"); err == nil {
+		t.Errorf("Expected a token to be rejected in another session")
+	}
+	// A mismatch does not use the token up
+	if err := c.consume(token, "session", "git_reset", "digest"); err != nil {
+		t.Errorf("Expected the token to still confirm its call, got: %v", err)
+	}
+}
+
+func TestConfirmations_Expiry(t *testing.T) {
+	c := newConfirmations()
+	token, err := c.issue("session", "git_reset", "digest")
+	if err != nil {
+		t.Fatalf("issue failed: %v", err)
+	}
+	pending := c.pending[token]
+	pending.expires = time.Now().Add(-time.Second)
+	c.pending[token] = pending
+
+	if err := c.consume(token, "session", "git_reset", "digest"); err == nil {
+		t.Errorf("Expected an expired token to be rejected")
+	}
+	if len(c.pending) != 0 || len(c.order) != 0 {
+		t.Errorf("Expected the expired token to be pruned, %d pending", len(c.pending))
+	}
+}
+
+func TestConfirmations_Eviction(t *testing.T) {
+	c := newConfirmations()
+	var tokens []string
+	for i := 0; i <= maxPendingConfirmations; i++ {
+		token, err := c.issue("session", "git_reset", "digest")
+		if err != nil {
+			t.Fatalf("issue failed: %v", err)
+		}
+		tokens = append(tokens, token)
+	}
+
+	if len(c.pending) != maxPendingConfirmations {
+		t.Errorf("Expected %d pending tokens, got %d", maxPendingConfirmations, len(c.pending))
+	}
+	if err := c.consume(tokens[0], "session", "git_reset", "digest"); err == nil {
+		t.Errorf("Expected the oldest token to be evicted")
+	}
+	if err := c.consume(tokens[1], "session", "git_reset", "digest"); err != nil {
+		t.Errorf("Expected the second token to be kept, got: %v", err)
+	}
+	if err := c.consume(tokens[maxPendingConfirmations], "session", "git_reset", "digest"); err != nil {
+		t.Errorf("Expected the newest token to be kept, got: %v", err)
+	}
+}
+
+var tokenPattern = regexp.MustCompile(`confirmation_token "([0-9a-f]+)"`)
+
+func TestRequireConfirmation(t *testing.T) {
+	s := &Server{confirmations: newConfirmations()}
+	destructive := destructiveTool{
+		applies: func(s *Server, arguments map[string]interface{}) bool {
+			return getBool(arguments, "force", false)
+		},
+		describe: func(s *Server, arguments map[string]interface{}) string {
+			return "test action"
+		},
+		preview: func(ctx context.Context, s *Server, handler mcp.ToolHandler, arguments map[string]interface{}) (string, error) {
+			return "test preview", nil
+		},
+	}
+	calls, confirmed := 0, false
+	handler := s.requireConfirmation(mcp.Tool{Name: "test_tool"}, destructive, func(ctx context.Context, arguments map[string]interface{}) ([]mcp.TextContent, error) {
+		calls++
+		confirmed = isConfirmed(ctx)
+		return []mcp.TextContent{{Type: "text", Text: "done"}}, nil
+	})
+	ctx := context.Background()
+
+	// Calls the tool does not consider destructive run directly
+	if _, err := handler(ctx, map[string]interface{}{"force": false}); err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+	if calls != 1 || confirmed {
+		t.Fatalf("Expected a non-destructive call to run unconfirmed, %d calls", calls)
+	}
+
+	arguments := map[string]interface{}{"force": true}
+	content, err := handler(ctx, arguments)
+	if err != nil {
+		t.Fatalf("Preview failed: %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("Expected the preview not to run the handler")
+	}
+	match := tokenPattern.FindStringSubmatch(joinContent(content))
+	if match == nil {
+		t.Fatalf("Expected a confirmation token in the preview, got: %s", joinContent(content))
+	}
+
+	if _, err := handler(ctx, map[string]interface{}{"force": true, "other": 1, "confirmation_token": match[1]}); err == nil {
+		t.Errorf("Expected the token to be rejected for other arguments")
+	}
+	if calls != 1 {
+		t.Fatalf("Expected a rejected call not to run the handler")
+	}
+
+	if _, err := handler(ctx, map[string]interface{}{"force": true, "confirmation_token": match[1]}); err != nil {
+		t.Fatalf("Confirmed call failed: %v", err)
+	}
+	if calls != 2 || !confirmed {
+		t.Errorf("Expected the confirmed call to run once, confirmed, %d calls", calls)
+	}
+	if _, err := handler(ctx, map[string]interface{}{"force": true, "confirmation_token": match[1]}); err == nil {
+		t.Errorf("Expected the token to be single use")
+	}
+}
+
+func TestIsDestructiveRawCommand(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "src"), 0755); err != nil {
+		t.Fatalf("Failed to create src: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "src", "file.go"), []byte("package src\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file.go: %v", err)
+	}
+
+	tests := []struct {
+		command     string
+		destructive bool
+	}{
+		{"git status", false},
+		{"git log --oneline -5", false},
+		{"git tag -a v1 -m release", false},
+		{"git reset --soft HEAD~1", false},
+		{"git reset --hard", true},
+		{"git -C /tmp/repo reset --hard HEAD~1", true},
+		{"git -c core.pager=cat reset --hard", true},
+		{"git push origin main", false},
+		{"git push --force origin main", true},
+		{"git push -fu origin main", true},
+		{"git push --force-with-lease=main origin main", true},
+		{"git push origin +main", true},
+		{"git push origin :main", true},
+		{"git push --delete origin main", true},
+		{"git push --mirror backup", true},
+		{"git clean -n -d", false},
+		{"git clean -fdx", true},
+		{"git branch feature", false},
+		{"git branch -D feature", true},
+		{"git branch --delete feature", true},
+		{"git tag -d v1", true},
+		{"git checkout main", false},
+		{"git checkout -f main", true},
+		{"git checkout -- file.txt", true},
+		{"git checkout src/file.go", true},
+		{"git checkout src", true},
+		{"git checkout main src/file.go", true},
+		{"git checkout *.go", true},
+		{"git -C src checkout file.go", true},
+		{"git checkout -b feature origin/feature", false},
+		{"git checkout --track origin/feature", false},
+		{"git restore --staged file.txt", false},
+		{"git restore file.txt", true},
+		{"git stash list", false},
+		{"git stash drop", true},
+		{"git stash clear", true},
+		{"git reflog expire --expire=now --all", true},
+		{"git filter-branch --tree-filter true", true},
+		{"git -c alias.st=status st", true},
+	}
+	for _, tt := range tests {
+		if got := isDestructiveRawCommand(dir, tt.command); got != tt.destructive {
+			t.Errorf("isDestructiveRawCommand(%q) = %v, expected %v", tt.command, got, tt.destructive)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package mcpserver
+
+import (
+	"context"
+
+	"github.com/pengcunfu/go-mcp-git/pkg/gitops"
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+// registerConflictTools registers tools inspecting an interrupted merge,
+// rebase, cherry-pick or revert
+func (s *Server) registerConflictTools() {
+	registerTypedTool(s, "git_conflicts",
+		"Lists the conflicted files of an in-progress merge, rebase, cherry-pick or revert with the kind of conflict, the blobs of each side and whether conflict markers are left",
+		s.handleGitConflicts)
+}
+
+func (s *Server) handleGitConflicts(ctx context.Context, params gitops.GitConflicts) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	result, err := s.git(ctx).Conflicts(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
@@ -0,0 +1,129 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/pengcunfu/go-mcp-git/pkg/gitops"
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+// DefaultDiscoveryDepth is the default number of directory levels below a
+// workspace searched for repositories
+const DefaultDiscoveryDepth = gitops.DefaultDiscoveryDepth
+
+// discoverWorkspaces finds the repositories of the workspaces scanned at
+// startup. Workspaces that cannot be read are logged and skipped, so that
+// a missing mount does not keep the server from starting.
+func (s *Server) discoverWorkspaces(workspaces []string, depth int) []string {
+	if depth <= 0 {
+		depth = DefaultDiscoveryDepth
+	}
+	var found []string
+	for _, workspace := range workspaces {
+		repos, err := s.gitOps.DiscoverRepositories(workspace, depth)
+		if err != nil {
+			slog.Warn("Skipping workspace", "workspace", workspace, "error", err)
+			continue
+		}
+		slog.Info("Discovered repositories", "workspace", workspace, "repositories", len(repos))
+		found = append(found, repos...)
+	}
+	return found
+}
+
+// repositoryURI returns the file URI of a repository
+func repositoryURI(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		// Windows drive letters
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
+
+// resourceRepositories returns the repositories offered as resources: the
+// registered ones, discovered and aliased included, found within the
+// workspace roots of the client
+func (s *Server) resourceRepositories(ctx context.Context) ([]string, error) {
+	repos := s.registeredRepositories()
+	if s.ignoreRoots {
+		return repos, nil
+	}
+	roots, err := s.rootPaths(ctx)
+	if err != nil || len(roots) == 0 {
+		return repos, err
+	}
+	within := make([]string, 0, len(repos))
+	for _, repo := range repos {
+		if pathWithin(repo, roots) {
+			within = append(within, repo)
+		}
+	}
+	return within, nil
+}
+
+// listResources lists the repositories of this instance as resources,
+// named by their alias or directory
+func (s *Server) listResources(ctx context.Context) ([]mcp.Resource, error) {
+	repos, err := s.resourceRepositories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	aliasOf := make(map[string]string)
+	for _, name := range s.aliases.names() {
+		if path, _ := s.aliases.path(name); aliasOf[path] == "" {
+			aliasOf[path] = name
+		}
+	}
+
+	resources := make([]mcp.Resource, 0, len(repos))
+	for _, repo := range repos {
+		name := filepath.Base(repo)
+		if alias := aliasOf[repo]; alias != "" {
+			name = alias
+		}
+		resources = append(resources, mcp.Resource{
+			URI:         repositoryURI(repo),
+			Name:        s.toolPrefix + name,
+			Description: fmt.Sprintf("Git repository at %s: its current branch and working tree status", repo),
+			MimeType:    "text/plain",
+		})
+	}
+	return resources, nil
+}
+
+// readResource reads the current branch and status of a repository of this
+// instance, leaving other URIs to the other instances
+func (s *Server) readResource(ctx context.Context, uri string) ([]mcp.ResourceContents, bool, error) {
+	repos, err := s.resourceRepositories(ctx)
+	if err != nil {
+		return nil, true, err
+	}
+	for _, repo := range repos {
+		if repositoryURI(repo) != uri {
+			continue
+		}
+		branch, err := s.git(ctx).CurrentBranch(repo)
+		if err != nil {
+			return nil, true, err
+		}
+		status, err := s.git(ctx).Status(repo, false)
+		if err != nil {
+			return nil, true, err
+		}
+		return []mcp.ResourceContents{{
+			URI:      uri,
+			MimeType: "text/plain",
+			Text:     fmt.Sprintf("Repository: %s\n%s\n\n%s", repo, branch, status),
+		}}, true, nil
+	}
+	return nil, false, nil
+}
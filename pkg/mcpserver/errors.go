@@ -0,0 +1,22 @@
+package mcpserver
+
+import (
+	"context"
+
+	"github.com/pengcunfu/go-mcp-git/pkg/gitops"
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+// classifyErrors wraps a handler so that its errors carry the error code of
+// gitops.ErrorCode, which clients find in the error result of the call
+func (s *Server) classifyErrors(handler mcp.ToolHandler) mcp.ToolHandler {
+	return func(ctx context.Context, arguments map[string]interface{}) ([]mcp.TextContent, error) {
+		content, err := handler(ctx, arguments)
+		if err != nil {
+			if _, ok := err.(*mcp.ToolError); !ok {
+				err = &mcp.ToolError{Code: gitops.ErrorCode(err, s.getRepoPath(getString(arguments, "repo_path"))), Err: err}
+			}
+		}
+		return content, err
+	}
+}
@@ -0,0 +1,34 @@
+package mcpserver
+
+import (
+	"context"
+
+	"github.com/pengcunfu/go-mcp-git/pkg/gitops"
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+// registerGraphTools registers tools exporting history for visualization
+func (s *Server) registerGraphTools() {
+	registerTypedTool(s, "git_graph_export",
+		"Exports the commit graph of a revision range as JSON: nodes with commit metadata and ref labels, parent edges, and boundary parents outside the range",
+		s.handleGitGraphExport)
+}
+
+func (s *Server) handleGitGraphExport(ctx context.Context, params gitops.GitGraphExport) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	opts := gitops.GraphExportOptions{
+		Range:    params.Range,
+		MaxCount: params.MaxCount,
+	}
+
+	result, err := s.git(ctx).ExportGraph(repoPath, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
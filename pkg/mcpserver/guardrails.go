@@ -0,0 +1,130 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+// readOnlyTools are the tools that never modify a repository. They are the
+// only tools exposed in read-only mode.
+var readOnlyTools = map[string]bool{
+	"git_status":                 true,
+	"git_diff_unstaged":          true,
+	"git_diff_staged":            true,
+	"git_diff":                   true,
+	"git_log":                    true,
+	"git_show":                   true,
+	"git_branch":                 true,
+	"git_current_branch":         true,
+	"git_list_repositories":      true,
+	"git_list_tags":              true,
+	"git_issue_from_branch":      true,
+	"git_diff_directory":         true,
+	"git_compare_refs":           true,
+	"git_cherry":                 true,
+	"git_multi_status":           true,
+	"git_log_search":             true,
+	"git_file_history":           true,
+	"git_grep":                   true,
+	"git_graph_export":           true,
+	"git_blame_heat":             true,
+	"git_trash_list":             true,
+	"git_conflicts":              true,
+	"git_list_hunks":             true,
+	"git_verify":                 true,
+	"git_show_note":              true,
+	"git_list_notes":             true,
+	"git_bundle_verify":          true,
+	"server_dump_state":          true,
+	"server_set_defaults":        true,
+	"git_disk_usage":             true,
+	"git_large_files":            true,
+	"git_lfs_files":              true,
+	"git_materialize_revision":   true,
+	"git_remove_materialized":    true,
+	"git_suggest_commit_message": true,
+}
+
+// registerTool registers a tool with the MCP server unless the tool
+// selection or read-only mode leaves it out, applying the guardrails, confirmation of destructive calls, output limit, result
+// style, repository locks, cloning of URL aliases, client roots, session defaults, repository
+// aliases, timeout, error codes, tool prefix and locking of the server configuration
+func (s *Server) registerTool(tool mcp.Tool, handler mcp.ToolHandler) {
+	if !s.selection.selects(tool.Name) && !lockTools[tool.Name] {
+		return
+	}
+	if s.readOnly && !readOnlyTools[tool.Name] {
+		return
+	}
+	if destructive, ok := destructiveTools[tool.Name]; ok {
+		addConfirmationProperty(&tool)
+		handler = s.requireConfirmation(tool, destructive, handler)
+	}
+	if s.maxOutputBytes > 0 {
+		handler = s.limitOutput(tool, handler)
+	}
+	if s.resultStyle == ResultStyleVerbose {
+		handler = s.describeResults(tool, handler)
+	}
+	if s.streamChunkBytes > 0 {
+		handler = s.streamOutput(handler)
+	}
+	handler = s.lockRepositories(tool, handler)
+	handler = s.cloneAliases(tool, handler)
+	if s.restrictRepositories {
+		handler = s.restrictToRegistered(handler)
+	}
+	if !s.ignoreRoots {
+		handler = s.applyRoots(tool, handler)
+	}
+	handler = s.applySessionDefaults(tool, handler)
+	handler = s.applyRepoAliases(tool, handler)
+	handler = s.limitDuration(tool, handler)
+	lockable := s.lockable(tool.Name)
+	tool.Name = s.toolPrefix + tool.Name
+	s.tools[tool.Name] = tool
+	if lockable {
+		s.lockedTools = append(s.lockedTools, tool.Name)
+	}
+	s.mcpServer.RegisterTool(tool, s.trackCalls(tool, s.classifyErrors(handler)))
+}
+
+// registerTypedTool registers a tool whose arguments are decoded into T,
+// its input schema being generated from the tags of T. A repo_path without
+// description is the repository the tool works on, auto-detected when
+// missing.
+func registerTypedTool[T any](s *Server, name, description string, handler mcp.TypedToolHandler[T]) {
+	schema := mcp.SchemaFor[T]()
+	if properties, ok := schema["properties"].(map[string]interface{}); ok {
+		if property, ok := properties["repo_path"].(map[string]interface{}); ok && property["description"] == nil {
+			properties["repo_path"] = s.createRepoPathProperty()
+		}
+	}
+	s.registerTool(mcp.Tool{
+		Name:        name,
+		Description: description,
+		InputSchema: schema,
+	}, mcp.Typed(handler))
+}
+
+// restrictToRegistered wraps a handler so that it rejects repo_path and
+// repo_paths arguments outside the registered repositories
+func (s *Server) restrictToRegistered(handler mcp.ToolHandler) mcp.ToolHandler {
+	return func(ctx context.Context, arguments map[string]interface{}) ([]mcp.TextContent, error) {
+		paths := append([]string{getString(arguments, "repo_path")}, getStringSlice(arguments, "repo_paths")...)
+		for _, path := range paths {
+			if !s.isRegistered(s.getRepoPath(path)) {
+				return nil, fmt.Errorf("repository %s is not registered with the server", s.getRepoPath(path))
+			}
+		}
+		return handler(ctx, arguments)
+	}
+}
+
+// isRegistered reports whether path is a registered repository or lies
+// inside one
+func (s *Server) isRegistered(path string) bool {
+	return pathWithin(path, s.registeredRepositories())
+}
@@ -0,0 +1,48 @@
+package mcpserver
+
+import (
+	"context"
+
+	"github.com/pengcunfu/go-mcp-git/pkg/gitops"
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+// registerHunkTools registers tools for staging parts of a file, like
+// git add -p
+func (s *Server) registerHunkTools() {
+	registerTypedTool(s, "git_list_hunks",
+		"Lists the unstaged changes of a file as hunks with stable IDs to pass to git_stage_hunks",
+		s.handleGitListHunks)
+
+	registerTypedTool(s, "git_stage_hunks",
+		"Stages the selected hunks of a file, leaving its other changes unstaged (like git add -p)",
+		s.handleGitStageHunks)
+}
+
+func (s *Server) handleGitListHunks(ctx context.Context, params gitops.GitListHunks) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	result, err := s.git(ctx).ListHunks(repoPath, params.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
+
+func (s *Server) handleGitStageHunks(ctx context.Context, params gitops.GitStageHunks) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	result, err := s.git(ctx).StageHunks(repoPath, params.Path, params.HunkIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
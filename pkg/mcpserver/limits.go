@@ -0,0 +1,136 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+// DefaultMaxOutputBytes is the default size limit of a tool result
+const DefaultMaxOutputBytes = 256 << 10
+
+// narrowingArguments are tool arguments that reduce the size of a result,
+// suggested when a result is truncated
+var narrowingArguments = []string{"path", "paths", "max_count", "max_results", "format", "range"}
+
+// truncation describes a truncated tool result
+type truncation struct {
+	TotalBytes    int      `json:"total_bytes"`
+	Offset        int      `json:"offset"`
+	ReturnedBytes int      `json:"returned_bytes"`
+	NextOffset    int      `json:"next_offset,omitempty"`
+	NarrowWith    []string `json:"narrow_with,omitempty"`
+}
+
+// limitOutput wraps a handler so that results larger than maxOutputBytes are
+// cut at a line boundary and end with a description of the truncation.
+// Read-only tools accept an output_offset argument to page through a large
+// result; other tools must not be re-run to get the rest of their output.
+func (s *Server) limitOutput(tool mcp.Tool, handler mcp.ToolHandler) mcp.ToolHandler {
+	properties := schemaProperties(tool)
+	pageable := readOnlyTools[tool.Name]
+	if pageable && properties != nil {
+		properties["output_offset"] = map[string]interface{}{
+			"type":        "integer",
+			"description": fmt.Sprintf("Byte offset into the result, to continue a result truncated at %d bytes", s.maxOutputBytes),
+			"default":     0,
+		}
+	}
+
+	var narrowWith []string
+	for _, name := range narrowingArguments {
+		if _, ok := properties[name]; ok {
+			narrowWith = append(narrowWith, name)
+		}
+	}
+
+	return func(ctx context.Context, arguments map[string]interface{}) ([]mcp.TextContent, error) {
+		offset := 0
+		if pageable {
+			offset = getInt(arguments, "output_offset", 0)
+		}
+
+		content, err := handler(ctx, arguments)
+		if err != nil || len(content) == 0 {
+			return content, err
+		}
+
+		text := content[0].Text
+		if offset < 0 || offset > len(text) {
+			return nil, fmt.Errorf("output_offset %d is outside the result of %d bytes", offset, len(text))
+		}
+		if offset == 0 && len(text) <= s.maxOutputBytes {
+			return content, nil
+		}
+
+		page := truncateText(text[offset:], s.maxOutputBytes)
+		end := offset + len(page)
+		if end == len(text) {
+			// The last page of a paged result needs no trailer
+			content[0].Text = page
+			return content, nil
+		}
+
+		info := truncation{
+			TotalBytes:    len(text),
+			Offset:        offset,
+			ReturnedBytes: len(page),
+			NarrowWith:    narrowWith,
+		}
+		if pageable {
+			info.NextOffset = end
+		}
+
+		data, err := json.Marshal(info)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode truncation: %w", err)
+		}
+		content[0].Text = page + "\n\n[output truncated] " + string(data)
+		return content, nil
+	}
+}
+
+// truncateText cuts text to at most limit bytes, preferring the end of a
+// line and never splitting a UTF-8 sequence
+func truncateText(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	cut := text[:limit]
+	if i := strings.LastIndexByte(cut, '\n'); i > limit/2 {
+		return cut[:i+1]
+	}
+	for len(cut) > 0 && !utf8.RuneStart(text[len(cut)]) {
+		cut = cut[:len(cut)-1]
+	}
+	return cut
+}
+
+// schemaProperties returns the properties of a tool's input schema
+func schemaProperties(tool mcp.Tool) map[string]interface{} {
+	schema, ok := tool.InputSchema.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	properties, _ := schema["properties"].(map[string]interface{})
+	return properties
+}
+
+// schemaRequires reports whether a tool's input schema requires an argument
+func schemaRequires(tool mcp.Tool, name string) bool {
+	schema, ok := tool.InputSchema.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	required, _ := schema["required"].([]string)
+	for _, argument := range required {
+		if argument == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+// lockTools switch the tools that modify repositories on and off in locked
+// mode, staying available themselves
+var lockTools = map[string]bool{
+	"server_unlock": true,
+	"server_lock":   true,
+}
+
+// unlockedKey holds in a session whether it unlocked the tools of a server
+// instance. Every session starts locked and unlocks for itself.
+type unlockedKey struct{ server *Server }
+
+// lockable reports whether a tool is hidden while the server is locked
+func (s *Server) lockable(name string) bool {
+	return s.locked && !readOnlyTools[name] && !lockTools[name]
+}
+
+// unlocked reports whether a session unlocked the tools of the instance
+func (s *Server) unlocked(session *mcp.Session) bool {
+	if session == nil {
+		return false
+	}
+	unlocked, _ := session.Value(unlockedKey{s}).(bool)
+	return unlocked
+}
+
+// ServerUnlock represents the parameters for unlocking the tools that
+// modify repositories, which takes none
+type ServerUnlock struct{}
+
+// ServerLock represents the parameters for locking the tools that modify
+// repositories again, which takes none
+type ServerLock struct{}
+
+// registerLockTools registers the tools unlocking and locking the tools
+// that modify repositories, in locked mode, and hides those tools from the
+// sessions that did not unlock them. It runs once all the other tools are
+// registered.
+func (s *Server) registerLockTools() {
+	if !s.locked {
+		return
+	}
+	locked := make(map[string]bool, len(s.lockedTools))
+	for _, name := range s.lockedTools {
+		locked[name] = true
+	}
+	s.mcpServer.FilterTools(func(session *mcp.Session, name string) bool {
+		return !locked[name] || s.unlocked(session)
+	})
+
+	registerTypedTool(s, "server_unlock",
+		"Makes the tools that modify repositories (commit, reset, push, ...) available to this session. Sessions start with them hidden; the client is notified that the tool list changed",
+		s.handleServerUnlock)
+
+	registerTypedTool(s, "server_lock",
+		"Hides the tools that modify repositories from this session again, leaving the read-only tools",
+		s.handleServerLock)
+}
+
+// setUnlocked locks or unlocks the tools of the instance for the session of
+// a call
+func (s *Server) setUnlocked(ctx context.Context, unlocked bool) error {
+	session := mcp.SessionFromContext(ctx)
+	if session == nil {
+		return errors.New("locking and unlocking tools needs a session")
+	}
+	if s.unlocked(session) == unlocked {
+		return nil
+	}
+	session.SetValue(unlockedKey{s}, unlocked)
+	session.ToolsChanged()
+	return nil
+}
+
+func (s *Server) handleServerUnlock(ctx context.Context, params ServerUnlock) ([]mcp.TextContent, error) {
+	if err := s.setUnlocked(ctx, true); err != nil {
+		return nil, err
+	}
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: fmt.Sprintf("Unlocked %d tools for this session: %s\nCall %sserver_lock to hide them again", len(s.lockedTools), strings.Join(s.lockedTools, ", "), s.toolPrefix),
+	}}, nil
+}
+
+func (s *Server) handleServerLock(ctx context.Context, params ServerLock) ([]mcp.TextContent, error) {
+	if err := s.setUnlocked(ctx, false); err != nil {
+		return nil, err
+	}
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: fmt.Sprintf("Locked %d tools for this session, call %sserver_unlock to make them available again", len(s.lockedTools), s.toolPrefix),
+	}}, nil
+}
@@ -0,0 +1,91 @@
+package mcpserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+const testInitialize = `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`
+
+// serveSession serves the messages of one client over newline framing,
+// returning the responses by id
+func serveSession(t *testing.T, server *mcp.Server, messages ...string) map[float64]mcp.JSONRPCResponse {
+	t.Helper()
+	var out bytes.Buffer
+	in := strings.NewReader(strings.Join(append([]string{testInitialize}, messages...), "\n") + "\n")
+	if err := server.ServeTransport(context.Background(), mcp.NewStdioTransport(in, &out, mcp.FramingNewline)); err != nil {
+		t.Fatalf("ServeTransport failed: %v", err)
+	}
+	responses := make(map[float64]mcp.JSONRPCResponse)
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var response mcp.JSONRPCResponse
+		if err := json.Unmarshal([]byte(line), &response); err != nil {
+			t.Fatalf("Expected a response, got %s: %v", line, err)
+		}
+		if id, ok := response.ID.(float64); ok {
+			responses[id] = response
+		}
+	}
+	return responses
+}
+
+// listsTool reports whether a tools/list response holds a tool
+func listsTool(t *testing.T, response mcp.JSONRPCResponse, name string) bool {
+	t.Helper()
+	encoded, err := json.Marshal(response.Result)
+	if err != nil {
+		t.Fatalf("Failed to encode the result: %v", err)
+	}
+	var result mcp.ListToolsResponse
+	if err := json.Unmarshal(encoded, &result); err != nil {
+		t.Fatalf("Expected a tool list, got %s: %v", encoded, err)
+	}
+	for _, tool := range result.Tools {
+		if tool.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestLockedMode_PerSession(t *testing.T) {
+	s, err := New(WithConfig(Config{Locked: true, ToolsPageSize: 1000}))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	const (
+		list   = `{"jsonrpc":"2.0","id":%d,"method":"tools/list"}`
+		unlock = `{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"server_unlock","arguments":{}}}`
+		commit = `{"jsonrpc":"2.0","id":%d,"method":"tools/call","params":{"name":"git_commit","arguments":{"message":"test"}}}`
+	)
+
+	// One session unlocks the tools for itself
+	responses := serveSession(t, s.MCP(), fmt.Sprintf(list, 2), unlock, fmt.Sprintf(list, 4), fmt.Sprintf(commit, 5))
+	if listsTool(t, responses[2], "git_commit") || !listsTool(t, responses[2], "git_status") {
+		t.Errorf("Expected a new session to see the read-only tools only")
+	}
+	if responses[3].Error != nil {
+		t.Fatalf("server_unlock failed: %v", responses[3].Error)
+	}
+	if !listsTool(t, responses[4], "git_commit") {
+		t.Errorf("Expected the unlocked session to see git_commit")
+	}
+	if response := responses[5]; response.Error != nil && response.Error.Code == -32601 {
+		t.Errorf("Expected the unlocked session to call git_commit, got: %v", response.Error)
+	}
+
+	// Another session stays locked
+	responses = serveSession(t, s.MCP(), fmt.Sprintf(list, 2), fmt.Sprintf(commit, 3))
+	if listsTool(t, responses[2], "git_commit") {
+		t.Errorf("Expected another session to stay locked")
+	}
+	if response := responses[3]; response.Error == nil || response.Error.Code != -32601 {
+		t.Errorf("Expected another session to be refused git_commit, got: %+v", response)
+	}
+}
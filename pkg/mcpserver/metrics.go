@@ -0,0 +1,224 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+// MetricsPath is the HTTP path of the Prometheus metrics
+const MetricsPath = "/metrics"
+
+// PprofPath is the HTTP path of the runtime profiles of net/http/pprof
+const PprofPath = "/debug/pprof/"
+
+// durationBuckets are the upper bounds, in seconds, of the histogram of
+// tool call durations: from quick status calls to slow fetches
+var durationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// callMetrics counts the tool calls of an MCP server, whatever instance they
+// belong to, and renders them in the Prometheus text format
+type callMetrics struct {
+	mcpServer *mcp.Server
+	started   time.Time
+
+	mu        sync.Mutex
+	calls     map[string]*toolMetrics
+	active    int
+	activeFor map[string]int
+}
+
+// toolMetrics are the metrics of one tool
+type toolMetrics struct {
+	calls  int64
+	errors map[string]int64
+	// buckets counts the calls of each duration bucket, not cumulated, and
+	// the slower ones last
+	buckets []int64
+	seconds float64
+}
+
+func newCallMetrics(mcpServer *mcp.Server) *callMetrics {
+	return &callMetrics{
+		mcpServer: mcpServer,
+		started:   time.Now(),
+		calls:     make(map[string]*toolMetrics),
+		activeFor: make(map[string]int),
+	}
+}
+
+// middleware records every tool call of the MCP server
+func (m *callMetrics) middleware(next mcp.ToolHandler) mcp.ToolHandler {
+	return func(ctx context.Context, arguments map[string]interface{}) (content []mcp.TextContent, err error) {
+		tool := mcp.ToolName(ctx)
+		started := time.Now()
+		m.mu.Lock()
+		m.active++
+		m.activeFor[tool]++
+		m.mu.Unlock()
+
+		defer func() {
+			// The panic goes on to the recovery of the MCP server, counted
+			// as the internal error it is answered with
+			recovered := recover()
+			code := ""
+			if recovered != nil {
+				code = mcp.ErrorCodeInternal
+			} else if err != nil {
+				code = "unknown"
+				var toolErr *mcp.ToolError
+				if errors.As(err, &toolErr) && toolErr.Code != "" {
+					code = toolErr.Code
+				}
+			}
+			m.record(tool, time.Since(started), code)
+			if recovered != nil {
+				panic(recovered)
+			}
+		}()
+		return next(ctx, arguments)
+	}
+}
+
+// record ends a call, code being the error code of a failed call
+func (m *callMetrics) record(tool string, duration time.Duration, code string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.active--
+	if m.activeFor[tool]--; m.activeFor[tool] == 0 {
+		delete(m.activeFor, tool)
+	}
+
+	metrics, ok := m.calls[tool]
+	if !ok {
+		metrics = &toolMetrics{errors: make(map[string]int64), buckets: make([]int64, len(durationBuckets)+1)}
+		m.calls[tool] = metrics
+	}
+	metrics.calls++
+	if code != "" {
+		metrics.errors[code]++
+	}
+	seconds := duration.Seconds()
+	metrics.seconds += seconds
+	bucket := sort.SearchFloat64s(durationBuckets, seconds)
+	metrics.buckets[bucket]++
+}
+
+// ServeHTTP renders the metrics in the Prometheus text format
+func (m *callMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	fmt.Fprint(w, m.render())
+}
+
+func (m *callMetrics) render() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tools := make([]string, 0, len(m.calls))
+	for tool := range m.calls {
+		tools = append(tools, tool)
+	}
+	sort.Strings(tools)
+
+	var out strings.Builder
+	header := func(name, kind, help string) {
+		fmt.Fprintf(&out, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	}
+
+	header("mcp_git_tool_calls_total", "counter", "Tool calls by tool, failed ones included.")
+	for _, tool := range tools {
+		fmt.Fprintf(&out, "mcp_git_tool_calls_total{tool=%s} %d\n", labelValue(tool), m.calls[tool].calls)
+	}
+
+	header("mcp_git_tool_errors_total", "counter", "Failed tool calls by tool and error code.")
+	for _, tool := range tools {
+		codes := make([]string, 0, len(m.calls[tool].errors))
+		for code := range m.calls[tool].errors {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		for _, code := range codes {
+			fmt.Fprintf(&out, "mcp_git_tool_errors_total{tool=%s,code=%s} %d\n", labelValue(tool), labelValue(code), m.calls[tool].errors[code])
+		}
+	}
+
+	header("mcp_git_tool_call_duration_seconds", "histogram", "Duration of the tool calls by tool.")
+	for _, tool := range tools {
+		metrics := m.calls[tool]
+		var cumulated int64
+		for i, bound := range durationBuckets {
+			cumulated += metrics.buckets[i]
+			fmt.Fprintf(&out, "mcp_git_tool_call_duration_seconds_bucket{tool=%s,le=\"%s\"} %d\n", labelValue(tool), strconv.FormatFloat(bound, 'g', -1, 64), cumulated)
+		}
+		fmt.Fprintf(&out, "mcp_git_tool_call_duration_seconds_bucket{tool=%s,le=\"+Inf\"} %d\n", labelValue(tool), metrics.calls)
+		fmt.Fprintf(&out, "mcp_git_tool_call_duration_seconds_sum{tool=%s} %s\n", labelValue(tool), strconv.FormatFloat(metrics.seconds, 'g', -1, 64))
+		fmt.Fprintf(&out, "mcp_git_tool_call_duration_seconds_count{tool=%s} %d\n", labelValue(tool), metrics.calls)
+	}
+
+	header("mcp_git_active_requests", "gauge", "Tool calls in flight.")
+	fmt.Fprintf(&out, "mcp_git_active_requests %d\n", m.active)
+	header("mcp_git_active_requests_by_tool", "gauge", "Tool calls in flight by tool.")
+	active := make([]string, 0, len(m.activeFor))
+	for tool := range m.activeFor {
+		active = append(active, tool)
+	}
+	sort.Strings(active)
+	for _, tool := range active {
+		fmt.Fprintf(&out, "mcp_git_active_requests_by_tool{tool=%s} %d\n", labelValue(tool), m.activeFor[tool])
+	}
+
+	header("mcp_git_sessions", "gauge", "Client sessions connected.")
+	fmt.Fprintf(&out, "mcp_git_sessions %d\n", len(m.mcpServer.Sessions()))
+	header("mcp_git_start_time_seconds", "gauge", "Start time of the server since the Unix epoch.")
+	fmt.Fprintf(&out, "mcp_git_start_time_seconds %d\n", m.started.Unix())
+	return out.String()
+}
+
+// labelValue quotes a Prometheus label value
+func labelValue(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value) + `"`
+}
+
+// pprofHandlers returns the handlers of the runtime profiles, by path
+func pprofHandlers() map[string]http.Handler {
+	return map[string]http.Handler{
+		PprofPath:             http.HandlerFunc(pprof.Index),
+		PprofPath + "cmdline": http.HandlerFunc(pprof.Cmdline),
+		PprofPath + "profile": http.HandlerFunc(pprof.Profile),
+		PprofPath + "symbol":  http.HandlerFunc(pprof.Symbol),
+		PprofPath + "trace":   http.HandlerFunc(pprof.Trace),
+	}
+}
+
+// monitoringHandlers adds the metrics and profiles enabled by a
+// configuration to its WebSocket handlers, counting the tool calls of
+// mcpServer for the metrics
+func monitoringHandlers(cfg Config, mcpServer *mcp.Server) map[string]http.Handler {
+	if !cfg.Metrics && !cfg.Pprof {
+		return cfg.WebSocket.Handlers
+	}
+	handlers := make(map[string]http.Handler, len(cfg.WebSocket.Handlers))
+	for path, handler := range cfg.WebSocket.Handlers {
+		handlers[path] = handler
+	}
+	if cfg.Metrics {
+		metrics := newCallMetrics(mcpServer)
+		mcpServer.Use(metrics.middleware)
+		handlers[MetricsPath] = metrics
+	}
+	if cfg.Pprof {
+		for path, handler := range pprofHandlers() {
+			handlers[path] = handler
+		}
+	}
+	return handlers
+}
@@ -0,0 +1,84 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pengcunfu/go-mcp-git/pkg/gitops"
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+// registerMultiRepoTools registers tools operating on several repositories
+// at once
+func (s *Server) registerMultiRepoTools() {
+	registerTypedTool(s, "git_multi_status",
+		"Shows a compact status summary (branch, modified and untracked files, ahead/behind upstream) for several repositories, inspected concurrently",
+		s.handleGitMultiStatus)
+
+	registerTypedTool(s, "git_multi_sync",
+		"Fetches every remote of several repositories concurrently and optionally fast-forwards their current branch, reporting the outcome per repository",
+		s.handleGitMultiSync)
+}
+
+// registeredKey holds the repositories of a call with all_registered in its
+// context, narrowed to the workspace roots of the client
+type registeredKey struct{}
+
+// withRegistered narrows the repositories of a call with all_registered
+func withRegistered(ctx context.Context, repoPaths []string) context.Context {
+	return context.WithValue(ctx, registeredKey{}, repoPaths)
+}
+
+// callRegistered returns the repositories a call with all_registered works
+// on: the registered repositories, or those inside the workspace roots of
+// the client when it has roots
+func (s *Server) callRegistered(ctx context.Context) []string {
+	if repoPaths, ok := ctx.Value(registeredKey{}).([]string); ok {
+		return repoPaths
+	}
+	return s.registeredRepositories()
+}
+
+// multiRepoPaths returns the repositories selected by the repo_paths and
+// all_registered arguments
+func (s *Server) multiRepoPaths(ctx context.Context, paths []string, allRegistered bool) ([]string, error) {
+	if allRegistered {
+		repoPaths := s.callRegistered(ctx)
+		if len(repoPaths) == 0 {
+			return nil, fmt.Errorf("no repositories registered; start the server with --repository")
+		}
+		return repoPaths, nil
+	}
+
+	var repoPaths []string
+	for _, repoPath := range paths {
+		repoPaths = append(repoPaths, s.getRepoPath(repoPath))
+	}
+	if len(repoPaths) == 0 {
+		return nil, fmt.Errorf("repo_paths is required unless all_registered is true")
+	}
+	return repoPaths, nil
+}
+
+func (s *Server) handleGitMultiStatus(ctx context.Context, params gitops.GitMultiStatus) ([]mcp.TextContent, error) {
+	repoPaths, err := s.multiRepoPaths(ctx, params.RepoPaths, params.AllRegistered)
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: s.git(ctx).MultiStatus(repoPaths),
+	}}, nil
+}
+
+func (s *Server) handleGitMultiSync(ctx context.Context, params gitops.GitMultiSync) ([]mcp.TextContent, error) {
+	repoPaths, err := s.multiRepoPaths(ctx, params.RepoPaths, params.AllRegistered)
+	if err != nil {
+		return nil, err
+	}
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: s.git(ctx).MultiSync(repoPaths, params.Pull),
+	}}, nil
+}
@@ -0,0 +1,48 @@
+package mcpserver
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ValidateWebSocket checks the WebSocket configuration. A server reachable
+// from other hosts runs git commands for anyone connecting, so it needs
+// authentication unless AllowUnauthenticated is set.
+func ValidateWebSocket(cfg Config) error {
+	ws := cfg.WebSocket
+	if (ws.TLSCertFile == "") != (ws.TLSKeyFile == "") {
+		return fmt.Errorf("TLS needs both a certificate and a key")
+	}
+	if cfg.WebSocketAddr == "" {
+		if ws.TLSCertFile != "" {
+			return fmt.Errorf("TLS applies to the WebSocket transport, which is not enabled")
+		}
+		if cfg.Metrics || cfg.Pprof {
+			return fmt.Errorf("metrics and profiles are served over HTTP by the WebSocket transport, which is not enabled")
+		}
+		return nil
+	}
+	if path := ws.Path; (cfg.Metrics && path == MetricsPath) || (cfg.Pprof && strings.HasPrefix(path, PprofPath)) {
+		return fmt.Errorf("WebSocket path %s is used by the metrics or profiles", path)
+	}
+
+	host, _, err := net.SplitHostPort(cfg.WebSocketAddr)
+	if err != nil {
+		return fmt.Errorf("invalid WebSocket address '%s': %w", cfg.WebSocketAddr, err)
+	}
+	if ws.Authenticate == nil && !cfg.AllowUnauthenticated && !isLoopback(host) {
+		return fmt.Errorf("refusing to serve WebSocket clients on %s without authentication, set an auth token or allow unauthenticated clients explicitly", cfg.WebSocketAddr)
+	}
+	return nil
+}
+
+// isLoopback reports whether a host only accepts local connections, an
+// empty host listening on all interfaces
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
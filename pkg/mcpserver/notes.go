@@ -0,0 +1,70 @@
+package mcpserver
+
+import (
+	"context"
+
+	"github.com/pengcunfu/go-mcp-git/pkg/gitops"
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+// registerNoteTools registers tools for the notes attached to commits, like
+// git notes
+func (s *Server) registerNoteTools() {
+	registerTypedTool(s, "git_add_note",
+		"Attaches a note to a commit without changing it, e.g. review metadata or build results (like git notes add)",
+		s.handleGitAddNote)
+
+	registerTypedTool(s, "git_show_note",
+		"Shows the note attached to a commit",
+		s.handleGitShowNote)
+
+	registerTypedTool(s, "git_list_notes",
+		"Lists the commits with a note, most recent first, with the first line of their note",
+		s.handleGitListNotes)
+}
+
+func (s *Server) handleGitAddNote(ctx context.Context, params gitops.GitAddNote) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	result, err := s.git(ctx).AddNote(repoPath, params.Revision, params.Message, gitops.NoteOptions{
+		Ref:    params.Ref,
+		Force:  params.Force,
+		Append: params.Append,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
+
+func (s *Server) handleGitShowNote(ctx context.Context, params gitops.GitShowNote) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	result, err := s.git(ctx).ShowNote(repoPath, params.Revision, params.Ref)
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
+
+func (s *Server) handleGitListNotes(ctx context.Context, params gitops.GitListNotes) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	result, err := s.git(ctx).ListNotes(repoPath, params.Ref, params.MaxCount)
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
@@ -0,0 +1,140 @@
+// Package mcpserver implements the MCP Git server: the git tools, their
+// guardrails and the transports serving them. Other programs can embed it:
+//
+//	srv, err := mcpserver.New(
+//		mcpserver.WithRepositories("/src/app"),
+//		mcpserver.WithReadOnly(true),
+//	)
+//	if err != nil {
+//		return err
+//	}
+//	return srv.Serve(ctx)
+//
+// Every option sets fields of Config, WithConfig setting all of them at
+// once, and later options override earlier ones.
+package mcpserver
+
+import (
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/pengcunfu/go-mcp-git/pkg/gitops"
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+// Option configures the server created by New or the instance added by
+// Mount
+type Option func(*Config)
+
+// newConfig applies opts to an empty configuration
+func newConfig(opts []Option) Config {
+	var cfg Config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// WithConfig replaces the whole configuration, for programs filling Config
+// themselves. Options given after it adjust it.
+func WithConfig(cfg Config) Option {
+	return func(c *Config) { *c = cfg }
+}
+
+// WithRepositories registers repositories, the first one being the default
+// repository of the tool calls omitting repo_path
+func WithRepositories(paths ...string) Option {
+	return func(c *Config) { c.Repositories = append(c.Repositories, paths...) }
+}
+
+// WithRestrictRepositories rejects tool calls on unregistered repositories
+func WithRestrictRepositories(restrict bool) Option {
+	return func(c *Config) { c.RestrictRepositories = restrict }
+}
+
+// WithAliases names repositories for the repo argument of the tools, URL
+// targets being cloned on first use into cloneDir (a cache directory when
+// empty)
+func WithAliases(aliases map[string]string, cloneDir string) Option {
+	return func(c *Config) { c.Aliases, c.AliasCloneDir = aliases, cloneDir }
+}
+
+// WithWorkspaces registers the repositories found in directories at
+// startup, searched depth levels deep (DefaultDiscoveryDepth when zero)
+func WithWorkspaces(depth int, dirs ...string) Option {
+	return func(c *Config) {
+		c.Workspaces = append(c.Workspaces, dirs...)
+		c.DiscoveryDepth = depth
+	}
+}
+
+// WithIdentity sets the author and committer of the commits
+func WithIdentity(userName, userEmail string) Option {
+	return func(c *Config) { c.UserName, c.UserEmail = userName, userEmail }
+}
+
+// WithAuth authenticates fetch and push
+func WithAuth(auth transport.AuthMethod) Option {
+	return func(c *Config) { c.Auth = auth }
+}
+
+// WithSSHAuth authenticates fetch and push to SSH remotes
+func WithSSHAuth(auth *gitops.SSHAuth) Option {
+	return func(c *Config) { c.SSHAuth = auth }
+}
+
+// WithCommitPolicy applies a commit policy to the repositories without
+// their own
+func WithCommitPolicy(policy *gitops.CommitPolicy) Option {
+	return func(c *Config) { c.CommitPolicy = policy }
+}
+
+// WithReadOnly only exposes the tools that do not modify repositories
+func WithReadOnly(readOnly bool) Option {
+	return func(c *Config) { c.ReadOnly = readOnly }
+}
+
+// WithTools selects the tools exposed by name or tool group, see
+// Config.EnableTools
+func WithTools(enable, disable []string) Option {
+	return func(c *Config) { c.EnableTools, c.DisableTools = enable, disable }
+}
+
+// WithToolPrefix prepends prefix to the name of every tool
+func WithToolPrefix(prefix string) Option {
+	return func(c *Config) { c.ToolPrefix = prefix }
+}
+
+// WithResultStyle sets the ResultStyle of the tool results
+func WithResultStyle(style string) Option {
+	return func(c *Config) { c.ResultStyle = style }
+}
+
+// WithMaxOutputBytes truncates larger tool results (0 means unlimited)
+func WithMaxOutputBytes(n int) Option {
+	return func(c *Config) { c.MaxOutputBytes = n }
+}
+
+// WithToolTimeout stops longer tool calls (0 means unlimited)
+func WithToolTimeout(timeout time.Duration) Option {
+	return func(c *Config) { c.ToolTimeout = timeout }
+}
+
+// WithRateLimits bounds the tool calls of each session, see
+// Config.CallsPerMinute
+func WithRateLimits(callsPerMinute, concurrentCalls, pushesPerHour, clonesPerHour int) Option {
+	return func(c *Config) {
+		c.CallsPerMinute, c.ConcurrentCalls = callsPerMinute, concurrentCalls
+		c.PushesPerHour, c.ClonesPerHour = pushesPerHour, clonesPerHour
+	}
+}
+
+// WithWebSocket serves clients over WebSocket on addr instead of stdio
+func WithWebSocket(addr string, opts mcp.WebSocketOptions) Option {
+	return func(c *Config) { c.WebSocketAddr, c.WebSocket = addr, opts }
+}
+
+// WithTracing exports a trace of every tool call to an OTLP collector
+func WithTracing(endpoint string, headers map[string]string) Option {
+	return func(c *Config) { c.Tracing = &TracingOptions{Endpoint: endpoint, Headers: headers} }
+}
@@ -0,0 +1,99 @@
+package mcpserver
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+// cloneQuota names the quota of clones, which the clones of URL aliases
+// count against too
+const cloneQuota = "clones"
+
+// rateLimits returns the rate limits of a configuration, ok false when it
+// sets none. The quotas match the tools of every instance, by the tool
+// prefixes in use.
+func rateLimits(cfg Config, prefixes *toolPrefixes) (limits mcp.RateLimits, ok bool) {
+	limits = mcp.RateLimits{CallsPerMinute: cfg.CallsPerMinute, ConcurrentCalls: cfg.ConcurrentCalls}
+	if cfg.PushesPerHour > 0 {
+		limits.Quotas = append(limits.Quotas, mcp.Quota{Name: "pushes", CallsPerHour: cfg.PushesPerHour, Match: prefixes.matcher(isPush)})
+	}
+	if cfg.ClonesPerHour > 0 {
+		limits.Quotas = append(limits.Quotas, mcp.Quota{Name: cloneQuota, CallsPerHour: cfg.ClonesPerHour, Match: prefixes.matcher(isClone)})
+	}
+	return limits, limits.CallsPerMinute > 0 || limits.ConcurrentCalls > 0 || len(limits.Quotas) > 0
+}
+
+// isPush reports whether a call pushes to a remote. Dry runs and the
+// previews of destructive pushes, which only return a confirmation token,
+// push nothing.
+func isPush(tool string, arguments map[string]interface{}) bool {
+	switch tool {
+	case "git_push", "git_push_tags":
+		return !getBool(arguments, "dry_run", false) && !isPreview(tool, arguments)
+	case "git_raw_command":
+		subcommand, args, _ := rawSubcommand(getString(arguments, "command"))
+		return subcommand == "push" && !hasRawFlag(args, "n", "--dry-run") && !isPreview(tool, arguments)
+	}
+	return false
+}
+
+// isClone reports whether a call clones a repository
+func isClone(tool string, arguments map[string]interface{}) bool {
+	switch tool {
+	case "git_clone":
+		return true
+	case "git_raw_command":
+		subcommand, _, _ := rawSubcommand(getString(arguments, "command"))
+		return subcommand == "clone"
+	}
+	return false
+}
+
+// isPreview reports whether a call of a destructive tool only returns a
+// preview and a confirmation token, without running
+func isPreview(tool string, arguments map[string]interface{}) bool {
+	destructive, ok := destructiveTools[tool]
+	if !ok || (destructive.applies != nil && !destructive.applies(nil, arguments)) {
+		return false
+	}
+	return getString(arguments, "confirmation_token") == ""
+}
+
+// toolPrefixes are the tool prefixes in use on an MCP server, shared by the
+// instances mounted on it. Instances may be mounted while calls are served.
+type toolPrefixes struct {
+	mu       sync.RWMutex
+	prefixes map[string]bool
+}
+
+func newToolPrefixes(prefix string) *toolPrefixes {
+	return &toolPrefixes{prefixes: map[string]bool{prefix: true}}
+}
+
+// add reserves a prefix, false when it is already in use
+func (p *toolPrefixes) add(prefix string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.prefixes[prefix] {
+		return false
+	}
+	p.prefixes[prefix] = true
+	return true
+}
+
+// matcher matches the calls made of a prefix in use and a tool name
+// matches accepts, along with its arguments
+func (p *toolPrefixes) matcher(matches func(tool string, arguments map[string]interface{}) bool) func(string, map[string]interface{}) bool {
+	return func(name string, arguments map[string]interface{}) bool {
+		p.mu.RLock()
+		defer p.mu.RUnlock()
+		for prefix := range p.prefixes {
+			if strings.HasPrefix(name, prefix) && matches(strings.TrimPrefix(name, prefix), arguments) {
+				return true
+			}
+		}
+		return false
+	}
+}
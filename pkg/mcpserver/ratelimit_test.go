@@ -0,0 +1,70 @@
+package mcpserver
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestRateLimitQuotas(t *testing.T) {
+	tests := []struct {
+		tool      string
+		arguments map[string]interface{}
+		push      bool
+		clone     bool
+	}{
+		{"git_push", map[string]interface{}{}, true, false},
+		{"git_push", map[string]interface{}{"dry_run": true}, false, false},
+		// The preview of a force push runs nothing, its confirmation does
+		{"git_push", map[string]interface{}{"force": true}, false, false},
+		{"git_push", map[string]interface{}{"force": true, "confirmation_token": "abc"}, true, false},
+		{"git_push_tags", map[string]interface{}{}, true, false},
+		{"git_clone", map[string]interface{}{"url": "https://example.com/repo.git"}, false, true},
+		{"git_fetch", map[string]interface{}{}, false, false},
+		{"git_raw_command", map[string]interface{}{"command": "git push origin main"}, true, false},
+		{"git_raw_command", map[string]interface{}{"command": "git push --dry-run origin main"}, false, false},
+		{"git_raw_command", map[string]interface{}{"command": "git push --force origin main"}, false, false},
+		{"git_raw_command", map[string]interface{}{"command": "git push --force origin main", "confirmation_token": "abc"}, true, false},
+		{"git_raw_command", map[string]interface{}{"command": "git -C /tmp clone https://example.com/repo.git"}, false, true},
+		{"git_raw_command", map[string]interface{}{"command": "git status"}, false, false},
+	}
+	for _, tt := range tests {
+		if got := isPush(tt.tool, tt.arguments); got != tt.push {
+			t.Errorf("isPush(%s, %v) = %v, expected %v", tt.tool, tt.arguments, got, tt.push)
+		}
+		if got := isClone(tt.tool, tt.arguments); got != tt.clone {
+			t.Errorf("isClone(%s, %v) = %v, expected %v", tt.tool, tt.arguments, got, tt.clone)
+		}
+	}
+}
+
+func TestToolPrefixes(t *testing.T) {
+	prefixes := newToolPrefixes("")
+	if prefixes.add("") {
+		t.Errorf("Expected the main prefix to be in use")
+	}
+	match := prefixes.matcher(isPush)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			prefixes.add(fmt.Sprintf("instance%d_", i))
+		}
+	}()
+	for i := 0; i < 50; i++ {
+		match("git_push", map[string]interface{}{})
+	}
+	wg.Wait()
+
+	if !match("instance7_git_push", map[string]interface{}{}) {
+		t.Errorf("Expected the tools of a mounted instance to match")
+	}
+	if match("unknown_git_push", map[string]interface{}{}) {
+		t.Errorf("Expected unknown prefixes not to match")
+	}
+	if !prefixes.add("other_") || prefixes.add("other_") {
+		t.Errorf("Expected a prefix to be added once")
+	}
+}
@@ -0,0 +1,220 @@
+package mcpserver
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// rawGlobalOptionsWithValue are the global options of git taking their
+// value as the next argument
+var rawGlobalOptionsWithValue = map[string]bool{
+	"-C":          true,
+	"-c":          true,
+	"--git-dir":   true,
+	"--work-tree": true,
+	"--namespace": true,
+}
+
+// rawSubcommand splits the command of git_raw_command into the git
+// subcommand and its arguments, skipping the global options before the
+// subcommand. aliased reports a -c alias.* option, which may run any
+// command under another name.
+func rawSubcommand(command string) (subcommand string, args []string, aliased bool) {
+	parts := strings.Fields(command)
+	if len(parts) > 0 && parts[0] == "git" {
+		parts = parts[1:]
+	}
+	for i := 0; i < len(parts); i++ {
+		part := parts[i]
+		if !strings.HasPrefix(part, "-") {
+			return part, parts[i+1:], aliased
+		}
+		if strings.HasPrefix(part, "-c") && strings.Contains(part, "alias.") {
+			aliased = true
+		}
+		if rawGlobalOptionsWithValue[part] {
+			i++
+			if part == "-c" && i < len(parts) && strings.HasPrefix(parts[i], "alias.") {
+				aliased = true
+			}
+		}
+	}
+	return "", nil, aliased
+}
+
+// hasRawFlag reports whether args hold one of the long options, or one of
+// the short ones on its own or in a cluster such as -fd. Long options
+// match with a value too, e.g. --force-with-lease=main.
+func hasRawFlag(args []string, short string, long ...string) bool {
+	for _, arg := range args {
+		if arg == "--" {
+			return false
+		}
+		if strings.HasPrefix(arg, "--") {
+			for _, option := range long {
+				if arg == option || strings.HasPrefix(arg, option+"=") {
+					return true
+				}
+			}
+			continue
+		}
+		if strings.HasPrefix(arg, "-") && len(arg) > 1 && strings.ContainsAny(arg[1:], short) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasRawArg reports whether args hold one of values
+func hasRawArg(args []string, values ...string) bool {
+	for _, arg := range args {
+		for _, value := range values {
+			if arg == value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// firstRawArg returns the first argument, empty without arguments
+func firstRawArg(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	return args[0]
+}
+
+// rawOperands returns the arguments that are not options, skipping the
+// values of the options in withValue
+func rawOperands(args []string, withValue ...string) []string {
+	var operands []string
+	for i := 0; i < len(args); i++ {
+		switch {
+		case hasRawArg([]string{args[i]}, withValue...):
+			i++
+		case !strings.HasPrefix(args[i], "-"):
+			operands = append(operands, args[i])
+		}
+	}
+	return operands
+}
+
+// isRawPath reports whether an operand of a command run in dir names files
+// rather than a revision: a glob pattern, which no revision matches, or an
+// existing file or directory
+func isRawPath(dir, operand string) bool {
+	if strings.ContainsAny(operand, "*?[") {
+		return true
+	}
+	if !filepath.IsAbs(operand) {
+		operand = filepath.Join(dir, operand)
+	}
+	_, err := os.Lstat(operand)
+	return err == nil
+}
+
+// isDestructiveRawCommand reports whether a command of git_raw_command run
+// in dir may discard work: hard resets, force pushes and remote deletions,
+// cleans, checkouts of paths or forced ones, branch and tag deletions,
+// history filters and the like. Commands run through a -c alias.* option
+// are treated as destructive since their actual command is unknown.
+func isDestructiveRawCommand(dir, command string) bool {
+	_, dir = rawGlobalPaths(dir, command)
+	subcommand, args, aliased := rawSubcommand(command)
+	if aliased {
+		return true
+	}
+	switch subcommand {
+	case "reset":
+		return hasRawFlag(args, "", "--hard", "--merge", "--keep")
+	case "push":
+		if hasRawFlag(args, "fd", "--force", "--force-with-lease", "--force-if-includes", "--mirror", "--delete", "--prune") {
+			return true
+		}
+		for _, arg := range args {
+			// Forced refspecs and deletions by an empty source
+			if strings.HasPrefix(arg, "+") || strings.HasPrefix(arg, ":") {
+				return true
+			}
+		}
+		return false
+	case "clean":
+		return !hasRawFlag(args, "n", "--dry-run")
+	case "branch":
+		return hasRawFlag(args, "dDMCf", "--delete", "--force")
+	case "tag":
+		return hasRawFlag(args, "df", "--delete", "--force")
+	case "checkout":
+		if hasRawFlag(args, "fB", "--force", "--ours", "--theirs") || hasRawArg(args, "--", ".") {
+			return true
+		}
+		// Like restore, checkout overwrites the paths it is given: those
+		// after the revision, or a single operand naming files
+		operands := rawOperands(args, "-b", "--orphan")
+		return len(operands) > 1 || (len(operands) == 1 && isRawPath(dir, operands[0]))
+	case "switch":
+		return hasRawFlag(args, "fC", "--force", "--discard-changes", "--force-create")
+	case "restore":
+		return !hasRawFlag(args, "S", "--staged") || hasRawFlag(args, "W", "--worktree")
+	case "rm":
+		return hasRawFlag(args, "f", "--force")
+	case "stash":
+		action := firstRawArg(args)
+		return action == "drop" || action == "clear"
+	case "reflog":
+		action := firstRawArg(args)
+		return action == "expire" || action == "delete"
+	case "worktree":
+		return firstRawArg(args) == "remove" && hasRawFlag(args, "f", "--force")
+	case "update-ref":
+		return hasRawFlag(args, "d", "--delete")
+	case "filter-branch", "filter-repo", "replace":
+		return true
+	}
+	return false
+}
+
+// rawGlobalPaths returns the directories the global -C, --git-dir and
+// --work-tree options of a command of git_raw_command run in dir point git
+// at, the relative ones resolved as git does: -C against dir or the
+// previous -C, the others against the directory -C changed to. workTree is
+// the directory whose files the command works on.
+func rawGlobalPaths(dir, command string) (paths []string, workTree string) {
+	parts := strings.Fields(command)
+	if len(parts) > 0 && parts[0] == "git" {
+		parts = parts[1:]
+	}
+	resolve := func(path string) string {
+		if filepath.IsAbs(path) {
+			return filepath.Clean(path)
+		}
+		return filepath.Join(dir, path)
+	}
+
+	var tree string
+	for i := 0; i < len(parts) && strings.HasPrefix(parts[i], "-"); i++ {
+		option, value, inline := strings.Cut(parts[i], "=")
+		if !inline && rawGlobalOptionsWithValue[option] {
+			if i++; i == len(parts) {
+				break
+			}
+			value = parts[i]
+		}
+		switch option {
+		case "-C":
+			dir = resolve(value)
+			paths = append(paths, dir)
+		case "--git-dir":
+			paths = append(paths, resolve(value))
+		case "--work-tree":
+			tree = resolve(value)
+			paths = append(paths, tree)
+		}
+	}
+	if tree == "" {
+		tree = dir
+	}
+	return paths, tree
+}
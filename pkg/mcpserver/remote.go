@@ -0,0 +1,81 @@
+package mcpserver
+
+import (
+	"context"
+
+	"github.com/pengcunfu/go-mcp-git/pkg/gitops"
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+// registerRemoteTools registers tools downloading from remotes
+func (s *Server) registerRemoteTools() {
+	registerTypedTool(s, "git_fetch",
+		"Fetches the branches and tags of a remote, reporting the references it updated",
+		s.handleGitFetch)
+
+	registerTypedTool(s, "git_remote_prune",
+		"Deletes the remote-tracking branches of branches deleted from a remote",
+		s.handleGitRemotePrune)
+
+	registerTypedTool(s, "git_clone",
+		"Clones a repository into a new directory",
+		s.handleGitClone)
+}
+
+func (s *Server) handleGitFetch(ctx context.Context, params gitops.GitFetch) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	result, err := s.git(ctx).Fetch(repoPath, gitops.FetchOptions{
+		Remote:      params.Remote,
+		Tags:        params.Tags,
+		Prune:       params.Prune,
+		Depth:       params.Depth,
+		Filter:      params.Filter,
+		Bundle:      params.Bundle,
+		Credentials: params.Credentials(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
+
+func (s *Server) handleGitRemotePrune(ctx context.Context, params gitops.GitRemotePrune) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	result, err := s.git(ctx).RemotePrune(repoPath, params.Remote, params.DryRun, params.Credentials())
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
+
+func (s *Server) handleGitClone(ctx context.Context, params gitops.GitClone) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	result, err := s.git(ctx).Clone(params.URL, repoPath, gitops.CloneOptions{
+		Branch:       params.Branch,
+		Bare:         params.Bare,
+		Depth:        params.Depth,
+		SingleBranch: params.SingleBranch,
+		Mirror:       params.Mirror,
+		Filter:       params.Filter,
+		Credentials:  params.Credentials(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
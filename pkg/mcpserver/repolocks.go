@@ -0,0 +1,45 @@
+package mcpserver
+
+import (
+	"context"
+
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+// lockRepositories wraps the handler of a tool taking repositories so that
+// the call holds the locks of its repositories: exclusive for the tools
+// modifying them, shared for the read-only tools. Calls modifying the same
+// repository thus run one after the other, e.g. a checkout waits for an
+// add and commit in flight, while reads run alongside each other. Calls
+// with all_registered hold the locks of every registered repository.
+func (s *Server) lockRepositories(tool mcp.Tool, handler mcp.ToolHandler) mcp.ToolHandler {
+	properties := schemaProperties(tool)
+	_, hasRepoPath := properties["repo_path"]
+	_, hasRepoPaths := properties["repo_paths"]
+	if !hasRepoPath && !hasRepoPaths {
+		return handler
+	}
+	exclusive := !readOnlyTools[tool.Name]
+
+	return func(ctx context.Context, arguments map[string]interface{}) ([]mcp.TextContent, error) {
+		var paths []string
+		if getBool(arguments, "all_registered", false) {
+			paths = s.callRegistered(ctx)
+		} else {
+			if hasRepoPath {
+				paths = append(paths, s.getRepoPath(getString(arguments, "repo_path")))
+			}
+			for _, path := range getStringSlice(arguments, "repo_paths") {
+				paths = append(paths, s.getRepoPath(path))
+			}
+		}
+		traceRepository(ctx, paths)
+
+		unlock, err := s.git(ctx).LockRepositories(exclusive, paths...)
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+		return handler(ctx, arguments)
+	}
+}
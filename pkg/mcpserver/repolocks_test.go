@@ -0,0 +1,57 @@
+package mcpserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pengcunfu/go-mcp-git/pkg/gitops"
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+func TestLockRepositories_AllRegistered(t *testing.T) {
+	repo := t.TempDir()
+	s := &Server{
+		gitOps:       gitops.NewOperations("Test User", "test@example.com"),
+		repositories: []string{t.TempDir(), repo},
+		aliases:      newRepoAliases(nil, ""),
+	}
+	ctx := context.Background()
+
+	committing, release := make(chan struct{}), make(chan struct{})
+	commit := s.lockRepositories(mcp.Tool{Name: "git_commit", InputSchema: mcp.SchemaFor[gitops.GitCommit]()},
+		func(ctx context.Context, arguments map[string]interface{}) ([]mcp.TextContent, error) {
+			close(committing)
+			<-release
+			return nil, nil
+		})
+	synced := make(chan struct{})
+	sync := s.lockRepositories(mcp.Tool{Name: "git_multi_sync", InputSchema: mcp.SchemaFor[gitops.GitMultiSync]()},
+		func(ctx context.Context, arguments map[string]interface{}) ([]mcp.TextContent, error) {
+			close(synced)
+			return nil, nil
+		})
+
+	go commit(ctx, map[string]interface{}{"repo_path": repo, "message": "test"})
+	<-committing
+	done := make(chan error)
+	go func() {
+		_, err := sync(ctx, map[string]interface{}{"all_registered": true, "pull": true})
+		done <- err
+	}()
+
+	select {
+	case <-synced:
+		t.Fatalf("Expected the sync of all registered repositories to wait for the commit")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	select {
+	case <-synced:
+	default:
+		t.Errorf("Expected the sync to run once the commit released its lock")
+	}
+}
@@ -0,0 +1,50 @@
+package mcpserver
+
+import (
+	"context"
+
+	"github.com/pengcunfu/go-mcp-git/pkg/gitops"
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+// registerRewriteTools registers tools rewriting the history of branches
+func (s *Server) registerRewriteTools() {
+	registerTypedTool(s, "git_rewrite_history",
+		"Rewrites the last commits of a branch like an interactive rebase, following a plan that picks, rewords, squashes, fixes up, drops or reorders them",
+		s.handleGitRewriteHistory)
+
+	registerTypedTool(s, "git_squash_branch",
+		"Squashes all the commits of the current branch since its merge base with a target branch into a single commit, to clean up a branch before merging it",
+		s.handleGitSquashBranch)
+}
+
+func (s *Server) handleGitRewriteHistory(ctx context.Context, params gitops.GitRewriteHistory) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	result, err := s.git(ctx).RewriteHistory(repoPath, params.Branch, params.Plan)
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
+
+func (s *Server) handleGitSquashBranch(ctx context.Context, params gitops.GitSquashBranch) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	result, err := s.git(ctx).SquashBranch(repoPath, params.Target, params.Message, gitops.CommitOptions{
+		Signoff: params.Signoff,
+		Sign:    params.Sign,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
@@ -0,0 +1,146 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+// applyRoots wraps the handler of a tool taking repositories so that it
+// follows the workspace roots of the client, when the client has roots:
+// repo_path and repo_paths must lie inside one of them, and a call without
+// repo_path runs in the only root when there is a single one and no default
+// repository is configured. Calls with all_registered only work on the
+// registered repositories inside the roots, and the directories the global
+// options of git_raw_command point git at must lie inside them too.
+func (s *Server) applyRoots(tool mcp.Tool, handler mcp.ToolHandler) mcp.ToolHandler {
+	properties := schemaProperties(tool)
+	_, hasRepoPath := properties["repo_path"]
+	_, hasRepoPaths := properties["repo_paths"]
+	if !hasRepoPath && !hasRepoPaths {
+		return handler
+	}
+	rawCommand := tool.Name == "git_raw_command"
+
+	return func(ctx context.Context, arguments map[string]interface{}) ([]mcp.TextContent, error) {
+		roots, err := s.rootPaths(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if len(roots) == 0 {
+			return handler(ctx, arguments)
+		}
+
+		if hasRepoPath && getString(arguments, "repo_path") == "" && s.repository == "" && len(roots) == 1 {
+			defaulted := make(map[string]interface{}, len(arguments)+1)
+			for key, value := range arguments {
+				defaulted[key] = value
+			}
+			defaulted["repo_path"] = roots[0]
+			arguments = defaulted
+		}
+
+		if getBool(arguments, "all_registered", false) {
+			var repoPaths []string
+			for _, path := range s.callRegistered(ctx) {
+				if pathWithin(path, roots) {
+					repoPaths = append(repoPaths, path)
+				}
+			}
+			if len(repoPaths) == 0 {
+				return nil, fmt.Errorf("no registered repository is inside the workspace roots of the client (%s)", strings.Join(roots, ", "))
+			}
+			return handler(withRegistered(ctx, repoPaths), arguments)
+		}
+
+		paths := append([]string{getString(arguments, "repo_path")}, getStringSlice(arguments, "repo_paths")...)
+		for _, path := range paths {
+			if path == "" && !hasRepoPath {
+				continue
+			}
+			if path := s.getRepoPath(path); !pathWithin(path, roots) {
+				return nil, fmt.Errorf("repository %s is outside the workspace roots of the client (%s)", path, strings.Join(roots, ", "))
+			}
+		}
+		if rawCommand {
+			repoPath := s.getRepoPath(getString(arguments, "repo_path"))
+			paths, _ := rawGlobalPaths(repoPath, getString(arguments, "command"))
+			for _, path := range paths {
+				if !pathWithin(path, roots) {
+					return nil, fmt.Errorf("git_raw_command points git at %s, outside the workspace roots of the client (%s)", path, strings.Join(roots, ", "))
+				}
+			}
+		}
+		return handler(ctx, arguments)
+	}
+}
+
+// rootPaths returns the directories of the file roots of the client, none
+// when the client has no roots
+func (s *Server) rootPaths(ctx context.Context) ([]string, error) {
+	roots, err := s.mcpServer.Roots(ctx)
+	if errors.Is(err, mcp.ErrRootsNotSupported) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the workspace roots of the client: %w", err)
+	}
+
+	var paths []string
+	for _, root := range roots {
+		u, err := url.Parse(root.URI)
+		if err != nil || u.Scheme != "file" || u.Path == "" {
+			continue
+		}
+		paths = append(paths, filepath.Clean(filepath.FromSlash(u.Path)))
+	}
+	return paths, nil
+}
+
+// pathWithin reports whether path is one of dirs or lies inside one, once
+// their symlinks are resolved
+func pathWithin(path string, dirs []string) bool {
+	path, err := resolvePath(path)
+	if err != nil {
+		return false
+	}
+
+	for _, dir := range dirs {
+		dir, err := resolvePath(dir)
+		if err != nil {
+			continue
+		}
+		if path == dir || strings.HasPrefix(path, strings.TrimSuffix(dir, string(filepath.Separator))+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolvePath returns the absolute path of path with its symlinks
+// resolved. Of a path that does not exist yet, such as the directory of a
+// git_init, the symlinks of its longest existing ancestor are resolved.
+func resolvePath(path string) (string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	var missing []string
+	for {
+		if resolved, err := filepath.EvalSymlinks(path); err == nil {
+			return filepath.Join(append([]string{resolved}, missing...)...), nil
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return filepath.Join(append([]string{path}, missing...)...), nil
+		}
+		missing = append([]string{filepath.Base(path)}, missing...)
+		path = parent
+	}
+}
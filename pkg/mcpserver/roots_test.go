@@ -0,0 +1,64 @@
+package mcpserver
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPathWithin_Symlinks(t *testing.T) {
+	root, outside := t.TempDir(), t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "repo"), 0755); err != nil {
+		t.Fatalf("Failed to create repo: %v", err)
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "escape")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+	link := filepath.Join(t.TempDir(), "root")
+	if err := os.Symlink(root, link); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		within bool
+	}{
+		{"root itself", root, true},
+		{"inside", filepath.Join(root, "repo"), true},
+		{"not created yet", filepath.Join(root, "new", "repo"), true},
+		{"symlink out of the root", filepath.Join(root, "escape"), false},
+		{"not created yet below a symlink out", filepath.Join(root, "escape", "new"), false},
+		{"through a symlink to the root", filepath.Join(link, "repo"), true},
+		{"outside", outside, false},
+		{"prefix of another directory", root + "-other", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pathWithin(tt.path, []string{root}); got != tt.within {
+				t.Errorf("pathWithin(%q) = %v, expected %v", tt.path, got, tt.within)
+			}
+		})
+	}
+}
+
+func TestRawGlobalPaths(t *testing.T) {
+	tests := []struct {
+		command string
+		paths   []string
+	}{
+		{"git status", nil},
+		{"git -C /other status", []string{"/other"}},
+		{"git -C sub -C deeper log", []string{"/repo/sub", "/repo/sub/deeper"}},
+		{"git --git-dir=/other/.git --work-tree /other status", []string{"/other/.git", "/other"}},
+		{"git -C .. --git-dir .git status", []string{"/", "/.git"}},
+		{"git -c core.pager=cat -C /other log", []string{"/other"}},
+		{"git log -C /other", nil},
+	}
+	for _, tt := range tests {
+		if got, _ := rawGlobalPaths("/repo", tt.command); !reflect.DeepEqual(got, tt.paths) {
+			t.Errorf("rawGlobalPaths(%q) = %v, expected %v", tt.command, got, tt.paths)
+		}
+	}
+}
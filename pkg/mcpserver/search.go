@@ -0,0 +1,96 @@
+package mcpserver
+
+import (
+	"context"
+
+	"github.com/pengcunfu/go-mcp-git/pkg/gitops"
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+// registerSearchTools registers tools searching repository contents
+func (s *Server) registerSearchTools() {
+	registerTypedTool(s, "git_grep",
+		"Searches the files of a revision for lines matching a regular expression, in one repository or across several repositories concurrently with matches grouped by repository",
+		s.handleGitGrep)
+
+	registerTypedTool(s, "git_log_search",
+		"Finds the commits whose changes introduce or remove a string (like git log -S) or add or remove lines matching a regular expression (like git log -G), newest first",
+		s.handleGitLogSearch)
+
+	registerTypedTool(s, "git_file_history",
+		"Lists the commits changing a file, newest first, following renames like git log --follow and optionally including the patch of the file in each commit",
+		s.handleGitFileHistory)
+}
+
+func (s *Server) handleGitGrep(ctx context.Context, params gitops.GitGrep) ([]mcp.TextContent, error) {
+	opts := gitops.GrepOptions{
+		Pattern:    params.Pattern,
+		Revision:   params.Revision,
+		Paths:      params.Paths,
+		IgnoreCase: params.IgnoreCase,
+		MaxResults: params.MaxResults,
+	}
+
+	if params.AllRegistered || len(params.RepoPaths) > 0 {
+		repoPaths, err := s.multiRepoPaths(ctx, params.RepoPaths, params.AllRegistered)
+		if err != nil {
+			return nil, err
+		}
+
+		return []mcp.TextContent{{
+			Type: "text",
+			Text: s.git(ctx).MultiGrep(repoPaths, opts),
+		}}, nil
+	}
+
+	repoPath := s.getRepoPath(params.RepoPath)
+	result, err := s.git(ctx).Grep(repoPath, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
+
+func (s *Server) handleGitLogSearch(ctx context.Context, params gitops.GitLogSearch) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+	result, err := s.git(ctx).LogSearch(repoPath, gitops.LogSearchOptions{
+		Pattern:    params.Pattern,
+		Regex:      params.Regex,
+		IgnoreCase: params.IgnoreCase,
+		Range:      params.Range,
+		Path:       params.Path,
+		MaxCount:   params.MaxCount,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
+
+func (s *Server) handleGitFileHistory(ctx context.Context, params gitops.GitFileHistory) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+	result, err := s.git(ctx).FileHistory(repoPath, gitops.FileHistoryOptions{
+		Path:         params.Path,
+		Range:        params.Range,
+		Follow:       boolOr(params.Follow, true),
+		Patch:        params.Patch,
+		ContextLines: intOr(params.ContextLines, gitops.DefaultContextLines),
+		MaxCount:     params.MaxCount,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
@@ -0,0 +1,1031 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/pengcunfu/go-mcp-git/internal/tracing"
+	"github.com/pengcunfu/go-mcp-git/pkg/gitops"
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+	"golang.org/x/crypto/ssh"
+)
+
+// DefaultRepoCacheSize is the default number of repository handles kept
+// open between calls
+const DefaultRepoCacheSize = gitops.DefaultRepoCacheSize
+
+// Config holds the configuration of the MCP Git server
+type Config struct {
+	// Repositories registered with the server. The first one is used as the
+	// default repository when a tool call omits repo_path.
+	Repositories []string
+	UserName     string
+	UserEmail    string
+	// Aliases name repositories, tools accepting repo: "backend" instead
+	// of a repo_path. Targets are paths, registered with the server, or
+	// URLs cloned on first use into AliasCloneDir.
+	Aliases       map[string]string
+	AliasCloneDir string
+	// Workspaces are directories searched at startup for repositories,
+	// DiscoveryDepth levels deep (DefaultDiscoveryDepth when zero). The
+	// repositories found are registered with the server.
+	Workspaces     []string
+	DiscoveryDepth int
+
+	// ScratchDir is where temporary working copies are created
+	ScratchDir string
+	// ScratchMaxBytes is the disk budget of ScratchDir (0 means unlimited)
+	ScratchMaxBytes int64
+	// TrashDir keeps copies of the files discarded by hard resets and
+	// restores, unless NoTrash is set
+	TrashDir string
+	NoTrash  bool
+	// RepoCacheSize is the number of repository handles kept open between
+	// calls (0 opens the repository on every call)
+	RepoCacheSize int
+
+	// CommitPolicy applies to repositories without their own policy
+	CommitPolicy *gitops.CommitPolicy
+	// Auth authenticates fetch and push
+	Auth transport.AuthMethod
+	// SSHAuth authenticates fetch and push to SSH remotes
+	SSHAuth *gitops.SSHAuth
+	// SigningKey signs the commits and tags requesting a signature
+	SigningKey *openpgp.Entity
+	// SSHSigningKey signs them with SSH instead, in repositories with
+	// gpg.format=ssh and no user.signingkey or when SigningKey is nil
+	SSHSigningKey ssh.Signer
+
+	// ReadOnly only exposes tools that do not modify repositories
+	ReadOnly bool
+	// EnableTools only exposes these tools and tool groups (ToolGroupRead,
+	// ToolGroupWrite, ToolGroupRemote, ToolGroupDestructive), all tools
+	// when empty. DisableTools hides tools and groups, winning over
+	// EnableTools.
+	EnableTools  []string
+	DisableTools []string
+	// RestrictRepositories rejects tool calls on unregistered repositories
+	RestrictRepositories bool
+	// Locked hides the tools that modify repositories from every session
+	// until it calls server_unlock
+	Locked bool
+	// IgnoreRoots neither restricts repositories to the workspace roots
+	// of the client nor defaults repo_path to its single root
+	IgnoreRoots bool
+
+	// SafeDirectories are trusted by the git executable regardless of
+	// their owner
+	SafeDirectories []string
+	// NonInteractive prevents the git executable from prompting
+	NonInteractive bool
+
+	// ResultStyle is one of the ResultStyle constants, normal when empty
+	ResultStyle string
+	// MaxOutputBytes truncates larger tool results (0 means unlimited)
+	MaxOutputBytes int
+	// StreamChunkBytes splits larger tool results into content blocks of
+	// this size, sent as partial results to the clients supporting them
+	// (0 sends every result in one block)
+	StreamChunkBytes int
+	// ToolTimeout stops longer tool calls (0 means unlimited), calls
+	// may ask for less with timeout_seconds
+	ToolTimeout time.Duration
+	// CallsPerMinute and ConcurrentCalls bound the tool calls of each
+	// session, PushesPerHour and ClonesPerHour its pushes and clones (0
+	// means unlimited)
+	CallsPerMinute  int
+	ConcurrentCalls int
+	PushesPerHour   int
+	ClonesPerHour   int
+	// DrainTimeout bounds the wait for the tool call in flight when Serve
+	// stops, mcp.DefaultDrainTimeout when zero
+	DrainTimeout time.Duration
+	// ToolsPageSize is the number of tools per page of tools/list, all of
+	// them when zero
+	ToolsPageSize int
+	// Framing separates the messages on stdio, mcp.FramingAuto when empty
+	Framing mcp.Framing
+	// WebSocketAddr serves clients over WebSocket on this address instead
+	// of stdio, e.g. "127.0.0.1:8765"
+	WebSocketAddr string
+	// WebSocket configures the WebSocket transport
+	WebSocket mcp.WebSocketOptions
+	// AllowUnauthenticated serves WebSocket clients without authentication
+	// on addresses other hosts can reach
+	AllowUnauthenticated bool
+	// Metrics serves Prometheus metrics of the tool calls on MetricsPath of
+	// the WebSocket server
+	Metrics bool
+	// Pprof serves the runtime profiles on PprofPath of the WebSocket
+	// server
+	Pprof bool
+	// Tracing, when set, exports a trace of every tool call to an OTLP
+	// collector, the service being described by the server
+	Tracing *TracingOptions
+
+	// ToolPrefix is prepended to the name of every tool, e.g. "repoA_"
+	// exposes git_status as repoA_git_status
+	ToolPrefix string
+}
+
+// serverName and serverVersion describe the server to clients and in traces
+const (
+	serverName    = "go-mcp-git"
+	serverVersion = "0.0.2"
+)
+
+// Server represents the MCP Git server
+type Server struct {
+	mcpServer    *mcp.Server
+	gitOps       *gitops.Operations
+	repository   string
+	repositories []string
+	aliases      *repoAliases
+	// discovered are the repositories found in the workspaces at startup
+	discovered []string
+	userName   string
+	userEmail  string
+
+	readOnly             bool
+	selection            *toolSelection
+	restrictRepositories bool
+	locked               bool
+	ignoreRoots          bool
+	resultStyle          string
+	maxOutputBytes       int
+	streamChunkBytes     int
+	toolTimeout          time.Duration
+	toolPrefix           string
+	websocketAddr        string
+	websocket            mcp.WebSocketOptions
+
+	state         *serverState
+	confirmations *confirmations
+	// tracer exports the traces of the tool calls, shut down by Serve
+	tracer *tracing.Tracer
+	// tools are the tools of this instance by prefixed name
+	tools map[string]mcp.Tool
+	// lockedTools are the prefixed names of the tools hidden while locked
+	lockedTools []string
+	// prefixes holds the tool prefixes in use on mcpServer, shared by all
+	// mounted instances
+	prefixes *toolPrefixes
+}
+
+// New creates a new MCP Git server configured by opts, e.g.
+// New(WithRepositories("/src/app"), WithReadOnly(true)). The configuration
+// is validated before any tool is registered.
+func New(opts ...Option) (*Server, error) {
+	cfg := newConfig(opts)
+	if err := validateConfig(cfg); err != nil {
+		return nil, err
+	}
+	if err := ValidateWebSocket(cfg); err != nil {
+		return nil, err
+	}
+
+	mcpServer := mcp.NewServer(serverName, serverVersion)
+	mcpServer.SetTitle("Git")
+	mcpServer.Use(logCalls(mcpServer))
+	cfg.WebSocket.Handlers = monitoringHandlers(cfg, mcpServer)
+	var tracer *tracing.Tracer
+	if cfg.Tracing != nil {
+		tracer = tracing.NewTracer(tracing.Options{
+			Endpoint:       cfg.Tracing.Endpoint,
+			Headers:        cfg.Tracing.Headers,
+			ServiceName:    serverName,
+			ServiceVersion: serverVersion,
+		})
+		mcpServer.Use(traceCalls(tracer))
+	}
+	prefixes := newToolPrefixes(cfg.ToolPrefix)
+	if limits, ok := rateLimits(cfg, prefixes); ok {
+		mcpServer.SetRateLimits(limits)
+	}
+	if cfg.DrainTimeout > 0 {
+		mcpServer.SetDrainTimeout(cfg.DrainTimeout)
+	}
+	mcpServer.SetToolsPageSize(cfg.ToolsPageSize)
+	if cfg.Framing != "" {
+		mcpServer.SetFraming(cfg.Framing)
+	}
+	server, err := newServer(cfg, mcpServer, prefixes)
+	if err != nil {
+		return nil, err
+	}
+	server.tracer = tracer
+	return server, nil
+}
+
+// Mount registers the tools of another configuration on the same MCP
+// server, so that one process serves several logical instances with their
+// own repositories, identity and guardrails. Every instance needs a distinct
+// tool prefix to keep tool names unique. The process-wide settings of opts
+// (transport, rate limits, tracing) are ignored.
+func (s *Server) Mount(opts ...Option) (*Server, error) {
+	cfg := newConfig(opts)
+	if err := validateConfig(cfg); err != nil {
+		return nil, err
+	}
+	if !s.prefixes.add(cfg.ToolPrefix) {
+		if cfg.ToolPrefix == "" {
+			return nil, fmt.Errorf("a mounted instance needs a tool prefix")
+		}
+		return nil, fmt.Errorf("tool prefix '%s' is already in use", cfg.ToolPrefix)
+	}
+
+	slog.Info("Mounting instance", "tool_prefix", cfg.ToolPrefix, "repositories", len(cfg.Repositories))
+	return newServer(cfg, s.mcpServer, s.prefixes)
+}
+
+// MCP returns the MCP server the tools are registered with, for programs
+// adding tools of their own or serving it on another transport
+func (s *Server) MCP() *mcp.Server {
+	return s.mcpServer
+}
+
+// validateConfig checks the settings of an instance
+func validateConfig(cfg Config) error {
+	if err := ValidateResultStyle(cfg.ResultStyle); err != nil {
+		return err
+	}
+	return ValidateToolPrefix(cfg.ToolPrefix)
+}
+
+// ValidateToolPrefix checks that a tool prefix only holds the characters
+// allowed in tool names
+func ValidateToolPrefix(prefix string) error {
+	if !toolPrefixPattern.MatchString(prefix) {
+		return fmt.Errorf("invalid tool prefix '%s' (expected letters, digits, '_' or '-')", prefix)
+	}
+	return nil
+}
+
+var toolPrefixPattern = regexp.MustCompile(`^[A-Za-z0-9_-]*$`)
+
+func newServer(cfg Config, mcpServer *mcp.Server, prefixes *toolPrefixes) (*Server, error) {
+	gitOps := gitops.New(
+		gitops.WithIdentity(cfg.UserName, cfg.UserEmail),
+		gitops.WithScratch(gitops.NewScratch(cfg.ScratchDir, cfg.ScratchMaxBytes)),
+		gitops.WithRepoCache(gitops.NewRepoCache(cfg.RepoCacheSize)),
+		gitops.WithCommitPolicy(cfg.CommitPolicy),
+		gitops.WithAuth(cfg.Auth),
+		gitops.WithSSHAuth(cfg.SSHAuth),
+		gitops.WithSigningKey(cfg.SigningKey),
+		gitops.WithSSHSigningKey(cfg.SSHSigningKey),
+		gitops.WithSafeDirectories(cfg.SafeDirectories),
+		gitops.WithNonInteractive(cfg.NonInteractive),
+	)
+	if !cfg.NoTrash {
+		gitOps.SetTrash(gitops.NewTrash(cfg.TrashDir, 0))
+	}
+
+	var repository string
+	if len(cfg.Repositories) > 0 {
+		repository = cfg.Repositories[0]
+	}
+
+	server := &Server{
+		mcpServer:    mcpServer,
+		gitOps:       gitOps,
+		repository:   repository,
+		repositories: cfg.Repositories,
+		aliases:      newRepoAliases(cfg.Aliases, cfg.AliasCloneDir),
+		userName:     cfg.UserName,
+		userEmail:    cfg.UserEmail,
+
+		readOnly:             cfg.ReadOnly,
+		selection:            newToolSelection(cfg.EnableTools, cfg.DisableTools),
+		restrictRepositories: cfg.RestrictRepositories,
+		locked:               cfg.Locked && !cfg.ReadOnly,
+		ignoreRoots:          cfg.IgnoreRoots,
+		resultStyle:          cfg.ResultStyle,
+		maxOutputBytes:       cfg.MaxOutputBytes,
+		streamChunkBytes:     cfg.StreamChunkBytes,
+		toolTimeout:          cfg.ToolTimeout,
+		toolPrefix:           cfg.ToolPrefix,
+		websocketAddr:        cfg.WebSocketAddr,
+		websocket:            cfg.WebSocket,
+
+		state:         newServerState(),
+		confirmations: newConfirmations(),
+		tools:         make(map[string]mcp.Tool),
+		prefixes:      prefixes,
+	}
+
+	server.discovered = server.discoverWorkspaces(cfg.Workspaces, cfg.DiscoveryDepth)
+	server.registerTools()
+	if err := server.selection.validate(); err != nil {
+		return nil, err
+	}
+	mcpServer.HandleCompletions(server.complete)
+	mcpServer.HandleResources(server.listResources, server.readResource)
+	return server, nil
+}
+
+// Serve starts the MCP server, until stdin is closed or ctx is done. With
+// a WebSocket address it serves WebSocket clients instead, until ctx is
+// done.
+func (s *Server) Serve(ctx context.Context) error {
+	slog.Info("Starting MCP Git server", "repository", s.repository)
+
+	defer s.shutdownTracer()
+
+	var err error
+	if s.websocketAddr != "" {
+		err = s.mcpServer.ServeWebSocket(ctx, s.websocketAddr, s.websocket)
+	} else {
+		err = s.mcpServer.Serve(ctx)
+	}
+	if err == nil && ctx.Err() != nil {
+		slog.Info("Stopped MCP Git server")
+	}
+	return err
+}
+
+// registerTools registers all Git tools with the MCP server
+func (s *Server) registerTools() {
+	// Git Status
+	registerTypedTool(s, "git_status",
+		"Shows the working tree status grouped into conflicted, staged, unstaged and untracked files with their change types",
+		s.handleGitStatus)
+
+	// Git Diff Unstaged
+	registerTypedTool(s, "git_diff_unstaged",
+		"Shows changes in working directory not yet staged",
+		s.handleGitDiffUnstaged)
+
+	// Git Diff Staged
+	registerTypedTool(s, "git_diff_staged",
+		"Shows changes that are staged for commit",
+		s.handleGitDiffStaged)
+
+	// Git Diff
+	registerTypedTool(s, "git_diff",
+		"Shows differences between a branch or commit and the working tree",
+		s.handleGitDiff)
+
+	// Git Commit
+	registerTypedTool(s, "git_commit",
+		"Records changes to the repository. The message is formatted by the repository's [mcpgit \"commit\"] policy when one is configured",
+		s.handleGitCommit)
+
+	// Git Add
+	registerTypedTool(s, "git_add",
+		"Adds file contents to the staging area. Files may be paths, directories or glob patterns (*.go, src/**)",
+		s.handleGitAdd)
+
+	// Git Reset
+	registerTypedTool(s, "git_reset",
+		"Resets HEAD, the index or individual files to a target commit (soft/mixed/hard)",
+		s.handleGitReset)
+
+	// Git Restore
+	registerTypedTool(s, "git_restore",
+		"Restores working tree files from the index or a revision, discarding their changes",
+		s.handleGitRestore)
+
+	// Git Log
+	registerTypedTool(s, "git_log",
+		"Shows the commit logs of a revision or revision range with optional date, path, author and message filtering",
+		s.handleGitLog)
+
+	// Git Create Branch
+	registerTypedTool(s, "git_create_branch",
+		"Creates a new branch",
+		s.handleGitCreateBranch)
+
+	// Git Checkout
+	registerTypedTool(s, "git_checkout",
+		"Switches branches, creates and switches to a new branch, or detaches HEAD at a commit or tag",
+		s.handleGitCheckout)
+
+	// Git Show
+	registerTypedTool(s, "git_show",
+		"Shows a commit and its patch",
+		s.handleGitShow)
+
+	// Git Branch
+	registerTypedTool(s, "git_branch",
+		"List Git branches",
+		s.handleGitBranch)
+
+	// Git Raw Command
+	registerTypedTool(s, "git_raw_command",
+		"Execute a raw Git command directly (bypasses shell wrapping issues)",
+		s.handleGitRawCommand)
+
+	// Git Init
+	registerTypedTool(s, "git_init",
+		"Initialize a new Git repository",
+		s.handleGitInit)
+
+	// Git Push
+	registerTypedTool(s, "git_push",
+		"Push changes to remote repository",
+		s.handleGitPush)
+
+	// Git List Repositories
+	registerTypedTool(s, "git_list_repositories",
+		"List Git repositories in a directory, and the repository aliases configured",
+		s.handleGitListRepositories)
+
+	// Git Create Tag
+	registerTypedTool(s, "git_create_tag",
+		"Create a new Git tag",
+		s.handleGitCreateTag)
+
+	// Git Delete Tag
+	registerTypedTool(s, "git_delete_tag",
+		"Delete a Git tag",
+		s.handleGitDeleteTag)
+
+	// Git List Tags
+	registerTypedTool(s, "git_list_tags",
+		"List Git tags",
+		s.handleGitListTags)
+
+	// Git Push Tags
+	registerTypedTool(s, "git_push_tags",
+		"Push tags to remote repository",
+		s.handleGitPushTags)
+
+	s.registerBranchTools()
+	s.registerStorageTools()
+	s.registerCompareTools()
+	s.registerMultiRepoTools()
+	s.registerRemoteTools()
+	s.registerSearchTools()
+	s.registerGraphTools()
+	s.registerBlameTools()
+	s.registerTrashTools()
+	s.registerConflictTools()
+	s.registerHunkTools()
+	s.registerApplyTools()
+	s.registerNoteTools()
+	s.registerBundleTools()
+	s.registerBisectTools()
+	s.registerRewriteTools()
+	s.registerVerifyTools()
+	s.registerSuggestTools()
+	s.registerStateTools()
+	s.registerSessionTools()
+	s.registerLockTools()
+}
+
+// createRepoPathProperty creates a standard repo_path property for tool schemas
+func (s *Server) createRepoPathProperty() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "string",
+		"description": "Path to Git repository (optional: auto-detects current Git repository if not provided)",
+	}
+}
+
+// getRepoPath returns the repository path, using intelligent path resolution
+func (s *Server) getRepoPath(providedPath string) string {
+	// 1. 如果提供了路径，处理相对路径和特殊符号
+	if providedPath != "" {
+		// 处理特殊路径符号
+		switch providedPath {
+		case ".", "./":
+			// 当前目录
+			if cwd, err := os.Getwd(); err == nil {
+				return cwd
+			}
+		case "..":
+			// 父目录
+			if cwd, err := os.Getwd(); err == nil {
+				return filepath.Dir(cwd)
+			}
+		}
+
+		// 处理相对路径
+		if !filepath.IsAbs(providedPath) {
+			if cwd, err := os.Getwd(); err == nil {
+				return filepath.Join(cwd, providedPath)
+			}
+		}
+
+		return providedPath
+	}
+
+	// 2. 使用服务器配置的默认仓库路径
+	if s.repository != "" {
+		return s.repository
+	}
+
+	// 3. 自动检测：从当前目录向上查找Git仓库
+	if repoPath := s.findGitRepository(); repoPath != "" {
+		return repoPath
+	}
+
+	// 4. 最后回退到当前目录
+	cwd, _ := os.Getwd()
+	return cwd
+}
+
+// registeredRepositories returns the repositories registered with the
+// server, those named by aliases and discovered in workspaces included
+func (s *Server) registeredRepositories() []string {
+	candidates := append(append([]string(nil), s.repositories...), s.aliases.paths()...)
+	candidates = append(candidates, s.discovered...)
+	seen := make(map[string]bool, len(candidates))
+	repos := make([]string, 0, len(candidates))
+	for _, repo := range candidates {
+		path := s.getRepoPath(repo)
+		if !seen[path] {
+			seen[path] = true
+			repos = append(repos, path)
+		}
+	}
+	return repos
+}
+
+// findGitRepository 从当前目录向上查找Git仓库
+func (s *Server) findGitRepository() string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+
+	// 向上遍历目录树查找.git目录
+	currentDir := cwd
+	for {
+		gitDir := filepath.Join(currentDir, ".git")
+		if _, err := os.Stat(gitDir); err == nil {
+			return currentDir
+		}
+
+		// 到达根目录，停止查找
+		parentDir := filepath.Dir(currentDir)
+		if parentDir == currentDir {
+			break
+		}
+		currentDir = parentDir
+	}
+
+	return ""
+}
+
+// Tool handlers
+
+func (s *Server) handleGitStatus(ctx context.Context, params gitops.GitStatus) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	result, err := s.git(ctx).Status(repoPath, params.IncludeIgnored)
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: s.frame("Repository status", result),
+	}}, nil
+}
+
+func (s *Server) handleGitDiffUnstaged(ctx context.Context, params gitops.GitDiffUnstaged) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	result, err := s.git(ctx).DiffUnstaged(repoPath, params.Options())
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: s.frame("Unstaged changes", result),
+	}}, nil
+}
+
+func (s *Server) handleGitDiffStaged(ctx context.Context, params gitops.GitDiffStaged) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	result, err := s.git(ctx).DiffStaged(repoPath, params.Options())
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: s.frame("Staged changes", result),
+	}}, nil
+}
+
+func (s *Server) handleGitDiff(ctx context.Context, params gitops.GitDiff) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	result, err := s.git(ctx).Diff(repoPath, params.Target, params.Options())
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: s.frame("Diff with "+params.Target, result),
+	}}, nil
+}
+
+func (s *Server) handleGitCommit(ctx context.Context, params gitops.GitCommit) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	result, err := s.git(ctx).Commit(repoPath, params.Message, gitops.CommitOptions{
+		Files:      params.Files,
+		All:        params.All,
+		AllowEmpty: params.AllowEmpty,
+		Trailers:   params.Trailers,
+		Signoff:    params.Signoff,
+		Sign:       params.Sign,
+
+		AuthorName:     params.AuthorName,
+		AuthorEmail:    params.AuthorEmail,
+		AuthorDate:     params.AuthorDate,
+		CommitterName:  params.CommitterName,
+		CommitterEmail: params.CommitterEmail,
+		CommitterDate:  params.CommitterDate,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
+
+func (s *Server) handleGitAdd(ctx context.Context, params gitops.GitAdd) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	result, err := s.git(ctx).Add(repoPath, params.Files, gitops.AddOptions{
+		All:    params.All,
+		Update: params.Update,
+		Force:  params.Force,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
+
+func (s *Server) handleGitReset(ctx context.Context, params gitops.GitReset) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	// Hard resets only get here once confirmed with their token
+	result, err := s.git(ctx).Reset(repoPath, params.Mode, params.Target, params.Files, isConfirmed(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
+
+func (s *Server) handleGitRestore(ctx context.Context, params gitops.GitRestore) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+	worktree := boolOr(params.Worktree, !params.Staged)
+
+	result, err := s.git(ctx).Restore(repoPath, params.Files, params.Source, params.Staged, worktree)
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
+
+func (s *Server) handleGitLog(ctx context.Context, params gitops.GitLog) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	commits, err := s.git(ctx).Log(repoPath, gitops.LogOptions{
+		Range:          params.Range,
+		From:           params.From,
+		To:             params.To,
+		MaxCount:       intOr(params.MaxCount, 10),
+		StartTimestamp: params.StartTimestamp,
+		EndTimestamp:   params.EndTimestamp,
+		Path:           params.Path,
+		Author:         params.Author,
+		Grep:           params.Grep,
+		Parents:        params.Parents,
+		Decorate:       params.Decorate,
+		Graph:          params.Graph,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result string
+	for _, commit := range commits {
+		result += commit + "\n"
+	}
+
+	// Commits of an interrupted rebase or merge are not on HEAD yet
+	operation, err := s.git(ctx).OperationInProgress(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	if operation != "" {
+		result = operation + "\n\n" + result
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: s.frame("Commit history", result),
+	}}, nil
+}
+
+func (s *Server) handleGitCreateBranch(ctx context.Context, params gitops.GitCreateBranch) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	result, err := s.git(ctx).CreateBranch(repoPath, params.BranchName, params.BaseBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
+
+func (s *Server) handleGitCheckout(ctx context.Context, params gitops.GitCheckout) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	result, err := s.git(ctx).Checkout(repoPath, params.BranchName, params.Create, params.StartPoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
+
+func (s *Server) handleGitShow(ctx context.Context, params gitops.GitShow) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+	opts := gitops.ShowOptions{
+		Revision:    params.Revision,
+		Paths:       params.Paths,
+		DiffOptions: params.Options(),
+	}
+
+	result, err := s.git(ctx).Show(repoPath, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
+
+func (s *Server) handleGitBranch(ctx context.Context, params gitops.GitBranch) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+	branchType := params.BranchType
+	if branchType == "" {
+		branchType = "local"
+	}
+
+	result, err := s.git(ctx).Branch(repoPath, branchType, params.Contains, params.NotContains, params.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
+
+// Helper functions for extracting values from arguments
+
+func getString(args map[string]interface{}, key string) string {
+	if val, ok := args[key]; ok {
+		if str, ok := val.(string); ok {
+			return str
+		}
+	}
+	return ""
+}
+
+func getInt(args map[string]interface{}, key string, defaultVal int) int {
+	if val, ok := args[key]; ok {
+		switch v := val.(type) {
+		case int:
+			return v
+		case float64:
+			return int(v)
+		case json.Number:
+			if i, err := v.Int64(); err == nil {
+				return int(i)
+			}
+		}
+	}
+	return defaultVal
+}
+
+func getStringSlice(args map[string]interface{}, key string) []string {
+	if val, ok := args[key]; ok {
+		if slice, ok := val.([]interface{}); ok {
+			result := make([]string, 0, len(slice))
+			for _, item := range slice {
+				if str, ok := item.(string); ok {
+					result = append(result, str)
+				}
+			}
+			return result
+		}
+	}
+	return []string{}
+}
+
+func getBool(args map[string]interface{}, key string, defaultVal bool) bool {
+	if val, ok := args[key]; ok {
+		if b, ok := val.(bool); ok {
+			return b
+		}
+	}
+	return defaultVal
+}
+
+// boolOr returns an optional boolean argument of a typed tool, defaultVal
+// when it is missing
+func boolOr(val *bool, defaultVal bool) bool {
+	if val == nil {
+		return defaultVal
+	}
+	return *val
+}
+
+// intOr returns an optional integer argument of a typed tool, defaultVal
+// when it is missing
+func intOr(val *int, defaultVal int) int {
+	if val == nil {
+		return defaultVal
+	}
+	return *val
+}
+
+func (s *Server) handleGitRawCommand(ctx context.Context, params gitops.GitRawCommand) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	result, err := s.git(ctx).RawCommand(repoPath, params.Command)
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
+
+func (s *Server) handleGitInit(ctx context.Context, params gitops.GitInit) ([]mcp.TextContent, error) {
+	result, err := s.git(ctx).Init(params.RepoPath, params.Bare)
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
+
+func (s *Server) handleGitPush(ctx context.Context, params gitops.GitPush) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	result, err := s.git(ctx).Push(repoPath, gitops.PushOptions{
+		Remote:         params.Remote,
+		Refspec:        params.Refspec,
+		Tags:           params.Tags,
+		Force:          params.Force,
+		ForceWithLease: params.ForceWithLease,
+		SetUpstream:    params.SetUpstream,
+		Delete:         params.Delete,
+		Mirror:         params.Mirror,
+		DryRun:         params.DryRun,
+		Options:        params.PushOptions,
+		Credentials:    params.Credentials(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
+
+func (s *Server) handleGitListRepositories(ctx context.Context, params gitops.GitListRepositories) ([]mcp.TextContent, error) {
+	searchPath := params.SearchPath
+
+	// Without a search path the repositories registered and discovered at
+	// startup are listed, when there are any
+	title := "Found Git repositories"
+	repositories := s.registeredRepositories()
+	if searchPath != "" || len(repositories) == 0 {
+		var err error
+		if repositories, err = s.git(ctx).ListRepositories(searchPath, params.Recursive); err != nil {
+			return nil, err
+		}
+	} else {
+		title = "Registered Git repositories"
+	}
+
+	text := "No Git repositories found"
+	if len(repositories) > 0 {
+		text = s.list(title, repositories)
+	}
+	if aliases := s.describeAliases(); len(aliases) > 0 {
+		text += "\n\n" + s.list("Repository aliases, accepted as repo", aliases)
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: text,
+	}}, nil
+}
+
+func (s *Server) handleGitCreateTag(ctx context.Context, params gitops.GitCreateTag) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+	annotated := boolOr(params.Annotated, true)
+
+	result, err := s.git(ctx).CreateTag(repoPath, params.TagName, params.Message, annotated, params.Sign)
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
+
+func (s *Server) handleGitDeleteTag(ctx context.Context, params gitops.GitDeleteTag) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	result, err := s.git(ctx).DeleteTag(repoPath, params.TagName)
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
+
+func (s *Server) handleGitListTags(ctx context.Context, params gitops.GitListTags) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	tags, err := s.git(ctx).ListTags(repoPath, params.Pattern, params.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tags) == 0 {
+		return []mcp.TextContent{{
+			Type: "text",
+			Text: "No tags found",
+		}}, nil
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: s.list("Tags", tags),
+	}}, nil
+}
+
+func (s *Server) handleGitPushTags(ctx context.Context, params gitops.GitPushTags) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	result, err := s.git(ctx).PushTags(repoPath, params.Remote, params.TagName, params.Credentials())
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
@@ -0,0 +1,114 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+// sessionDefaults are the defaults a client chose for its session with
+// server_set_defaults, overriding those of the server configuration
+type sessionDefaults struct {
+	repoPath  string
+	userName  string
+	userEmail string
+}
+
+// sessionDefaultsKey holds the defaults of a session among its values
+type sessionDefaultsKey struct{}
+
+// defaultsOf returns the defaults of the session of a call, none outside
+// sessions
+func defaultsOf(ctx context.Context) sessionDefaults {
+	session := mcp.SessionFromContext(ctx)
+	if session == nil {
+		return sessionDefaults{}
+	}
+	defaults, _ := session.Value(sessionDefaultsKey{}).(sessionDefaults)
+	return defaults
+}
+
+// applySessionDefaults wraps the handler of a tool taking a repository so
+// that calls without repo_path run in the default repository of their
+// session, when the client set one
+func (s *Server) applySessionDefaults(tool mcp.Tool, handler mcp.ToolHandler) mcp.ToolHandler {
+	if _, ok := schemaProperties(tool)["repo_path"]; !ok {
+		return handler
+	}
+
+	return func(ctx context.Context, arguments map[string]interface{}) ([]mcp.TextContent, error) {
+		repoPath := defaultsOf(ctx).repoPath
+		if repoPath == "" || getString(arguments, "repo_path") != "" {
+			return handler(ctx, arguments)
+		}
+		defaulted := make(map[string]interface{}, len(arguments)+1)
+		for key, value := range arguments {
+			defaulted[key] = value
+		}
+		defaulted["repo_path"] = repoPath
+		return handler(ctx, defaulted)
+	}
+}
+
+// ServerSetDefaults represents the parameters for setting the defaults of a
+// session, nil values keeping the current ones
+type ServerSetDefaults struct {
+	Repository *string `json:"repository,omitempty" description:"Repository, or repository alias, of the calls without repo_path, checked against the workspace roots and registered repositories on each call"`
+	UserName   *string `json:"user_name,omitempty" description:"Name of the author and committer of commits and tags"`
+	UserEmail  *string `json:"user_email,omitempty" description:"Email of the author and committer of commits and tags"`
+}
+
+// registerSessionTools registers the tools setting the defaults of a
+// session
+func (s *Server) registerSessionTools() {
+	registerTypedTool(s, "server_set_defaults",
+		"Sets defaults for the rest of this session, leaving other clients of the server unaffected: the repository of calls without repo_path and the identity of commits and tags. Omitted values are kept, empty values reset to the server configuration",
+		s.handleServerSetDefaults)
+}
+
+func (s *Server) handleServerSetDefaults(ctx context.Context, params ServerSetDefaults) ([]mcp.TextContent, error) {
+	session := mcp.SessionFromContext(ctx)
+	if session == nil {
+		return nil, fmt.Errorf("no session to set defaults for")
+	}
+
+	defaults := defaultsOf(ctx)
+	if params.Repository != nil {
+		defaults.repoPath = ""
+		if repoPath := *params.Repository; repoPath != "" {
+			defaults.repoPath = s.getRepoPath(repoPath)
+			if _, ok := s.aliases.path(repoPath); ok {
+				path, err := s.aliasPath(repoPath)
+				if err != nil {
+					return nil, err
+				}
+				defaults.repoPath = s.getRepoPath(path)
+			}
+			// URL aliases are only cloned by the first call using them
+			_, _, isURL := s.urlAlias(defaults.repoPath)
+			if info, err := os.Stat(defaults.repoPath); !isURL && (err != nil || !info.IsDir()) {
+				return nil, fmt.Errorf("repository %s is not a directory", defaults.repoPath)
+			}
+		}
+	}
+	if params.UserName != nil {
+		defaults.userName = *params.UserName
+	}
+	if params.UserEmail != nil {
+		defaults.userEmail = *params.UserEmail
+	}
+	session.SetValue(sessionDefaultsKey{}, defaults)
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Defaults of session #%s:\n", session.ID()))
+	result.WriteString(fmt.Sprintf("  Repository: %s\n", orDefault(defaults.repoPath, "server default")))
+	result.WriteString(fmt.Sprintf("  User name: %s\n", orDefault(defaults.userName, "server default")))
+	result.WriteString(fmt.Sprintf("  User email: %s\n", orDefault(defaults.userEmail, "server default")))
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result.String(),
+	}}, nil
+}
@@ -0,0 +1,269 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+// maxRecentErrors is the number of failed tool calls kept for
+// server_dump_state
+const maxRecentErrors = 20
+
+// toolCall is a tool call being handled
+type toolCall struct {
+	tool     string
+	repoPath string
+	started  time.Time
+}
+
+// failedCall is a tool call that returned an error
+type failedCall struct {
+	toolCall
+	duration time.Duration
+	code     string
+	err      string
+}
+
+// serverState records the activity of the server for server_dump_state
+type serverState struct {
+	mu       sync.Mutex
+	started  time.Time
+	nextID   int
+	inFlight map[int]toolCall
+	calls    map[string]int
+	failures map[string]int
+	recent   []failedCall
+}
+
+func newServerState() *serverState {
+	return &serverState{
+		started:  time.Now(),
+		inFlight: make(map[int]toolCall),
+		calls:    make(map[string]int),
+		failures: make(map[string]int),
+	}
+}
+
+// begin records the start of a tool call and returns its ID
+func (st *serverState) begin(call toolCall) int {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	st.nextID++
+	st.inFlight[st.nextID] = call
+	st.calls[call.tool]++
+	return st.nextID
+}
+
+// end records the completion of a tool call, keeping its error if it failed
+func (st *serverState) end(id int, err error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	call := st.inFlight[id]
+	delete(st.inFlight, id)
+	if err == nil {
+		return
+	}
+
+	st.failures[call.tool]++
+	failure := failedCall{toolCall: call, duration: time.Since(call.started), err: err.Error()}
+	if toolErr, ok := err.(*mcp.ToolError); ok {
+		failure.code = toolErr.Code
+	}
+	st.recent = append(st.recent, failure)
+	if len(st.recent) > maxRecentErrors {
+		st.recent = st.recent[len(st.recent)-maxRecentErrors:]
+	}
+}
+
+// trackCalls wraps a handler so that its calls show up in server_dump_state
+func (s *Server) trackCalls(tool mcp.Tool, handler mcp.ToolHandler) mcp.ToolHandler {
+	return func(ctx context.Context, arguments map[string]interface{}) ([]mcp.TextContent, error) {
+		id := s.state.begin(toolCall{
+			tool:     tool.Name,
+			repoPath: getString(arguments, "repo_path"),
+			started:  time.Now(),
+		})
+		finished := false
+		defer func() {
+			// A panicking call ends too, the panic going on to the
+			// recovery of the MCP server
+			if !finished {
+				s.state.end(id, &mcp.ToolError{Code: mcp.ErrorCodeInternal, Err: fmt.Errorf("handler panicked")})
+			}
+		}()
+		content, err := handler(ctx, arguments)
+		finished = true
+		s.state.end(id, err)
+		return content, err
+	}
+}
+
+// logCalls logs every tool call with its duration and outcome, at the debug
+// level (-vv) of the server log and as a log message to the client, so that
+// users see the git operations an agent ran
+func logCalls(mcpServer *mcp.Server) mcp.Middleware {
+	return func(next mcp.ToolHandler) mcp.ToolHandler {
+		return func(ctx context.Context, arguments map[string]interface{}) ([]mcp.TextContent, error) {
+			started := time.Now()
+			content, err := next(ctx, arguments)
+			duration := time.Since(started).Round(time.Millisecond)
+
+			tool, repoPath := mcp.ToolName(ctx), getString(arguments, "repo_path")
+			attrs := []any{"tool", tool, "repo_path", repoPath, "duration", duration}
+			message := map[string]interface{}{"tool": tool, "duration_ms": duration.Milliseconds()}
+			if repoPath != "" {
+				message["repo_path"] = repoPath
+			}
+			if err != nil {
+				slog.DebugContext(ctx, "Tool call failed", append(attrs, "error", err)...)
+				message["error"] = err.Error()
+				if toolErr, ok := err.(*mcp.ToolError); ok {
+					message["code"] = toolErr.Code
+				}
+				mcpServer.Log(ctx, "warning", "tools", message)
+			} else {
+				slog.DebugContext(ctx, "Tool call completed", attrs...)
+				mcpServer.Log(ctx, "info", "tools", message)
+			}
+			return content, err
+		}
+	}
+}
+
+// ServerDumpState represents the parameters for reporting the state of the
+// server, which takes none
+type ServerDumpState struct{}
+
+// registerStateTools registers the diagnostic tools of the server itself
+func (s *Server) registerStateTools() {
+	registerTypedTool(s, "server_dump_state",
+		"Reports the state of the server for troubleshooting: configuration, in-flight tool calls, call counts, scratch usage and recent errors",
+		s.handleServerDumpState)
+}
+
+func (s *Server) handleServerDumpState(ctx context.Context, params ServerDumpState) ([]mcp.TextContent, error) {
+	scratch, err := s.git(ctx).ScratchUsage()
+	if err != nil {
+		scratch = fmt.Sprintf("Scratch: %v", err)
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: s.state.dump(s, mcp.SessionFromContext(ctx), scratch),
+	}}, nil
+}
+
+// dump renders the recorded state along with the server configuration, as
+// seen by the session of the caller
+func (st *serverState) dump(s *Server, caller *mcp.Session, scratch string) string {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	now := time.Now()
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Uptime: %s (since %s)\n", now.Sub(st.started).Round(time.Second), st.started.Format(time.RFC3339)))
+
+	sessions := s.mcpServer.Sessions()
+	result.WriteString(fmt.Sprintf("Sessions: %d\n", len(sessions)))
+	for _, session := range sessions {
+		client := orNone(strings.TrimSpace(session.Client.Name + " " + session.Client.Version))
+		version := orNone(session.ProtocolVersion)
+		if !session.Initialized {
+			version = "not initialized"
+		}
+		result.WriteString(fmt.Sprintf("  #%s: client %s, protocol version %s, connected for %s\n", session.ID, client, version, now.Sub(session.Started).Round(time.Second)))
+	}
+
+	result.WriteString("Configuration:\n")
+	result.WriteString(fmt.Sprintf("  Repositories: %s\n", orNone(strings.Join(s.repositories, ", "))))
+	result.WriteString(fmt.Sprintf("  Read-only: %t, restricted to registered repositories: %t\n", s.readOnly, s.restrictRepositories))
+	if selection := s.selection.describe(); selection != "" {
+		result.WriteString(fmt.Sprintf("  Tool selection: %s, %d tools exposed\n", selection, len(s.tools)))
+	}
+	if s.locked {
+		locked := !s.unlocked(caller)
+		result.WriteString(fmt.Sprintf("  Locked mode: %d write tools, locked for this session: %t\n", len(s.lockedTools), locked))
+	}
+	result.WriteString(fmt.Sprintf("  Result style: %s, output limit: %s\n", orDefault(s.resultStyle, ResultStyleNormal), formatLimit(s.maxOutputBytes)))
+	if s.toolTimeout > 0 {
+		result.WriteString(fmt.Sprintf("  Tool timeout: %s\n", s.toolTimeout))
+	} else {
+		result.WriteString("  Tool timeout: none\n")
+	}
+	if s.toolPrefix != "" {
+		result.WriteString(fmt.Sprintf("  Tool prefix: %s\n", s.toolPrefix))
+	}
+	if id := s.gitOps.SigningKeyID(); id != "" {
+		result.WriteString(fmt.Sprintf("  Signing key: %s\n", id))
+	}
+	if cache := s.gitOps.RepoCacheStats(); cache.Size > 0 {
+		result.WriteString(fmt.Sprintf("  Repository handles: %d of %d cached, %d hits, %d misses\n", cache.Idle, cache.Size, cache.Hits, cache.Misses))
+	} else {
+		result.WriteString("  Repository handles: not cached, each call opens its repository\n")
+	}
+	result.WriteString(fmt.Sprintf("  Repository locks: held by the calls in flight, %d repositories locked now; no file watches\n", s.gitOps.LockedRepositories()))
+
+	ids := make([]int, 0, len(st.inFlight))
+	for id := range st.inFlight {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	result.WriteString(fmt.Sprintf("In-flight calls (%d):\n", len(ids)))
+	for _, id := range ids {
+		call := st.inFlight[id]
+		result.WriteString(fmt.Sprintf("  #%d %s%s running for %s\n", id, call.tool, repoSuffix(call.repoPath), now.Sub(call.started).Round(time.Millisecond)))
+	}
+
+	tools := make([]string, 0, len(st.calls))
+	for tool := range st.calls {
+		tools = append(tools, tool)
+	}
+	sort.Strings(tools)
+	result.WriteString(fmt.Sprintf("Calls by tool (%d tools):\n", len(tools)))
+	for _, tool := range tools {
+		result.WriteString(fmt.Sprintf("  %s: %d calls, %d failed\n", tool, st.calls[tool], st.failures[tool]))
+	}
+
+	result.WriteString(fmt.Sprintf("Recent errors (%d, newest first):\n", len(st.recent)))
+	for i := len(st.recent) - 1; i >= 0; i-- {
+		failure := st.recent[i]
+		result.WriteString(fmt.Sprintf("  %s %s%s after %s: [%s] %s\n", failure.started.Format(time.RFC3339), failure.tool, repoSuffix(failure.repoPath), failure.duration.Round(time.Millisecond), failure.code, failure.err))
+	}
+
+	result.WriteString(scratch)
+	return strings.TrimRight(result.String(), "\n")
+}
+
+func repoSuffix(repoPath string) string {
+	if repoPath == "" {
+		return ""
+	}
+	return " on " + repoPath
+}
+
+func orNone(s string) string {
+	return orDefault(s, "none")
+}
+
+func orDefault(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
+
+func formatLimit(bytes int) string {
+	if bytes <= 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%d KiB", bytes>>10)
+}
@@ -0,0 +1,147 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pengcunfu/go-mcp-git/pkg/gitops"
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+// registerStorageTools registers tools reporting on and compacting
+// repository storage, and managing temporary working copies in the scratch
+// directory
+func (s *Server) registerStorageTools() {
+	registerTypedTool(s, "git_disk_usage",
+		"Reports working tree size, .git size and pack breakdown of repositories, plus scratch directory usage",
+		s.handleGitDiskUsage)
+
+	registerTypedTool(s, "git_maintenance",
+		"Compacts the object database of a repository with git gc, repack or prune, reporting object counts and sizes before and after",
+		s.handleGitMaintenance)
+
+	registerTypedTool(s, "git_large_files",
+		"Scans history for blobs over a size threshold, reporting their paths and the commits that introduced them, to decide what to move to LFS or purge",
+		s.handleGitLargeFiles)
+
+	registerTypedTool(s, "git_lfs_files",
+		"Lists the files stored in Git LFS and the .gitattributes patterns tracking them, telling which are checked out as pointers instead of their content",
+		s.handleGitLFSFiles)
+
+	registerTypedTool(s, "git_materialize_revision",
+		"Checks out a revision into a managed temporary directory without touching the worktree and returns its path",
+		s.handleGitMaterializeRevision)
+
+	registerTypedTool(s, "git_remove_materialized",
+		"Removes a temporary directory created by git_materialize_revision",
+		s.handleGitRemoveMaterialized)
+}
+
+func (s *Server) handleGitDiskUsage(ctx context.Context, params gitops.GitDiskUsage) ([]mcp.TextContent, error) {
+	var repoPaths []string
+	if params.AllRegistered {
+		repoPaths = s.callRegistered(ctx)
+		if len(repoPaths) == 0 {
+			return nil, fmt.Errorf("no repositories registered; start the server with --repository")
+		}
+	} else {
+		repoPaths = []string{s.getRepoPath(params.RepoPath)}
+	}
+
+	var sections []string
+	for _, repoPath := range repoPaths {
+		usage, err := s.git(ctx).DiskUsage(repoPath)
+		if err != nil {
+			return nil, err
+		}
+		sections = append(sections, usage)
+	}
+
+	scratch, err := s.git(ctx).ScratchUsage()
+	if err != nil {
+		return nil, err
+	}
+	sections = append(sections, scratch)
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: strings.Join(sections, "\n\n"),
+	}}, nil
+}
+
+func (s *Server) handleGitMaintenance(ctx context.Context, params gitops.GitMaintenance) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	result, err := s.git(ctx).Maintenance(repoPath, gitops.MaintenanceOptions{
+		Task:        params.Task,
+		PruneExpire: params.PruneExpire,
+		Aggressive:  params.Aggressive,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
+
+func (s *Server) handleGitLargeFiles(ctx context.Context, params gitops.GitLargeFiles) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	result, err := s.git(ctx).LargeFiles(repoPath, gitops.LargeFileOptions{
+		Threshold: int64(params.ThresholdKB) << 10,
+		Range:     params.Range,
+		MaxCount:  params.MaxCount,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
+
+func (s *Server) handleGitLFSFiles(ctx context.Context, params gitops.GitLFSFiles) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	result, err := s.git(ctx).LFSFiles(repoPath, params.Revision)
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
+
+func (s *Server) handleGitMaterializeRevision(ctx context.Context, params gitops.GitMaterializeRevision) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	dir, hash, err := s.git(ctx).MaterializeRevision(repoPath, params.Revision)
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: fmt.Sprintf("Materialized %s at %s", hash, dir),
+	}}, nil
+}
+
+func (s *Server) handleGitRemoveMaterialized(ctx context.Context, params gitops.GitRemoveMaterialized) ([]mcp.TextContent, error) {
+	result, err := s.git(ctx).RemoveMaterialized(params.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
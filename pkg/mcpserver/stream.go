@@ -0,0 +1,72 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+// DefaultStreamChunkBytes is the default size of the content blocks large
+// tool results are split into
+const DefaultStreamChunkBytes = 64 << 10
+
+// streamOutput wraps a handler so that results larger than streamChunkBytes
+// are split at line boundaries into several content blocks. Calls giving a
+// progress token are notified as each block is sent, and clients supporting
+// partial results get every block but the last one in these notifications,
+// the response only holding the last block instead of the whole result.
+func (s *Server) streamOutput(handler mcp.ToolHandler) mcp.ToolHandler {
+	return func(ctx context.Context, arguments map[string]interface{}) ([]mcp.TextContent, error) {
+		content, err := handler(ctx, arguments)
+		if err != nil {
+			return content, err
+		}
+
+		total := 0
+		for _, block := range content {
+			total += len(block.Text)
+		}
+		if total <= s.streamChunkBytes {
+			return content, nil
+		}
+
+		var blocks []mcp.TextContent
+		for _, block := range content {
+			for _, chunk := range splitText(block.Text, s.streamChunkBytes) {
+				blocks = append(blocks, mcp.TextContent{Type: block.Type, Text: chunk})
+			}
+		}
+
+		var kept []mcp.TextContent
+		sent := 0
+		for i, block := range blocks {
+			sent += len(block.Text)
+			message := fmt.Sprintf("Block %d of %d, %d of %d bytes", i+1, len(blocks), sent, total)
+			if i < len(blocks)-1 && mcp.SendPartialResult(ctx, []mcp.TextContent{block}, float64(sent), float64(total), message) {
+				continue
+			}
+			if i < len(blocks)-1 {
+				mcp.ReportProgress(ctx, float64(sent), float64(total), message)
+			}
+			kept = append(kept, block)
+		}
+		return kept, nil
+	}
+}
+
+// splitText cuts text into parts of at most size bytes, each ending at a
+// line boundary when it can, without copying it
+func splitText(text string, size int) []string {
+	var parts []string
+	for len(text) > size {
+		part := truncateText(text, size)
+		if part == "" {
+			// A UTF-8 sequence longer than size
+			part = text[:size]
+		}
+		parts = append(parts, part)
+		text = text[len(part):]
+	}
+	return append(parts, text)
+}
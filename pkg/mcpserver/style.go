@@ -0,0 +1,75 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+// Result styles controlling the prose around tool results
+const (
+	// ResultStyleMinimal returns the raw data only
+	ResultStyleMinimal = "minimal"
+	// ResultStyleNormal adds a title line to results
+	ResultStyleNormal = "normal"
+	// ResultStyleVerbose also names the tool and repository of each result
+	ResultStyleVerbose = "verbose"
+)
+
+// ResultStyles lists the accepted result styles
+var ResultStyles = []string{ResultStyleMinimal, ResultStyleNormal, ResultStyleVerbose}
+
+// ValidateResultStyle checks a result style, the empty string selecting the
+// normal style
+func ValidateResultStyle(style string) error {
+	switch style {
+	case "", ResultStyleMinimal, ResultStyleNormal, ResultStyleVerbose:
+		return nil
+	}
+	return fmt.Errorf("unknown result style '%s' (expected one of %s)", style, strings.Join(ResultStyles, ", "))
+}
+
+// frame puts a title line above a tool result, except in minimal style
+func (s *Server) frame(title, body string) string {
+	if s.resultStyle == ResultStyleMinimal {
+		return body
+	}
+	return title + ":\n" + body
+}
+
+// list renders items as a titled bullet list, or one item per line in
+// minimal style
+func (s *Server) list(title string, items []string) string {
+	if s.resultStyle == ResultStyleMinimal {
+		return strings.Join(items, "\n")
+	}
+
+	var result strings.Builder
+	result.WriteString(title + ":")
+	for _, item := range items {
+		result.WriteString("\n- " + item)
+	}
+	return result.String()
+}
+
+// describeResults wraps a handler so that its results start with the name
+// of the tool and, for tools taking repo_path, the repository it ran in
+func (s *Server) describeResults(tool mcp.Tool, handler mcp.ToolHandler) mcp.ToolHandler {
+	_, takesRepoPath := schemaProperties(tool)["repo_path"]
+
+	return func(ctx context.Context, arguments map[string]interface{}) ([]mcp.TextContent, error) {
+		content, err := handler(ctx, arguments)
+		if err != nil || len(content) == 0 {
+			return content, err
+		}
+
+		header := "Tool: " + tool.Name
+		if takesRepoPath {
+			header += "\nRepository: " + s.getRepoPath(getString(arguments, "repo_path"))
+		}
+		content[0].Text = header + "\n\n" + content[0].Text
+		return content, nil
+	}
+}
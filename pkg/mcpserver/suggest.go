@@ -0,0 +1,105 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pengcunfu/go-mcp-git/pkg/gitops"
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+// suggestMaxDiffBytes caps the staged diff sent to the client's model
+const suggestMaxDiffBytes = 48 << 10
+
+// suggestRecentCommits is the number of commits shown to the model as
+// examples of the style of the repository
+const suggestRecentCommits = 10
+
+// suggestSystemPrompt instructs the client's model to write a commit message
+const suggestSystemPrompt = `You write git commit messages following the Conventional Commits specification.
+The first line is "type(scope): summary", type being one of feat, fix, docs, style, refactor, perf, test, build, ci or chore, the scope optional, the summary in the imperative mood and the whole line at most 72 characters.
+When the change needs explaining, add a blank line and a body saying what changed and why, wrapped at 72 columns.
+Reply with the commit message only, without code fences or commentary.`
+
+// registerSuggestTools registers the tools drafting text with the model of
+// the client, through MCP sampling
+func (s *Server) registerSuggestTools() {
+	registerTypedTool(s, "git_suggest_commit_message",
+		"Drafts a Conventional Commits message for the staged changes with the client's model (MCP sampling) and returns it for review. It does not commit: pass the approved message to git_commit",
+		s.handleGitSuggestCommitMessage)
+}
+
+func (s *Server) handleGitSuggestCommitMessage(ctx context.Context, params gitops.GitSuggestCommitMessage) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	diff, err := s.git(ctx).DiffStaged(repoPath, gitops.DiffOptions{ContextLines: gitops.DefaultContextLines})
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(diff) == "" {
+		return nil, fmt.Errorf("nothing to commit: no staged changes, stage them with git_add first")
+	}
+	if len(diff) > suggestMaxDiffBytes {
+		cut := strings.LastIndexByte(diff[:suggestMaxDiffBytes], '\n')
+		if cut < 0 {
+			cut = suggestMaxDiffBytes
+		}
+		diff = diff[:cut] + fmt.Sprintf("\n[diff truncated, %d of %d bytes shown]", cut, len(diff))
+	}
+
+	var prompt strings.Builder
+	// An unborn branch has no commits to learn the style from
+	if recent, err := s.git(ctx).Log(repoPath, gitops.LogOptions{MaxCount: suggestRecentCommits, Graph: true}); err == nil && len(recent) > 0 {
+		prompt.WriteString("Recent commits of the repository, showing its style and scopes:\n")
+		prompt.WriteString(strings.Join(recent, "\n"))
+		prompt.WriteString("\n\n")
+	}
+	if params.Hint != "" {
+		prompt.WriteString("What the change is about, according to the user: " + params.Hint + "\n\n")
+	}
+	prompt.WriteString("Staged changes:\n" + diff)
+
+	speed := 0.7
+	result, err := s.mcpServer.CreateMessage(ctx, mcp.CreateMessageRequest{
+		Messages:         []mcp.SamplingMessage{{Role: "user", Content: mcp.TextContent{Type: "text", Text: prompt.String()}}},
+		SystemPrompt:     suggestSystemPrompt,
+		MaxTokens:        400,
+		ModelPreferences: &mcp.ModelPreferences{SpeedPriority: &speed},
+	})
+	if errors.Is(err, mcp.ErrSamplingNotSupported) {
+		return nil, fmt.Errorf("the client does not support sampling, draft the message from git_diff_staged instead")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	message := cleanSuggestion(result.Content.Text)
+	if message == "" {
+		return nil, fmt.Errorf("the client's model returned an empty message")
+	}
+	title := "Suggested commit message, not committed yet"
+	if result.Model != "" {
+		title += fmt.Sprintf(" (drafted by %s)", result.Model)
+	}
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: s.frame(title, message),
+	}}, nil
+}
+
+// cleanSuggestion strips the code fences and blank lines models tend to
+// wrap messages in
+func cleanSuggestion(text string) string {
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "```") && strings.HasSuffix(text, "```") {
+		text = strings.TrimSuffix(text, "```")
+		if newline := strings.IndexByte(text, '\n'); newline >= 0 {
+			text = text[newline+1:]
+		} else {
+			text = ""
+		}
+	}
+	return strings.TrimSpace(text)
+}
@@ -0,0 +1,126 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/pengcunfu/go-mcp-git/pkg/gitops"
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+// DefaultToolTimeout is the default limit of the duration of a tool call
+const DefaultToolTimeout = 10 * time.Minute
+
+// timeoutGrace is how long a call past its timeout is waited for once
+// cancelled, for its outcome to be known
+const timeoutGrace = 10 * time.Second
+
+// git returns the git operations bound to the context of a call, so that
+// network operations and the git executable stop with it, committing as the
+// identity the session of the call set, if any
+func (s *Server) git(ctx context.Context) *gitops.Operations {
+	defaults := defaultsOf(ctx)
+	return s.gitOps.WithContext(ctx).WithIdentity(defaults.userName, defaults.userEmail)
+}
+
+// limitDuration wraps a handler so that calls are cancelled once they run
+// longer than the tool timeout, or than their timeout_seconds argument when
+// it is shorter. The handler is then waited for timeoutGrace: a call that
+// still completes returns its result, since its changes were made, and one
+// that fails returns a timeout error. Handlers ignoring the context past the
+// grace period are left to finish in the background, so that the server
+// keeps serving, and the call fails with its outcome unknown. They keep
+// their repository locks and their slot in the concurrent calls of the
+// session until they return.
+func (s *Server) limitDuration(tool mcp.Tool, handler mcp.ToolHandler) mcp.ToolHandler {
+	if properties := schemaProperties(tool); properties != nil {
+		limit := "no limit"
+		if s.toolTimeout > 0 {
+			limit = fmt.Sprintf("%d, which is also the maximum", int(s.toolTimeout.Seconds()))
+		}
+		properties["timeout_seconds"] = map[string]interface{}{
+			"type":        "integer",
+			"description": fmt.Sprintf("Stop the call after this many seconds (default: %s)", limit),
+			"minimum":     1,
+		}
+	}
+	name := s.toolPrefix + tool.Name
+
+	return func(ctx context.Context, arguments map[string]interface{}) ([]mcp.TextContent, error) {
+		timeout := s.toolTimeout
+		if seconds := getInt(arguments, "timeout_seconds", 0); seconds > 0 {
+			if requested := time.Duration(seconds) * time.Second; timeout == 0 || requested < timeout {
+				timeout = requested
+			}
+		}
+		if timeout == 0 {
+			return handler(ctx, arguments)
+		}
+
+		callCtx := ctx
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		type result struct {
+			content []mcp.TextContent
+			err     error
+		}
+		done := make(chan result, 1)
+		go func() {
+			content, err := mcp.RecoverPanics(handler)(ctx, arguments)
+			done <- result{content, err}
+		}()
+
+		select {
+		case r := <-done:
+			if r.err != nil && ctx.Err() == context.DeadlineExceeded {
+				return nil, timeoutError(name, timeout, r.err)
+			}
+			return r.content, r.err
+		case <-ctx.Done():
+			if ctx.Err() != context.DeadlineExceeded {
+				return nil, ctx.Err()
+			}
+			grace := time.NewTimer(timeoutGrace)
+			defer grace.Stop()
+			select {
+			case r := <-done:
+				if r.err != nil {
+					return nil, timeoutError(name, timeout, r.err)
+				}
+				slog.Warn("Tool completed after timing out", "tool", name)
+				return r.content, nil
+			case <-grace.C:
+			}
+
+			release := mcp.KeepInFlight(callCtx)
+			go func() {
+				defer release()
+				if r := <-done; r.err == nil {
+					slog.Warn("Tool completed after its outcome was reported unknown", "tool", name)
+				}
+			}()
+			return nil, unknownOutcomeError(name, timeout)
+		}
+	}
+}
+
+// unknownOutcomeError reports a call still running after its timeout and
+// grace period, whose changes may or may not be made
+func unknownOutcomeError(name string, timeout time.Duration) error {
+	message := fmt.Sprintf("%s timed out after %s and is still running, its outcome is unknown: check the state of the repository before retrying, it stays locked until the call ends", name, timeout)
+	return &mcp.ToolError{Code: gitops.ErrorCodeTimeout, Err: errors.New(message)}
+}
+
+// timeoutError reports a call stopped by its timeout, along with the error
+// the handler returned when it stopped
+func timeoutError(name string, timeout time.Duration, err error) error {
+	message := fmt.Sprintf("%s timed out after %s, raise timeout_seconds or --tool-timeout for slow remotes and large repositories", name, timeout)
+	if err != nil {
+		message += fmt.Sprintf(" (%v)", err)
+	}
+	return &mcp.ToolError{Code: gitops.ErrorCodeTimeout, Err: errors.New(message)}
+}
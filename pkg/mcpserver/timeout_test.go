@@ -0,0 +1,61 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pengcunfu/go-mcp-git/pkg/gitops"
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+func TestLimitDuration_CompletedAfterTimeout(t *testing.T) {
+	s := &Server{toolTimeout: 20 * time.Millisecond}
+	handler := s.limitDuration(mcp.Tool{Name: "test_tool"}, func(ctx context.Context, arguments map[string]interface{}) ([]mcp.TextContent, error) {
+		// Ignores the cancellation, the change is made anyway
+		time.Sleep(100 * time.Millisecond)
+		return []mcp.TextContent{{Type: "text", Text: "committed"}}, nil
+	})
+
+	content, err := handler(context.Background(), map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Expected the result of a call completing in the grace period, got: %v", err)
+	}
+	if joinContent(content) != "committed" {
+		t.Errorf("Unexpected result: %s", joinContent(content))
+	}
+}
+
+func TestLimitDuration_FailedAfterTimeout(t *testing.T) {
+	s := &Server{toolTimeout: 20 * time.Millisecond}
+	returned := false
+	handler := s.limitDuration(mcp.Tool{Name: "test_tool"}, func(ctx context.Context, arguments map[string]interface{}) ([]mcp.TextContent, error) {
+		<-ctx.Done()
+		time.Sleep(50 * time.Millisecond)
+		returned = true
+		return nil, ctx.Err()
+	})
+
+	_, err := handler(context.Background(), map[string]interface{}{})
+	var toolErr *mcp.ToolError
+	if !errors.As(err, &toolErr) || toolErr.Code != gitops.ErrorCodeTimeout {
+		t.Fatalf("Expected a timeout error, got: %v", err)
+	}
+	if !returned {
+		t.Errorf("Expected the handler to have returned before the timeout was reported")
+	}
+}
+
+func TestLimitDuration_TimeoutArgument(t *testing.T) {
+	s := &Server{}
+	handler := s.limitDuration(mcp.Tool{Name: "test_tool"}, func(ctx context.Context, arguments map[string]interface{}) ([]mcp.TextContent, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Errorf("Expected timeout_seconds to set a deadline")
+		}
+		return nil, nil
+	})
+	if _, err := handler(context.Background(), map[string]interface{}{"timeout_seconds": float64(30)}); err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package mcpserver
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Groups of tools, selecting several tools at once in EnableTools and
+// DisableTools
+const (
+	// ToolGroupRead are the tools that never modify a repository
+	ToolGroupRead = "read"
+	// ToolGroupWrite are the tools that may modify a repository
+	ToolGroupWrite = "write"
+	// ToolGroupRemote are the tools talking to remotes
+	ToolGroupRemote = "remote"
+	// ToolGroupDestructive are the tools whose calls may discard work,
+	// confirmed before they run
+	ToolGroupDestructive = "destructive"
+)
+
+// remoteTools are the tools fetching from or pushing to remotes.
+// git_raw_command is one of them since it runs any git command, fetch and
+// push included.
+var remoteTools = map[string]bool{
+	"git_clone":        true,
+	"git_fetch":        true,
+	"git_push":         true,
+	"git_push_tags":    true,
+	"git_remote_prune": true,
+	"git_multi_sync":   true,
+	"git_raw_command":  true,
+}
+
+// inToolGroup reports whether a tool belongs to a group
+func inToolGroup(name, group string) bool {
+	switch group {
+	case ToolGroupRead:
+		return readOnlyTools[name]
+	case ToolGroupWrite:
+		return !readOnlyTools[name]
+	case ToolGroupRemote:
+		return remoteTools[name]
+	case ToolGroupDestructive:
+		_, ok := destructiveTools[name]
+		return ok
+	}
+	return false
+}
+
+func isToolGroup(name string) bool {
+	switch name {
+	case ToolGroupRead, ToolGroupWrite, ToolGroupRemote, ToolGroupDestructive:
+		return true
+	}
+	return false
+}
+
+// toolSelection holds the tools and groups enabled and disabled by the
+// configuration. Without enabled entries every tool is enabled, and
+// disabled entries win over enabled ones.
+type toolSelection struct {
+	enabled  []string
+	disabled []string
+	// offered are the names of all the tools offered for registration,
+	// checking the entries name existing tools
+	offered map[string]bool
+}
+
+func newToolSelection(enabled, disabled []string) *toolSelection {
+	return &toolSelection{enabled: trimEntries(enabled), disabled: trimEntries(disabled), offered: make(map[string]bool)}
+}
+
+// trimEntries drops the spaces around entries and the empty ones
+func trimEntries(entries []string) []string {
+	var trimmed []string
+	for _, entry := range entries {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			trimmed = append(trimmed, entry)
+		}
+	}
+	return trimmed
+}
+
+// selects reports whether a tool is exposed
+func (t *toolSelection) selects(name string) bool {
+	t.offered[name] = true
+	if matchesTool(name, t.disabled) {
+		return false
+	}
+	return len(t.enabled) == 0 || matchesTool(name, t.enabled)
+}
+
+func matchesTool(name string, entries []string) bool {
+	for _, entry := range entries {
+		if entry == name || inToolGroup(name, entry) {
+			return true
+		}
+	}
+	return false
+}
+
+// validate checks that the entries name tool groups or tools offered for
+// registration, typos otherwise leaving tools exposed
+func (t *toolSelection) validate() error {
+	for _, entry := range append(append([]string(nil), t.enabled...), t.disabled...) {
+		if !isToolGroup(entry) && !t.offered[entry] {
+			return fmt.Errorf("unknown tool or tool group '%s' (groups: %s, %s, %s, %s)", entry, ToolGroupRead, ToolGroupWrite, ToolGroupRemote, ToolGroupDestructive)
+		}
+	}
+	return nil
+}
+
+// describe summarizes the selection for server_dump_state, empty when
+// every tool is enabled
+func (t *toolSelection) describe() string {
+	if len(t.enabled) == 0 && len(t.disabled) == 0 {
+		return ""
+	}
+	enabled := "all"
+	if len(t.enabled) > 0 {
+		enabled = strings.Join(t.enabled, ", ")
+	}
+	return fmt.Sprintf("enabled %s; disabled %s", enabled, orNone(strings.Join(t.disabled, ", ")))
+}
@@ -0,0 +1,73 @@
+package mcpserver
+
+import "testing"
+
+func TestInToolGroup(t *testing.T) {
+	tests := []struct {
+		tool  string
+		group string
+		in    bool
+	}{
+		{"git_status", ToolGroupRead, true},
+		{"git_status", ToolGroupWrite, false},
+		{"git_status", ToolGroupRemote, false},
+		{"git_status", ToolGroupDestructive, false},
+		{"git_commit", ToolGroupWrite, true},
+		{"git_commit", ToolGroupRead, false},
+		{"git_fetch", ToolGroupRemote, true},
+		{"git_push", ToolGroupRemote, true},
+		{"git_push", ToolGroupDestructive, true},
+		{"git_reset", ToolGroupDestructive, true},
+		{"git_reset", ToolGroupRemote, false},
+		{"git_raw_command", ToolGroupWrite, true},
+		{"git_raw_command", ToolGroupRemote, true},
+		{"git_raw_command", ToolGroupDestructive, true},
+		{"git_status", "unknown", false},
+	}
+	for _, tt := range tests {
+		if got := inToolGroup(tt.tool, tt.group); got != tt.in {
+			t.Errorf("inToolGroup(%q, %q) = %v, expected %v", tt.tool, tt.group, got, tt.in)
+		}
+	}
+}
+
+func TestToolSelection_Selects(t *testing.T) {
+	tests := []struct {
+		name     string
+		enabled  []string
+		disabled []string
+		tool     string
+		selected bool
+	}{
+		{"everything by default", nil, nil, "git_push", true},
+		{"enabled group", []string{ToolGroupRead}, nil, "git_status", true},
+		{"outside the enabled group", []string{ToolGroupRead}, nil, "git_commit", false},
+		{"enabled by name", []string{ToolGroupRead, "git_commit"}, nil, "git_commit", true},
+		{"disabled wins", []string{"git_push"}, []string{ToolGroupRemote}, "git_push", false},
+		{"raw command disabled as remote", nil, []string{ToolGroupRemote}, "git_raw_command", false},
+		{"raw command disabled as destructive", nil, []string{ToolGroupDestructive}, "git_raw_command", false},
+		{"entries trimmed", []string{" read "}, nil, "git_log", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selection := newToolSelection(tt.enabled, tt.disabled)
+			if got := selection.selects(tt.tool); got != tt.selected {
+				t.Errorf("selects(%q) = %v, expected %v", tt.tool, got, tt.selected)
+			}
+		})
+	}
+}
+
+func TestToolSelection_Validate(t *testing.T) {
+	selection := newToolSelection([]string{ToolGroupRead, "git_status"}, []string{"git_stauts"})
+	selection.selects("git_status")
+	if err := selection.validate(); err == nil {
+		t.Errorf("Expected an unknown tool name to be rejected")
+	}
+
+	selection = newToolSelection([]string{ToolGroupRead, "git_status"}, []string{ToolGroupDestructive})
+	selection.selects("git_status")
+	if err := selection.validate(); err != nil {
+		t.Errorf("Expected offered tools and groups to be accepted, got: %v", err)
+	}
+}
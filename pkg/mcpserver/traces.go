@@ -0,0 +1,85 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/pengcunfu/go-mcp-git/internal/tracing"
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+// TracingOptions configure the export of the traces of the tool calls
+type TracingOptions struct {
+	// Endpoint is the OTLP/HTTP traces URL of the collector, e.g.
+	// http://localhost:4318/v1/traces
+	Endpoint string
+	// Headers are sent with every export, e.g. for authentication
+	Headers map[string]string
+}
+
+// traceCalls starts a span for every tool call of the MCP server, joining
+// the trace the client gave in the metadata of the call. The git operations
+// of the call are its child spans.
+func traceCalls(tracer *tracing.Tracer) mcp.Middleware {
+	return func(next mcp.ToolHandler) mcp.ToolHandler {
+		return func(ctx context.Context, arguments map[string]interface{}) (content []mcp.TextContent, err error) {
+			tool := mcp.ToolName(ctx)
+			attrs := []tracing.Attribute{
+				tracing.String("mcp.method.name", mcp.MethodCallTool),
+				tracing.String("gen_ai.tool.name", tool),
+			}
+			if session := mcp.SessionFromContext(ctx); session != nil {
+				attrs = append(attrs, tracing.String("mcp.session.id", session.ID()))
+			}
+			ctx, span := tracer.Start(ctx, mcp.MethodCallTool+" "+tool, mcp.TraceParent(ctx), attrs...)
+
+			defer func() {
+				// The panic goes on to the recovery of the MCP server
+				if recovered := recover(); recovered != nil {
+					span.SetAttributes(tracing.String("error.type", mcp.ErrorCodeInternal))
+					span.End(errors.New("handler panicked"))
+					panic(recovered)
+				}
+				if err != nil {
+					code := "unknown"
+					var toolErr *mcp.ToolError
+					if errors.As(err, &toolErr) && toolErr.Code != "" {
+						code = toolErr.Code
+					}
+					span.SetAttributes(tracing.String("error.type", code))
+				}
+				span.End(err)
+			}()
+			return next(ctx, arguments)
+		}
+	}
+}
+
+// traceRepository records the repository of a call in its span, hashed so
+// that traces can be grouped by repository without revealing its path
+func traceRepository(ctx context.Context, repoPaths []string) {
+	span := tracing.FromContext(ctx)
+	if len(repoPaths) == 1 && repoPaths[0] != "" {
+		span.SetAttributes(tracing.Hash("git.repo_path.hash", repoPaths[0]))
+	} else if len(repoPaths) > 1 {
+		span.SetAttributes(tracing.Int("git.repositories", len(repoPaths)))
+	}
+}
+
+// tracerShutdownTimeout bounds the export of the spans left when the server
+// stops
+const tracerShutdownTimeout = 5 * time.Second
+
+// shutdownTracer exports the spans left once the server stopped
+func (s *Server) shutdownTracer() {
+	if s.tracer == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), tracerShutdownTimeout)
+	defer cancel()
+	if err := s.tracer.Shutdown(ctx); err != nil {
+		slog.Warn("Failed to export the last spans", "error", err)
+	}
+}
@@ -0,0 +1,46 @@
+package mcpserver
+
+import (
+	"context"
+
+	"github.com/pengcunfu/go-mcp-git/pkg/gitops"
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+// registerTrashTools registers tools recovering files discarded by hard
+// resets and restores
+func (s *Server) registerTrashTools() {
+	registerTypedTool(s, "git_trash_list",
+		"Lists the trash entries of a repository, holding copies of the files discarded by hard resets and restores, newest first",
+		s.handleGitTrashList)
+
+	registerTypedTool(s, "git_trash_restore",
+		"Copies the files of a trash entry back into the repository they were discarded from",
+		s.handleGitTrashRestore)
+}
+
+func (s *Server) handleGitTrashList(ctx context.Context, params gitops.GitTrashList) ([]mcp.TextContent, error) {
+	result, err := s.git(ctx).TrashList(s.getRepoPath(params.RepoPath))
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
+
+func (s *Server) handleGitTrashRestore(ctx context.Context, params gitops.GitTrashRestore) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	result, err := s.git(ctx).TrashRestore(repoPath, params.ID, params.Files, params.Overwrite)
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}
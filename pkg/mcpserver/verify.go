@@ -0,0 +1,33 @@
+package mcpserver
+
+import (
+	"context"
+
+	"github.com/pengcunfu/go-mcp-git/pkg/gitops"
+	"github.com/pengcunfu/go-mcp-git/pkg/mcp"
+)
+
+// registerVerifyTools registers tools checking the signatures of commits
+// and tags
+func (s *Server) registerVerifyTools() {
+	registerTypedTool(s, "git_verify",
+		"Checks the OpenPGP or SSH signature of a tag or commit and reports the signer and whether the signature is valid and trusted, like git verify-tag and git verify-commit",
+		s.handleGitVerify)
+}
+
+func (s *Server) handleGitVerify(ctx context.Context, params gitops.GitVerify) ([]mcp.TextContent, error) {
+	repoPath := s.getRepoPath(params.RepoPath)
+
+	result, err := s.git(ctx).Verify(repoPath, params.Revision, gitops.VerifyOptions{
+		PublicKeys:     params.PublicKeys,
+		AllowedSigners: params.AllowedSigners,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.TextContent{{
+		Type: "text",
+		Text: result,
+	}}, nil
+}